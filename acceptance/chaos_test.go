@@ -40,6 +40,8 @@ import (
 
 var maxTransfer = flag.Int("max-transfer", 999, "Maximum amount to transfer in one transaction.")
 var numAccounts = flag.Int("num-accounts", 999, "Number of accounts.")
+var nemesisSlowDisk = flag.Bool("nemesis-slow-disk", false,
+	"Include disk throttling in the nemeses. Requires write access to the docker blkio cgroups.")
 
 type testClient struct {
 	sync.RWMutex
@@ -422,3 +424,90 @@ func testNodeRestartInner(t *testing.T, c cluster.Cluster, cfg cluster.TestConfi
 		log.Error(pErr)
 	}
 }
+
+// TestNemeses starts up a cluster with an "accounts" table and transfers
+// money between accounts through a single node while the other nodes are
+// subjected to alternating faults: clock skew, network partitions and,
+// optionally, slow disks. The bank must remain balanced throughout.
+func TestNemeses(t *testing.T) {
+	runTestOnConfigs(t, testNemesesInner)
+}
+
+func testNemesesInner(t *testing.T, c cluster.Cluster, cfg cluster.TestConfig) {
+	l, ok := c.(*cluster.LocalCluster)
+	if !ok {
+		t.Skip("nemeses are only supported on local clusters")
+	}
+	num := c.NumNodes()
+	if num < 3 {
+		t.Skipf("need at least 3 nodes, got %d", num)
+	}
+
+	initBank(t, c.PGUrl(0))
+
+	start := time.Now()
+	state := testState{
+		t:        t,
+		errChan:  make(chan error, 1),
+		teardown: make(chan struct{}),
+		deadline: start.Add(cfg.Duration),
+		clients:  make([]testClient, 1),
+	}
+
+	// The client talks to the last node, which is spared by the nemeses so
+	// that its connection survives nodes being restarted in new containers.
+	client := &state.clients[0]
+	client.Lock()
+	client.db = makePGClient(t, c.PGUrl(num-1))
+	client.Unlock()
+	go transferMoneyLoop(0, &state, *numAccounts, *maxTransfer)
+
+	var targets []int
+	for i := 0; i < num-1; i++ {
+		targets = append(targets, i)
+	}
+	nemeses := []cluster.Nemesis{
+		&cluster.ClockSkewNemesis{MaxOffset: 200 * time.Millisecond, Nodes: targets},
+		&cluster.PartitionNemesis{Nodes: targets},
+	}
+	if *nemesisSlowDisk {
+		nemeses = append(nemeses, &cluster.SlowDiskNemesis{BytesPerSecond: 1 << 20, Nodes: targets})
+	}
+
+	rnd, seed := randutil.NewPseudoRand()
+	log.Warningf("nemeses start (seed %d)", seed)
+	go func() {
+		defer close(state.teardown)
+		// Stop the nemeses once the test is done. In the meantime, count
+		// rounds of client progress so that waitClientsStop can detect stalls.
+		stop := make(chan struct{})
+		go func() {
+			defer close(stop)
+			var prevCount uint64
+			for !state.done() {
+				time.Sleep(time.Second)
+				if count := atomic.LoadUint64(&client.count); count > prevCount {
+					prevCount = count
+					atomic.AddUint64(&state.monkeyIteration, 1)
+				}
+			}
+		}()
+		if err := cluster.RunNemeses(l, stop, 10*time.Second, rnd, nemeses...); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	waitClientsStop(1, &state, cfg.Stall)
+	<-state.teardown
+
+	verifyAccounts(t, client)
+
+	elapsed := time.Since(start)
+	count := atomic.LoadUint64(&client.count)
+	log.Infof("%d %.1f/sec", count, float64(count)/elapsed.Seconds())
+	kvClient, kvStopper := c.NewClient(t, num-1)
+	defer kvStopper.Stop()
+	if pErr := kvClient.CheckConsistency(keys.TableDataMin, keys.TableDataMax); pErr != nil {
+		t.Fatal(pErr)
+	}
+}
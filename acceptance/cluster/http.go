@@ -20,8 +20,10 @@ import (
 	"crypto/tls"
 	"net/http"
 
+	"golang.org/x/net/context"
+
 	"github.com/cockroachdb/cockroach/base"
-	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/httputil"
 )
 
 // HTTPClient is an http.Client configured for querying a cluster. We need to
@@ -36,14 +38,21 @@ var HTTPClient = http.Client{
 		},
 	}}
 
-// getJSON is a convenience wrapper around cockroach/util.GetJSON(), which retrieves
-// an URL specified by the parameters and unmarshals the result into the supplied
-// interface.
+// getJSON is a convenience wrapper around httputil.Client.GetJSON(), which
+// retrieves an URL specified by the parameters and unmarshals the result into
+// the supplied interface. Requests are retried with
+// httputil.DefaultRetryOptions.
 func getJSON(tls bool, hostport, path string, v interface{}) error {
 	scheme := "https"
 	if !tls {
 		scheme = "http"
 	}
 
-	return util.GetJSON(&HTTPClient, scheme, hostport, path, v)
+	opts := httputil.DefaultRetryOptions
+	client := httputil.Client{
+		HTTPClient:   &HTTPClient,
+		Scheme:       scheme,
+		RetryOptions: &opts,
+	}
+	return client.GetJSON(context.Background(), hostport, path, v)
 }
@@ -120,6 +120,9 @@ type testNode struct {
 	nodeStr string
 	config  NodeConfig
 	stores  []testStore
+	// env holds additional environment variables, in the form "KEY=value",
+	// passed to the node's cockroach process.
+	env []string
 }
 
 // LocalCluster manages a local cockroach cluster running on docker. The
@@ -140,6 +143,10 @@ type LocalCluster struct {
 	monitorCtxCancelFunc func()
 	logDir               string
 	networkID            string
+	// retired maps the IDs of node containers which have been replaced to
+	// the index of their node, so that their final events are attributed
+	// correctly.
+	retired map[string]int
 }
 
 // CreateLocal creates a new local cockroach cluster. The stopper is used to
@@ -168,6 +175,7 @@ func CreateLocal(cfg TestConfig, logDir string, stopper chan struct{}) *LocalClu
 		events:         make(chan Event, 1000),
 		expectedEvents: make(chan Event, 1000),
 		logDir:         logDir,
+		retired:        map[string]int{},
 	}
 }
 
@@ -359,6 +367,8 @@ func (l *LocalCluster) createRoach(node *testNode, vols *Container, env []string
 
 	hostConfig := container.HostConfig{
 		PublishAllPorts: true,
+		// Allow the nemeses to manipulate the node's firewall.
+		CapAdd: []string{"NET_ADMIN"},
 	}
 
 	if vols != nil {
@@ -448,14 +458,14 @@ func (l *LocalCluster) startNode(node *testNode) {
 			"--logtostderr=false")
 
 	}
-	env := []string{"COCKROACH_SCAN_MAX_IDLE_TIME=200ms"}
+	env := append([]string{"COCKROACH_SCAN_MAX_IDLE_TIME=200ms"}, node.env...)
 	l.createRoach(node, l.vols, env, cmd...)
 	maybePanic(node.Start())
 	log.Infof(`*** started %[1]s ***
   ui:        %[2]s
   trace:     %[2]s/debug/requests
   logs:      %[3]s/cockroach.INFO
-  pprof:     docker exec -it %[4]s /bin/bash -c 'go tool pprof /cockroach <(wget --no-check-certificate -qO- https://$(hostname):%[5]s/debug/pprof/heap)'
+  pprof:     docker exec -it %[4]s /bin/bash -c 'go tool pprof /cockroach <(wget --no-check-certificate --certificate=/certs/node.crt --private-key=/certs/node.key -qO- https://$(hostname):%[5]s/debug/pprof/heap)'
   cockroach: %[6]s`,
 		node.Name(), "https://"+node.Addr(DefaultTCP).String(), locallogDir, node.Container.id[:5], base.DefaultHTTPPort, cmd)
 }
@@ -470,19 +480,27 @@ func (l *LocalCluster) processEvent(event events.Message) bool {
 		return true
 	}
 
+	nodeIndex := -1
 	for i, n := range l.Nodes {
 		if n != nil && n.id == event.ID {
-			if log.V(1) {
-				log.Errorf("node=%d status=%s", i, event.Status)
-			}
-			select {
-			case l.events <- Event{NodeIndex: i, Status: event.Status}:
-			default:
-				panic("events channel filled up")
-			}
-			return true
+			nodeIndex = i
+			break
 		}
 	}
+	if i, ok := l.retired[event.ID]; ok && nodeIndex < 0 {
+		nodeIndex = i
+	}
+	if nodeIndex >= 0 {
+		if log.V(1) {
+			log.Errorf("node=%d status=%s", nodeIndex, event.Status)
+		}
+		select {
+		case l.events <- Event{NodeIndex: nodeIndex, Status: event.Status}:
+		default:
+			panic("events channel filled up")
+		}
+		return true
+	}
 
 	// An event on any other container is unexpected. Die.
 	select {
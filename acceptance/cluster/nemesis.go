@@ -0,0 +1,268 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cluster
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/engine-api/types"
+
+	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/log"
+)
+
+var blkioCgroupRoot = flag.String("blkio-cgroup-root", "/sys/fs/cgroup/blkio/docker",
+	"the blkio cgroup hierarchy of docker containers, used to throttle disk IO")
+var blkioDevice = flag.String("blkio-device", "8:0",
+	"the major:minor number of the device backing the docker volumes")
+
+const clockOffsetEnv = "COCKROACH_CLOCK_OFFSET"
+
+// exec runs the given command inside the container and waits for it to
+// complete.
+func (c *Container) exec(cmd ...string) error {
+	resp, err := c.cluster.client.ContainerExecCreate(types.ExecConfig{
+		Container: c.id,
+		Cmd:       cmd,
+	})
+	if err != nil {
+		return err
+	}
+	if err := c.cluster.client.ContainerExecStart(resp.ID, types.ExecStartCheck{Detach: true}); err != nil {
+		return err
+	}
+	return util.RetryForDuration(30*time.Second, func() error {
+		info, err := c.cluster.client.ContainerExecInspect(resp.ID)
+		if err != nil {
+			return err
+		}
+		if info.Running {
+			return util.Errorf("%s still running", cmd)
+		}
+		if info.ExitCode != 0 {
+			return util.Errorf("%s exited with code %d", cmd, info.ExitCode)
+		}
+		return nil
+	})
+}
+
+// SetClockOffset restarts the i-th node with its clock skewed by offset
+// relative to the other nodes. Since the node is run in a new container,
+// clients connected to it must reconnect.
+func (l *LocalCluster) SetClockOffset(i int, offset time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	node := l.Nodes[i]
+	if err := node.Kill(); err != nil {
+		return err
+	}
+	if err := node.Remove(); err != nil {
+		return err
+	}
+	l.retired[node.id] = i
+
+	var env []string
+	for _, e := range node.env {
+		if !strings.HasPrefix(e, clockOffsetEnv+"=") {
+			env = append(env, e)
+		}
+	}
+	if offset != 0 {
+		env = append(env, fmt.Sprintf("%s=%s", clockOffsetEnv, offset))
+	}
+	node.env = env
+	l.startNode(node)
+	return nil
+}
+
+// Partition drops all network traffic between the i-th and j-th nodes.
+func (l *LocalCluster) Partition(i, j int) error {
+	other := l.Nodes[j].nodeStr
+	if err := l.Nodes[i].exec("iptables", "-A", "INPUT", "-s", other, "-j", "DROP"); err != nil {
+		return err
+	}
+	return l.Nodes[i].exec("iptables", "-A", "OUTPUT", "-d", other, "-j", "DROP")
+}
+
+// HealPartitions removes all network partitions created through Partition.
+func (l *LocalCluster) HealPartitions() error {
+	for _, node := range l.Nodes {
+		if err := node.exec("iptables", "-F", "INPUT"); err != nil {
+			return err
+		}
+		if err := node.exec("iptables", "-F", "OUTPUT"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ThrottleDisk limits the disk throughput of the i-th node to the given
+// number of bytes per second for both reads and writes. A limit of zero
+// removes the throttling.
+func (l *LocalCluster) ThrottleDisk(i int, bytesPerSecond uint64) error {
+	dir := filepath.Join(*blkioCgroupRoot, l.Nodes[i].id)
+	rule := []byte(fmt.Sprintf("%s %d", *blkioDevice, bytesPerSecond))
+	for _, file := range []string{"blkio.throttle.read_bps_device", "blkio.throttle.write_bps_device"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, file), rule, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// A Nemesis injects a fault into a LocalCluster and later repairs it.
+type Nemesis interface {
+	// Name returns a short description of the nemesis.
+	Name() string
+	// Start injects the fault.
+	Start(l *LocalCluster, rnd *rand.Rand) error
+	// Stop repairs the fault injected by the last call to Start.
+	Stop(l *LocalCluster) error
+}
+
+// pickNode picks one of the given nodes, or any node of the cluster if no
+// nodes are given.
+func pickNode(l *LocalCluster, rnd *rand.Rand, nodes []int) int {
+	if len(nodes) == 0 {
+		return rnd.Intn(l.NumNodes())
+	}
+	return nodes[rnd.Intn(len(nodes))]
+}
+
+// ClockSkewNemesis skews the clock of a random node by up to MaxOffset in
+// either direction.
+type ClockSkewNemesis struct {
+	MaxOffset time.Duration
+	// Nodes restricts the nemesis to the given nodes. All nodes are
+	// candidates if empty.
+	Nodes []int
+
+	node int
+}
+
+// Name implements the Nemesis interface.
+func (n *ClockSkewNemesis) Name() string { return "clock skew" }
+
+// Start implements the Nemesis interface.
+func (n *ClockSkewNemesis) Start(l *LocalCluster, rnd *rand.Rand) error {
+	n.node = pickNode(l, rnd, n.Nodes)
+	offset := time.Duration(rnd.Int63n(2*int64(n.MaxOffset)+1)) - n.MaxOffset
+	log.Infof("skewing clock of node %d by %s", n.node, offset)
+	return l.SetClockOffset(n.node, offset)
+}
+
+// Stop implements the Nemesis interface.
+func (n *ClockSkewNemesis) Stop(l *LocalCluster) error {
+	return l.SetClockOffset(n.node, 0)
+}
+
+// PartitionNemesis isolates a random node from all other nodes.
+type PartitionNemesis struct {
+	// Nodes restricts the nemesis to the given nodes. All nodes are
+	// candidates if empty.
+	Nodes []int
+}
+
+// Name implements the Nemesis interface.
+func (n *PartitionNemesis) Name() string { return "partition" }
+
+// Start implements the Nemesis interface.
+func (n *PartitionNemesis) Start(l *LocalCluster, rnd *rand.Rand) error {
+	node := pickNode(l, rnd, n.Nodes)
+	log.Infof("isolating node %d", node)
+	for j := 0; j < l.NumNodes(); j++ {
+		if j == node {
+			continue
+		}
+		if err := l.Partition(node, j); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stop implements the Nemesis interface.
+func (n *PartitionNemesis) Stop(l *LocalCluster) error {
+	return l.HealPartitions()
+}
+
+// SlowDiskNemesis throttles the disk of a random node to BytesPerSecond.
+type SlowDiskNemesis struct {
+	BytesPerSecond uint64
+	// Nodes restricts the nemesis to the given nodes. All nodes are
+	// candidates if empty.
+	Nodes []int
+
+	node int
+}
+
+// Name implements the Nemesis interface.
+func (n *SlowDiskNemesis) Name() string { return "slow disk" }
+
+// Start implements the Nemesis interface.
+func (n *SlowDiskNemesis) Start(l *LocalCluster, rnd *rand.Rand) error {
+	n.node = pickNode(l, rnd, n.Nodes)
+	log.Infof("throttling disk of node %d to %d bytes/s", n.node, n.BytesPerSecond)
+	return l.ThrottleDisk(n.node, n.BytesPerSecond)
+}
+
+// Stop implements the Nemesis interface.
+func (n *SlowDiskNemesis) Stop(l *LocalCluster) error {
+	return l.ThrottleDisk(n.node, 0)
+}
+
+// RunNemeses alternates between the given nemeses until stopper is closed:
+// each round, a random nemesis is started, left in place for the given
+// period, then stopped, after which the cluster is given the same period to
+// recover. The first error encountered is returned.
+func RunNemeses(l *LocalCluster, stopper <-chan struct{}, period time.Duration,
+	rnd *rand.Rand, nemeses ...Nemesis) error {
+	if len(nemeses) == 0 {
+		return util.Errorf("no nemeses given")
+	}
+	wait := func() bool {
+		select {
+		case <-stopper:
+			return false
+		case <-l.stopper:
+			return false
+		case <-time.After(period):
+			return true
+		}
+	}
+	for round := 1; ; round++ {
+		n := nemeses[rnd.Intn(len(nemeses))]
+		log.Infof("nemesis round %d: starting %s", round, n.Name())
+		if err := n.Start(l, rnd); err != nil {
+			return util.Errorf("starting %s: %s", n.Name(), err)
+		}
+		done := !wait()
+		log.Infof("nemesis round %d: stopping %s", round, n.Name())
+		if err := n.Stop(l); err != nil {
+			return util.Errorf("stopping %s: %s", n.Name(), err)
+		}
+		if done || !wait() {
+			return nil
+		}
+	}
+}
@@ -18,18 +18,23 @@
 package cli
 
 import (
+	"bufio"
 	"bytes"
 	"errors"
 	"fmt"
+	"os"
 	"strconv"
+	"strings"
 
 	"github.com/cockroachdb/cockroach/keys"
 	"github.com/cockroachdb/cockroach/roachpb"
 	"github.com/cockroachdb/cockroach/storage"
 	"github.com/cockroachdb/cockroach/storage/engine"
+	"github.com/cockroachdb/cockroach/util/hlc"
 	"github.com/cockroachdb/cockroach/util/stop"
 	"github.com/coreos/etcd/raft/raftpb"
 	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
 )
 
 var debugKeysCmd = &cobra.Command{
@@ -71,7 +76,7 @@ func runDebugKeys(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if err := db.Iterate(engine.NilKey, engine.MVCCKeyMax, printKey); err != nil {
+	if err := db.Iterate(context.Background(), engine.NilKey, engine.MVCCKeyMax, printKey); err != nil {
 		return err
 	}
 
@@ -122,7 +127,7 @@ func runDebugRangeDescriptors(cmd *cobra.Command, args []string) error {
 	start := engine.MakeMVCCMetadataKey(keys.LocalRangePrefix)
 	end := engine.MakeMVCCMetadataKey(keys.LocalRangeMax)
 
-	if err := db.Iterate(start, end, printRangeDescriptor); err != nil {
+	if err := db.Iterate(context.Background(), start, end, printRangeDescriptor); err != nil {
 		return err
 	}
 	return nil
@@ -186,16 +191,131 @@ func runDebugRaftLog(cmd *cobra.Command, args []string) error {
 	start := engine.MakeMVCCMetadataKey(keys.RaftLogPrefix(rangeID))
 	end := engine.MakeMVCCMetadataKey(keys.RaftLogPrefix(rangeID).PrefixEnd())
 
-	if err := db.Iterate(start, end, printRaftLogEntry); err != nil {
+	if err := db.Iterate(context.Background(), start, end, printRaftLogEntry); err != nil {
 		return err
 	}
 	return nil
 }
 
+var debugRemoveDeadReplicasCmd = &cobra.Command{
+	Use:   "unsafe-remove-dead-replicas [directory...]",
+	Short: "remove dead replicas from ranges which lost quorum (UNSAFE)",
+	Long: `
+Rewrites the range descriptors in stopped stores to remove the replicas
+which reside on the stores given by --dead-store-ids, for all ranges that
+lost a majority of their replicas to those stores. This allows the
+surviving replicas to make progress again once the nodes are restarted.
+The changes are computed for all the given stores before any of them is
+written, so that an error leaves every store untouched.
+
+This command is UNSAFE and may cause data loss or inconsistencies. It
+should only be used to recover from a disaster in which a majority of
+the replicas of some ranges has been permanently lost. The command must
+be run against every store which holds a surviving replica, while the
+node owning that store is stopped.
+`,
+	RunE: runDebugRemoveDeadReplicas,
+}
+
+// deadStoreIDs is the comma-separated list of store IDs passed to
+// unsafe-remove-dead-replicas.
+var deadStoreIDs string
+
+func parseStoreIDs(s string) ([]roachpb.StoreID, error) {
+	var ids []roachpb.StoreID
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(f, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid store ID %q: %s", f, err)
+		}
+		ids = append(ids, roachpb.StoreID(id))
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("--dead-store-ids must specify at least one store ID")
+	}
+	return ids, nil
+}
+
+func runDebugRemoveDeadReplicas(cmd *cobra.Command, args []string) error {
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+
+	if len(args) == 0 {
+		return errors.New("at least one argument is required")
+	}
+	deadIDs, err := parseStoreIDs(deadStoreIDs)
+	if err != nil {
+		return err
+	}
+
+	// Compute the changes to all stores before writing any of them.
+	var batches []engine.Engine
+	defer func() {
+		for _, batch := range batches {
+			batch.Close()
+		}
+	}()
+	var count int
+	now := hlc.NewClock(hlc.UnixNano).Now()
+	for _, dir := range args {
+		db, err := openStore(cmd, dir, stopper)
+		if err != nil {
+			return err
+		}
+		var ident roachpb.StoreIdent
+		ok, err := engine.MVCCGetProto(db, keys.StoreIdentKey(), roachpb.ZeroTimestamp, true, nil, &ident)
+		if err != nil {
+			return err
+		} else if !ok {
+			return fmt.Errorf("store at %s has not been bootstrapped", dir)
+		}
+
+		batch := db.NewBatch()
+		batches = append(batches, batch)
+		descs, err := storage.RemoveDeadReplicas(batch, ident.StoreID, deadIDs, now)
+		if err != nil {
+			return fmt.Errorf("store %d at %s: %s", ident.StoreID, dir, err)
+		}
+		if len(descs) == 0 {
+			fmt.Printf("store %d: no ranges lost quorum\n", ident.StoreID)
+		}
+		for i := range descs {
+			fmt.Printf("store %d: range %d will be rewritten to:\n%s\n", ident.StoreID, descs[i].RangeID, &descs[i])
+		}
+		count += len(descs)
+	}
+	if count == 0 {
+		fmt.Println("nothing to do")
+		return nil
+	}
+
+	fmt.Printf("\nThis operation may cause data loss. Type 'yes' to rewrite %d range descriptor(s): ",
+		count)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(answer) != "yes" {
+		return errors.New("aborted; no changes were made")
+	}
+	for _, batch := range batches {
+		if err := batch.Commit(); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("rewrote %d range descriptor(s) on %d store(s)\n", count, len(args))
+	return nil
+}
+
 var debugCmds = []*cobra.Command{
 	debugKeysCmd,
 	debugRangeDescriptorsCmd,
 	debugRaftLogCmd,
+	debugRemoveDeadReplicasCmd,
 	kvCmd,
 	rangeCmd,
 }
@@ -71,6 +71,52 @@ Database server port to connect to for HTTP requests.`),
 	"database": wrapText(`
 The name of the database to connect to.`),
 
+	"dead-store-ids": wrapText(`
+A comma-separated list of the IDs of stores which have been permanently
+lost. Replicas residing on these stores are removed from all ranges which
+no longer have a quorum of live replicas.`),
+
+	"debug-addr": wrapText(`
+The host:port to bind for the debug endpoints (/debug/pprof, /debug/vars and
+/debug/requests). If set, these endpoints are served on this address only
+instead of on the HTTP port, allowing them to be bound to a separate
+interface. In secure mode, access requires a root or node client
+certificate.`),
+
+	"sql-addr": wrapText(`
+The host:port to bind for SQL clients. If set, SQL clients are served on this
+address only instead of on --port, allowing SQL to be exposed on a public
+interface while the intra-cluster RPCs are kept on a private one.`),
+
+	"sql-ca-cert": wrapText(`
+Path to the CA certificate used for SQL clients in place of --ca-cert.`),
+
+	"sql-cert": wrapText(`
+Path to the server certificate used for SQL clients in place of --cert.`),
+
+	"sql-key": wrapText(`
+Path to the key protecting --sql-cert.`),
+
+	"http-ca-cert": wrapText(`
+Path to the CA certificate used for HTTP requests in place of --ca-cert.`),
+
+	"http-cert": wrapText(`
+Path to the server certificate used for HTTP requests in place of --cert.`),
+
+	"http-key": wrapText(`
+Path to the key protecting --http-cert.`),
+
+	"diagnostics": wrapText(`
+Whether to periodically report anonymous diagnostics ("on" or "off").
+Reports contain the cluster's randomly generated ID, the node's version,
+the number of nodes, store sizes and aggregate metrics; they never contain
+keys, values or addresses. The report a node would send can be inspected
+at /_status/diagnostics/local.`),
+
+	"diagnostics-url": wrapText(`
+The URL to which anonymous diagnostic reports are posted when
+--diagnostics=on.`),
+
 	"execute": wrapText(`
 Execute the SQL statement(s) on the command line, then exit. This flag may be
 specified multiple times and each value may contain multiple semicolon
@@ -107,6 +153,27 @@ Define the maximum number of results that will be retrieved.`),
 The created user's password. If provided, disables prompting. Pass '-' to
 provide the password on standard input.`),
 
+	"cors-origins": wrapText(`
+Comma-separated list of the origins, e.g. https://dash.example.com, from which
+browsers may query the admin and status endpoints, for dashboards hosted
+elsewhere. "*" allows all origins. By default, cross-origin requests are not
+allowed.`),
+
+	"cors-methods": wrapText(`
+Comma-separated list of the HTTP methods allowed for cross-origin requests.
+Defaults to GET and POST.`),
+
+	"metrics-sink": wrapText(`
+The URL of a monitoring system to which the metrics of the node are pushed
+periodically, tagged with the IDs of the node and its stores. Supported
+systems are OpenTSDB (opentsdb://host:port), Graphite (graphite://host:port)
+and StatsD (statsd://host:port).`),
+
+	"profile-dir": wrapText(`
+The directory in which runtime profile snapshots captured through
+/_admin/v1/profiles/ are stored. Defaults to the "profiles" subdirectory of
+the first on-disk store.`),
+
 	"server_port": wrapText(`
 The port to bind to.`),
 
@@ -209,6 +276,9 @@ func (b *bytesValue) Set(s string) error {
 	if err != nil {
 		return err
 	}
+	if v < 0 {
+		return fmt.Errorf("size must not be negative: %s", s)
+	}
 	*b.val = v
 	b.isSet = true
 	return nil
@@ -270,6 +340,8 @@ func initFlags(ctx *Context) {
 		f.StringVar(&connHost, "host", "", usage("server_host"))
 		f.StringVarP(&connPort, "port", "p", base.DefaultPort, usage("server_port"))
 		f.StringVar(&httpPort, "http-port", base.DefaultHTTPPort, usage("server_http_port"))
+		f.StringVar(&ctx.DebugAddr, "debug-addr", ctx.DebugAddr, usage("debug-addr"))
+		f.StringVar(&ctx.SQLAddr, "sql-addr", ctx.SQLAddr, usage("sql-addr"))
 		f.StringVar(&ctx.Attrs, "attrs", ctx.Attrs, usage("attrs"))
 		f.VarP(&ctx.Stores, "store", "s", usage("store"))
 
@@ -279,10 +351,30 @@ func initFlags(ctx *Context) {
 		f.StringVar(&ctx.SSLCA, "ca-cert", ctx.SSLCA, usage("ca-cert"))
 		f.StringVar(&ctx.SSLCert, "cert", ctx.SSLCert, usage("cert"))
 		f.StringVar(&ctx.SSLCertKey, "key", ctx.SSLCertKey, usage("key"))
+		f.StringVar(&ctx.SQLSSLCA, "sql-ca-cert", ctx.SQLSSLCA, usage("sql-ca-cert"))
+		f.StringVar(&ctx.SQLSSLCert, "sql-cert", ctx.SQLSSLCert, usage("sql-cert"))
+		f.StringVar(&ctx.SQLSSLCertKey, "sql-key", ctx.SQLSSLCertKey, usage("sql-key"))
+		f.StringVar(&ctx.HTTPSSLCA, "http-ca-cert", ctx.HTTPSSLCA, usage("http-ca-cert"))
+		f.StringVar(&ctx.HTTPSSLCert, "http-cert", ctx.HTTPSSLCert, usage("http-cert"))
+		f.StringVar(&ctx.HTTPSSLCertKey, "http-key", ctx.HTTPSSLCertKey, usage("http-key"))
 
 		// Cluster joining flags.
 		f.StringVar(&ctx.JoinUsing, "join", ctx.JoinUsing, usage("join"))
 
+		// Diagnostics flags.
+		f.StringVar(&ctx.Diagnostics, "diagnostics", ctx.Diagnostics, usage("diagnostics"))
+		f.StringVar(&ctx.DiagnosticsURL, "diagnostics-url", ctx.DiagnosticsURL, usage("diagnostics-url"))
+
+		// CORS flags.
+		f.StringVar(&ctx.CORSAllowedOrigins, "cors-origins", ctx.CORSAllowedOrigins, usage("cors-origins"))
+		f.StringVar(&ctx.CORSAllowedMethods, "cors-methods", ctx.CORSAllowedMethods, usage("cors-methods"))
+
+		// Metrics flags.
+		f.StringVar(&ctx.MetricsSink, "metrics-sink", ctx.MetricsSink, usage("metrics-sink"))
+
+		// Profiling flags.
+		f.StringVar(&ctx.ProfileDir, "profile-dir", ctx.ProfileDir, usage("profile-dir"))
+
 		// Engine flags.
 		cacheSize = newBytesValue(&ctx.CacheSize)
 		f.Var(cacheSize, "cache", usage("cache"))
@@ -317,6 +409,14 @@ func initFlags(ctx *Context) {
 		}
 	}
 
+	{
+		f := debugRemoveDeadReplicasCmd.Flags()
+		f.StringVar(&deadStoreIDs, "dead-store-ids", "", usage("dead-store-ids"))
+		if err := debugRemoveDeadReplicasCmd.MarkFlagRequired("dead-store-ids"); err != nil {
+			panic(err)
+		}
+	}
+
 	setUserCmd.Flags().StringVar(&password, "password", "", usage("password"))
 
 	clientCmds := []*cobra.Command{
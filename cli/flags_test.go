@@ -74,4 +74,11 @@ func TestCacheFlagValue(t *testing.T) {
 	if expectedCacheSize != ctx.CacheSize {
 		t.Errorf("expected %d, but got %d", expectedCacheSize, ctx.CacheSize)
 	}
+
+	if err := f.Parse([]string{"--cache", "-100MB"}); err == nil {
+		t.Error("expected a negative cache size to be rejected")
+	}
+	if expectedCacheSize != ctx.CacheSize {
+		t.Errorf("expected %d after a rejected size, but got %d", expectedCacheSize, ctx.CacheSize)
+	}
 }
@@ -28,15 +28,19 @@ import (
 	"text/tabwriter"
 	"time"
 
+	"golang.org/x/net/context"
+
 	"github.com/cockroachdb/cockroach/client"
 	"github.com/cockroachdb/cockroach/security"
 	"github.com/cockroachdb/cockroach/server"
 	"github.com/cockroachdb/cockroach/storage/engine"
 	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/httputil"
 	"github.com/cockroachdb/cockroach/util/log"
 	"github.com/cockroachdb/cockroach/util/stop"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var errMissingParams = errors.New("missing or invalid parameters")
@@ -64,14 +68,14 @@ func panicf(format string, args ...interface{}) {
 	panic(fmt.Sprintf(format, args...))
 }
 
-// getJSON is a convenience wrapper around util.GetJSON that uses our Context to populate
-// parts of the request.
+// getJSON is a convenience wrapper around httputil.Client.GetJSON that uses
+// our Context to populate parts of the request.
 func getJSON(hostport, path string, v interface{}) error {
-	httpClient, err := cliContext.GetHTTPClient()
+	httpClient, err := httputil.NewClient(&cliContext.Context)
 	if err != nil {
 		return err
 	}
-	return util.GetJSON(httpClient, cliContext.HTTPRequestScheme(), hostport, path, v)
+	return httpClient.GetJSON(context.Background(), hostport, path, v)
 }
 
 // startCmd starts a node by initializing the stores and joining
@@ -101,12 +105,38 @@ func initCacheSize() {
 	}
 }
 
+// setConfigSources records the flags which were set on the command line as
+// the sources of the context values they set, to be reported by the config
+// endpoint.
+func setConfigSources(flags *pflag.FlagSet) {
+	flags.Visit(func(f *pflag.Flag) {
+		source := "flag --" + f.Name
+		switch f.Name {
+		case "host":
+			cliContext.SetConfigSource(&cliContext.Addr, source)
+			cliContext.SetConfigSource(&cliContext.HTTPAddr, source)
+		case "port":
+			cliContext.SetConfigSource(&cliContext.Addr, source)
+		case "http-port":
+			cliContext.SetConfigSource(&cliContext.HTTPAddr, source)
+		default:
+			// The values of the other flags point to the fields they set.
+			var field interface{} = f.Value
+			if b, ok := f.Value.(*bytesValue); ok {
+				field = b.val
+			}
+			cliContext.SetConfigSource(field, source)
+		}
+	})
+}
+
 // runStart starts the cockroach node using --store as the list of
 // storage devices ("stores") on this machine and --join as the list
 // of other active nodes used to join this node to the cockroach
 // cluster, if this is its first time connecting.
-func runStart(_ *cobra.Command, _ []string) error {
+func runStart(cmd *cobra.Command, _ []string) error {
 	initCacheSize()
+	setConfigSources(cmd.Flags())
 
 	// Default the log directory to the the "logs" subdirectory of the first
 	// non-memory store. We only do this for the "start" command which is why
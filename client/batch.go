@@ -49,6 +49,15 @@ type Batch struct {
 	// in the batch. This can only be used if all requests are of the same type, and that type is
 	// Scan or ReverseScan.
 	MaxScanResults int64
+	// If nonzero, limits the total size in bytes of the key/values returned by all
+	// Scan/ReverseScan operations in the batch, so that wide rows cannot produce
+	// responses larger than available memory. The same restrictions as for
+	// MaxScanResults apply.
+	TargetBytes int64
+	// AdmissionClass determines the priority with which the batch is
+	// admitted by overloaded stores. Internal background processes should
+	// set it to roachpb.BACKGROUND.
+	AdmissionClass roachpb.AdmissionClass
 	// We use pre-allocated buffers to avoid dynamic allocations for small batches.
 	resultsBuf [8]Result
 	rowsBuf    [8]KeyValue
@@ -64,6 +73,15 @@ func (b *Batch) prepare() *roachpb.Error {
 	return nil
 }
 
+// header returns the roachpb.Header fields which are set on the batch.
+func (b *Batch) header() roachpb.Header {
+	return roachpb.Header{
+		MaxScanResults: b.MaxScanResults,
+		TargetBytes:    b.TargetBytes,
+		AdmissionClass: b.AdmissionClass,
+	}
+}
+
 func (b *Batch) initResult(calls, numRows int, err error) {
 	// TODO(tschottdorf): assert that calls is 0 or 1?
 	r := Result{calls: calls, PErr: roachpb.NewError(err)}
@@ -142,6 +160,7 @@ func (b *Batch) fillResults(br *roachpb.BatchResponse, pErr *roachpb.Error) *roa
 						dst.Key = src.Key
 						dst.Value = &src.Value
 					}
+					result.ResumeSpan = t.ResumeSpan
 				}
 			case *roachpb.ReverseScanRequest:
 				if result.PErr == nil {
@@ -153,6 +172,7 @@ func (b *Batch) fillResults(br *roachpb.BatchResponse, pErr *roachpb.Error) *roa
 						dst.Key = src.Key
 						dst.Value = &src.Value
 					}
+					result.ResumeSpan = t.ResumeSpan
 				}
 			case *roachpb.DeleteRequest:
 				row := &result.Rows[k]
@@ -177,6 +197,10 @@ func (b *Batch) fillResults(br *roachpb.BatchResponse, pErr *roachpb.Error) *roa
 			case *roachpb.TruncateLogRequest:
 			case *roachpb.LeaderLeaseRequest:
 			case *roachpb.CheckConsistencyRequest:
+			case *roachpb.ClearRangeRequest:
+			case *roachpb.LeaseInfoRequest:
+			case *roachpb.ExportRequest:
+			case *roachpb.RecomputeStatsRequest:
 				// Nothing to do for these methods as they do not generate any
 				// rows.
 
@@ -404,6 +428,35 @@ func (b *Batch) DelRange(s, e interface{}, returnKeys bool) {
 	b.initResult(1, 0, nil)
 }
 
+// ClearRange removes all the values, including all of their versions,
+// between begin (inclusive) and end (exclusive). Unlike DelRange, it is
+// not transactional and leaves no tombstones behind; see
+// roachpb.ClearRangeRequest.
+//
+// A new result will be appended to the batch which will contain 0 rows and
+// Result.Err will indicate success or failure.
+//
+// key can be either a byte slice or a string.
+func (b *Batch) ClearRange(s, e interface{}) {
+	begin, err := marshalKey(s)
+	if err != nil {
+		b.initResult(0, 0, err)
+		return
+	}
+	end, err := marshalKey(e)
+	if err != nil {
+		b.initResult(0, 0, err)
+		return
+	}
+	b.reqs = append(b.reqs, &roachpb.ClearRangeRequest{
+		Span: roachpb.Span{
+			Key:    begin,
+			EndKey: end,
+		},
+	})
+	b.initResult(1, 0, nil)
+}
+
 // adminMerge is only exported on DB. It is here for symmetry with the
 // other operations.
 func (b *Batch) adminMerge(key interface{}) {
@@ -691,3 +691,64 @@ func TestClientPermissions(t *testing.T) {
 		}
 	}
 }
+
+// TestClientScanResumeSpan verifies that limited scans spanning several
+// ranges return the span from which they can be resumed, and that the
+// scans can be paginated with them.
+func TestClientScanResumeSpan(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := server.StartTestServer(t)
+	defer s.Stop()
+	db := s.DB()
+
+	var keys []roachpb.Key
+	for _, k := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		key := roachpb.Key(k)
+		keys = append(keys, key)
+		if pErr := db.Put(key, k); pErr != nil {
+			t.Fatal(pErr)
+		}
+	}
+	for _, k := range []string{"c", "e"} {
+		if pErr := db.AdminSplit(k); pErr != nil {
+			t.Fatal(pErr)
+		}
+	}
+
+	for _, reverse := range []bool{false, true} {
+		var scanned []roachpb.Key
+		span := &roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("z")}
+		for pages := 0; span != nil; pages++ {
+			if pages > len(keys) {
+				t.Fatalf("reverse=%t: too many pages: %s", reverse, scanned)
+			}
+			b := &client.Batch{}
+			if reverse {
+				b.ReverseScan(span.Key, span.EndKey, 3)
+			} else {
+				b.Scan(span.Key, span.EndKey, 3)
+			}
+			if pErr := db.Run(b); pErr != nil {
+				t.Fatal(pErr)
+			}
+			for _, row := range b.Results[0].Rows {
+				scanned = append(scanned, row.Key)
+			}
+			span = b.Results[0].ResumeSpan
+		}
+		if reverse {
+			for i, j := 0, len(scanned)-1; i < j; i, j = i+1, j-1 {
+				scanned[i], scanned[j] = scanned[j], scanned[i]
+			}
+		}
+		if len(scanned) != len(keys) {
+			t.Fatalf("reverse=%t: expected keys %s, got %s", reverse, keys, scanned)
+		}
+		for i := range keys {
+			if !keys[i].Equal(scanned[i]) {
+				t.Errorf("reverse=%t: expected keys %s, got %s", reverse, keys, scanned)
+				break
+			}
+		}
+	}
+}
@@ -130,6 +130,12 @@ type Result struct {
 
 	// Keys is set by some operations instead of returning the rows themselves.
 	Keys []roachpb.Key
+
+	// ResumeSpan is set by Scan and ReverseScan if a limit stopped them
+	// before they covered their whole span. It is the part of the span
+	// which remains to be scanned, from which the scan can be resumed to
+	// retrieve the next page of rows.
+	ResumeSpan *roachpb.Span
 }
 
 func (r Result) String() string {
@@ -299,6 +305,18 @@ func (db *DB) DelRange(begin, end interface{}) *roachpb.Error {
 	return pErr
 }
 
+// ClearRange removes all the values, including all of their versions,
+// between begin (inclusive) and end (exclusive). It must not be used in
+// a transaction.
+//
+// key can be either a byte slice or a string.
+func (db *DB) ClearRange(begin, end interface{}) *roachpb.Error {
+	b := db.NewBatch()
+	b.ClearRange(begin, end)
+	_, pErr := runOneResult(db, b)
+	return pErr
+}
+
 // AdminMerge merges the range containing key and the subsequent
 // range. After the merge operation is complete, the range containing
 // key will contain all of the key/value pairs of the subsequent range
@@ -334,13 +352,13 @@ func (db *DB) CheckConsistency(begin, end interface{}) *roachpb.Error {
 // sendAndFill is a helper which sends the given batch and fills its results,
 // returning the appropriate error which is either from the first failing call,
 // or an "internal" error.
-func sendAndFill(send func(int64, ...roachpb.Request) (*roachpb.BatchResponse, *roachpb.Error), b *Batch) (*roachpb.BatchResponse, *roachpb.Error) {
+func sendAndFill(send func(roachpb.Header, ...roachpb.Request) (*roachpb.BatchResponse, *roachpb.Error), b *Batch) (*roachpb.BatchResponse, *roachpb.Error) {
 	// Errors here will be attached to the results, so we will get them from
 	// the call to fillResults in the regular case in which an individual call
 	// fails. But send() also returns its own errors, so there's some dancing
 	// here to do because we want to run fillResults() so that the individual
 	// result gets initialized with an error from the corresponding call.
-	br, pErr := send(b.MaxScanResults, b.reqs...)
+	br, pErr := send(b.header(), b.reqs...)
 	if pErr != nil {
 		// Discard errors from fillResults.
 		_ = b.fillResults(nil, pErr)
@@ -396,19 +414,13 @@ func (db *DB) Txn(retryable func(txn *Txn) *roachpb.Error) *roachpb.Error {
 
 // send runs the specified calls synchronously in a single batch and returns
 // any errors. Returns a nil response for empty input (no requests).
-func (db *DB) send(maxScanResults int64, reqs ...roachpb.Request) (
+func (db *DB) send(h roachpb.Header, reqs ...roachpb.Request) (
 	*roachpb.BatchResponse, *roachpb.Error) {
 	if len(reqs) == 0 {
 		return nil, nil
 	}
 
-	ba := roachpb.BatchRequest{}
-	ba.Add(reqs...)
-
-	ba.MaxScanResults = maxScanResults
-	if db.userPriority != 1 {
-		ba.UserPriority = db.userPriority
-	}
+	ba := db.batchRequest(h, reqs)
 
 	tracing.AnnotateTrace()
 
@@ -422,6 +434,39 @@ func (db *DB) send(maxScanResults int64, reqs ...roachpb.Request) (
 	return br, nil
 }
 
+// sendStream is like send, but streams the responses to f instead of
+// returning them.
+func (db *DB) sendStream(h roachpb.Header, reqs []roachpb.Request,
+	f func(*roachpb.BatchResponse) (bool, error)) *roachpb.Error {
+	if len(reqs) == 0 {
+		return nil
+	}
+
+	ba := db.batchRequest(h, reqs)
+
+	tracing.AnnotateTrace()
+
+	pErr := SendStream(db.sender, context.TODO(), ba, f)
+	if pErr != nil && log.V(1) {
+		log.Infof("failed batch: %s", pErr)
+	}
+	return pErr
+}
+
+// batchRequest returns a batch of the specified calls with the header.
+func (db *DB) batchRequest(h roachpb.Header, reqs []roachpb.Request) roachpb.BatchRequest {
+	ba := roachpb.BatchRequest{}
+	ba.Add(reqs...)
+
+	ba.MaxScanResults = h.MaxScanResults
+	ba.TargetBytes = h.TargetBytes
+	ba.AdmissionClass = h.AdmissionClass
+	if db.userPriority != 1 {
+		ba.UserPriority = db.userPriority
+	}
+	return ba
+}
+
 // Runner only exports the Run method on a batch of operations.
 type Runner interface {
 	Run(b *Batch) *roachpb.Error
@@ -317,36 +317,39 @@ func TestCommonMethods(t *testing.T) {
 		// request with the information that this particular Get must be
 		// unmarshaled, which didn't seem worth doing as we're not using
 		// Batch.GetProto at the moment.
-		key{dbType, "GetProto"}:                   {},
-		key{txnType, "GetProto"}:                  {},
-		key{batchType, "CheckConsistency"}:        {},
-		key{batchType, "InternalAddRequest"}:      {},
-		key{dbType, "AdminMerge"}:                 {},
-		key{dbType, "AdminSplit"}:                 {},
-		key{dbType, "CheckConsistency"}:           {},
-		key{dbType, "NewBatch"}:                   {},
-		key{dbType, "Run"}:                        {},
-		key{dbType, "RunWithResponse"}:            {},
-		key{dbType, "Txn"}:                        {},
-		key{dbType, "GetSender"}:                  {},
-		key{txnType, "Commit"}:                    {},
-		key{txnType, "CommitBy"}:                  {},
-		key{txnType, "CommitInBatch"}:             {},
-		key{txnType, "CommitInBatchWithResponse"}: {},
-		key{txnType, "CommitNoCleanup"}:           {},
-		key{txnType, "Rollback"}:                  {},
-		key{txnType, "Cleanup"}:                   {},
-		key{txnType, "DebugName"}:                 {},
-		key{txnType, "InternalSetPriority"}:       {},
-		key{txnType, "NewBatch"}:                  {},
-		key{txnType, "Exec"}:                      {},
-		key{txnType, "Run"}:                       {},
-		key{txnType, "RunWithResponse"}:           {},
-		key{txnType, "SetDebugName"}:              {},
-		key{txnType, "SetIsolation"}:              {},
-		key{txnType, "SetUserPriority"}:           {},
-		key{txnType, "SetSystemConfigTrigger"}:    {},
-		key{txnType, "SystemConfigTrigger"}:       {},
+		key{dbType, "GetProto"}:                     {},
+		key{txnType, "GetProto"}:                    {},
+		key{batchType, "CheckConsistency"}:          {},
+		key{batchType, "InternalAddRequest"}:        {},
+		key{dbType, "AdminMerge"}:                   {},
+		key{dbType, "AdminSplit"}:                   {},
+		key{dbType, "CheckConsistency"}:             {},
+		key{dbType, "NewBatch"}:                     {},
+		key{dbType, "Run"}:                          {},
+		key{dbType, "RunWithResponse"}:              {},
+		key{dbType, "Txn"}:                          {},
+		key{dbType, "GetSender"}:                    {},
+		key{txnType, "Commit"}:                      {},
+		key{txnType, "CommitBy"}:                    {},
+		key{txnType, "CommitInBatch"}:               {},
+		key{txnType, "CommitInBatchByWithResponse"}: {},
+		key{txnType, "CommitInBatchWithResponse"}:   {},
+		key{txnType, "CommitInBatches"}:             {},
+		key{txnType, "CommitNoCleanup"}:             {},
+		key{txnType, "Rollback"}:                    {},
+		key{txnType, "Cleanup"}:                     {},
+		key{txnType, "DebugName"}:                   {},
+		key{txnType, "InternalSetPriority"}:         {},
+		key{txnType, "NewBatch"}:                    {},
+		key{txnType, "Exec"}:                        {},
+		key{txnType, "Run"}:                         {},
+		key{txnType, "RunBatches"}:                  {},
+		key{txnType, "RunWithResponse"}:             {},
+		key{txnType, "SetDebugName"}:                {},
+		key{txnType, "SetIsolation"}:                {},
+		key{txnType, "SetUserPriority"}:             {},
+		key{txnType, "SetSystemConfigTrigger"}:      {},
+		key{txnType, "SystemConfigTrigger"}:         {},
 	}
 
 	for b := range blacklist {
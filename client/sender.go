@@ -28,6 +28,18 @@ type Sender interface {
 	Send(context.Context, roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error)
 }
 
+// StreamingSender is implemented by Senders which can pass on the responses
+// of the ranges spanned by a batch as they arrive, instead of combining them
+// in memory first.
+type StreamingSender interface {
+	Sender
+	// SendStream sends the batch and calls f with each of the responses in
+	// turn. If f returns true or an error, no further responses are
+	// retrieved. The responses passed to f before an error is returned are
+	// not to be relied upon.
+	SendStream(context.Context, roachpb.BatchRequest, func(*roachpb.BatchResponse) (bool, error)) *roachpb.Error
+}
+
 // SenderFunc is an adapter to allow the use of ordinary functions
 // as Senders.
 type SenderFunc func(context.Context, roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error)
@@ -37,6 +49,22 @@ func (f SenderFunc) Send(ctx context.Context, ba roachpb.BatchRequest) (*roachpb
 	return f(ctx, ba)
 }
 
+// SendStream sends the batch via the provided Sender, streaming the
+// responses to f if the Sender is a StreamingSender and calling f with the
+// whole response otherwise.
+func SendStream(sender Sender, ctx context.Context, ba roachpb.BatchRequest,
+	f func(*roachpb.BatchResponse) (bool, error)) *roachpb.Error {
+	if ss, ok := sender.(StreamingSender); ok {
+		return ss.SendStream(ctx, ba, f)
+	}
+	br, pErr := sender.Send(ctx, ba)
+	if pErr != nil {
+		return pErr
+	}
+	_, err := f(br)
+	return roachpb.NewError(err)
+}
+
 // SendWrappedWith is a convenience function which wraps the request in a batch
 // and sends it via the provided Sender at the given timestamp. It returns the
 // unwrapped response or an error. It's valid to pass a `nil` context;
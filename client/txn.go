@@ -48,37 +48,81 @@ type txnSender Txn
 
 func (ts *txnSender) Send(ctx context.Context, ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
 	// Send call through wrapped sender.
-	ba.Txn = &ts.Proto
-	if ts.UserPriority > 0 {
-		ba.UserPriority = ts.UserPriority
-	}
-
-	ctx = opentracing.ContextWithSpan(ctx, ts.Trace)
-
-	ba.SetNewRequest()
+	ctx = ts.prepare(ctx, &ba)
 	br, pErr := ts.wrapped.Send(ctx, ba)
 	if br != nil && br.Error != nil {
 		panic(roachpb.ErrorUnexpectedlySet(ts.wrapped, br))
 	}
 
 	if br != nil {
-		for _, encSp := range br.CollectedSpans {
-			var newSp basictracer.RawSpan
-			if err := tracing.DecodeRawSpan(encSp, &newSp); err != nil {
-				return nil, roachpb.NewError(err)
-			}
-			ts.CollectedSpans = append(ts.CollectedSpans, newSp)
+		if pErr := ts.collectSpans(br); pErr != nil {
+			return nil, pErr
 		}
 	}
 	// Only successful requests can carry an updated Txn in their response
 	// header. Any error (e.g. a restart) can have a Txn attached to them as
 	// well; those update our local state in the same way for the next attempt.
-	// The exception is if our transaction was aborted and needs to restart
-	// from scratch, in which case we do just that.
 	if pErr == nil {
 		ts.Proto.Update(br.Txn)
 		return br, nil
-	} else if _, ok := pErr.GetDetail().(*roachpb.TransactionAbortedError); ok {
+	}
+	ts.updateOnError(pErr)
+	return nil, pErr
+}
+
+// SendStream implements the StreamingSender interface, updating the
+// transaction with each of the responses streamed.
+func (ts *txnSender) SendStream(ctx context.Context, ba roachpb.BatchRequest,
+	f func(*roachpb.BatchResponse) (bool, error)) *roachpb.Error {
+	ctx = ts.prepare(ctx, &ba)
+	pErr := SendStream(ts.wrapped, ctx, ba, func(br *roachpb.BatchResponse) (bool, error) {
+		if br.Error != nil {
+			panic(roachpb.ErrorUnexpectedlySet(ts.wrapped, br))
+		}
+		if pErr := ts.collectSpans(br); pErr != nil {
+			return false, pErr.GoError()
+		}
+		ts.Proto.Update(br.Txn)
+		return f(br)
+	})
+	if pErr != nil {
+		ts.updateOnError(pErr)
+	}
+	return pErr
+}
+
+// prepare sets up the batch to be sent in the transaction, returning the
+// context to send it with.
+func (ts *txnSender) prepare(ctx context.Context, ba *roachpb.BatchRequest) context.Context {
+	ba.Txn = &ts.Proto
+	if ts.UserPriority > 0 {
+		ba.UserPriority = ts.UserPriority
+	}
+	if ts.AdmissionClass > ba.AdmissionClass {
+		ba.AdmissionClass = ts.AdmissionClass
+	}
+	ba.SetNewRequest()
+	return opentracing.ContextWithSpan(ctx, ts.Trace)
+}
+
+// collectSpans adds the trace spans collected in the response to those of
+// the transaction.
+func (ts *txnSender) collectSpans(br *roachpb.BatchResponse) *roachpb.Error {
+	for _, encSp := range br.CollectedSpans {
+		var newSp basictracer.RawSpan
+		if err := tracing.DecodeRawSpan(encSp, &newSp); err != nil {
+			return roachpb.NewError(err)
+		}
+		ts.CollectedSpans = append(ts.CollectedSpans, newSp)
+	}
+	return nil
+}
+
+// updateOnError updates the transaction with the one attached to the error,
+// for the next attempt. The exception is if our transaction was aborted and
+// needs to restart from scratch, in which case we do just that.
+func (ts *txnSender) updateOnError(pErr *roachpb.Error) {
+	if _, ok := pErr.GetDetail().(*roachpb.TransactionAbortedError); ok {
 		// On Abort, reset the transaction so we start anew on restart.
 		ts.Proto = roachpb.Transaction{
 			TxnMeta: roachpb.TxnMeta{
@@ -93,7 +137,6 @@ func (ts *txnSender) Send(ctx context.Context, ba roachpb.BatchRequest) (*roachp
 	} else if pErr.TransactionRestart != roachpb.TransactionRestart_ABORT {
 		ts.Proto.Update(pErr.GetTxn())
 	}
-	return nil, pErr
 }
 
 // Txn is an in-progress distributed database transaction. A Txn is not safe for
@@ -105,6 +148,10 @@ type Txn struct {
 	UserPriority   roachpb.UserPriority
 	Trace          opentracing.Span // can be nil
 	CollectedSpans []basictracer.RawSpan
+	// AdmissionClass is the lowest admission class of the batches of the
+	// transaction. Internal background processes should set it to
+	// roachpb.BACKGROUND.
+	AdmissionClass roachpb.AdmissionClass
 	// systemConfigTrigger is set to true when modifying keys from the SystemConfig
 	// span. This sets the SystemConfigTrigger on EndTransactionRequest.
 	systemConfigTrigger bool
@@ -335,6 +382,108 @@ func (txn *Txn) RunWithResponse(b *Batch) (*roachpb.BatchResponse, *roachpb.Erro
 	return sendAndFill(txn.send, b)
 }
 
+// RunBatches runs several batches like Run, but sends all of their
+// operations to the cluster in a single request, so that they are
+// executed concurrently. The batches must not limit the results of their
+// scans. If the request fails, the batch containing the failed operation
+// is passed an error whose Index refers to its own operations, and that
+// error is returned; the other batches are passed the error without an
+// Index.
+func (txn *Txn) RunBatches(batches ...*Batch) *roachpb.Error {
+	tracing.AnnotateTrace()
+	defer tracing.AnnotateTrace()
+
+	var reqs []roachpb.Request
+	for _, b := range batches {
+		if pErr := b.prepare(); pErr != nil {
+			return pErr
+		}
+		if b.MaxScanResults != 0 || b.TargetBytes != 0 {
+			return roachpb.NewErrorf("cannot run batches with limits together")
+		}
+		reqs = append(reqs, b.reqs...)
+	}
+	br, pErr := txn.send(roachpb.Header{}, reqs...)
+	offset := 0
+	if pErr != nil {
+		var ownerErr *roachpb.Error
+		for _, b := range batches {
+			bErr := &roachpb.Error{}
+			*bErr = *pErr
+			bErr.Index = nil
+			if pErr.Index != nil {
+				if index := int(pErr.Index.Index) - offset; index >= 0 && index < len(b.reqs) {
+					bErr.SetErrorIndex(int32(index))
+					ownerErr = bErr
+				}
+			}
+			// Discard errors from fillResults.
+			_ = b.fillResults(nil, bErr)
+			offset += len(b.reqs)
+		}
+		if ownerErr != nil {
+			return ownerErr
+		}
+		return pErr
+	}
+	for _, b := range batches {
+		responses := br.Responses[offset:]
+		if len(responses) > len(b.reqs) {
+			responses = responses[:len(b.reqs)]
+		}
+		if pErr := b.fillResults(&roachpb.BatchResponse{
+			BatchResponse_Header: br.BatchResponse_Header,
+			Responses:            responses,
+		}, nil); pErr != nil {
+			return pErr
+		}
+		offset += len(responses)
+	}
+	return nil
+}
+
+// RunStream runs a batch of Scan or ReverseScan operations like Run, but
+// instead of filling in the results of the batch it calls f with the rows
+// read from each of the ranges spanned by the batch as they arrive, which
+// bounds the memory used by large scans. The rows passed to f are those of
+// the operations in the order in which they were added to the batch. If f
+// returns true or an error, the ranges which remain are not read.
+func (txn *Txn) RunStream(b *Batch, f func(rows []KeyValue) (bool, error)) *roachpb.Error {
+	tracing.AnnotateTrace()
+	defer tracing.AnnotateTrace()
+
+	if pErr := b.prepare(); pErr != nil {
+		return pErr
+	}
+	if txn.Proto.Status != roachpb.PENDING {
+		return roachpb.NewErrorf("attempting to use %s transaction", txn.Proto.Status)
+	}
+	for _, args := range b.reqs {
+		switch args.(type) {
+		case *roachpb.ScanRequest, *roachpb.ReverseScanRequest:
+		default:
+			return roachpb.NewErrorf("cannot stream the results of %s", args.Method())
+		}
+	}
+	return txn.db.sendStream(b.header(), b.reqs, func(br *roachpb.BatchResponse) (bool, error) {
+		var rows []KeyValue
+		for _, union := range br.Responses {
+			// Operations outside of the range have no results.
+			var kvs []roachpb.KeyValue
+			switch reply := union.GetInner().(type) {
+			case *roachpb.ScanResponse:
+				kvs = reply.Rows
+			case *roachpb.ReverseScanResponse:
+				kvs = reply.Rows
+			}
+			for j := range kvs {
+				rows = append(rows, KeyValue{Key: kvs[j].Key, Value: &kvs[j].Value})
+			}
+		}
+		return f(rows)
+	})
+}
+
 func (txn *Txn) commit(deadline *roachpb.Timestamp) *roachpb.Error {
 	return txn.sendEndTxnReq(true /* commit */, deadline)
 }
@@ -342,6 +491,13 @@ func (txn *Txn) commit(deadline *roachpb.Timestamp) *roachpb.Error {
 // Cleanup cleans up the transaction as appropriate based on err.
 func (txn *Txn) Cleanup(pErr *roachpb.Error) {
 	if pErr != nil {
+		if _, ok := pErr.GetDetail().(*roachpb.AmbiguousResultError); ok {
+			// The transaction may have committed, in which case rolling it
+			// back would fail at best. If it didn't, its intents are cleaned
+			// up by the readers which run into them once it has expired.
+			log.Warningf("not aborting transaction %s whose commit is ambiguous: %s", txn.DebugName(), pErr)
+			return
+		}
 		if replyErr := txn.Rollback(); replyErr != nil {
 			log.Errorf("failure aborting transaction: %s; abort caused by: %s", replyErr, pErr)
 		}
@@ -368,14 +524,33 @@ func (txn *Txn) CommitInBatch(b *Batch) *roachpb.Error {
 // CommitInBatchWithResponse is a version of CommitInBatch that returns the
 // BatchResponse.
 func (txn *Txn) CommitInBatchWithResponse(b *Batch) (*roachpb.BatchResponse, *roachpb.Error) {
+	return txn.commitInBatch(b, nil)
+}
+
+// CommitInBatchByWithResponse is a version of CommitInBatchWithResponse
+// whose EndTransactionRequest has Deadline=deadline.
+func (txn *Txn) CommitInBatchByWithResponse(b *Batch, deadline roachpb.Timestamp) (*roachpb.BatchResponse, *roachpb.Error) {
+	return txn.commitInBatch(b, &deadline)
+}
+
+func (txn *Txn) commitInBatch(b *Batch, deadline *roachpb.Timestamp) (*roachpb.BatchResponse, *roachpb.Error) {
 	if txn != b.txn {
 		return nil, roachpb.NewErrorf("a batch b can only be committed by b.txn")
 	}
-	b.reqs = append(b.reqs, endTxnReq(true /* commit */, nil, txn.SystemConfigTrigger()))
+	b.reqs = append(b.reqs, endTxnReq(true /* commit */, deadline, txn.SystemConfigTrigger()))
 	b.initResult(1, 0, nil)
 	return txn.RunWithResponse(b)
 }
 
+// CommitInBatches runs several batches like RunBatches and commits the
+// transaction in the same request.
+func (txn *Txn) CommitInBatches(batches ...*Batch) *roachpb.Error {
+	commit := txn.NewBatch()
+	commit.reqs = append(commit.reqs, endTxnReq(true /* commit */, nil, txn.SystemConfigTrigger()))
+	commit.initResult(1, 0, nil)
+	return txn.RunBatches(append(batches, commit)...)
+}
+
 // Commit sends an EndTransactionRequest with Commit=true.
 func (txn *Txn) Commit() *roachpb.Error {
 	pErr := txn.commit(nil)
@@ -397,7 +572,7 @@ func (txn *Txn) Rollback() *roachpb.Error {
 }
 
 func (txn *Txn) sendEndTxnReq(commit bool, deadline *roachpb.Timestamp) *roachpb.Error {
-	_, pErr := txn.send(0, endTxnReq(commit, deadline, txn.SystemConfigTrigger()))
+	_, pErr := txn.send(roachpb.Header{}, endTxnReq(commit, deadline, txn.SystemConfigTrigger()))
 	return pErr
 }
 
@@ -429,6 +604,12 @@ type TxnExecOptions struct {
 	// encountered. If not set, committing or leaving open the txn is the
 	// responsibility of the client.
 	AutoCommit bool
+	// If set, OnRetry is called with the error which caused each automatic
+	// retry of the closure before it is retried.
+	OnRetry func(*roachpb.Error)
+	// If set, the closure is no longer retried automatically once Context
+	// is done.
+	Context context.Context
 }
 
 // Exec executes fn in the context of a distributed transaction.
@@ -457,8 +638,12 @@ func (txn *Txn) Exec(
 	if opt.AutoRetry {
 		retryOptions = txn.db.txnRetryOptions
 	}
+	ctx := opt.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
 RetryLoop:
-	for r := retry.Start(retryOptions); r.Next(); {
+	for r := retry.StartWithCtx(ctx, retryOptions); r.Next(); {
 		pErr = fn(txn, &opt)
 		if (pErr == nil) && opt.AutoCommit && (txn.Proto.Status == roachpb.PENDING) {
 			// fn succeeded, but didn't commit.
@@ -492,6 +677,9 @@ RetryLoop:
 			log.Infof("automatically retrying transaction: %s because of error: %s",
 				txn.DebugName(), pErr)
 		}
+		if opt.OnRetry != nil {
+			opt.OnRetry(pErr)
+		}
 	}
 	if txn != nil {
 		// TODO(andrei): don't do Cleanup() on retriable errors here.
@@ -510,7 +698,7 @@ RetryLoop:
 // EndTransaction call is silently dropped, allowing the caller to
 // always commit or clean-up explicitly even when that may not be
 // required (or even erroneous).
-func (txn *Txn) send(maxScanResults int64, reqs ...roachpb.Request) (
+func (txn *Txn) send(h roachpb.Header, reqs ...roachpb.Request) (
 	*roachpb.BatchResponse, *roachpb.Error) {
 
 	if txn.Proto.Status != roachpb.PENDING {
@@ -563,7 +751,7 @@ func (txn *Txn) send(maxScanResults int64, reqs ...roachpb.Request) (
 		reqs = reqs[:lastIndex]
 	}
 
-	br, pErr := txn.db.send(maxScanResults, reqs...)
+	br, pErr := txn.db.send(h, reqs...)
 	if elideEndTxn && pErr == nil {
 		// This normally happens on the server and sent back in response
 		// headers, but this transaction was optimized away. The caller may
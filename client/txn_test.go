@@ -192,6 +192,103 @@ func TestTransactionConfig(t *testing.T) {
 	}
 }
 
+// TestTxnAdmissionClass verifies that the batches of a transaction are sent
+// with at least the admission class of the transaction.
+func TestTxnAdmissionClass(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	var classes []roachpb.AdmissionClass
+	db := newDB(newTestSender(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+		classes = append(classes, ba.AdmissionClass)
+		return ba.CreateReply(), nil
+	}, nil))
+	if pErr := db.Txn(func(txn *Txn) *roachpb.Error {
+		if _, pErr := txn.Get("a"); pErr != nil {
+			return pErr
+		}
+		txn.AdmissionClass = roachpb.BACKGROUND
+		_, pErr := txn.Get("a")
+		return pErr
+	}); pErr != nil {
+		t.Errorf("unexpected error on commit: %s", pErr)
+	}
+	expected := []roachpb.AdmissionClass{roachpb.FOREGROUND, roachpb.BACKGROUND}
+	if !reflect.DeepEqual(expected, classes) {
+		t.Errorf("expected %s, got %s", expected, classes)
+	}
+}
+
+// TestTxnRunBatches verifies that batches run together are sent in a
+// single request, and that an error is attributed to the batch which
+// contains the failed operation.
+func TestTxnRunBatches(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	var calls [][]roachpb.Method
+	db := newDB(newTestSender(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+		calls = append(calls, ba.Methods())
+		if _, ok := ba.GetArg(roachpb.ConditionalPut); ok {
+			pErr := roachpb.NewError(&roachpb.ConditionFailedError{})
+			pErr.SetErrorIndex(2)
+			return nil, pErr
+		}
+		return ba.CreateReply(), nil
+	}, nil))
+	txn := NewTxn(*db)
+	b1, b2 := txn.NewBatch(), txn.NewBatch()
+	b1.Put("a", "1")
+	b2.Put("b", "2")
+	if pErr := txn.RunBatches(b1, b2); pErr != nil {
+		t.Fatal(pErr)
+	}
+	if expected := [][]roachpb.Method{{roachpb.BeginTransaction, roachpb.Put, roachpb.Put}}; !reflect.DeepEqual(expected, calls) {
+		t.Fatalf("expected %s, got %s", expected, calls)
+	}
+	if string(b2.Results[0].Rows[0].Key) != "b" {
+		t.Errorf("unexpected results %+v", b2.Results)
+	}
+
+	b1, b2 = txn.NewBatch(), txn.NewBatch()
+	b1.Put("a", "1")
+	b2.Put("b", "2")
+	b2.CPut("c", "3", nil)
+	pErr := txn.RunBatches(b1, b2)
+	if pErr == nil || pErr.Index == nil || pErr.Index.Index != 1 {
+		t.Fatalf("expected error at index 1, got %v", pErr)
+	}
+	if b2.Results[1].PErr != pErr {
+		t.Errorf("expected the error to be attributed to the second batch")
+	}
+	if b1.Results[0].PErr == nil || b1.Results[0].PErr.Index != nil {
+		t.Errorf("expected an error without index for the first batch, got %v", b1.Results[0].PErr)
+	}
+}
+
+// TestTxnCommitInBatches verifies that batches committed together are sent
+// in a single request along with the EndTransaction.
+func TestTxnCommitInBatches(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	var calls [][]roachpb.Method
+	db := newDB(newTestSender(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+		calls = append(calls, ba.Methods())
+		return ba.CreateReply(), nil
+	}, nil))
+	txn := NewTxn(*db)
+	b1, b2 := txn.NewBatch(), txn.NewBatch()
+	b1.Put("a", "1")
+	b2.Put("b", "2")
+	if pErr := txn.CommitInBatches(b1, b2); pErr != nil {
+		t.Fatal(pErr)
+	}
+	expected := [][]roachpb.Method{
+		{roachpb.BeginTransaction, roachpb.Put, roachpb.Put, roachpb.EndTransaction},
+	}
+	if !reflect.DeepEqual(expected, calls) {
+		t.Fatalf("expected %s, got %s", expected, calls)
+	}
+	if string(b2.Results[0].Rows[0].Key) != "b" {
+		t.Errorf("unexpected results %+v", b2.Results)
+	}
+}
+
 // TestCommitReadOnlyTransaction verifies that transaction is
 // committed but EndTransaction is not sent if only read-only
 // operations were performed.
@@ -64,6 +64,27 @@ const (
 	// The value if a config.SystemConfig which holds all key/value
 	// pairs in the system DB span.
 	KeySystemConfig = "system-db"
+
+	// KeyTxnWaitsPrefix is the key prefix for gossiping the transaction
+	// waits of a store. The suffix is a store ID and the value is
+	// storage.TxnWaits.
+	KeyTxnWaitsPrefix = "txn-waits"
+
+	// KeyLeaderLeasePrefix is the key prefix for gossiping the leader lease
+	// of a range when it is acquired by a new holder. The suffix is a range
+	// ID and the value is a roachpb.Lease.
+	KeyLeaderLeasePrefix = "leader-lease"
+
+	// KeyStoreDrainingPrefix is the key prefix for gossiping whether a
+	// store is draining. The suffix is a store ID and the value is a single
+	// byte, 1 if the store is draining and 0 otherwise.
+	KeyStoreDrainingPrefix = "store-draining"
+
+	// KeyStoreWriteStalledPrefix is the key prefix for gossiping whether
+	// the engine of a store is stalling writes. The suffix is a store ID and
+	// the value is a single byte, 1 if the engine is stalling writes and 0
+	// otherwise.
+	KeyStoreWriteStalledPrefix = "store-write-stalled"
 )
 
 // MakeKey creates a canonical key under which to gossip a piece of
@@ -90,3 +111,27 @@ func MakeNodeIDKey(nodeID roachpb.NodeID) string {
 func MakeStoreKey(storeID roachpb.StoreID) string {
 	return MakeKey(KeyStorePrefix, storeID.String())
 }
+
+// MakeTxnWaitsKey returns the gossip key for the transaction waits of the
+// given store.
+func MakeTxnWaitsKey(storeID roachpb.StoreID) string {
+	return MakeKey(KeyTxnWaitsPrefix, storeID.String())
+}
+
+// MakeStoreDrainingKey returns the gossip key for whether the given store
+// is draining.
+func MakeStoreDrainingKey(storeID roachpb.StoreID) string {
+	return MakeKey(KeyStoreDrainingPrefix, storeID.String())
+}
+
+// MakeStoreWriteStalledKey returns the gossip key for whether the engine of
+// the given store is stalling writes.
+func MakeStoreWriteStalledKey(storeID roachpb.StoreID) string {
+	return MakeKey(KeyStoreWriteStalledPrefix, storeID.String())
+}
+
+// MakeLeaderLeaseKey returns the gossip key for the leader lease of the
+// given range.
+func MakeLeaderLeaseKey(rangeID roachpb.RangeID) string {
+	return MakeKey(KeyLeaderLeasePrefix, rangeID.String())
+}
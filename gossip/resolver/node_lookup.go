@@ -20,8 +20,11 @@ import (
 	"net"
 	"net/http"
 
+	"golang.org/x/net/context"
+
 	"github.com/cockroachdb/cockroach/base"
 	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/httputil"
 	"github.com/cockroachdb/cockroach/util/log"
 )
 
@@ -33,8 +36,9 @@ type nodeLookupResolver struct {
 	context *base.Context
 	typ     string
 	addr    string
-	// We need our own client so that we may specify timeouts.
-	httpClient *http.Client
+	// We need our own client so that we may specify timeouts. Requests are
+	// not retried; the gossip bootstrap loop will try again.
+	httpClient *httputil.Client
 }
 
 // Type returns the resolver type.
@@ -50,9 +54,12 @@ func (nl *nodeLookupResolver) GetAddress() (net.Addr, error) {
 		if err != nil {
 			return nil, err
 		}
-		nl.httpClient = &http.Client{
-			Transport: &http.Transport{TLSClientConfig: tlsConfig},
-			Timeout:   base.NetworkTimeout,
+		nl.httpClient = &httputil.Client{
+			HTTPClient: &http.Client{
+				Transport: &http.Transport{TLSClientConfig: tlsConfig},
+				Timeout:   base.NetworkTimeout,
+			},
+			Scheme: nl.context.HTTPRequestScheme(),
 		}
 	}
 
@@ -63,7 +70,7 @@ func (nl *nodeLookupResolver) GetAddress() (net.Addr, error) {
 
 	log.Infof("querying %s for gossip nodes", nl.addr)
 	// TODO(marc): put common URIs in base and reuse everywhere.
-	if err := util.GetJSON(nl.httpClient, nl.context.HTTPRequestScheme(), nl.addr, "/_status/details/local", &local); err != nil {
+	if err := nl.httpClient.GetJSON(context.Background(), nl.addr, "/_status/details/local", &local); err != nil {
 		return nil, err
 	}
 
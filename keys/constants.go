@@ -65,6 +65,12 @@ var (
 	// localStoreGossipSuffix stores gossip bootstrap metadata for this
 	// store, updated any time new gossip hosts are encountered.
 	localStoreGossipSuffix = []byte("goss")
+	// localStoreHLCUpperBoundSuffix stores an upper bound on the wall time
+	// of the node's hybrid logical clock, updated periodically.
+	localStoreHLCUpperBoundSuffix = []byte("hlcu")
+	// localStoreRangeDescriptorCacheSuffix stores the contents of the
+	// node's range descriptor cache, keyed by end key, updated periodically.
+	localStoreRangeDescriptorCacheSuffix = []byte("rngc")
 
 	// LocalRangeIDPrefix is the prefix identifying per-range data
 	// indexed by Range ID. The Range ID is appended to this prefix,
@@ -94,6 +100,9 @@ var (
 	localRaftTruncatedStateSuffix = []byte("rftt")
 	// localRangeLeaderLeaseSuffix is the suffix for a range leader lease.
 	localRangeLeaderLeaseSuffix = []byte("rll-")
+	// localRangeClosedTimestampSuffix is the suffix for a range's closed
+	// timestamp.
+	localRangeClosedTimestampSuffix = []byte("rlct")
 	// localRangeStatsSuffix is the suffix for range statistics.
 	localRangeStatsSuffix = []byte("stat")
 
@@ -133,11 +142,11 @@ var (
 	// localRangeTreeNodeSuffix is the suffix for keys storing
 	// range tree nodes.  The value is a struct of type RangeTreeNode.
 	localRangeTreeNodeSuffix = roachpb.RKey("rtn-")
-	// localTransactionSuffix specifies the key suffix for
+	// LocalTransactionSuffix specifies the key suffix for
 	// transaction records. The additional detail is the transaction id.
 	// NOTE: if this value changes, it must be updated in C++
 	// (storage/engine/rocksdb/db.cc).
-	localTransactionSuffix = roachpb.RKey("txn-")
+	LocalTransactionSuffix = roachpb.RKey("txn-")
 
 	// Meta1Prefix is the first level of key addressing. It is selected such that
 	// all range addressing records sort before any system tables which they
@@ -185,6 +194,14 @@ var (
 	// TimeseriesPrefix is the key prefix for all timeseries data.
 	TimeseriesPrefix = roachpb.Key(makeKey(SystemPrefix, roachpb.RKey("tsd")))
 
+	// IdempotencyPrefix is the key prefix for the responses recorded for
+	// admin requests carrying an idempotency key.
+	IdempotencyPrefix = roachpb.Key(makeKey(SystemPrefix, roachpb.RKey("idem-")))
+
+	// TableClearPrefix is the key prefix for the pending clears of the data
+	// of dropped tables.
+	TableClearPrefix = roachpb.Key(makeKey(SystemPrefix, roachpb.RKey("table-clear-")))
+
 	// TableDataMin is the start of the range of table data keys.
 	TableDataMin = roachpb.Key(encoding.EncodeVarintAscending(nil, math.MinInt64))
 	// TableDataMin is the end of the range of table data keys.
@@ -226,6 +243,7 @@ const (
 	DescriptorTableID = 3
 	UsersTableID      = 4
 	ZonesTableID      = 5
+	SettingsTableID   = 6
 
 	// Reserved IDs for other system tables. If you're adding a new system table,
 	// it probably belongs here.
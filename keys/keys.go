@@ -52,6 +52,24 @@ func StoreGossipKey() roachpb.Key {
 	return MakeStoreKey(localStoreGossipSuffix, nil)
 }
 
+// StoreHLCUpperBoundKey returns a store-local key for the upper bound on the
+// wall time of the node's hybrid logical clock.
+func StoreHLCUpperBoundKey() roachpb.Key {
+	return MakeStoreKey(localStoreHLCUpperBoundSuffix, nil)
+}
+
+// StoreRangeDescriptorCacheKey returns a store-local key for the cached
+// descriptor of the range with the given end key.
+func StoreRangeDescriptorCacheKey(endKey roachpb.RKey) roachpb.Key {
+	return MakeStoreKey(localStoreRangeDescriptorCacheSuffix, endKey)
+}
+
+// StoreRangeDescriptorCachePrefix returns the prefix of the store-local keys
+// of the cached range descriptors.
+func StoreRangeDescriptorCachePrefix() roachpb.Key {
+	return MakeStoreKey(localStoreRangeDescriptorCacheSuffix, nil)
+}
+
 // StoreStatusKey returns the key for accessing the store status for the
 // specified store ID.
 func StoreStatusKey(storeID int32) roachpb.Key {
@@ -70,6 +88,25 @@ func NodeStatusKey(nodeID int32) roachpb.Key {
 	return key
 }
 
+// TableClearKey returns the key recording the pending clear of the data of
+// the given dropped table.
+func TableClearKey(tableID uint32) roachpb.Key {
+	key := make(roachpb.Key, 0, len(TableClearPrefix)+9)
+	key = append(key, TableClearPrefix...)
+	key = encoding.EncodeUvarintAscending(key, uint64(tableID))
+	return key
+}
+
+// DecodeTableClearKey returns the table ID of a key created by
+// TableClearKey.
+func DecodeTableClearKey(key roachpb.Key) (uint32, error) {
+	if !bytes.HasPrefix(key, TableClearPrefix) {
+		return 0, util.Errorf("key %s does not have %s prefix", key, TableClearPrefix)
+	}
+	_, tableID, err := encoding.DecodeUvarintAscending(key[len(TableClearPrefix):])
+	return uint32(tableID), err
+}
+
 func makePrefixWithRangeID(prefix []byte, rangeID roachpb.RangeID, infix roachpb.RKey) roachpb.Key {
 	// Size the key buffer so that it is large enough for most callers.
 	key := make(roachpb.Key, 0, 32)
@@ -185,6 +222,12 @@ func RangeLeaderLeaseKey(rangeID roachpb.RangeID) roachpb.Key {
 	return MakeRangeIDReplicatedKey(rangeID, localRangeLeaderLeaseSuffix, nil)
 }
 
+// RangeClosedTimestampKey returns a system-local key for a range's closed
+// timestamp.
+func RangeClosedTimestampKey(rangeID roachpb.RangeID) roachpb.Key {
+	return MakeRangeIDReplicatedKey(rangeID, localRangeClosedTimestampSuffix, nil)
+}
+
 // RangeStatsKey returns the key for accessing the MVCCStats struct
 // for the specified Range ID.
 func RangeStatsKey(rangeID roachpb.RangeID) roachpb.Key {
@@ -307,7 +350,7 @@ func RangeDescriptorKey(key roachpb.RKey) roachpb.Key {
 // transaction key and ID. The base key is encoded in order to
 // guarantee that all transaction records for a range sort together.
 func TransactionKey(key roachpb.Key, txnID *uuid.UUID) roachpb.Key {
-	return MakeRangeKey(Addr(key), localTransactionSuffix, roachpb.RKey(txnID.Bytes()))
+	return MakeRangeKey(Addr(key), LocalTransactionSuffix, roachpb.RKey(txnID.Bytes()))
 }
 
 // Addr returns the address for the key, used to lookup the range containing
@@ -96,6 +96,7 @@ var (
 		{name: "RangeLastReplicaGCTimestamp", suffix: localRangeLastReplicaGCTimestampSuffix},
 		{name: "RangeLastVerificationTimestamp", suffix: localRangeLastVerificationTimestampSuffix},
 		{name: "RangeLeaderLease", suffix: localRangeLeaderLeaseSuffix},
+		{name: "RangeClosedTimestamp", suffix: localRangeClosedTimestampSuffix},
 		{name: "RangeStats", suffix: localRangeStatsSuffix},
 	}
 
@@ -106,7 +107,7 @@ var (
 	}{
 		{name: "RangeDescriptor", suffix: LocalRangeDescriptorSuffix, atEnd: true},
 		{name: "RangeTreeNode", suffix: localRangeTreeNodeSuffix, atEnd: true},
-		{name: "Transaction", suffix: localTransactionSuffix, atEnd: false},
+		{name: "Transaction", suffix: LocalTransactionSuffix, atEnd: false},
 	}
 )
 
@@ -115,6 +116,10 @@ func localStoreKeyPrint(key roachpb.Key) string {
 		return "/storeIdent"
 	} else if bytes.HasPrefix(key, localStoreGossipSuffix) {
 		return "/gossipBootstrap"
+	} else if bytes.HasPrefix(key, localStoreHLCUpperBoundSuffix) {
+		return "/hlcUpperBound"
+	} else if bytes.HasPrefix(key, localStoreRangeDescriptorCacheSuffix) {
+		return fmt.Sprintf("/rangeDescriptorCache%s", roachpb.Key(key[len(localStoreRangeDescriptorCacheSuffix):]))
 	}
 
 	return fmt.Sprintf("%q", []byte(key))
@@ -17,6 +17,8 @@
 package kv
 
 import (
+	"bytes"
+
 	"github.com/cockroachdb/cockroach/keys"
 	"github.com/cockroachdb/cockroach/roachpb"
 	"github.com/cockroachdb/cockroach/util"
@@ -107,6 +109,45 @@ func truncate(ba roachpb.BatchRequest, rs roachpb.RSpan) (roachpb.BatchRequest,
 	return ba, len(ba.Requests) - numNoop, nil
 }
 
+// resumeSpan returns the part of the span of the given bounded request which
+// remains to be processed after the request was executed on the ranges up to
+// and including desc, where resume is the resume span returned by the last
+// range for the request (if any). A range only sees the part of the request's
+// span which was truncated to it, so its resume span is extended to the rest
+// of the request's span. Without one, the request resumes with the part of its
+// span beyond desc. Nil is returned if nothing remains.
+func resumeSpan(args roachpb.Request, resume *roachpb.Span, desc *roachpb.RangeDescriptor,
+	isReverse bool) *roachpb.Span {
+	header := args.Header()
+	span := roachpb.Span{Key: header.Key, EndKey: header.EndKey}
+	switch {
+	case resume != nil && isReverse:
+		span.EndKey = resume.EndKey
+	case resume != nil:
+		span.Key = resume.Key
+	case isReverse:
+		// Local key ranges can't span ranges, so they are either entirely
+		// processed or not at all.
+		if !keys.Addr(header.Key).Less(desc.StartKey) {
+			return nil
+		}
+		if desc.StartKey.Less(keys.Addr(header.EndKey)) {
+			span.EndKey = desc.StartKey.AsRawKey()
+		}
+	default:
+		if !desc.EndKey.Less(keys.Addr(header.EndKey)) {
+			return nil
+		}
+		if keys.Addr(header.Key).Less(desc.EndKey) {
+			span.Key = desc.EndKey.AsRawKey()
+		}
+	}
+	if bytes.Compare(span.Key, span.EndKey) >= 0 {
+		return nil
+	}
+	return &span
+}
+
 // prev gives the right boundary of the union of all requests which don't
 // affect keys larger than the given key.
 // TODO(tschottdorf): again, better on BatchRequest itself, but can't pull
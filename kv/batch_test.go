@@ -18,6 +18,7 @@ package kv
 
 import (
 	"bytes"
+	"reflect"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/roachpb"
@@ -69,3 +70,40 @@ func TestBatchPrevNext(t *testing.T) {
 		}
 	}
 }
+
+// TestBatchResumeSpan tests resumeSpan.
+func TestBatchResumeSpan(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	desc := &roachpb.RangeDescriptor{StartKey: roachpb.RKey("c"), EndKey: roachpb.RKey("m")}
+	span := func(key, endKey string) *roachpb.Span {
+		return &roachpb.Span{Key: roachpb.Key(key), EndKey: roachpb.Key(endKey)}
+	}
+	testCases := []struct {
+		key, endKey string
+		resume      *roachpb.Span
+		reverse     bool
+		expected    *roachpb.Span
+	}{
+		// Resume spans are extended to the rest of the request.
+		{"a", "z", span("f", "m"), false, span("f", "z")},
+		{"a", "z", span("c", "f"), true, span("a", "f")},
+		{"d", "k", span("f", "k"), false, span("f", "k")},
+		// Requests which weren't limited resume beyond the range.
+		{"a", "z", nil, false, span("m", "z")},
+		{"a", "z", nil, true, span("a", "c")},
+		{"n", "z", nil, false, span("n", "z")},
+		{"a", "b", nil, true, span("a", "b")},
+		// Requests which were completed have nothing left.
+		{"a", "k", nil, false, nil},
+		{"d", "z", nil, true, nil},
+		{"d", "m", span("m", "m"), false, nil},
+	}
+	for i, test := range testCases {
+		args := &roachpb.ScanRequest{}
+		args.Key, args.EndKey = roachpb.Key(test.key), roachpb.Key(test.endKey)
+		actual := resumeSpan(args, test.resume, desc, test.reverse)
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("%d: expected resume span %v, got %v", i, test.expected, actual)
+		}
+	}
+}
@@ -19,6 +19,10 @@ package kv
 import (
 	"errors"
 	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 	"unsafe"
@@ -34,10 +38,78 @@ import (
 	"github.com/cockroachdb/cockroach/rpc"
 	"github.com/cockroachdb/cockroach/util/hlc"
 	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/metric"
 	"github.com/cockroachdb/cockroach/util/retry"
 	"github.com/cockroachdb/cockroach/util/tracing"
 )
 
+// distSenderMetrics holds the metrics of a DistSender.
+type distSenderMetrics struct {
+	// batches counts the batches sent.
+	batches *metric.Counter
+	// The retries counters count the errors of each type upon which a
+	// range was queried again.
+	retriesNotLeader        *metric.Counter
+	retriesRangeKeyMismatch *metric.Counter
+	retriesRangeNotFound    *metric.Counter
+	retriesSendError        *metric.Counter
+	// retryBudgetExceeded counts the batches which failed after exhausting
+	// their retry budget or deadline.
+	retryBudgetExceeded *metric.Counter
+	// batchesQueued counts the RPCs which waited for other RPCs to the same
+	// node to complete.
+	batchesQueued *metric.Counter
+	// ambiguousResults counts the batches committing a transaction which
+	// failed in a state where they may have been applied.
+	ambiguousResults *metric.Counter
+	// The cache counters count the lookups served by the range descriptor
+	// and leader caches and those which missed them.
+	rangeCacheHits    *metric.Counter
+	rangeCacheMisses  *metric.Counter
+	leaderCacheHits   *metric.Counter
+	leaderCacheMisses *metric.Counter
+	// The prefetch counters count the descriptors prefetched into the range
+	// descriptor cache by range lookups, those which were used and those
+	// which were removed from the cache without having been used.
+	prefetched     *metric.Counter
+	prefetchUsed   *metric.Counter
+	prefetchWasted *metric.Counter
+	// ranges records the number of ranges queried by each batch.
+	ranges *metric.Histogram
+	// localCalls and remoteCalls count the RPCs dispatched to the local
+	// server directly and those sent through gRPC respectively.
+	localCalls  *metric.Counter
+	remoteCalls *metric.Counter
+}
+
+func makeDistSenderMetrics(reg *metric.Registry) distSenderMetrics {
+	m := distSenderMetrics{
+		batches:                 reg.Counter("batches"),
+		retriesNotLeader:        reg.Counter("retries.notleader"),
+		retriesRangeKeyMismatch: reg.Counter("retries.rangekeymismatch"),
+		retriesRangeNotFound:    reg.Counter("retries.rangenotfound"),
+		retriesSendError:        reg.Counter("retries.senderror"),
+		retryBudgetExceeded:     reg.Counter("retries.budgetexceeded"),
+		ambiguousResults:        reg.Counter("errors.ambiguous"),
+		batchesQueued:           reg.Counter("batches.queued"),
+		rangeCacheHits:          reg.Counter("rangecache.hits"),
+		rangeCacheMisses:        reg.Counter("rangecache.misses"),
+		leaderCacheHits:         reg.Counter("leadercache.hits"),
+		leaderCacheMisses:       reg.Counter("leadercache.misses"),
+		prefetched:              reg.Counter("rangecache.prefetch.fetched"),
+		prefetchUsed:            reg.Counter("rangecache.prefetch.used"),
+		prefetchWasted:          reg.Counter("rangecache.prefetch.wasted"),
+		ranges:                  reg.Histogram("ranges", time.Minute, 1000, 1),
+		localCalls:              reg.Counter("rpcs.local"),
+		remoteCalls:             reg.Counter("rpcs.remote"),
+	}
+	reg.SetMetadata("ranges", metric.Metadata{
+		Unit: metric.UnitCount,
+		Help: "Number of ranges queried by each batch",
+	})
+	return m
+}
+
 // Default constants for timeouts.
 const (
 	defaultSendNextTimeout = 10 * time.Second // for now; see #2500
@@ -45,21 +117,35 @@ const (
 	retryBackoff           = 250 * time.Millisecond
 	maxRetryBackoff        = 30 * time.Second
 
-	// The default maximum number of ranges to return from a range
-	// lookup.
+	// The initial number of ranges to return from a range lookup, which
+	// is then adapted to the use of the prefetched descriptors.
 	defaultRangeLookupMaxRanges = 8
 	// The default size of the leader cache.
 	defaultLeaderCacheSize = 1 << 16
+	// The default duration for which cached leaders are used.
+	defaultLeaderCacheTTL = time.Minute
 	// The default size of the range descriptor cache.
 	defaultRangeDescriptorCacheSize = 1 << 20
+	// The default size of the read cache, if enabled.
+	defaultReadCacheSize = 1 << 10
+	// The default maximum number of RPCs in flight to a single node.
+	defaultMaxConcurrentBatchesPerNode = 1024
+	// The default time to live of read cache entries.
+	defaultReadCacheTTL = 1 * time.Second
 
 	opDistSender = "distributed sender"
 )
 
+// defaultRPCRetryOptions randomize backoffs by up to half in either
+// direction, so that the clients of a range which failed for all of them at
+// once, e.g. while it was unavailable, don't retry it in lockstep once it
+// recovers.
 var defaultRPCRetryOptions = retry.Options{
-	InitialBackoff: retryBackoff,
-	MaxBackoff:     maxRetryBackoff,
-	Multiplier:     2,
+	InitialBackoff:      retryBackoff,
+	MaxBackoff:          maxRetryBackoff,
+	Multiplier:          2,
+	RandomizationFactor: 0.5,
+	Jitter:              retry.JitterProportional,
 }
 
 // GetDefaultDistSenderRetryOptions returns the default retry options for a
@@ -114,8 +200,7 @@ type DistSender struct {
 	// ranges.
 	gossip *gossip.Gossip
 	// rangeCache caches replica metadata for key ranges.
-	rangeCache           *rangeDescriptorCache
-	rangeLookupMaxRanges int32
+	rangeCache *rangeDescriptorCache
 	// leaderCache caches the last known leader replica for range
 	// consensus groups.
 	leaderCache *leaderCache
@@ -124,6 +209,32 @@ type DistSender struct {
 	rpcSend         rpcSendFn
 	rpcContext      *rpc.Context
 	rpcRetryOptions retry.Options
+	// followerReads enables routing eligible reads to the nearest replica.
+	followerReads bool
+	// readCache, if set, caches INCONSISTENT point reads of designated
+	// keys.
+	readCache *readCache
+	// slowRequests, if set, retains the traces of slow requests.
+	slowRequests *tracing.SlowRequests
+	// sendParallelism is the maximum number of ranges queried concurrently
+	// by batches eligible for it.
+	sendParallelism int
+	// prefetchDescriptors is set if the descriptor of the next range of a
+	// multi-range query is looked up while the current range is queried.
+	prefetchDescriptors bool
+	metrics             distSenderMetrics
+	// sendNextTimeout and rpcTimeout are the SendNextTimeout and Timeout
+	// of the SendOptions of the RPCs sent to replicas.
+	sendNextTimeout time.Duration
+	rpcTimeout      time.Duration
+	// batchDeadline and rangeRetryBudget bound the retries of a batch; see
+	// DistSenderContext.
+	batchDeadline    time.Duration
+	rangeRetryBudget int
+	// limiter bounds the number of RPCs in flight to each node.
+	limiter *nodeLimiter
+	// storeHealth tracks the stores whose replicas are tried last.
+	storeHealth *storeHealth
 }
 
 var _ client.Sender = &DistSender{}
@@ -137,11 +248,22 @@ type rpcSendFn func(SendOptions, ReplicaSlice,
 type DistSenderContext struct {
 	Clock                    *hlc.Clock
 	RangeDescriptorCacheSize int32
-	// RangeLookupMaxRanges sets how many ranges will be prefetched into the
-	// range descriptor cache when dispatching a range lookup request.
+	// RangeLookupMaxRanges, if set, fixes how many ranges will be prefetched
+	// into the range descriptor cache when dispatching a range lookup
+	// request. By default, the number is adapted to how many of the
+	// prefetched descriptors are used.
 	RangeLookupMaxRanges int32
+	// RangeLookupRateLimit, if set, is the maximum number of range lookups
+	// per second reading meta1 and meta2 records each. Lookups in excess
+	// are delayed. Concurrent lookups of the same range are coalesced
+	// regardless.
+	RangeLookupRateLimit float64
 	LeaderCacheSize      int32
-	RPCRetryOptions      *retry.Options
+	// LeaderCacheTTL, if set, is the duration for which a cached leader is
+	// used before the range's replicas are tried in order again. Defaults
+	// to defaultLeaderCacheTTL.
+	LeaderCacheTTL  time.Duration
+	RPCRetryOptions *retry.Options
 	// nodeDescriptor, if provided, is used to describe which node the DistSender
 	// lives on, for instance when deciding where to send RPCs.
 	// Usually it is filled in from the Gossip network on demand.
@@ -152,13 +274,75 @@ type DistSenderContext struct {
 	RPCContext        *rpc.Context
 	RangeDescriptorDB RangeDescriptorDB
 	Tracer            opentracing.Tracer
+	// FollowerReads, if set, sends non-transactional reads at an explicit
+	// timestamp to the nearest replica instead of the leader. Replicas
+	// serve such reads if the timestamp is closed (see
+	// storage.StoreContext.ClosedTimestampTarget) and otherwise redirect to
+	// the leader.
+	FollowerReads bool
+	// ReadCachePrefixes, if set, enables a read-through cache for
+	// INCONSISTENT, non-transactional Get requests of keys with one of the
+	// given prefixes. Cached values are served for up to ReadCacheTTL and
+	// may thus be stale by that much more than inconsistent reads usually
+	// are.
+	ReadCachePrefixes []roachpb.Key
+	ReadCacheTTL      time.Duration
+	ReadCacheSize     int32
+	// SlowRequests, if set, retains the traces of requests which are slow
+	// to complete.
+	SlowRequests *tracing.SlowRequests
+	// SendParallelism, if greater than one, is the maximum number of ranges
+	// queried concurrently by a batch spanning several ranges whose
+	// responses are all needed regardless of each other: reads without
+	// limits which are either transactional or INCONSISTENT. Other batches
+	// query their ranges one after the other.
+	SendParallelism int
+	// PrefetchRangeDescriptors, if set, looks up the descriptor of the next
+	// range of a query spanning ranges in the background while the current
+	// range is queried, so that the query doesn't stall on the lookup once
+	// it gets there. The lookups run on the stopper of RPCContext, without
+	// which nothing is prefetched.
+	PrefetchRangeDescriptors bool
+	// Registry, if set, is the registry to which the metrics of the
+	// DistSender are added.
+	Registry *metric.Registry
+	// SendNextTimeout, if set, is the duration after which an RPC to a
+	// replica whose latency is unknown is speculatively sent to the next
+	// replica as well, and bounds the timeout derived from the latency of
+	// the others. Defaults to defaultSendNextTimeout.
+	SendNextTimeout time.Duration
+	// RPCTimeout, if set, is the maximum duration of an RPC to a replica
+	// before it fails. Defaults to base.NetworkTimeout.
+	RPCTimeout time.Duration
+	// BatchDeadline, if set, is the maximum duration of a batch including
+	// its retries, after which it fails with a RetryBudgetExceededError.
+	BatchDeadline time.Duration
+	// RangeRetryBudget, if set, is the maximum number of attempts to send a
+	// batch to a range, including those retried immediately on addressing
+	// errors, after which it fails with a RetryBudgetExceededError.
+	RangeRetryBudget int
+	// MaxConcurrentBatchesPerNode is the maximum number of RPCs sent
+	// concurrently to a single node. Further RPCs to the node queue until
+	// one completes, or fail once their timeout expires. Defaults to
+	// defaultMaxConcurrentBatchesPerNode; negative for no limit.
+	MaxConcurrentBatchesPerNode int
+	// TimeUntilStoreDead, if set, is the time after which a store whose
+	// descriptor wasn't gossiped is considered dead, and its replicas are
+	// tried last, like those of stores which gossiped that they are
+	// draining.
+	TimeUntilStoreDead time.Duration
+	// RangeDescriptors, if set, are added to the range descriptor cache
+	// when the DistSender is created, e.g. to warm it up with the
+	// descriptors persisted before a restart. Stale descriptors are
+	// evicted like any other once the requests using them fail.
+	RangeDescriptors []roachpb.RangeDescriptor
 }
 
 // NewDistSender returns a batch.Sender instance which connects to the
 // Cockroach cluster via the supplied gossip instance. Supplying a
 // DistSenderContext or the fields within is optional. For omitted values, sane
 // defaults will be used.
-func NewDistSender(ctx *DistSenderContext, gossip *gossip.Gossip) *DistSender {
+func NewDistSender(ctx *DistSenderContext, g *gossip.Gossip) *DistSender {
 	if ctx == nil {
 		ctx = &DistSenderContext{}
 	}
@@ -168,7 +352,7 @@ func NewDistSender(ctx *DistSenderContext, gossip *gossip.Gossip) *DistSender {
 	}
 	ds := &DistSender{
 		clock:  clock,
-		gossip: gossip,
+		gossip: g,
 	}
 	if ctx.nodeDescriptor != nil {
 		atomic.StorePointer(&ds.nodeDescriptor, unsafe.Pointer(ctx.nodeDescriptor))
@@ -182,14 +366,32 @@ func NewDistSender(ctx *DistSenderContext, gossip *gossip.Gossip) *DistSender {
 		rdb = ds
 	}
 	ds.rangeCache = newRangeDescriptorCache(rdb, int(rcSize))
+	ds.rangeCache.setLookupRateLimit(ctx.RangeLookupRateLimit)
+	ds.rangeCache.addRangeDescriptors(ctx.RangeDescriptors)
 	lcSize := ctx.LeaderCacheSize
 	if lcSize <= 0 {
 		lcSize = defaultLeaderCacheSize
 	}
-	ds.leaderCache = newLeaderCache(int(lcSize))
-	if ctx.RangeLookupMaxRanges <= 0 {
-		ds.rangeLookupMaxRanges = defaultRangeLookupMaxRanges
+	lcTTL := ctx.LeaderCacheTTL
+	if lcTTL <= 0 {
+		lcTTL = defaultLeaderCacheTTL
+	}
+	ds.leaderCache = newLeaderCache(int(lcSize), lcTTL)
+	if ctx.RangeLookupMaxRanges > 0 {
+		ds.rangeCache.setLookupMaxRanges(ctx.RangeLookupMaxRanges)
+	}
+	ds.prefetchDescriptors = ctx.PrefetchRangeDescriptors && ctx.RPCContext != nil
+	registry := ctx.Registry
+	if registry == nil {
+		registry = metric.NewRegistry()
 	}
+	ds.metrics = makeDistSenderMetrics(registry)
+	ds.rangeCache.prefetch.prefetched = ds.metrics.prefetched
+	ds.rangeCache.prefetch.used = ds.metrics.prefetchUsed
+	ds.rangeCache.prefetch.wasted = ds.metrics.prefetchWasted
+	registry.GaugeFunc("rangelookup.maxranges", func() int64 {
+		return int64(ds.rangeCache.lookupMaxRanges())
+	})
 	ds.rpcSend = send
 	if ctx.RPCSend != nil {
 		ds.rpcSend = ctx.RPCSend
@@ -201,15 +403,72 @@ func NewDistSender(ctx *DistSenderContext, gossip *gossip.Gossip) *DistSender {
 	if ctx.RPCRetryOptions != nil {
 		ds.rpcRetryOptions = *ctx.RPCRetryOptions
 	}
+	ds.followerReads = ctx.FollowerReads
+	ds.slowRequests = ctx.SlowRequests
+	ds.sendParallelism = ctx.SendParallelism
+	ds.sendNextTimeout = ctx.SendNextTimeout
+	if ds.sendNextTimeout <= 0 {
+		ds.sendNextTimeout = defaultSendNextTimeout
+	}
+	ds.rpcTimeout = ctx.RPCTimeout
+	if ds.rpcTimeout <= 0 {
+		ds.rpcTimeout = base.NetworkTimeout
+	}
+	ds.batchDeadline = ctx.BatchDeadline
+	ds.rangeRetryBudget = ctx.RangeRetryBudget
+	if limit := ctx.MaxConcurrentBatchesPerNode; limit >= 0 {
+		if limit == 0 {
+			limit = defaultMaxConcurrentBatchesPerNode
+		}
+		ds.limiter = newNodeLimiter(limit, ds.metrics.batchesQueued)
+	}
+	if len(ctx.ReadCachePrefixes) > 0 {
+		ttl := ctx.ReadCacheTTL
+		if ttl <= 0 {
+			ttl = defaultReadCacheTTL
+		}
+		size := ctx.ReadCacheSize
+		if size <= 0 {
+			size = defaultReadCacheSize
+		}
+		ds.readCache = newReadCache(ctx.ReadCachePrefixes, ttl, int(size), clock)
+	}
 	if ctx.Tracer != nil {
 		ds.Tracer = ctx.Tracer
 	} else {
 		ds.Tracer = tracing.NewTracer()
 	}
+	ds.storeHealth = newStoreHealth(clock, ctx.TimeUntilStoreDead)
+	if ds.gossip != nil {
+		ds.gossip.RegisterCallback(gossip.MakePrefixPattern(gossip.KeyLeaderLeasePrefix),
+			ds.leaderLeaseGossipUpdate)
+		ds.gossip.RegisterCallback(gossip.MakePrefixPattern(gossip.KeyStorePrefix),
+			ds.storeHealth.storeGossipUpdate)
+		ds.gossip.RegisterCallback(gossip.MakePrefixPattern(gossip.KeyStoreDrainingPrefix),
+			ds.storeHealth.drainingGossipUpdate)
+	}
 
 	return ds
 }
 
+// leaderLeaseGossipUpdate is the gossip callback updating the leader cache
+// with the leader leases gossiped by their new holders, so that lease
+// transfers are reflected without a NotLeaderError round trip.
+func (ds *DistSender) leaderLeaseGossipUpdate(key string, content roachpb.Value) {
+	var lease roachpb.Lease
+	if err := content.GetProto(&lease); err != nil {
+		log.Error(err)
+		return
+	}
+	id := strings.TrimPrefix(key, gossip.MakeKey(gossip.KeyLeaderLeasePrefix, ""))
+	rangeID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		log.Errorf("invalid leader lease gossip key %q: %s", key, err)
+		return
+	}
+	ds.updateLeaderCache(roachpb.RangeID(rangeID), lease.Replica)
+}
+
 // RangeLookup dispatches a RangeLookup request for the given metadata
 // key to the replicas of the given range. Note that we allow
 // inconsistent reads when doing range lookups for efficiency. Getting
@@ -218,8 +477,9 @@ func NewDistSender(ctx *DistSenderContext, gossip *gossip.Gossip) *DistSender {
 // lookups may be required. Note also that rangeLookup bypasses the
 // DistSender's Send() method, so there is no error inspection and
 // retry logic here; this is not an issue since the lookup performs a
-// single inconsistent read only.
-func (ds *DistSender) RangeLookup(key roachpb.RKey, desc *roachpb.RangeDescriptor, considerIntents, useReverseScan bool) ([]roachpb.RangeDescriptor, *roachpb.Error) {
+// single inconsistent read only. The lookup is traced as a child of the span
+// of the given context, if any.
+func (ds *DistSender) RangeLookup(ctx context.Context, key roachpb.RKey, desc *roachpb.RangeDescriptor, considerIntents, useReverseScan bool) ([]roachpb.RangeDescriptor, *roachpb.Error) {
 	ba := roachpb.BatchRequest{}
 	ba.ReadConsistency = roachpb.INCONSISTENT
 	ba.Add(&roachpb.RangeLookupRequest{
@@ -228,16 +488,22 @@ func (ds *DistSender) RangeLookup(key roachpb.RKey, desc *roachpb.RangeDescripto
 			// lookup; those are never local.
 			Key: key.AsRawKey(),
 		},
-		MaxRanges:       ds.rangeLookupMaxRanges,
+		MaxRanges:       ds.rangeCache.lookupMaxRanges(),
 		ConsiderIntents: considerIntents,
 		Reverse:         useReverseScan,
 	})
 	replicas := newReplicaSlice(ds.gossip, desc)
-	trace := ds.Tracer.StartSpan("range lookup")
+	var trace opentracing.Span
+	if parent := opentracing.SpanFromContext(ctx); parent != nil {
+		trace = opentracing.StartChildSpan(parent, "range lookup")
+	} else {
+		trace = ds.Tracer.StartSpan("range lookup")
+	}
 	defer trace.Finish()
-	// TODO(tschottdorf): Ideally we would use the trace of the request which
-	// caused this lookup instead of a new one.
-	br, err := ds.sendRPC(trace, desc.RangeID, replicas, orderRandom, ba)
+	// Only the trace of the request is carried over: the lookup may be
+	// shared by other requests, so it mustn't be canceled along with it.
+	br, err := ds.sendRPC(opentracing.ContextWithSpan(context.Background(), trace),
+		desc.RangeID, replicas, orderRandom, ba)
 	if err != nil {
 		return nil, err
 	}
@@ -260,7 +526,27 @@ func (ds *DistSender) FirstRange() (*roachpb.RangeDescriptor, *roachpb.Error) {
 	return rangeDesc, nil
 }
 
+// optimizeReplicaOrder orders the replicas in which the RPCs to a range are
+// tried and returns the policy with which the RPC layer refines the order.
 func (ds *DistSender) optimizeReplicaOrder(replicas ReplicaSlice) orderingPolicy {
+	order := ds.orderReplicasByProximity(replicas)
+	// Try the replicas on stores which are draining or dead last, even if
+	// they are close. Ordering by latency would undo this, so the order of
+	// the other replicas is settled here.
+	if n := ds.storeHealth.demoteSuspects(replicas); n > 0 {
+		if order == orderRandom {
+			replicas.randPerm(0, len(replicas)-n-1, rand.Intn)
+		}
+		return orderStable
+	}
+	return order
+}
+
+// orderReplicasByProximity orders the replicas by attribute affinity to the
+// local node, putting the local replica first. Once the latencies to some of
+// the replicas have been measured, the RPC layer orders them by latency,
+// falling back to this order for the others.
+func (ds *DistSender) orderReplicasByProximity(replicas ReplicaSlice) orderingPolicy {
 	// Unless we know better, send the RPCs randomly.
 	order := orderingPolicy(orderRandom)
 	nodeDesc := ds.getNodeDescriptor()
@@ -281,9 +567,31 @@ func (ds *DistSender) optimizeReplicaOrder(replicas ReplicaSlice) orderingPolicy
 		replicas.MoveToFront(i)
 		order = orderStable
 	}
+	if ds.knowsLatency(replicas) {
+		if order == orderRandom {
+			// Spread the load randomly among the replicas whose latency
+			// isn't known.
+			replicas.randPerm(0, len(replicas)-1, rand.Intn)
+		}
+		return orderLatency
+	}
 	return order
 }
 
+// knowsLatency returns whether the heartbeat latency to any of the replicas
+// has been measured.
+func (ds *DistSender) knowsLatency(replicas ReplicaSlice) bool {
+	if ds.rpcContext == nil {
+		return false
+	}
+	for _, r := range replicas {
+		if _, ok := ds.rpcContext.RemoteLatency(r.NodeDesc.Address.String()); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // getNodeDescriptor returns ds.nodeDescriptor, but makes an attempt to load
 // it from the Gossip network if a nil value is found.
 // We must jump through hoops here to get the node descriptor because it's not available
@@ -319,9 +627,9 @@ func (ds *DistSender) getNodeDescriptor() *roachpb.NodeDescriptor {
 // leader) and then sent via Send, with requirement that one RPC to a server
 // must succeed. Returns an RPC error if the request could not be sent. Note
 // that the reply may contain a higher level error and must be checked in
-// addition to the RPC error.
-// TODO(tschottdorf): should take a context instead of a Span.
-func (ds *DistSender) sendRPC(sp opentracing.Span, rangeID roachpb.RangeID, replicas ReplicaSlice,
+// addition to the RPC error. The RPCs are abandoned once the context, which
+// must carry the trace of the request, is done.
+func (ds *DistSender) sendRPC(ctx context.Context, rangeID roachpb.RangeID, replicas ReplicaSlice,
 	order orderingPolicy, ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
 	if len(replicas) == 0 {
 		return nil, roachpb.NewError(noNodeAddrsAvailError{})
@@ -335,9 +643,14 @@ func (ds *DistSender) sendRPC(sp opentracing.Span, rangeID roachpb.RangeID, repl
 	// Set RPC opts with stipulation that one of N RPCs must succeed.
 	rpcOpts := SendOptions{
 		Ordering:        order,
-		SendNextTimeout: defaultSendNextTimeout,
-		Timeout:         base.NetworkTimeout,
-		Trace:           sp,
+		SendNextTimeout: ds.sendNextTimeout,
+		Timeout:         ds.rpcTimeout,
+		Hedge:           ds.anyReplicaCanServe(ba),
+		Context:         ctx,
+		Trace:           opentracing.SpanFromContext(ctx),
+		limiter:         ds.limiter,
+		localCalls:      ds.metrics.localCalls,
+		remoteCalls:     ds.metrics.remoteCalls,
 	}
 	tracing.AnnotateTrace()
 	defer tracing.AnnotateTrace()
@@ -355,11 +668,14 @@ func (ds *DistSender) sendRPC(sp opentracing.Span, rangeID roachpb.RangeID, repl
 // returned first; the returned bool is true in case the given range reaches
 // outside the first descriptor.
 // In case either of the descriptors is discovered stale, the returned closure
-// should be called; it evicts the cache appropriately.
+// should be called; it evicts the cache appropriately and logs the eviction
+// to the trace.
 // Note that `from` and `to` are not necessarily Key and EndKey from a
 // RequestHeader; it's assumed that they've been translated to key addresses
 // already (via KeyAddress).
-func (ds *DistSender) getDescriptors(rs roachpb.RSpan, considerIntents, useReverseScan bool) (*roachpb.RangeDescriptor, bool, func(), *roachpb.Error) {
+// The context must carry the trace of the request, of which the range lookups
+// become part.
+func (ds *DistSender) getDescriptors(ctx context.Context, rs roachpb.RSpan, considerIntents, useReverseScan bool) (*roachpb.RangeDescriptor, bool, func(), *roachpb.Error) {
 	var desc *roachpb.RangeDescriptor
 	var pErr *roachpb.Error
 	var descKey roachpb.RKey
@@ -368,7 +684,14 @@ func (ds *DistSender) getDescriptors(rs roachpb.RSpan, considerIntents, useRever
 	} else {
 		descKey = rs.EndKey
 	}
-	desc, pErr = ds.rangeCache.LookupRangeDescriptor(descKey, considerIntents, useReverseScan)
+	if _, desc = ds.rangeCache.getCachedRangeDescriptor(descKey, useReverseScan); desc != nil {
+		ds.metrics.rangeCacheHits.Inc(1)
+		// The descriptor may have been prefetched by an earlier lookup.
+		ds.rangeCache.prefetch.hit(desc)
+	} else {
+		ds.metrics.rangeCacheMisses.Inc(1)
+		desc, pErr = ds.rangeCache.LookupRangeDescriptor(ctx, descKey, considerIntents, useReverseScan)
+	}
 
 	if pErr != nil {
 		return nil, false, nil, pErr
@@ -383,6 +706,7 @@ func (ds *DistSender) getDescriptors(rs roachpb.RSpan, considerIntents, useRever
 	}
 
 	evict := func() {
+		opentracing.SpanFromContext(ctx).LogEvent(fmt.Sprintf("evicting cached range descriptor [%s, %s)", desc.StartKey, desc.EndKey))
 		ds.rangeCache.EvictCachedRangeDescriptor(descKey, desc, useReverseScan)
 	}
 
@@ -390,10 +714,17 @@ func (ds *DistSender) getDescriptors(rs roachpb.RSpan, considerIntents, useRever
 }
 
 // sendSingleRange gathers and rearranges the replicas, and makes an RPC call.
-func (ds *DistSender) sendSingleRange(trace opentracing.Span, ba roachpb.BatchRequest, desc *roachpb.RangeDescriptor) (*roachpb.BatchResponse, *roachpb.Error) {
+// The context must carry the trace of the request.
+func (ds *DistSender) sendSingleRange(ctx context.Context, ba roachpb.BatchRequest, desc *roachpb.RangeDescriptor) (*roachpb.BatchResponse, *roachpb.Error) {
+	trace := opentracing.SpanFromContext(ctx)
 	trace.LogEvent(fmt.Sprintf("sending RPC to [%s, %s)", desc.StartKey, desc.EndKey))
 
 	leader := ds.leaderCache.Lookup(roachpb.RangeID(desc.RangeID))
+	if leader.StoreID > 0 {
+		ds.metrics.leaderCacheHits.Inc(1)
+	} else {
+		ds.metrics.leaderCacheMisses.Inc(1)
+	}
 
 	// Try to send the call.
 	replicas := newReplicaSlice(ds.gossip, desc)
@@ -405,8 +736,7 @@ func (ds *DistSender) sendSingleRange(trace opentracing.Span, ba roachpb.BatchRe
 
 	// If this request needs to go to a leader and we know who that is, move
 	// it to the front.
-	if !(ba.IsReadOnly() && ba.ReadConsistency == roachpb.INCONSISTENT) &&
-		leader.StoreID > 0 {
+	if !ds.anyReplicaCanServe(ba) && leader.StoreID > 0 {
 		if i := replicas.FindReplica(leader.StoreID); i >= 0 {
 			replicas.MoveToFront(i)
 			order = orderStable
@@ -422,7 +752,7 @@ func (ds *DistSender) sendSingleRange(trace opentracing.Span, ba roachpb.BatchRe
 	ba.SetNewRequest()
 
 	// TODO(tschottdorf): should serialize the trace here, not higher up.
-	br, pErr := ds.sendRPC(trace, desc.RangeID, replicas, order, ba)
+	br, pErr := ds.sendRPC(ctx, desc.RangeID, replicas, order, ba)
 	if pErr != nil {
 		return nil, pErr
 	}
@@ -432,6 +762,20 @@ func (ds *DistSender) sendSingleRange(trace opentracing.Span, ba roachpb.BatchRe
 	return br, pErr
 }
 
+// anyReplicaCanServe returns true if the batch doesn't need to go to the
+// leader: it is an inconsistent read, or it may be sent to a follower.
+func (ds *DistSender) anyReplicaCanServe(ba roachpb.BatchRequest) bool {
+	return (ba.IsReadOnly() && ba.ReadConsistency == roachpb.INCONSISTENT) || ds.canSendToFollower(ba)
+}
+
+// canSendToFollower returns true if the batch may be served by a replica
+// other than the leader. This is the case for non-transactional reads at an
+// explicit timestamp when follower reads are enabled.
+func (ds *DistSender) canSendToFollower(ba roachpb.BatchRequest) bool {
+	return ds.followerReads && ba.IsReadOnly() && ba.Txn == nil &&
+		!ba.Timestamp.Equal(roachpb.ZeroTimestamp)
+}
+
 // Send implements the batch.Sender interface. It subdivides
 // the Batch into batches admissible for sending (preventing certain
 // illegal mixtures of requests), executes each individual part
@@ -444,7 +788,76 @@ func (ds *DistSender) sendSingleRange(trace opentracing.Span, ba roachpb.BatchRe
 // of a transaction, but no entry. Pushing such a transaction will succeed, and
 // may lead to the transaction being aborted early.
 func (ds *DistSender) Send(ctx context.Context, ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+	if ds.readCache == nil {
+		return ds.send(ctx, ba, nil)
+	}
+	if br := ds.readCache.Lookup(ba); br != nil {
+		return br, nil
+	}
+	br, pErr := ds.send(ctx, ba, nil)
+	ds.readCache.Update(ba, br)
+	return br, pErr
+}
+
+// SendStream implements the client.StreamingSender interface. Read-only
+// batches which don't need to be split into chunks are sent to the ranges
+// they span one after the other, and f is called with the response of each
+// range as soon as it arrives instead of combining them. The ranges are
+// queried in the order of the keys, or in reverse for reverse scans. If f
+// returns true or an error, the ranges which remain aren't queried. Other
+// batches are sent like by Send and f is called with the whole response.
+func (ds *DistSender) SendStream(ctx context.Context, ba roachpb.BatchRequest,
+	f func(*roachpb.BatchResponse) (bool, error)) *roachpb.Error {
+	if !ba.IsReadOnly() || len(ba.Split(false /* don't split ET */)) > 1 {
+		br, pErr := ds.Send(ctx, ba)
+		if pErr != nil {
+			return pErr
+		}
+		_, err := f(br)
+		return roachpb.NewError(err)
+	}
+	_, pErr := ds.send(ctx, ba, f)
+	return pErr
+}
+
+// callerContextKey is the key under which withBatchDeadline records the
+// context of the caller.
+type callerContextKey struct{}
+
+// withBatchDeadline returns a context which is done once the batch deadline
+// expires, recording the context of the caller in it so that
+// batchDeadlineExceeded can tell the two deadlines apart.
+func withBatchDeadline(ctx context.Context, deadline time.Duration) (context.Context, func()) {
+	caller := ctx
+	ctx, cancel := context.WithTimeout(ctx, deadline)
+	return context.WithValue(ctx, callerContextKey{}, caller), cancel
+}
+
+// batchDeadlineExceeded returns whether the context is done because the
+// batch deadline of the DistSender expired, rather than because the context
+// of the caller is done.
+func batchDeadlineExceeded(ctx context.Context) bool {
+	if ctx.Err() != context.DeadlineExceeded {
+		return false
+	}
+	caller, ok := ctx.Value(callerContextKey{}).(context.Context)
+	return ok && caller.Err() == nil
+}
+
+// send implements Send, bypassing the read cache. If stream is set, the
+// responses of the ranges are passed to it instead of being returned; the
+// batch must not need to be split into chunks in that case.
+func (ds *DistSender) send(ctx context.Context, ba roachpb.BatchRequest,
+	stream func(*roachpb.BatchResponse) (bool, error)) (*roachpb.BatchResponse, *roachpb.Error) {
 	tracing.AnnotateTrace()
+	ctx, finishTrace := ds.slowRequests.Trace(ctx, ds.Tracer, opDistSender)
+	defer finishTrace()
+	ds.metrics.batches.Inc(1)
+	if ds.batchDeadline > 0 {
+		var cancel func()
+		ctx, cancel = withBatchDeadline(ctx, ds.batchDeadline)
+		defer cancel()
+	}
 
 	// In the event that timestamp isn't set and read consistency isn't
 	// required, set the timestamp using the local clock.
@@ -482,7 +895,7 @@ func (ds *DistSender) Send(ctx context.Context, ba roachpb.BatchRequest) (*roach
 		panic("empty batch")
 	}
 
-	if ba.MaxScanResults != 0 {
+	if ba.MaxScanResults != 0 || ba.TargetBytes != 0 {
 		// Verify that the batch contains only Scan or ReverseScan requests.
 		fwd, rev := false, false
 		for _, req := range ba.Requests {
@@ -502,15 +915,20 @@ func (ds *DistSender) Send(ctx context.Context, ba roachpb.BatchRequest) (*roach
 
 	var rplChunks []*roachpb.BatchResponse
 	parts := ba.Split(false /* don't split ET */)
-	if len(parts) > 1 && ba.MaxScanResults != 0 {
+	if len(parts) > 1 && (ba.MaxScanResults != 0 || ba.TargetBytes != 0) {
 		// We already verified above that the batch contains only scan requests of the same type.
 		// Such a batch should never need splitting.
-		panic("batch with MaxScanResults needs splitting")
+		panic("batch with MaxScanResults or TargetBytes needs splitting")
 	}
 	for len(parts) > 0 {
+		// Don't send the remaining chunks if the caller has given up on the
+		// batch.
+		if err := ctx.Err(); err != nil {
+			return nil, roachpb.NewError(err)
+		}
 		part := parts[0]
 		ba.Requests = part
-		rpl, pErr, shouldSplitET := ds.sendChunk(ctx, ba)
+		rpl, pErr, shouldSplitET := ds.sendChunk(ctx, ba, stream)
 		if shouldSplitET {
 			// If we tried to send a single round-trip EndTransaction but
 			// it looks like it's going to hit multiple ranges, split it
@@ -527,6 +945,10 @@ func (ds *DistSender) Send(ctx context.Context, ba roachpb.BatchRequest) (*roach
 		if pErr != nil {
 			return nil, pErr
 		}
+		if stream != nil {
+			// The responses were streamed.
+			return nil, nil
+		}
 		// Propagate transaction from last reply to next request. The final
 		// update is taken and put into the response's main header.
 		ba.Txn.Update(rpl.Header().Txn)
@@ -548,19 +970,151 @@ func (ds *DistSender) Send(ctx context.Context, ba roachpb.BatchRequest) (*roach
 // mixing of forward and reverse scans, etc). The parameters and return values
 // correspond to client.Sender with the exception of the returned boolean,
 // which is true when indicating that the caller should retry but needs to send
-// EndTransaction in a separate request.
-func (ds *DistSender) sendChunk(ctx context.Context, ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error, bool) {
-	isReverse := ba.IsReverse()
-
-	sp, cleanupSp := tracing.SpanFromContext(opDistSender, ds.Tracer, ctx)
-	defer cleanupSp()
-
+// EndTransaction in a separate request. If stream is set, the responses of
+// the ranges are passed to it in order and no response is returned.
+func (ds *DistSender) sendChunk(ctx context.Context, ba roachpb.BatchRequest,
+	stream func(*roachpb.BatchResponse) (bool, error)) (*roachpb.BatchResponse, *roachpb.Error, bool) {
 	// The minimal key range encompassing all requests contained within.
 	// Local addressing has already been resolved.
 	// TODO(tschottdorf): consider rudimentary validation of the batch here
 	// (for example, non-range requests with EndKey, or empty key ranges).
 	rs := keys.Range(ba)
+	var numRanges int32
+	defer func() {
+		ds.metrics.ranges.RecordValue(int64(atomic.LoadInt32(&numRanges)))
+	}()
+	if stream == nil && ds.sendParallelism > 1 && canSendInParallel(ba) {
+		spans, pErr := ds.rangeSpans(ctx, ba, rs)
+		// On errors, fall back to querying the ranges one after the other,
+		// which retries the lookups.
+		if pErr == nil && len(spans) > 1 {
+			return ds.sendParallel(ctx, ba, spans, &numRanges)
+		}
+	}
+	return ds.sendSpan(ctx, ba, rs, &numRanges, stream)
+}
+
+// canSendInParallel returns whether the ranges spanned by the batch may be
+// queried concurrently. The batch must be a read which isn't limited in any
+// way, so that the responses of all ranges are needed regardless of each
+// other. It must also be transactional or INCONSISTENT, since consistent
+// reads spanning ranges are refused outside of transactions.
+func canSendInParallel(ba roachpb.BatchRequest) bool {
+	if !ba.IsReadOnly() || ba.MaxScanResults != 0 || ba.TargetBytes != 0 {
+		return false
+	}
+	if ba.Txn == nil && ba.ReadConsistency != roachpb.INCONSISTENT {
+		return false
+	}
+	for _, union := range ba.Requests {
+		if bounded, ok := union.GetInner().(roachpb.Bounded); ok && bounded.GetBound() > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// rangeSpans divides rs into the parts covered by each of the ranges it
+// spans according to the range descriptor cache, in the order in which they
+// would be queried one after the other. The parts are contiguous even if the
+// cached descriptors are stale.
+func (ds *DistSender) rangeSpans(ctx context.Context, ba roachpb.BatchRequest, rs roachpb.RSpan) ([]roachpb.RSpan, *roachpb.Error) {
+	sp, cleanupSp := tracing.SpanFromContext(opDistSender, ds.Tracer, ctx)
+	defer cleanupSp()
+	ctx = opentracing.ContextWithSpan(ctx, sp)
+
+	isReverse := ba.IsReverse()
+	var spans []roachpb.RSpan
+	for {
+		desc, needAnother, _, pErr := ds.getDescriptors(ctx, rs, false /* considerIntents */, isReverse)
+		if pErr != nil {
+			return nil, pErr
+		}
+		if !needAnother {
+			return append(spans, rs), nil
+		}
+		if isReverse {
+			spans = append(spans, roachpb.RSpan{Key: desc.StartKey, EndKey: rs.EndKey})
+			rs.EndKey = prev(ba, desc.StartKey)
+		} else {
+			spans = append(spans, roachpb.RSpan{Key: rs.Key, EndKey: desc.EndKey})
+			rs.Key = next(ba, desc.EndKey)
+		}
+	}
+}
+
+// sendParallel sends the batch to each of the spans concurrently, bounded by
+// the DistSender's parallelism, and combines the responses in the order of
+// the spans. Each span is sent like a batch of its own, retrying on errors and
+// querying all the ranges which cover it if the descriptor it was derived from
+// turns out to be stale.
+func (ds *DistSender) sendParallel(ctx context.Context, ba roachpb.BatchRequest, spans []roachpb.RSpan,
+	numRanges *int32) (*roachpb.BatchResponse, *roachpb.Error, bool) {
+	type result struct {
+		br   *roachpb.BatchResponse
+		pErr *roachpb.Error
+	}
+	results := make([]result, len(spans))
+	sem := make(chan struct{}, ds.sendParallelism)
+	var wg sync.WaitGroup
+	for i, span := range spans {
+		// Each part updates its own copy of the transaction.
+		partBA := ba
+		if ba.Txn != nil {
+			txn := ba.Txn.Clone()
+			partBA.Txn = &txn
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, partBA roachpb.BatchRequest, span roachpb.RSpan) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i].br, results[i].pErr, _ = ds.sendSpan(ctx, partBA, span, numRanges, nil)
+		}(i, partBA, span)
+	}
+	wg.Wait()
+
 	var br *roachpb.BatchResponse
+	for _, res := range results {
+		if res.pErr != nil {
+			return nil, res.pErr, false
+		}
+		if br == nil {
+			br = res.br
+		} else if err := br.Combine(res.br); err != nil {
+			return nil, roachpb.NewError(err), false
+		}
+	}
+	return br, nil, false
+}
+
+// sendSpan sends the parts of the batch within rs to the ranges covering rs,
+// one after the other, adding the number of ranges queried to numRanges. It
+// is otherwise like sendChunk.
+func (ds *DistSender) sendSpan(ctx context.Context, ba roachpb.BatchRequest, rs roachpb.RSpan,
+	numRanges *int32, stream func(*roachpb.BatchResponse) (bool, error)) (*roachpb.BatchResponse, *roachpb.Error, bool) {
+	isReverse := ba.IsReverse()
+
+	sp, cleanupSp := tracing.SpanFromContext(opDistSender, ds.Tracer, ctx)
+	defer cleanupSp()
+	ctx = opentracing.ContextWithSpan(ctx, sp)
+
+	// The requests of the batch before any of them are masked out. They are
+	// needed to compute the resume spans of bounded requests.
+	origRequests := ba.Requests
+	var br *roachpb.BatchResponse
+
+	// Log each backoff to the trace, in addition to whatever the configured
+	// retry options do.
+	retryOpts := ds.rpcRetryOptions
+	retryOpts.OnRetry = func(attempt int, backoff time.Duration) {
+		sp.LogEvent(fmt.Sprintf("retry %d after backing off %s", attempt, backoff))
+		if ds.rpcRetryOptions.OnRetry != nil {
+			ds.rpcRetryOptions.OnRetry(attempt, backoff)
+		}
+	}
 
 	// Send the request to one range per iteration.
 	for {
@@ -570,13 +1124,30 @@ func (ds *DistSender) sendChunk(ctx context.Context, ba roachpb.BatchRequest) (*
 		var needAnother bool
 		var pErr *roachpb.Error
 		var finished bool
-		for r := retry.Start(ds.rpcRetryOptions); r.Next(); {
+		// The attempts count towards the retry budget regardless of the
+		// retry options, which are reset on addressing errors.
+		var attempts int
+		var budgetExceeded bool
+		var rangeID roachpb.RangeID
+		// ambiguousErr is set once an attempt to commit a transaction may
+		// have been applied without the outcome being known.
+		var ambiguousErr *roachpb.Error
+		for r := retry.StartWithCtx(ctx, retryOpts); r.Next(); {
+			if ds.rangeRetryBudget > 0 && attempts >= ds.rangeRetryBudget {
+				budgetExceeded = true
+				break
+			}
+			attempts++
+
 			// Get range descriptor (or, when spanning range, descriptors). Our
 			// error handling below may clear them on certain errors, so we
 			// refresh (likely from the cache) on every retry.
 			sp.LogEvent("meta descriptor lookup")
 			var evictDesc func()
-			desc, needAnother, evictDesc, pErr = ds.getDescriptors(rs, considerIntents, isReverse)
+			desc, needAnother, evictDesc, pErr = ds.getDescriptors(ctx, rs, considerIntents, isReverse)
+			if desc != nil {
+				rangeID = desc.RangeID
+			}
 
 			// getDescriptors may fail retryably if the first range isn't
 			// available via Gossip.
@@ -639,11 +1210,20 @@ func (ds *DistSender) sendChunk(ctx context.Context, ba roachpb.BatchRequest) (*
 					return nil, roachpb.NewError(trErr)
 				}
 				truncBA.MaxScanResults = ba.MaxScanResults
+				truncBA.TargetBytes = ba.TargetBytes
 
-				return ds.sendSingleRange(sp, truncBA, desc)
+				if needAnother && ds.prefetchDescriptors {
+					if isReverse {
+						ds.rangeCache.prefetchRangeDescriptor(ds.rpcContext.Stopper, desc.StartKey, considerIntents, true /* useReverseScan */)
+					} else {
+						ds.rangeCache.prefetchRangeDescriptor(ds.rpcContext.Stopper, desc.EndKey, considerIntents, false /* useReverseScan */)
+					}
+				}
+				return ds.sendSingleRange(ctx, truncBA, desc)
 			}()
 			// If sending succeeded, break this loop.
 			if pErr == nil {
+				atomic.AddInt32(numRanges, 1)
 				finished = true
 				break
 			}
@@ -658,6 +1238,13 @@ func (ds *DistSender) sendChunk(ctx context.Context, ba roachpb.BatchRequest) (*
 			// key mismatch errors, we don't backoff on the retry,
 			// but reset the backoff loop so we can retry immediately.
 			switch tErr := pErr.GetDetail().(type) {
+			case *roachpb.AmbiguousResultError:
+				// A retry either commits the transaction, or fails because
+				// the earlier attempt was applied or for an unrelated
+				// reason, which leaves the result ambiguous.
+				ambiguousErr = pErr
+				evictDesc()
+				continue
 			case *roachpb.SendError:
 				// For an RPC error to occur, we must've been unable to contact
 				// any replicas. In this case, likely all nodes are down (or
@@ -665,14 +1252,21 @@ func (ds *DistSender) sendChunk(ctx context.Context, ba roachpb.BatchRequest) (*
 				// We may simply not be trying to talk to the up-to-date
 				// replicas, so clearing the descriptor here should be a good
 				// idea.
-				// TODO(tschottdorf): If a replica group goes dead, this
-				// will cause clients to put high read pressure on the first
-				// range, so there should be some rate limiting here.
+				// If a replica group goes dead, this causes clients to put
+				// high read pressure on the meta ranges, which is bounded
+				// by the coalescing and rate limiting of range lookups in
+				// the range descriptor cache.
+				ds.metrics.retriesSendError.Inc(1)
 				evictDesc()
 				if tErr.CanRetry() {
 					continue
 				}
 			case *roachpb.RangeNotFoundError, *roachpb.RangeKeyMismatchError:
+				if _, ok := tErr.(*roachpb.RangeNotFoundError); ok {
+					ds.metrics.retriesRangeNotFound.Inc(1)
+				} else {
+					ds.metrics.retriesRangeKeyMismatch.Inc(1)
+				}
 				// Range descriptor might be out of date - evict it.
 				evictDesc()
 				// On addressing errors, don't backoff; retry immediately.
@@ -691,6 +1285,7 @@ func (ds *DistSender) sendChunk(ctx context.Context, ba roachpb.BatchRequest) (*
 				considerIntents = true
 				continue
 			case *roachpb.NotLeaderError:
+				ds.metrics.retriesNotLeader.Inc(1)
 				newLeader := tErr.Leader
 				if newLeader != nil {
 					// Verify that leader is a known replica according to the
@@ -734,6 +1329,33 @@ func (ds *DistSender) sendChunk(ctx context.Context, ba roachpb.BatchRequest) (*
 			break
 		}
 
+		// An ambiguous result is returned as is, even if the retries also
+		// exhausted their budget or deadline or failed otherwise: the
+		// caller must learn that the batch may have been applied.
+		if ambiguousErr != nil && !finished {
+			pErr = ambiguousErr
+		}
+		if _, ok := pErr.GetDetail().(*roachpb.AmbiguousResultError); ok {
+			ds.metrics.ambiguousResults.Inc(1)
+			return nil, pErr, false
+		}
+
+		// The context of the caller being done is not a failure of the
+		// range, and its error is returned as is.
+		deadlineExceeded := !finished && batchDeadlineExceeded(ctx)
+		if err := ctx.Err(); !finished && err != nil && !deadlineExceeded {
+			return nil, roachpb.NewError(err), false
+		}
+
+		// Fail with an error describing the range and the last error if the
+		// retries exhausted their budget or deadline, so that callers can
+		// tell why.
+		if budgetExceeded || deadlineExceeded {
+			ds.metrics.retryBudgetExceeded.Inc(1)
+			return nil, roachpb.NewError(roachpb.NewRetryBudgetExceededError(
+				rangeID, rs, attempts, deadlineExceeded, pErr)), false
+		}
+
 		// Immediately return if querying a range failed non-retryably.
 		if pErr != nil {
 			return nil, pErr, false
@@ -742,14 +1364,20 @@ func (ds *DistSender) sendChunk(ctx context.Context, ba roachpb.BatchRequest) (*
 			case <-ds.rpcRetryOptions.Closer:
 				return nil, roachpb.NewError(&roachpb.NodeUnavailableError{}), false
 			default:
-				log.Fatal("exited retry loop with nil error but finished=false")
 			}
+			if err := ctx.Err(); err != nil {
+				return nil, roachpb.NewError(err), false
+			}
+			// The retries ran out while the descriptors found for the
+			// span kept turning out stale.
+			return nil, roachpb.NewErrorf("retries exhausted looking up range for [%s,%s)", rs.Key, rs.EndKey), false
 		}
 
 		ba.Txn.Update(curReply.Txn)
 
-		if br == nil {
-			// First response from a Range.
+		if br == nil || stream != nil {
+			// First response from a Range, or a response which is streamed
+			// on its own.
 			br = curReply
 		} else {
 			// This was the second or later call in a cross-Range request.
@@ -759,33 +1387,57 @@ func (ds *DistSender) sendChunk(ctx context.Context, ba roachpb.BatchRequest) (*
 			}
 		}
 
-		if ba.MaxScanResults > 0 {
-			// Count how many results we received.
-			var numResults int64
-			for _, resp := range curReply.Responses {
-				if cResp, ok := resp.GetInner().(roachpb.Countable); ok {
-					numResults += cResp.Count()
+		if ba.MaxScanResults > 0 || ba.TargetBytes > 0 {
+			done := false
+			if ba.MaxScanResults > 0 {
+				// Count how many results we received.
+				var numResults int64
+				for _, resp := range curReply.Responses {
+					if cResp, ok := resp.GetInner().(roachpb.Countable); ok {
+						numResults += cResp.Count()
+					}
+				}
+				if numResults > ba.MaxScanResults {
+					panic(fmt.Sprintf("received %d results, limit was %d", numResults, ba.MaxScanResults))
 				}
+				ba.MaxScanResults -= numResults
+				done = ba.MaxScanResults == 0
 			}
-			if numResults > ba.MaxScanResults {
-				panic(fmt.Sprintf("received %d results, limit was %d", numResults, ba.MaxScanResults))
+			if ba.TargetBytes > 0 {
+				// Subtract the size of the results we received. The range
+				// stops scanning once the target is reached, so a target
+				// which was not reached means that the range was scanned
+				// completely.
+				for _, resp := range curReply.Responses {
+					if sResp, ok := resp.GetInner().(roachpb.Sizable); ok {
+						ba.TargetBytes -= sResp.ByteSize()
+					}
+				}
+				if ba.TargetBytes <= 0 {
+					done = true
+				}
 			}
-			ba.MaxScanResults -= numResults
-			if ba.MaxScanResults == 0 {
+			if done {
 				// We are done with this batch. Some requests might have NoopResponses; we must
-				// replace them with empty responses of the proper type.
+				// replace them with empty responses of the proper type. All requests which
+				// weren't completed get the span from which they can be resumed.
 				for i, req := range ba.Requests {
-					if _, ok := br.Responses[i].GetInner().(*roachpb.NoopResponse); !ok {
-						continue
-					}
-					resp := roachpb.ResponseUnion{}
-					if _, ok := req.GetInner().(*roachpb.ScanRequest); ok {
-						resp.SetValue(&roachpb.ScanResponse{})
-					} else {
-						_ = req.GetInner().(*roachpb.ReverseScanRequest)
-						resp.SetValue(&roachpb.ReverseScanResponse{})
+					if _, ok := br.Responses[i].GetInner().(*roachpb.NoopResponse); ok {
+						resp := roachpb.ResponseUnion{}
+						if _, ok := req.GetInner().(*roachpb.ScanRequest); ok {
+							resp.SetValue(&roachpb.ScanResponse{})
+						} else {
+							_ = req.GetInner().(*roachpb.ReverseScanRequest)
+							resp.SetValue(&roachpb.ReverseScanResponse{})
+						}
+						br.Responses[i] = resp
 					}
-					br.Responses[i] = resp
+					header := br.Responses[i].GetInner().Header()
+					header.ResumeSpan = resumeSpan(origRequests[i].GetInner(), header.ResumeSpan, desc, isReverse)
+				}
+				if stream != nil {
+					_, err := stream(br)
+					return nil, roachpb.NewError(err), false
 				}
 				return br, nil, false
 			}
@@ -835,10 +1487,14 @@ func (ds *DistSender) sendChunk(ctx context.Context, ba roachpb.BatchRequest) (*
 					continue
 				}
 				nextBound := prevBound - cReply.Count()
-				if nextBound <= 0 {
-					// We've hit max results for this piece of the batch. Mask
-					// it out (we've copied the requests slice above, so this
-					// is kosher).
+				if resume := curReply.Responses[i].GetInner().Header().ResumeSpan; resume != nil || nextBound <= 0 {
+					// We've hit max results for this piece of the batch, which
+					// stopped where its resume span starts, or at the end of
+					// the range if it has none. Extend the resume span to the
+					// rest of the request and mask the request out (we've
+					// copied the requests slice above, so this is kosher).
+					br.Responses[i].GetInner().Header().ResumeSpan = resumeSpan(
+						origRequests[i].GetInner(), resume, desc, isReverse)
 					ba.Requests[i].Reset() // necessary (no one-of?)
 					if !ba.Requests[i].SetValue(&roachpb.NoopRequest{}) {
 						panic("RequestUnion excludes NoopRequest")
@@ -851,8 +1507,19 @@ func (ds *DistSender) sendChunk(ctx context.Context, ba roachpb.BatchRequest) (*
 			}
 		}
 
+		if stream != nil {
+			if stop, err := stream(br); err != nil {
+				return nil, roachpb.NewError(err), false
+			} else if stop {
+				return nil, nil, false
+			}
+		}
+
 		// If this was the last range accessed by this call, exit loop.
 		if !needAnother {
+			if stream != nil {
+				return nil, nil, false
+			}
 			return br, nil, false
 		}
 
@@ -876,6 +1543,30 @@ func (ds *DistSender) sendChunk(ctx context.Context, ba roachpb.BatchRequest) (*
 	}
 }
 
+// RangeCacheContents is a snapshot of the range descriptor and leader
+// caches of a DistSender.
+type RangeCacheContents struct {
+	Descriptors []CachedRangeDescriptor `json:"descriptors"`
+	// NextStart is the key at which the next page of descriptors starts,
+	// or nil if there are no more.
+	NextStart roachpb.RKey   `json:"next_start,omitempty"`
+	Leaders   []CachedLeader `json:"leaders"`
+}
+
+// RangeCacheContents returns the current contents of the range descriptor
+// cache, in key order starting with the range which contains the start key
+// and limited to limit descriptors if positive, and of the leader cache,
+// most recently used first, along with the ages of the entries. It is
+// intended for debugging.
+func (ds *DistSender) RangeCacheContents(start roachpb.RKey, limit int) RangeCacheContents {
+	descs, next := ds.rangeCache.descriptors(start, limit)
+	return RangeCacheContents{
+		Descriptors: descs,
+		NextStart:   next,
+		Leaders:     ds.leaderCache.leaders(),
+	}
+}
+
 // updateLeaderCache updates the cached leader for the given range,
 // evicting any previous value in the process.
 func (ds *DistSender) updateLeaderCache(rid roachpb.RangeID, leader roachpb.ReplicaDescriptor) {
@@ -17,14 +17,19 @@
 package kv_test
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/cockroach/client"
 	"github.com/cockroachdb/cockroach/keys"
 	"github.com/cockroachdb/cockroach/kv"
 	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/rpc"
 	"github.com/cockroachdb/cockroach/server"
 	"github.com/cockroachdb/cockroach/storage/engine"
 	"github.com/cockroachdb/cockroach/testutils"
@@ -32,6 +37,7 @@ import (
 	"github.com/cockroachdb/cockroach/util/hlc"
 	"github.com/cockroachdb/cockroach/util/leaktest"
 	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/metric"
 	"github.com/cockroachdb/cockroach/util/uuid"
 )
 
@@ -662,3 +668,69 @@ func TestPropagateTxnOnPushError(t *testing.T) {
 		}
 	}
 }
+
+// TestDistSenderMaxConcurrentBatchesPerNode verifies that a DistSender
+// limited to one RPC in flight per node sends concurrent batches to the node
+// one at a time.
+func TestDistSenderMaxConcurrentBatchesPerNode(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	prefix := roachpb.Key("limited")
+	var inFlight, maxInFlight int32
+	ctx := server.NewTestContext()
+	ctx.TestingMocker.StoreTestingMocker.TestingCommandFilter =
+		func(_ roachpb.StoreID, args roachpb.Request, _ roachpb.Header) error {
+			if args.Method() != roachpb.Get || !bytes.HasPrefix(args.Header().Key, prefix) {
+				return nil
+			}
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		}
+	s := server.StartTestServerWithContext(t, ctx)
+	defer s.Stop()
+
+	// A separate RPC context makes the RPCs to the server remote, as local
+	// calls are not limited.
+	registry := metric.NewRegistry()
+	ds := kv.NewDistSender(&kv.DistSenderContext{
+		Clock:                       s.Clock(),
+		RPCContext:                  rpc.NewContext(testutils.NewNodeTestBaseContext(), s.Clock(), s.Stopper()),
+		Registry:                    registry,
+		MaxConcurrentBatchesPerNode: 1,
+	}, s.Gossip())
+
+	const numGets = 5
+	var wg sync.WaitGroup
+	errs := make(chan error, numGets)
+	for i := 0; i < numGets; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			get := roachpb.NewGet(append(prefix, fmt.Sprintf("%d", i)...))
+			_, pErr := client.SendWrappedWith(ds, nil, roachpb.Header{
+				ReadConsistency: roachpb.INCONSISTENT,
+			}, get)
+			errs <- pErr.GoError()
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if max := atomic.LoadInt32(&maxInFlight); max != 1 {
+		t.Errorf("expected one batch in flight at a time, got up to %d", max)
+	}
+	if q := registry.GetCounter("batches.queued").Count(); q == 0 {
+		t.Errorf("expected batches to be queued")
+	}
+}
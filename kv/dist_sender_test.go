@@ -22,11 +22,14 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 
+	opentracing "github.com/opentracing/opentracing-go"
 	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/cockroach/base"
 	"github.com/cockroachdb/cockroach/client"
 	"github.com/cockroachdb/cockroach/gossip"
 	"github.com/cockroachdb/cockroach/gossip/simulation"
@@ -36,6 +39,8 @@ import (
 	"github.com/cockroachdb/cockroach/testutils"
 	"github.com/cockroachdb/cockroach/util"
 	"github.com/cockroachdb/cockroach/util/leaktest"
+	"github.com/cockroachdb/cockroach/util/metric"
+	"github.com/cockroachdb/cockroach/util/stop"
 )
 
 var testRangeDescriptor = roachpb.RangeDescriptor{
@@ -136,6 +141,8 @@ func TestSendRPCOrder(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	g, s := makeTestGossip(t)
 	defer s()
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
 	rangeID := roachpb.RangeID(99)
 
 	nodeAttrs := map[int32][]string{
@@ -184,6 +191,11 @@ func TestSendRPCOrder(t *testing.T) {
 		// Likely a test setup here will never have a read lease, but good
 		// to keep in mind.
 		consistent bool
+		// followerRead enables follower reads and sends the request at an
+		// explicit timestamp.
+		followerRead bool
+		// latency, if set, measures the heartbeat latency to node 3.
+		latency bool
 	}{
 		// Inconsistent Scan without matching attributes.
 		{
@@ -251,6 +263,50 @@ func TestSendRPCOrder(t *testing.T) {
 			expReplica: []roachpb.NodeID{1, 2, 3, 4, 5},
 			leader:     2,
 		},
+		// Consistent Get with matching attributes and a leader, sent as a
+		// follower read. Should ignore the leader and move the two nodes
+		// matching the attributes to the front.
+		{
+			args:         &roachpb.GetRequest{},
+			attrs:        nodeAttrs[5],
+			order:        orderStable,
+			expReplica:   []roachpb.NodeID{5, 4, 0, 0, 0},
+			leader:       2,
+			consistent:   true,
+			followerRead: true,
+		},
+		// Inconsistent Scan without matching attributes once the latency to
+		// a replica has been measured. Should shuffle the replicas and leave
+		// ordering them by latency to the RPC layer.
+		{
+			args:       &roachpb.ScanRequest{},
+			attrs:      []string{},
+			order:      orderLatency,
+			expReplica: []roachpb.NodeID{0, 0, 0, 0, 0},
+			latency:    true,
+		},
+		// Inconsistent Scan with matching attributes once the latency to a
+		// replica has been measured. Should move the two nodes matching the
+		// attributes to the front, which the RPC layer falls back to for the
+		// replicas of unknown latency.
+		{
+			args:       &roachpb.ScanRequest{},
+			attrs:      nodeAttrs[5],
+			order:      orderLatency,
+			expReplica: []roachpb.NodeID{5, 4, 0, 0, 0},
+			latency:    true,
+		},
+		// Put with matching attributes that finds the leader (node 2) once
+		// the latency to a replica has been measured. Should still address
+		// the leader first.
+		{
+			args:       &roachpb.PutRequest{},
+			attrs:      append(nodeAttrs[5], "irrelevant"),
+			order:      orderStable,
+			expReplica: []roachpb.NodeID{2, 5, 4, 0, 0},
+			leader:     2,
+			latency:    true,
+		},
 	}
 
 	descriptor := roachpb.RangeDescriptor{
@@ -315,6 +371,14 @@ func TestSendRPCOrder(t *testing.T) {
 			}
 		}
 
+		ds.rpcContext = nil
+		if tc.latency {
+			ds.rpcContext = newNodeTestContext(nil, stopper)
+			for i := 0; i < 10; i++ {
+				ds.rpcContext.RemoteLatencies.RecordLatency("node3:1", time.Millisecond)
+			}
+		}
+
 		ds.leaderCache.Update(roachpb.RangeID(rangeID), roachpb.ReplicaDescriptor{})
 		if tc.leader > 0 {
 			ds.leaderCache.Update(roachpb.RangeID(rangeID), descriptor.Replicas[tc.leader-1])
@@ -329,11 +393,17 @@ func TestSendRPCOrder(t *testing.T) {
 		if !tc.consistent {
 			consistency = roachpb.INCONSISTENT
 		}
+		var ts roachpb.Timestamp
+		ds.followerReads = tc.followerRead
+		if tc.followerRead {
+			ts = ds.clock.Now()
+		}
 		// Kill the cached NodeDescriptor, enforcing a lookup from Gossip.
 		ds.nodeDescriptor = nil
 		if _, err := client.SendWrappedWith(ds, nil, roachpb.Header{
 			RangeID:         rangeID, // Not used in this test, but why not.
 			ReadConsistency: consistency,
+			Timestamp:       ts,
 		}, args); err != nil {
 			t.Errorf("%d: %s", n, err)
 		}
@@ -342,7 +412,7 @@ func TestSendRPCOrder(t *testing.T) {
 
 type mockRangeDescriptorDB func(roachpb.RKey, bool, bool) ([]roachpb.RangeDescriptor, *roachpb.Error)
 
-func (mdb mockRangeDescriptorDB) RangeLookup(key roachpb.RKey, _ *roachpb.RangeDescriptor, considerIntents, useReverseScan bool) ([]roachpb.RangeDescriptor, *roachpb.Error) {
+func (mdb mockRangeDescriptorDB) RangeLookup(_ context.Context, key roachpb.RKey, _ *roachpb.RangeDescriptor, considerIntents, useReverseScan bool) ([]roachpb.RangeDescriptor, *roachpb.Error) {
 	if bytes.HasPrefix(key, keys.Meta2Prefix) {
 		return mdb(key[len(keys.Meta1Prefix):], considerIntents, useReverseScan)
 	}
@@ -353,7 +423,7 @@ func (mdb mockRangeDescriptorDB) RangeLookup(key roachpb.RKey, _ *roachpb.RangeD
 	return mdb(nil, considerIntents, useReverseScan)
 }
 func (mdb mockRangeDescriptorDB) FirstRange() (*roachpb.RangeDescriptor, *roachpb.Error) {
-	rs, err := mdb.RangeLookup(nil, nil, false /* considerIntents */, false /* useReverseScan */)
+	rs, err := mdb.RangeLookup(context.Background(), nil, nil, false /* considerIntents */, false /* useReverseScan */)
 	if err != nil || len(rs) == 0 {
 		return nil, err
 	}
@@ -413,7 +483,8 @@ func TestOwnNodeCertain(t *testing.T) {
 }
 
 // TestRetryOnNotLeaderError verifies that the DistSender correctly updates the
-// leader cache and retries when receiving a NotLeaderError.
+// leader cache and retries when receiving a NotLeaderError, and that it
+// records the retry and cache lookups in its metrics.
 func TestRetryOnNotLeaderError(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	g, s := makeTestGossip(t)
@@ -436,11 +507,13 @@ func TestRetryOnNotLeaderError(t *testing.T) {
 		return args.CreateReply(), nil
 	}
 
+	registry := metric.NewRegistry()
 	ctx := &DistSenderContext{
 		RPCSend: testFn,
 		RangeDescriptorDB: mockRangeDescriptorDB(func(_ roachpb.RKey, _, _ bool) ([]roachpb.RangeDescriptor, *roachpb.Error) {
 			return []roachpb.RangeDescriptor{testRangeDescriptor}, nil
 		}),
+		Registry: registry,
 	}
 	ds := NewDistSender(ctx, g)
 	v := roachpb.MakeValueFromString("value")
@@ -455,6 +528,21 @@ func TestRetryOnNotLeaderError(t *testing.T) {
 		t.Errorf("leader cache was not updated: expected %v, got %v",
 			&leader, cur)
 	}
+
+	// The retry found both the range descriptor and the leader cached.
+	for name, exp := range map[string]int64{
+		"batches":                  1,
+		"retries.notleader":        1,
+		"retries.rangekeymismatch": 0,
+		"rangecache.misses":        1,
+		"rangecache.hits":          1,
+		"leadercache.misses":       1,
+		"leadercache.hits":         1,
+	} {
+		if c := registry.GetCounter(name).Count(); c != exp {
+			t.Errorf("expected %s to be %d, got %d", name, exp, c)
+		}
+	}
 }
 
 // TestRetryOnDescriptorLookupError verifies that the DistSender retries a descriptor
@@ -567,6 +655,35 @@ func TestEvictCacheOnError(t *testing.T) {
 	}
 }
 
+// TestDistSenderInitialRangeDescriptors verifies that the range
+// descriptors the DistSender is created with are used without lookups.
+func TestDistSenderInitialRangeDescriptors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	g, s := makeTestGossip(t)
+	defer s()
+
+	var lookups int
+	ctx := &DistSenderContext{
+		RPCSend: func(_ SendOptions, _ ReplicaSlice,
+			args roachpb.BatchRequest, _ *rpc.Context) (*roachpb.BatchResponse, error) {
+			return args.CreateReply(), nil
+		},
+		RangeDescriptorDB: mockRangeDescriptorDB(func(_ roachpb.RKey, _, _ bool) ([]roachpb.RangeDescriptor, *roachpb.Error) {
+			lookups++
+			return []roachpb.RangeDescriptor{testRangeDescriptor}, nil
+		}),
+		RangeDescriptors: []roachpb.RangeDescriptor{testRangeDescriptor},
+	}
+	ds := NewDistSender(ctx, g)
+	put := roachpb.NewPut(roachpb.Key("a"), roachpb.MakeValueFromString("value"))
+	if _, pErr := client.SendWrapped(ds, nil, put); pErr != nil {
+		t.Fatal(pErr)
+	}
+	if lookups != 0 {
+		t.Errorf("expected no range lookups, got %d", lookups)
+	}
+}
+
 // TestRetryOnWrongReplicaError sets up a DistSender on a minimal gossip
 // network and a mock of Send, and verifies that the DistSender correctly
 // retries upon encountering a stale entry in its range descriptor cache.
@@ -716,6 +833,152 @@ func TestSendRPCRetry(t *testing.T) {
 	}
 }
 
+// TestSendRPCTimeouts verifies that the RPCs sent to replicas use the
+// timeouts of the DistSenderContext, and the defaults if they are unset.
+func TestSendRPCTimeouts(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	g, s := makeTestGossip(t)
+	defer s()
+	nd := &roachpb.NodeDescriptor{
+		NodeID:  1,
+		Address: util.MakeUnresolvedAddr("tcp", "node1"),
+	}
+	if err := g.AddInfoProto(gossip.MakeNodeIDKey(nd.NodeID), nd, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	descriptor := roachpb.RangeDescriptor{
+		RangeID:  1,
+		StartKey: roachpb.RKeyMin,
+		EndKey:   roachpb.RKeyMax,
+		Replicas: []roachpb.ReplicaDescriptor{{NodeID: 1, StoreID: 1}},
+	}
+
+	testCases := []struct {
+		sendNextTimeout, rpcTimeout       time.Duration
+		expSendNextTimeout, expRPCTimeout time.Duration
+	}{
+		{0, 0, defaultSendNextTimeout, base.NetworkTimeout},
+		{time.Minute, 2 * time.Minute, time.Minute, 2 * time.Minute},
+	}
+	for i, c := range testCases {
+		var opts SendOptions
+		ctx := &DistSenderContext{
+			RPCSend: func(o SendOptions, _ ReplicaSlice, args roachpb.BatchRequest,
+				_ *rpc.Context) (*roachpb.BatchResponse, error) {
+				opts = o
+				return args.CreateReply(), nil
+			},
+			RangeDescriptorDB: mockRangeDescriptorDB(func(_ roachpb.RKey, _, _ bool) ([]roachpb.RangeDescriptor, *roachpb.Error) {
+				return []roachpb.RangeDescriptor{descriptor}, nil
+			}),
+			SendNextTimeout: c.sendNextTimeout,
+			RPCTimeout:      c.rpcTimeout,
+		}
+		ds := NewDistSender(ctx, g)
+		if _, err := client.SendWrapped(ds, nil, roachpb.NewGet(roachpb.Key("a"))); err != nil {
+			t.Fatal(err)
+		}
+		if opts.SendNextTimeout != c.expSendNextTimeout {
+			t.Errorf("%d: expected send next timeout %s, got %s", i, c.expSendNextTimeout, opts.SendNextTimeout)
+		}
+		if opts.Timeout != c.expRPCTimeout {
+			t.Errorf("%d: expected rpc timeout %s, got %s", i, c.expRPCTimeout, opts.Timeout)
+		}
+	}
+}
+
+// TestRetryBudget verifies that batches fail with a RetryBudgetExceededError
+// describing the range and the last error once their retries exhausted the
+// retry budget or the deadline.
+func TestRetryBudget(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	g, s := makeTestGossip(t)
+	defer s()
+
+	testCases := []struct {
+		budget   int
+		deadline time.Duration
+		err      error
+		class    string
+	}{
+		// Addressing errors are retried without backing off, and would be
+		// retried forever without a budget.
+		{3, 0, roachpb.NewRangeNotFoundError(1), "RangeNotFoundError"},
+		{0, 10 * time.Millisecond, roachpb.NewSendError("boom", true), "SendError"},
+	}
+	for i, c := range testCases {
+		var attempts int
+		ctx := &DistSenderContext{
+			RPCSend: func(_ SendOptions, _ ReplicaSlice, args roachpb.BatchRequest,
+				_ *rpc.Context) (*roachpb.BatchResponse, error) {
+				attempts++
+				if _, ok := c.err.(*roachpb.SendError); ok {
+					return nil, c.err
+				}
+				reply := &roachpb.BatchResponse{}
+				reply.Error = roachpb.NewError(c.err)
+				return reply, nil
+			},
+			RangeDescriptorDB: mockRangeDescriptorDB(func(_ roachpb.RKey, _, _ bool) ([]roachpb.RangeDescriptor, *roachpb.Error) {
+				return []roachpb.RangeDescriptor{testRangeDescriptor}, nil
+			}),
+			RangeRetryBudget: c.budget,
+			BatchDeadline:    c.deadline,
+		}
+		ds := NewDistSender(ctx, g)
+		_, pErr := client.SendWrapped(ds, nil, roachpb.NewGet(roachpb.Key("a")))
+		e, ok := pErr.GetDetail().(*roachpb.RetryBudgetExceededError)
+		if !ok {
+			t.Fatalf("%d: expected a RetryBudgetExceededError, got %v", i, pErr)
+		}
+		if e.RangeID != testRangeDescriptor.RangeID {
+			t.Errorf("%d: expected range %d, got %d", i, testRangeDescriptor.RangeID, e.RangeID)
+		}
+		if e.ErrorClass != c.class || !e.ClusterUnavailable() {
+			t.Errorf("%d: expected unavailable class %s, got %s", i, c.class, e.ErrorClass)
+		}
+		if e.DeadlineExceeded != (c.deadline > 0) {
+			t.Errorf("%d: expected deadline exceeded=%t", i, c.deadline > 0)
+		}
+		if c.budget > 0 && (attempts != c.budget || int(e.Attempts) != c.budget) {
+			t.Errorf("%d: expected %d attempts, got %d (reported %d)", i, c.budget, attempts, e.Attempts)
+		}
+	}
+}
+
+// TestRetryBudgetCallerDeadline verifies that a batch whose caller's
+// deadline expires before the deadline of the DistSender fails with the
+// error of the caller's context rather than a RetryBudgetExceededError.
+func TestRetryBudgetCallerDeadline(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	g, s := makeTestGossip(t)
+	defer s()
+
+	ctx := &DistSenderContext{
+		RPCSend: func(_ SendOptions, _ ReplicaSlice, _ roachpb.BatchRequest,
+			_ *rpc.Context) (*roachpb.BatchResponse, error) {
+			return nil, roachpb.NewSendError("boom", true)
+		},
+		RangeDescriptorDB: mockRangeDescriptorDB(func(_ roachpb.RKey, _, _ bool) ([]roachpb.RangeDescriptor, *roachpb.Error) {
+			return []roachpb.RangeDescriptor{testRangeDescriptor}, nil
+		}),
+		BatchDeadline: time.Minute,
+	}
+	ds := NewDistSender(ctx, g)
+
+	callerCtx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	var ba roachpb.BatchRequest
+	ba.Add(roachpb.NewGet(roachpb.Key("a")))
+	_, pErr := ds.Send(callerCtx, ba)
+	if pErr == nil || pErr.GoError().Error() != context.DeadlineExceeded.Error() {
+		t.Fatalf("expected the error of the caller's context, got %v", pErr)
+	}
+	if c := ds.metrics.retryBudgetExceeded.Count(); c != 0 {
+		t.Errorf("expected no exhausted retry budget to be counted, got %d", c)
+	}
+}
+
 // TestGetNodeDescriptor checks that the Node descriptor automatically gets
 // looked up from Gossip.
 func TestGetNodeDescriptor(t *testing.T) {
@@ -736,6 +999,27 @@ func TestGetNodeDescriptor(t *testing.T) {
 	})
 }
 
+// TestLeaderLeaseGossip verifies that the leader cache is updated with the
+// leader leases gossiped by their new holders.
+func TestLeaderLeaseGossip(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	g, s := makeTestGossip(t)
+	defer s()
+	ds := NewDistSender(&DistSenderContext{}, g)
+	ds.leaderCache.Update(7, roachpb.ReplicaDescriptor{NodeID: 1, StoreID: 1})
+
+	lease := roachpb.Lease{Replica: roachpb.ReplicaDescriptor{NodeID: 2, StoreID: 2, ReplicaID: 2}}
+	if err := g.AddInfoProto(gossip.MakeLeaderLeaseKey(7), &lease, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	util.SucceedsSoon(t, func() error {
+		if leader := ds.leaderCache.Lookup(7); leader != lease.Replica {
+			return util.Errorf("expected leader %+v, got %+v", lease.Replica, leader)
+		}
+		return nil
+	})
+}
+
 // TestMultiRangeMergeStaleDescriptor simulates the situation in which the
 // DistSender executes a multi-range scan which encounters the stale descriptor
 // of a range which has since incorporated its right neighbor by means of a
@@ -1167,3 +1451,458 @@ func TestMultiRangeSplitEndTransaction(t *testing.T) {
 		}
 	}
 }
+
+// TestMultiRangeBoundedScanResumeSpan verifies that a bounded scan spanning
+// two ranges stops at the range which saturates it, whether or not its reply
+// carries a resume span, and that it returns the span from which it can be
+// resumed.
+func TestMultiRangeBoundedScanResumeSpan(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	g, s := makeTestGossip(t)
+	defer s()
+
+	if err := g.SetNodeDescriptor(&roachpb.NodeDescriptor{NodeID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	nd := &roachpb.NodeDescriptor{
+		NodeID:  roachpb.NodeID(1),
+		Address: util.MakeUnresolvedAddr(testAddress.Network(), testAddress.String()),
+	}
+	if err := g.AddInfoProto(gossip.MakeNodeIDKey(roachpb.NodeID(1)), nd, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	replicas := []roachpb.ReplicaDescriptor{{NodeID: 1, StoreID: 1}}
+	descriptor1 := roachpb.RangeDescriptor{
+		RangeID:  1,
+		StartKey: roachpb.RKeyMin,
+		EndKey:   roachpb.RKey("b"),
+		Replicas: replicas,
+	}
+	descriptor2 := roachpb.RangeDescriptor{
+		RangeID:  2,
+		StartKey: roachpb.RKey("b"),
+		EndKey:   roachpb.RKeyMax,
+		Replicas: replicas,
+	}
+	descDB := mockRangeDescriptorDB(func(key roachpb.RKey, _, _ bool) ([]roachpb.RangeDescriptor, *roachpb.Error) {
+		desc := descriptor1
+		if !key.Less(roachpb.RKey("b")) {
+			desc = descriptor2
+		}
+		return []roachpb.RangeDescriptor{desc}, nil
+	})
+
+	testCases := []struct {
+		// rows and resume are the number of rows returned by the first range
+		// and the key at which it stopped, if it returned a resume span.
+		rows   int
+		resume roachpb.Key
+		// expBounds are the bounds of the scans sent to the ranges.
+		expBounds []int64
+		expResume *roachpb.Span
+	}{
+		// The first range saturates the scan and says where it stopped.
+		{2, roachpb.Key("a3"), []int64{2}, &roachpb.Span{Key: roachpb.Key("a3"), EndKey: roachpb.Key("z")}},
+		// The first range saturates the scan without a resume span, so the
+		// scan resumes at the second range rather than going on unbounded.
+		{2, nil, []int64{2}, &roachpb.Span{Key: roachpb.Key("b"), EndKey: roachpb.Key("z")}},
+		// The first range doesn't saturate the scan, which goes on with the
+		// remaining bound and completes in the second range.
+		{1, nil, []int64{2, 1}, nil},
+	}
+	for i, test := range testCases {
+		var bounds []int64
+		var testFn rpcSendFn = func(_ SendOptions, _ ReplicaSlice,
+			ba roachpb.BatchRequest, _ *rpc.Context) (*roachpb.BatchResponse, error) {
+			br := ba.CreateReply()
+			scan, ok := ba.Requests[0].GetInner().(*roachpb.ScanRequest)
+			if !ok {
+				return br, nil
+			}
+			bounds = append(bounds, scan.MaxResults)
+			reply := br.Responses[0].GetInner().(*roachpb.ScanResponse)
+			if bytes.Compare(scan.Key, roachpb.Key("b")) < 0 {
+				for j := 0; j < test.rows; j++ {
+					reply.Rows = append(reply.Rows, roachpb.KeyValue{Key: roachpb.Key(fmt.Sprintf("a%d", j))})
+				}
+				if test.resume != nil {
+					reply.ResumeSpan = &roachpb.Span{Key: test.resume, EndKey: roachpb.Key("b")}
+				}
+			}
+			return br, nil
+		}
+
+		ctx := &DistSenderContext{
+			RPCSend:           testFn,
+			RangeDescriptorDB: descDB,
+		}
+		ds := NewDistSender(ctx, g)
+
+		var ba roachpb.BatchRequest
+		ba.Add(&roachpb.ScanRequest{
+			Span:       roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("z")},
+			MaxResults: 2,
+		})
+		br, pErr := ds.Send(context.Background(), ba)
+		if pErr != nil {
+			t.Fatal(pErr)
+		}
+		if !reflect.DeepEqual(bounds, test.expBounds) {
+			t.Errorf("%d: expected scans bounded by %v, got %v", i, test.expBounds, bounds)
+		}
+		if resume := br.Responses[0].GetInner().Header().ResumeSpan; !reflect.DeepEqual(resume, test.expResume) {
+			t.Errorf("%d: expected resume span %s, got %s", i, test.expResume, resume)
+		}
+	}
+}
+
+// TestAmbiguousCommitRetry verifies that once an attempt to commit a
+// transaction may have been applied, the batch fails with an
+// AmbiguousResultError unless a retry succeeds, even if the retries fail
+// with an error which would otherwise be definite.
+func TestAmbiguousCommitRetry(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	g, s := makeTestGossip(t)
+	defer s()
+
+	testCases := []struct {
+		retryErr     error
+		expAmbiguous bool
+	}{
+		// The first attempt committed the transaction.
+		{roachpb.NewTransactionStatusError("already committed"), true},
+		{&roachpb.NotLeaderError{}, true},
+		// The retry committed the transaction.
+		{nil, false},
+	}
+	for i, c := range testCases {
+		var attempts int
+		ctx := &DistSenderContext{
+			RPCSend: func(_ SendOptions, _ ReplicaSlice, ba roachpb.BatchRequest,
+				_ *rpc.Context) (*roachpb.BatchResponse, error) {
+				attempts++
+				if attempts == 1 {
+					return nil, roachpb.NewAmbiguousResultError("timeout")
+				}
+				br := ba.CreateReply()
+				if c.retryErr != nil {
+					br.Error = roachpb.NewError(c.retryErr)
+				}
+				return br, nil
+			},
+			RangeDescriptorDB: mockRangeDescriptorDB(func(_ roachpb.RKey, _, _ bool) ([]roachpb.RangeDescriptor, *roachpb.Error) {
+				return []roachpb.RangeDescriptor{testRangeDescriptor}, nil
+			}),
+			RangeRetryBudget: 3,
+		}
+		ds := NewDistSender(ctx, g)
+
+		var ba roachpb.BatchRequest
+		ba.Txn = &roachpb.Transaction{Name: "test"}
+		ba.Add(&roachpb.EndTransactionRequest{Span: roachpb.Span{Key: roachpb.Key("a")}, Commit: true})
+		_, pErr := ds.Send(context.Background(), ba)
+		if _, ok := pErr.GetDetail().(*roachpb.AmbiguousResultError); ok != c.expAmbiguous {
+			t.Errorf("%d: expected ambiguous=%t, got %v", i, c.expAmbiguous, pErr)
+		}
+		if !c.expAmbiguous && pErr != nil {
+			t.Errorf("%d: unexpected error %s", i, pErr)
+		}
+		if attempts < 2 {
+			t.Errorf("%d: expected the commit to be retried", i)
+		}
+	}
+}
+
+// TestAmbiguousCommitContextDone verifies that a commit whose RPCs are
+// abandoned once the context of the batch is done fails with an
+// AmbiguousResultError, while other batches fail with the context's error.
+func TestAmbiguousCommitContextDone(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	g, s := makeTestGossip(t)
+	defer s()
+
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+	nodeContext := newNodeTestContext(nil, stopper)
+	_, ln := newTestServer(t, nodeContext)
+	if err := g.AddInfoProto(gossip.MakeNodeIDKey(1), &roachpb.NodeDescriptor{
+		NodeID:  1,
+		Address: util.MakeUnresolvedAddr(ln.Addr().Network(), ln.Addr().String()),
+	}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	// The RPCs never return before being cancelled.
+	sendOneFn = func(ctx context.Context, _ batchClient, _ time.Duration,
+		_ *rpc.Context, _ opentracing.Span, done chan batchCall) {
+		go func() {
+			<-ctx.Done()
+			done <- batchCall{err: ctx.Err(), ambiguous: true}
+		}()
+	}
+	defer func() { sendOneFn = sendOne }()
+
+	ds := NewDistSender(&DistSenderContext{
+		RPCContext: nodeContext,
+		RangeDescriptorDB: mockRangeDescriptorDB(func(_ roachpb.RKey, _, _ bool) ([]roachpb.RangeDescriptor, *roachpb.Error) {
+			return []roachpb.RangeDescriptor{testRangeDescriptor}, nil
+		}),
+	}, g)
+
+	val := roachpb.MakeValueFromString("val")
+	for i, commit := range []bool{false, true} {
+		var ba roachpb.BatchRequest
+		ba.Txn = &roachpb.Transaction{Name: "test"}
+		ba.Add(roachpb.NewPut(roachpb.Key("a"), val))
+		if commit {
+			ba.Add(&roachpb.EndTransactionRequest{Span: roachpb.Span{Key: roachpb.Key("a")}, Commit: true})
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		_, pErr := ds.Send(ctx, ba)
+		cancel()
+		if _, ok := pErr.GetDetail().(*roachpb.AmbiguousResultError); ok != commit {
+			t.Errorf("%d: expected ambiguous=%t, got %v", i, commit, pErr)
+		}
+		if pErr == nil {
+			t.Errorf("%d: unexpected success", i)
+		}
+	}
+}
+
+// TestRangeLookupPrefetchAdaptation verifies that the descriptors
+// prefetched by range lookups and used by a sequential scan through the
+// DistSender's cache count as used, so that the number of ranges looked up
+// at once grows.
+func TestRangeLookupPrefetchAdaptation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	g, s := makeTestGossip(t)
+	defer s()
+
+	// One range per letter, the first of which also holds the meta ranges.
+	var descs []roachpb.RangeDescriptor
+	startKey := roachpb.RKeyMin
+	for c := 'b'; c <= 'z'; c++ {
+		descs = append(descs, roachpb.RangeDescriptor{
+			RangeID:  roachpb.RangeID(len(descs) + 1),
+			StartKey: startKey,
+			EndKey:   roachpb.RKey(string(c)),
+			Replicas: []roachpb.ReplicaDescriptor{{NodeID: 1, StoreID: 1}},
+		})
+		startKey = roachpb.RKey(string(c))
+	}
+	descs = append(descs, roachpb.RangeDescriptor{
+		RangeID:  roachpb.RangeID(len(descs) + 1),
+		StartKey: startKey,
+		EndKey:   roachpb.RKeyMax,
+		Replicas: []roachpb.ReplicaDescriptor{{NodeID: 1, StoreID: 1}},
+	})
+
+	var lookups int
+	ctx := &DistSenderContext{
+		RPCSend: func(_ SendOptions, _ ReplicaSlice, ba roachpb.BatchRequest,
+			_ *rpc.Context) (*roachpb.BatchResponse, error) {
+			return ba.CreateReply(), nil
+		},
+		RangeDescriptorDB: mockRangeDescriptorDB(func(key roachpb.RKey, _, _ bool) ([]roachpb.RangeDescriptor, *roachpb.Error) {
+			lookups++
+			// Return the range containing the key and the following ones.
+			for i := range descs {
+				if key.Less(descs[i].EndKey) {
+					end := i + defaultRangeLookupMaxRanges
+					if end > len(descs) {
+						end = len(descs)
+					}
+					return descs[i:end], nil
+				}
+			}
+			return nil, roachpb.NewErrorf("no range for %s", key)
+		}),
+	}
+	ds := NewDistSender(ctx, g)
+
+	var ba roachpb.BatchRequest
+	ba.ReadConsistency = roachpb.INCONSISTENT
+	ba.Add(roachpb.NewScan(roachpb.Key("a"), roachpb.Key("z"), 0))
+	if _, pErr := ds.Send(context.Background(), ba); pErr != nil {
+		t.Fatal(pErr)
+	}
+	if used := ds.rangeCache.prefetch.used.Count(); used == 0 {
+		t.Errorf("expected prefetched descriptors to be used, %d lookups", lookups)
+	}
+	if n := ds.rangeCache.lookupMaxRanges(); n <= defaultRangeLookupMaxRanges {
+		t.Errorf("expected lookups of more than %d ranges, got %d", defaultRangeLookupMaxRanges, n)
+	}
+}
+
+// TestSendParallel verifies that unlimited transactional scans query their
+// ranges concurrently and combine the rows in key order, while limited scans
+// query them one after the other.
+func TestSendParallel(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	g, s := makeTestGossip(t)
+	defer s()
+
+	splits := []roachpb.RKey{roachpb.RKeyMin, roachpb.RKey("b"), roachpb.RKey("c"), roachpb.RKeyMax}
+	descDB := mockRangeDescriptorDB(func(key roachpb.RKey, _, _ bool) ([]roachpb.RangeDescriptor, *roachpb.Error) {
+		for i := 1; i < len(splits); i++ {
+			if key.Less(splits[i]) {
+				return []roachpb.RangeDescriptor{{
+					RangeID:  roachpb.RangeID(i),
+					StartKey: splits[i-1],
+					EndKey:   splits[i],
+					Replicas: []roachpb.ReplicaDescriptor{{NodeID: 1, StoreID: 1}},
+				}}, nil
+			}
+		}
+		return nil, roachpb.NewErrorf("no range for key %s", key)
+	})
+
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	var testFn rpcSendFn = func(_ SendOptions, _ ReplicaSlice,
+		ba roachpb.BatchRequest, _ *rpc.Context) (*roachpb.BatchResponse, error) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		// Give the other ranges a chance to be queried concurrently.
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		rs := keys.Range(ba)
+		br := ba.CreateReply()
+		br.Txn = ba.Txn
+		// Return a single row at the start of the queried part of the scan.
+		br.Responses[0].GetInner().(*roachpb.ScanResponse).Rows = []roachpb.KeyValue{{Key: rs.Key.AsRawKey().Next()}}
+		return br, nil
+	}
+
+	ds := NewDistSender(&DistSenderContext{
+		RPCSend:           testFn,
+		RangeDescriptorDB: descDB,
+		SendParallelism:   3,
+	}, g)
+
+	testCases := []struct {
+		maxResults  int64
+		expParallel bool
+	}{
+		{0, true},
+		{10, false},
+	}
+	for i, tc := range testCases {
+		mu.Lock()
+		maxInFlight = 0
+		mu.Unlock()
+
+		scan := roachpb.NewScan(roachpb.Key("a"), roachpb.Key("d"), tc.maxResults)
+		reply, pErr := client.SendWrappedWith(ds, nil, roachpb.Header{Txn: &roachpb.Transaction{}}, scan)
+		if pErr != nil {
+			t.Fatalf("%d: %s", i, pErr)
+		}
+		var rows []string
+		for _, kv := range reply.(*roachpb.ScanResponse).Rows {
+			rows = append(rows, string(kv.Key))
+		}
+		if exp := []string{"a\x00", "b\x00", "c\x00"}; !reflect.DeepEqual(rows, exp) {
+			t.Errorf("%d: expected rows %q, got %q", i, exp, rows)
+		}
+		mu.Lock()
+		parallel := maxInFlight > 1
+		mu.Unlock()
+		if parallel != tc.expParallel {
+			t.Errorf("%d: expected parallel=%t, got %d ranges queried concurrently", i, tc.expParallel, maxInFlight)
+		}
+	}
+}
+
+// TestSendStream verifies that SendStream passes on the response of each
+// range in the order of the scan, and that it stops querying ranges once the
+// stream asks it to.
+func TestSendStream(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	g, s := makeTestGossip(t)
+	defer s()
+
+	splits := []roachpb.RKey{roachpb.RKeyMin, roachpb.RKey("b"), roachpb.RKey("c"), roachpb.RKeyMax}
+	descDB := mockRangeDescriptorDB(func(key roachpb.RKey, _, useReverseScan bool) ([]roachpb.RangeDescriptor, *roachpb.Error) {
+		for i := 1; i < len(splits); i++ {
+			if key.Less(splits[i]) || (useReverseScan && key.Equal(splits[i])) {
+				return []roachpb.RangeDescriptor{{
+					RangeID:  roachpb.RangeID(i),
+					StartKey: splits[i-1],
+					EndKey:   splits[i],
+					Replicas: []roachpb.ReplicaDescriptor{{NodeID: 1, StoreID: 1}},
+				}}, nil
+			}
+		}
+		return nil, roachpb.NewErrorf("no range for key %s", key)
+	})
+
+	var numRPCs int
+	var testFn rpcSendFn = func(_ SendOptions, _ ReplicaSlice,
+		ba roachpb.BatchRequest, _ *rpc.Context) (*roachpb.BatchResponse, error) {
+		numRPCs++
+		rs := keys.Range(ba)
+		br := ba.CreateReply()
+		br.Txn = ba.Txn
+		// Return a single row at the start of the queried part of the scan.
+		row := []roachpb.KeyValue{{Key: rs.Key.AsRawKey().Next()}}
+		switch reply := br.Responses[0].GetInner().(type) {
+		case *roachpb.ScanResponse:
+			reply.Rows = row
+		case *roachpb.ReverseScanResponse:
+			reply.Rows = row
+		}
+		return br, nil
+	}
+
+	ds := NewDistSender(&DistSenderContext{
+		RPCSend:           testFn,
+		RangeDescriptorDB: descDB,
+		SendParallelism:   3,
+	}, g)
+
+	testCases := []struct {
+		req     roachpb.Request
+		stopAt  int
+		expRows []string
+	}{
+		{roachpb.NewScan(roachpb.Key("a"), roachpb.Key("d"), 0), 0, []string{"a\x00", "b\x00", "c\x00"}},
+		{roachpb.NewReverseScan(roachpb.Key("a"), roachpb.Key("d"), 0), 0, []string{"c\x00", "b\x00", "a\x00"}},
+		{roachpb.NewScan(roachpb.Key("a"), roachpb.Key("d"), 0), 2, []string{"a\x00", "b\x00"}},
+	}
+	for i, tc := range testCases {
+		numRPCs = 0
+		var ba roachpb.BatchRequest
+		ba.Txn = &roachpb.Transaction{}
+		ba.Add(tc.req)
+		var rows []string
+		if pErr := ds.SendStream(context.Background(), ba, func(br *roachpb.BatchResponse) (bool, error) {
+			var kvs []roachpb.KeyValue
+			switch reply := br.Responses[0].GetInner().(type) {
+			case *roachpb.ScanResponse:
+				kvs = reply.Rows
+			case *roachpb.ReverseScanResponse:
+				kvs = reply.Rows
+			}
+			for _, kv := range kvs {
+				rows = append(rows, string(kv.Key))
+			}
+			return len(rows) == tc.stopAt, nil
+		}); pErr != nil {
+			t.Fatalf("%d: %s", i, pErr)
+		}
+		if !reflect.DeepEqual(rows, tc.expRows) {
+			t.Errorf("%d: expected rows %q, got %q", i, tc.expRows, rows)
+		}
+		if numRPCs != len(tc.expRows) {
+			t.Errorf("%d: expected %d ranges to be queried, got %d", i, len(tc.expRows), numRPCs)
+		}
+	}
+}
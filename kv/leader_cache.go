@@ -18,23 +18,41 @@ package kv
 
 import (
 	"sync"
+	"time"
 
 	"github.com/cockroachdb/cockroach/roachpb"
 	"github.com/cockroachdb/cockroach/util/cache"
 )
 
 // A leaderCache is a cache used to keep track of the leader
-// replica of Raft consensus groups.
+// replica of Raft consensus groups. Entries expire after a TTL, so that
+// leadership changes which don't cause a NotLeaderError, e.g. because
+// the former leader's node is down, don't cost every request a miss
+// forever.
 type leaderCache struct {
+	// ttl is the duration for which entries are served. Zero disables
+	// expiration.
+	ttl time.Duration
+	// now is the clock of the cache, replaced in tests.
+	now func() time.Time
+
 	mu    sync.Mutex
 	cache *cache.UnorderedCache
 }
 
-// newLeaderCache creates a new leaderCache of the given size.
-// The underlying cache internally uses a hash map, so lookups
-// are cheap.
-func newLeaderCache(size int) *leaderCache {
+// leaderCacheEntry is a cached leader and the time at which it was cached.
+type leaderCacheEntry struct {
+	replica roachpb.ReplicaDescriptor
+	added   time.Time
+}
+
+// newLeaderCache creates a new leaderCache of the given size whose
+// entries expire after the given TTL. The underlying cache internally
+// uses a hash map, so lookups are cheap.
+func newLeaderCache(size int, ttl time.Duration) *leaderCache {
 	return &leaderCache{
+		ttl: ttl,
+		now: time.Now,
 		cache: cache.NewUnorderedCache(cache.Config{
 			Policy: cache.CacheLRU,
 			ShouldEvict: func(s int, key, value interface{}) bool {
@@ -45,7 +63,7 @@ func newLeaderCache(size int) *leaderCache {
 }
 
 // Lookup consults the cache for the replica cached as the leader of
-// the given Raft consensus group.
+// the given Raft consensus group. Expired entries are evicted.
 func (lc *leaderCache) Lookup(group roachpb.RangeID) roachpb.ReplicaDescriptor {
 	lc.mu.Lock()
 	defer lc.mu.Unlock()
@@ -53,7 +71,12 @@ func (lc *leaderCache) Lookup(group roachpb.RangeID) roachpb.ReplicaDescriptor {
 	if !ok || v == nil {
 		return roachpb.ReplicaDescriptor{}
 	}
-	return *(v.(*roachpb.ReplicaDescriptor))
+	entry := v.(*leaderCacheEntry)
+	if lc.ttl > 0 && lc.now().Sub(entry.added) >= lc.ttl {
+		lc.cache.Del(group)
+		return roachpb.ReplicaDescriptor{}
+	}
+	return entry.replica
 }
 
 // Update invalidates the cached leader for the given Raft group.
@@ -64,6 +87,39 @@ func (lc *leaderCache) Update(group roachpb.RangeID, r roachpb.ReplicaDescriptor
 	defer lc.mu.Unlock()
 	lc.cache.Del(group)
 	if r.StoreID != 0 {
-		lc.cache.Add(group, &r)
+		lc.cache.Add(group, &leaderCacheEntry{replica: r, added: lc.now()})
 	}
 }
+
+// CachedLeader is an entry of the leader cache.
+type CachedLeader struct {
+	RangeID roachpb.RangeID           `json:"range_id"`
+	Leader  roachpb.ReplicaDescriptor `json:"leader"`
+	// CachedAt is the time at which the leader was cached.
+	CachedAt time.Time `json:"cached_at"`
+	// Age is the time elapsed since, in nanoseconds.
+	Age time.Duration `json:"age"`
+}
+
+// leaders returns the unexpired entries of the cache, most recently used
+// first.
+func (lc *leaderCache) leaders() []CachedLeader {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	now := lc.now()
+	var leaders []CachedLeader
+	lc.cache.Do(func(k, v interface{}) {
+		entry := v.(*leaderCacheEntry)
+		age := now.Sub(entry.added)
+		if lc.ttl > 0 && age >= lc.ttl {
+			return
+		}
+		leaders = append(leaders, CachedLeader{
+			RangeID:  k.(roachpb.RangeID),
+			Leader:   entry.replica,
+			CachedAt: entry.added,
+			Age:      age,
+		})
+	})
+	return leaders
+}
@@ -18,6 +18,7 @@ package kv
 
 import (
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/cockroach/roachpb"
 	"github.com/cockroachdb/cockroach/util/leaktest"
@@ -25,7 +26,7 @@ import (
 
 func TestLeaderCache(t *testing.T) {
 	defer leaktest.AfterTest(t)()
-	lc := newLeaderCache(3)
+	lc := newLeaderCache(3, 0)
 	if r := lc.Lookup(12); r.StoreID != 0 {
 		t.Fatalf("lookup of missing key returned replica: %v", r)
 	}
@@ -53,3 +54,27 @@ func TestLeaderCache(t *testing.T) {
 		t.Errorf("unexpected policy used in cache")
 	}
 }
+
+func TestLeaderCacheTTL(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	now := time.Unix(0, 0)
+	lc := newLeaderCache(3, time.Minute)
+	lc.now = func() time.Time { return now }
+
+	replica := roachpb.ReplicaDescriptor{StoreID: 1}
+	lc.Update(5, replica)
+	now = now.Add(time.Minute - 1)
+	if r := lc.Lookup(5); r.StoreID != 1 {
+		t.Errorf("expected %v, got %v", replica, r)
+	}
+	// Updating the entry restarts its TTL.
+	lc.Update(5, replica)
+	now = now.Add(time.Minute - 1)
+	if r := lc.Lookup(5); r.StoreID != 1 {
+		t.Errorf("expected %v, got %v", replica, r)
+	}
+	now = now.Add(1)
+	if r := lc.Lookup(5); r.StoreID != 0 {
+		t.Errorf("expired leader returned: %v", r)
+	}
+}
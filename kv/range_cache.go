@@ -19,13 +19,29 @@ package kv
 import (
 	"bytes"
 	"fmt"
+	"math"
 	"sync"
+	"time"
 
 	"github.com/biogo/store/llrb"
+	opentracing "github.com/opentracing/opentracing-go"
+	"golang.org/x/net/context"
+
 	"github.com/cockroachdb/cockroach/keys"
 	"github.com/cockroachdb/cockroach/roachpb"
 	"github.com/cockroachdb/cockroach/util/cache"
 	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/metric"
+	"github.com/cockroachdb/cockroach/util/stop"
+)
+
+const (
+	// minRangeLookupMaxRanges and maxRangeLookupMaxRanges bound the number
+	// of ranges returned by adaptive range lookups. The lower bound keeps
+	// at least one descriptor prefetched by each lookup, without which the
+	// use of prefetched descriptors could not be observed anymore.
+	minRangeLookupMaxRanges = 2
+	maxRangeLookupMaxRanges = 64
 )
 
 // rangeCacheKey is the key type used to store and sort values in the
@@ -53,7 +69,8 @@ type RangeDescriptorDB interface {
 	// rangeLookup takes a meta key to look up descriptors for,
 	// for example \x00\x00meta1aa or \x00\x00meta2f.
 	// The two booleans are considerIntents and useReverseScan respectively.
-	RangeLookup(roachpb.RKey, *roachpb.RangeDescriptor, bool, bool) ([]roachpb.RangeDescriptor, *roachpb.Error)
+	// The context carries the trace of the request which caused the lookup.
+	RangeLookup(context.Context, roachpb.RKey, *roachpb.RangeDescriptor, bool, bool) ([]roachpb.RangeDescriptor, *roachpb.Error)
 	// FirstRange returns the descriptor for the first Range. This is the
 	// Range containing all \x00\x00meta1 entries.
 	FirstRange() (*roachpb.RangeDescriptor, *roachpb.Error)
@@ -70,23 +87,274 @@ type rangeDescriptorCache struct {
 	// filled while servicing read and write requests to the key value
 	// store.
 	rangeCache *cache.OrderedCache
-	// rangeCacheMu protects rangeCache for concurrent access
+	// rangeCacheMu protects rangeCache and cachedAt for concurrent access
 	rangeCacheMu sync.RWMutex
+	// cachedAt holds the times at which the descriptors in rangeCache were
+	// cached, for introspection.
+	cachedAt map[*roachpb.RangeDescriptor]time.Time
+	// lookupRequests holds the range lookups in flight, keyed by
+	// lookupRequestKey. lookupMu protects lookupRequests.
+	lookupMu       sync.Mutex
+	lookupRequests map[string]*lookupRequest
+	// meta1Limiter and meta2Limiter, if set, limit the rate of the lookups
+	// reading meta1 and meta2 records respectively, so that storms of
+	// evictions, e.g. while the replicas of a range are down, don't
+	// overwhelm the meta ranges.
+	meta1Limiter *lookupLimiter
+	meta2Limiter *lookupLimiter
+	// prefetch tracks the use of the descriptors prefetched by range
+	// lookups and sizes the lookups accordingly.
+	prefetch *prefetchTracker
+}
+
+// maxLookupDelay bounds the delay of range lookups by a lookupLimiter. The
+// lookups queued beyond it, e.g. during a burst of evictions, wait no longer
+// than the lookups already queued, rather than for ever longer.
+const maxLookupDelay = 5 * time.Second
+
+// A lookupLimiter is a token bucket limiting the rate of range lookups. It
+// accrues tokens at a fixed rate, up to one second worth of tokens, and
+// delays the lookups once they exhaust the tokens. The debt of tokens is
+// capped at maxLookupDelay worth of tokens.
+type lookupLimiter struct {
+	rate  float64
+	now   func() time.Time
+	sleep func(context.Context, time.Duration) error
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newLookupLimiter(rate float64) *lookupLimiter {
+	return &lookupLimiter{
+		rate:   rate,
+		now:    time.Now,
+		sleep:  sleepContext,
+		tokens: rate,
+		last:   time.Now(),
+	}
+}
+
+// sleepContext sleeps for the given duration, or until the context is done,
+// in which case it returns the error of the context.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// wait takes a token, waiting until it has accrued if none is left, and
+// returns the duration waited. Waiting lookups queue up, since the tokens
+// they take may be negative, but only up to maxLookupDelay: once the debt
+// reaches it, lookups wait maxLookupDelay without adding to the debt. If
+// the context is done while waiting, the token is returned and so is the
+// error of the context. A nil lookupLimiter never waits.
+func (l *lookupLimiter) wait(ctx context.Context) (time.Duration, error) {
+	if l == nil {
+		return 0, nil
+	}
+	l.mu.Lock()
+	now := l.now()
+	l.tokens += l.rate * now.Sub(l.last).Seconds()
+	if burst := math.Max(l.rate, 1); l.tokens > burst {
+		l.tokens = burst
+	}
+	l.last = now
+	taken := 1.0
+	if maxDebt := l.rate * maxLookupDelay.Seconds(); l.tokens-taken < -maxDebt {
+		taken = math.Max(l.tokens+maxDebt, 0)
+	}
+	l.tokens -= taken
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+	if delay <= 0 {
+		return 0, nil
+	}
+	if err := l.sleep(ctx, delay); err != nil {
+		l.mu.Lock()
+		l.tokens += taken
+		l.mu.Unlock()
+		return 0, err
+	}
+	return delay, nil
+}
+
+// A prefetchTracker tracks whether the descriptors returned by range lookups
+// beyond the one looked up, i.e. those prefetched into the cache, are used
+// before they are removed from the cache, and adapts the number of ranges
+// returned by range lookups to it: the number doubles while most of the
+// prefetched descriptors are used, as they are by sequential scans, and
+// halves while most are not, as with random point lookups.
+type prefetchTracker struct {
+	// fixed is set if the number of ranges returned by range lookups is
+	// not adapted.
+	fixed bool
+
+	// The counters count the prefetched descriptors, those which were used
+	// and those which were removed from the cache without having been used.
+	prefetched, used, wasted *metric.Counter
+
+	mu struct {
+		sync.Mutex
+		// maxRanges is the number of ranges range lookups currently return.
+		maxRanges int32
+		// descs holds the prefetched descriptors which have not been used
+		// yet.
+		descs map[*roachpb.RangeDescriptor]struct{}
+		// prefetched and used count the prefetched descriptors and those
+		// which were used since maxRanges was last adjusted.
+		prefetched, used int
+	}
+}
+
+func newPrefetchTracker() *prefetchTracker {
+	t := &prefetchTracker{
+		prefetched: metric.NewCounter(),
+		used:       metric.NewCounter(),
+		wasted:     metric.NewCounter(),
+	}
+	t.mu.maxRanges = defaultRangeLookupMaxRanges
+	t.mu.descs = map[*roachpb.RangeDescriptor]struct{}{}
+	return t
+}
+
+// maxRanges returns the number of ranges range lookups should return.
+func (t *prefetchTracker) maxRanges() int32 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.mu.maxRanges
+}
+
+// adjust adapts the number of ranges returned by range lookups to the
+// fraction of the descriptors prefetched since the last adjustment which
+// were used. It is called before each range lookup.
+func (t *prefetchTracker) adjust() {
+	if t.fixed {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.mu.prefetched == 0 {
+		return
+	}
+	if 2*t.mu.used >= t.mu.prefetched {
+		if t.mu.maxRanges *= 2; t.mu.maxRanges > maxRangeLookupMaxRanges {
+			t.mu.maxRanges = maxRangeLookupMaxRanges
+		}
+	} else if 4*t.mu.used < t.mu.prefetched {
+		if t.mu.maxRanges /= 2; t.mu.maxRanges < minRangeLookupMaxRanges {
+			t.mu.maxRanges = minRangeLookupMaxRanges
+		}
+	}
+	t.mu.prefetched, t.mu.used = 0, 0
+}
+
+// added records the descriptors returned by a range lookup, all but the
+// first of which were prefetched.
+func (t *prefetchTracker) added(rs []roachpb.RangeDescriptor) {
+	if len(rs) < 2 {
+		return
+	}
+	t.mu.Lock()
+	for i := 1; i < len(rs); i++ {
+		t.mu.descs[&rs[i]] = struct{}{}
+	}
+	t.mu.prefetched += len(rs) - 1
+	t.mu.Unlock()
+	t.prefetched.Inc(int64(len(rs) - 1))
+}
+
+// hit records the use of the given cached descriptor.
+func (t *prefetchTracker) hit(desc *roachpb.RangeDescriptor) {
+	t.mu.Lock()
+	_, ok := t.mu.descs[desc]
+	if ok {
+		delete(t.mu.descs, desc)
+		t.mu.used++
+	}
+	t.mu.Unlock()
+	if ok {
+		t.used.Inc(1)
+	}
+}
+
+// removed records the removal of the given descriptor from the cache.
+func (t *prefetchTracker) removed(desc *roachpb.RangeDescriptor) {
+	t.mu.Lock()
+	_, ok := t.mu.descs[desc]
+	delete(t.mu.descs, desc)
+	t.mu.Unlock()
+	if ok {
+		t.wasted.Inc(1)
+	}
+}
+
+// A lookupRequest is a range lookup in flight. Cache misses which would
+// issue the same lookup wait for it to complete instead.
+type lookupRequest struct {
+	// done is closed once descs and pErr are set.
+	done  chan struct{}
+	descs []roachpb.RangeDescriptor
+	pErr  *roachpb.Error
+	// waiters is the number of cache misses waiting for the lookup.
+	waiters int
 }
 
 // newRangeDescriptorCache returns a new RangeDescriptorCache which
 // uses the given RangeDescriptorDB as the underlying source of range
 // descriptors.
 func newRangeDescriptorCache(db RangeDescriptorDB, size int) *rangeDescriptorCache {
-	return &rangeDescriptorCache{
-		db: db,
-		rangeCache: cache.NewOrderedCache(cache.Config{
-			Policy: cache.CacheLRU,
-			ShouldEvict: func(n int, k, v interface{}) bool {
-				return n > size
-			},
-		}),
+	rdc := &rangeDescriptorCache{
+		db:             db,
+		lookupRequests: map[string]*lookupRequest{},
+		cachedAt:       map[*roachpb.RangeDescriptor]time.Time{},
+		prefetch:       newPrefetchTracker(),
+	}
+	rdc.rangeCache = cache.NewOrderedCache(cache.Config{
+		Policy: cache.CacheLRU,
+		ShouldEvict: func(n int, k, v interface{}) bool {
+			return n > size
+		},
+		OnEvicted: func(k, v interface{}) {
+			desc := v.(*roachpb.RangeDescriptor)
+			delete(rdc.cachedAt, desc)
+			rdc.prefetch.removed(desc)
+		},
+	})
+	return rdc
+}
+
+// setLookupRateLimit limits the lookups of meta1 and meta2 records to the
+// given rate per second each. A rate of zero removes the limit.
+func (rdc *rangeDescriptorCache) setLookupRateLimit(rate float64) {
+	if rate <= 0 {
+		rdc.meta1Limiter, rdc.meta2Limiter = nil, nil
+		return
 	}
+	rdc.meta1Limiter, rdc.meta2Limiter = newLookupLimiter(rate), newLookupLimiter(rate)
+}
+
+// setLookupMaxRanges fixes the number of ranges returned by range lookups.
+// By default, it is adapted to the use of the prefetched descriptors.
+func (rdc *rangeDescriptorCache) setLookupMaxRanges(maxRanges int32) {
+	rdc.prefetch.fixed = true
+	rdc.prefetch.mu.Lock()
+	rdc.prefetch.mu.maxRanges = maxRanges
+	rdc.prefetch.mu.Unlock()
+}
+
+// lookupMaxRanges returns the number of ranges range lookups should return.
+func (rdc *rangeDescriptorCache) lookupMaxRanges() int32 {
+	return rdc.prefetch.maxRanges()
 }
 
 func (rdc *rangeDescriptorCache) String() string {
@@ -115,10 +383,12 @@ func (rdc *rangeDescriptorCache) stringLocked() string {
 // cached for subsequent lookups.
 //
 // This method returns the RangeDescriptor for the range containing
-// the key's data, or an error if any occurred.
-func (rdc *rangeDescriptorCache) LookupRangeDescriptor(key roachpb.RKey,
+// the key's data, or an error if any occurred. The range lookups are traced
+// as part of the trace carried by the context.
+func (rdc *rangeDescriptorCache) LookupRangeDescriptor(ctx context.Context, key roachpb.RKey,
 	considerIntents, useReverseScan bool) (*roachpb.RangeDescriptor, *roachpb.Error) {
 	if _, r := rdc.getCachedRangeDescriptor(key, useReverseScan); r != nil {
+		rdc.prefetch.hit(r)
 		return r, nil
 	}
 
@@ -127,53 +397,184 @@ func (rdc *rangeDescriptorCache) LookupRangeDescriptor(key roachpb.RKey,
 	} else if log.V(1) {
 		log.Infof("lookup range descriptor: key=%s", key)
 	}
-	rs, pErr := func(key roachpb.RKey, considerIntents, useReverseScan bool) ([]roachpb.RangeDescriptor, *roachpb.Error) {
-		var (
-			// metadataKey is sent to rangeLookup to find the
-			// RangeDescriptor which contains key.
-			metadataKey = meta(key)
-			// desc is the RangeDescriptor for the range which contains
-			// metadataKey.
-			desc *roachpb.RangeDescriptor
-			pErr *roachpb.Error
-		)
-		if bytes.Equal(metadataKey, roachpb.RKeyMin) {
-			// In this case, the requested key is stored in the cluster's first
-			// range. Return the first range, which is always gossiped and not
-			// queried from the datastore.
-			desc, pErr = rdc.db.FirstRange()
-			if pErr != nil {
-				return nil, pErr
-			}
-			return []roachpb.RangeDescriptor{*desc}, nil
+	for {
+		rs, coalesced, pErr := rdc.lookupRangeDescriptors(ctx, key, considerIntents, useReverseScan)
+		if pErr != nil {
+			return nil, pErr
 		}
-		if bytes.HasPrefix(metadataKey, keys.Meta1Prefix) {
-			// In this case, desc is the cluster's first range.
-			if desc, pErr = rdc.db.FirstRange(); pErr != nil {
-				return nil, pErr
+		if !coalesced {
+			return &rs[0], nil
+		}
+		// The lookup was made on behalf of another key, so it may not have
+		// returned the range containing ours. If it didn't, the gap in the
+		// cache has shrunk and we look again.
+		for i := range rs {
+			if containsKey(&rs[i], key, useReverseScan) {
+				rdc.prefetch.hit(&rs[i])
+				return &rs[i], nil
 			}
-		} else {
-			// Look up desc from the cache, which will recursively call into
-			// this function if it is not cached.
-			desc, pErr = rdc.LookupRangeDescriptor(metadataKey, considerIntents, useReverseScan)
-			if pErr != nil {
-				return nil, pErr
+		}
+		if _, r := rdc.getCachedRangeDescriptor(key, useReverseScan); r != nil {
+			rdc.prefetch.hit(r)
+			return r, nil
+		}
+	}
+}
+
+// prefetchRangeDescriptor looks up the descriptor of the range containing
+// the given key in the background, unless it is cached or already being
+// looked up. It is used to look up the next range of a multi-range query
+// while the current one is being queried. The lookup runs as a worker of
+// the given stopper.
+func (rdc *rangeDescriptorCache) prefetchRangeDescriptor(stopper *stop.Stopper,
+	key roachpb.RKey, considerIntents, useReverseScan bool) {
+	if _, r := rdc.getCachedRangeDescriptor(key, useReverseScan); r != nil {
+		return
+	}
+	reqKey := rdc.lookupRequestKey(key, considerIntents, useReverseScan)
+	rdc.lookupMu.Lock()
+	_, inFlight := rdc.lookupRequests[reqKey]
+	rdc.lookupMu.Unlock()
+	if inFlight {
+		return
+	}
+	stopper.RunWorker(func() {
+		// The prefetch outlives the request which triggered it, so it isn't
+		// part of its trace.
+		if _, pErr := rdc.LookupRangeDescriptor(context.Background(), key, considerIntents, useReverseScan); pErr != nil {
+			if log.V(1) {
+				log.Infof("failed to prefetch range descriptor for key=%s: %s", key, pErr)
 			}
 		}
-		return rdc.db.RangeLookup(metadataKey, desc, considerIntents, useReverseScan)
-	}(key, considerIntents, useReverseScan)
-	if pErr != nil {
-		return nil, pErr
-	}
-	if len(rs) == 0 {
-		panic(fmt.Sprintf("no range descriptors returned for %s", key))
-	}
-	// TODO(tamird): there is a race here; multiple readers may experience cache
-	// misses and concurrently attempt to refresh the cache, duplicating work.
-	// Locking over the getRangeDescriptors call is even worse though, because
-	// that blocks the cache completely for the duration of a slow query to the
-	// cluster.
+	})
+}
+
+// lookupRangeDescriptors looks up the descriptors of the range containing
+// the given key and of the ranges following it, and adds them to the cache.
+// Concurrent cache misses for keys which fall into the same gap of the
+// cache are coalesced into a single lookup, whose result is shared; the
+// returned boolean is true if the lookup was made on behalf of another key,
+// in which case the descriptors may not include the range containing key.
+// The lookup is only traced as part of the trace of the context of the key
+// on whose behalf it is made.
+func (rdc *rangeDescriptorCache) lookupRangeDescriptors(ctx context.Context, key roachpb.RKey,
+	considerIntents, useReverseScan bool) ([]roachpb.RangeDescriptor, bool, *roachpb.Error) {
+	reqKey := rdc.lookupRequestKey(key, considerIntents, useReverseScan)
+	rdc.lookupMu.Lock()
+	if req, ok := rdc.lookupRequests[reqKey]; ok {
+		req.waiters++
+		rdc.lookupMu.Unlock()
+		if sp := opentracing.SpanFromContext(ctx); sp != nil {
+			sp.LogEvent("waiting for range lookup in flight")
+		}
+		<-req.done
+		return req.descs, true, req.pErr
+	}
+	req := &lookupRequest{done: make(chan struct{})}
+	rdc.lookupRequests[reqKey] = req
+	rdc.lookupMu.Unlock()
+
+	rdc.prefetch.adjust()
+	req.descs, req.pErr = rdc.performRangeLookup(ctx, key, considerIntents, useReverseScan)
+	if req.pErr == nil {
+		if len(req.descs) == 0 {
+			panic(fmt.Sprintf("no range descriptors returned for %s", key))
+		}
+		rdc.prefetch.added(req.descs)
+		rdc.addRangeDescriptors(req.descs)
+	}
+
+	rdc.lookupMu.Lock()
+	delete(rdc.lookupRequests, reqKey)
+	if log.V(1) && req.waiters > 0 {
+		log.Infof("coalesced %d range lookups with lookup of key=%s", req.waiters, key)
+	}
+	rdc.lookupMu.Unlock()
+	close(req.done)
+	return req.descs, false, req.pErr
+}
+
+// lookupRequestKey returns the key under which a range lookup for the given
+// key is coalesced with others. Keys are coalesced if they fall into the
+// same gap of the cache, i.e. if the cached descriptor following them is the
+// same, and if they are on the same level of range metadata.
+func (rdc *rangeDescriptorCache) lookupRequestKey(key roachpb.RKey,
+	considerIntents, useReverseScan bool) string {
+	var metaKey roachpb.RKey
+	if !useReverseScan {
+		metaKey = meta(key.Next())
+	} else {
+		metaKey = meta(key)
+	}
+	var buf bytes.Buffer
+	// The first byte of a meta key identifies its level (meta1 or meta2).
+	// Lookups on different levels must never be coalesced, since a lookup
+	// recursively looks up the range holding its meta key.
+	if len(metaKey) > 0 {
+		buf.WriteByte(metaKey[0])
+	}
+	fmt.Fprintf(&buf, "%t%t", considerIntents, useReverseScan)
+	rdc.rangeCacheMu.RLock()
+	if k, _, ok := rdc.rangeCache.Ceil(rangeCacheKey(metaKey)); ok {
+		buf.Write(k.(rangeCacheKey))
+	}
+	rdc.rangeCacheMu.RUnlock()
+	return buf.String()
+}
+
+// performRangeLookup queries the RangeDescriptorDB for the descriptors of
+// the range containing the given key and of the ranges following it.
+func (rdc *rangeDescriptorCache) performRangeLookup(ctx context.Context, key roachpb.RKey,
+	considerIntents, useReverseScan bool) ([]roachpb.RangeDescriptor, *roachpb.Error) {
+	var (
+		// metadataKey is sent to rangeLookup to find the
+		// RangeDescriptor which contains key.
+		metadataKey = meta(key)
+		// desc is the RangeDescriptor for the range which contains
+		// metadataKey.
+		desc *roachpb.RangeDescriptor
+		pErr *roachpb.Error
+	)
+	if bytes.Equal(metadataKey, roachpb.RKeyMin) {
+		// In this case, the requested key is stored in the cluster's first
+		// range. Return the first range, which is always gossiped and not
+		// queried from the datastore.
+		desc, pErr = rdc.db.FirstRange()
+		if pErr != nil {
+			return nil, pErr
+		}
+		return []roachpb.RangeDescriptor{*desc}, nil
+	}
+	limiter := rdc.meta2Limiter
+	if bytes.HasPrefix(metadataKey, keys.Meta1Prefix) {
+		// In this case, desc is the cluster's first range.
+		if desc, pErr = rdc.db.FirstRange(); pErr != nil {
+			return nil, pErr
+		}
+		limiter = rdc.meta1Limiter
+	} else {
+		// Look up desc from the cache, which will recursively call into
+		// this function if it is not cached.
+		desc, pErr = rdc.LookupRangeDescriptor(ctx, metadataKey, considerIntents, useReverseScan)
+		if pErr != nil {
+			return nil, pErr
+		}
+	}
+	delay, err := limiter.wait(ctx)
+	if err != nil {
+		return nil, roachpb.NewError(err)
+	}
+	if delay > 0 && log.V(1) {
+		log.Infof("range lookup of key=%s delayed by %s by the rate limit", metadataKey, delay)
+	}
+	return rdc.db.RangeLookup(ctx, metadataKey, desc, considerIntents, useReverseScan)
+}
+
+// addRangeDescriptors adds the descriptors returned by a range lookup to
+// the cache.
+func (rdc *rangeDescriptorCache) addRangeDescriptors(rs []roachpb.RangeDescriptor) {
 	rdc.rangeCacheMu.Lock()
+	defer rdc.rangeCacheMu.Unlock()
 	for i := range rs {
 		// Note: we append the end key of each range to meta records
 		// so that calls to rdc.rangeCache.Ceil() for a key will return
@@ -189,10 +590,59 @@ func (rdc *rangeDescriptorCache) LookupRangeDescriptor(key roachpb.RKey,
 			log.Infof("adding descriptor: key=%s desc=%s", rangeKey, &rs[i])
 		}
 		rdc.clearOverlappingCachedRangeDescriptors(&rs[i])
+		rdc.cachedAt[&rs[i]] = time.Now()
 		rdc.rangeCache.Add(rangeCacheKey(rangeKey), &rs[i])
 	}
-	rdc.rangeCacheMu.Unlock()
-	return &rs[0], nil
+}
+
+// CachedRangeDescriptor is an entry of the range descriptor cache.
+type CachedRangeDescriptor struct {
+	Desc roachpb.RangeDescriptor `json:"desc"`
+	// CachedAt is the time at which the descriptor was cached.
+	CachedAt time.Time `json:"cached_at"`
+	// Age is the time elapsed since, in nanoseconds.
+	Age time.Duration `json:"age"`
+}
+
+// descriptors returns the entries of the cache in key order, starting with
+// the range which contains the start key. If limit is positive, at most
+// limit entries are returned, along with the key to start the next page
+// at, which is nil once all entries were returned.
+func (rdc *rangeDescriptorCache) descriptors(start roachpb.RKey, limit int) (
+	[]CachedRangeDescriptor, roachpb.RKey) {
+	rdc.rangeCacheMu.RLock()
+	defer rdc.rangeCacheMu.RUnlock()
+	now := time.Now()
+	var descs []CachedRangeDescriptor
+	// The cache is indexed using the meta keys of the end keys of the
+	// ranges, so walk it from the first range ending after the start key.
+	k, v, ok := rdc.rangeCache.Ceil(rangeCacheKey(meta(start.Next())))
+	for ; ok; k, v, ok = rdc.rangeCache.Ceil(rangeCacheKey(roachpb.RKey(k.(rangeCacheKey)).Next())) {
+		desc := v.(*roachpb.RangeDescriptor)
+		if !start.Less(desc.EndKey) {
+			continue
+		}
+		if limit > 0 && len(descs) == limit {
+			return descs, descs[len(descs)-1].Desc.EndKey
+		}
+		cachedAt := rdc.cachedAt[desc]
+		descs = append(descs, CachedRangeDescriptor{
+			Desc:     *desc,
+			CachedAt: cachedAt,
+			Age:      now.Sub(cachedAt),
+		})
+	}
+	return descs, nil
+}
+
+// containsKey returns whether the range contains the key. If inclusive is
+// set, the range is considered to contain its end key instead of its start
+// key, matching getCachedRangeDescriptor.
+func containsKey(desc *roachpb.RangeDescriptor, key roachpb.RKey, inclusive bool) bool {
+	if inclusive {
+		return desc.StartKey.Less(key) && !desc.EndKey.Less(key)
+	}
+	return desc.ContainsKey(key)
 }
 
 // EvictCachedRangeDescriptor will evict any cached range descriptors
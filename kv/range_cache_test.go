@@ -19,14 +19,22 @@ package kv
 import (
 	"bytes"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/biogo/store/llrb"
+	opentracing "github.com/opentracing/opentracing-go"
+	"golang.org/x/net/context"
+
 	"github.com/cockroachdb/cockroach/keys"
 	"github.com/cockroachdb/cockroach/roachpb"
 	"github.com/cockroachdb/cockroach/testutils"
+	"github.com/cockroachdb/cockroach/util"
 	"github.com/cockroachdb/cockroach/util/leaktest"
 	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/stop"
+	"github.com/cockroachdb/cockroach/util/tracing"
 )
 
 type testDescriptorDB struct {
@@ -71,7 +79,7 @@ func (db *testDescriptorDB) FirstRange() (*roachpb.RangeDescriptor, *roachpb.Err
 	return nil, nil
 }
 
-func (db *testDescriptorDB) RangeLookup(key roachpb.RKey, _ *roachpb.RangeDescriptor, _, _ bool) ([]roachpb.RangeDescriptor, *roachpb.Error) {
+func (db *testDescriptorDB) RangeLookup(_ context.Context, key roachpb.RKey, _ *roachpb.RangeDescriptor, _, _ bool) ([]roachpb.RangeDescriptor, *roachpb.Error) {
 	db.lookupCount++
 	if bytes.HasPrefix(key, keys.Meta2Prefix) {
 		return db.getDescriptor(key[len(keys.Meta2Prefix):]), nil
@@ -127,7 +135,7 @@ func (db *testDescriptorDB) assertLookupCount(t *testing.T, expected int, key st
 }
 
 func doLookup(t *testing.T, rc *rangeDescriptorCache, key string) *roachpb.RangeDescriptor {
-	r, pErr := rc.LookupRangeDescriptor(roachpb.RKey(key), false /* considerIntents */, false /* useReverseScan */)
+	r, pErr := rc.LookupRangeDescriptor(context.Background(), roachpb.RKey(key), false /* considerIntents */, false /* useReverseScan */)
 	if pErr != nil {
 		t.Fatalf("Unexpected error from LookupRangeDescriptor: %s", pErr)
 	}
@@ -218,6 +226,120 @@ func TestRangeCache(t *testing.T) {
 
 }
 
+// TestRangeCacheLookupRateLimit verifies that the lookups of meta1 and
+// meta2 records are delayed once they exceed the rate limit of their level.
+func TestRangeCacheLookupRateLimit(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	db := newTestDescriptorDB()
+	for i, char := range "abcdefghijklmnopqrstuvwx" {
+		db.splitRange(t, roachpb.RKey(string(char)))
+		if i > 0 && i%6 == 0 {
+			db.splitRange(t, meta(roachpb.RKey(string(char))))
+		}
+	}
+
+	db.cache = newRangeDescriptorCache(db, 2<<10)
+	db.cache.setLookupRateLimit(2)
+	// The clock only advances when sleeping, so the tokens only accrue
+	// while lookups are delayed.
+	now := time.Unix(0, 0)
+	var delays []time.Duration
+	for _, l := range []*lookupLimiter{db.cache.meta1Limiter, db.cache.meta2Limiter} {
+		l.now = func() time.Time { return now }
+		l.last = now
+		l.sleep = func(_ context.Context, d time.Duration) error {
+			delays = append(delays, d)
+			now = now.Add(d)
+			return nil
+		}
+	}
+
+	// Both levels are looked up, within the burst of each limiter.
+	doLookup(t, db.cache, "aa")
+	db.assertLookupCount(t, 2, "aa")
+	// Only meta2 records are looked up, exhausting its burst.
+	doLookup(t, db.cache, "d")
+	db.assertLookupCount(t, 1, "d")
+	if len(delays) != 0 {
+		t.Fatalf("expected no delays within the burst, got %v", delays)
+	}
+	doLookup(t, db.cache, "ij")
+	db.assertLookupCount(t, 1, "ij")
+	doLookup(t, db.cache, "pn")
+	db.assertLookupCount(t, 1, "pn")
+	if expected := []time.Duration{500 * time.Millisecond, 500 * time.Millisecond}; !reflect.DeepEqual(delays, expected) {
+		t.Fatalf("expected meta2 lookups to be delayed by %v, got %v", expected, delays)
+	}
+
+	// The meta1 limiter accrued tokens while the meta2 lookups were delayed.
+	delays = nil
+	doLookup(t, db.cache, "vu")
+	db.assertLookupCount(t, 2, "vu")
+	if expected := []time.Duration{500 * time.Millisecond}; !reflect.DeepEqual(delays, expected) {
+		t.Fatalf("expected only the meta2 lookup to be delayed by %v, got %v", expected, delays)
+	}
+
+	// Removing the limit removes the delays.
+	delays = nil
+	db.cache.setLookupRateLimit(0)
+	db.cache.EvictCachedRangeDescriptor(roachpb.RKey("da"), nil, false)
+	doLookup(t, db.cache, "da")
+	db.assertLookupCount(t, 2, "da")
+	if len(delays) != 0 {
+		t.Fatalf("expected no delays without a limit, got %v", delays)
+	}
+}
+
+// TestLookupLimiter verifies that the delays of the lookups queued by a
+// lookupLimiter are capped, and that a lookup whose context is done stops
+// waiting and returns its token.
+func TestLookupLimiter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	now := time.Unix(0, 0)
+	l := newLookupLimiter(4)
+	l.now = func() time.Time { return now }
+	l.last = now
+	l.sleep = func(context.Context, time.Duration) error { return nil }
+
+	// The burst of 4 lookups isn't delayed, after which the delays grow by
+	// the interval between tokens up to maxLookupDelay.
+	var last time.Duration
+	for i := 0; i < 40; i++ {
+		delay, err := l.wait(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := time.Duration(i-3) * 250 * time.Millisecond
+		if expected < 0 {
+			expected = 0
+		} else if expected > maxLookupDelay {
+			expected = maxLookupDelay
+		}
+		if delay != expected {
+			t.Fatalf("%d: expected a delay of %s, got %s", i, expected, delay)
+		}
+		last = delay
+	}
+	if last != maxLookupDelay {
+		t.Fatalf("expected the delays to be capped at %s, got %s", maxLookupDelay, last)
+	}
+
+	// A cancelled lookup returns its token, so the next lookup, once the
+	// debt was repaid, isn't delayed.
+	now = now.Add(maxLookupDelay)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	l.sleep = sleepContext
+	if _, err := l.wait(ctx); err != context.Canceled {
+		t.Fatalf("expected the lookup to be cancelled, got %v", err)
+	}
+	l.sleep = func(context.Context, time.Duration) error { return nil }
+	now = now.Add(250 * time.Millisecond)
+	if delay, err := l.wait(context.Background()); err != nil || delay != 0 {
+		t.Fatalf("expected no delay after a cancelled lookup, got %s, %v", delay, err)
+	}
+}
+
 // TestRangeCacheClearOverlapping verifies that existing, overlapping
 // cached entries are cleared when adding a new entry.
 func TestRangeCacheClearOverlapping(t *testing.T) {
@@ -388,3 +510,256 @@ func TestGetCachedRangeDescriptorInclusive(t *testing.T) {
 	}
 
 }
+
+// TestRangeCacheDescriptorsPagination verifies that the contents of the
+// cache can be listed page by page.
+func TestRangeCacheDescriptorsPagination(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []*roachpb.RangeDescriptor{
+		{StartKey: roachpb.RKey("a"), EndKey: roachpb.RKey("c")},
+		{StartKey: roachpb.RKey("c"), EndKey: roachpb.RKey("e")},
+		{StartKey: roachpb.RKey("g"), EndKey: roachpb.RKey("z")},
+	}
+
+	cache := newRangeDescriptorCache(nil, 2<<10)
+	for _, rd := range testData {
+		cache.rangeCache.Add(rangeCacheKey(keys.RangeMetaKey(rd.EndKey)), rd)
+	}
+
+	testCases := []struct {
+		start     roachpb.RKey
+		limit     int
+		endKeys   []string
+		nextStart roachpb.RKey
+	}{
+		{roachpb.RKeyMin, 0, []string{"c", "e", "z"}, nil},
+		{roachpb.RKeyMin, 2, []string{"c", "e"}, roachpb.RKey("e")},
+		{roachpb.RKeyMin, 3, []string{"c", "e", "z"}, nil},
+		{roachpb.RKey("c"), 1, []string{"e"}, roachpb.RKey("e")},
+		{roachpb.RKey("e"), 1, []string{"z"}, nil},
+		{roachpb.RKey("d"), 0, []string{"e", "z"}, nil},
+		{roachpb.RKey("z"), 0, nil, nil},
+	}
+
+	for i, test := range testCases {
+		descs, nextStart := cache.descriptors(test.start, test.limit)
+		var endKeys []string
+		for _, d := range descs {
+			endKeys = append(endKeys, string(d.Desc.EndKey))
+		}
+		if !reflect.DeepEqual(endKeys, test.endKeys) {
+			t.Errorf("%d: expected ranges ending at %v, got %v", i, test.endKeys, endKeys)
+		}
+		if !nextStart.Equal(test.nextStart) {
+			t.Errorf("%d: expected next start %q, got %q", i, test.nextStart, nextStart)
+		}
+	}
+}
+
+// blockingDescriptorDB is a testDescriptorDB whose range lookups block
+// while unblock is set.
+type blockingDescriptorDB struct {
+	*testDescriptorDB
+	mu      sync.Mutex
+	unblock chan struct{}
+}
+
+func (db *blockingDescriptorDB) RangeLookup(ctx context.Context, key roachpb.RKey, desc *roachpb.RangeDescriptor,
+	considerIntents, useReverseScan bool) ([]roachpb.RangeDescriptor, *roachpb.Error) {
+	db.mu.Lock()
+	unblock := db.unblock
+	db.mu.Unlock()
+	if unblock != nil {
+		<-unblock
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.testDescriptorDB.RangeLookup(ctx, key, desc, considerIntents, useReverseScan)
+}
+
+// TestRangeCacheCoalescedLookups verifies that concurrent cache misses for
+// keys in the same gap of the cache are served by a single range lookup.
+func TestRangeCacheCoalescedLookups(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	db := &blockingDescriptorDB{testDescriptorDB: newTestDescriptorDB()}
+	for _, char := range "bcdy" {
+		db.splitRange(t, roachpb.RKey(string(char)))
+	}
+	rc := newRangeDescriptorCache(db, 2<<10)
+
+	// Cache the meta descriptor, so that only the lookups of the ranges
+	// themselves remain.
+	doLookup(t, rc, "yy")
+	db.assertLookupCount(t, 2, "yy")
+
+	db.mu.Lock()
+	db.unblock = make(chan struct{})
+	db.mu.Unlock()
+
+	const numLookups = 10
+	descs := make(chan *roachpb.RangeDescriptor, numLookups)
+	for i := 0; i < numLookups; i++ {
+		key := roachpb.RKey([]byte{'a', byte('a' + i)})
+		go func() {
+			desc, pErr := rc.LookupRangeDescriptor(context.Background(), key, false /* considerIntents */, false /* useReverseScan */)
+			if pErr != nil {
+				t.Error(pErr)
+			}
+			descs <- desc
+		}()
+	}
+
+	// Wait for all but one of the lookups to wait for the other.
+	util.SucceedsSoon(t, func() error {
+		rc.lookupMu.Lock()
+		defer rc.lookupMu.Unlock()
+		for _, req := range rc.lookupRequests {
+			if req.waiters == numLookups-1 {
+				return nil
+			}
+		}
+		return util.Errorf("lookups not coalesced yet: %v", rc.lookupRequests)
+	})
+	close(db.unblock)
+
+	first := <-descs
+	for i := 1; i < numLookups; i++ {
+		if desc := <-descs; desc != first {
+			t.Errorf("expected the lookups to share descriptor %s, got %s", first, desc)
+		}
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.assertLookupCount(t, 1, "aa-aj")
+}
+
+// TestRangeCachePrefetch verifies that prefetching a range descriptor looks
+// it up in the background and adds it to the cache.
+func TestRangeCachePrefetch(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+	db := &blockingDescriptorDB{testDescriptorDB: newTestDescriptorDB()}
+	for _, char := range "bcdefg" {
+		db.splitRange(t, roachpb.RKey(string(char)))
+	}
+	rc := newRangeDescriptorCache(db, 2<<10)
+
+	// The lookup caches [a,b), [b,c) and [c,d).
+	doLookup(t, rc, "a")
+	db.assertLookupCount(t, 2, "a")
+
+	db.mu.Lock()
+	db.unblock = make(chan struct{})
+	db.mu.Unlock()
+
+	rc.prefetchRangeDescriptor(stopper, roachpb.RKey("d"), false /* considerIntents */, false /* useReverseScan */)
+	util.SucceedsSoon(t, func() error {
+		rc.lookupMu.Lock()
+		defer rc.lookupMu.Unlock()
+		if len(rc.lookupRequests) != 1 {
+			return util.Errorf("expected a lookup in flight, got %v", rc.lookupRequests)
+		}
+		return nil
+	})
+	// Prefetching a key while its lookup is in flight doesn't look it up
+	// again.
+	rc.prefetchRangeDescriptor(stopper, roachpb.RKey("d"), false /* considerIntents */, false /* useReverseScan */)
+	close(db.unblock)
+
+	util.SucceedsSoon(t, func() error {
+		if _, desc := rc.getCachedRangeDescriptor(roachpb.RKey("d"), false /* inclusive */); desc == nil {
+			return util.Errorf("range descriptor for d not cached yet")
+		}
+		return nil
+	})
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.assertLookupCount(t, 1, "d")
+}
+
+// TestRangeCachePrefetchTracker verifies that the use of prefetched
+// descriptors is tracked and that the number of ranges returned by range
+// lookups adapts to it.
+func TestRangeCachePrefetchTracker(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tr := newPrefetchTracker()
+	lookup := func() []roachpb.RangeDescriptor {
+		tr.adjust()
+		rs := make([]roachpb.RangeDescriptor, tr.maxRanges())
+		tr.added(rs)
+		return rs
+	}
+
+	// Sequential scans use all the prefetched descriptors, and the lookups
+	// grow up to the upper bound.
+	for i := 0; i < 10; i++ {
+		rs := lookup()
+		for j := range rs {
+			tr.hit(&rs[j])
+		}
+	}
+	if n := tr.maxRanges(); n != maxRangeLookupMaxRanges {
+		t.Errorf("expected lookups of %d ranges, got %d", maxRangeLookupMaxRanges, n)
+	}
+
+	// Random point lookups don't, and the lookups shrink down to the lower
+	// bound. The unused descriptors count as wasted once removed.
+	var unused int64
+	for i := 0; i < 10; i++ {
+		rs := lookup()
+		tr.hit(&rs[0])
+		for j := 1; j < len(rs); j++ {
+			tr.removed(&rs[j])
+			unused++
+		}
+	}
+	if n := tr.maxRanges(); n != minRangeLookupMaxRanges {
+		t.Errorf("expected lookups of %d ranges, got %d", minRangeLookupMaxRanges, n)
+	}
+	if prefetched, used, wasted := tr.prefetched.Count(), tr.used.Count(), tr.wasted.Count(); prefetched != used+wasted || wasted != unused {
+		t.Errorf("expected %d wasted of %d prefetched descriptors, got %d used and %d wasted",
+			unused, prefetched, used, wasted)
+	}
+	if l := len(tr.mu.descs); l != 0 {
+		t.Errorf("expected no tracked descriptors, got %d", l)
+	}
+}
+
+// tracingDescriptorDB is a testDescriptorDB which records the spans of the
+// contexts of its range lookups.
+type tracingDescriptorDB struct {
+	*testDescriptorDB
+	spans []opentracing.Span
+}
+
+func (db *tracingDescriptorDB) RangeLookup(ctx context.Context, key roachpb.RKey, desc *roachpb.RangeDescriptor,
+	considerIntents, useReverseScan bool) ([]roachpb.RangeDescriptor, *roachpb.Error) {
+	db.spans = append(db.spans, opentracing.SpanFromContext(ctx))
+	return db.testDescriptorDB.RangeLookup(ctx, key, desc, considerIntents, useReverseScan)
+}
+
+// TestRangeCacheLookupTrace verifies that the range lookups, including the
+// recursive lookups of meta descriptors, are made with the context of the
+// caller, so that they become part of its trace.
+func TestRangeCacheLookupTrace(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	db := &tracingDescriptorDB{testDescriptorDB: newTestDescriptorDB()}
+	rc := newRangeDescriptorCache(db, 2<<10)
+
+	sp := tracing.NewTracer().StartSpan("test")
+	defer sp.Finish()
+	ctx := opentracing.ContextWithSpan(context.Background(), sp)
+	if _, pErr := rc.LookupRangeDescriptor(ctx, roachpb.RKey("a"), false /* considerIntents */, false /* useReverseScan */); pErr != nil {
+		t.Fatal(pErr)
+	}
+	if len(db.spans) != 2 {
+		t.Fatalf("expected 2 range lookups, got %d", len(db.spans))
+	}
+	for i, lookupSp := range db.spans {
+		if lookupSp != sp {
+			t.Errorf("%d: expected the range lookup to be traced by the caller's span", i)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package kv
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/util/cache"
+	"github.com/cockroachdb/cockroach/util/hlc"
+)
+
+// A readCache is a small read-through cache for INCONSISTENT point reads
+// of keys under a set of designated prefixes, typically hot configuration
+// keys. Entries expire after a fixed TTL, so a cached value may be stale by
+// up to the TTL in addition to the staleness of the inconsistent read
+// which populated it. Writes sent through the same DistSender evict the
+// affected entries.
+type readCache struct {
+	prefixes []roachpb.Key
+	ttl      time.Duration
+	clock    *hlc.Clock
+
+	mu    sync.Mutex
+	cache *cache.UnorderedCache
+}
+
+// readCacheEntry is a cached value along with the wall time at which it
+// expires. A nil value caches the absence of the key.
+type readCacheEntry struct {
+	value      *roachpb.Value
+	expiration int64
+}
+
+// newReadCache creates a new readCache of the given size for the given
+// key prefixes.
+func newReadCache(prefixes []roachpb.Key, ttl time.Duration, size int, clock *hlc.Clock) *readCache {
+	return &readCache{
+		prefixes: prefixes,
+		ttl:      ttl,
+		clock:    clock,
+		cache: cache.NewUnorderedCache(cache.Config{
+			Policy: cache.CacheLRU,
+			ShouldEvict: func(s int, key, value interface{}) bool {
+				return s > size
+			},
+		}),
+	}
+}
+
+// cacheable returns the key of the batch if it consists of a single
+// INCONSISTENT, non-transactional Get of a key under one of the designated
+// prefixes at the current time.
+func (rc *readCache) cacheable(ba roachpb.BatchRequest) (roachpb.Key, bool) {
+	if len(ba.Requests) != 1 || ba.Txn != nil || ba.ReadConsistency != roachpb.INCONSISTENT ||
+		!ba.Timestamp.Equal(roachpb.ZeroTimestamp) {
+		return nil, false
+	}
+	get, ok := ba.Requests[0].GetInner().(*roachpb.GetRequest)
+	if !ok || !rc.hasPrefix(get.Key) {
+		return nil, false
+	}
+	return get.Key, true
+}
+
+func (rc *readCache) hasPrefix(key roachpb.Key) bool {
+	for _, prefix := range rc.prefixes {
+		if bytes.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup returns a response for the batch from the cache, or nil if the
+// batch cannot be served from the cache.
+func (rc *readCache) Lookup(ba roachpb.BatchRequest) *roachpb.BatchResponse {
+	key, ok := rc.cacheable(ba)
+	if !ok {
+		return nil
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	v, ok := rc.cache.Get(string(key))
+	if !ok {
+		return nil
+	}
+	entry := v.(readCacheEntry)
+	if rc.clock.PhysicalNow() >= entry.expiration {
+		rc.cache.Del(string(key))
+		return nil
+	}
+	reply := &roachpb.GetResponse{}
+	if entry.value != nil {
+		value := *entry.value
+		reply.Value = &value
+	}
+	br := &roachpb.BatchResponse{}
+	br.Add(reply)
+	return br
+}
+
+// Update caches the response to a cacheable batch and evicts the entries
+// of keys written by the batch. The response is nil if the batch failed,
+// in which case its writes may still have been applied.
+func (rc *readCache) Update(ba roachpb.BatchRequest, br *roachpb.BatchResponse) {
+	if key, ok := rc.cacheable(ba); ok {
+		if br == nil {
+			return
+		}
+		entry := readCacheEntry{expiration: rc.clock.PhysicalNow() + rc.ttl.Nanoseconds()}
+		if value := br.Responses[0].GetInner().(*roachpb.GetResponse).Value; value != nil {
+			copied := *value
+			entry.value = &copied
+		}
+		rc.mu.Lock()
+		rc.cache.Add(string(key), entry)
+		rc.mu.Unlock()
+		return
+	}
+	if ba.IsReadOnly() {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	for _, union := range ba.Requests {
+		header := union.GetInner().Header()
+		if len(header.EndKey) == 0 {
+			rc.cache.Del(string(header.Key))
+			continue
+		}
+		// Evicting the entries of a span would require iterating over the
+		// cache, which is not worth it given its small size.
+		rc.cache.Clear()
+		return
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package kv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/util/hlc"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+func TestReadCache(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	manual := hlc.NewManualClock(1)
+	rc := newReadCache([]roachpb.Key{roachpb.Key("cfg/")}, time.Second, 10, hlc.NewClock(manual.UnixNano))
+
+	makeGet := func(key string, consistency roachpb.ReadConsistencyType) roachpb.BatchRequest {
+		ba := roachpb.BatchRequest{}
+		ba.ReadConsistency = consistency
+		ba.Add(&roachpb.GetRequest{Span: roachpb.Span{Key: roachpb.Key(key)}})
+		return ba
+	}
+	makeReply := func(value string) *roachpb.BatchResponse {
+		v := roachpb.MakeValueFromString(value)
+		br := &roachpb.BatchResponse{}
+		br.Add(&roachpb.GetResponse{Value: &v})
+		return br
+	}
+	expectValue := func(ba roachpb.BatchRequest, expected string) {
+		br := rc.Lookup(ba)
+		if expected == "" {
+			if br != nil {
+				t.Fatalf("expected cache miss, got %+v", br)
+			}
+			return
+		}
+		if br == nil {
+			t.Fatal("expected cache hit")
+		}
+		value := br.Responses[0].GetInner().(*roachpb.GetResponse).Value
+		if s, err := value.GetBytes(); err != nil || string(s) != expected {
+			t.Fatalf("expected %q, got %q (%v)", expected, s, err)
+		}
+	}
+
+	get := makeGet("cfg/a", roachpb.INCONSISTENT)
+	expectValue(get, "")
+	rc.Update(get, makeReply("1"))
+	expectValue(get, "1")
+
+	// Consistent reads and reads outside of the prefixes are not cached.
+	consistent := makeGet("cfg/a", roachpb.CONSISTENT)
+	rc.Update(consistent, makeReply("2"))
+	expectValue(consistent, "")
+	other := makeGet("other", roachpb.INCONSISTENT)
+	rc.Update(other, makeReply("3"))
+	expectValue(other, "")
+
+	// Entries expire after the TTL.
+	manual.Increment(time.Second.Nanoseconds())
+	expectValue(get, "")
+
+	// Writes evict the entries of the keys they write.
+	rc.Update(get, makeReply("4"))
+	expectValue(get, "4")
+	put := roachpb.BatchRequest{}
+	put.Add(&roachpb.PutRequest{Span: roachpb.Span{Key: roachpb.Key("cfg/a")}})
+	rc.Update(put, nil)
+	expectValue(get, "")
+}
@@ -17,10 +17,14 @@
 package kv
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
 	"os"
+	"sort"
+	"sync"
 	"time"
 
 	opentracing "github.com/opentracing/opentracing-go"
@@ -30,7 +34,9 @@ import (
 	"github.com/cockroachdb/cockroach/roachpb"
 	"github.com/cockroachdb/cockroach/rpc"
 	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/admission"
 	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/metric"
 	"github.com/cockroachdb/cockroach/util/retry"
 )
 
@@ -43,6 +49,11 @@ const (
 	orderStable = iota
 	// orderRandom randomly orders available endpoints.
 	orderRandom
+	// orderLatency orders available endpoints by the round-trip latencies
+	// measured by the rpc heartbeats, fastest first. Endpoints with equal
+	// or unknown latencies keep the order provided, which therefore acts as
+	// the fallback.
+	orderLatency
 )
 
 // A SendOptions structure describes the algorithm for sending RPCs to one or
@@ -53,13 +64,95 @@ type SendOptions struct {
 	// deciding which to send to (if there are more than one).
 	Ordering orderingPolicy
 	// SendNextTimeout is the duration after which RPCs are sent to
-	// other replicas in a set.
+	// other replicas in a set. It is used for replicas whose latency is
+	// unknown, and bounds the delay derived from the latency of others.
 	SendNextTimeout time.Duration
 	// Timeout is the maximum duration of an RPC before failure.
 	// 0 for no timeout.
 	Timeout time.Duration
+	// Hedge is set for batches which any replica can serve. Their RPCs are
+	// sent to the next replica once the last one tried takes longer than
+	// usual, rather than only once it appears to be unresponsive.
+	Hedge bool
+	// Context is the context of the request. The RPCs are cancelled and no
+	// further replicas are tried once it is done. Nil for none.
+	Context context.Context
 	// Information about the request is added to this trace. Must not be nil.
 	Trace opentracing.Span
+
+	// limiter, if set, bounds the number of RPCs in flight to each node.
+	limiter *nodeLimiter
+	// localCalls and remoteCalls, if set, count the RPCs dispatched to the
+	// local server directly and those sent through gRPC respectively.
+	localCalls  *metric.Counter
+	remoteCalls *metric.Counter
+}
+
+// A nodeLimiter bounds the number of RPCs sent concurrently to each node,
+// so that a burst of batches cannot open an unbounded number of gRPC
+// streams against one node. Surplus RPCs queue on their goroutines, so that
+// the batch may be sent to other replicas meanwhile, until an RPC to the
+// node completes, or until they time out or their context is done. Queued
+// RPCs are sent by the admission class of their batch, as described on
+// admission.Queue.
+type nodeLimiter struct {
+	limit  int
+	queued *metric.Counter
+
+	mu sync.Mutex
+	// nodes holds the nodes with RPCs in flight or queued; the others are
+	// pruned.
+	nodes map[roachpb.NodeID]*nodeQueue
+}
+
+// nodeQueue holds the admission queue of a node, and the number of RPCs
+// holding or waiting for one of its slots.
+type nodeQueue struct {
+	*admission.Queue
+	refs int
+}
+
+func newNodeLimiter(limit int, queued *metric.Counter) *nodeLimiter {
+	return &nodeLimiter{
+		limit:  limit,
+		queued: queued,
+		nodes:  map[roachpb.NodeID]*nodeQueue{},
+	}
+}
+
+// acquire takes a slot for an RPC of the given admission class to the given
+// node, waiting for one to free up if necessary. It returns the function
+// releasing the slot, or an error if the context is done first.
+func (l *nodeLimiter) acquire(ctx context.Context, nodeID roachpb.NodeID,
+	class roachpb.AdmissionClass) (func(), error) {
+	l.mu.Lock()
+	n, ok := l.nodes[nodeID]
+	if !ok {
+		n = &nodeQueue{Queue: admission.NewQueue(l.limit, l.queued)}
+		l.nodes[nodeID] = n
+	}
+	n.refs++
+	l.mu.Unlock()
+
+	if err := n.Admit(ctx, class, nil); err != nil {
+		l.unref(nodeID, n)
+		return nil, err
+	}
+	return func() {
+		n.Release()
+		l.unref(nodeID, n)
+	}, nil
+}
+
+// unref drops a reference to the queue of the given node, which is
+// forgotten once it has no RPCs in flight or queued.
+func (l *nodeLimiter) unref(nodeID roachpb.NodeID, n *nodeQueue) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n.refs--
+	if n.refs == 0 {
+		delete(l.nodes, nodeID)
+	}
 }
 
 // An rpcError indicates a failure to send the RPC. rpcErrors are
@@ -80,11 +173,72 @@ func newRPCError(err error) rpcError {
 // and without a positive outlook.
 func (r rpcError) CanRetry() bool { return true }
 
+const (
+	// sendNextLatencyMultiplier is the multiple of the p99 heartbeat latency
+	// to a replica after which an RPC sent to it is also sent to the next
+	// replica.
+	sendNextLatencyMultiplier = 10
+	// minSendNextTimeout is the lower bound of the timeout derived from the
+	// heartbeat latency to a replica, which leaves room for the processing
+	// of the RPC on fast networks.
+	minSendNextTimeout = 500 * time.Millisecond
+	// minHedgeDelay is the lower bound of the delay derived from the latency
+	// of the RPCs served by a replica, which keeps fast replicas from being
+	// hedged on scheduling hiccups.
+	minHedgeDelay = 10 * time.Millisecond
+)
+
+// sendNextTimeout returns the delay after which an RPC sent to the remote
+// address is also sent to the next replica. For batches which may be
+// hedged, it is the p99 latency of the RPCs recently served by the address,
+// so that only its slowest RPCs are hedged. Otherwise, or while the address
+// served too few RPCs, it is derived from the latencies of the heartbeats
+// to the address, and falls back to the SendNextTimeout of the options when
+// those are unknown too. The delay never exceeds SendNextTimeout.
+func sendNextTimeout(opts SendOptions, rpcContext *rpc.Context, remoteAddr string) time.Duration {
+	var timeout time.Duration
+	if p99, ok := latencyQuantile(rpcContext.RPCLatencies, remoteAddr); opts.Hedge && ok {
+		timeout = p99
+		if timeout < minHedgeDelay {
+			timeout = minHedgeDelay
+		}
+	} else if p99, ok := latencyQuantile(rpcContext.RemoteLatencies, remoteAddr); ok {
+		timeout = p99 * sendNextLatencyMultiplier
+		if timeout < minSendNextTimeout {
+			timeout = minSendNextTimeout
+		}
+	} else {
+		return opts.SendNextTimeout
+	}
+	if timeout > opts.SendNextTimeout {
+		timeout = opts.SendNextTimeout
+	}
+	return timeout
+}
+
+// latencyQuantile returns the p99 latency to the remote address recorded by
+// the monitor, which may be nil.
+func latencyQuantile(m *rpc.RemoteLatencyMonitor, remoteAddr string) (time.Duration, bool) {
+	if m == nil {
+		return 0, false
+	}
+	return m.LatencyQuantile(remoteAddr, 0.99)
+}
+
 type batchClient struct {
+	nodeID     roachpb.NodeID
 	remoteAddr string
 	conn       *grpc.ClientConn
 	client     roachpb.InternalClient
-	args       roachpb.BatchRequest
+	// limiter, if set, bounds the number of RPCs in flight to the node.
+	limiter *nodeLimiter
+	// local, if set, is the server of the local node, to which the request
+	// is dispatched directly instead of through conn and client.
+	local roachpb.InternalServer
+	// verifyLocal is set if the requests dispatched to local are checked
+	// not to be modified by it.
+	verifyLocal bool
+	args        roachpb.BatchRequest
 }
 
 func shuffleClients(clients []batchClient) {
@@ -94,9 +248,128 @@ func shuffleClients(clients []batchClient) {
 	}
 }
 
+// moveHealthyToFront moves the clients which are local or whose connections
+// are ready to the front of the slice, keeping the relative order of both
+// the healthy and the unhealthy clients, and returns the number of healthy
+// clients.
+func moveHealthyToFront(clients []batchClient) (int, error) {
+	healthy := make([]batchClient, 0, len(clients))
+	var unhealthy []batchClient
+	for _, client := range clients {
+		ok := client.local != nil
+		if !ok {
+			clientState, err := client.conn.State()
+			if err != nil {
+				return 0, err
+			}
+			ok = clientState == grpc.Ready
+		}
+		if ok {
+			healthy = append(healthy, client)
+		} else {
+			unhealthy = append(unhealthy, client)
+		}
+	}
+	copy(clients, healthy)
+	copy(clients[len(healthy):], unhealthy)
+	return len(healthy), nil
+}
+
+const (
+	// similarLatencyFraction and similarLatencyMargin define which clients
+	// have a similar latency: those whose latency exceeds the fastest of
+	// them by at most the fraction of its latency or by at most the margin,
+	// whichever is larger.
+	similarLatencyFraction = 0.2
+	similarLatencyMargin   = time.Millisecond
+)
+
+// similarLatency returns whether the given latency is similar to the given
+// fastest latency.
+func similarLatency(fastest, latency time.Duration) bool {
+	margin := time.Duration(float64(fastest) * similarLatencyFraction)
+	if margin < similarLatencyMargin {
+		margin = similarLatencyMargin
+	}
+	return latency-fastest <= margin
+}
+
+// sortClientsByLatency sorts the clients by the median of the heartbeat
+// round-trip latencies to their addresses, fastest first. Local clients
+// come first as they need no round trip, and clients whose latency is
+// unknown come last, in their relative order. Clients of similar latency
+// are shuffled, so that the load is spread among them rather than all RPCs
+// going to the fastest one.
+func sortClientsByLatency(clients []batchClient, rpcContext *rpc.Context) {
+	s := clientsByLatency{
+		clients:   clients,
+		latencies: make([]time.Duration, len(clients)),
+		known:     make([]bool, len(clients)),
+	}
+	for i, client := range clients {
+		if client.local != nil {
+			s.known[i] = true
+			continue
+		}
+		s.latencies[i], s.known[i] = rpcContext.RemoteLatency(client.remoteAddr)
+	}
+	sort.Stable(s)
+	for i := 0; i < len(clients); {
+		if clients[i].local != nil || !s.known[i] {
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(clients) && clients[j].local == nil && s.known[j] &&
+			similarLatency(s.latencies[i], s.latencies[j]) {
+			j++
+		}
+		shuffleClients(clients[i:j])
+		i = j
+	}
+}
+
+// clientsByLatency sorts clients by increasing latency, with those of
+// unknown latency last.
+type clientsByLatency struct {
+	clients   []batchClient
+	latencies []time.Duration
+	known     []bool
+}
+
+func (s clientsByLatency) Len() int { return len(s.clients) }
+
+func (s clientsByLatency) Swap(i, j int) {
+	s.clients[i], s.clients[j] = s.clients[j], s.clients[i]
+	s.latencies[i], s.latencies[j] = s.latencies[j], s.latencies[i]
+	s.known[i], s.known[j] = s.known[j], s.known[i]
+}
+
+func (s clientsByLatency) Less(i, j int) bool {
+	if s.known[i] != s.known[j] {
+		return s.known[i]
+	}
+	return s.latencies[i] < s.latencies[j]
+}
+
 type batchCall struct {
 	reply *roachpb.BatchResponse
 	err   error
+	// ambiguous is set if the RPC failed after the request may have been
+	// delivered to the replica, in which case it may have been applied.
+	ambiguous bool
+}
+
+// newSendError returns the error describing a failure to send the batch.
+// If the batch contains an EndTransaction request which may have been
+// applied, the error is an AmbiguousResultError: the transaction may have
+// committed, so neither retrying nor aborting it is correct. Other batches
+// are safely retried, as writes are idempotent within a transaction.
+func newSendError(ba roachpb.BatchRequest, ambiguous bool, msg string, canRetry bool) error {
+	if _, ok := ba.GetArg(roachpb.EndTransaction); ok && ambiguous {
+		return roachpb.NewAmbiguousResultError(msg)
+	}
+	return roachpb.NewSendError(msg, canRetry)
 }
 
 // Send sends one or more RPCs to clients specified by the slice of
@@ -106,6 +379,15 @@ type batchCall struct {
 func send(opts SendOptions, replicas ReplicaSlice,
 	args roachpb.BatchRequest, rpcContext *rpc.Context) (*roachpb.BatchResponse, error) {
 	sp := opts.Trace // must not be nil
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// The RPCs still in flight when send returns lost the race against the
+	// first reply, or are no longer waited for. Cancel them so that their
+	// replicas stop working on them.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
 	if len(replicas) < 1 {
 		return nil, roachpb.NewSendError(
@@ -115,18 +397,47 @@ func send(opts SendOptions, replicas ReplicaSlice,
 
 	done := make(chan batchCall, len(replicas))
 
+	// Skip the replicas on nodes whose breakers are open, unless that would
+	// leave none.
+	if rpcContext.Breakers != nil {
+		ready := make(ReplicaSlice, 0, len(replicas))
+		for _, replica := range replicas {
+			if rpcContext.Breakers.Ready(replica.NodeDesc.Address.String()) {
+				ready = append(ready, replica)
+			}
+		}
+		if len(ready) > 0 && len(ready) < len(replicas) {
+			sp.LogEvent(fmt.Sprintf("skipping %d replicas with open breakers", len(replicas)-len(ready)))
+			replicas = ready
+		}
+	}
+
 	clients := make([]batchClient, 0, len(replicas))
 	for _, replica := range replicas {
-		conn, err := rpcContext.GRPCDial(replica.NodeDesc.Address.String())
+		addr := replica.NodeDesc.Address.String()
+		argsCopy := args
+		argsCopy.Replica = replica.ReplicaDescriptor
+		if enableLocalCalls && rpcContext.IsLocal(replica.NodeID, addr) {
+			// Local calls don't need a connection.
+			clients = append(clients, batchClient{
+				nodeID:      replica.NodeID,
+				remoteAddr:  addr,
+				local:       rpcContext.LocalInternalServer,
+				verifyLocal: rpcContext.VerifyLocalCalls,
+				args:        argsCopy,
+			})
+			continue
+		}
+		conn, err := rpcContext.GRPCDialNode(addr, replica.NodeID)
 		if err != nil {
 			return nil, err
 		}
-		argsCopy := args
-		argsCopy.Replica = replica.ReplicaDescriptor
 		clients = append(clients, batchClient{
-			remoteAddr: replica.NodeDesc.Address.String(),
+			nodeID:     replica.NodeID,
+			remoteAddr: addr,
 			conn:       conn,
 			client:     roachpb.NewInternalClient(conn),
+			limiter:    opts.limiter,
 			args:       argsCopy,
 		})
 	}
@@ -137,57 +448,113 @@ func send(opts SendOptions, replicas ReplicaSlice,
 		orderedClients = clients
 	case orderRandom:
 		// Randomly permute order, but keep known-unhealthy clients last.
-		var nHealthy int
-		for i, client := range clients {
-			clientState, err := client.conn.State()
-			if err != nil {
-				return nil, err
-			}
-			if clientState == grpc.Ready {
-				clients[i], clients[nHealthy] = clients[nHealthy], clients[i]
-				nHealthy++
-			}
+		nHealthy, err := moveHealthyToFront(clients)
+		if err != nil {
+			return nil, err
 		}
 
 		shuffleClients(clients[:nHealthy])
 		shuffleClients(clients[nHealthy:])
 
+		orderedClients = clients
+	case orderLatency:
+		// Order the healthy clients by latency, but keep known-unhealthy
+		// clients last.
+		nHealthy, err := moveHealthyToFront(clients)
+		if err != nil {
+			return nil, err
+		}
+
+		sortClientsByLatency(clients[:nHealthy], rpcContext)
+
 		orderedClients = clients
 	}
-	// TODO(spencer): going to need to also sort by affinity; closest
-	// ping time should win. Makes sense to have the rpc client/server
-	// heartbeat measure ping times. With a bit of seasoning, each
-	// node will be able to order the healthy replicas based on latency.
+
+	var lastAddr string
+	var pending int
+	sendNext := func() {
+		client := orderedClients[0]
+		orderedClients = orderedClients[1:]
+		if client.local != nil {
+			if opts.localCalls != nil {
+				opts.localCalls.Inc(1)
+			}
+		} else if opts.remoteCalls != nil {
+			opts.remoteCalls.Inc(1)
+		}
+		sendOneFn(ctx, client, opts.Timeout, rpcContext, sp, done)
+		lastAddr = client.remoteAddr
+		pending++
+	}
 
 	// Send the first request.
-	sendOneFn(orderedClients[0], opts.Timeout, rpcContext, sp, done)
-	orderedClients = orderedClients[1:]
+	sendNext()
 
 	var errors, retryableErrors int
+	// errReply holds the first reply carrying an error, such as a
+	// NotLeaderError from a follower. It is only returned once no other
+	// RPC in flight may still succeed.
+	var errReply *roachpb.BatchResponse
+	// ambiguous is set once any RPC may have been applied without
+	// returning a reply. It sticks for batches committing a transaction:
+	// another replica's reply can't tell whether the commit was applied.
+	var ambiguous bool
+	_, hasEndTxn := args.GetArg(roachpb.EndTransaction)
+	reply := func(br *roachpb.BatchResponse) (*roachpb.BatchResponse, error) {
+		// An error reply to a replayed commit, such as a NotLeaderError or
+		// a TransactionStatusError, doesn't mean that the earlier attempt
+		// wasn't applied.
+		if hasEndTxn && ambiguous && br.Error != nil {
+			return nil, roachpb.NewAmbiguousResultError(fmt.Sprintf(
+				"commit may have been applied before %s", br.Error))
+		}
+		return br, nil
+	}
 
 	// Wait for completions.
 	var sendNextTimer util.Timer
 	defer sendNextTimer.Stop()
 	for {
-		sendNextTimer.Reset(opts.SendNextTimeout)
+		sendNextTimer.Reset(sendNextTimeout(opts, rpcContext, lastAddr))
 		select {
+		case <-ctx.Done():
+			// The RPCs in flight are cancelled along with the context.
+			sp.LogEvent("context done, abandoning RPCs")
+			// The abandoned RPCs may still be applied.
+			if hasEndTxn && (pending > 0 || ambiguous) {
+				return nil, roachpb.NewAmbiguousResultError(ctx.Err().Error())
+			}
+			return nil, ctx.Err()
+
 		case <-sendNextTimer.C:
 			sendNextTimer.Read = true
 			// On successive RPC timeouts, send to additional replicas if available.
 			if len(orderedClients) > 0 {
 				sp.LogEvent("timeout, trying next peer")
-				sendOneFn(orderedClients[0], opts.Timeout, rpcContext, sp, done)
-				orderedClients = orderedClients[1:]
+				sendNext()
 			}
 
 		case call := <-done:
+			pending--
 			err := call.err
 			if err == nil {
+				if call.reply.Error != nil && pending > 0 {
+					sp.LogEvent("error reply, waiting for outstanding RPCs")
+					if errReply == nil {
+						errReply = call.reply
+					}
+					continue
+				}
 				if log.V(2) {
 					log.Infof("successful reply: %+v", call.reply)
 				}
-
-				return call.reply, nil
+				if pending > 0 {
+					sp.LogEvent(fmt.Sprintf("cancelling %d outstanding RPCs", pending))
+				}
+				if call.reply.Error != nil && errReply != nil {
+					call.reply = errReply
+				}
+				return reply(call.reply)
 			}
 
 			// Error handling.
@@ -196,6 +563,9 @@ func send(opts SendOptions, replicas ReplicaSlice,
 			}
 
 			errors++
+			if call.ambiguous {
+				ambiguous = true
+			}
 
 			// Since we have a reconnecting client here, disconnect errors are retryable.
 			disconnected := err == io.ErrUnexpectedEOF
@@ -204,15 +574,16 @@ func send(opts SendOptions, replicas ReplicaSlice,
 			}
 
 			if remainingNonErrorRPCs := len(replicas) - errors; remainingNonErrorRPCs < 1 {
-				return nil, roachpb.NewSendError(
+				return nil, newSendError(args, ambiguous,
 					fmt.Sprintf("too many errors encountered (%d of %d total): %v",
 						errors, len(clients), err), remainingNonErrorRPCs+retryableErrors >= 1)
 			}
 			// Send to additional replicas if available.
 			if len(orderedClients) > 0 {
 				sp.LogEvent("error, trying next peer")
-				sendOneFn(orderedClients[0], opts.Timeout, rpcContext, sp, done)
-				orderedClients = orderedClients[1:]
+				sendNext()
+			} else if pending == 0 && errReply != nil {
+				return reply(errReply)
 			}
 		}
 	}
@@ -222,16 +593,46 @@ func send(opts SendOptions, replicas ReplicaSlice,
 // sending an RPC.
 var enableLocalCalls = os.Getenv("ENABLE_LOCAL_CALLS") != "0"
 
+// errModifiedLocalCall is returned by sendLocal if verify is set and the
+// local server modified the request. The request was evaluated nonetheless.
+var errModifiedLocalCall = errors.New("local server modified request")
+
+// sendLocal dispatches the request directly to the local server. The request
+// is shared with the server rather than copied, so the server must abide by
+// the ownership rules of rpc.Context.LocalInternalServer, which are checked
+// if verify is set; see rpc.Context.VerifyLocalCalls.
+func sendLocal(ctx context.Context, server roachpb.InternalServer,
+	args *roachpb.BatchRequest, verify bool) (*roachpb.BatchResponse, error) {
+	if !verify {
+		return server.Batch(ctx, args)
+	}
+	before, err := args.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	reply, err := server.Batch(ctx, args)
+	after, mErr := args.Marshal()
+	if mErr != nil {
+		return nil, mErr
+	}
+	if !bytes.Equal(before, after) {
+		log.Errorf("local server modified request: %s", args)
+		return nil, errModifiedLocalCall
+	}
+	return reply, err
+}
+
 // sendOneFn is overwritten in tests to mock sendOne.
 var sendOneFn = sendOne
 
 // sendOne invokes the specified RPC on the supplied client when the
 // client is ready. On success, the reply is sent on the channel;
-// otherwise an error is sent.
+// otherwise an error is sent. The RPC is cancelled once the context is
+// done.
 //
 // Do not call directly, but instead use sendOneFn. Tests mock out this method
 // via sendOneFn in order to test various error cases.
-func sendOne(client batchClient, timeout time.Duration,
+func sendOne(ctx context.Context, client batchClient, timeout time.Duration,
 	rpcContext *rpc.Context, trace opentracing.Span, done chan batchCall) {
 	addr := client.remoteAddr
 	if log.V(2) {
@@ -239,22 +640,52 @@ func sendOne(client batchClient, timeout time.Duration,
 	}
 	trace.LogEvent(fmt.Sprintf("sending to %s", addr))
 
-	// TODO(tamird/tschottdorf): pass this in from DistSender.
-	ctx := context.TODO()
+	parent := ctx
+	cancel := func() {}
 	if timeout != 0 {
-		ctx, _ = context.WithTimeout(ctx, timeout)
+		ctx, cancel = context.WithTimeout(ctx, timeout)
 	}
 
-	if localServer := rpcContext.LocalInternalServer; enableLocalCalls && localServer != nil && addr == rpcContext.LocalAddr {
-		reply, err := localServer.Batch(ctx, &client.args)
-		done <- batchCall{reply: reply, err: err}
+	if client.local != nil {
+		// The local server evaluates the batch synchronously and fails
+		// only before evaluating it; evaluation errors are returned in the
+		// reply. A failed local call was therefore not applied, unless it
+		// failed verification.
+		reply, err := sendLocal(ctx, client.local, &client.args, client.verifyLocal)
+		cancel()
+		done <- batchCall{reply: reply, err: err, ambiguous: err == errModifiedLocalCall}
 		return
 	}
 
 	go func() {
+		defer cancel()
+		// Take a slot for the RPC on the goroutine, so that a saturated node
+		// doesn't keep the caller from hedging to the other replicas.
+		if client.limiter != nil {
+			release, err := client.limiter.acquire(ctx, client.nodeID, client.args.AdmissionClass)
+			if err != nil {
+				done <- batchCall{err: newRPCError(
+					util.Errorf("rpc to %s failed while queued behind other rpcs to the node: %s", addr, err))}
+				return
+			}
+			defer release()
+		}
+		// Record the outcome with the breaker of the address, unless the
+		// RPC was abandoned by the caller.
+		record := func(err error) {
+			if rpcContext.Breakers == nil || parent.Err() != nil {
+				return
+			}
+			if err != nil {
+				rpcContext.Breakers.Failure(addr)
+			} else {
+				rpcContext.Breakers.Success(addr)
+			}
+		}
 		c := client.conn
 		for state, err := c.State(); state != grpc.Ready; state, err = c.WaitForStateChange(ctx, state) {
 			if err != nil {
+				record(err)
 				done <- batchCall{err: newRPCError(
 					util.Errorf("rpc to %s failed: %s", addr, err))}
 				return
@@ -266,7 +697,16 @@ func sendOne(client batchClient, timeout time.Duration,
 			}
 		}
 
+		// The errors above are returned before the request is sent. Once it
+		// is, a failure may have happened after the replica applied it.
+		start := time.Now()
 		reply, err := client.client.Batch(ctx, &client.args)
-		done <- batchCall{reply: reply, err: err}
+		record(err)
+		// Error replies, such as redirections by followers, are often much
+		// faster than the replies to the same requests by leaders.
+		if err == nil && reply.Error == nil && rpcContext.RPCLatencies != nil {
+			rpcContext.RPCLatencies.RecordLatency(addr, time.Since(start))
+		}
+		done <- batchCall{reply: reply, err: err, ambiguous: err != nil}
 	}()
 }
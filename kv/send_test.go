@@ -18,6 +18,7 @@ package kv
 
 import (
 	"errors"
+	"fmt"
 	"net"
 	"testing"
 	"time"
@@ -30,8 +31,10 @@ import (
 	"github.com/cockroachdb/cockroach/rpc"
 	"github.com/cockroachdb/cockroach/testutils"
 	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/admission"
 	"github.com/cockroachdb/cockroach/util/hlc"
 	"github.com/cockroachdb/cockroach/util/leaktest"
+	"github.com/cockroachdb/cockroach/util/metric"
 	"github.com/cockroachdb/cockroach/util/retry"
 	"github.com/cockroachdb/cockroach/util/stop"
 	"github.com/cockroachdb/cockroach/util/tracing"
@@ -43,6 +46,7 @@ func newNodeTestContext(clock *hlc.Clock, stopper *stop.Stopper) *rpc.Context {
 	ctx := rpc.NewContext(testutils.NewNodeTestBaseContext(), clock, stopper)
 	ctx.HeartbeatInterval = 10 * time.Millisecond
 	ctx.HeartbeatTimeout = 5 * time.Second
+	ctx.VerifyLocalCalls = true
 	return ctx
 }
 
@@ -196,7 +200,7 @@ func TestUnretryableError(t *testing.T) {
 		Trace:           sp,
 	}
 
-	sendOneFn = func(_ batchClient, _ time.Duration,
+	sendOneFn = func(_ context.Context, _ batchClient, _ time.Duration,
 		_ *rpc.Context, _ opentracing.Span, done chan batchCall) {
 		done <- batchCall{
 			reply: &roachpb.BatchResponse{},
@@ -218,6 +222,510 @@ func TestUnretryableError(t *testing.T) {
 	}
 }
 
+// failingNode is an InternalServer which fails every batch after receiving
+// it.
+type failingNode struct{}
+
+func (failingNode) Batch(context.Context, *roachpb.BatchRequest) (*roachpb.BatchResponse, error) {
+	return nil, errors.New("boom")
+}
+
+// TestSendOneAmbiguity verifies that sendOne reports the RPCs which failed
+// after the request was sent as ambiguous, and those which failed before it
+// was sent or which failed locally as not applied.
+func TestSendOneAmbiguity(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+
+	nodeContext := newNodeTestContext(nil, stopper)
+	s, ln := newTestServer(t, nodeContext)
+	roachpb.RegisterInternalServer(s, failingNode{})
+
+	sp := tracing.NewTracer().StartSpan("node test")
+	defer sp.Finish()
+
+	addr := ln.Addr().String()
+	conn, err := nodeContext.GRPCDial(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedConn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := closedConn.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		client       batchClient
+		expAmbiguous bool
+	}{
+		// The server received the request before failing.
+		{batchClient{remoteAddr: addr, conn: conn, client: roachpb.NewInternalClient(conn)}, true},
+		// The request was never sent.
+		{batchClient{remoteAddr: addr, conn: closedConn, client: roachpb.NewInternalClient(closedConn)}, false},
+		// Local calls fail before evaluating the batch.
+		{batchClient{remoteAddr: addr, local: failingNode{}}, false},
+	}
+	for i, tc := range testCases {
+		done := make(chan batchCall, 1)
+		sendOne(context.Background(), tc.client, 0, nodeContext, sp, done)
+		call := <-done
+		if call.err == nil {
+			t.Fatalf("%d: unexpected success", i)
+		}
+		if call.ambiguous != tc.expAmbiguous {
+			t.Errorf("%d: expected ambiguous=%t, got %t (%s)", i, tc.expAmbiguous, call.ambiguous, call.err)
+		}
+	}
+}
+
+// TestAmbiguousCommitError verifies that a batch containing an
+// EndTransaction request fails with an AmbiguousResultError if an RPC failed
+// after it may have been delivered, even if another replica replied with an
+// error afterwards, while other batches fail with a SendError.
+func TestAmbiguousCommitError(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+
+	nodeContext := newNodeTestContext(nil, stopper)
+	s, ln := newTestServer(t, nodeContext)
+	roachpb.RegisterInternalServer(s, failingNode{})
+
+	sp := tracing.NewTracer().StartSpan("node test")
+	defer sp.Finish()
+
+	opts := SendOptions{
+		Ordering:        orderStable,
+		SendNextTimeout: 1 * time.Second,
+		Timeout:         10 * time.Second,
+		Trace:           sp,
+	}
+
+	var put, commit roachpb.BatchRequest
+	put.Add(&roachpb.PutRequest{})
+	commit.Add(&roachpb.PutRequest{}, &roachpb.EndTransactionRequest{Commit: true})
+
+	checkErr := func(i int, err error, expAmbiguous bool) {
+		switch err.(type) {
+		case *roachpb.AmbiguousResultError:
+			if !expAmbiguous {
+				t.Errorf("%d: unexpected ambiguous result error: %s", i, err)
+			}
+		case *roachpb.SendError:
+			if expAmbiguous {
+				t.Errorf("%d: expected ambiguous result error, got %s", i, err)
+			}
+		default:
+			t.Errorf("%d: unexpected error %T: %v", i, err, err)
+		}
+	}
+
+	// The server fails the batches after receiving them.
+	for i, tc := range []struct {
+		ba           roachpb.BatchRequest
+		expAmbiguous bool
+	}{
+		{put, false},
+		{commit, true},
+	} {
+		_, err := send(opts, makeReplicas(ln.Addr()), tc.ba, nodeContext)
+		checkErr(i, err, tc.expAmbiguous)
+	}
+
+	// The first replica fails ambiguously or not, and the second replies
+	// with an error, which doesn't resolve the ambiguity.
+	var ambiguous bool
+	var calls int
+	sendOneFn = func(_ context.Context, _ batchClient, _ time.Duration,
+		_ *rpc.Context, _ opentracing.Span, done chan batchCall) {
+		calls++
+		if calls%2 == 1 {
+			done <- batchCall{err: errors.New("timeout"), ambiguous: ambiguous}
+			return
+		}
+		br := &roachpb.BatchResponse{}
+		br.Error = roachpb.NewError(&roachpb.NotLeaderError{})
+		done <- batchCall{reply: br}
+	}
+	defer func() { sendOneFn = sendOne }()
+
+	for i, tc := range []struct {
+		ba           roachpb.BatchRequest
+		ambiguous    bool
+		expAmbiguous bool
+	}{
+		{put, true, false},
+		{commit, false, false},
+		{commit, true, true},
+	} {
+		ambiguous = tc.ambiguous
+		br, err := send(opts, makeReplicas(ln.Addr(), ln.Addr()), tc.ba, nodeContext)
+		if !tc.expAmbiguous {
+			if err != nil || br.Error == nil {
+				t.Errorf("%d: expected the error reply, got %v, %v", i, br, err)
+			}
+			continue
+		}
+		checkErr(i, err, tc.expAmbiguous)
+	}
+}
+
+// TestNodeLimiter verifies that RPCs to a node queue behind others once
+// the limit of the node is reached, and fail once their context is done.
+func TestNodeLimiter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	queued := metric.NewCounter()
+	l := newNodeLimiter(1, queued)
+
+	release, err := l.acquire(context.Background(), 1, roachpb.FOREGROUND)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Other nodes are not affected.
+	releaseB, err := l.acquire(context.Background(), 2, roachpb.FOREGROUND)
+	if err != nil {
+		t.Fatal(err)
+	}
+	releaseB()
+	if q := queued.Count(); q != 0 {
+		t.Fatalf("expected no queued RPCs, got %d", q)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.acquire(ctx, 1, roachpb.FOREGROUND); err != context.DeadlineExceeded {
+		t.Fatalf("expected %s, got %v", context.DeadlineExceeded, err)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		release, err := l.acquire(context.Background(), 1, roachpb.FOREGROUND)
+		if err != nil {
+			t.Error(err)
+		} else {
+			release()
+		}
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("expected the RPC to queue")
+	case <-time.After(10 * time.Millisecond):
+	}
+	release()
+	<-acquired
+	if q := queued.Count(); q != 2 {
+		t.Errorf("expected 2 queued RPCs, got %d", q)
+	}
+	// Nodes without RPCs in flight are forgotten.
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if n := len(l.nodes); n != 0 {
+		t.Errorf("expected no nodes to be tracked, got %d", n)
+	}
+}
+
+// TestNodeLimiterAdmissionClass verifies that queued RPCs are sent in the
+// order of their admission class.
+func TestNodeLimiterAdmissionClass(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	queued := metric.NewCounter()
+	l := newNodeLimiter(1, queued)
+
+	release, err := l.acquire(context.Background(), 1, roachpb.FOREGROUND)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acquired := make(chan roachpb.AdmissionClass, admission.NumClasses)
+	for i, class := range []roachpb.AdmissionClass{roachpb.BACKGROUND, roachpb.FOREGROUND} {
+		go func(class roachpb.AdmissionClass) {
+			release, err := l.acquire(context.Background(), 1, class)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			acquired <- class
+			release()
+		}(class)
+		// Queue the RPCs one after the other.
+		util.SucceedsSoon(t, func() error {
+			if q := queued.Count(); q != int64(i+1) {
+				return util.Errorf("expected %d queued RPCs, got %d", i+1, q)
+			}
+			return nil
+		})
+	}
+	release()
+	for _, expected := range []roachpb.AdmissionClass{roachpb.FOREGROUND, roachpb.BACKGROUND} {
+		if class := <-acquired; class != expected {
+			t.Errorf("expected %s RPC to be sent next, got %s", expected, class)
+		}
+	}
+}
+
+// TestSendCancel verifies that Send stops waiting for replies and cancels the
+// RPCs in flight once the context of the request is done.
+func TestSendCancel(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+
+	nodeContext := newNodeTestContext(nil, stopper)
+	_, ln := newTestServer(t, nodeContext)
+
+	sp := tracing.NewTracer().StartSpan("node test")
+	defer sp.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	opts := SendOptions{
+		Ordering:        orderStable,
+		SendNextTimeout: 1 * time.Second,
+		Timeout:         10 * time.Second,
+		Context:         ctx,
+		Trace:           sp,
+	}
+
+	sent := make(chan struct{})
+	rpcCancelled := make(chan struct{})
+	sendOneFn = func(ctx context.Context, _ batchClient, _ time.Duration,
+		_ *rpc.Context, _ opentracing.Span, done chan batchCall) {
+		close(sent)
+		go func() {
+			<-ctx.Done()
+			close(rpcCancelled)
+			done <- batchCall{err: ctx.Err()}
+		}()
+	}
+	defer func() { sendOneFn = sendOne }()
+
+	go func() {
+		<-sent
+		cancel()
+	}()
+	if _, err := sendBatch(opts, []net.Addr{ln.Addr()}, nodeContext); err != context.Canceled {
+		t.Fatalf("expected %s, got %v", context.Canceled, err)
+	}
+	select {
+	case <-rpcCancelled:
+	case <-time.After(time.Second):
+		t.Fatal("RPC was not cancelled")
+	}
+}
+
+// TestSendErrorReplyDoesNotWin verifies that a reply carrying an error
+// doesn't cancel the RPCs still in flight, and is only returned if none of
+// them succeeds.
+func TestSendErrorReplyDoesNotWin(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+
+	nodeContext := newNodeTestContext(nil, stopper)
+	_, ln1 := newTestServer(t, nodeContext)
+	_, ln2 := newTestServer(t, nodeContext)
+
+	sp := tracing.NewTracer().StartSpan("node test")
+	defer sp.Finish()
+	opts := SendOptions{
+		Ordering:        orderStable,
+		SendNextTimeout: time.Millisecond,
+		Timeout:         10 * time.Second,
+		Hedge:           true,
+		Trace:           sp,
+	}
+	defer func() { sendOneFn = sendOne }()
+
+	for i, slowSucceeds := range []bool{true, false} {
+		slowAddr := ln1.Addr().String()
+		redirected := make(chan struct{})
+		sendOneFn = func(ctx context.Context, client batchClient, _ time.Duration,
+			_ *rpc.Context, _ opentracing.Span, done chan batchCall) {
+			if client.remoteAddr != slowAddr {
+				br := &roachpb.BatchResponse{}
+				br.Error = roachpb.NewError(&roachpb.NotLeaderError{})
+				done <- batchCall{reply: br}
+				close(redirected)
+				return
+			}
+			go func() {
+				// The slow replica replies only after the hedged RPC was
+				// redirected.
+				select {
+				case <-redirected:
+				case <-ctx.Done():
+				}
+				if slowSucceeds {
+					done <- batchCall{reply: &roachpb.BatchResponse{}}
+				} else {
+					done <- batchCall{err: errors.New("boom")}
+				}
+			}()
+		}
+
+		br, err := sendBatch(opts, []net.Addr{ln1.Addr(), ln2.Addr()}, nodeContext)
+		if err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+		if slowSucceeds && br.Error != nil {
+			t.Errorf("%d: expected the slow replica's reply, got %s", i, br.Error)
+		}
+		if _, ok := br.Error.GetDetail().(*roachpb.NotLeaderError); !slowSucceeds && !ok {
+			t.Errorf("%d: expected the error reply, got %v", i, br.Error)
+		}
+	}
+}
+
+// errorReplyNode is an InternalServer which replies to every batch with an
+// error.
+type errorReplyNode struct{}
+
+func (errorReplyNode) Batch(context.Context, *roachpb.BatchRequest) (*roachpb.BatchResponse, error) {
+	br := &roachpb.BatchResponse{}
+	br.Error = roachpb.NewError(&roachpb.NotLeaderError{})
+	return br, nil
+}
+
+// TestSendRPCLatencies verifies that the latencies of the RPCs served by a
+// replica are recorded, except for those of error replies.
+func TestSendRPCLatencies(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+
+	nodeContext := newNodeTestContext(nil, stopper)
+	s1, ln1 := newTestServer(t, nodeContext)
+	roachpb.RegisterInternalServer(s1, Node(0))
+	s2, ln2 := newTestServer(t, nodeContext)
+	roachpb.RegisterInternalServer(s2, errorReplyNode{})
+
+	sp := tracing.NewTracer().StartSpan("node test")
+	defer sp.Finish()
+	opts := SendOptions{
+		Ordering:        orderStable,
+		SendNextTimeout: time.Second,
+		Timeout:         10 * time.Second,
+		Trace:           sp,
+	}
+	for _, ln := range []net.Listener{ln1, ln2} {
+		for i := 0; i < 20; i++ {
+			if _, err := sendBatch(opts, []net.Addr{ln.Addr()}, nodeContext); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if _, ok := nodeContext.RPCLatencies.LatencyQuantile(ln1.Addr().String(), 0.99); !ok {
+		t.Errorf("expected the latencies of successful replies to be recorded")
+	}
+	if _, ok := nodeContext.RPCLatencies.LatencyQuantile(ln2.Addr().String(), 0.99); ok {
+		t.Errorf("expected the latencies of error replies not to be recorded")
+	}
+}
+
+// TestSendCancelsLosers verifies that the RPCs still in flight are
+// cancelled once another replica replied successfully.
+func TestSendCancelsLosers(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+
+	nodeContext := newNodeTestContext(nil, stopper)
+	_, ln1 := newTestServer(t, nodeContext)
+	_, ln2 := newTestServer(t, nodeContext)
+
+	sp := tracing.NewTracer().StartSpan("node test")
+	defer sp.Finish()
+	opts := SendOptions{
+		Ordering:        orderStable,
+		SendNextTimeout: time.Millisecond,
+		Timeout:         10 * time.Second,
+		Trace:           sp,
+	}
+
+	slowAddr := ln1.Addr().String()
+	rpcCancelled := make(chan struct{})
+	sendOneFn = func(ctx context.Context, client batchClient, _ time.Duration,
+		_ *rpc.Context, _ opentracing.Span, done chan batchCall) {
+		if client.remoteAddr != slowAddr {
+			done <- batchCall{reply: &roachpb.BatchResponse{}}
+			return
+		}
+		go func() {
+			<-ctx.Done()
+			close(rpcCancelled)
+			done <- batchCall{err: ctx.Err()}
+		}()
+	}
+	defer func() { sendOneFn = sendOne }()
+
+	if _, err := sendBatch(opts, []net.Addr{ln1.Addr(), ln2.Addr()}, nodeContext); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-rpcCancelled:
+	case <-time.After(time.Second):
+		t.Fatal("RPC to the slow replica was not cancelled")
+	}
+}
+
+// TestSendSkipsOpenBreakers verifies that replicas on nodes whose breakers
+// are open are skipped, unless the breakers of all replicas are open.
+func TestSendSkipsOpenBreakers(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+
+	nodeContext := newNodeTestContext(nil, stopper)
+	_, ln1 := newTestServer(t, nodeContext)
+	_, ln2 := newTestServer(t, nodeContext)
+
+	sp := tracing.NewTracer().StartSpan("node test")
+	defer sp.Finish()
+	opts := SendOptions{
+		Ordering:        orderStable,
+		SendNextTimeout: time.Second,
+		Timeout:         time.Second,
+		Trace:           sp,
+	}
+
+	var sentTo []string
+	sendOneFn = func(_ context.Context, client batchClient, _ time.Duration,
+		_ *rpc.Context, _ opentracing.Span, done chan batchCall) {
+		sentTo = append(sentTo, client.remoteAddr)
+		done <- batchCall{reply: &roachpb.BatchResponse{}}
+	}
+	defer func() { sendOneFn = sendOne }()
+
+	addrs := []net.Addr{ln1.Addr(), ln2.Addr()}
+	for i := 0; i < nodeContext.Breakers.Threshold; i++ {
+		nodeContext.Breakers.Failure(ln1.Addr().String())
+	}
+	if _, err := sendBatch(opts, addrs, nodeContext); err != nil {
+		t.Fatal(err)
+	}
+	if len(sentTo) != 1 || sentTo[0] != ln2.Addr().String() {
+		t.Fatalf("expected the RPC to be sent to %s only, got %v", ln2.Addr(), sentTo)
+	}
+
+	sentTo = nil
+	for i := 0; i < nodeContext.Breakers.Threshold; i++ {
+		nodeContext.Breakers.Failure(ln2.Addr().String())
+	}
+	if _, err := sendBatch(opts, addrs, nodeContext); err != nil {
+		t.Fatal(err)
+	}
+	if len(sentTo) != 1 || sentTo[0] != ln1.Addr().String() {
+		t.Fatalf("expected the RPC to be sent to %s with all breakers open, got %v", ln1.Addr(), sentTo)
+	}
+}
+
 // TestClientNotReady verifies that Send gets an RPC error when a client
 // does not become ready.
 func TestClientNotReady(t *testing.T) {
@@ -343,7 +851,7 @@ func TestComplexScenarios(t *testing.T) {
 		}
 
 		// Mock sendOne.
-		sendOneFn = func(client batchClient, _ time.Duration,
+		sendOneFn = func(_ context.Context, client batchClient, _ time.Duration,
 			_ *rpc.Context, _ opentracing.Span, done chan batchCall) {
 			addrID := -1
 			for serverAddrID, serverAddr := range serverAddrs {
@@ -393,7 +901,312 @@ func makeReplicas(addrs ...net.Addr) ReplicaSlice {
 	return replicas
 }
 
+// TestSendNodeLimiterHedge verifies that an RPC queued behind the RPCs to a
+// saturated node doesn't keep the batch from being sent to other replicas.
+func TestSendNodeLimiterHedge(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+
+	nodeContext := newNodeTestContext(nil, stopper)
+	var addrs []net.Addr
+	for i := 0; i < 2; i++ {
+		s, ln := newTestServer(t, nodeContext)
+		roachpb.RegisterInternalServer(s, Node(0))
+		addrs = append(addrs, ln.Addr())
+	}
+	replicas := makeReplicas(addrs...)
+	for i := range replicas {
+		replicas[i].NodeID = roachpb.NodeID(i + 1)
+	}
+
+	// Take the only slot of the first node.
+	l := newNodeLimiter(1, metric.NewCounter())
+	release, err := l.acquire(context.Background(), 1, roachpb.FOREGROUND)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	sp := tracing.NewTracer().StartSpan("node test")
+	defer sp.Finish()
+	opts := SendOptions{
+		Ordering:        orderStable,
+		SendNextTimeout: minSendNextTimeout,
+		Timeout:         5 * time.Second,
+		Trace:           sp,
+		limiter:         l,
+	}
+	start := time.Now()
+	if _, err := send(opts, replicas, roachpb.BatchRequest{}, nodeContext); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed >= opts.Timeout {
+		t.Errorf("expected the second replica to answer before the first node's RPC timed out, took %s", elapsed)
+	}
+}
+
 // sendBatch sends Batch requests to specified addresses using send.
 func sendBatch(opts SendOptions, addrs []net.Addr, rpcContext *rpc.Context) (*roachpb.BatchResponse, error) {
 	return send(opts, makeReplicas(addrs...), roachpb.BatchRequest{}, rpcContext)
 }
+
+// TestSendNextTimeout verifies that the delay after which RPCs are sent to
+// the next replica is derived from the latency to the replica, and that the
+// SendNextTimeout of the options is used when the latency is unknown.
+func TestSendNextTimeout(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+	nodeContext := newNodeTestContext(nil, stopper)
+	opts := SendOptions{SendNextTimeout: 10 * time.Second}
+
+	if timeout := sendNextTimeout(opts, nodeContext, "unknown:1"); timeout != opts.SendNextTimeout {
+		t.Errorf("expected %s for an unknown address, got %s", opts.SendNextTimeout, timeout)
+	}
+
+	testCases := []struct {
+		latency  time.Duration
+		expected time.Duration
+	}{
+		{time.Millisecond, minSendNextTimeout},
+		{100 * time.Millisecond, time.Second},
+		{5 * time.Second, opts.SendNextTimeout},
+	}
+	for i, c := range testCases {
+		addr := fmt.Sprintf("test:%d", i)
+		for j := 0; j < 10; j++ {
+			nodeContext.RemoteLatencies.RecordLatency(addr, c.latency)
+		}
+		if timeout := sendNextTimeout(opts, nodeContext, addr); timeout != c.expected {
+			t.Errorf("%d: expected %s, got %s", i, c.expected, timeout)
+		}
+	}
+
+	// The latencies of the RPCs served by a replica take precedence over
+	// those of its heartbeats for batches which may be hedged.
+	hedgeOpts := opts
+	hedgeOpts.Hedge = true
+	rpcTestCases := []struct {
+		latency  time.Duration
+		expected time.Duration
+	}{
+		{time.Millisecond, minHedgeDelay},
+		{100 * time.Millisecond, 100 * time.Millisecond},
+		{time.Minute, opts.SendNextTimeout},
+	}
+	for i, c := range rpcTestCases {
+		addr := fmt.Sprintf("test:%d", i)
+		for j := 0; j < 10; j++ {
+			nodeContext.RPCLatencies.RecordLatency(addr, c.latency)
+		}
+		if timeout := sendNextTimeout(hedgeOpts, nodeContext, addr); timeout != c.expected {
+			t.Errorf("%d: expected %s, got %s", i, c.expected, timeout)
+		}
+		if timeout := sendNextTimeout(opts, nodeContext, addr); timeout != testCases[i].expected {
+			t.Errorf("%d: expected %s without hedging, got %s", i, testCases[i].expected, timeout)
+		}
+	}
+}
+
+// TestSortClientsByLatency verifies that clients are ordered by the latency
+// measured to their addresses, with local clients first and those of unknown
+// latency last in the order provided.
+func TestSortClientsByLatency(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+	nodeContext := newNodeTestContext(nil, stopper)
+
+	latencies := map[string]time.Duration{
+		"far:1":  100 * time.Millisecond,
+		"near:1": time.Millisecond,
+		"mid:1":  10 * time.Millisecond,
+	}
+	for addr, latency := range latencies {
+		for i := 0; i < 10; i++ {
+			nodeContext.RemoteLatencies.RecordLatency(addr, latency)
+		}
+	}
+
+	clients := []batchClient{
+		{remoteAddr: "unknown:1"},
+		{remoteAddr: "far:1"},
+		{remoteAddr: "unknown:2"},
+		{remoteAddr: "near:1"},
+		{remoteAddr: "local:1", local: Node(0)},
+		{remoteAddr: "mid:1"},
+	}
+	sortClientsByLatency(clients, nodeContext)
+
+	expected := []string{"local:1", "near:1", "mid:1", "far:1", "unknown:1", "unknown:2"}
+	for i, client := range clients {
+		if client.remoteAddr != expected[i] {
+			t.Errorf("%d: expected %s, got %s", i, expected[i], client.remoteAddr)
+		}
+	}
+}
+
+// TestSortClientsByLatencySimilar verifies that clients of similar latency
+// are tried first in random order, ahead of the slower clients.
+func TestSortClientsByLatencySimilar(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+	nodeContext := newNodeTestContext(nil, stopper)
+
+	latencies := map[string]time.Duration{
+		"near:1": 10 * time.Millisecond,
+		"near:2": 11 * time.Millisecond,
+		"far:1":  100 * time.Millisecond,
+	}
+	for addr, latency := range latencies {
+		for i := 0; i < 10; i++ {
+			nodeContext.RemoteLatencies.RecordLatency(addr, latency)
+		}
+	}
+
+	first := map[string]int{}
+	for i := 0; i < 100; i++ {
+		clients := []batchClient{
+			{remoteAddr: "far:1"},
+			{remoteAddr: "near:1"},
+			{remoteAddr: "near:2"},
+		}
+		sortClientsByLatency(clients, nodeContext)
+		if addr := clients[2].remoteAddr; addr != "far:1" {
+			t.Fatalf("expected far:1 to be tried last, got %s", addr)
+		}
+		first[clients[0].remoteAddr]++
+	}
+	if len(first) != 2 {
+		t.Errorf("expected both near clients to be tried first, got %v", first)
+	}
+}
+
+// mutatingNode is a local server which breaks the ownership rules of local
+// calls by modifying the request.
+type mutatingNode struct{}
+
+func (mutatingNode) Batch(ctx context.Context, args *roachpb.BatchRequest) (*roachpb.BatchResponse, error) {
+	args.Replica.NodeID++
+	return &roachpb.BatchResponse{}, nil
+}
+
+// TestSendLocal verifies that requests to the local address are dispatched
+// directly to the local server without a connection, and that servers
+// modifying the requests of local calls are caught when verification is
+// enabled.
+func TestSendLocal(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+
+	// Nothing listens on the local address, so any RPC to it would fail.
+	addr := util.NewUnresolvedAddr("tcp", "127.0.0.1:1")
+	ctx := newNodeTestContext(nil, stopper)
+	ctx.SetLocalInternalServer(Node(0), addr.String())
+
+	sp := tracing.NewTracer().StartSpan("node test")
+	defer sp.Finish()
+	opts := SendOptions{
+		Ordering:        orderStable,
+		SendNextTimeout: time.Second,
+		Timeout:         time.Second,
+		Trace:           sp,
+	}
+	if _, err := sendBatch(opts, []net.Addr{addr}, ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx.SetLocalInternalServer(mutatingNode{}, addr.String())
+	if _, err := sendBatch(opts, []net.Addr{addr}, ctx); !testutils.IsError(err, errModifiedLocalCall.Error()) {
+		t.Errorf("expected an error for a modified request, got %v", err)
+	}
+}
+
+// TestSendLocalByNodeID verifies that requests to the replicas of the
+// local node are dispatched to the local server even if they are addressed
+// to another address, and that the local and remote RPCs are counted.
+func TestSendLocalByNodeID(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+
+	ctx := newNodeTestContext(nil, stopper)
+	ctx.SetLocalInternalServer(Node(0), "127.0.0.1:1")
+	ctx.SetLocalNodeID(1)
+
+	sp := tracing.NewTracer().StartSpan("node test")
+	defer sp.Finish()
+	opts := SendOptions{
+		Ordering:        orderStable,
+		SendNextTimeout: time.Second,
+		Timeout:         time.Second,
+		Trace:           sp,
+		localCalls:      metric.NewCounter(),
+		remoteCalls:     metric.NewCounter(),
+	}
+	// Nothing listens on the advertised address, so any RPC to it would
+	// fail.
+	replicas := makeReplicas(util.NewUnresolvedAddr("tcp", "127.0.0.1:2"))
+	replicas[0].NodeID = 1
+	if _, err := send(opts, replicas, roachpb.BatchRequest{}, ctx); err != nil {
+		t.Fatal(err)
+	}
+	if local, remote := opts.localCalls.Count(), opts.remoteCalls.Count(); local != 1 || remote != 0 {
+		t.Errorf("expected 1 local and 0 remote RPCs, got %d and %d", local, remote)
+	}
+}
+
+func benchmarkSend(b *testing.B, local bool) {
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+
+	ctx := newNodeTestContext(nil, stopper)
+	s := rpc.NewServer(ctx)
+	ln, err := util.ListenAndServeGRPC(stopper, s, util.TestAddr)
+	if err != nil {
+		b.Fatal(err)
+	}
+	roachpb.RegisterInternalServer(s, Node(0))
+	if local {
+		ctx.SetLocalInternalServer(Node(0), ln.Addr().String())
+	}
+
+	sp := tracing.NewTracer().StartSpan("node benchmark")
+	defer sp.Finish()
+	opts := SendOptions{
+		Ordering:        orderStable,
+		SendNextTimeout: time.Second,
+		Timeout:         10 * time.Second,
+		Trace:           sp,
+	}
+	var ba roachpb.BatchRequest
+	for i := 0; i < 10; i++ {
+		ba.Add(&roachpb.PutRequest{
+			Span:  roachpb.Span{Key: roachpb.Key(fmt.Sprintf("key%d", i))},
+			Value: roachpb.MakeValueFromString("value"),
+		})
+	}
+	replicas := makeReplicas(ln.Addr())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := send(opts, replicas, ba, ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkSendLocal measures sending batches to the local server, which
+// bypasses gRPC.
+func BenchmarkSendLocal(b *testing.B) {
+	benchmarkSend(b, true)
+}
+
+// BenchmarkSendRemote measures sending batches to a server through gRPC, as
+// for a remote node.
+func BenchmarkSendRemote(b *testing.B) {
+	benchmarkSend(b, false)
+}
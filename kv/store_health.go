@@ -0,0 +1,126 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package kv
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/gossip"
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/util/hlc"
+	"github.com/cockroachdb/cockroach/util/log"
+)
+
+// A storeHealth tracks the stores which are suspected to be unavailable
+// according to gossip: those which gossiped that they are draining, and
+// those whose descriptors haven't been gossiped for timeUntilDead. The
+// DistSender tries the replicas on suspect stores last, so that requests
+// don't wait on a store going away while the other replicas are healthy.
+type storeHealth struct {
+	clock *hlc.Clock
+	// timeUntilDead is the time after which a store whose descriptor wasn't
+	// gossiped is suspect. Zero disables it.
+	timeUntilDead time.Duration
+
+	mu struct {
+		sync.Mutex
+		// lastGossiped is the time at which the descriptor of each store
+		// was last gossiped.
+		lastGossiped map[roachpb.StoreID]time.Time
+		// draining holds the stores which gossiped that they are draining.
+		draining map[roachpb.StoreID]struct{}
+	}
+}
+
+func newStoreHealth(clock *hlc.Clock, timeUntilDead time.Duration) *storeHealth {
+	h := &storeHealth{
+		clock:         clock,
+		timeUntilDead: timeUntilDead,
+	}
+	h.mu.lastGossiped = map[roachpb.StoreID]time.Time{}
+	h.mu.draining = map[roachpb.StoreID]struct{}{}
+	return h
+}
+
+// storeGossipUpdate is the gossip callback recording when the descriptors
+// of the stores are gossiped.
+func (h *storeHealth) storeGossipUpdate(_ string, content roachpb.Value) {
+	var desc roachpb.StoreDescriptor
+	if err := content.GetProto(&desc); err != nil {
+		log.Error(err)
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.mu.lastGossiped[desc.StoreID] = h.clock.PhysicalTime()
+}
+
+// drainingGossipUpdate is the gossip callback recording whether the stores
+// are draining.
+func (h *storeHealth) drainingGossipUpdate(key string, content roachpb.Value) {
+	id := strings.TrimPrefix(key, gossip.MakeKey(gossip.KeyStoreDrainingPrefix, ""))
+	storeID, err := strconv.ParseInt(id, 10, 32)
+	if err != nil {
+		log.Errorf("invalid store draining gossip key %q: %s", key, err)
+		return
+	}
+	b, err := content.GetBytes()
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	draining := len(b) == 1 && b[0] == 1
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if draining {
+		h.mu.draining[roachpb.StoreID(storeID)] = struct{}{}
+	} else {
+		delete(h.mu.draining, roachpb.StoreID(storeID))
+	}
+}
+
+// suspectLocked returns whether the given store is draining or has stopped
+// gossiping its descriptor. Stores whose descriptors are yet to be gossiped
+// aren't suspect. Caller must hold mutex.
+func (h *storeHealth) suspectLocked(storeID roachpb.StoreID, now time.Time) bool {
+	if _, ok := h.mu.draining[storeID]; ok {
+		return true
+	}
+	last, ok := h.mu.lastGossiped[storeID]
+	return ok && h.timeUntilDead > 0 && now.Sub(last) > h.timeUntilDead
+}
+
+// demoteSuspects moves the replicas on suspect stores to the back of the
+// slice, keeping the order of the replicas otherwise stable, and returns
+// their number.
+func (h *storeHealth) demoteSuspects(replicas ReplicaSlice) int {
+	now := h.clock.PhysicalTime()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var suspects ReplicaSlice
+	healthy := replicas[:0]
+	for _, r := range replicas {
+		if h.suspectLocked(r.StoreID, now) {
+			suspects = append(suspects, r)
+		} else {
+			healthy = append(healthy, r)
+		}
+	}
+	copy(replicas[len(healthy):], suspects)
+	return len(suspects)
+}
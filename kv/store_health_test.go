@@ -0,0 +1,95 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package kv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/gossip"
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/util/hlc"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+// TestDemoteSuspectReplicas verifies that the replicas on stores which
+// gossiped that they are draining or stopped gossiping their descriptors
+// are tried last.
+func TestDemoteSuspectReplicas(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	manual := hlc.NewManualClock(0)
+	clock := hlc.NewClock(manual.UnixNano)
+	localNodeDesc := roachpb.NodeDescriptor{NodeID: 1}
+	ds := NewDistSender(&DistSenderContext{
+		Clock:              clock,
+		nodeDescriptor:     &localNodeDesc,
+		TimeUntilStoreDead: time.Minute,
+	}, nil)
+
+	gossipStore := func(storeID roachpb.StoreID) {
+		var v roachpb.Value
+		if err := v.SetProto(&roachpb.StoreDescriptor{StoreID: storeID}); err != nil {
+			t.Fatal(err)
+		}
+		ds.storeHealth.storeGossipUpdate(gossip.MakeStoreKey(storeID), v)
+	}
+	gossipDraining := func(storeID roachpb.StoreID, draining bool) {
+		val := []byte{0}
+		if draining {
+			val[0] = 1
+		}
+		ds.storeHealth.drainingGossipUpdate(gossip.MakeStoreDrainingKey(storeID), roachpb.MakeValueFromBytes(val))
+	}
+	order := func() []roachpb.StoreID {
+		replicas := ReplicaSlice{}
+		for i := 1; i <= 3; i++ {
+			replicas = append(replicas, ReplicaInfo{
+				ReplicaDescriptor: roachpb.ReplicaDescriptor{NodeID: roachpb.NodeID(i), StoreID: roachpb.StoreID(i)},
+				NodeDesc:          &roachpb.NodeDescriptor{NodeID: roachpb.NodeID(i)},
+			})
+		}
+		ds.optimizeReplicaOrder(replicas)
+		var storeIDs []roachpb.StoreID
+		for _, r := range replicas {
+			storeIDs = append(storeIDs, r.StoreID)
+		}
+		return storeIDs
+	}
+
+	for i := 1; i <= 3; i++ {
+		gossipStore(roachpb.StoreID(i))
+	}
+	if o := order(); o[0] != 1 {
+		t.Errorf("expected the local replica first, got %v", o)
+	}
+
+	// The local replica is tried last while its store is draining.
+	gossipDraining(1, true)
+	if o := order(); o[2] != 1 {
+		t.Errorf("expected the replica on the draining store last, got %v", o)
+	}
+	gossipDraining(1, false)
+	if o := order(); o[0] != 1 {
+		t.Errorf("expected the local replica first once it stopped draining, got %v", o)
+	}
+
+	// Stores whose descriptors aren't gossiped anymore are suspect.
+	manual.Increment(int64(2 * time.Minute))
+	gossipStore(1)
+	gossipStore(3)
+	if o := order(); o[0] != 1 || o[2] != 2 {
+		t.Errorf("expected the replica on the dead store last, got %v", o)
+	}
+}
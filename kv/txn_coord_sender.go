@@ -97,6 +97,12 @@ type txnMetadata struct {
 // taking care not to add this range if existing entries already
 // completely cover the range.
 func addKeyRange(keys interval.RangeGroup, start, end roachpb.Key) {
+	keys.Add(makeKeyRange(start, end))
+}
+
+// makeKeyRange returns the interval covering the key range [start, end), or
+// the single key start if end is empty.
+func makeKeyRange(start, end roachpb.Key) interval.Range {
 	// This gives us a memory-efficient end key if end is empty.
 	// The most common case for keys in the intents interval map
 	// is for single keys. However, the range group requires
@@ -106,11 +112,54 @@ func addKeyRange(keys interval.RangeGroup, start, end roachpb.Key) {
 		end = start.Next()
 		start = end[:len(start)]
 	}
-	keyR := interval.Range{
+	return interval.Range{
 		Start: interval.Comparable(start),
 		End:   interval.Comparable(end),
 	}
-	keys.Add(keyR)
+}
+
+// A pipelinedWrite is a batch of writes of a transaction which the
+// coordinator acknowledged to the client before the wrapped sender
+// returned. The writes are proven, that is waited for, before any later
+// request of the transaction which touches their keys, and before the
+// transaction ends. Writes of an earlier epoch of the transaction are
+// dropped once it restarts, and their outcome is ignored.
+type pipelinedWrite struct {
+	// keys are the keys written by the batch.
+	keys interval.RangeGroup
+	// epoch is the epoch of the transaction which sent the batch.
+	epoch uint32
+	// done is closed once the batch has returned and txn and pErr are set.
+	done chan struct{}
+	// txn is the transaction returned with the batch on success.
+	txn *roachpb.Transaction
+	// pErr is the error returned by the batch, if any.
+	pErr *roachpb.Error
+}
+
+// finished returns whether the batch has returned.
+func (w *pipelinedWrite) finished() bool {
+	select {
+	case <-w.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// overlaps returns whether any request of the given batch touches the keys
+// written by the pipelined batch.
+func (w *pipelinedWrite) overlaps(ba roachpb.BatchRequest) bool {
+	for _, union := range ba.Requests {
+		h := union.GetInner().Header()
+		if len(h.Key) == 0 {
+			continue
+		}
+		if w.keys.Overlaps(makeKeyRange(h.Key, h.EndKey)) {
+			return true
+		}
+	}
+	return false
 }
 
 // setLastUpdate updates the wall time (in nanoseconds) since the most
@@ -158,7 +207,11 @@ type TxnMetrics struct {
 	Aborts    metric.Rates
 	Commits   metric.Rates
 	Abandons  metric.Rates
-	Durations metric.Histograms
+	Durations metric.Latency
+
+	// Pipelined is the number of write batches acknowledged before they
+	// returned.
+	Pipelined *metric.Counter
 
 	// Restarts is the number of times we had to restart the transaction.
 	Restarts *metric.Histogram
@@ -169,6 +222,7 @@ const (
 	commitsPrefix   = "commits"
 	abandonsPrefix  = "abandons"
 	durationsPrefix = "durations"
+	pipelinedKey    = "pipelined"
 	restartsKey     = "restarts"
 )
 
@@ -180,6 +234,7 @@ func NewTxnMetrics(txnRegistry *metric.Registry) *TxnMetrics {
 		Commits:   txnRegistry.Rates(commitsPrefix),
 		Abandons:  txnRegistry.Rates(abandonsPrefix),
 		Durations: txnRegistry.Latency(durationsPrefix),
+		Pipelined: txnRegistry.Counter(pipelinedKey),
 		Restarts:  txnRegistry.Histogram(restartsKey, 60*time.Second, 100, 3),
 	}
 }
@@ -204,6 +259,12 @@ type TxnCoordSender struct {
 	tracer            opentracing.Tracer
 	stopper           *stop.Stopper
 	metrics           *TxnMetrics
+
+	// pipelineWrites enables the pipelining of writes; see SetPipelineWrites.
+	pipelineWrites bool
+	// pipelined holds the pipelined writes of each transaction which have
+	// not been proven yet. Protected by the mutex.
+	pipelined map[uuid.UUID][]*pipelinedWrite
 }
 
 var _ client.Sender = &TxnCoordSender{}
@@ -224,12 +285,24 @@ func NewTxnCoordSender(wrapped client.Sender, clock *hlc.Clock, linearizable boo
 		tracer:            tracer,
 		stopper:           stopper,
 		metrics:           txnMetrics,
+		pipelined:         map[uuid.UUID][]*pipelinedWrite{},
 	}
 
 	tc.stopper.RunWorker(tc.startStats)
 	return tc
 }
 
+// SetPipelineWrites enables or disables the pipelining of writes. When
+// enabled, batches of blind writes (puts and deletes) of transactions which
+// have already written are acknowledged as soon as they are sent, saving
+// the client the latency of their replication. Their outcome is proven
+// before any later request of the transaction which touches the written
+// keys, and before the transaction ends; errors are returned from the
+// request which proves them. Must be called before the coordinator is used.
+func (tc *TxnCoordSender) SetPipelineWrites(enabled bool) {
+	tc.pipelineWrites = enabled
+}
+
 // startStats blocks and periodically logs transaction statistics (throughput,
 // success rates, durations, ...). Note that this only captures write txns,
 // since read-only txns are stateless as far as TxnCoordSender is concerned.
@@ -300,6 +373,22 @@ func (tc *TxnCoordSender) startStats() {
 // write intents; they're tagged to an outgoing EndTransaction request, with
 // the receiving replica in charge of resolving them.
 func (tc *TxnCoordSender) Send(ctx context.Context, ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+	return tc.send(ctx, ba, nil)
+}
+
+// SendStream implements the client.StreamingSender interface. The responses
+// are passed on to f as the wrapped sender streams them, and the state of the
+// transaction is updated once the stream ended.
+func (tc *TxnCoordSender) SendStream(ctx context.Context, ba roachpb.BatchRequest,
+	f func(*roachpb.BatchResponse) (bool, error)) *roachpb.Error {
+	_, pErr := tc.send(ctx, ba, f)
+	return pErr
+}
+
+// send implements Send and SendStream; the responses are streamed to f if
+// it is set.
+func (tc *TxnCoordSender) send(ctx context.Context, ba roachpb.BatchRequest,
+	f func(*roachpb.BatchResponse) (bool, error)) (*roachpb.BatchResponse, *roachpb.Error) {
 	// Start new or pick up active trace and embed its trace metadata into
 	// header for use by RPC recipients. From here on, there's always an active
 	// Trace, though its overhead is small unless it's sampled.
@@ -331,6 +420,11 @@ func (tc *TxnCoordSender) Send(ctx context.Context, ba roachpb.BatchRequest) (*r
 	if ba.Txn != nil {
 		// If this request is part of a transaction...
 		txnID := *ba.Txn.ID
+		// Prove the pipelined writes this request depends on before it
+		// observes their keys or ends the transaction.
+		if pErr := tc.provePipelinedWrites(&ba); pErr != nil {
+			return nil, pErr
+		}
 		// Verify that if this Transaction is not read-only, we have it on
 		// file. If not, refuse writes - the client must have issued a write on
 		// another coordinator previously.
@@ -403,20 +497,44 @@ func (tc *TxnCoordSender) Send(ctx context.Context, ba roachpb.BatchRequest) (*r
 		}
 	}
 
+	if f == nil && tc.canPipeline(ba) {
+		if br, ok := tc.pipeline(ba); ok {
+			sp.LogEvent("pipelined writes")
+			return br, nil
+		}
+	}
+
 	// Send the command through wrapped sender, taking appropriate measures
 	// on error.
 	var br *roachpb.BatchResponse
 	{
 		var pErr *roachpb.Error
-		br, pErr = tc.wrapped.Send(ctx, ba)
+		if f != nil {
+			br, pErr = tc.sendStream(ctx, ba, f)
+		} else {
+			br, pErr = tc.wrapped.Send(ctx, ba)
+		}
 
 		if _, ok := pErr.GetDetail().(*roachpb.OpRequiresTxnError); ok {
 			// TODO(tschottdorf): needs to keep the trace.
 			br, pErr = tc.resendWithTxn(ba)
+			if pErr == nil && f != nil {
+				// Nothing was streamed before the error.
+				if _, err := f(br); err != nil {
+					br, pErr = nil, roachpb.NewError(err)
+				}
+			}
 		}
 
 		if pErr = tc.updateState(ctx, ba, br, pErr); pErr != nil {
 			sp.LogEvent(fmt.Sprintf("error: %s", pErr))
+			if restartTxn := pErr.GetTxn(); ba.Txn != nil && restartTxn != nil && restartTxn.Epoch > ba.Txn.Epoch {
+				// The pipelined writes of the earlier epochs are dropped, so
+				// that their errors don't surface in the new epoch.
+				tc.Lock()
+				tc.dropStalePipelinedWritesLocked(*ba.Txn.ID, restartTxn.Epoch)
+				tc.Unlock()
+			}
 			return nil, pErr
 		}
 	}
@@ -455,6 +573,160 @@ func (tc *TxnCoordSender) Send(ctx context.Context, ba roachpb.BatchRequest) (*r
 	return br, nil
 }
 
+// canPipeline returns whether the batch can be acknowledged before it
+// returns: pipelining must be enabled, and the batch must consist of blind
+// writes of a transaction which has already written, and thus is tracked
+// by the coordinator.
+func (tc *TxnCoordSender) canPipeline(ba roachpb.BatchRequest) bool {
+	if !tc.pipelineWrites || ba.Txn == nil || !ba.Txn.Writing || len(ba.Requests) == 0 {
+		return false
+	}
+	for _, union := range ba.Requests {
+		switch union.GetInner().(type) {
+		case *roachpb.PutRequest, *roachpb.DeleteRequest:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// pipeline sends the batch through the wrapped sender asynchronously and
+// returns a response acknowledging its writes, unless the system is
+// draining, in which case it returns false and the batch must be sent
+// synchronously.
+func (tc *TxnCoordSender) pipeline(ba roachpb.BatchRequest) (*roachpb.BatchResponse, bool) {
+	w := &pipelinedWrite{
+		keys:  interval.NewRangeTree(),
+		epoch: ba.Txn.Epoch,
+		done:  make(chan struct{}),
+	}
+	ba.IntentSpanIterate(func(key, endKey roachpb.Key) {
+		addKeyRange(w.keys, key, endKey)
+	})
+	// The client updates its transaction while the batch is in flight.
+	txn := ba.Txn.Clone()
+	ba.Txn = &txn
+	if !tc.stopper.RunAsyncTask(func() {
+		defer close(w.done)
+		// The writes outlive the client's request, and thus its context.
+		ctx := context.Background()
+		br, pErr := tc.wrapped.Send(ctx, ba)
+		if tc.isStalePipelinedWrite(w, ba) {
+			return
+		}
+		if w.pErr = tc.updateState(ctx, ba, br, pErr); w.pErr == nil {
+			w.txn = br.Txn
+		}
+	}) {
+		return nil, false
+	}
+	tc.Lock()
+	tc.pipelined[*ba.Txn.ID] = append(tc.pipelined[*ba.Txn.ID], w)
+	tc.Unlock()
+	tc.metrics.Pipelined.Inc(1)
+
+	br := ba.CreateReply()
+	brTxn := txn.Clone()
+	br.Txn = &brTxn
+	return br, true
+}
+
+// isStalePipelinedWrite returns whether the transaction restarted or ended
+// since it sent the pipelined batch, in which case the outcome of the batch
+// must not be applied to the transaction. The intents the batch may have
+// written are still tracked so that they are resolved.
+func (tc *TxnCoordSender) isStalePipelinedWrite(w *pipelinedWrite, ba roachpb.BatchRequest) bool {
+	tc.Lock()
+	defer tc.Unlock()
+	txnMeta, ok := tc.txns[*ba.Txn.ID]
+	if ok && txnMeta.txn.Epoch <= w.epoch {
+		return false
+	}
+	if ok {
+		ba.IntentSpanIterate(func(key, endKey roachpb.Key) {
+			addKeyRange(txnMeta.keys, key, endKey)
+		})
+	}
+	return true
+}
+
+// provePipelinedWrites waits for the pipelined writes of the batch's
+// transaction which the batch depends on: those which touch the keys of
+// the batch, or all of them if the batch ends the transaction. Writes which
+// already returned are proven along the way. The transaction of the batch
+// is updated with the outcome of the writes, and the first error they
+// returned is returned, in which case all the pipelined writes of the
+// transaction are waited for.
+func (tc *TxnCoordSender) provePipelinedWrites(ba *roachpb.BatchRequest) *roachpb.Error {
+	txnID := *ba.Txn.ID
+	_, isEnding := ba.GetArg(roachpb.EndTransaction)
+	tc.Lock()
+	var proving, pending []*pipelinedWrite
+	for _, w := range tc.pipelined[txnID] {
+		if w.epoch < ba.Txn.Epoch {
+			// Left behind by an earlier epoch; nothing depends on it.
+			continue
+		}
+		if isEnding || w.finished() || w.overlaps(*ba) {
+			proving = append(proving, w)
+		} else {
+			pending = append(pending, w)
+		}
+	}
+	if len(pending) == 0 {
+		delete(tc.pipelined, txnID)
+	} else {
+		tc.pipelined[txnID] = pending
+	}
+	tc.Unlock()
+	if len(proving) == 0 {
+		return nil
+	}
+
+	txn := ba.Txn.Clone()
+	var pErr *roachpb.Error
+	for _, w := range proving {
+		<-w.done
+		if w.pErr != nil {
+			if pErr == nil {
+				pErr = w.pErr
+			}
+			continue
+		}
+		txn.Update(w.txn)
+	}
+	if pErr != nil {
+		// The transaction is going to restart or abort; don't leave writes
+		// of its current incarnation behind.
+		tc.Lock()
+		pending = tc.pipelined[txnID]
+		delete(tc.pipelined, txnID)
+		tc.Unlock()
+		for _, w := range pending {
+			<-w.done
+		}
+		return pErr
+	}
+	ba.Txn = &txn
+	return nil
+}
+
+// sendStream streams the batch through the wrapped sender to f. The returned
+// response carries only the header of the last response streamed, from which
+// the state of the transaction is updated.
+func (tc *TxnCoordSender) sendStream(ctx context.Context, ba roachpb.BatchRequest,
+	f func(*roachpb.BatchResponse) (bool, error)) (*roachpb.BatchResponse, *roachpb.Error) {
+	br := &roachpb.BatchResponse{}
+	if pErr := client.SendStream(tc.wrapped, ctx, ba, func(chunk *roachpb.BatchResponse) (bool, error) {
+		br.BatchResponse_Header = chunk.BatchResponse_Header
+		return f(chunk)
+	}); pErr != nil {
+		return nil, pErr
+	}
+	return br, nil
+}
+
 // maybeBeginTxn begins a new transaction if a txn has been specified
 // in the request but has a nil ID. The new transaction is initialized
 // using the name and isolation in the otherwise uninitialized txn.
@@ -524,6 +796,23 @@ func (tc *TxnCoordSender) cleanupTxn(trace opentracing.Span, txn roachpb.Transac
 	txnMeta.txnEnd = nil
 }
 
+// dropStalePipelinedWritesLocked forgets the pipelined writes of the
+// transaction sent by epochs before the given one. It assumes the lock is
+// held.
+func (tc *TxnCoordSender) dropStalePipelinedWritesLocked(txnID uuid.UUID, epoch uint32) {
+	var pending []*pipelinedWrite
+	for _, w := range tc.pipelined[txnID] {
+		if w.epoch >= epoch {
+			pending = append(pending, w)
+		}
+	}
+	if len(pending) == 0 {
+		delete(tc.pipelined, txnID)
+	} else {
+		tc.pipelined[txnID] = pending
+	}
+}
+
 // unregisterTxn deletes a txnMetadata object from the sender
 // and collects its stats. It assumes the lock is held. Returns
 // the status and starts for the removed transaction.
@@ -539,6 +828,7 @@ func (tc *TxnCoordSender) unregisterTxnLocked(txnID uuid.UUID) (duration, restar
 	txnMeta.keys.Clear()
 
 	delete(tc.txns, txnID)
+	delete(tc.pipelined, txnID)
 
 	return
 }
@@ -859,6 +1149,7 @@ func (tc *TxnCoordSender) resendWithTxn(ba roachpb.BatchRequest) (*roachpb.Batch
 		txn.SetDebugName("auto-wrap", 0)
 		b := txn.NewBatch()
 		b.MaxScanResults = ba.MaxScanResults
+		b.TargetBytes = ba.TargetBytes
 		for _, arg := range ba.Requests {
 			req := arg.GetInner()
 			b.InternalAddRequest(req)
@@ -876,7 +1167,7 @@ func (tc *TxnCoordSender) resendWithTxn(ba roachpb.BatchRequest) (*roachpb.Batch
 
 // updateStats updates transaction metrics after a transaction finishes.
 func (tc *TxnCoordSender) updateStats(duration, restarts int64, status roachpb.TransactionStatus) {
-	tc.metrics.Durations.RecordValue(duration)
+	tc.metrics.Durations.RecordValue(time.Duration(duration))
 	tc.metrics.Restarts.RecordValue(restarts)
 	switch status {
 	case roachpb.ABORTED:
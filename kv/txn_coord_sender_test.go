@@ -697,6 +697,198 @@ func TestTxnCoordSenderSingleRoundtripTxn(t *testing.T) {
 	}
 }
 
+// TestTxnCoordSenderPipelineWrites verifies that blind writes of a writing
+// transaction are acknowledged before they return, and that they are proven
+// before the requests which touch their keys and before the commit, which
+// fails if they did.
+func TestTxnCoordSenderPipelineWrites(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+	manual := hlc.NewManualClock(0)
+	clock := hlc.NewClock(manual.UnixNano)
+	clock.SetMaxOffset(20)
+
+	unblock := make(chan struct{})
+	var pushed, overtaken int32
+	ts := NewTxnCoordSender(senderFn(func(_ context.Context, ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+		br := ba.CreateReply()
+		txnClone := ba.Txn.Clone()
+		br.Txn = &txnClone
+		br.Txn.Writing = true
+		if _, ok := ba.GetArg(roachpb.Put); ok && len(ba.Requests) > 1 {
+			// The first write of the transaction.
+			return br, nil
+		}
+		switch ba.Requests[0].GetInner().(type) {
+		case *roachpb.PutRequest:
+			<-unblock
+			if bytes.Equal(ba.Requests[0].GetInner().Header().Key, roachpb.Key("fail")) {
+				return nil, roachpb.NewErrorf("injected")
+			}
+			// Push the transaction, which the commit must pick up.
+			br.Txn.Timestamp.Forward(makeTS(10, 0))
+			atomic.AddInt32(&pushed, 1)
+		case *roachpb.GetRequest:
+			if atomic.LoadInt32(&pushed) == 0 {
+				atomic.AddInt32(&overtaken, 1)
+			}
+		case *roachpb.EndTransactionRequest:
+			if !ba.Txn.Timestamp.Equal(makeTS(10, 0)) {
+				return nil, roachpb.NewErrorf("commit at %s did not pick up the pipelined write", ba.Txn.Timestamp)
+			}
+			br.Txn.Status = roachpb.COMMITTED
+		}
+		return br, nil
+	}), clock, false, tracing.NewTracer(), stopper, NewTxnMetrics(metric.NewRegistry()))
+	ts.SetPipelineWrites(true)
+	defer teardownHeartbeats(ts)
+
+	send := func(txn *roachpb.Transaction, args ...roachpb.Request) (*roachpb.Transaction, *roachpb.Error) {
+		var ba roachpb.BatchRequest
+		for _, arg := range args {
+			ba.Add(arg)
+		}
+		ba.Txn = txn
+		br, pErr := ts.Send(context.Background(), ba)
+		if pErr != nil {
+			return nil, pErr
+		}
+		return br.Txn, nil
+	}
+	begin := func(name string) *roachpb.Transaction {
+		key := roachpb.Key(name)
+		txn, pErr := send(&roachpb.Transaction{Name: name, Isolation: roachpb.SNAPSHOT},
+			&roachpb.BeginTransactionRequest{Span: roachpb.Span{Key: key}},
+			&roachpb.PutRequest{Span: roachpb.Span{Key: key}})
+		if pErr != nil {
+			t.Fatal(pErr)
+		}
+		return txn
+	}
+
+	txn := begin("a")
+	// The write returns while the wrapped sender blocks.
+	txn, pErr := send(txn, &roachpb.PutRequest{Span: roachpb.Span{Key: roachpb.Key("b")}})
+	if pErr != nil {
+		t.Fatal(pErr)
+	}
+	// Reads of other keys don't wait for it.
+	if _, pErr := send(txn, &roachpb.GetRequest{Span: roachpb.Span{Key: roachpb.Key("c")}}); pErr != nil {
+		t.Fatal(pErr)
+	}
+	if n := atomic.LoadInt32(&overtaken); n != 1 {
+		t.Fatalf("expected the read to overtake the pipelined write")
+	}
+	if c := ts.metrics.Pipelined.Count(); c != 1 {
+		t.Errorf("expected 1 pipelined write, got %d", c)
+	}
+	close(unblock)
+	// Reads of the written key, and the commit, wait for it.
+	if _, pErr := send(txn, &roachpb.GetRequest{Span: roachpb.Span{Key: roachpb.Key("b")}}); pErr != nil {
+		t.Fatal(pErr)
+	}
+	if n := atomic.LoadInt32(&overtaken); n != 1 {
+		t.Fatalf("expected the read to wait for the pipelined write")
+	}
+	if _, pErr := send(txn, &roachpb.EndTransactionRequest{Commit: true}); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	// The errors of pipelined writes are returned by the commit.
+	txn = begin("d")
+	if _, pErr := send(txn, &roachpb.PutRequest{Span: roachpb.Span{Key: roachpb.Key("fail")}}); pErr != nil {
+		t.Fatal(pErr)
+	}
+	if _, pErr := send(txn, &roachpb.EndTransactionRequest{Commit: true}); !testutils.IsPError(pErr, "injected") {
+		t.Fatalf("expected the commit to return the error of the pipelined write, got %v", pErr)
+	}
+}
+
+// TestTxnCoordSenderPipelineWritesRestart verifies that the errors of the
+// pipelined writes of an earlier epoch don't surface after the transaction
+// restarted.
+func TestTxnCoordSenderPipelineWritesRestart(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+	manual := hlc.NewManualClock(0)
+	clock := hlc.NewClock(manual.UnixNano)
+	clock.SetMaxOffset(20)
+
+	unblock := make(chan struct{})
+	ts := NewTxnCoordSender(senderFn(func(_ context.Context, ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+		br := ba.CreateReply()
+		txnClone := ba.Txn.Clone()
+		br.Txn = &txnClone
+		br.Txn.Writing = true
+		if _, ok := ba.GetArg(roachpb.Put); ok && len(ba.Requests) > 1 {
+			// The first write of the transaction.
+			return br, nil
+		}
+		switch ba.Requests[0].GetInner().(type) {
+		case *roachpb.PutRequest:
+			<-unblock
+			return nil, roachpb.NewErrorf("injected")
+		case *roachpb.GetRequest:
+			return nil, roachpb.NewErrorWithTxn(roachpb.NewTransactionRetryError(), ba.Txn)
+		case *roachpb.EndTransactionRequest:
+			br.Txn.Status = roachpb.COMMITTED
+		}
+		return br, nil
+	}), clock, false, tracing.NewTracer(), stopper, NewTxnMetrics(metric.NewRegistry()))
+	ts.SetPipelineWrites(true)
+	defer teardownHeartbeats(ts)
+
+	send := func(txn *roachpb.Transaction, args ...roachpb.Request) (*roachpb.Transaction, *roachpb.Error) {
+		var ba roachpb.BatchRequest
+		for _, arg := range args {
+			ba.Add(arg)
+		}
+		ba.Txn = txn
+		br, pErr := ts.Send(context.Background(), ba)
+		if pErr != nil {
+			return nil, pErr
+		}
+		return br.Txn, nil
+	}
+
+	key := roachpb.Key("a")
+	txn, pErr := send(&roachpb.Transaction{Name: "test", Isolation: roachpb.SNAPSHOT},
+		&roachpb.BeginTransactionRequest{Span: roachpb.Span{Key: key}},
+		&roachpb.PutRequest{Span: roachpb.Span{Key: key}})
+	if pErr != nil {
+		t.Fatal(pErr)
+	}
+	if txn, pErr = send(txn, &roachpb.PutRequest{Span: roachpb.Span{Key: roachpb.Key("b")}}); pErr != nil {
+		t.Fatal(pErr)
+	}
+	ts.Lock()
+	writes := ts.pipelined[*txn.ID]
+	ts.Unlock()
+	if len(writes) != 1 {
+		t.Fatalf("expected 1 pipelined write, got %d", len(writes))
+	}
+
+	// A read of another key restarts the transaction while the write is in
+	// flight.
+	_, pErr = send(txn, &roachpb.GetRequest{Span: roachpb.Span{Key: roachpb.Key("c")}})
+	if _, ok := pErr.GetDetail().(*roachpb.TransactionRetryError); !ok {
+		t.Fatalf("expected a retry error, got %v", pErr)
+	}
+	restarted := pErr.GetTxn()
+	if restarted.Epoch <= txn.Epoch {
+		t.Fatalf("expected the transaction to restart, got epoch %d", restarted.Epoch)
+	}
+	close(unblock)
+	<-writes[0].done
+
+	// The failed write of the earlier epoch doesn't fail the new one.
+	if _, pErr := send(restarted, &roachpb.EndTransactionRequest{Commit: true}); pErr != nil {
+		t.Fatal(pErr)
+	}
+}
+
 // TestTxnCoordSenderErrorWithIntent validates that if a transactional request
 // returns an error but also indicates a Writing transaction, the coordinator
 // tracks it just like a successful request.
@@ -788,7 +980,7 @@ func checkTxnMetrics(t *testing.T, sender *TxnCoordSender, name string, commits,
 			{"commits", metrics.Commits.Count(), commits},
 			{"abandons", metrics.Abandons.Count(), abandons},
 			{"aborts", metrics.Aborts.Count(), aborts},
-			{"durations", metrics.Durations[metric.Scale1M].Current().TotalCount(),
+			{"durations", metrics.Durations.Histograms[metric.Scale1M].Current().TotalCount(),
 				commits + abandons + aborts},
 		}
 
@@ -987,7 +1179,7 @@ func TestTxnDurations(t *testing.T) {
 	teardownHeartbeats(sender)
 	checkTxnMetrics(t, sender, "txn durations", puts, 0, 0, 0)
 
-	hist := sender.metrics.Durations[metric.Scale1M].Current()
+	hist := sender.metrics.Durations.Histograms[metric.Scale1M].Current()
 
 	// The clock is a bit odd in these tests, so I can't test the mean without introducing
 	// spurious errors or being overly lax.
@@ -138,6 +138,9 @@ func (rh *ResponseHeader) combine(otherRH *ResponseHeader) error {
 		if rh.Txn != nil && otherRH.Txn == nil {
 			rh.Txn = nil
 		}
+		if otherRH.ResumeSpan != nil {
+			rh.ResumeSpan = otherRH.ResumeSpan
+		}
 	}
 	return nil
 }
@@ -200,6 +203,29 @@ func (cc *CheckConsistencyResponse) combine(c combinable) error {
 	return nil
 }
 
+// combine implements the combinable interface.
+func (cr *ClearRangeResponse) combine(c combinable) error {
+	if cr != nil {
+		otherCR := c.(*ClearRangeResponse)
+		if err := cr.Header().combine(otherCR.Header()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// combine implements the combinable interface.
+func (er *ExportResponse) combine(c combinable) error {
+	if er != nil {
+		otherER := c.(*ExportResponse)
+		if err := er.Header().combine(otherER.Header()); err != nil {
+			return err
+		}
+		er.Files = append(er.Files, otherER.Files...)
+	}
+	return nil
+}
+
 // Header implements the Request interface for RequestHeader.
 func (rh *Span) Header() *Span {
 	return rh
@@ -298,6 +324,33 @@ func (sr *ReverseScanResponse) Count() int64 {
 	return int64(len(sr.Rows))
 }
 
+// Sizable is implemented by response types whose result rows count against
+// the TargetBytes limit of a batch, such as Scan.
+type Sizable interface {
+	ByteSize() int64
+}
+
+// rowsByteSize returns the total size of the keys and values of the rows.
+func rowsByteSize(rows []KeyValue) int64 {
+	var size int64
+	for _, kv := range rows {
+		size += int64(len(kv.Key) + len(kv.Value.RawBytes))
+	}
+	return size
+}
+
+// ByteSize returns the total size of the keys and values of the rows in
+// ScanResponse.
+func (sr *ScanResponse) ByteSize() int64 {
+	return rowsByteSize(sr.Rows)
+}
+
+// ByteSize returns the total size of the keys and values of the rows in
+// ReverseScanResponse.
+func (sr *ReverseScanResponse) ByteSize() int64 {
+	return rowsByteSize(sr.Rows)
+}
+
 // Method implements the Request interface.
 func (*GetRequest) Method() Method { return Get }
 
@@ -325,6 +378,18 @@ func (*ReverseScanRequest) Method() Method { return ReverseScan }
 // Method implements the Request interface.
 func (*CheckConsistencyRequest) Method() Method { return CheckConsistency }
 
+// Method implements the Request interface.
+func (*ClearRangeRequest) Method() Method { return ClearRange }
+
+// Method implements the Request interface.
+func (*LeaseInfoRequest) Method() Method { return LeaseInfo }
+
+// Method implements the Request interface.
+func (*ExportRequest) Method() Method { return Export }
+
+// Method implements the Request interface.
+func (*RecomputeStatsRequest) Method() Method { return RecomputeStats }
+
 // Method implements the Request interface.
 func (*BeginTransactionRequest) Method() Method { return BeginTransaction }
 
@@ -382,6 +447,10 @@ func (*DeleteRangeRequest) createReply() Response        { return &DeleteRangeRe
 func (*ScanRequest) createReply() Response               { return &ScanResponse{} }
 func (*ReverseScanRequest) createReply() Response        { return &ReverseScanResponse{} }
 func (*CheckConsistencyRequest) createReply() Response   { return &CheckConsistencyResponse{} }
+func (*ClearRangeRequest) createReply() Response         { return &ClearRangeResponse{} }
+func (*LeaseInfoRequest) createReply() Response          { return &LeaseInfoResponse{} }
+func (*ExportRequest) createReply() Response             { return &ExportResponse{} }
+func (*RecomputeStatsRequest) createReply() Response     { return &RecomputeStatsResponse{} }
 func (*BeginTransactionRequest) createReply() Response   { return &BeginTransactionResponse{} }
 func (*EndTransactionRequest) createReply() Response     { return &EndTransactionResponse{} }
 func (*AdminSplitRequest) createReply() Response         { return &AdminSplitResponse{} }
@@ -528,3 +597,7 @@ func (*LeaderLeaseRequest) flags() int        { return isWrite }
 func (*ComputeChecksumRequest) flags() int    { return isWrite }
 func (*VerifyChecksumRequest) flags() int     { return isWrite }
 func (*CheckConsistencyRequest) flags() int   { return isAdmin | isRange }
+func (*ClearRangeRequest) flags() int         { return isWrite | isRange | isAlone }
+func (*LeaseInfoRequest) flags() int          { return isRead }
+func (*ExportRequest) flags() int             { return isRead | isRange }
+func (*RecomputeStatsRequest) flags() int     { return isWrite | isAlone }
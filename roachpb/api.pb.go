@@ -33,6 +33,15 @@
 		ReverseScanResponse
 		CheckConsistencyRequest
 		CheckConsistencyResponse
+		ClearRangeRequest
+		ClearRangeResponse
+		LeaseInfoRequest
+		LeaseInfoResponse
+		ExportRequest
+		ExportedFile
+		ExportResponse
+		RecomputeStatsRequest
+		RecomputeStatsResponse
 		BeginTransactionRequest
 		BeginTransactionResponse
 		EndTransactionRequest
@@ -106,6 +115,8 @@
 		DidntUpdateDescriptorError
 		SqlTransactionAbortedError
 		ExistingSchemaChangeLeaseError
+		RetryBudgetExceededError
+		AmbiguousResultError
 		ErrorDetail
 		ErrPosition
 		Error
@@ -194,6 +205,46 @@ func (x *ReadConsistencyType) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// AdmissionClass determines the order in which requests are admitted for
+// evaluation by a store which is overloaded.
+type AdmissionClass int32
+
+const (
+	// FOREGROUND requests are issued on behalf of users, e.g. by SQL
+	// statements. They are always admitted ahead of BACKGROUND requests.
+	FOREGROUND AdmissionClass = 0
+	// BACKGROUND requests are issued by internal processes such as garbage
+	// collection and the replica queues. They are the first to be delayed
+	// when a store is overloaded.
+	BACKGROUND AdmissionClass = 1
+)
+
+var AdmissionClass_name = map[int32]string{
+	0: "FOREGROUND",
+	1: "BACKGROUND",
+}
+var AdmissionClass_value = map[string]int32{
+	"FOREGROUND": 0,
+	"BACKGROUND": 1,
+}
+
+func (x AdmissionClass) Enum() *AdmissionClass {
+	p := new(AdmissionClass)
+	*p = x
+	return p
+}
+func (x AdmissionClass) String() string {
+	return proto.EnumName(AdmissionClass_name, int32(x))
+}
+func (x *AdmissionClass) UnmarshalJSON(data []byte) error {
+	value, err := proto.UnmarshalJSONEnum(AdmissionClass_value, data, "AdmissionClass")
+	if err != nil {
+		return err
+	}
+	*x = AdmissionClass(value)
+	return nil
+}
+
 // TxnPushType determines what action to take when pushing a transaction.
 type PushTxnType int32
 
@@ -249,6 +300,11 @@ type ResponseHeader struct {
 	// The transaction timestamp and/or priority may have been updated,
 	// depending on the outcome of the request.
 	Txn *Transaction `protobuf:"bytes,3,opt,name=txn" json:"txn,omitempty"`
+	// resume_span is set by bounded requests (such as Scan) which stopped
+	// before covering their whole span because a limit was reached. It is
+	// the part of the request's span which remains to be processed, and may
+	// be empty if the limit was reached at the end of the span.
+	ResumeSpan *Span `protobuf:"bytes,4,opt,name=resume_span" json:"resume_span,omitempty"`
 }
 
 func (m *ResponseHeader) Reset()         { *m = ResponseHeader{} }
@@ -465,6 +521,114 @@ func (m *CheckConsistencyResponse) Reset()         { *m = CheckConsistencyRespon
 func (m *CheckConsistencyResponse) String() string { return proto.CompactTextString(m) }
 func (*CheckConsistencyResponse) ProtoMessage()    {}
 
+// A ClearRangeRequest is the argument to the ClearRange() method. It
+// removes all values, including all of their versions, in the specified
+// span. The request is not transactional and bypasses MVCC, so it must
+// only be used on spans which are no longer read or written, such as the
+// key span of a dropped table.
+type ClearRangeRequest struct {
+	Span `protobuf:"bytes,1,opt,name=header,embedded=header" json:"header"`
+}
+
+func (m *ClearRangeRequest) Reset()         { *m = ClearRangeRequest{} }
+func (m *ClearRangeRequest) String() string { return proto.CompactTextString(m) }
+func (*ClearRangeRequest) ProtoMessage()    {}
+
+// A ClearRangeResponse is the return value from the ClearRange() method.
+type ClearRangeResponse struct {
+	ResponseHeader `protobuf:"bytes,1,opt,name=header,embedded=header" json:"header"`
+}
+
+func (m *ClearRangeResponse) Reset()         { *m = ClearRangeResponse{} }
+func (m *ClearRangeResponse) String() string { return proto.CompactTextString(m) }
+func (*ClearRangeResponse) ProtoMessage()    {}
+
+// A LeaseInfoRequest is the argument to the LeaseInfo() method. It returns
+// the lease in effect for the range containing its key, as seen by the lease
+// holder which serves it.
+type LeaseInfoRequest struct {
+	Span `protobuf:"bytes,1,opt,name=header,embedded=header" json:"header"`
+}
+
+func (m *LeaseInfoRequest) Reset()         { *m = LeaseInfoRequest{} }
+func (m *LeaseInfoRequest) String() string { return proto.CompactTextString(m) }
+func (*LeaseInfoRequest) ProtoMessage()    {}
+
+// A LeaseInfoResponse is the return value from the LeaseInfo() method.
+type LeaseInfoResponse struct {
+	ResponseHeader `protobuf:"bytes,1,opt,name=header,embedded=header" json:"header"`
+	// lease is the lease in effect for the range.
+	Lease Lease `protobuf:"bytes,2,opt,name=lease" json:"lease"`
+}
+
+func (m *LeaseInfoResponse) Reset()         { *m = LeaseInfoResponse{} }
+func (m *LeaseInfoResponse) String() string { return proto.CompactTextString(m) }
+func (*LeaseInfoResponse) ProtoMessage()    {}
+
+// An ExportRequest is the argument to the Export() method. It exports the
+// latest values of the keys in the specified span as of the timestamp of the
+// batch, in the SSTable format used by RocksDB.
+type ExportRequest struct {
+	Span `protobuf:"bytes,1,opt,name=header,embedded=header" json:"header"`
+	// dir, if set, is a directory on the nodes evaluating the request to which
+	// the SSTables are written instead of being returned.
+	Dir string `protobuf:"bytes,2,opt,name=dir" json:"dir"`
+}
+
+func (m *ExportRequest) Reset()         { *m = ExportRequest{} }
+func (m *ExportRequest) String() string { return proto.CompactTextString(m) }
+func (*ExportRequest) ProtoMessage()    {}
+
+// An ExportedFile is an SSTable holding the exported data of a range.
+type ExportedFile struct {
+	// span is the part of the request's span which was exported to the file.
+	Span Span `protobuf:"bytes,1,opt,name=span" json:"span"`
+	// path is the path of the file if the request specified a directory.
+	Path string `protobuf:"bytes,2,opt,name=path" json:"path"`
+	// sst holds the contents of the file if the request did not specify a
+	// directory.
+	SST []byte `protobuf:"bytes,3,opt,name=sst" json:"sst,omitempty"`
+}
+
+func (m *ExportedFile) Reset()         { *m = ExportedFile{} }
+func (m *ExportedFile) String() string { return proto.CompactTextString(m) }
+func (*ExportedFile) ProtoMessage()    {}
+
+// An ExportResponse is the return value from the Export() method.
+type ExportResponse struct {
+	ResponseHeader `protobuf:"bytes,1,opt,name=header,embedded=header" json:"header"`
+	// files holds one file for each range which had data to export.
+	Files []ExportedFile `protobuf:"bytes,2,rep,name=files" json:"files,omitempty"`
+}
+
+func (m *ExportResponse) Reset()         { *m = ExportResponse{} }
+func (m *ExportResponse) String() string { return proto.CompactTextString(m) }
+func (*ExportResponse) ProtoMessage()    {}
+
+// A RecomputeStatsRequest is the argument to the RecomputeStats() method. It
+// repairs the MVCC stats of the range, which the lease holder found to have
+// drifted from a recomputation over all of its data.
+type RecomputeStatsRequest struct {
+	Span `protobuf:"bytes,1,opt,name=header,embedded=header" json:"header"`
+	// delta is the marshaled MVCCStats which are added to the stored stats of
+	// the range to repair them.
+	Delta []byte `protobuf:"bytes,2,opt,name=delta" json:"delta,omitempty"`
+}
+
+func (m *RecomputeStatsRequest) Reset()         { *m = RecomputeStatsRequest{} }
+func (m *RecomputeStatsRequest) String() string { return proto.CompactTextString(m) }
+func (*RecomputeStatsRequest) ProtoMessage()    {}
+
+// A RecomputeStatsResponse is the return value from the RecomputeStats()
+// method.
+type RecomputeStatsResponse struct {
+	ResponseHeader `protobuf:"bytes,1,opt,name=header,embedded=header" json:"header"`
+}
+
+func (m *RecomputeStatsResponse) Reset()         { *m = RecomputeStatsResponse{} }
+func (m *RecomputeStatsResponse) String() string { return proto.CompactTextString(m) }
+func (*RecomputeStatsResponse) ProtoMessage()    {}
+
 // A BeginTransactionRequest is the argument to the BeginTransaction() method.
 type BeginTransactionRequest struct {
 	Span `protobuf:"bytes,1,opt,name=header,embedded=header" json:"header"`
@@ -519,6 +683,9 @@ type EndTransactionResponse struct {
 	CommitWait int64 `protobuf:"varint,2,opt,name=commit_wait" json:"commit_wait"`
 	// List of intents resolved by EndTransaction call.
 	Resolved []Key `protobuf:"bytes,3,rep,name=resolved,casttype=Key" json:"resolved,omitempty"`
+	// True if the transaction committed in the batch which began it, with all
+	// of its intents resolved synchronously, i.e. in a single phase.
+	OnePhaseCommit bool `protobuf:"varint,4,opt,name=one_phase_commit" json:"one_phase_commit"`
 }
 
 func (m *EndTransactionResponse) Reset()         { *m = EndTransactionResponse{} }
@@ -980,6 +1147,10 @@ type RequestUnion struct {
 	VerifyChecksum     *VerifyChecksumRequest     `protobuf:"bytes,23,opt,name=verify_checksum" json:"verify_checksum,omitempty"`
 	CheckConsistency   *CheckConsistencyRequest   `protobuf:"bytes,24,opt,name=check_consistency" json:"check_consistency,omitempty"`
 	Noop               *NoopRequest               `protobuf:"bytes,25,opt,name=noop" json:"noop,omitempty"`
+	ClearRange         *ClearRangeRequest         `protobuf:"bytes,26,opt,name=clear_range" json:"clear_range,omitempty"`
+	LeaseInfo          *LeaseInfoRequest          `protobuf:"bytes,27,opt,name=lease_info" json:"lease_info,omitempty"`
+	Export             *ExportRequest             `protobuf:"bytes,28,opt,name=export" json:"export,omitempty"`
+	RecomputeStats     *RecomputeStatsRequest     `protobuf:"bytes,29,opt,name=recompute_stats" json:"recompute_stats,omitempty"`
 }
 
 func (m *RequestUnion) Reset()         { *m = RequestUnion{} }
@@ -1014,6 +1185,10 @@ type ResponseUnion struct {
 	VerifyChecksum     *VerifyChecksumResponse     `protobuf:"bytes,23,opt,name=verify_checksum" json:"verify_checksum,omitempty"`
 	CheckConsistency   *CheckConsistencyResponse   `protobuf:"bytes,24,opt,name=check_consistency" json:"check_consistency,omitempty"`
 	Noop               *NoopResponse               `protobuf:"bytes,25,opt,name=noop" json:"noop,omitempty"`
+	ClearRange         *ClearRangeResponse         `protobuf:"bytes,26,opt,name=clear_range" json:"clear_range,omitempty"`
+	LeaseInfo          *LeaseInfoResponse          `protobuf:"bytes,27,opt,name=lease_info" json:"lease_info,omitempty"`
+	Export             *ExportResponse             `protobuf:"bytes,28,opt,name=export" json:"export,omitempty"`
+	RecomputeStats     *RecomputeStatsResponse     `protobuf:"bytes,29,opt,name=recompute_stats" json:"recompute_stats,omitempty"`
 }
 
 func (m *ResponseUnion) Reset()         { *m = ResponseUnion{} }
@@ -1058,6 +1233,14 @@ type Header struct {
 	// if set to a non-zero value, limits the total number of results for
 	// Scan/ReverseScan requests in the batch.
 	MaxScanResults int64 `protobuf:"varint,8,opt,name=max_scan_results" json:"max_scan_results"`
+	// admission_class determines the priority with which the batch is
+	// admitted for evaluation by an overloaded store.
+	AdmissionClass AdmissionClass `protobuf:"varint,9,opt,name=admission_class,enum=cockroach.roachpb.AdmissionClass" json:"admission_class"`
+	// if set to a non-zero value, limits the total size in bytes of the keys
+	// and values returned by Scan/ReverseScan requests in the batch. The limit
+	// is soft: results stop after the first row which reaches it, so that at
+	// least one row is returned.
+	TargetBytes int64 `protobuf:"varint,10,opt,name=target_bytes" json:"target_bytes"`
 }
 
 func (m *Header) Reset()         { *m = Header{} }
@@ -1123,6 +1306,15 @@ func init() {
 	proto.RegisterType((*ReverseScanResponse)(nil), "cockroach.roachpb.ReverseScanResponse")
 	proto.RegisterType((*CheckConsistencyRequest)(nil), "cockroach.roachpb.CheckConsistencyRequest")
 	proto.RegisterType((*CheckConsistencyResponse)(nil), "cockroach.roachpb.CheckConsistencyResponse")
+	proto.RegisterType((*ClearRangeRequest)(nil), "cockroach.roachpb.ClearRangeRequest")
+	proto.RegisterType((*ClearRangeResponse)(nil), "cockroach.roachpb.ClearRangeResponse")
+	proto.RegisterType((*LeaseInfoRequest)(nil), "cockroach.roachpb.LeaseInfoRequest")
+	proto.RegisterType((*LeaseInfoResponse)(nil), "cockroach.roachpb.LeaseInfoResponse")
+	proto.RegisterType((*ExportRequest)(nil), "cockroach.roachpb.ExportRequest")
+	proto.RegisterType((*ExportedFile)(nil), "cockroach.roachpb.ExportedFile")
+	proto.RegisterType((*ExportResponse)(nil), "cockroach.roachpb.ExportResponse")
+	proto.RegisterType((*RecomputeStatsRequest)(nil), "cockroach.roachpb.RecomputeStatsRequest")
+	proto.RegisterType((*RecomputeStatsResponse)(nil), "cockroach.roachpb.RecomputeStatsResponse")
 	proto.RegisterType((*BeginTransactionRequest)(nil), "cockroach.roachpb.BeginTransactionRequest")
 	proto.RegisterType((*BeginTransactionResponse)(nil), "cockroach.roachpb.BeginTransactionResponse")
 	proto.RegisterType((*EndTransactionRequest)(nil), "cockroach.roachpb.EndTransactionRequest")
@@ -1163,6 +1355,7 @@ func init() {
 	proto.RegisterType((*BatchResponse)(nil), "cockroach.roachpb.BatchResponse")
 	proto.RegisterType((*BatchResponse_Header)(nil), "cockroach.roachpb.BatchResponse.Header")
 	proto.RegisterEnum("cockroach.roachpb.ReadConsistencyType", ReadConsistencyType_name, ReadConsistencyType_value)
+	proto.RegisterEnum("cockroach.roachpb.AdmissionClass", AdmissionClass_name, AdmissionClass_value)
 	proto.RegisterEnum("cockroach.roachpb.PushTxnType", PushTxnType_name, PushTxnType_value)
 }
 
@@ -1317,6 +1510,16 @@ func (m *ResponseHeader) MarshalTo(data []byte) (int, error) {
 		}
 		i += n2
 	}
+	if m.ResumeSpan != nil {
+		data[i] = 0x22
+		i++
+		i = encodeVarintApi(data, i, uint64(m.ResumeSpan.Size()))
+		n3, err := m.ResumeSpan.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n3
+	}
 	return i, nil
 }
 
@@ -1879,7 +2082,7 @@ func (m *CheckConsistencyResponse) MarshalTo(data []byte) (int, error) {
 	return i, nil
 }
 
-func (m *BeginTransactionRequest) Marshal() (data []byte, err error) {
+func (m *ClearRangeRequest) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -1889,7 +2092,7 @@ func (m *BeginTransactionRequest) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *BeginTransactionRequest) MarshalTo(data []byte) (int, error) {
+func (m *ClearRangeRequest) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -1897,15 +2100,15 @@ func (m *BeginTransactionRequest) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.Span.Size()))
-	n25, err := m.Span.MarshalTo(data[i:])
+	n130, err := m.Span.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n25
+	i += n130
 	return i, nil
 }
 
-func (m *BeginTransactionResponse) Marshal() (data []byte, err error) {
+func (m *ClearRangeResponse) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -1915,7 +2118,7 @@ func (m *BeginTransactionResponse) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *BeginTransactionResponse) MarshalTo(data []byte) (int, error) {
+func (m *ClearRangeResponse) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -1923,15 +2126,15 @@ func (m *BeginTransactionResponse) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.ResponseHeader.Size()))
-	n26, err := m.ResponseHeader.MarshalTo(data[i:])
+	n131, err := m.ResponseHeader.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n26
+	i += n131
 	return i, nil
 }
 
-func (m *EndTransactionRequest) Marshal() (data []byte, err error) {
+func (m *LeaseInfoRequest) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -1941,7 +2144,7 @@ func (m *EndTransactionRequest) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *EndTransactionRequest) MarshalTo(data []byte) (int, error) {
+func (m *LeaseInfoRequest) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -1949,55 +2152,15 @@ func (m *EndTransactionRequest) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.Span.Size()))
-	n27, err := m.Span.MarshalTo(data[i:])
+	n134, err := m.Span.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n27
-	data[i] = 0x10
-	i++
-	if m.Commit {
-		data[i] = 1
-	} else {
-		data[i] = 0
-	}
-	i++
-	if m.Deadline != nil {
-		data[i] = 0x1a
-		i++
-		i = encodeVarintApi(data, i, uint64(m.Deadline.Size()))
-		n28, err := m.Deadline.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n28
-	}
-	if m.InternalCommitTrigger != nil {
-		data[i] = 0x22
-		i++
-		i = encodeVarintApi(data, i, uint64(m.InternalCommitTrigger.Size()))
-		n29, err := m.InternalCommitTrigger.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n29
-	}
-	if len(m.IntentSpans) > 0 {
-		for _, msg := range m.IntentSpans {
-			data[i] = 0x2a
-			i++
-			i = encodeVarintApi(data, i, uint64(msg.Size()))
-			n, err := msg.MarshalTo(data[i:])
-			if err != nil {
-				return 0, err
-			}
-			i += n
-		}
-	}
+	i += n134
 	return i, nil
 }
 
-func (m *EndTransactionResponse) Marshal() (data []byte, err error) {
+func (m *LeaseInfoResponse) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2007,7 +2170,7 @@ func (m *EndTransactionResponse) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *EndTransactionResponse) MarshalTo(data []byte) (int, error) {
+func (m *LeaseInfoResponse) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -2015,58 +2178,23 @@ func (m *EndTransactionResponse) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.ResponseHeader.Size()))
-	n30, err := m.ResponseHeader.MarshalTo(data[i:])
+	n135, err := m.ResponseHeader.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n30
-	data[i] = 0x10
-	i++
-	i = encodeVarintApi(data, i, uint64(m.CommitWait))
-	if len(m.Resolved) > 0 {
-		for _, b := range m.Resolved {
-			data[i] = 0x1a
-			i++
-			i = encodeVarintApi(data, i, uint64(len(b)))
-			i += copy(data[i:], b)
-		}
-	}
-	return i, nil
-}
-
-func (m *AdminSplitRequest) Marshal() (data []byte, err error) {
-	size := m.Size()
-	data = make([]byte, size)
-	n, err := m.MarshalTo(data)
-	if err != nil {
-		return nil, err
-	}
-	return data[:n], nil
-}
-
-func (m *AdminSplitRequest) MarshalTo(data []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	data[i] = 0xa
+	i += n135
+	data[i] = 0x12
 	i++
-	i = encodeVarintApi(data, i, uint64(m.Span.Size()))
-	n31, err := m.Span.MarshalTo(data[i:])
+	i = encodeVarintApi(data, i, uint64(m.Lease.Size()))
+	n136, err := m.Lease.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n31
-	if m.SplitKey != nil {
-		data[i] = 0x12
-		i++
-		i = encodeVarintApi(data, i, uint64(len(m.SplitKey)))
-		i += copy(data[i:], m.SplitKey)
-	}
+	i += n136
 	return i, nil
 }
 
-func (m *AdminSplitResponse) Marshal() (data []byte, err error) {
+func (m *ExportRequest) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2076,23 +2204,27 @@ func (m *AdminSplitResponse) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *AdminSplitResponse) MarshalTo(data []byte) (int, error) {
+func (m *ExportRequest) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
 	data[i] = 0xa
 	i++
-	i = encodeVarintApi(data, i, uint64(m.ResponseHeader.Size()))
-	n32, err := m.ResponseHeader.MarshalTo(data[i:])
+	i = encodeVarintApi(data, i, uint64(m.Span.Size()))
+	n139, err := m.Span.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n32
+	i += n139
+	data[i] = 0x12
+	i++
+	i = encodeVarintApi(data, i, uint64(len(m.Dir)))
+	i += copy(data[i:], m.Dir)
 	return i, nil
 }
 
-func (m *AdminMergeRequest) Marshal() (data []byte, err error) {
+func (m *ExportedFile) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2102,7 +2234,7 @@ func (m *AdminMergeRequest) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *AdminMergeRequest) MarshalTo(data []byte) (int, error) {
+func (m *ExportedFile) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -2110,15 +2242,25 @@ func (m *AdminMergeRequest) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.Span.Size()))
-	n33, err := m.Span.MarshalTo(data[i:])
+	n140, err := m.Span.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n33
+	i += n140
+	data[i] = 0x12
+	i++
+	i = encodeVarintApi(data, i, uint64(len(m.Path)))
+	i += copy(data[i:], m.Path)
+	if m.SST != nil {
+		data[i] = 0x1a
+		i++
+		i = encodeVarintApi(data, i, uint64(len(m.SST)))
+		i += copy(data[i:], m.SST)
+	}
 	return i, nil
 }
 
-func (m *AdminMergeResponse) Marshal() (data []byte, err error) {
+func (m *ExportResponse) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2128,7 +2270,7 @@ func (m *AdminMergeResponse) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *AdminMergeResponse) MarshalTo(data []byte) (int, error) {
+func (m *ExportResponse) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -2136,15 +2278,27 @@ func (m *AdminMergeResponse) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.ResponseHeader.Size()))
-	n34, err := m.ResponseHeader.MarshalTo(data[i:])
+	n141, err := m.ResponseHeader.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n34
+	i += n141
+	if len(m.Files) > 0 {
+		for _, msg := range m.Files {
+			data[i] = 0x12
+			i++
+			i = encodeVarintApi(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
 	return i, nil
 }
 
-func (m *RangeLookupRequest) Marshal() (data []byte, err error) {
+func (m *RecomputeStatsRequest) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2154,7 +2308,7 @@ func (m *RangeLookupRequest) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *RangeLookupRequest) MarshalTo(data []byte) (int, error) {
+func (m *RecomputeStatsRequest) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -2162,34 +2316,21 @@ func (m *RangeLookupRequest) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.Span.Size()))
-	n35, err := m.Span.MarshalTo(data[i:])
+	n144, err := m.Span.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n35
-	data[i] = 0x10
-	i++
-	i = encodeVarintApi(data, i, uint64(m.MaxRanges))
-	data[i] = 0x18
-	i++
-	if m.ConsiderIntents {
-		data[i] = 1
-	} else {
-		data[i] = 0
-	}
-	i++
-	data[i] = 0x20
-	i++
-	if m.Reverse {
-		data[i] = 1
-	} else {
-		data[i] = 0
+	i += n144
+	if m.Delta != nil {
+		data[i] = 0x12
+		i++
+		i = encodeVarintApi(data, i, uint64(len(m.Delta)))
+		i += copy(data[i:], m.Delta)
 	}
-	i++
 	return i, nil
 }
 
-func (m *RangeLookupResponse) Marshal() (data []byte, err error) {
+func (m *RecomputeStatsResponse) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2199,7 +2340,7 @@ func (m *RangeLookupResponse) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *RangeLookupResponse) MarshalTo(data []byte) (int, error) {
+func (m *RecomputeStatsResponse) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -2207,27 +2348,15 @@ func (m *RangeLookupResponse) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.ResponseHeader.Size()))
-	n36, err := m.ResponseHeader.MarshalTo(data[i:])
+	n145, err := m.ResponseHeader.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n36
-	if len(m.Ranges) > 0 {
-		for _, msg := range m.Ranges {
-			data[i] = 0x12
-			i++
-			i = encodeVarintApi(data, i, uint64(msg.Size()))
-			n, err := msg.MarshalTo(data[i:])
-			if err != nil {
-				return 0, err
-			}
-			i += n
-		}
-	}
+	i += n145
 	return i, nil
 }
 
-func (m *HeartbeatTxnRequest) Marshal() (data []byte, err error) {
+func (m *BeginTransactionRequest) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2237,7 +2366,7 @@ func (m *HeartbeatTxnRequest) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *HeartbeatTxnRequest) MarshalTo(data []byte) (int, error) {
+func (m *BeginTransactionRequest) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -2245,23 +2374,15 @@ func (m *HeartbeatTxnRequest) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.Span.Size()))
-	n37, err := m.Span.MarshalTo(data[i:])
-	if err != nil {
-		return 0, err
-	}
-	i += n37
-	data[i] = 0x12
-	i++
-	i = encodeVarintApi(data, i, uint64(m.Now.Size()))
-	n38, err := m.Now.MarshalTo(data[i:])
+	n25, err := m.Span.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n38
+	i += n25
 	return i, nil
 }
 
-func (m *HeartbeatTxnResponse) Marshal() (data []byte, err error) {
+func (m *BeginTransactionResponse) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2271,7 +2392,7 @@ func (m *HeartbeatTxnResponse) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *HeartbeatTxnResponse) MarshalTo(data []byte) (int, error) {
+func (m *BeginTransactionResponse) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -2279,15 +2400,15 @@ func (m *HeartbeatTxnResponse) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.ResponseHeader.Size()))
-	n39, err := m.ResponseHeader.MarshalTo(data[i:])
+	n26, err := m.ResponseHeader.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n39
+	i += n26
 	return i, nil
 }
 
-func (m *GCRequest) Marshal() (data []byte, err error) {
+func (m *EndTransactionRequest) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2297,7 +2418,7 @@ func (m *GCRequest) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *GCRequest) MarshalTo(data []byte) (int, error) {
+func (m *EndTransactionRequest) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -2305,14 +2426,42 @@ func (m *GCRequest) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.Span.Size()))
-	n40, err := m.Span.MarshalTo(data[i:])
+	n27, err := m.Span.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n40
-	if len(m.Keys) > 0 {
-		for _, msg := range m.Keys {
-			data[i] = 0x1a
+	i += n27
+	data[i] = 0x10
+	i++
+	if m.Commit {
+		data[i] = 1
+	} else {
+		data[i] = 0
+	}
+	i++
+	if m.Deadline != nil {
+		data[i] = 0x1a
+		i++
+		i = encodeVarintApi(data, i, uint64(m.Deadline.Size()))
+		n28, err := m.Deadline.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n28
+	}
+	if m.InternalCommitTrigger != nil {
+		data[i] = 0x22
+		i++
+		i = encodeVarintApi(data, i, uint64(m.InternalCommitTrigger.Size()))
+		n29, err := m.InternalCommitTrigger.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n29
+	}
+	if len(m.IntentSpans) > 0 {
+		for _, msg := range m.IntentSpans {
+			data[i] = 0x2a
 			i++
 			i = encodeVarintApi(data, i, uint64(msg.Size()))
 			n, err := msg.MarshalTo(data[i:])
@@ -2325,7 +2474,7 @@ func (m *GCRequest) MarshalTo(data []byte) (int, error) {
 	return i, nil
 }
 
-func (m *GCRequest_GCKey) Marshal() (data []byte, err error) {
+func (m *EndTransactionResponse) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2335,29 +2484,42 @@ func (m *GCRequest_GCKey) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *GCRequest_GCKey) MarshalTo(data []byte) (int, error) {
+func (m *EndTransactionResponse) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
-	if m.Key != nil {
-		data[i] = 0xa
-		i++
-		i = encodeVarintApi(data, i, uint64(len(m.Key)))
-		i += copy(data[i:], m.Key)
-	}
-	data[i] = 0x12
+	data[i] = 0xa
 	i++
-	i = encodeVarintApi(data, i, uint64(m.Timestamp.Size()))
-	n41, err := m.Timestamp.MarshalTo(data[i:])
+	i = encodeVarintApi(data, i, uint64(m.ResponseHeader.Size()))
+	n30, err := m.ResponseHeader.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n41
+	i += n30
+	data[i] = 0x10
+	i++
+	i = encodeVarintApi(data, i, uint64(m.CommitWait))
+	if len(m.Resolved) > 0 {
+		for _, b := range m.Resolved {
+			data[i] = 0x1a
+			i++
+			i = encodeVarintApi(data, i, uint64(len(b)))
+			i += copy(data[i:], b)
+		}
+	}
+	data[i] = 0x20
+	i++
+	if m.OnePhaseCommit {
+		data[i] = 1
+	} else {
+		data[i] = 0
+	}
+	i++
 	return i, nil
 }
 
-func (m *GCResponse) Marshal() (data []byte, err error) {
+func (m *AdminSplitRequest) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2367,23 +2529,29 @@ func (m *GCResponse) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *GCResponse) MarshalTo(data []byte) (int, error) {
+func (m *AdminSplitRequest) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
 	data[i] = 0xa
 	i++
-	i = encodeVarintApi(data, i, uint64(m.ResponseHeader.Size()))
-	n42, err := m.ResponseHeader.MarshalTo(data[i:])
+	i = encodeVarintApi(data, i, uint64(m.Span.Size()))
+	n31, err := m.Span.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n42
+	i += n31
+	if m.SplitKey != nil {
+		data[i] = 0x12
+		i++
+		i = encodeVarintApi(data, i, uint64(len(m.SplitKey)))
+		i += copy(data[i:], m.SplitKey)
+	}
 	return i, nil
 }
 
-func (m *PushTxnRequest) Marshal() (data []byte, err error) {
+func (m *AdminSplitResponse) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2393,58 +2561,49 @@ func (m *PushTxnRequest) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *PushTxnRequest) MarshalTo(data []byte) (int, error) {
+func (m *AdminSplitResponse) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
 	data[i] = 0xa
 	i++
-	i = encodeVarintApi(data, i, uint64(m.Span.Size()))
-	n43, err := m.Span.MarshalTo(data[i:])
-	if err != nil {
-		return 0, err
-	}
-	i += n43
-	data[i] = 0x12
-	i++
-	i = encodeVarintApi(data, i, uint64(m.PusherTxn.Size()))
-	n44, err := m.PusherTxn.MarshalTo(data[i:])
-	if err != nil {
-		return 0, err
-	}
-	i += n44
-	data[i] = 0x1a
-	i++
-	i = encodeVarintApi(data, i, uint64(m.PusheeTxn.Size()))
-	n45, err := m.PusheeTxn.MarshalTo(data[i:])
+	i = encodeVarintApi(data, i, uint64(m.ResponseHeader.Size()))
+	n32, err := m.ResponseHeader.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n45
-	data[i] = 0x22
-	i++
-	i = encodeVarintApi(data, i, uint64(m.PushTo.Size()))
-	n46, err := m.PushTo.MarshalTo(data[i:])
+	i += n32
+	return i, nil
+}
+
+func (m *AdminMergeRequest) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	i += n46
-	data[i] = 0x2a
+	return data[:n], nil
+}
+
+func (m *AdminMergeRequest) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	data[i] = 0xa
 	i++
-	i = encodeVarintApi(data, i, uint64(m.Now.Size()))
-	n47, err := m.Now.MarshalTo(data[i:])
+	i = encodeVarintApi(data, i, uint64(m.Span.Size()))
+	n33, err := m.Span.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n47
-	data[i] = 0x30
-	i++
-	i = encodeVarintApi(data, i, uint64(m.PushType))
+	i += n33
 	return i, nil
 }
 
-func (m *PushTxnResponse) Marshal() (data []byte, err error) {
+func (m *AdminMergeResponse) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2454,7 +2613,7 @@ func (m *PushTxnResponse) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *PushTxnResponse) MarshalTo(data []byte) (int, error) {
+func (m *AdminMergeResponse) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -2462,23 +2621,15 @@ func (m *PushTxnResponse) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.ResponseHeader.Size()))
-	n48, err := m.ResponseHeader.MarshalTo(data[i:])
-	if err != nil {
-		return 0, err
-	}
-	i += n48
-	data[i] = 0x12
-	i++
-	i = encodeVarintApi(data, i, uint64(m.PusheeTxn.Size()))
-	n49, err := m.PusheeTxn.MarshalTo(data[i:])
+	n34, err := m.ResponseHeader.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n49
+	i += n34
 	return i, nil
 }
 
-func (m *ResolveIntentRequest) Marshal() (data []byte, err error) {
+func (m *RangeLookupRequest) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2488,7 +2639,7 @@ func (m *ResolveIntentRequest) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *ResolveIntentRequest) MarshalTo(data []byte) (int, error) {
+func (m *RangeLookupRequest) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -2496,25 +2647,25 @@ func (m *ResolveIntentRequest) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.Span.Size()))
-	n50, err := m.Span.MarshalTo(data[i:])
+	n35, err := m.Span.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n50
-	data[i] = 0x12
+	i += n35
+	data[i] = 0x10
 	i++
-	i = encodeVarintApi(data, i, uint64(m.IntentTxn.Size()))
-	n51, err := m.IntentTxn.MarshalTo(data[i:])
-	if err != nil {
-		return 0, err
-	}
-	i += n51
+	i = encodeVarintApi(data, i, uint64(m.MaxRanges))
 	data[i] = 0x18
 	i++
-	i = encodeVarintApi(data, i, uint64(m.Status))
+	if m.ConsiderIntents {
+		data[i] = 1
+	} else {
+		data[i] = 0
+	}
+	i++
 	data[i] = 0x20
 	i++
-	if m.Poison {
+	if m.Reverse {
 		data[i] = 1
 	} else {
 		data[i] = 0
@@ -2523,7 +2674,7 @@ func (m *ResolveIntentRequest) MarshalTo(data []byte) (int, error) {
 	return i, nil
 }
 
-func (m *ResolveIntentResponse) Marshal() (data []byte, err error) {
+func (m *RangeLookupResponse) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2533,7 +2684,7 @@ func (m *ResolveIntentResponse) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *ResolveIntentResponse) MarshalTo(data []byte) (int, error) {
+func (m *RangeLookupResponse) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -2541,15 +2692,27 @@ func (m *ResolveIntentResponse) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.ResponseHeader.Size()))
-	n52, err := m.ResponseHeader.MarshalTo(data[i:])
+	n36, err := m.ResponseHeader.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n52
+	i += n36
+	if len(m.Ranges) > 0 {
+		for _, msg := range m.Ranges {
+			data[i] = 0x12
+			i++
+			i = encodeVarintApi(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
 	return i, nil
 }
 
-func (m *ResolveIntentRangeRequest) Marshal() (data []byte, err error) {
+func (m *HeartbeatTxnRequest) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2559,7 +2722,7 @@ func (m *ResolveIntentRangeRequest) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *ResolveIntentRangeRequest) MarshalTo(data []byte) (int, error) {
+func (m *HeartbeatTxnRequest) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -2567,34 +2730,23 @@ func (m *ResolveIntentRangeRequest) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.Span.Size()))
-	n53, err := m.Span.MarshalTo(data[i:])
+	n37, err := m.Span.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n53
+	i += n37
 	data[i] = 0x12
 	i++
-	i = encodeVarintApi(data, i, uint64(m.IntentTxn.Size()))
-	n54, err := m.IntentTxn.MarshalTo(data[i:])
+	i = encodeVarintApi(data, i, uint64(m.Now.Size()))
+	n38, err := m.Now.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n54
-	data[i] = 0x18
-	i++
-	i = encodeVarintApi(data, i, uint64(m.Status))
-	data[i] = 0x20
-	i++
-	if m.Poison {
-		data[i] = 1
-	} else {
-		data[i] = 0
-	}
-	i++
+	i += n38
 	return i, nil
 }
 
-func (m *NoopResponse) Marshal() (data []byte, err error) {
+func (m *HeartbeatTxnResponse) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2604,7 +2756,7 @@ func (m *NoopResponse) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *NoopResponse) MarshalTo(data []byte) (int, error) {
+func (m *HeartbeatTxnResponse) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -2612,15 +2764,15 @@ func (m *NoopResponse) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.ResponseHeader.Size()))
-	n55, err := m.ResponseHeader.MarshalTo(data[i:])
+	n39, err := m.ResponseHeader.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n55
+	i += n39
 	return i, nil
 }
 
-func (m *NoopRequest) Marshal() (data []byte, err error) {
+func (m *GCRequest) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2630,7 +2782,7 @@ func (m *NoopRequest) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *NoopRequest) MarshalTo(data []byte) (int, error) {
+func (m *GCRequest) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -2638,16 +2790,28 @@ func (m *NoopRequest) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.Span.Size()))
-	n56, err := m.Span.MarshalTo(data[i:])
+	n40, err := m.Span.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n56
-	return i, nil
-}
-
-func (m *ResolveIntentRangeResponse) Marshal() (data []byte, err error) {
-	size := m.Size()
+	i += n40
+	if len(m.Keys) > 0 {
+		for _, msg := range m.Keys {
+			data[i] = 0x1a
+			i++
+			i = encodeVarintApi(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *GCRequest_GCKey) Marshal() (data []byte, err error) {
+	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
 	if err != nil {
@@ -2656,7 +2820,39 @@ func (m *ResolveIntentRangeResponse) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *ResolveIntentRangeResponse) MarshalTo(data []byte) (int, error) {
+func (m *GCRequest_GCKey) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Key != nil {
+		data[i] = 0xa
+		i++
+		i = encodeVarintApi(data, i, uint64(len(m.Key)))
+		i += copy(data[i:], m.Key)
+	}
+	data[i] = 0x12
+	i++
+	i = encodeVarintApi(data, i, uint64(m.Timestamp.Size()))
+	n41, err := m.Timestamp.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n41
+	return i, nil
+}
+
+func (m *GCResponse) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *GCResponse) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -2664,15 +2860,15 @@ func (m *ResolveIntentRangeResponse) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.ResponseHeader.Size()))
-	n57, err := m.ResponseHeader.MarshalTo(data[i:])
+	n42, err := m.ResponseHeader.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n57
+	i += n42
 	return i, nil
 }
 
-func (m *MergeRequest) Marshal() (data []byte, err error) {
+func (m *PushTxnRequest) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2682,7 +2878,7 @@ func (m *MergeRequest) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *MergeRequest) MarshalTo(data []byte) (int, error) {
+func (m *PushTxnRequest) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -2690,23 +2886,50 @@ func (m *MergeRequest) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.Span.Size()))
-	n58, err := m.Span.MarshalTo(data[i:])
+	n43, err := m.Span.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n58
+	i += n43
 	data[i] = 0x12
 	i++
-	i = encodeVarintApi(data, i, uint64(m.Value.Size()))
-	n59, err := m.Value.MarshalTo(data[i:])
+	i = encodeVarintApi(data, i, uint64(m.PusherTxn.Size()))
+	n44, err := m.PusherTxn.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n59
+	i += n44
+	data[i] = 0x1a
+	i++
+	i = encodeVarintApi(data, i, uint64(m.PusheeTxn.Size()))
+	n45, err := m.PusheeTxn.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n45
+	data[i] = 0x22
+	i++
+	i = encodeVarintApi(data, i, uint64(m.PushTo.Size()))
+	n46, err := m.PushTo.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n46
+	data[i] = 0x2a
+	i++
+	i = encodeVarintApi(data, i, uint64(m.Now.Size()))
+	n47, err := m.Now.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n47
+	data[i] = 0x30
+	i++
+	i = encodeVarintApi(data, i, uint64(m.PushType))
 	return i, nil
 }
 
-func (m *MergeResponse) Marshal() (data []byte, err error) {
+func (m *PushTxnResponse) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2716,7 +2939,7 @@ func (m *MergeResponse) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *MergeResponse) MarshalTo(data []byte) (int, error) {
+func (m *PushTxnResponse) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -2724,15 +2947,23 @@ func (m *MergeResponse) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.ResponseHeader.Size()))
-	n60, err := m.ResponseHeader.MarshalTo(data[i:])
+	n48, err := m.ResponseHeader.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n60
+	i += n48
+	data[i] = 0x12
+	i++
+	i = encodeVarintApi(data, i, uint64(m.PusheeTxn.Size()))
+	n49, err := m.PusheeTxn.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n49
 	return i, nil
 }
 
-func (m *TruncateLogRequest) Marshal() (data []byte, err error) {
+func (m *ResolveIntentRequest) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2742,7 +2973,7 @@ func (m *TruncateLogRequest) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *TruncateLogRequest) MarshalTo(data []byte) (int, error) {
+func (m *ResolveIntentRequest) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -2750,21 +2981,34 @@ func (m *TruncateLogRequest) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.Span.Size()))
-	n61, err := m.Span.MarshalTo(data[i:])
+	n50, err := m.Span.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n61
-	data[i] = 0x10
+	i += n50
+	data[i] = 0x12
 	i++
-	i = encodeVarintApi(data, i, uint64(m.Index))
+	i = encodeVarintApi(data, i, uint64(m.IntentTxn.Size()))
+	n51, err := m.IntentTxn.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n51
 	data[i] = 0x18
 	i++
-	i = encodeVarintApi(data, i, uint64(m.RangeID))
+	i = encodeVarintApi(data, i, uint64(m.Status))
+	data[i] = 0x20
+	i++
+	if m.Poison {
+		data[i] = 1
+	} else {
+		data[i] = 0
+	}
+	i++
 	return i, nil
 }
 
-func (m *TruncateLogResponse) Marshal() (data []byte, err error) {
+func (m *ResolveIntentResponse) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2774,7 +3018,7 @@ func (m *TruncateLogResponse) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *TruncateLogResponse) MarshalTo(data []byte) (int, error) {
+func (m *ResolveIntentResponse) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -2782,15 +3026,15 @@ func (m *TruncateLogResponse) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.ResponseHeader.Size()))
-	n62, err := m.ResponseHeader.MarshalTo(data[i:])
+	n52, err := m.ResponseHeader.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n62
+	i += n52
 	return i, nil
 }
 
-func (m *LeaderLeaseRequest) Marshal() (data []byte, err error) {
+func (m *ResolveIntentRangeRequest) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2800,7 +3044,7 @@ func (m *LeaderLeaseRequest) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *LeaderLeaseRequest) MarshalTo(data []byte) (int, error) {
+func (m *ResolveIntentRangeRequest) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -2808,23 +3052,34 @@ func (m *LeaderLeaseRequest) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.Span.Size()))
-	n63, err := m.Span.MarshalTo(data[i:])
+	n53, err := m.Span.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n63
+	i += n53
 	data[i] = 0x12
 	i++
-	i = encodeVarintApi(data, i, uint64(m.Lease.Size()))
-	n64, err := m.Lease.MarshalTo(data[i:])
+	i = encodeVarintApi(data, i, uint64(m.IntentTxn.Size()))
+	n54, err := m.IntentTxn.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n64
+	i += n54
+	data[i] = 0x18
+	i++
+	i = encodeVarintApi(data, i, uint64(m.Status))
+	data[i] = 0x20
+	i++
+	if m.Poison {
+		data[i] = 1
+	} else {
+		data[i] = 0
+	}
+	i++
 	return i, nil
 }
 
-func (m *LeaderLeaseResponse) Marshal() (data []byte, err error) {
+func (m *NoopResponse) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2834,7 +3089,7 @@ func (m *LeaderLeaseResponse) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *LeaderLeaseResponse) MarshalTo(data []byte) (int, error) {
+func (m *NoopResponse) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -2842,15 +3097,15 @@ func (m *LeaderLeaseResponse) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.ResponseHeader.Size()))
-	n65, err := m.ResponseHeader.MarshalTo(data[i:])
+	n55, err := m.ResponseHeader.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n65
+	i += n55
 	return i, nil
 }
 
-func (m *ComputeChecksumRequest) Marshal() (data []byte, err error) {
+func (m *NoopRequest) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2860,7 +3115,7 @@ func (m *ComputeChecksumRequest) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *ComputeChecksumRequest) MarshalTo(data []byte) (int, error) {
+func (m *NoopRequest) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -2868,26 +3123,15 @@ func (m *ComputeChecksumRequest) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.Span.Size()))
-	n66, err := m.Span.MarshalTo(data[i:])
-	if err != nil {
-		return 0, err
-	}
-	i += n66
-	data[i] = 0x10
-	i++
-	i = encodeVarintApi(data, i, uint64(m.Version))
-	data[i] = 0x1a
-	i++
-	i = encodeVarintApi(data, i, uint64(m.ChecksumID.Size()))
-	n67, err := m.ChecksumID.MarshalTo(data[i:])
+	n56, err := m.Span.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n67
+	i += n56
 	return i, nil
 }
 
-func (m *ComputeChecksumResponse) Marshal() (data []byte, err error) {
+func (m *ResolveIntentRangeResponse) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2897,7 +3141,7 @@ func (m *ComputeChecksumResponse) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *ComputeChecksumResponse) MarshalTo(data []byte) (int, error) {
+func (m *ResolveIntentRangeResponse) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -2905,15 +3149,15 @@ func (m *ComputeChecksumResponse) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.ResponseHeader.Size()))
-	n68, err := m.ResponseHeader.MarshalTo(data[i:])
+	n57, err := m.ResponseHeader.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n68
+	i += n57
 	return i, nil
 }
 
-func (m *VerifyChecksumRequest) Marshal() (data []byte, err error) {
+func (m *MergeRequest) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2923,7 +3167,7 @@ func (m *VerifyChecksumRequest) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *VerifyChecksumRequest) MarshalTo(data []byte) (int, error) {
+func (m *MergeRequest) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -2931,32 +3175,23 @@ func (m *VerifyChecksumRequest) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.Span.Size()))
-	n69, err := m.Span.MarshalTo(data[i:])
+	n58, err := m.Span.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n69
-	data[i] = 0x10
-	i++
-	i = encodeVarintApi(data, i, uint64(m.Version))
-	data[i] = 0x1a
+	i += n58
+	data[i] = 0x12
 	i++
-	i = encodeVarintApi(data, i, uint64(m.ChecksumID.Size()))
-	n70, err := m.ChecksumID.MarshalTo(data[i:])
+	i = encodeVarintApi(data, i, uint64(m.Value.Size()))
+	n59, err := m.Value.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n70
-	if m.Checksum != nil {
-		data[i] = 0x22
-		i++
-		i = encodeVarintApi(data, i, uint64(len(m.Checksum)))
-		i += copy(data[i:], m.Checksum)
-	}
+	i += n59
 	return i, nil
 }
 
-func (m *VerifyChecksumResponse) Marshal() (data []byte, err error) {
+func (m *MergeResponse) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2966,7 +3201,7 @@ func (m *VerifyChecksumResponse) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *VerifyChecksumResponse) MarshalTo(data []byte) (int, error) {
+func (m *MergeResponse) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -2974,15 +3209,15 @@ func (m *VerifyChecksumResponse) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintApi(data, i, uint64(m.ResponseHeader.Size()))
-	n71, err := m.ResponseHeader.MarshalTo(data[i:])
+	n60, err := m.ResponseHeader.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n71
+	i += n60
 	return i, nil
 }
 
-func (m *RequestUnion) Marshal() (data []byte, err error) {
+func (m *TruncateLogRequest) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -2992,285 +3227,247 @@ func (m *RequestUnion) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *RequestUnion) MarshalTo(data []byte) (int, error) {
+func (m *TruncateLogRequest) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
-	if m.Get != nil {
-		data[i] = 0xa
-		i++
-		i = encodeVarintApi(data, i, uint64(m.Get.Size()))
-		n72, err := m.Get.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n72
-	}
-	if m.Put != nil {
-		data[i] = 0x12
-		i++
-		i = encodeVarintApi(data, i, uint64(m.Put.Size()))
-		n73, err := m.Put.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n73
-	}
-	if m.ConditionalPut != nil {
-		data[i] = 0x1a
-		i++
-		i = encodeVarintApi(data, i, uint64(m.ConditionalPut.Size()))
-		n74, err := m.ConditionalPut.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n74
+	data[i] = 0xa
+	i++
+	i = encodeVarintApi(data, i, uint64(m.Span.Size()))
+	n61, err := m.Span.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
 	}
-	if m.Increment != nil {
-		data[i] = 0x22
-		i++
-		i = encodeVarintApi(data, i, uint64(m.Increment.Size()))
-		n75, err := m.Increment.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n75
+	i += n61
+	data[i] = 0x10
+	i++
+	i = encodeVarintApi(data, i, uint64(m.Index))
+	data[i] = 0x18
+	i++
+	i = encodeVarintApi(data, i, uint64(m.RangeID))
+	return i, nil
+}
+
+func (m *TruncateLogResponse) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
 	}
-	if m.Delete != nil {
-		data[i] = 0x2a
-		i++
-		i = encodeVarintApi(data, i, uint64(m.Delete.Size()))
-		n76, err := m.Delete.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n76
+	return data[:n], nil
+}
+
+func (m *TruncateLogResponse) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	data[i] = 0xa
+	i++
+	i = encodeVarintApi(data, i, uint64(m.ResponseHeader.Size()))
+	n62, err := m.ResponseHeader.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
 	}
-	if m.DeleteRange != nil {
-		data[i] = 0x32
-		i++
-		i = encodeVarintApi(data, i, uint64(m.DeleteRange.Size()))
-		n77, err := m.DeleteRange.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n77
+	i += n62
+	return i, nil
+}
+
+func (m *LeaderLeaseRequest) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
 	}
-	if m.Scan != nil {
-		data[i] = 0x3a
-		i++
-		i = encodeVarintApi(data, i, uint64(m.Scan.Size()))
-		n78, err := m.Scan.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n78
+	return data[:n], nil
+}
+
+func (m *LeaderLeaseRequest) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	data[i] = 0xa
+	i++
+	i = encodeVarintApi(data, i, uint64(m.Span.Size()))
+	n63, err := m.Span.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
 	}
-	if m.BeginTransaction != nil {
-		data[i] = 0x42
-		i++
-		i = encodeVarintApi(data, i, uint64(m.BeginTransaction.Size()))
-		n79, err := m.BeginTransaction.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n79
+	i += n63
+	data[i] = 0x12
+	i++
+	i = encodeVarintApi(data, i, uint64(m.Lease.Size()))
+	n64, err := m.Lease.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
 	}
-	if m.EndTransaction != nil {
-		data[i] = 0x4a
-		i++
-		i = encodeVarintApi(data, i, uint64(m.EndTransaction.Size()))
-		n80, err := m.EndTransaction.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n80
+	i += n64
+	return i, nil
+}
+
+func (m *LeaderLeaseResponse) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
 	}
-	if m.AdminSplit != nil {
-		data[i] = 0x52
-		i++
-		i = encodeVarintApi(data, i, uint64(m.AdminSplit.Size()))
-		n81, err := m.AdminSplit.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n81
+	return data[:n], nil
+}
+
+func (m *LeaderLeaseResponse) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	data[i] = 0xa
+	i++
+	i = encodeVarintApi(data, i, uint64(m.ResponseHeader.Size()))
+	n65, err := m.ResponseHeader.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
 	}
-	if m.AdminMerge != nil {
-		data[i] = 0x5a
-		i++
-		i = encodeVarintApi(data, i, uint64(m.AdminMerge.Size()))
-		n82, err := m.AdminMerge.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n82
+	i += n65
+	return i, nil
+}
+
+func (m *ComputeChecksumRequest) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
 	}
-	if m.HeartbeatTxn != nil {
-		data[i] = 0x62
-		i++
-		i = encodeVarintApi(data, i, uint64(m.HeartbeatTxn.Size()))
-		n83, err := m.HeartbeatTxn.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n83
+	return data[:n], nil
+}
+
+func (m *ComputeChecksumRequest) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	data[i] = 0xa
+	i++
+	i = encodeVarintApi(data, i, uint64(m.Span.Size()))
+	n66, err := m.Span.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
 	}
-	if m.Gc != nil {
-		data[i] = 0x6a
-		i++
-		i = encodeVarintApi(data, i, uint64(m.Gc.Size()))
-		n84, err := m.Gc.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n84
+	i += n66
+	data[i] = 0x10
+	i++
+	i = encodeVarintApi(data, i, uint64(m.Version))
+	data[i] = 0x1a
+	i++
+	i = encodeVarintApi(data, i, uint64(m.ChecksumID.Size()))
+	n67, err := m.ChecksumID.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
 	}
-	if m.PushTxn != nil {
-		data[i] = 0x72
-		i++
-		i = encodeVarintApi(data, i, uint64(m.PushTxn.Size()))
-		n85, err := m.PushTxn.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n85
+	i += n67
+	return i, nil
+}
+
+func (m *ComputeChecksumResponse) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
 	}
-	if m.RangeLookup != nil {
-		data[i] = 0x7a
-		i++
-		i = encodeVarintApi(data, i, uint64(m.RangeLookup.Size()))
-		n86, err := m.RangeLookup.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n86
-	}
-	if m.ResolveIntent != nil {
-		data[i] = 0x82
-		i++
-		data[i] = 0x1
-		i++
-		i = encodeVarintApi(data, i, uint64(m.ResolveIntent.Size()))
-		n87, err := m.ResolveIntent.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n87
-	}
-	if m.ResolveIntentRange != nil {
-		data[i] = 0x8a
-		i++
-		data[i] = 0x1
-		i++
-		i = encodeVarintApi(data, i, uint64(m.ResolveIntentRange.Size()))
-		n88, err := m.ResolveIntentRange.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n88
-	}
-	if m.Merge != nil {
-		data[i] = 0x92
-		i++
-		data[i] = 0x1
-		i++
-		i = encodeVarintApi(data, i, uint64(m.Merge.Size()))
-		n89, err := m.Merge.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n89
-	}
-	if m.TruncateLog != nil {
-		data[i] = 0x9a
-		i++
-		data[i] = 0x1
-		i++
-		i = encodeVarintApi(data, i, uint64(m.TruncateLog.Size()))
-		n90, err := m.TruncateLog.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n90
+	return data[:n], nil
+}
+
+func (m *ComputeChecksumResponse) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	data[i] = 0xa
+	i++
+	i = encodeVarintApi(data, i, uint64(m.ResponseHeader.Size()))
+	n68, err := m.ResponseHeader.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
 	}
-	if m.LeaderLease != nil {
-		data[i] = 0xa2
-		i++
-		data[i] = 0x1
-		i++
-		i = encodeVarintApi(data, i, uint64(m.LeaderLease.Size()))
-		n91, err := m.LeaderLease.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n91
+	i += n68
+	return i, nil
+}
+
+func (m *VerifyChecksumRequest) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
 	}
-	if m.ReverseScan != nil {
-		data[i] = 0xaa
-		i++
-		data[i] = 0x1
-		i++
-		i = encodeVarintApi(data, i, uint64(m.ReverseScan.Size()))
-		n92, err := m.ReverseScan.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n92
+	return data[:n], nil
+}
+
+func (m *VerifyChecksumRequest) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	data[i] = 0xa
+	i++
+	i = encodeVarintApi(data, i, uint64(m.Span.Size()))
+	n69, err := m.Span.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
 	}
-	if m.ComputeChecksum != nil {
-		data[i] = 0xb2
-		i++
-		data[i] = 0x1
-		i++
-		i = encodeVarintApi(data, i, uint64(m.ComputeChecksum.Size()))
-		n93, err := m.ComputeChecksum.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n93
+	i += n69
+	data[i] = 0x10
+	i++
+	i = encodeVarintApi(data, i, uint64(m.Version))
+	data[i] = 0x1a
+	i++
+	i = encodeVarintApi(data, i, uint64(m.ChecksumID.Size()))
+	n70, err := m.ChecksumID.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
 	}
-	if m.VerifyChecksum != nil {
-		data[i] = 0xba
-		i++
-		data[i] = 0x1
+	i += n70
+	if m.Checksum != nil {
+		data[i] = 0x22
 		i++
-		i = encodeVarintApi(data, i, uint64(m.VerifyChecksum.Size()))
-		n94, err := m.VerifyChecksum.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n94
+		i = encodeVarintApi(data, i, uint64(len(m.Checksum)))
+		i += copy(data[i:], m.Checksum)
 	}
-	if m.CheckConsistency != nil {
-		data[i] = 0xc2
-		i++
-		data[i] = 0x1
-		i++
-		i = encodeVarintApi(data, i, uint64(m.CheckConsistency.Size()))
-		n95, err := m.CheckConsistency.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n95
+	return i, nil
+}
+
+func (m *VerifyChecksumResponse) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
 	}
-	if m.Noop != nil {
-		data[i] = 0xca
-		i++
-		data[i] = 0x1
-		i++
-		i = encodeVarintApi(data, i, uint64(m.Noop.Size()))
-		n96, err := m.Noop.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n96
+	return data[:n], nil
+}
+
+func (m *VerifyChecksumResponse) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	data[i] = 0xa
+	i++
+	i = encodeVarintApi(data, i, uint64(m.ResponseHeader.Size()))
+	n71, err := m.ResponseHeader.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
 	}
+	i += n71
 	return i, nil
 }
 
-func (m *ResponseUnion) Marshal() (data []byte, err error) {
+func (m *RequestUnion) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -3280,7 +3477,7 @@ func (m *ResponseUnion) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *ResponseUnion) MarshalTo(data []byte) (int, error) {
+func (m *RequestUnion) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
@@ -3289,151 +3486,151 @@ func (m *ResponseUnion) MarshalTo(data []byte) (int, error) {
 		data[i] = 0xa
 		i++
 		i = encodeVarintApi(data, i, uint64(m.Get.Size()))
-		n97, err := m.Get.MarshalTo(data[i:])
+		n72, err := m.Get.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n97
+		i += n72
 	}
 	if m.Put != nil {
 		data[i] = 0x12
 		i++
 		i = encodeVarintApi(data, i, uint64(m.Put.Size()))
-		n98, err := m.Put.MarshalTo(data[i:])
+		n73, err := m.Put.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n98
+		i += n73
 	}
 	if m.ConditionalPut != nil {
 		data[i] = 0x1a
 		i++
 		i = encodeVarintApi(data, i, uint64(m.ConditionalPut.Size()))
-		n99, err := m.ConditionalPut.MarshalTo(data[i:])
+		n74, err := m.ConditionalPut.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n99
+		i += n74
 	}
 	if m.Increment != nil {
 		data[i] = 0x22
 		i++
 		i = encodeVarintApi(data, i, uint64(m.Increment.Size()))
-		n100, err := m.Increment.MarshalTo(data[i:])
+		n75, err := m.Increment.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n100
+		i += n75
 	}
 	if m.Delete != nil {
 		data[i] = 0x2a
 		i++
 		i = encodeVarintApi(data, i, uint64(m.Delete.Size()))
-		n101, err := m.Delete.MarshalTo(data[i:])
+		n76, err := m.Delete.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n101
+		i += n76
 	}
 	if m.DeleteRange != nil {
 		data[i] = 0x32
 		i++
 		i = encodeVarintApi(data, i, uint64(m.DeleteRange.Size()))
-		n102, err := m.DeleteRange.MarshalTo(data[i:])
+		n77, err := m.DeleteRange.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n102
+		i += n77
 	}
 	if m.Scan != nil {
 		data[i] = 0x3a
 		i++
 		i = encodeVarintApi(data, i, uint64(m.Scan.Size()))
-		n103, err := m.Scan.MarshalTo(data[i:])
+		n78, err := m.Scan.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n103
+		i += n78
 	}
 	if m.BeginTransaction != nil {
 		data[i] = 0x42
 		i++
 		i = encodeVarintApi(data, i, uint64(m.BeginTransaction.Size()))
-		n104, err := m.BeginTransaction.MarshalTo(data[i:])
+		n79, err := m.BeginTransaction.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n104
+		i += n79
 	}
 	if m.EndTransaction != nil {
 		data[i] = 0x4a
 		i++
 		i = encodeVarintApi(data, i, uint64(m.EndTransaction.Size()))
-		n105, err := m.EndTransaction.MarshalTo(data[i:])
+		n80, err := m.EndTransaction.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n105
+		i += n80
 	}
 	if m.AdminSplit != nil {
 		data[i] = 0x52
 		i++
 		i = encodeVarintApi(data, i, uint64(m.AdminSplit.Size()))
-		n106, err := m.AdminSplit.MarshalTo(data[i:])
+		n81, err := m.AdminSplit.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n106
+		i += n81
 	}
 	if m.AdminMerge != nil {
 		data[i] = 0x5a
 		i++
 		i = encodeVarintApi(data, i, uint64(m.AdminMerge.Size()))
-		n107, err := m.AdminMerge.MarshalTo(data[i:])
+		n82, err := m.AdminMerge.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n107
+		i += n82
 	}
 	if m.HeartbeatTxn != nil {
 		data[i] = 0x62
 		i++
 		i = encodeVarintApi(data, i, uint64(m.HeartbeatTxn.Size()))
-		n108, err := m.HeartbeatTxn.MarshalTo(data[i:])
+		n83, err := m.HeartbeatTxn.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n108
+		i += n83
 	}
 	if m.Gc != nil {
 		data[i] = 0x6a
 		i++
 		i = encodeVarintApi(data, i, uint64(m.Gc.Size()))
-		n109, err := m.Gc.MarshalTo(data[i:])
+		n84, err := m.Gc.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n109
+		i += n84
 	}
 	if m.PushTxn != nil {
 		data[i] = 0x72
 		i++
 		i = encodeVarintApi(data, i, uint64(m.PushTxn.Size()))
-		n110, err := m.PushTxn.MarshalTo(data[i:])
+		n85, err := m.PushTxn.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n110
+		i += n85
 	}
 	if m.RangeLookup != nil {
 		data[i] = 0x7a
 		i++
 		i = encodeVarintApi(data, i, uint64(m.RangeLookup.Size()))
-		n111, err := m.RangeLookup.MarshalTo(data[i:])
+		n86, err := m.RangeLookup.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n111
+		i += n86
 	}
 	if m.ResolveIntent != nil {
 		data[i] = 0x82
@@ -3441,11 +3638,11 @@ func (m *ResponseUnion) MarshalTo(data []byte) (int, error) {
 		data[i] = 0x1
 		i++
 		i = encodeVarintApi(data, i, uint64(m.ResolveIntent.Size()))
-		n112, err := m.ResolveIntent.MarshalTo(data[i:])
+		n87, err := m.ResolveIntent.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n112
+		i += n87
 	}
 	if m.ResolveIntentRange != nil {
 		data[i] = 0x8a
@@ -3453,11 +3650,11 @@ func (m *ResponseUnion) MarshalTo(data []byte) (int, error) {
 		data[i] = 0x1
 		i++
 		i = encodeVarintApi(data, i, uint64(m.ResolveIntentRange.Size()))
-		n113, err := m.ResolveIntentRange.MarshalTo(data[i:])
+		n88, err := m.ResolveIntentRange.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n113
+		i += n88
 	}
 	if m.Merge != nil {
 		data[i] = 0x92
@@ -3465,11 +3662,11 @@ func (m *ResponseUnion) MarshalTo(data []byte) (int, error) {
 		data[i] = 0x1
 		i++
 		i = encodeVarintApi(data, i, uint64(m.Merge.Size()))
-		n114, err := m.Merge.MarshalTo(data[i:])
+		n89, err := m.Merge.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n114
+		i += n89
 	}
 	if m.TruncateLog != nil {
 		data[i] = 0x9a
@@ -3477,11 +3674,11 @@ func (m *ResponseUnion) MarshalTo(data []byte) (int, error) {
 		data[i] = 0x1
 		i++
 		i = encodeVarintApi(data, i, uint64(m.TruncateLog.Size()))
-		n115, err := m.TruncateLog.MarshalTo(data[i:])
+		n90, err := m.TruncateLog.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n115
+		i += n90
 	}
 	if m.LeaderLease != nil {
 		data[i] = 0xa2
@@ -3489,11 +3686,11 @@ func (m *ResponseUnion) MarshalTo(data []byte) (int, error) {
 		data[i] = 0x1
 		i++
 		i = encodeVarintApi(data, i, uint64(m.LeaderLease.Size()))
-		n116, err := m.LeaderLease.MarshalTo(data[i:])
+		n91, err := m.LeaderLease.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n116
+		i += n91
 	}
 	if m.ReverseScan != nil {
 		data[i] = 0xaa
@@ -3501,11 +3698,11 @@ func (m *ResponseUnion) MarshalTo(data []byte) (int, error) {
 		data[i] = 0x1
 		i++
 		i = encodeVarintApi(data, i, uint64(m.ReverseScan.Size()))
-		n117, err := m.ReverseScan.MarshalTo(data[i:])
+		n92, err := m.ReverseScan.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n117
+		i += n92
 	}
 	if m.ComputeChecksum != nil {
 		data[i] = 0xb2
@@ -3513,11 +3710,11 @@ func (m *ResponseUnion) MarshalTo(data []byte) (int, error) {
 		data[i] = 0x1
 		i++
 		i = encodeVarintApi(data, i, uint64(m.ComputeChecksum.Size()))
-		n118, err := m.ComputeChecksum.MarshalTo(data[i:])
+		n93, err := m.ComputeChecksum.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n118
+		i += n93
 	}
 	if m.VerifyChecksum != nil {
 		data[i] = 0xba
@@ -3525,11 +3722,11 @@ func (m *ResponseUnion) MarshalTo(data []byte) (int, error) {
 		data[i] = 0x1
 		i++
 		i = encodeVarintApi(data, i, uint64(m.VerifyChecksum.Size()))
-		n119, err := m.VerifyChecksum.MarshalTo(data[i:])
+		n94, err := m.VerifyChecksum.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n119
+		i += n94
 	}
 	if m.CheckConsistency != nil {
 		data[i] = 0xc2
@@ -3537,11 +3734,11 @@ func (m *ResponseUnion) MarshalTo(data []byte) (int, error) {
 		data[i] = 0x1
 		i++
 		i = encodeVarintApi(data, i, uint64(m.CheckConsistency.Size()))
-		n120, err := m.CheckConsistency.MarshalTo(data[i:])
+		n95, err := m.CheckConsistency.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n120
+		i += n95
 	}
 	if m.Noop != nil {
 		data[i] = 0xca
@@ -3549,82 +3746,64 @@ func (m *ResponseUnion) MarshalTo(data []byte) (int, error) {
 		data[i] = 0x1
 		i++
 		i = encodeVarintApi(data, i, uint64(m.Noop.Size()))
-		n121, err := m.Noop.MarshalTo(data[i:])
+		n96, err := m.Noop.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n121
-	}
-	return i, nil
-}
-
-func (m *Header) Marshal() (data []byte, err error) {
-	size := m.Size()
-	data = make([]byte, size)
-	n, err := m.MarshalTo(data)
-	if err != nil {
-		return nil, err
+		i += n96
 	}
-	return data[:n], nil
-}
-
-func (m *Header) MarshalTo(data []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	data[i] = 0xa
-	i++
-	i = encodeVarintApi(data, i, uint64(m.Timestamp.Size()))
-	n122, err := m.Timestamp.MarshalTo(data[i:])
-	if err != nil {
-		return 0, err
+	if m.ClearRange != nil {
+		data[i] = 0xd2
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintApi(data, i, uint64(m.ClearRange.Size()))
+		n132, err := m.ClearRange.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n132
 	}
-	i += n122
-	data[i] = 0x12
-	i++
-	i = encodeVarintApi(data, i, uint64(m.Replica.Size()))
-	n123, err := m.Replica.MarshalTo(data[i:])
-	if err != nil {
-		return 0, err
+	if m.LeaseInfo != nil {
+		data[i] = 0xda
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintApi(data, i, uint64(m.LeaseInfo.Size()))
+		n137, err := m.LeaseInfo.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n137
 	}
-	i += n123
-	data[i] = 0x18
-	i++
-	i = encodeVarintApi(data, i, uint64(m.RangeID))
-	data[i] = 0x21
-	i++
-	i = encodeFixed64Api(data, i, uint64(math.Float64bits(float64(m.UserPriority))))
-	if m.Txn != nil {
-		data[i] = 0x2a
+	if m.Export != nil {
+		data[i] = 0xe2
 		i++
-		i = encodeVarintApi(data, i, uint64(m.Txn.Size()))
-		n124, err := m.Txn.MarshalTo(data[i:])
+		data[i] = 0x1
+		i++
+		i = encodeVarintApi(data, i, uint64(m.Export.Size()))
+		n142, err := m.Export.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n124
+		i += n142
 	}
-	data[i] = 0x30
-	i++
-	i = encodeVarintApi(data, i, uint64(m.ReadConsistency))
-	if m.Trace != nil {
-		data[i] = 0x3a
+	if m.RecomputeStats != nil {
+		data[i] = 0xea
 		i++
-		i = encodeVarintApi(data, i, uint64(m.Trace.Size()))
-		n125, err := m.Trace.MarshalTo(data[i:])
+		data[i] = 0x1
+		i++
+		i = encodeVarintApi(data, i, uint64(m.RecomputeStats.Size()))
+		n146, err := m.RecomputeStats.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n125
+		i += n146
 	}
-	data[i] = 0x40
-	i++
-	i = encodeVarintApi(data, i, uint64(m.MaxScanResults))
 	return i, nil
 }
 
-func (m *BatchRequest) Marshal() (data []byte, err error) {
+func (m *ResponseUnion) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
 	n, err := m.MarshalTo(data)
@@ -3634,544 +3813,622 @@ func (m *BatchRequest) Marshal() (data []byte, err error) {
 	return data[:n], nil
 }
 
-func (m *BatchRequest) MarshalTo(data []byte) (int, error) {
+func (m *ResponseUnion) MarshalTo(data []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
-	data[i] = 0xa
-	i++
-	i = encodeVarintApi(data, i, uint64(m.Header.Size()))
-	n126, err := m.Header.MarshalTo(data[i:])
-	if err != nil {
-		return 0, err
-	}
-	i += n126
-	if len(m.Requests) > 0 {
-		for _, msg := range m.Requests {
-			data[i] = 0x12
-			i++
-			i = encodeVarintApi(data, i, uint64(msg.Size()))
-			n, err := msg.MarshalTo(data[i:])
-			if err != nil {
-				return 0, err
-			}
-			i += n
+	if m.Get != nil {
+		data[i] = 0xa
+		i++
+		i = encodeVarintApi(data, i, uint64(m.Get.Size()))
+		n97, err := m.Get.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
 		}
+		i += n97
 	}
-	return i, nil
-}
-
-func (m *BatchResponse) Marshal() (data []byte, err error) {
-	size := m.Size()
-	data = make([]byte, size)
-	n, err := m.MarshalTo(data)
-	if err != nil {
-		return nil, err
-	}
-	return data[:n], nil
-}
-
-func (m *BatchResponse) MarshalTo(data []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	data[i] = 0xa
-	i++
-	i = encodeVarintApi(data, i, uint64(m.BatchResponse_Header.Size()))
-	n127, err := m.BatchResponse_Header.MarshalTo(data[i:])
-	if err != nil {
-		return 0, err
+	if m.Put != nil {
+		data[i] = 0x12
+		i++
+		i = encodeVarintApi(data, i, uint64(m.Put.Size()))
+		n98, err := m.Put.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n98
 	}
-	i += n127
-	if len(m.Responses) > 0 {
-		for _, msg := range m.Responses {
-			data[i] = 0x12
-			i++
-			i = encodeVarintApi(data, i, uint64(msg.Size()))
-			n, err := msg.MarshalTo(data[i:])
-			if err != nil {
-				return 0, err
-			}
-			i += n
+	if m.ConditionalPut != nil {
+		data[i] = 0x1a
+		i++
+		i = encodeVarintApi(data, i, uint64(m.ConditionalPut.Size()))
+		n99, err := m.ConditionalPut.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
 		}
+		i += n99
 	}
-	return i, nil
-}
-
-func (m *BatchResponse_Header) Marshal() (data []byte, err error) {
-	size := m.Size()
-	data = make([]byte, size)
-	n, err := m.MarshalTo(data)
-	if err != nil {
-		return nil, err
+	if m.Increment != nil {
+		data[i] = 0x22
+		i++
+		i = encodeVarintApi(data, i, uint64(m.Increment.Size()))
+		n100, err := m.Increment.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n100
 	}
-	return data[:n], nil
-}
-
-func (m *BatchResponse_Header) MarshalTo(data []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.Error != nil {
-		data[i] = 0xa
+	if m.Delete != nil {
+		data[i] = 0x2a
 		i++
-		i = encodeVarintApi(data, i, uint64(m.Error.Size()))
-		n128, err := m.Error.MarshalTo(data[i:])
+		i = encodeVarintApi(data, i, uint64(m.Delete.Size()))
+		n101, err := m.Delete.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n128
+		i += n101
 	}
-	if m.Txn != nil {
-		data[i] = 0x1a
+	if m.DeleteRange != nil {
+		data[i] = 0x32
 		i++
-		i = encodeVarintApi(data, i, uint64(m.Txn.Size()))
-		n129, err := m.Txn.MarshalTo(data[i:])
+		i = encodeVarintApi(data, i, uint64(m.DeleteRange.Size()))
+		n102, err := m.DeleteRange.MarshalTo(data[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n129
+		i += n102
 	}
-	if len(m.CollectedSpans) > 0 {
-		for _, b := range m.CollectedSpans {
-			data[i] = 0x22
-			i++
-			i = encodeVarintApi(data, i, uint64(len(b)))
-			i += copy(data[i:], b)
+	if m.Scan != nil {
+		data[i] = 0x3a
+		i++
+		i = encodeVarintApi(data, i, uint64(m.Scan.Size()))
+		n103, err := m.Scan.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
 		}
+		i += n103
 	}
-	return i, nil
-}
-
-func encodeFixed64Api(data []byte, offset int, v uint64) int {
-	data[offset] = uint8(v)
-	data[offset+1] = uint8(v >> 8)
-	data[offset+2] = uint8(v >> 16)
-	data[offset+3] = uint8(v >> 24)
-	data[offset+4] = uint8(v >> 32)
-	data[offset+5] = uint8(v >> 40)
-	data[offset+6] = uint8(v >> 48)
-	data[offset+7] = uint8(v >> 56)
-	return offset + 8
-}
-func encodeFixed32Api(data []byte, offset int, v uint32) int {
-	data[offset] = uint8(v)
-	data[offset+1] = uint8(v >> 8)
-	data[offset+2] = uint8(v >> 16)
-	data[offset+3] = uint8(v >> 24)
-	return offset + 4
-}
-func encodeVarintApi(data []byte, offset int, v uint64) int {
-	for v >= 1<<7 {
-		data[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
+	if m.BeginTransaction != nil {
+		data[i] = 0x42
+		i++
+		i = encodeVarintApi(data, i, uint64(m.BeginTransaction.Size()))
+		n104, err := m.BeginTransaction.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n104
 	}
-	data[offset] = uint8(v)
-	return offset + 1
-}
-func (m *ResponseHeader) Size() (n int) {
-	var l int
-	_ = l
-	l = m.Timestamp.Size()
-	n += 1 + l + sovApi(uint64(l))
-	if m.Txn != nil {
-		l = m.Txn.Size()
-		n += 1 + l + sovApi(uint64(l))
+	if m.EndTransaction != nil {
+		data[i] = 0x4a
+		i++
+		i = encodeVarintApi(data, i, uint64(m.EndTransaction.Size()))
+		n105, err := m.EndTransaction.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n105
 	}
-	return n
-}
-
-func (m *GetRequest) Size() (n int) {
-	var l int
-	_ = l
-	l = m.Span.Size()
-	n += 1 + l + sovApi(uint64(l))
-	return n
-}
-
-func (m *GetResponse) Size() (n int) {
-	var l int
-	_ = l
-	l = m.ResponseHeader.Size()
-	n += 1 + l + sovApi(uint64(l))
-	if m.Value != nil {
-		l = m.Value.Size()
-		n += 1 + l + sovApi(uint64(l))
+	if m.AdminSplit != nil {
+		data[i] = 0x52
+		i++
+		i = encodeVarintApi(data, i, uint64(m.AdminSplit.Size()))
+		n106, err := m.AdminSplit.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n106
 	}
-	return n
-}
-
-func (m *PutRequest) Size() (n int) {
-	var l int
-	_ = l
-	l = m.Span.Size()
-	n += 1 + l + sovApi(uint64(l))
-	l = m.Value.Size()
-	n += 1 + l + sovApi(uint64(l))
-	return n
-}
-
-func (m *PutResponse) Size() (n int) {
-	var l int
-	_ = l
-	l = m.ResponseHeader.Size()
-	n += 1 + l + sovApi(uint64(l))
-	return n
-}
-
-func (m *ConditionalPutRequest) Size() (n int) {
-	var l int
-	_ = l
-	l = m.Span.Size()
-	n += 1 + l + sovApi(uint64(l))
-	l = m.Value.Size()
-	n += 1 + l + sovApi(uint64(l))
-	if m.ExpValue != nil {
-		l = m.ExpValue.Size()
-		n += 1 + l + sovApi(uint64(l))
+	if m.AdminMerge != nil {
+		data[i] = 0x5a
+		i++
+		i = encodeVarintApi(data, i, uint64(m.AdminMerge.Size()))
+		n107, err := m.AdminMerge.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n107
 	}
-	return n
-}
-
-func (m *ConditionalPutResponse) Size() (n int) {
-	var l int
-	_ = l
-	l = m.ResponseHeader.Size()
-	n += 1 + l + sovApi(uint64(l))
-	return n
-}
-
-func (m *IncrementRequest) Size() (n int) {
-	var l int
-	_ = l
-	l = m.Span.Size()
-	n += 1 + l + sovApi(uint64(l))
-	n += 1 + sovApi(uint64(m.Increment))
-	return n
-}
-
-func (m *IncrementResponse) Size() (n int) {
-	var l int
-	_ = l
-	l = m.ResponseHeader.Size()
-	n += 1 + l + sovApi(uint64(l))
-	n += 1 + sovApi(uint64(m.NewValue))
-	return n
-}
-
-func (m *DeleteRequest) Size() (n int) {
-	var l int
-	_ = l
-	l = m.Span.Size()
-	n += 1 + l + sovApi(uint64(l))
-	return n
-}
-
-func (m *DeleteResponse) Size() (n int) {
-	var l int
-	_ = l
-	l = m.ResponseHeader.Size()
-	n += 1 + l + sovApi(uint64(l))
-	return n
-}
-
-func (m *DeleteRangeRequest) Size() (n int) {
-	var l int
-	_ = l
-	l = m.Span.Size()
-	n += 1 + l + sovApi(uint64(l))
-	n += 1 + sovApi(uint64(m.MaxEntriesToDelete))
-	n += 2
-	return n
-}
-
-func (m *DeleteRangeResponse) Size() (n int) {
-	var l int
-	_ = l
-	l = m.ResponseHeader.Size()
-	n += 1 + l + sovApi(uint64(l))
-	if len(m.Keys) > 0 {
-		for _, b := range m.Keys {
-			l = len(b)
-			n += 1 + l + sovApi(uint64(l))
+	if m.HeartbeatTxn != nil {
+		data[i] = 0x62
+		i++
+		i = encodeVarintApi(data, i, uint64(m.HeartbeatTxn.Size()))
+		n108, err := m.HeartbeatTxn.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
 		}
+		i += n108
 	}
-	return n
-}
-
-func (m *ScanRequest) Size() (n int) {
-	var l int
-	_ = l
-	l = m.Span.Size()
-	n += 1 + l + sovApi(uint64(l))
-	n += 1 + sovApi(uint64(m.MaxResults))
-	return n
-}
-
-func (m *ScanResponse) Size() (n int) {
-	var l int
-	_ = l
-	l = m.ResponseHeader.Size()
-	n += 1 + l + sovApi(uint64(l))
-	if len(m.Rows) > 0 {
-		for _, e := range m.Rows {
-			l = e.Size()
-			n += 1 + l + sovApi(uint64(l))
+	if m.Gc != nil {
+		data[i] = 0x6a
+		i++
+		i = encodeVarintApi(data, i, uint64(m.Gc.Size()))
+		n109, err := m.Gc.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
 		}
+		i += n109
 	}
-	return n
-}
-
-func (m *ReverseScanRequest) Size() (n int) {
-	var l int
-	_ = l
-	l = m.Span.Size()
-	n += 1 + l + sovApi(uint64(l))
-	n += 1 + sovApi(uint64(m.MaxResults))
-	return n
-}
-
-func (m *ReverseScanResponse) Size() (n int) {
-	var l int
-	_ = l
-	l = m.ResponseHeader.Size()
-	n += 1 + l + sovApi(uint64(l))
-	if len(m.Rows) > 0 {
-		for _, e := range m.Rows {
-			l = e.Size()
-			n += 1 + l + sovApi(uint64(l))
+	if m.PushTxn != nil {
+		data[i] = 0x72
+		i++
+		i = encodeVarintApi(data, i, uint64(m.PushTxn.Size()))
+		n110, err := m.PushTxn.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
 		}
+		i += n110
 	}
-	return n
-}
-
-func (m *CheckConsistencyRequest) Size() (n int) {
-	var l int
-	_ = l
-	l = m.Span.Size()
-	n += 1 + l + sovApi(uint64(l))
-	return n
-}
-
-func (m *CheckConsistencyResponse) Size() (n int) {
-	var l int
-	_ = l
-	l = m.ResponseHeader.Size()
-	n += 1 + l + sovApi(uint64(l))
-	return n
-}
-
-func (m *BeginTransactionRequest) Size() (n int) {
-	var l int
-	_ = l
-	l = m.Span.Size()
-	n += 1 + l + sovApi(uint64(l))
-	return n
-}
-
-func (m *BeginTransactionResponse) Size() (n int) {
-	var l int
-	_ = l
-	l = m.ResponseHeader.Size()
-	n += 1 + l + sovApi(uint64(l))
-	return n
-}
-
-func (m *EndTransactionRequest) Size() (n int) {
-	var l int
-	_ = l
-	l = m.Span.Size()
-	n += 1 + l + sovApi(uint64(l))
-	n += 2
-	if m.Deadline != nil {
-		l = m.Deadline.Size()
-		n += 1 + l + sovApi(uint64(l))
+	if m.RangeLookup != nil {
+		data[i] = 0x7a
+		i++
+		i = encodeVarintApi(data, i, uint64(m.RangeLookup.Size()))
+		n111, err := m.RangeLookup.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n111
 	}
-	if m.InternalCommitTrigger != nil {
-		l = m.InternalCommitTrigger.Size()
-		n += 1 + l + sovApi(uint64(l))
+	if m.ResolveIntent != nil {
+		data[i] = 0x82
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintApi(data, i, uint64(m.ResolveIntent.Size()))
+		n112, err := m.ResolveIntent.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n112
 	}
-	if len(m.IntentSpans) > 0 {
-		for _, e := range m.IntentSpans {
-			l = e.Size()
-			n += 1 + l + sovApi(uint64(l))
+	if m.ResolveIntentRange != nil {
+		data[i] = 0x8a
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintApi(data, i, uint64(m.ResolveIntentRange.Size()))
+		n113, err := m.ResolveIntentRange.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
 		}
+		i += n113
 	}
-	return n
-}
-
-func (m *EndTransactionResponse) Size() (n int) {
-	var l int
-	_ = l
-	l = m.ResponseHeader.Size()
-	n += 1 + l + sovApi(uint64(l))
-	n += 1 + sovApi(uint64(m.CommitWait))
-	if len(m.Resolved) > 0 {
-		for _, b := range m.Resolved {
-			l = len(b)
-			n += 1 + l + sovApi(uint64(l))
+	if m.Merge != nil {
+		data[i] = 0x92
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintApi(data, i, uint64(m.Merge.Size()))
+		n114, err := m.Merge.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
 		}
+		i += n114
 	}
-	return n
-}
-
-func (m *AdminSplitRequest) Size() (n int) {
-	var l int
-	_ = l
-	l = m.Span.Size()
-	n += 1 + l + sovApi(uint64(l))
-	if m.SplitKey != nil {
-		l = len(m.SplitKey)
-		n += 1 + l + sovApi(uint64(l))
+	if m.TruncateLog != nil {
+		data[i] = 0x9a
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintApi(data, i, uint64(m.TruncateLog.Size()))
+		n115, err := m.TruncateLog.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n115
 	}
-	return n
-}
-
-func (m *AdminSplitResponse) Size() (n int) {
-	var l int
-	_ = l
-	l = m.ResponseHeader.Size()
-	n += 1 + l + sovApi(uint64(l))
-	return n
+	if m.LeaderLease != nil {
+		data[i] = 0xa2
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintApi(data, i, uint64(m.LeaderLease.Size()))
+		n116, err := m.LeaderLease.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n116
+	}
+	if m.ReverseScan != nil {
+		data[i] = 0xaa
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintApi(data, i, uint64(m.ReverseScan.Size()))
+		n117, err := m.ReverseScan.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n117
+	}
+	if m.ComputeChecksum != nil {
+		data[i] = 0xb2
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintApi(data, i, uint64(m.ComputeChecksum.Size()))
+		n118, err := m.ComputeChecksum.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n118
+	}
+	if m.VerifyChecksum != nil {
+		data[i] = 0xba
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintApi(data, i, uint64(m.VerifyChecksum.Size()))
+		n119, err := m.VerifyChecksum.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n119
+	}
+	if m.CheckConsistency != nil {
+		data[i] = 0xc2
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintApi(data, i, uint64(m.CheckConsistency.Size()))
+		n120, err := m.CheckConsistency.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n120
+	}
+	if m.Noop != nil {
+		data[i] = 0xca
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintApi(data, i, uint64(m.Noop.Size()))
+		n121, err := m.Noop.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n121
+	}
+	if m.ClearRange != nil {
+		data[i] = 0xd2
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintApi(data, i, uint64(m.ClearRange.Size()))
+		n133, err := m.ClearRange.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n133
+	}
+	if m.LeaseInfo != nil {
+		data[i] = 0xda
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintApi(data, i, uint64(m.LeaseInfo.Size()))
+		n138, err := m.LeaseInfo.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n138
+	}
+	if m.Export != nil {
+		data[i] = 0xe2
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintApi(data, i, uint64(m.Export.Size()))
+		n143, err := m.Export.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n143
+	}
+	if m.RecomputeStats != nil {
+		data[i] = 0xea
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintApi(data, i, uint64(m.RecomputeStats.Size()))
+		n147, err := m.RecomputeStats.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n147
+	}
+	return i, nil
 }
 
-func (m *AdminMergeRequest) Size() (n int) {
-	var l int
-	_ = l
-	l = m.Span.Size()
-	n += 1 + l + sovApi(uint64(l))
-	return n
+func (m *Header) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
 }
 
-func (m *AdminMergeResponse) Size() (n int) {
+func (m *Header) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	l = m.ResponseHeader.Size()
-	n += 1 + l + sovApi(uint64(l))
-	return n
-}
-
-func (m *RangeLookupRequest) Size() (n int) {
-	var l int
-	_ = l
-	l = m.Span.Size()
-	n += 1 + l + sovApi(uint64(l))
-	n += 1 + sovApi(uint64(m.MaxRanges))
-	n += 2
-	n += 2
-	return n
+	data[i] = 0xa
+	i++
+	i = encodeVarintApi(data, i, uint64(m.Timestamp.Size()))
+	n122, err := m.Timestamp.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n122
+	data[i] = 0x12
+	i++
+	i = encodeVarintApi(data, i, uint64(m.Replica.Size()))
+	n123, err := m.Replica.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n123
+	data[i] = 0x18
+	i++
+	i = encodeVarintApi(data, i, uint64(m.RangeID))
+	data[i] = 0x21
+	i++
+	i = encodeFixed64Api(data, i, uint64(math.Float64bits(float64(m.UserPriority))))
+	if m.Txn != nil {
+		data[i] = 0x2a
+		i++
+		i = encodeVarintApi(data, i, uint64(m.Txn.Size()))
+		n124, err := m.Txn.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n124
+	}
+	data[i] = 0x30
+	i++
+	i = encodeVarintApi(data, i, uint64(m.ReadConsistency))
+	if m.Trace != nil {
+		data[i] = 0x3a
+		i++
+		i = encodeVarintApi(data, i, uint64(m.Trace.Size()))
+		n125, err := m.Trace.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n125
+	}
+	data[i] = 0x40
+	i++
+	i = encodeVarintApi(data, i, uint64(m.MaxScanResults))
+	data[i] = 0x48
+	i++
+	i = encodeVarintApi(data, i, uint64(m.AdmissionClass))
+	data[i] = 0x50
+	i++
+	i = encodeVarintApi(data, i, uint64(m.TargetBytes))
+	return i, nil
 }
 
-func (m *RangeLookupResponse) Size() (n int) {
+func (m *BatchRequest) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *BatchRequest) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	l = m.ResponseHeader.Size()
-	n += 1 + l + sovApi(uint64(l))
-	if len(m.Ranges) > 0 {
-		for _, e := range m.Ranges {
-			l = e.Size()
-			n += 1 + l + sovApi(uint64(l))
+	data[i] = 0xa
+	i++
+	i = encodeVarintApi(data, i, uint64(m.Header.Size()))
+	n126, err := m.Header.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n126
+	if len(m.Requests) > 0 {
+		for _, msg := range m.Requests {
+			data[i] = 0x12
+			i++
+			i = encodeVarintApi(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
 		}
 	}
-	return n
+	return i, nil
 }
 
-func (m *HeartbeatTxnRequest) Size() (n int) {
+func (m *BatchResponse) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *BatchResponse) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	l = m.Span.Size()
-	n += 1 + l + sovApi(uint64(l))
-	l = m.Now.Size()
-	n += 1 + l + sovApi(uint64(l))
-	return n
+	data[i] = 0xa
+	i++
+	i = encodeVarintApi(data, i, uint64(m.BatchResponse_Header.Size()))
+	n127, err := m.BatchResponse_Header.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n127
+	if len(m.Responses) > 0 {
+		for _, msg := range m.Responses {
+			data[i] = 0x12
+			i++
+			i = encodeVarintApi(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
 }
 
-func (m *HeartbeatTxnResponse) Size() (n int) {
+func (m *BatchResponse_Header) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *BatchResponse_Header) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	l = m.ResponseHeader.Size()
-	n += 1 + l + sovApi(uint64(l))
-	return n
+	if m.Error != nil {
+		data[i] = 0xa
+		i++
+		i = encodeVarintApi(data, i, uint64(m.Error.Size()))
+		n128, err := m.Error.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n128
+	}
+	if m.Txn != nil {
+		data[i] = 0x1a
+		i++
+		i = encodeVarintApi(data, i, uint64(m.Txn.Size()))
+		n129, err := m.Txn.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n129
+	}
+	if len(m.CollectedSpans) > 0 {
+		for _, b := range m.CollectedSpans {
+			data[i] = 0x22
+			i++
+			i = encodeVarintApi(data, i, uint64(len(b)))
+			i += copy(data[i:], b)
+		}
+	}
+	return i, nil
 }
 
-func (m *GCRequest) Size() (n int) {
+func encodeFixed64Api(data []byte, offset int, v uint64) int {
+	data[offset] = uint8(v)
+	data[offset+1] = uint8(v >> 8)
+	data[offset+2] = uint8(v >> 16)
+	data[offset+3] = uint8(v >> 24)
+	data[offset+4] = uint8(v >> 32)
+	data[offset+5] = uint8(v >> 40)
+	data[offset+6] = uint8(v >> 48)
+	data[offset+7] = uint8(v >> 56)
+	return offset + 8
+}
+func encodeFixed32Api(data []byte, offset int, v uint32) int {
+	data[offset] = uint8(v)
+	data[offset+1] = uint8(v >> 8)
+	data[offset+2] = uint8(v >> 16)
+	data[offset+3] = uint8(v >> 24)
+	return offset + 4
+}
+func encodeVarintApi(data []byte, offset int, v uint64) int {
+	for v >= 1<<7 {
+		data[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	data[offset] = uint8(v)
+	return offset + 1
+}
+func (m *ResponseHeader) Size() (n int) {
 	var l int
 	_ = l
-	l = m.Span.Size()
+	l = m.Timestamp.Size()
 	n += 1 + l + sovApi(uint64(l))
-	if len(m.Keys) > 0 {
-		for _, e := range m.Keys {
-			l = e.Size()
-			n += 1 + l + sovApi(uint64(l))
-		}
+	if m.Txn != nil {
+		l = m.Txn.Size()
+		n += 1 + l + sovApi(uint64(l))
+	}
+	if m.ResumeSpan != nil {
+		l = m.ResumeSpan.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
 	return n
 }
 
-func (m *GCRequest_GCKey) Size() (n int) {
+func (m *GetRequest) Size() (n int) {
 	var l int
 	_ = l
-	if m.Key != nil {
-		l = len(m.Key)
-		n += 1 + l + sovApi(uint64(l))
-	}
-	l = m.Timestamp.Size()
+	l = m.Span.Size()
 	n += 1 + l + sovApi(uint64(l))
 	return n
 }
 
-func (m *GCResponse) Size() (n int) {
+func (m *GetResponse) Size() (n int) {
 	var l int
 	_ = l
 	l = m.ResponseHeader.Size()
 	n += 1 + l + sovApi(uint64(l))
+	if m.Value != nil {
+		l = m.Value.Size()
+		n += 1 + l + sovApi(uint64(l))
+	}
 	return n
 }
 
-func (m *PushTxnRequest) Size() (n int) {
+func (m *PutRequest) Size() (n int) {
 	var l int
 	_ = l
 	l = m.Span.Size()
 	n += 1 + l + sovApi(uint64(l))
-	l = m.PusherTxn.Size()
-	n += 1 + l + sovApi(uint64(l))
-	l = m.PusheeTxn.Size()
-	n += 1 + l + sovApi(uint64(l))
-	l = m.PushTo.Size()
-	n += 1 + l + sovApi(uint64(l))
-	l = m.Now.Size()
+	l = m.Value.Size()
 	n += 1 + l + sovApi(uint64(l))
-	n += 1 + sovApi(uint64(m.PushType))
 	return n
 }
 
-func (m *PushTxnResponse) Size() (n int) {
+func (m *PutResponse) Size() (n int) {
 	var l int
 	_ = l
 	l = m.ResponseHeader.Size()
 	n += 1 + l + sovApi(uint64(l))
-	l = m.PusheeTxn.Size()
-	n += 1 + l + sovApi(uint64(l))
 	return n
 }
 
-func (m *ResolveIntentRequest) Size() (n int) {
+func (m *ConditionalPutRequest) Size() (n int) {
 	var l int
 	_ = l
 	l = m.Span.Size()
 	n += 1 + l + sovApi(uint64(l))
-	l = m.IntentTxn.Size()
+	l = m.Value.Size()
 	n += 1 + l + sovApi(uint64(l))
-	n += 1 + sovApi(uint64(m.Status))
-	n += 2
+	if m.ExpValue != nil {
+		l = m.ExpValue.Size()
+		n += 1 + l + sovApi(uint64(l))
+	}
 	return n
 }
 
-func (m *ResolveIntentResponse) Size() (n int) {
+func (m *ConditionalPutResponse) Size() (n int) {
 	var l int
 	_ = l
 	l = m.ResponseHeader.Size()
@@ -4179,27 +4436,25 @@ func (m *ResolveIntentResponse) Size() (n int) {
 	return n
 }
 
-func (m *ResolveIntentRangeRequest) Size() (n int) {
+func (m *IncrementRequest) Size() (n int) {
 	var l int
 	_ = l
 	l = m.Span.Size()
 	n += 1 + l + sovApi(uint64(l))
-	l = m.IntentTxn.Size()
-	n += 1 + l + sovApi(uint64(l))
-	n += 1 + sovApi(uint64(m.Status))
-	n += 2
+	n += 1 + sovApi(uint64(m.Increment))
 	return n
 }
 
-func (m *NoopResponse) Size() (n int) {
+func (m *IncrementResponse) Size() (n int) {
 	var l int
 	_ = l
 	l = m.ResponseHeader.Size()
 	n += 1 + l + sovApi(uint64(l))
+	n += 1 + sovApi(uint64(m.NewValue))
 	return n
 }
 
-func (m *NoopRequest) Size() (n int) {
+func (m *DeleteRequest) Size() (n int) {
 	var l int
 	_ = l
 	l = m.Span.Size()
@@ -4207,7 +4462,7 @@ func (m *NoopRequest) Size() (n int) {
 	return n
 }
 
-func (m *ResolveIntentRangeResponse) Size() (n int) {
+func (m *DeleteResponse) Size() (n int) {
 	var l int
 	_ = l
 	l = m.ResponseHeader.Size()
@@ -4215,53 +4470,85 @@ func (m *ResolveIntentRangeResponse) Size() (n int) {
 	return n
 }
 
-func (m *MergeRequest) Size() (n int) {
+func (m *DeleteRangeRequest) Size() (n int) {
 	var l int
 	_ = l
 	l = m.Span.Size()
 	n += 1 + l + sovApi(uint64(l))
-	l = m.Value.Size()
-	n += 1 + l + sovApi(uint64(l))
+	n += 1 + sovApi(uint64(m.MaxEntriesToDelete))
+	n += 2
 	return n
 }
 
-func (m *MergeResponse) Size() (n int) {
+func (m *DeleteRangeResponse) Size() (n int) {
 	var l int
 	_ = l
 	l = m.ResponseHeader.Size()
 	n += 1 + l + sovApi(uint64(l))
+	if len(m.Keys) > 0 {
+		for _, b := range m.Keys {
+			l = len(b)
+			n += 1 + l + sovApi(uint64(l))
+		}
+	}
 	return n
 }
 
-func (m *TruncateLogRequest) Size() (n int) {
+func (m *ScanRequest) Size() (n int) {
 	var l int
 	_ = l
 	l = m.Span.Size()
 	n += 1 + l + sovApi(uint64(l))
-	n += 1 + sovApi(uint64(m.Index))
-	n += 1 + sovApi(uint64(m.RangeID))
+	n += 1 + sovApi(uint64(m.MaxResults))
 	return n
 }
 
-func (m *TruncateLogResponse) Size() (n int) {
+func (m *ScanResponse) Size() (n int) {
 	var l int
 	_ = l
 	l = m.ResponseHeader.Size()
 	n += 1 + l + sovApi(uint64(l))
+	if len(m.Rows) > 0 {
+		for _, e := range m.Rows {
+			l = e.Size()
+			n += 1 + l + sovApi(uint64(l))
+		}
+	}
 	return n
 }
 
-func (m *LeaderLeaseRequest) Size() (n int) {
+func (m *ReverseScanRequest) Size() (n int) {
 	var l int
 	_ = l
 	l = m.Span.Size()
 	n += 1 + l + sovApi(uint64(l))
-	l = m.Lease.Size()
+	n += 1 + sovApi(uint64(m.MaxResults))
+	return n
+}
+
+func (m *ReverseScanResponse) Size() (n int) {
+	var l int
+	_ = l
+	l = m.ResponseHeader.Size()
+	n += 1 + l + sovApi(uint64(l))
+	if len(m.Rows) > 0 {
+		for _, e := range m.Rows {
+			l = e.Size()
+			n += 1 + l + sovApi(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *CheckConsistencyRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = m.Span.Size()
 	n += 1 + l + sovApi(uint64(l))
 	return n
 }
 
-func (m *LeaderLeaseResponse) Size() (n int) {
+func (m *CheckConsistencyResponse) Size() (n int) {
 	var l int
 	_ = l
 	l = m.ResponseHeader.Size()
@@ -4269,289 +4556,207 @@ func (m *LeaderLeaseResponse) Size() (n int) {
 	return n
 }
 
-func (m *ComputeChecksumRequest) Size() (n int) {
+func (m *ClearRangeRequest) Size() (n int) {
 	var l int
 	_ = l
 	l = m.Span.Size()
 	n += 1 + l + sovApi(uint64(l))
-	n += 1 + sovApi(uint64(m.Version))
-	l = m.ChecksumID.Size()
+	return n
+}
+
+func (m *ClearRangeResponse) Size() (n int) {
+	var l int
+	_ = l
+	l = m.ResponseHeader.Size()
 	n += 1 + l + sovApi(uint64(l))
 	return n
 }
 
-func (m *ComputeChecksumResponse) Size() (n int) {
+func (m *LeaseInfoRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = m.Span.Size()
+	n += 1 + l + sovApi(uint64(l))
+	return n
+}
+
+func (m *LeaseInfoResponse) Size() (n int) {
 	var l int
 	_ = l
 	l = m.ResponseHeader.Size()
 	n += 1 + l + sovApi(uint64(l))
+	l = m.Lease.Size()
+	n += 1 + l + sovApi(uint64(l))
 	return n
 }
 
-func (m *VerifyChecksumRequest) Size() (n int) {
+func (m *ExportRequest) Size() (n int) {
 	var l int
 	_ = l
 	l = m.Span.Size()
 	n += 1 + l + sovApi(uint64(l))
-	n += 1 + sovApi(uint64(m.Version))
-	l = m.ChecksumID.Size()
+	l = len(m.Dir)
 	n += 1 + l + sovApi(uint64(l))
-	if m.Checksum != nil {
-		l = len(m.Checksum)
+	return n
+}
+
+func (m *ExportedFile) Size() (n int) {
+	var l int
+	_ = l
+	l = m.Span.Size()
+	n += 1 + l + sovApi(uint64(l))
+	l = len(m.Path)
+	n += 1 + l + sovApi(uint64(l))
+	if m.SST != nil {
+		l = len(m.SST)
 		n += 1 + l + sovApi(uint64(l))
 	}
 	return n
 }
 
-func (m *VerifyChecksumResponse) Size() (n int) {
+func (m *ExportResponse) Size() (n int) {
 	var l int
 	_ = l
 	l = m.ResponseHeader.Size()
 	n += 1 + l + sovApi(uint64(l))
+	if len(m.Files) > 0 {
+		for _, e := range m.Files {
+			l = e.Size()
+			n += 1 + l + sovApi(uint64(l))
+		}
+	}
 	return n
 }
 
-func (m *RequestUnion) Size() (n int) {
+func (m *RecomputeStatsRequest) Size() (n int) {
 	var l int
 	_ = l
-	if m.Get != nil {
-		l = m.Get.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.Put != nil {
-		l = m.Put.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.ConditionalPut != nil {
-		l = m.ConditionalPut.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.Increment != nil {
-		l = m.Increment.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.Delete != nil {
-		l = m.Delete.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.DeleteRange != nil {
-		l = m.DeleteRange.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.Scan != nil {
-		l = m.Scan.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.BeginTransaction != nil {
-		l = m.BeginTransaction.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.EndTransaction != nil {
-		l = m.EndTransaction.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.AdminSplit != nil {
-		l = m.AdminSplit.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.AdminMerge != nil {
-		l = m.AdminMerge.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.HeartbeatTxn != nil {
-		l = m.HeartbeatTxn.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.Gc != nil {
-		l = m.Gc.Size()
+	l = m.Span.Size()
+	n += 1 + l + sovApi(uint64(l))
+	if m.Delta != nil {
+		l = len(m.Delta)
 		n += 1 + l + sovApi(uint64(l))
 	}
-	if m.PushTxn != nil {
-		l = m.PushTxn.Size()
+	return n
+}
+
+func (m *RecomputeStatsResponse) Size() (n int) {
+	var l int
+	_ = l
+	l = m.ResponseHeader.Size()
+	n += 1 + l + sovApi(uint64(l))
+	return n
+}
+
+func (m *BeginTransactionRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = m.Span.Size()
+	n += 1 + l + sovApi(uint64(l))
+	return n
+}
+
+func (m *BeginTransactionResponse) Size() (n int) {
+	var l int
+	_ = l
+	l = m.ResponseHeader.Size()
+	n += 1 + l + sovApi(uint64(l))
+	return n
+}
+
+func (m *EndTransactionRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = m.Span.Size()
+	n += 1 + l + sovApi(uint64(l))
+	n += 2
+	if m.Deadline != nil {
+		l = m.Deadline.Size()
 		n += 1 + l + sovApi(uint64(l))
 	}
-	if m.RangeLookup != nil {
-		l = m.RangeLookup.Size()
+	if m.InternalCommitTrigger != nil {
+		l = m.InternalCommitTrigger.Size()
 		n += 1 + l + sovApi(uint64(l))
 	}
-	if m.ResolveIntent != nil {
-		l = m.ResolveIntent.Size()
-		n += 2 + l + sovApi(uint64(l))
-	}
-	if m.ResolveIntentRange != nil {
-		l = m.ResolveIntentRange.Size()
-		n += 2 + l + sovApi(uint64(l))
-	}
-	if m.Merge != nil {
-		l = m.Merge.Size()
-		n += 2 + l + sovApi(uint64(l))
-	}
-	if m.TruncateLog != nil {
-		l = m.TruncateLog.Size()
-		n += 2 + l + sovApi(uint64(l))
-	}
-	if m.LeaderLease != nil {
-		l = m.LeaderLease.Size()
-		n += 2 + l + sovApi(uint64(l))
-	}
-	if m.ReverseScan != nil {
-		l = m.ReverseScan.Size()
-		n += 2 + l + sovApi(uint64(l))
-	}
-	if m.ComputeChecksum != nil {
-		l = m.ComputeChecksum.Size()
-		n += 2 + l + sovApi(uint64(l))
-	}
-	if m.VerifyChecksum != nil {
-		l = m.VerifyChecksum.Size()
-		n += 2 + l + sovApi(uint64(l))
-	}
-	if m.CheckConsistency != nil {
-		l = m.CheckConsistency.Size()
-		n += 2 + l + sovApi(uint64(l))
-	}
-	if m.Noop != nil {
-		l = m.Noop.Size()
-		n += 2 + l + sovApi(uint64(l))
+	if len(m.IntentSpans) > 0 {
+		for _, e := range m.IntentSpans {
+			l = e.Size()
+			n += 1 + l + sovApi(uint64(l))
+		}
 	}
 	return n
 }
 
-func (m *ResponseUnion) Size() (n int) {
+func (m *EndTransactionResponse) Size() (n int) {
 	var l int
 	_ = l
-	if m.Get != nil {
-		l = m.Get.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.Put != nil {
-		l = m.Put.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.ConditionalPut != nil {
-		l = m.ConditionalPut.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.Increment != nil {
-		l = m.Increment.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.Delete != nil {
-		l = m.Delete.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.DeleteRange != nil {
-		l = m.DeleteRange.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.Scan != nil {
-		l = m.Scan.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.BeginTransaction != nil {
-		l = m.BeginTransaction.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.EndTransaction != nil {
-		l = m.EndTransaction.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.AdminSplit != nil {
-		l = m.AdminSplit.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.AdminMerge != nil {
-		l = m.AdminMerge.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.HeartbeatTxn != nil {
-		l = m.HeartbeatTxn.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.Gc != nil {
-		l = m.Gc.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.PushTxn != nil {
-		l = m.PushTxn.Size()
-		n += 1 + l + sovApi(uint64(l))
+	l = m.ResponseHeader.Size()
+	n += 1 + l + sovApi(uint64(l))
+	n += 1 + sovApi(uint64(m.CommitWait))
+	if len(m.Resolved) > 0 {
+		for _, b := range m.Resolved {
+			l = len(b)
+			n += 1 + l + sovApi(uint64(l))
+		}
 	}
-	if m.RangeLookup != nil {
-		l = m.RangeLookup.Size()
+	n += 2
+	return n
+}
+
+func (m *AdminSplitRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = m.Span.Size()
+	n += 1 + l + sovApi(uint64(l))
+	if m.SplitKey != nil {
+		l = len(m.SplitKey)
 		n += 1 + l + sovApi(uint64(l))
 	}
-	if m.ResolveIntent != nil {
-		l = m.ResolveIntent.Size()
-		n += 2 + l + sovApi(uint64(l))
-	}
-	if m.ResolveIntentRange != nil {
-		l = m.ResolveIntentRange.Size()
-		n += 2 + l + sovApi(uint64(l))
-	}
-	if m.Merge != nil {
-		l = m.Merge.Size()
-		n += 2 + l + sovApi(uint64(l))
-	}
-	if m.TruncateLog != nil {
-		l = m.TruncateLog.Size()
-		n += 2 + l + sovApi(uint64(l))
-	}
-	if m.LeaderLease != nil {
-		l = m.LeaderLease.Size()
-		n += 2 + l + sovApi(uint64(l))
-	}
-	if m.ReverseScan != nil {
-		l = m.ReverseScan.Size()
-		n += 2 + l + sovApi(uint64(l))
-	}
-	if m.ComputeChecksum != nil {
-		l = m.ComputeChecksum.Size()
-		n += 2 + l + sovApi(uint64(l))
-	}
-	if m.VerifyChecksum != nil {
-		l = m.VerifyChecksum.Size()
-		n += 2 + l + sovApi(uint64(l))
-	}
-	if m.CheckConsistency != nil {
-		l = m.CheckConsistency.Size()
-		n += 2 + l + sovApi(uint64(l))
-	}
-	if m.Noop != nil {
-		l = m.Noop.Size()
-		n += 2 + l + sovApi(uint64(l))
-	}
 	return n
 }
 
-func (m *Header) Size() (n int) {
+func (m *AdminSplitResponse) Size() (n int) {
 	var l int
 	_ = l
-	l = m.Timestamp.Size()
+	l = m.ResponseHeader.Size()
 	n += 1 + l + sovApi(uint64(l))
-	l = m.Replica.Size()
+	return n
+}
+
+func (m *AdminMergeRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = m.Span.Size()
 	n += 1 + l + sovApi(uint64(l))
-	n += 1 + sovApi(uint64(m.RangeID))
-	n += 9
-	if m.Txn != nil {
-		l = m.Txn.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	n += 1 + sovApi(uint64(m.ReadConsistency))
-	if m.Trace != nil {
-		l = m.Trace.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	n += 1 + sovApi(uint64(m.MaxScanResults))
 	return n
 }
 
-func (m *BatchRequest) Size() (n int) {
+func (m *AdminMergeResponse) Size() (n int) {
 	var l int
 	_ = l
-	l = m.Header.Size()
+	l = m.ResponseHeader.Size()
 	n += 1 + l + sovApi(uint64(l))
-	if len(m.Requests) > 0 {
-		for _, e := range m.Requests {
+	return n
+}
+
+func (m *RangeLookupRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = m.Span.Size()
+	n += 1 + l + sovApi(uint64(l))
+	n += 1 + sovApi(uint64(m.MaxRanges))
+	n += 2
+	n += 2
+	return n
+}
+
+func (m *RangeLookupResponse) Size() (n int) {
+	var l int
+	_ = l
+	l = m.ResponseHeader.Size()
+	n += 1 + l + sovApi(uint64(l))
+	if len(m.Ranges) > 0 {
+		for _, e := range m.Ranges {
 			l = e.Size()
 			n += 1 + l + sovApi(uint64(l))
 		}
@@ -4559,13 +4764,31 @@ func (m *BatchRequest) Size() (n int) {
 	return n
 }
 
-func (m *BatchResponse) Size() (n int) {
+func (m *HeartbeatTxnRequest) Size() (n int) {
 	var l int
 	_ = l
-	l = m.BatchResponse_Header.Size()
+	l = m.Span.Size()
 	n += 1 + l + sovApi(uint64(l))
-	if len(m.Responses) > 0 {
-		for _, e := range m.Responses {
+	l = m.Now.Size()
+	n += 1 + l + sovApi(uint64(l))
+	return n
+}
+
+func (m *HeartbeatTxnResponse) Size() (n int) {
+	var l int
+	_ = l
+	l = m.ResponseHeader.Size()
+	n += 1 + l + sovApi(uint64(l))
+	return n
+}
+
+func (m *GCRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = m.Span.Size()
+	n += 1 + l + sovApi(uint64(l))
+	if len(m.Keys) > 0 {
+		for _, e := range m.Keys {
 			l = e.Size()
 			n += 1 + l + sovApi(uint64(l))
 		}
@@ -4573,312 +4796,1698 @@ func (m *BatchResponse) Size() (n int) {
 	return n
 }
 
-func (m *BatchResponse_Header) Size() (n int) {
+func (m *GCRequest_GCKey) Size() (n int) {
 	var l int
 	_ = l
-	if m.Error != nil {
-		l = m.Error.Size()
-		n += 1 + l + sovApi(uint64(l))
-	}
-	if m.Txn != nil {
-		l = m.Txn.Size()
+	if m.Key != nil {
+		l = len(m.Key)
 		n += 1 + l + sovApi(uint64(l))
 	}
-	if len(m.CollectedSpans) > 0 {
-		for _, b := range m.CollectedSpans {
-			l = len(b)
-			n += 1 + l + sovApi(uint64(l))
-		}
-	}
+	l = m.Timestamp.Size()
+	n += 1 + l + sovApi(uint64(l))
 	return n
 }
 
-func sovApi(x uint64) (n int) {
-	for {
-		n++
-		x >>= 7
-		if x == 0 {
-			break
-		}
-	}
+func (m *GCResponse) Size() (n int) {
+	var l int
+	_ = l
+	l = m.ResponseHeader.Size()
+	n += 1 + l + sovApi(uint64(l))
 	return n
 }
-func sozApi(x uint64) (n int) {
-	return sovApi(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+
+func (m *PushTxnRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = m.Span.Size()
+	n += 1 + l + sovApi(uint64(l))
+	l = m.PusherTxn.Size()
+	n += 1 + l + sovApi(uint64(l))
+	l = m.PusheeTxn.Size()
+	n += 1 + l + sovApi(uint64(l))
+	l = m.PushTo.Size()
+	n += 1 + l + sovApi(uint64(l))
+	l = m.Now.Size()
+	n += 1 + l + sovApi(uint64(l))
+	n += 1 + sovApi(uint64(m.PushType))
+	return n
 }
-func (this *RequestUnion) GetValue() interface{} {
-	if this.Get != nil {
-		return this.Get
-	}
-	if this.Put != nil {
-		return this.Put
-	}
-	if this.ConditionalPut != nil {
-		return this.ConditionalPut
-	}
-	if this.Increment != nil {
-		return this.Increment
-	}
-	if this.Delete != nil {
-		return this.Delete
+
+func (m *PushTxnResponse) Size() (n int) {
+	var l int
+	_ = l
+	l = m.ResponseHeader.Size()
+	n += 1 + l + sovApi(uint64(l))
+	l = m.PusheeTxn.Size()
+	n += 1 + l + sovApi(uint64(l))
+	return n
+}
+
+func (m *ResolveIntentRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = m.Span.Size()
+	n += 1 + l + sovApi(uint64(l))
+	l = m.IntentTxn.Size()
+	n += 1 + l + sovApi(uint64(l))
+	n += 1 + sovApi(uint64(m.Status))
+	n += 2
+	return n
+}
+
+func (m *ResolveIntentResponse) Size() (n int) {
+	var l int
+	_ = l
+	l = m.ResponseHeader.Size()
+	n += 1 + l + sovApi(uint64(l))
+	return n
+}
+
+func (m *ResolveIntentRangeRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = m.Span.Size()
+	n += 1 + l + sovApi(uint64(l))
+	l = m.IntentTxn.Size()
+	n += 1 + l + sovApi(uint64(l))
+	n += 1 + sovApi(uint64(m.Status))
+	n += 2
+	return n
+}
+
+func (m *NoopResponse) Size() (n int) {
+	var l int
+	_ = l
+	l = m.ResponseHeader.Size()
+	n += 1 + l + sovApi(uint64(l))
+	return n
+}
+
+func (m *NoopRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = m.Span.Size()
+	n += 1 + l + sovApi(uint64(l))
+	return n
+}
+
+func (m *ResolveIntentRangeResponse) Size() (n int) {
+	var l int
+	_ = l
+	l = m.ResponseHeader.Size()
+	n += 1 + l + sovApi(uint64(l))
+	return n
+}
+
+func (m *MergeRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = m.Span.Size()
+	n += 1 + l + sovApi(uint64(l))
+	l = m.Value.Size()
+	n += 1 + l + sovApi(uint64(l))
+	return n
+}
+
+func (m *MergeResponse) Size() (n int) {
+	var l int
+	_ = l
+	l = m.ResponseHeader.Size()
+	n += 1 + l + sovApi(uint64(l))
+	return n
+}
+
+func (m *TruncateLogRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = m.Span.Size()
+	n += 1 + l + sovApi(uint64(l))
+	n += 1 + sovApi(uint64(m.Index))
+	n += 1 + sovApi(uint64(m.RangeID))
+	return n
+}
+
+func (m *TruncateLogResponse) Size() (n int) {
+	var l int
+	_ = l
+	l = m.ResponseHeader.Size()
+	n += 1 + l + sovApi(uint64(l))
+	return n
+}
+
+func (m *LeaderLeaseRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = m.Span.Size()
+	n += 1 + l + sovApi(uint64(l))
+	l = m.Lease.Size()
+	n += 1 + l + sovApi(uint64(l))
+	return n
+}
+
+func (m *LeaderLeaseResponse) Size() (n int) {
+	var l int
+	_ = l
+	l = m.ResponseHeader.Size()
+	n += 1 + l + sovApi(uint64(l))
+	return n
+}
+
+func (m *ComputeChecksumRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = m.Span.Size()
+	n += 1 + l + sovApi(uint64(l))
+	n += 1 + sovApi(uint64(m.Version))
+	l = m.ChecksumID.Size()
+	n += 1 + l + sovApi(uint64(l))
+	return n
+}
+
+func (m *ComputeChecksumResponse) Size() (n int) {
+	var l int
+	_ = l
+	l = m.ResponseHeader.Size()
+	n += 1 + l + sovApi(uint64(l))
+	return n
+}
+
+func (m *VerifyChecksumRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = m.Span.Size()
+	n += 1 + l + sovApi(uint64(l))
+	n += 1 + sovApi(uint64(m.Version))
+	l = m.ChecksumID.Size()
+	n += 1 + l + sovApi(uint64(l))
+	if m.Checksum != nil {
+		l = len(m.Checksum)
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.DeleteRange != nil {
-		return this.DeleteRange
+	return n
+}
+
+func (m *VerifyChecksumResponse) Size() (n int) {
+	var l int
+	_ = l
+	l = m.ResponseHeader.Size()
+	n += 1 + l + sovApi(uint64(l))
+	return n
+}
+
+func (m *RequestUnion) Size() (n int) {
+	var l int
+	_ = l
+	if m.Get != nil {
+		l = m.Get.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.Scan != nil {
-		return this.Scan
+	if m.Put != nil {
+		l = m.Put.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.BeginTransaction != nil {
-		return this.BeginTransaction
+	if m.ConditionalPut != nil {
+		l = m.ConditionalPut.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.EndTransaction != nil {
-		return this.EndTransaction
+	if m.Increment != nil {
+		l = m.Increment.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.AdminSplit != nil {
-		return this.AdminSplit
+	if m.Delete != nil {
+		l = m.Delete.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.AdminMerge != nil {
-		return this.AdminMerge
+	if m.DeleteRange != nil {
+		l = m.DeleteRange.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.HeartbeatTxn != nil {
-		return this.HeartbeatTxn
+	if m.Scan != nil {
+		l = m.Scan.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.Gc != nil {
-		return this.Gc
+	if m.BeginTransaction != nil {
+		l = m.BeginTransaction.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.PushTxn != nil {
-		return this.PushTxn
+	if m.EndTransaction != nil {
+		l = m.EndTransaction.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.RangeLookup != nil {
-		return this.RangeLookup
+	if m.AdminSplit != nil {
+		l = m.AdminSplit.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.ResolveIntent != nil {
-		return this.ResolveIntent
+	if m.AdminMerge != nil {
+		l = m.AdminMerge.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.ResolveIntentRange != nil {
-		return this.ResolveIntentRange
+	if m.HeartbeatTxn != nil {
+		l = m.HeartbeatTxn.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.Merge != nil {
-		return this.Merge
+	if m.Gc != nil {
+		l = m.Gc.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.TruncateLog != nil {
-		return this.TruncateLog
+	if m.PushTxn != nil {
+		l = m.PushTxn.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.LeaderLease != nil {
-		return this.LeaderLease
+	if m.RangeLookup != nil {
+		l = m.RangeLookup.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.ReverseScan != nil {
-		return this.ReverseScan
+	if m.ResolveIntent != nil {
+		l = m.ResolveIntent.Size()
+		n += 2 + l + sovApi(uint64(l))
 	}
-	if this.ComputeChecksum != nil {
-		return this.ComputeChecksum
+	if m.ResolveIntentRange != nil {
+		l = m.ResolveIntentRange.Size()
+		n += 2 + l + sovApi(uint64(l))
 	}
-	if this.VerifyChecksum != nil {
-		return this.VerifyChecksum
+	if m.Merge != nil {
+		l = m.Merge.Size()
+		n += 2 + l + sovApi(uint64(l))
 	}
-	if this.CheckConsistency != nil {
-		return this.CheckConsistency
+	if m.TruncateLog != nil {
+		l = m.TruncateLog.Size()
+		n += 2 + l + sovApi(uint64(l))
 	}
-	if this.Noop != nil {
-		return this.Noop
+	if m.LeaderLease != nil {
+		l = m.LeaderLease.Size()
+		n += 2 + l + sovApi(uint64(l))
 	}
-	return nil
+	if m.ReverseScan != nil {
+		l = m.ReverseScan.Size()
+		n += 2 + l + sovApi(uint64(l))
+	}
+	if m.ComputeChecksum != nil {
+		l = m.ComputeChecksum.Size()
+		n += 2 + l + sovApi(uint64(l))
+	}
+	if m.VerifyChecksum != nil {
+		l = m.VerifyChecksum.Size()
+		n += 2 + l + sovApi(uint64(l))
+	}
+	if m.CheckConsistency != nil {
+		l = m.CheckConsistency.Size()
+		n += 2 + l + sovApi(uint64(l))
+	}
+	if m.Noop != nil {
+		l = m.Noop.Size()
+		n += 2 + l + sovApi(uint64(l))
+	}
+	if m.ClearRange != nil {
+		l = m.ClearRange.Size()
+		n += 2 + l + sovApi(uint64(l))
+	}
+	if m.LeaseInfo != nil {
+		l = m.LeaseInfo.Size()
+		n += 2 + l + sovApi(uint64(l))
+	}
+	if m.Export != nil {
+		l = m.Export.Size()
+		n += 2 + l + sovApi(uint64(l))
+	}
+	if m.RecomputeStats != nil {
+		l = m.RecomputeStats.Size()
+		n += 2 + l + sovApi(uint64(l))
+	}
+	return n
 }
 
-func (this *RequestUnion) SetValue(value interface{}) bool {
-	switch vt := value.(type) {
-	case *GetRequest:
-		this.Get = vt
-	case *PutRequest:
-		this.Put = vt
-	case *ConditionalPutRequest:
-		this.ConditionalPut = vt
-	case *IncrementRequest:
-		this.Increment = vt
-	case *DeleteRequest:
-		this.Delete = vt
-	case *DeleteRangeRequest:
-		this.DeleteRange = vt
-	case *ScanRequest:
-		this.Scan = vt
-	case *BeginTransactionRequest:
-		this.BeginTransaction = vt
-	case *EndTransactionRequest:
-		this.EndTransaction = vt
-	case *AdminSplitRequest:
-		this.AdminSplit = vt
-	case *AdminMergeRequest:
-		this.AdminMerge = vt
-	case *HeartbeatTxnRequest:
-		this.HeartbeatTxn = vt
-	case *GCRequest:
-		this.Gc = vt
-	case *PushTxnRequest:
-		this.PushTxn = vt
-	case *RangeLookupRequest:
-		this.RangeLookup = vt
-	case *ResolveIntentRequest:
-		this.ResolveIntent = vt
-	case *ResolveIntentRangeRequest:
-		this.ResolveIntentRange = vt
-	case *MergeRequest:
-		this.Merge = vt
-	case *TruncateLogRequest:
-		this.TruncateLog = vt
-	case *LeaderLeaseRequest:
-		this.LeaderLease = vt
-	case *ReverseScanRequest:
-		this.ReverseScan = vt
-	case *ComputeChecksumRequest:
-		this.ComputeChecksum = vt
-	case *VerifyChecksumRequest:
-		this.VerifyChecksum = vt
-	case *CheckConsistencyRequest:
-		this.CheckConsistency = vt
-	case *NoopRequest:
-		this.Noop = vt
-	default:
-		return false
+func (m *ResponseUnion) Size() (n int) {
+	var l int
+	_ = l
+	if m.Get != nil {
+		l = m.Get.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	return true
-}
-func (this *ResponseUnion) GetValue() interface{} {
-	if this.Get != nil {
-		return this.Get
+	if m.Put != nil {
+		l = m.Put.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.Put != nil {
-		return this.Put
+	if m.ConditionalPut != nil {
+		l = m.ConditionalPut.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.ConditionalPut != nil {
-		return this.ConditionalPut
+	if m.Increment != nil {
+		l = m.Increment.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.Increment != nil {
-		return this.Increment
+	if m.Delete != nil {
+		l = m.Delete.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.Delete != nil {
-		return this.Delete
+	if m.DeleteRange != nil {
+		l = m.DeleteRange.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.DeleteRange != nil {
-		return this.DeleteRange
+	if m.Scan != nil {
+		l = m.Scan.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.Scan != nil {
-		return this.Scan
+	if m.BeginTransaction != nil {
+		l = m.BeginTransaction.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.BeginTransaction != nil {
-		return this.BeginTransaction
+	if m.EndTransaction != nil {
+		l = m.EndTransaction.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.EndTransaction != nil {
-		return this.EndTransaction
+	if m.AdminSplit != nil {
+		l = m.AdminSplit.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.AdminSplit != nil {
-		return this.AdminSplit
+	if m.AdminMerge != nil {
+		l = m.AdminMerge.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.AdminMerge != nil {
-		return this.AdminMerge
+	if m.HeartbeatTxn != nil {
+		l = m.HeartbeatTxn.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.HeartbeatTxn != nil {
-		return this.HeartbeatTxn
+	if m.Gc != nil {
+		l = m.Gc.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.Gc != nil {
-		return this.Gc
+	if m.PushTxn != nil {
+		l = m.PushTxn.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.PushTxn != nil {
-		return this.PushTxn
+	if m.RangeLookup != nil {
+		l = m.RangeLookup.Size()
+		n += 1 + l + sovApi(uint64(l))
 	}
-	if this.RangeLookup != nil {
-		return this.RangeLookup
+	if m.ResolveIntent != nil {
+		l = m.ResolveIntent.Size()
+		n += 2 + l + sovApi(uint64(l))
 	}
-	if this.ResolveIntent != nil {
-		return this.ResolveIntent
+	if m.ResolveIntentRange != nil {
+		l = m.ResolveIntentRange.Size()
+		n += 2 + l + sovApi(uint64(l))
 	}
-	if this.ResolveIntentRange != nil {
-		return this.ResolveIntentRange
+	if m.Merge != nil {
+		l = m.Merge.Size()
+		n += 2 + l + sovApi(uint64(l))
 	}
-	if this.Merge != nil {
-		return this.Merge
+	if m.TruncateLog != nil {
+		l = m.TruncateLog.Size()
+		n += 2 + l + sovApi(uint64(l))
 	}
-	if this.TruncateLog != nil {
-		return this.TruncateLog
+	if m.LeaderLease != nil {
+		l = m.LeaderLease.Size()
+		n += 2 + l + sovApi(uint64(l))
 	}
-	if this.LeaderLease != nil {
-		return this.LeaderLease
+	if m.ReverseScan != nil {
+		l = m.ReverseScan.Size()
+		n += 2 + l + sovApi(uint64(l))
 	}
-	if this.ReverseScan != nil {
-		return this.ReverseScan
+	if m.ComputeChecksum != nil {
+		l = m.ComputeChecksum.Size()
+		n += 2 + l + sovApi(uint64(l))
 	}
-	if this.ComputeChecksum != nil {
-		return this.ComputeChecksum
+	if m.VerifyChecksum != nil {
+		l = m.VerifyChecksum.Size()
+		n += 2 + l + sovApi(uint64(l))
 	}
-	if this.VerifyChecksum != nil {
-		return this.VerifyChecksum
+	if m.CheckConsistency != nil {
+		l = m.CheckConsistency.Size()
+		n += 2 + l + sovApi(uint64(l))
 	}
-	if this.CheckConsistency != nil {
-		return this.CheckConsistency
+	if m.Noop != nil {
+		l = m.Noop.Size()
+		n += 2 + l + sovApi(uint64(l))
+	}
+	if m.ClearRange != nil {
+		l = m.ClearRange.Size()
+		n += 2 + l + sovApi(uint64(l))
+	}
+	if m.LeaseInfo != nil {
+		l = m.LeaseInfo.Size()
+		n += 2 + l + sovApi(uint64(l))
+	}
+	if m.Export != nil {
+		l = m.Export.Size()
+		n += 2 + l + sovApi(uint64(l))
+	}
+	if m.RecomputeStats != nil {
+		l = m.RecomputeStats.Size()
+		n += 2 + l + sovApi(uint64(l))
+	}
+	return n
+}
+
+func (m *Header) Size() (n int) {
+	var l int
+	_ = l
+	l = m.Timestamp.Size()
+	n += 1 + l + sovApi(uint64(l))
+	l = m.Replica.Size()
+	n += 1 + l + sovApi(uint64(l))
+	n += 1 + sovApi(uint64(m.RangeID))
+	n += 9
+	if m.Txn != nil {
+		l = m.Txn.Size()
+		n += 1 + l + sovApi(uint64(l))
+	}
+	n += 1 + sovApi(uint64(m.ReadConsistency))
+	if m.Trace != nil {
+		l = m.Trace.Size()
+		n += 1 + l + sovApi(uint64(l))
+	}
+	n += 1 + sovApi(uint64(m.MaxScanResults))
+	n += 1 + sovApi(uint64(m.AdmissionClass))
+	n += 1 + sovApi(uint64(m.TargetBytes))
+	return n
+}
+
+func (m *BatchRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = m.Header.Size()
+	n += 1 + l + sovApi(uint64(l))
+	if len(m.Requests) > 0 {
+		for _, e := range m.Requests {
+			l = e.Size()
+			n += 1 + l + sovApi(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *BatchResponse) Size() (n int) {
+	var l int
+	_ = l
+	l = m.BatchResponse_Header.Size()
+	n += 1 + l + sovApi(uint64(l))
+	if len(m.Responses) > 0 {
+		for _, e := range m.Responses {
+			l = e.Size()
+			n += 1 + l + sovApi(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *BatchResponse_Header) Size() (n int) {
+	var l int
+	_ = l
+	if m.Error != nil {
+		l = m.Error.Size()
+		n += 1 + l + sovApi(uint64(l))
+	}
+	if m.Txn != nil {
+		l = m.Txn.Size()
+		n += 1 + l + sovApi(uint64(l))
+	}
+	if len(m.CollectedSpans) > 0 {
+		for _, b := range m.CollectedSpans {
+			l = len(b)
+			n += 1 + l + sovApi(uint64(l))
+		}
+	}
+	return n
+}
+
+func sovApi(x uint64) (n int) {
+	for {
+		n++
+		x >>= 7
+		if x == 0 {
+			break
+		}
+	}
+	return n
+}
+func sozApi(x uint64) (n int) {
+	return sovApi(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (this *RequestUnion) GetValue() interface{} {
+	if this.Get != nil {
+		return this.Get
+	}
+	if this.Put != nil {
+		return this.Put
+	}
+	if this.ConditionalPut != nil {
+		return this.ConditionalPut
+	}
+	if this.Increment != nil {
+		return this.Increment
+	}
+	if this.Delete != nil {
+		return this.Delete
+	}
+	if this.DeleteRange != nil {
+		return this.DeleteRange
+	}
+	if this.Scan != nil {
+		return this.Scan
+	}
+	if this.BeginTransaction != nil {
+		return this.BeginTransaction
+	}
+	if this.EndTransaction != nil {
+		return this.EndTransaction
+	}
+	if this.AdminSplit != nil {
+		return this.AdminSplit
+	}
+	if this.AdminMerge != nil {
+		return this.AdminMerge
+	}
+	if this.HeartbeatTxn != nil {
+		return this.HeartbeatTxn
+	}
+	if this.Gc != nil {
+		return this.Gc
+	}
+	if this.PushTxn != nil {
+		return this.PushTxn
+	}
+	if this.RangeLookup != nil {
+		return this.RangeLookup
+	}
+	if this.ResolveIntent != nil {
+		return this.ResolveIntent
+	}
+	if this.ResolveIntentRange != nil {
+		return this.ResolveIntentRange
+	}
+	if this.Merge != nil {
+		return this.Merge
+	}
+	if this.TruncateLog != nil {
+		return this.TruncateLog
+	}
+	if this.LeaderLease != nil {
+		return this.LeaderLease
+	}
+	if this.ReverseScan != nil {
+		return this.ReverseScan
+	}
+	if this.ComputeChecksum != nil {
+		return this.ComputeChecksum
+	}
+	if this.VerifyChecksum != nil {
+		return this.VerifyChecksum
+	}
+	if this.CheckConsistency != nil {
+		return this.CheckConsistency
+	}
+	if this.Noop != nil {
+		return this.Noop
+	}
+	if this.ClearRange != nil {
+		return this.ClearRange
+	}
+	if this.LeaseInfo != nil {
+		return this.LeaseInfo
+	}
+	if this.Export != nil {
+		return this.Export
+	}
+	if this.RecomputeStats != nil {
+		return this.RecomputeStats
+	}
+	return nil
+}
+
+func (this *RequestUnion) SetValue(value interface{}) bool {
+	switch vt := value.(type) {
+	case *GetRequest:
+		this.Get = vt
+	case *PutRequest:
+		this.Put = vt
+	case *ConditionalPutRequest:
+		this.ConditionalPut = vt
+	case *IncrementRequest:
+		this.Increment = vt
+	case *DeleteRequest:
+		this.Delete = vt
+	case *DeleteRangeRequest:
+		this.DeleteRange = vt
+	case *ScanRequest:
+		this.Scan = vt
+	case *BeginTransactionRequest:
+		this.BeginTransaction = vt
+	case *EndTransactionRequest:
+		this.EndTransaction = vt
+	case *AdminSplitRequest:
+		this.AdminSplit = vt
+	case *AdminMergeRequest:
+		this.AdminMerge = vt
+	case *HeartbeatTxnRequest:
+		this.HeartbeatTxn = vt
+	case *GCRequest:
+		this.Gc = vt
+	case *PushTxnRequest:
+		this.PushTxn = vt
+	case *RangeLookupRequest:
+		this.RangeLookup = vt
+	case *ResolveIntentRequest:
+		this.ResolveIntent = vt
+	case *ResolveIntentRangeRequest:
+		this.ResolveIntentRange = vt
+	case *MergeRequest:
+		this.Merge = vt
+	case *TruncateLogRequest:
+		this.TruncateLog = vt
+	case *LeaderLeaseRequest:
+		this.LeaderLease = vt
+	case *ReverseScanRequest:
+		this.ReverseScan = vt
+	case *ComputeChecksumRequest:
+		this.ComputeChecksum = vt
+	case *VerifyChecksumRequest:
+		this.VerifyChecksum = vt
+	case *CheckConsistencyRequest:
+		this.CheckConsistency = vt
+	case *NoopRequest:
+		this.Noop = vt
+	case *ClearRangeRequest:
+		this.ClearRange = vt
+	case *LeaseInfoRequest:
+		this.LeaseInfo = vt
+	case *ExportRequest:
+		this.Export = vt
+	case *RecomputeStatsRequest:
+		this.RecomputeStats = vt
+	default:
+		return false
+	}
+	return true
+}
+func (this *ResponseUnion) GetValue() interface{} {
+	if this.Get != nil {
+		return this.Get
+	}
+	if this.Put != nil {
+		return this.Put
+	}
+	if this.ConditionalPut != nil {
+		return this.ConditionalPut
+	}
+	if this.Increment != nil {
+		return this.Increment
+	}
+	if this.Delete != nil {
+		return this.Delete
+	}
+	if this.DeleteRange != nil {
+		return this.DeleteRange
+	}
+	if this.Scan != nil {
+		return this.Scan
+	}
+	if this.BeginTransaction != nil {
+		return this.BeginTransaction
+	}
+	if this.EndTransaction != nil {
+		return this.EndTransaction
+	}
+	if this.AdminSplit != nil {
+		return this.AdminSplit
+	}
+	if this.AdminMerge != nil {
+		return this.AdminMerge
+	}
+	if this.HeartbeatTxn != nil {
+		return this.HeartbeatTxn
+	}
+	if this.Gc != nil {
+		return this.Gc
+	}
+	if this.PushTxn != nil {
+		return this.PushTxn
+	}
+	if this.RangeLookup != nil {
+		return this.RangeLookup
+	}
+	if this.ResolveIntent != nil {
+		return this.ResolveIntent
+	}
+	if this.ResolveIntentRange != nil {
+		return this.ResolveIntentRange
+	}
+	if this.Merge != nil {
+		return this.Merge
+	}
+	if this.TruncateLog != nil {
+		return this.TruncateLog
+	}
+	if this.LeaderLease != nil {
+		return this.LeaderLease
+	}
+	if this.ReverseScan != nil {
+		return this.ReverseScan
+	}
+	if this.ComputeChecksum != nil {
+		return this.ComputeChecksum
+	}
+	if this.VerifyChecksum != nil {
+		return this.VerifyChecksum
+	}
+	if this.CheckConsistency != nil {
+		return this.CheckConsistency
+	}
+	if this.Noop != nil {
+		return this.Noop
+	}
+	if this.ClearRange != nil {
+		return this.ClearRange
+	}
+	if this.LeaseInfo != nil {
+		return this.LeaseInfo
+	}
+	if this.Export != nil {
+		return this.Export
+	}
+	if this.RecomputeStats != nil {
+		return this.RecomputeStats
+	}
+	return nil
+}
+
+func (this *ResponseUnion) SetValue(value interface{}) bool {
+	switch vt := value.(type) {
+	case *GetResponse:
+		this.Get = vt
+	case *PutResponse:
+		this.Put = vt
+	case *ConditionalPutResponse:
+		this.ConditionalPut = vt
+	case *IncrementResponse:
+		this.Increment = vt
+	case *DeleteResponse:
+		this.Delete = vt
+	case *DeleteRangeResponse:
+		this.DeleteRange = vt
+	case *ScanResponse:
+		this.Scan = vt
+	case *BeginTransactionResponse:
+		this.BeginTransaction = vt
+	case *EndTransactionResponse:
+		this.EndTransaction = vt
+	case *AdminSplitResponse:
+		this.AdminSplit = vt
+	case *AdminMergeResponse:
+		this.AdminMerge = vt
+	case *HeartbeatTxnResponse:
+		this.HeartbeatTxn = vt
+	case *GCResponse:
+		this.Gc = vt
+	case *PushTxnResponse:
+		this.PushTxn = vt
+	case *RangeLookupResponse:
+		this.RangeLookup = vt
+	case *ResolveIntentResponse:
+		this.ResolveIntent = vt
+	case *ResolveIntentRangeResponse:
+		this.ResolveIntentRange = vt
+	case *MergeResponse:
+		this.Merge = vt
+	case *TruncateLogResponse:
+		this.TruncateLog = vt
+	case *LeaderLeaseResponse:
+		this.LeaderLease = vt
+	case *ReverseScanResponse:
+		this.ReverseScan = vt
+	case *ComputeChecksumResponse:
+		this.ComputeChecksum = vt
+	case *VerifyChecksumResponse:
+		this.VerifyChecksum = vt
+	case *CheckConsistencyResponse:
+		this.CheckConsistency = vt
+	case *NoopResponse:
+		this.Noop = vt
+	case *ClearRangeResponse:
+		this.ClearRange = vt
+	case *LeaseInfoResponse:
+		this.LeaseInfo = vt
+	case *ExportResponse:
+		this.Export = vt
+	case *RecomputeStatsResponse:
+		this.RecomputeStats = vt
+	default:
+		return false
+	}
+	return true
+}
+func (m *ResponseHeader) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowApi
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ResponseHeader: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ResponseHeader: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timestamp", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Timestamp.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Txn", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Txn == nil {
+				m.Txn = &Transaction{}
+			}
+			if err := m.Txn.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ResumeSpan", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ResumeSpan == nil {
+				m.ResumeSpan = &Span{}
+			}
+			if err := m.ResumeSpan.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipApi(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthApi
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetRequest) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowApi
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Span", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Span.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipApi(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthApi
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetResponse) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowApi
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ResponseHeader", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.ResponseHeader.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Value == nil {
+				m.Value = &Value{}
+			}
+			if err := m.Value.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipApi(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthApi
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *PutRequest) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowApi
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PutRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PutRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Span", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Span.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Value.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipApi(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthApi
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *PutResponse) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowApi
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PutResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PutResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ResponseHeader", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.ResponseHeader.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipApi(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthApi
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ConditionalPutRequest) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowApi
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ConditionalPutRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ConditionalPutRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Span", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Span.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Value.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExpValue", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ExpValue == nil {
+				m.ExpValue = &Value{}
+			}
+			if err := m.ExpValue.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipApi(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthApi
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ConditionalPutResponse) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowApi
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ConditionalPutResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ConditionalPutResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ResponseHeader", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.ResponseHeader.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipApi(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthApi
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
 	}
-	if this.Noop != nil {
-		return this.Noop
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
 	}
 	return nil
 }
+func (m *IncrementRequest) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowApi
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: IncrementRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: IncrementRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Span", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Span.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Increment", wireType)
+			}
+			m.Increment = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.Increment |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipApi(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthApi
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
 
-func (this *ResponseUnion) SetValue(value interface{}) bool {
-	switch vt := value.(type) {
-	case *GetResponse:
-		this.Get = vt
-	case *PutResponse:
-		this.Put = vt
-	case *ConditionalPutResponse:
-		this.ConditionalPut = vt
-	case *IncrementResponse:
-		this.Increment = vt
-	case *DeleteResponse:
-		this.Delete = vt
-	case *DeleteRangeResponse:
-		this.DeleteRange = vt
-	case *ScanResponse:
-		this.Scan = vt
-	case *BeginTransactionResponse:
-		this.BeginTransaction = vt
-	case *EndTransactionResponse:
-		this.EndTransaction = vt
-	case *AdminSplitResponse:
-		this.AdminSplit = vt
-	case *AdminMergeResponse:
-		this.AdminMerge = vt
-	case *HeartbeatTxnResponse:
-		this.HeartbeatTxn = vt
-	case *GCResponse:
-		this.Gc = vt
-	case *PushTxnResponse:
-		this.PushTxn = vt
-	case *RangeLookupResponse:
-		this.RangeLookup = vt
-	case *ResolveIntentResponse:
-		this.ResolveIntent = vt
-	case *ResolveIntentRangeResponse:
-		this.ResolveIntentRange = vt
-	case *MergeResponse:
-		this.Merge = vt
-	case *TruncateLogResponse:
-		this.TruncateLog = vt
-	case *LeaderLeaseResponse:
-		this.LeaderLease = vt
-	case *ReverseScanResponse:
-		this.ReverseScan = vt
-	case *ComputeChecksumResponse:
-		this.ComputeChecksum = vt
-	case *VerifyChecksumResponse:
-		this.VerifyChecksum = vt
-	case *CheckConsistencyResponse:
-		this.CheckConsistency = vt
-	case *NoopResponse:
-		this.Noop = vt
-	default:
-		return false
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
 	}
-	return true
+	return nil
 }
-func (m *ResponseHeader) Unmarshal(data []byte) error {
+func (m *IncrementResponse) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -4901,15 +6510,15 @@ func (m *ResponseHeader) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ResponseHeader: wiretype end group for non-group")
+			return fmt.Errorf("proto: IncrementResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ResponseHeader: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: IncrementResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
-		case 2:
+		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Timestamp", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ResponseHeader", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -4933,13 +6542,82 @@ func (m *ResponseHeader) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.Timestamp.Unmarshal(data[iNdEx:postIndex]); err != nil {
+			if err := m.ResponseHeader.Unmarshal(data[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		case 3:
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NewValue", wireType)
+			}
+			m.NewValue = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.NewValue |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipApi(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthApi
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DeleteRequest) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowApi
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DeleteRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DeleteRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Txn", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Span", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -4963,10 +6641,7 @@ func (m *ResponseHeader) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Txn == nil {
-				m.Txn = &Transaction{}
-			}
-			if err := m.Txn.Unmarshal(data[iNdEx:postIndex]); err != nil {
+			if err := m.Span.Unmarshal(data[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -4991,7 +6666,7 @@ func (m *ResponseHeader) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *GetRequest) Unmarshal(data []byte) error {
+func (m *DeleteResponse) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -5014,15 +6689,15 @@ func (m *GetRequest) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: GetRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: DeleteResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: GetRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: DeleteResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Span", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ResponseHeader", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -5046,7 +6721,7 @@ func (m *GetRequest) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.Span.Unmarshal(data[iNdEx:postIndex]); err != nil {
+			if err := m.ResponseHeader.Unmarshal(data[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -5071,7 +6746,7 @@ func (m *GetRequest) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *GetResponse) Unmarshal(data []byte) error {
+func (m *DeleteRangeRequest) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -5094,15 +6769,15 @@ func (m *GetResponse) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: GetResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: DeleteRangeRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: GetResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: DeleteRangeRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ResponseHeader", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Span", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -5126,15 +6801,15 @@ func (m *GetResponse) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.ResponseHeader.Unmarshal(data[iNdEx:postIndex]); err != nil {
+			if err := m.Span.Unmarshal(data[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
 		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxEntriesToDelete", wireType)
 			}
-			var msglen int
+			m.MaxEntriesToDelete = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowApi
@@ -5144,25 +6819,31 @@ func (m *GetResponse) Unmarshal(data []byte) error {
 				}
 				b := data[iNdEx]
 				iNdEx++
-				msglen |= (int(b) & 0x7F) << shift
+				m.MaxEntriesToDelete |= (int64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
-				return ErrInvalidLengthApi
-			}
-			postIndex := iNdEx + msglen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			if m.Value == nil {
-				m.Value = &Value{}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReturnKeys", wireType)
 			}
-			if err := m.Value.Unmarshal(data[iNdEx:postIndex]); err != nil {
-				return err
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-			iNdEx = postIndex
+			m.ReturnKeys = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipApi(data[iNdEx:])
@@ -5184,7 +6865,7 @@ func (m *GetResponse) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *PutRequest) Unmarshal(data []byte) error {
+func (m *DeleteRangeResponse) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -5207,15 +6888,15 @@ func (m *PutRequest) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: PutRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: DeleteRangeResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: PutRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: DeleteRangeResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Span", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ResponseHeader", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -5239,15 +6920,15 @@ func (m *PutRequest) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.Span.Unmarshal(data[iNdEx:postIndex]); err != nil {
+			if err := m.ResponseHeader.Unmarshal(data[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Keys", wireType)
 			}
-			var msglen int
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowApi
@@ -5257,21 +6938,20 @@ func (m *PutRequest) Unmarshal(data []byte) error {
 				}
 				b := data[iNdEx]
 				iNdEx++
-				msglen |= (int(b) & 0x7F) << shift
+				byteLen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			if byteLen < 0 {
 				return ErrInvalidLengthApi
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + byteLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.Value.Unmarshal(data[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.Keys = append(m.Keys, make([]byte, postIndex-iNdEx))
+			copy(m.Keys[len(m.Keys)-1], data[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -5294,7 +6974,7 @@ func (m *PutRequest) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *PutResponse) Unmarshal(data []byte) error {
+func (m *ScanRequest) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -5317,15 +6997,15 @@ func (m *PutResponse) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: PutResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: ScanRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: PutResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ScanRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ResponseHeader", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Span", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -5349,10 +7029,29 @@ func (m *PutResponse) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.ResponseHeader.Unmarshal(data[iNdEx:postIndex]); err != nil {
+			if err := m.Span.Unmarshal(data[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxResults", wireType)
+			}
+			m.MaxResults = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.MaxResults |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipApi(data[iNdEx:])
@@ -5374,7 +7073,7 @@ func (m *PutResponse) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *ConditionalPutRequest) Unmarshal(data []byte) error {
+func (m *ScanResponse) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -5397,15 +7096,15 @@ func (m *ConditionalPutRequest) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ConditionalPutRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: ScanResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ConditionalPutRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ScanResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Span", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ResponseHeader", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -5429,43 +7128,13 @@ func (m *ConditionalPutRequest) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.Span.Unmarshal(data[iNdEx:postIndex]); err != nil {
+			if err := m.ResponseHeader.Unmarshal(data[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
-			}
-			var msglen int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowApi
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := data[iNdEx]
-				iNdEx++
-				msglen |= (int(b) & 0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			if msglen < 0 {
-				return ErrInvalidLengthApi
-			}
-			postIndex := iNdEx + msglen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			if err := m.Value.Unmarshal(data[iNdEx:postIndex]); err != nil {
-				return err
-			}
-			iNdEx = postIndex
-		case 3:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ExpValue", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Rows", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -5489,10 +7158,8 @@ func (m *ConditionalPutRequest) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.ExpValue == nil {
-				m.ExpValue = &Value{}
-			}
-			if err := m.ExpValue.Unmarshal(data[iNdEx:postIndex]); err != nil {
+			m.Rows = append(m.Rows, KeyValue{})
+			if err := m.Rows[len(m.Rows)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -5517,7 +7184,7 @@ func (m *ConditionalPutRequest) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *ConditionalPutResponse) Unmarshal(data []byte) error {
+func (m *ReverseScanRequest) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -5540,15 +7207,15 @@ func (m *ConditionalPutResponse) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ConditionalPutResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: ReverseScanRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ConditionalPutResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ReverseScanRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ResponseHeader", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Span", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -5572,10 +7239,29 @@ func (m *ConditionalPutResponse) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.ResponseHeader.Unmarshal(data[iNdEx:postIndex]); err != nil {
+			if err := m.Span.Unmarshal(data[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxResults", wireType)
+			}
+			m.MaxResults = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.MaxResults |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipApi(data[iNdEx:])
@@ -5597,7 +7283,7 @@ func (m *ConditionalPutResponse) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *IncrementRequest) Unmarshal(data []byte) error {
+func (m *ReverseScanResponse) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -5620,15 +7306,15 @@ func (m *IncrementRequest) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: IncrementRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: ReverseScanResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: IncrementRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ReverseScanResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Span", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ResponseHeader", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -5652,15 +7338,15 @@ func (m *IncrementRequest) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.Span.Unmarshal(data[iNdEx:postIndex]); err != nil {
+			if err := m.ResponseHeader.Unmarshal(data[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
 		case 2:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Increment", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Rows", wireType)
 			}
-			m.Increment = 0
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowApi
@@ -5670,11 +7356,23 @@ func (m *IncrementRequest) Unmarshal(data []byte) error {
 				}
 				b := data[iNdEx]
 				iNdEx++
-				m.Increment |= (int64(b) & 0x7F) << shift
+				msglen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Rows = append(m.Rows, KeyValue{})
+			if err := m.Rows[len(m.Rows)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipApi(data[iNdEx:])
@@ -5696,7 +7394,7 @@ func (m *IncrementRequest) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *IncrementResponse) Unmarshal(data []byte) error {
+func (m *CheckConsistencyRequest) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -5719,47 +7417,17 @@ func (m *IncrementResponse) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: IncrementResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: CheckConsistencyRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: IncrementResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: CheckConsistencyRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ResponseHeader", wireType)
-			}
-			var msglen int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowApi
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := data[iNdEx]
-				iNdEx++
-				msglen |= (int(b) & 0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			if msglen < 0 {
-				return ErrInvalidLengthApi
-			}
-			postIndex := iNdEx + msglen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			if err := m.ResponseHeader.Unmarshal(data[iNdEx:postIndex]); err != nil {
-				return err
-			}
-			iNdEx = postIndex
-		case 2:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field NewValue", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Span", wireType)
 			}
-			m.NewValue = 0
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowApi
@@ -5769,11 +7437,22 @@ func (m *IncrementResponse) Unmarshal(data []byte) error {
 				}
 				b := data[iNdEx]
 				iNdEx++
-				m.NewValue |= (int64(b) & 0x7F) << shift
+				msglen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Span.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipApi(data[iNdEx:])
@@ -5795,7 +7474,7 @@ func (m *IncrementResponse) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *DeleteRequest) Unmarshal(data []byte) error {
+func (m *CheckConsistencyResponse) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -5818,15 +7497,15 @@ func (m *DeleteRequest) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: DeleteRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: CheckConsistencyResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: DeleteRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: CheckConsistencyResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Span", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ResponseHeader", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -5850,7 +7529,7 @@ func (m *DeleteRequest) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.Span.Unmarshal(data[iNdEx:postIndex]); err != nil {
+			if err := m.ResponseHeader.Unmarshal(data[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -5875,7 +7554,7 @@ func (m *DeleteRequest) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *DeleteResponse) Unmarshal(data []byte) error {
+func (m *ClearRangeRequest) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -5898,15 +7577,15 @@ func (m *DeleteResponse) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: DeleteResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: ClearRangeRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: DeleteResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ClearRangeRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ResponseHeader", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Span", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -5930,7 +7609,7 @@ func (m *DeleteResponse) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.ResponseHeader.Unmarshal(data[iNdEx:postIndex]); err != nil {
+			if err := m.Span.Unmarshal(data[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -5955,7 +7634,7 @@ func (m *DeleteResponse) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *DeleteRangeRequest) Unmarshal(data []byte) error {
+func (m *ClearRangeResponse) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -5978,15 +7657,15 @@ func (m *DeleteRangeRequest) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: DeleteRangeRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: ClearRangeResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: DeleteRangeRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ClearRangeResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Span", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ResponseHeader", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -6010,49 +7689,10 @@ func (m *DeleteRangeRequest) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.Span.Unmarshal(data[iNdEx:postIndex]); err != nil {
+			if err := m.ResponseHeader.Unmarshal(data[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		case 2:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field MaxEntriesToDelete", wireType)
-			}
-			m.MaxEntriesToDelete = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowApi
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := data[iNdEx]
-				iNdEx++
-				m.MaxEntriesToDelete |= (int64(b) & 0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-		case 3:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ReturnKeys", wireType)
-			}
-			var v int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowApi
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := data[iNdEx]
-				iNdEx++
-				v |= (int(b) & 0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			m.ReturnKeys = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipApi(data[iNdEx:])
@@ -6074,7 +7714,7 @@ func (m *DeleteRangeRequest) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *DeleteRangeResponse) Unmarshal(data []byte) error {
+func (m *LeaseInfoRequest) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -6097,15 +7737,15 @@ func (m *DeleteRangeResponse) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: DeleteRangeResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: LeaseInfoRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: DeleteRangeResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: LeaseInfoRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ResponseHeader", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Span", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -6129,39 +7769,10 @@ func (m *DeleteRangeResponse) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.ResponseHeader.Unmarshal(data[iNdEx:postIndex]); err != nil {
+			if err := m.Span.Unmarshal(data[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Keys", wireType)
-			}
-			var byteLen int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowApi
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := data[iNdEx]
-				iNdEx++
-				byteLen |= (int(b) & 0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			if byteLen < 0 {
-				return ErrInvalidLengthApi
-			}
-			postIndex := iNdEx + byteLen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Keys = append(m.Keys, make([]byte, postIndex-iNdEx))
-			copy(m.Keys[len(m.Keys)-1], data[iNdEx:postIndex])
-			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipApi(data[iNdEx:])
@@ -6183,7 +7794,7 @@ func (m *DeleteRangeResponse) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *ScanRequest) Unmarshal(data []byte) error {
+func (m *LeaseInfoResponse) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -6206,15 +7817,15 @@ func (m *ScanRequest) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ScanRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: LeaseInfoResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ScanRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: LeaseInfoResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Span", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ResponseHeader", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -6238,15 +7849,15 @@ func (m *ScanRequest) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.Span.Unmarshal(data[iNdEx:postIndex]); err != nil {
+			if err := m.ResponseHeader.Unmarshal(data[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
 		case 2:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field MaxResults", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Lease", wireType)
 			}
-			m.MaxResults = 0
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowApi
@@ -6256,11 +7867,22 @@ func (m *ScanRequest) Unmarshal(data []byte) error {
 				}
 				b := data[iNdEx]
 				iNdEx++
-				m.MaxResults |= (int64(b) & 0x7F) << shift
+				msglen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Lease.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipApi(data[iNdEx:])
@@ -6282,7 +7904,7 @@ func (m *ScanRequest) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *ScanResponse) Unmarshal(data []byte) error {
+func (m *ExportRequest) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -6305,15 +7927,15 @@ func (m *ScanResponse) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ScanResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: ExportRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ScanResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ExportRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ResponseHeader", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Span", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -6337,15 +7959,15 @@ func (m *ScanResponse) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.ResponseHeader.Unmarshal(data[iNdEx:postIndex]); err != nil {
+			if err := m.Span.Unmarshal(data[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Rows", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Dir", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowApi
@@ -6355,22 +7977,20 @@ func (m *ScanResponse) Unmarshal(data []byte) error {
 				}
 				b := data[iNdEx]
 				iNdEx++
-				msglen |= (int(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthApi
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Rows = append(m.Rows, KeyValue{})
-			if err := m.Rows[len(m.Rows)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.Dir = string(data[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -6393,7 +8013,7 @@ func (m *ScanResponse) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *ReverseScanRequest) Unmarshal(data []byte) error {
+func (m *ExportedFile) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -6416,10 +8036,10 @@ func (m *ReverseScanRequest) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ReverseScanRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: ExportedFile: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ReverseScanRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ExportedFile: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -6453,10 +8073,10 @@ func (m *ReverseScanRequest) Unmarshal(data []byte) error {
 			}
 			iNdEx = postIndex
 		case 2:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field MaxResults", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Path", wireType)
 			}
-			m.MaxResults = 0
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowApi
@@ -6466,11 +8086,52 @@ func (m *ReverseScanRequest) Unmarshal(data []byte) error {
 				}
 				b := data[iNdEx]
 				iNdEx++
-				m.MaxResults |= (int64(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Path = string(data[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SST", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			if byteLen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SST = append(m.SST[:0], data[iNdEx:postIndex]...)
+			if m.SST == nil {
+				m.SST = []byte{}
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipApi(data[iNdEx:])
@@ -6492,7 +8153,7 @@ func (m *ReverseScanRequest) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *ReverseScanResponse) Unmarshal(data []byte) error {
+func (m *ExportResponse) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -6515,10 +8176,10 @@ func (m *ReverseScanResponse) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ReverseScanResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: ExportResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ReverseScanResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ExportResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -6553,7 +8214,7 @@ func (m *ReverseScanResponse) Unmarshal(data []byte) error {
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Rows", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Files", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -6577,8 +8238,8 @@ func (m *ReverseScanResponse) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Rows = append(m.Rows, KeyValue{})
-			if err := m.Rows[len(m.Rows)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
+			m.Files = append(m.Files, ExportedFile{})
+			if err := m.Files[len(m.Files)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -6603,7 +8264,7 @@ func (m *ReverseScanResponse) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *CheckConsistencyRequest) Unmarshal(data []byte) error {
+func (m *RecomputeStatsRequest) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -6626,10 +8287,10 @@ func (m *CheckConsistencyRequest) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: CheckConsistencyRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: RecomputeStatsRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: CheckConsistencyRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: RecomputeStatsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -6662,6 +8323,37 @@ func (m *CheckConsistencyRequest) Unmarshal(data []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Delta", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Delta = append(m.Delta[:0], data[iNdEx:postIndex]...)
+			if m.Delta == nil {
+				m.Delta = []byte{}
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipApi(data[iNdEx:])
@@ -6683,7 +8375,7 @@ func (m *CheckConsistencyRequest) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *CheckConsistencyResponse) Unmarshal(data []byte) error {
+func (m *RecomputeStatsResponse) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -6706,10 +8398,10 @@ func (m *CheckConsistencyResponse) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: CheckConsistencyResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: RecomputeStatsResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: CheckConsistencyResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: RecomputeStatsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -7227,6 +8919,26 @@ func (m *EndTransactionResponse) Unmarshal(data []byte) error {
 			m.Resolved = append(m.Resolved, make([]byte, postIndex-iNdEx))
 			copy(m.Resolved[len(m.Resolved)-1], data[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OnePhaseCommit", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.OnePhaseCommit = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipApi(data[iNdEx:])
@@ -11169,6 +12881,138 @@ func (m *RequestUnion) Unmarshal(data []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 26:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ClearRange", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ClearRange == nil {
+				m.ClearRange = &ClearRangeRequest{}
+			}
+			if err := m.ClearRange.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 27:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LeaseInfo", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.LeaseInfo == nil {
+				m.LeaseInfo = &LeaseInfoRequest{}
+			}
+			if err := m.LeaseInfo.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 28:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Export", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Export == nil {
+				m.Export = &ExportRequest{}
+			}
+			if err := m.Export.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 29:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RecomputeStats", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.RecomputeStats == nil {
+				m.RecomputeStats = &RecomputeStatsRequest{}
+			}
+			if err := m.RecomputeStats.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipApi(data[iNdEx:])
@@ -12044,6 +13888,138 @@ func (m *ResponseUnion) Unmarshal(data []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 26:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ClearRange", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ClearRange == nil {
+				m.ClearRange = &ClearRangeResponse{}
+			}
+			if err := m.ClearRange.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 27:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LeaseInfo", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.LeaseInfo == nil {
+				m.LeaseInfo = &LeaseInfoResponse{}
+			}
+			if err := m.LeaseInfo.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 28:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Export", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Export == nil {
+				m.Export = &ExportResponse{}
+			}
+			if err := m.Export.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 29:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RecomputeStats", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.RecomputeStats == nil {
+				m.RecomputeStats = &RecomputeStatsResponse{}
+			}
+			if err := m.RecomputeStats.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipApi(data[iNdEx:])
@@ -12295,6 +14271,44 @@ func (m *Header) Unmarshal(data []byte) error {
 					break
 				}
 			}
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AdmissionClass", wireType)
+			}
+			m.AdmissionClass = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.AdmissionClass |= (AdmissionClass(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TargetBytes", wireType)
+			}
+			m.TargetBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.TargetBytes |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipApi(data[iNdEx:])
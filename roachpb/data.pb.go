@@ -285,6 +285,14 @@ type SplitTrigger struct {
 	// initial_leader_store_id designates the replica which should start
 	// a raft election upon processing this split.
 	InitialLeaderStoreID StoreID `protobuf:"varint,3,opt,name=initial_leader_store_id,casttype=StoreID" json:"initial_leader_store_id"`
+	// left_stats are the marshaled MVCCStats of the first half of the split,
+	// computed by the replica proposing it so that the replicas applying the
+	// split don't have to iterate over its data. orig_stats are the marshaled
+	// MVCCStats of the range at the time they were computed; if they differ
+	// from the stats of the range when the split is applied, commands raced
+	// with the split and the stats are repaired after it.
+	LeftStats []byte `protobuf:"bytes,4,opt,name=left_stats" json:"left_stats,omitempty"`
+	OrigStats []byte `protobuf:"bytes,5,opt,name=orig_stats" json:"orig_stats,omitempty"`
 }
 
 func (m *SplitTrigger) Reset()         { *m = SplitTrigger{} }
@@ -486,6 +494,31 @@ func (m *SequenceCacheEntry) Reset()         { *m = SequenceCacheEntry{} }
 func (m *SequenceCacheEntry) String() string { return proto.CompactTextString(m) }
 func (*SequenceCacheEntry) ProtoMessage()    {}
 
+// TxnWait records that a request of the pusher transaction is blocked on an
+// intent of the pushee transaction after failing to push it.
+type TxnWait struct {
+	Pusher         TxnMeta `protobuf:"bytes,1,opt,name=pusher" json:"pusher"`
+	PusherPriority int32   `protobuf:"varint,2,opt,name=pusher_priority" json:"pusher_priority"`
+	Pushee         TxnMeta `protobuf:"bytes,3,opt,name=pushee" json:"pushee"`
+	// The wall time in nanoseconds at which the pusher started waiting.
+	Since int64 `protobuf:"varint,4,opt,name=since" json:"since"`
+}
+
+func (m *TxnWait) Reset()         { *m = TxnWait{} }
+func (m *TxnWait) String() string { return proto.CompactTextString(m) }
+func (*TxnWait) ProtoMessage()    {}
+
+// TxnWaits contains the transaction waits of a store. It is gossiped to
+// detect deadlocks between transactions blocked on different stores.
+type TxnWaits struct {
+	StoreID StoreID   `protobuf:"varint,1,opt,name=store_id,casttype=StoreID" json:"store_id"`
+	Waits   []TxnWait `protobuf:"bytes,2,rep,name=waits" json:"waits"`
+}
+
+func (m *TxnWaits) Reset()         { *m = TxnWaits{} }
+func (m *TxnWaits) String() string { return proto.CompactTextString(m) }
+func (*TxnWaits) ProtoMessage()    {}
+
 func init() {
 	proto.RegisterType((*Span)(nil), "cockroach.roachpb.Span")
 	proto.RegisterType((*Timestamp)(nil), "cockroach.roachpb.Timestamp")
@@ -502,6 +535,8 @@ func init() {
 	proto.RegisterType((*Intent)(nil), "cockroach.roachpb.Intent")
 	proto.RegisterType((*Lease)(nil), "cockroach.roachpb.Lease")
 	proto.RegisterType((*SequenceCacheEntry)(nil), "cockroach.roachpb.SequenceCacheEntry")
+	proto.RegisterType((*TxnWait)(nil), "cockroach.roachpb.TxnWait")
+	proto.RegisterType((*TxnWaits)(nil), "cockroach.roachpb.TxnWaits")
 	proto.RegisterEnum("cockroach.roachpb.ValueType", ValueType_name, ValueType_value)
 	proto.RegisterEnum("cockroach.roachpb.ReplicaChangeType", ReplicaChangeType_name, ReplicaChangeType_value)
 	proto.RegisterEnum("cockroach.roachpb.IsolationType", IsolationType_name, IsolationType_value)
@@ -691,6 +726,18 @@ func (m *SplitTrigger) MarshalTo(data []byte) (int, error) {
 	data[i] = 0x18
 	i++
 	i = encodeVarintData(data, i, uint64(m.InitialLeaderStoreID))
+	if m.LeftStats != nil {
+		data[i] = 0x22
+		i++
+		i = encodeVarintData(data, i, uint64(len(m.LeftStats)))
+		i += copy(data[i:], m.LeftStats)
+	}
+	if m.OrigStats != nil {
+		data[i] = 0x2a
+		i++
+		i = encodeVarintData(data, i, uint64(len(m.OrigStats)))
+		i += copy(data[i:], m.OrigStats)
+	}
 	return i, nil
 }
 
@@ -1121,6 +1168,79 @@ func (m *SequenceCacheEntry) MarshalTo(data []byte) (int, error) {
 	return i, nil
 }
 
+func (m *TxnWait) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *TxnWait) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	data[i] = 0xa
+	i++
+	i = encodeVarintData(data, i, uint64(m.Pusher.Size()))
+	n26, err := m.Pusher.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n26
+	data[i] = 0x10
+	i++
+	i = encodeVarintData(data, i, uint64(m.PusherPriority))
+	data[i] = 0x1a
+	i++
+	i = encodeVarintData(data, i, uint64(m.Pushee.Size()))
+	n27, err := m.Pushee.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n27
+	data[i] = 0x20
+	i++
+	i = encodeVarintData(data, i, uint64(m.Since))
+	return i, nil
+}
+
+func (m *TxnWaits) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *TxnWaits) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	data[i] = 0x8
+	i++
+	i = encodeVarintData(data, i, uint64(m.StoreID))
+	if len(m.Waits) > 0 {
+		for _, msg := range m.Waits {
+			data[i] = 0x12
+			i++
+			i = encodeVarintData(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
 func encodeFixed64Data(data []byte, offset int, v uint64) int {
 	data[offset] = uint8(v)
 	data[offset+1] = uint8(v >> 8)
@@ -1212,6 +1332,14 @@ func (m *SplitTrigger) Size() (n int) {
 	l = m.NewDesc.Size()
 	n += 1 + l + sovData(uint64(l))
 	n += 1 + sovData(uint64(m.InitialLeaderStoreID))
+	if m.LeftStats != nil {
+		l = len(m.LeftStats)
+		n += 1 + l + sovData(uint64(l))
+	}
+	if m.OrigStats != nil {
+		l = len(m.OrigStats)
+		n += 1 + l + sovData(uint64(l))
+	}
 	return n
 }
 
@@ -1360,6 +1488,31 @@ func (m *SequenceCacheEntry) Size() (n int) {
 	return n
 }
 
+func (m *TxnWait) Size() (n int) {
+	var l int
+	_ = l
+	l = m.Pusher.Size()
+	n += 1 + l + sovData(uint64(l))
+	n += 1 + sovData(uint64(m.PusherPriority))
+	l = m.Pushee.Size()
+	n += 1 + l + sovData(uint64(l))
+	n += 1 + sovData(uint64(m.Since))
+	return n
+}
+
+func (m *TxnWaits) Size() (n int) {
+	var l int
+	_ = l
+	n += 1 + sovData(uint64(m.StoreID))
+	if len(m.Waits) > 0 {
+		for _, e := range m.Waits {
+			l = e.Size()
+			n += 1 + l + sovData(uint64(l))
+		}
+	}
+	return n
+}
+
 func sovData(x uint64) (n int) {
 	for {
 		n++
@@ -2021,6 +2174,68 @@ func (m *SplitTrigger) Unmarshal(data []byte) error {
 					break
 				}
 			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LeftStats", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowData
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthData
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.LeftStats = append(m.LeftStats[:0], data[iNdEx:postIndex]...)
+			if m.LeftStats == nil {
+				m.LeftStats = []byte{}
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OrigStats", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowData
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthData
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.OrigStats = append(m.OrigStats[:0], data[iNdEx:postIndex]...)
+			if m.OrigStats == nil {
+				m.OrigStats = []byte{}
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipData(data[iNdEx:])
@@ -3530,6 +3745,254 @@ func (m *SequenceCacheEntry) Unmarshal(data []byte) error {
 	}
 	return nil
 }
+func (m *TxnWait) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowData
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TxnWait: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TxnWait: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Pusher", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowData
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthData
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Pusher.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PusherPriority", wireType)
+			}
+			m.PusherPriority = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowData
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.PusherPriority |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Pushee", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowData
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthData
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Pushee.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Since", wireType)
+			}
+			m.Since = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowData
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.Since |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipData(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthData
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TxnWaits) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowData
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TxnWaits: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TxnWaits: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StoreID", wireType)
+			}
+			m.StoreID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowData
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.StoreID |= (StoreID(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Waits", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowData
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthData
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Waits = append(m.Waits, TxnWait{})
+			if err := m.Waits[len(m.Waits)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipData(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthData
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipData(data []byte) (n int, err error) {
 	l := len(data)
 	iNdEx := 0
@@ -573,3 +573,127 @@ func (*ExistingSchemaChangeLeaseError) message(_ *Error) string {
 }
 
 var _ ErrorDetailInterface = &ExistingSchemaChangeLeaseError{}
+
+// NewRetryBudgetExceededError initializes a new RetryBudgetExceededError for
+// the range with the given ID, to which the part rs of a batch was sent in
+// the given number of attempts. lastErr is the last error encountered on
+// the range, if any.
+func NewRetryBudgetExceededError(rangeID RangeID, rs RSpan, attempts int, deadlineExceeded bool,
+	lastErr *Error) *RetryBudgetExceededError {
+	e := &RetryBudgetExceededError{
+		RangeID:          rangeID,
+		Key:              Key(rs.Key),
+		EndKey:           Key(rs.EndKey),
+		Attempts:         int32(attempts),
+		DeadlineExceeded: deadlineExceeded,
+	}
+	if lastErr != nil {
+		e.ErrorClass = errorClass(lastErr.GetDetail())
+		e.LastError = lastErr.Message
+	}
+	return e
+}
+
+// errorClass returns the class of an error detail, a name which, unlike its
+// type, is stable across releases. Errors of unknown types are of class
+// "Error".
+func errorClass(detail error) string {
+	switch detail.(type) {
+	case *SendError:
+		return "SendError"
+	case *NodeUnavailableError:
+		return "NodeUnavailableError"
+	case *NotLeaderError:
+		return "NotLeaderError"
+	case *LeaseRejectedError:
+		return "LeaseRejectedError"
+	case *RangeNotFoundError:
+		return "RangeNotFoundError"
+	case *RangeKeyMismatchError:
+		return "RangeKeyMismatchError"
+	case *RaftGroupDeletedError:
+		return "RaftGroupDeletedError"
+	case *ReplicaCorruptionError:
+		return "ReplicaCorruptionError"
+	case *TransactionAbortedError:
+		return "TransactionAbortedError"
+	case *TransactionPushError:
+		return "TransactionPushError"
+	case *TransactionRetryError:
+		return "TransactionRetryError"
+	case *TransactionStatusError:
+		return "TransactionStatusError"
+	case *WriteIntentError:
+		return "WriteIntentError"
+	case *WriteTooOldError:
+		return "WriteTooOldError"
+	case *ReadWithinUncertaintyIntervalError:
+		return "ReadWithinUncertaintyIntervalError"
+	case *OpRequiresTxnError:
+		return "OpRequiresTxnError"
+	case *ConditionFailedError:
+		return "ConditionFailedError"
+	case *AmbiguousResultError:
+		return "AmbiguousResultError"
+	}
+	return "Error"
+}
+
+// Error formats error.
+func (e *RetryBudgetExceededError) Error() string {
+	return e.message(nil)
+}
+
+// message returns an error message.
+func (e *RetryBudgetExceededError) message(_ *Error) string {
+	budget := "retry budget"
+	if e.DeadlineExceeded {
+		budget = "deadline"
+	}
+	msg := fmt.Sprintf("%s exceeded after %d attempts on range %d [%s,%s)",
+		budget, e.Attempts, e.RangeID, e.Key, e.EndKey)
+	if e.ErrorClass == "" {
+		return msg + ": range descriptors kept turning out stale"
+	}
+	return fmt.Sprintf("%s: last error (%s): %s", msg, e.ErrorClass, e.LastError)
+}
+
+// unavailableErrorClasses are the classes of errors returned when the
+// cluster fails to serve a range: its replicas are unreachable, or its
+// leadership or descriptor keeps changing.
+var unavailableErrorClasses = map[string]bool{
+	"":                      true,
+	"SendError":             true,
+	"NodeUnavailableError":  true,
+	"NotLeaderError":        true,
+	"RangeNotFoundError":    true,
+	"RangeKeyMismatchError": true,
+}
+
+// ClusterUnavailable returns true if the budget was exhausted by errors
+// indicating that the cluster failed to serve the range, as opposed to
+// errors returned by the range for the request itself, e.g. because the
+// request is too large to be served.
+func (e *RetryBudgetExceededError) ClusterUnavailable() bool {
+	return unavailableErrorClasses[e.ErrorClass]
+}
+
+var _ ErrorDetailInterface = &RetryBudgetExceededError{}
+
+// NewAmbiguousResultError initializes a new AmbiguousResultError with the
+// given description of the failure.
+func NewAmbiguousResultError(msg string) *AmbiguousResultError {
+	return &AmbiguousResultError{Message: msg}
+}
+
+// Error formats error.
+func (e *AmbiguousResultError) Error() string {
+	return e.message(nil)
+}
+
+// message returns an error message.
+func (e *AmbiguousResultError) message(_ *Error) string {
+	return "result is ambiguous: " + e.Message
+}
+
+var _ ErrorDetailInterface = &AmbiguousResultError{}
@@ -293,6 +293,38 @@ func (m *ExistingSchemaChangeLeaseError) Reset()         { *m = ExistingSchemaCh
 func (m *ExistingSchemaChangeLeaseError) String() string { return proto.CompactTextString(m) }
 func (*ExistingSchemaChangeLeaseError) ProtoMessage()    {}
 
+// A RetryBudgetExceededError indicates that the DistSender gave up
+// retrying a batch on a range after exhausting its retry budget or
+// deadline. The class of the last error encountered on the range lets
+// callers tell a cluster failing to serve the range from a request which
+// the range failed to serve.
+type RetryBudgetExceededError struct {
+	RangeID          RangeID `protobuf:"varint,1,opt,name=range_id,casttype=RangeID" json:"range_id"`
+	Key              Key     `protobuf:"bytes,2,opt,name=key,casttype=Key" json:"key,omitempty"`
+	EndKey           Key     `protobuf:"bytes,3,opt,name=end_key,casttype=Key" json:"end_key,omitempty"`
+	Attempts         int32   `protobuf:"varint,4,opt,name=attempts" json:"attempts"`
+	DeadlineExceeded bool    `protobuf:"varint,5,opt,name=deadline_exceeded" json:"deadline_exceeded"`
+	ErrorClass       string  `protobuf:"bytes,6,opt,name=error_class" json:"error_class"`
+	LastError        string  `protobuf:"bytes,7,opt,name=last_error" json:"last_error"`
+}
+
+func (m *RetryBudgetExceededError) Reset()         { *m = RetryBudgetExceededError{} }
+func (m *RetryBudgetExceededError) String() string { return proto.CompactTextString(m) }
+func (*RetryBudgetExceededError) ProtoMessage()    {}
+
+// An AmbiguousResultError indicates that a batch containing an
+// EndTransaction request failed in a state where it may nonetheless have
+// been applied, e.g. because the RPC timed out after reaching the leader.
+// The transaction may or may not have committed, so it must neither be
+// retried nor rolled back by the client.
+type AmbiguousResultError struct {
+	Message string `protobuf:"bytes,1,opt,name=message" json:"message"`
+}
+
+func (m *AmbiguousResultError) Reset()         { *m = AmbiguousResultError{} }
+func (m *AmbiguousResultError) String() string { return proto.CompactTextString(m) }
+func (*AmbiguousResultError) ProtoMessage()    {}
+
 // ErrorDetail is a union type containing all available errors.
 type ErrorDetail struct {
 	NotLeader                     *NotLeaderError                     `protobuf:"bytes,1,opt,name=not_leader" json:"not_leader,omitempty"`
@@ -318,6 +350,8 @@ type ErrorDetail struct {
 	DidntUpdateDescriptor     *DidntUpdateDescriptorError     `protobuf:"bytes,19,opt,name=didnt_update_descriptor" json:"didnt_update_descriptor,omitempty"`
 	SqlTranasctionAborted     *SqlTransactionAbortedError     `protobuf:"bytes,20,opt,name=sql_tranasction_aborted" json:"sql_tranasction_aborted,omitempty"`
 	ExistingSchemeChangeLease *ExistingSchemaChangeLeaseError `protobuf:"bytes,21,opt,name=existing_scheme_change_lease" json:"existing_scheme_change_lease,omitempty"`
+	RetryBudgetExceeded       *RetryBudgetExceededError       `protobuf:"bytes,22,opt,name=retry_budget_exceeded" json:"retry_budget_exceeded,omitempty"`
+	AmbiguousResult           *AmbiguousResultError           `protobuf:"bytes,23,opt,name=ambiguous_result" json:"ambiguous_result,omitempty"`
 }
 
 func (m *ErrorDetail) Reset()         { *m = ErrorDetail{} }
@@ -383,6 +417,8 @@ func init() {
 	proto.RegisterType((*DidntUpdateDescriptorError)(nil), "cockroach.roachpb.DidntUpdateDescriptorError")
 	proto.RegisterType((*SqlTransactionAbortedError)(nil), "cockroach.roachpb.SqlTransactionAbortedError")
 	proto.RegisterType((*ExistingSchemaChangeLeaseError)(nil), "cockroach.roachpb.ExistingSchemaChangeLeaseError")
+	proto.RegisterType((*RetryBudgetExceededError)(nil), "cockroach.roachpb.RetryBudgetExceededError")
+	proto.RegisterType((*AmbiguousResultError)(nil), "cockroach.roachpb.AmbiguousResultError")
 	proto.RegisterType((*ErrorDetail)(nil), "cockroach.roachpb.ErrorDetail")
 	proto.RegisterType((*ErrPosition)(nil), "cockroach.roachpb.ErrPosition")
 	proto.RegisterType((*Error)(nil), "cockroach.roachpb.Error")
@@ -932,6 +968,80 @@ func (m *ExistingSchemaChangeLeaseError) MarshalTo(data []byte) (int, error) {
 	return i, nil
 }
 
+func (m *RetryBudgetExceededError) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *RetryBudgetExceededError) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	data[i] = 0x8
+	i++
+	i = encodeVarintErrors(data, i, uint64(m.RangeID))
+	if m.Key != nil {
+		data[i] = 0x12
+		i++
+		i = encodeVarintErrors(data, i, uint64(len(m.Key)))
+		i += copy(data[i:], m.Key)
+	}
+	if m.EndKey != nil {
+		data[i] = 0x1a
+		i++
+		i = encodeVarintErrors(data, i, uint64(len(m.EndKey)))
+		i += copy(data[i:], m.EndKey)
+	}
+	data[i] = 0x20
+	i++
+	i = encodeVarintErrors(data, i, uint64(m.Attempts))
+	data[i] = 0x28
+	i++
+	if m.DeadlineExceeded {
+		data[i] = 1
+	} else {
+		data[i] = 0
+	}
+	i++
+	data[i] = 0x32
+	i++
+	i = encodeVarintErrors(data, i, uint64(len(m.ErrorClass)))
+	i += copy(data[i:], m.ErrorClass)
+	data[i] = 0x3a
+	i++
+	i = encodeVarintErrors(data, i, uint64(len(m.LastError)))
+	i += copy(data[i:], m.LastError)
+	return i, nil
+}
+
+func (m *AmbiguousResultError) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *AmbiguousResultError) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	data[i] = 0xa
+	i++
+	i = encodeVarintErrors(data, i, uint64(len(m.Message)))
+	i += copy(data[i:], m.Message)
+	return i, nil
+}
+
 func (m *ErrorDetail) Marshal() (data []byte, err error) {
 	size := m.Size()
 	data = make([]byte, size)
@@ -1169,6 +1279,30 @@ func (m *ErrorDetail) MarshalTo(data []byte) (int, error) {
 		}
 		i += n32
 	}
+	if m.RetryBudgetExceeded != nil {
+		data[i] = 0xb2
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintErrors(data, i, uint64(m.RetryBudgetExceeded.Size()))
+		n36, err := m.RetryBudgetExceeded.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n36
+	}
+	if m.AmbiguousResult != nil {
+		data[i] = 0xba
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintErrors(data, i, uint64(m.AmbiguousResult.Size()))
+		n37, err := m.AmbiguousResult.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n37
+	}
 	return i, nil
 }
 
@@ -1469,6 +1603,35 @@ func (m *ExistingSchemaChangeLeaseError) Size() (n int) {
 	return n
 }
 
+func (m *RetryBudgetExceededError) Size() (n int) {
+	var l int
+	_ = l
+	n += 1 + sovErrors(uint64(m.RangeID))
+	if m.Key != nil {
+		l = len(m.Key)
+		n += 1 + l + sovErrors(uint64(l))
+	}
+	if m.EndKey != nil {
+		l = len(m.EndKey)
+		n += 1 + l + sovErrors(uint64(l))
+	}
+	n += 1 + sovErrors(uint64(m.Attempts))
+	n += 2
+	l = len(m.ErrorClass)
+	n += 1 + l + sovErrors(uint64(l))
+	l = len(m.LastError)
+	n += 1 + l + sovErrors(uint64(l))
+	return n
+}
+
+func (m *AmbiguousResultError) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Message)
+	n += 1 + l + sovErrors(uint64(l))
+	return n
+}
+
 func (m *ErrorDetail) Size() (n int) {
 	var l int
 	_ = l
@@ -1556,6 +1719,14 @@ func (m *ErrorDetail) Size() (n int) {
 		l = m.ExistingSchemeChangeLease.Size()
 		n += 2 + l + sovErrors(uint64(l))
 	}
+	if m.RetryBudgetExceeded != nil {
+		l = m.RetryBudgetExceeded.Size()
+		n += 2 + l + sovErrors(uint64(l))
+	}
+	if m.AmbiguousResult != nil {
+		l = m.AmbiguousResult.Size()
+		n += 2 + l + sovErrors(uint64(l))
+	}
 	return n
 }
 
@@ -1666,6 +1837,12 @@ func (this *ErrorDetail) GetValue() interface{} {
 	if this.ExistingSchemeChangeLease != nil {
 		return this.ExistingSchemeChangeLease
 	}
+	if this.RetryBudgetExceeded != nil {
+		return this.RetryBudgetExceeded
+	}
+	if this.AmbiguousResult != nil {
+		return this.AmbiguousResult
+	}
 	return nil
 }
 
@@ -1713,6 +1890,10 @@ func (this *ErrorDetail) SetValue(value interface{}) bool {
 		this.SqlTranasctionAborted = vt
 	case *ExistingSchemaChangeLeaseError:
 		this.ExistingSchemeChangeLease = vt
+	case *RetryBudgetExceededError:
+		this.RetryBudgetExceeded = vt
+	case *AmbiguousResultError:
+		this.AmbiguousResult = vt
 	default:
 		return false
 	}
@@ -3417,6 +3598,313 @@ func (m *ExistingSchemaChangeLeaseError) Unmarshal(data []byte) error {
 	}
 	return nil
 }
+func (m *RetryBudgetExceededError) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowErrors
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RetryBudgetExceededError: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RetryBudgetExceededError: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RangeID", wireType)
+			}
+			m.RangeID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrors
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.RangeID |= (RangeID(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrors
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthErrors
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = append(m.Key[:0], data[iNdEx:postIndex]...)
+			if m.Key == nil {
+				m.Key = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field EndKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrors
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthErrors
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.EndKey = append(m.EndKey[:0], data[iNdEx:postIndex]...)
+			if m.EndKey == nil {
+				m.EndKey = []byte{}
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Attempts", wireType)
+			}
+			m.Attempts = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrors
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.Attempts |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DeadlineExceeded", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrors
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.DeadlineExceeded = bool(v != 0)
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ErrorClass", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrors
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthErrors
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ErrorClass = string(data[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LastError", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrors
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthErrors
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.LastError = string(data[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipErrors(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthErrors
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *AmbiguousResultError) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowErrors
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: AmbiguousResultError: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: AmbiguousResultError: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrors
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthErrors
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Message = string(data[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipErrors(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthErrors
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *ErrorDetail) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
@@ -4139,6 +4627,72 @@ func (m *ErrorDetail) Unmarshal(data []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 22:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RetryBudgetExceeded", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrors
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthErrors
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.RetryBudgetExceeded == nil {
+				m.RetryBudgetExceeded = &RetryBudgetExceededError{}
+			}
+			if err := m.RetryBudgetExceeded.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 23:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AmbiguousResult", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowErrors
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthErrors
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.AmbiguousResult == nil {
+				m.AmbiguousResult = &AmbiguousResultError{}
+			}
+			if err := m.AmbiguousResult.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipErrors(data[iNdEx:])
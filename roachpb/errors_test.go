@@ -94,3 +94,38 @@ func TestStripErrorTransaction(t *testing.T) {
 		t.Errorf("unexpected message: %s", pErr.Message)
 	}
 }
+
+// TestRetryBudgetExceededError verifies that the class of the last error is
+// recorded and tells unavailable clusters from failed requests.
+func TestRetryBudgetExceededError(t *testing.T) {
+	rs := RSpan{Key: RKey("a"), EndKey: RKey("b")}
+	testCases := []struct {
+		lastErr     *Error
+		class       string
+		unavailable bool
+	}{
+		{nil, "", true},
+		{NewError(NewSendError("boom", true)), "SendError", true},
+		{NewError(NewRangeNotFoundError(1)), "RangeNotFoundError", true},
+		{NewError(&NotLeaderError{}), "NotLeaderError", true},
+		{NewError(&TransactionPushError{}), "TransactionPushError", false},
+		{NewError(&ConditionFailedError{}), "ConditionFailedError", false},
+		{NewErrorf("boom"), "Error", false},
+	}
+	for i, c := range testCases {
+		pErr := NewError(NewRetryBudgetExceededError(1, rs, 3, false, c.lastErr))
+		e, ok := pErr.GetDetail().(*RetryBudgetExceededError)
+		if !ok {
+			t.Fatalf("%d: expected a RetryBudgetExceededError, got %T", i, pErr.GetDetail())
+		}
+		if e.ErrorClass != c.class {
+			t.Errorf("%d: expected class %q, got %q", i, c.class, e.ErrorClass)
+		}
+		if e.ClusterUnavailable() != c.unavailable {
+			t.Errorf("%d: expected unavailable=%t", i, c.unavailable)
+		}
+		if !strings.Contains(pErr.Message, "retry budget exceeded after 3 attempts on range 1") {
+			t.Errorf("%d: unexpected message %q", i, pErr.Message)
+		}
+	}
+}
@@ -24,6 +24,11 @@ type RaftCommand struct {
 	RangeID       RangeID           `protobuf:"varint,1,opt,name=range_id,casttype=RangeID" json:"range_id"`
 	OriginReplica ReplicaDescriptor `protobuf:"bytes,2,opt,name=origin_replica" json:"origin_replica"`
 	Cmd           BatchRequest      `protobuf:"bytes,3,opt,name=cmd" json:"cmd"`
+	// The timestamp at or below which the proposing leader guarantees that
+	// no further writes will be applied to the range. Replicas may serve
+	// consistent reads at or below the maximum closed timestamp they have
+	// applied. Zero if closed timestamps are disabled.
+	ClosedTimestamp Timestamp `protobuf:"bytes,4,opt,name=closed_timestamp" json:"closed_timestamp"`
 }
 
 func (m *RaftCommand) Reset()         { *m = RaftCommand{} }
@@ -117,6 +122,14 @@ func (m *RaftCommand) MarshalTo(data []byte) (int, error) {
 		return 0, err
 	}
 	i += n2
+	data[i] = 0x22
+	i++
+	i = encodeVarintInternalRaft(data, i, uint64(m.ClosedTimestamp.Size()))
+	n3, err := m.ClosedTimestamp.MarshalTo(data[i:])
+	if err != nil {
+		return 0, err
+	}
+	i += n3
 	return i, nil
 }
 
@@ -183,11 +196,11 @@ func (m *RaftSnapshotData) MarshalTo(data []byte) (int, error) {
 	data[i] = 0xa
 	i++
 	i = encodeVarintInternalRaft(data, i, uint64(m.RangeDescriptor.Size()))
-	n3, err := m.RangeDescriptor.MarshalTo(data[i:])
+	n4, err := m.RangeDescriptor.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n3
+	i += n4
 	if len(m.KV) > 0 {
 		for _, msg := range m.KV {
 			data[i] = 0x12
@@ -245,11 +258,11 @@ func (m *RaftSnapshotData_KeyValue) MarshalTo(data []byte) (int, error) {
 	data[i] = 0x1a
 	i++
 	i = encodeVarintInternalRaft(data, i, uint64(m.Timestamp.Size()))
-	n4, err := m.Timestamp.MarshalTo(data[i:])
+	n5, err := m.Timestamp.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n4
+	i += n5
 	return i, nil
 }
 
@@ -288,6 +301,8 @@ func (m *RaftCommand) Size() (n int) {
 	n += 1 + l + sovInternalRaft(uint64(l))
 	l = m.Cmd.Size()
 	n += 1 + l + sovInternalRaft(uint64(l))
+	l = m.ClosedTimestamp.Size()
+	n += 1 + l + sovInternalRaft(uint64(l))
 	return n
 }
 
@@ -463,6 +478,36 @@ func (m *RaftCommand) Unmarshal(data []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ClosedTimestamp", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowInternalRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthInternalRaft
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.ClosedTimestamp.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipInternalRaft(data[iNdEx:])
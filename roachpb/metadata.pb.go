@@ -37,6 +37,12 @@ type ReplicaDescriptor struct {
 	// a store and then re-added to the same store, the new instance will have a
 	// higher replica_id.
 	ReplicaID ReplicaID `protobuf:"varint,3,opt,name=replica_id,casttype=ReplicaID" json:"replica_id"`
+	// learner is set on a replica which was added to replace another replica
+	// of the range and has not caught up yet. Learners are not part of the
+	// range's raft group, are not eligible for the leader lease and do not
+	// count towards the range's replication factor. A learner is promoted once
+	// it has applied the snapshot it was sent.
+	Learner bool `protobuf:"varint,4,opt,name=learner" json:"learner"`
 }
 
 func (m *ReplicaDescriptor) Reset()         { *m = ReplicaDescriptor{} }
@@ -95,6 +101,16 @@ type StoreCapacity struct {
 	Capacity   int64 `protobuf:"varint,1,opt,name=capacity" json:"capacity"`
 	Available  int64 `protobuf:"varint,2,opt,name=available" json:"available"`
 	RangeCount int32 `protobuf:"varint,3,opt,name=range_count" json:"range_count"`
+	// The read amplification of the engine, i.e. the number of files a point
+	// lookup may have to consult: one per L0 file plus one per other
+	// non-empty level.
+	ReadAmplification int32 `protobuf:"varint,4,opt,name=read_amplification" json:"read_amplification"`
+	// The estimated number of bytes compactions need to rewrite to bring all
+	// levels of the engine down under their target size.
+	PendingCompactionBytes int64 `protobuf:"varint,5,opt,name=pending_compaction_bytes" json:"pending_compaction_bytes"`
+	// The number of files in level 0 of the engine. RocksDB stalls writes
+	// when too many of them accumulate.
+	L0FileCount int32 `protobuf:"varint,6,opt,name=l0_file_count" json:"l0_file_count"`
 }
 
 func (m *StoreCapacity) Reset()         { *m = StoreCapacity{} }
@@ -192,6 +208,14 @@ func (m *ReplicaDescriptor) MarshalTo(data []byte) (int, error) {
 	data[i] = 0x18
 	i++
 	i = encodeVarintMetadata(data, i, uint64(m.ReplicaID))
+	data[i] = 0x20
+	i++
+	if m.Learner {
+		data[i] = 1
+	} else {
+		data[i] = 0
+	}
+	i++
 	return i, nil
 }
 
@@ -341,6 +365,15 @@ func (m *StoreCapacity) MarshalTo(data []byte) (int, error) {
 	data[i] = 0x18
 	i++
 	i = encodeVarintMetadata(data, i, uint64(m.RangeCount))
+	data[i] = 0x20
+	i++
+	i = encodeVarintMetadata(data, i, uint64(m.ReadAmplification))
+	data[i] = 0x28
+	i++
+	i = encodeVarintMetadata(data, i, uint64(m.PendingCompactionBytes))
+	data[i] = 0x30
+	i++
+	i = encodeVarintMetadata(data, i, uint64(m.L0FileCount))
 	return i, nil
 }
 
@@ -471,6 +504,7 @@ func (m *ReplicaDescriptor) Size() (n int) {
 	n += 1 + sovMetadata(uint64(m.NodeID))
 	n += 1 + sovMetadata(uint64(m.StoreID))
 	n += 1 + sovMetadata(uint64(m.ReplicaID))
+	n += 2
 	return n
 }
 
@@ -535,6 +569,9 @@ func (m *StoreCapacity) Size() (n int) {
 	n += 1 + sovMetadata(uint64(m.Capacity))
 	n += 1 + sovMetadata(uint64(m.Available))
 	n += 1 + sovMetadata(uint64(m.RangeCount))
+	n += 1 + sovMetadata(uint64(m.ReadAmplification))
+	n += 1 + sovMetadata(uint64(m.PendingCompactionBytes))
+	n += 1 + sovMetadata(uint64(m.L0FileCount))
 	return n
 }
 
@@ -740,6 +777,26 @@ func (m *ReplicaDescriptor) Unmarshal(data []byte) error {
 					break
 				}
 			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Learner", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetadata
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Learner = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMetadata(data[iNdEx:])
@@ -1303,6 +1360,63 @@ func (m *StoreCapacity) Unmarshal(data []byte) error {
 					break
 				}
 			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReadAmplification", wireType)
+			}
+			m.ReadAmplification = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetadata
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.ReadAmplification |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PendingCompactionBytes", wireType)
+			}
+			m.PendingCompactionBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetadata
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.PendingCompactionBytes |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field L0FileCount", wireType)
+			}
+			m.L0FileCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetadata
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.L0FileCount |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMetadata(data[iNdEx:])
@@ -111,4 +111,15 @@ const (
 	// CheckConsistency verifies the consistency of all ranges falling within a
 	// key span.
 	CheckConsistency
+	// ClearRange removes all values, including all of their versions, in a
+	// key span.
+	ClearRange
+	// LeaseInfo returns the lease in effect for a range.
+	LeaseInfo
+	// Export writes the latest values of the keys in a key span as of a
+	// timestamp to SSTables.
+	Export
+	// RecomputeStats recomputes the MVCC stats of a range and repairs them
+	// if they have drifted.
+	RecomputeStats
 )
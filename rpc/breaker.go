@@ -0,0 +1,131 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package rpc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/util/metric"
+)
+
+const (
+	// defaultBreakerThreshold is the number of consecutive failed RPCs to a
+	// remote address after which its breaker trips.
+	defaultBreakerThreshold = 5
+	// defaultBreakerCooldown is the duration for which RPCs to a remote
+	// address are rejected once its breaker tripped.
+	defaultBreakerCooldown = 5 * time.Second
+)
+
+// Breakers keeps a circuit breaker per remote address. A breaker trips
+// after Threshold consecutive RPCs to its address failed, after which the
+// address is reported as not ready for Cooldown. Once the cooldown elapsed,
+// RPCs are let through again, but a single further failure trips the
+// breaker anew; a success resets it.
+type Breakers struct {
+	Threshold int
+	Cooldown  time.Duration
+
+	// now is the clock of the breakers, replaced in tests.
+	now func() time.Time
+
+	registry *metric.Registry
+	tripped  *metric.Counter
+	rejected *metric.Counter
+
+	mu       sync.Mutex
+	breakers map[string]*breaker // Maps remote string addr to breaker.
+}
+
+// breaker is the state of the circuit breaker of a remote address.
+type breaker struct {
+	failures  int
+	openUntil time.Time
+}
+
+func newBreakers() *Breakers {
+	b := &Breakers{
+		Threshold: defaultBreakerThreshold,
+		Cooldown:  defaultBreakerCooldown,
+		now:       time.Now,
+		registry:  metric.NewRegistry(),
+		breakers:  map[string]*breaker{},
+	}
+	b.tripped = b.registry.Counter("breaker.tripped")
+	b.rejected = b.registry.Counter("breaker.rejected")
+	b.registry.GaugeFunc("breaker.open", b.numOpen)
+	return b
+}
+
+// Registry returns the registry of the metrics of the breakers: the number
+// of times breakers tripped, the number of RPCs rejected by open breakers
+// and the number of breakers currently open.
+func (b *Breakers) Registry() *metric.Registry {
+	return b.registry
+}
+
+// Ready returns false if the breaker of the remote address is open, in
+// which case RPCs should not be sent to the address. Rejections are
+// counted, so callers should only ask when about to send.
+func (b *Breakers) Ready(addr string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	br, ok := b.breakers[addr]
+	if !ok || !b.now().Before(br.openUntil) {
+		return true
+	}
+	b.rejected.Inc(1)
+	return false
+}
+
+// Success records a successful RPC to the remote address, which resets its
+// breaker.
+func (b *Breakers) Success(addr string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.breakers, addr)
+}
+
+// Failure records a failed RPC to the remote address, which trips its
+// breaker after Threshold consecutive failures.
+func (b *Breakers) Failure(addr string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	br, ok := b.breakers[addr]
+	if !ok {
+		br = &breaker{}
+		b.breakers[addr] = br
+	}
+	br.failures++
+	if br.failures >= b.Threshold {
+		br.openUntil = b.now().Add(b.Cooldown)
+		b.tripped.Inc(1)
+	}
+}
+
+// numOpen returns the number of breakers which are currently open.
+func (b *Breakers) numOpen() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	var n int64
+	for _, br := range b.breakers {
+		if now.Before(br.openUntil) {
+			n++
+		}
+	}
+	return n
+}
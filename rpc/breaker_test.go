@@ -0,0 +1,78 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package rpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+func TestBreakers(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	b := newBreakers()
+	now := time.Unix(0, 0)
+	b.now = func() time.Time { return now }
+	const addr = "foo:26257"
+
+	// The breaker trips after Threshold consecutive failures only.
+	for i := 0; i < b.Threshold-1; i++ {
+		b.Failure(addr)
+	}
+	b.Success(addr)
+	for i := 0; i < b.Threshold-1; i++ {
+		b.Failure(addr)
+	}
+	if !b.Ready(addr) {
+		t.Fatal("expected the breaker to be closed before the threshold")
+	}
+	b.Failure(addr)
+	if b.Ready(addr) {
+		t.Fatal("expected the breaker to be open after the threshold")
+	}
+	if !b.Ready("bar:26257") {
+		t.Error("expected the breakers of other addresses to be closed")
+	}
+	if n := b.numOpen(); n != 1 {
+		t.Errorf("expected 1 open breaker, got %d", n)
+	}
+
+	// Once the cooldown elapsed, RPCs are let through again, but a single
+	// failure trips the breaker anew.
+	now = now.Add(b.Cooldown)
+	if !b.Ready(addr) {
+		t.Fatal("expected the breaker to let RPCs through after the cooldown")
+	}
+	b.Failure(addr)
+	if b.Ready(addr) {
+		t.Fatal("expected the breaker to trip again on a failure after the cooldown")
+	}
+
+	// A success resets the breaker.
+	now = now.Add(b.Cooldown)
+	b.Success(addr)
+	b.Failure(addr)
+	if !b.Ready(addr) {
+		t.Fatal("expected the breaker to be reset by a success")
+	}
+
+	if a, e := b.tripped.Count(), int64(2); a != e {
+		t.Errorf("expected %d trips, got %d", e, a)
+	}
+	if a, e := b.rejected.Count(), int64(2); a != e {
+		t.Errorf("expected %d rejections, got %d", e, a)
+	}
+}
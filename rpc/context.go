@@ -1,7 +1,9 @@
 package rpc
 
 import (
+	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/net/context"
@@ -55,15 +57,40 @@ type Context struct {
 	// Embed the base context.
 	base.Context
 
-	localClock   *hlc.Clock
-	Stopper      *stop.Stopper
-	RemoteClocks *RemoteClockMonitor
+	localClock      *hlc.Clock
+	Stopper         *stop.Stopper
+	RemoteClocks    *RemoteClockMonitor
+	RemoteLatencies *RemoteLatencyMonitor
+	// RPCLatencies keeps track of the latencies of the RPCs recently served
+	// by remote addresses, which unlike heartbeats include the processing of
+	// the requests.
+	RPCLatencies *RemoteLatencyMonitor
+	// Breakers skips remote addresses to which RPCs keep failing.
+	Breakers *Breakers
 
 	HeartbeatInterval time.Duration
 	HeartbeatTimeout  time.Duration
 
+	// LocalInternalServer, if set, receives the requests addressed to
+	// LocalAddr directly instead of through gRPC. Neither the request nor
+	// the reply is copied or marshaled, so both sides share their memory:
+	// the server must not modify the request nor retain references to it or
+	// to the reply once it returns, and the caller must not modify the
+	// request during the call.
 	LocalInternalServer roachpb.InternalServer
 	LocalAddr           string
+	// VerifyLocalCalls, if set, checks that LocalInternalServer does not
+	// modify the requests it serves, failing those it did. The check
+	// marshals every request twice, which local calls otherwise avoid, so
+	// it is meant for tests.
+	VerifyLocalCalls bool
+	// localNodeID, if nonzero, is the ID of the node served by
+	// LocalInternalServer. Accessed atomically.
+	localNodeID int32
+
+	// Dialer, if set, is used in place of the default dialer to establish
+	// outgoing connections. Tests use it to inject network faults.
+	Dialer func(addr string, timeout time.Duration) (net.Conn, error)
 
 	conns struct {
 		sync.Mutex
@@ -92,6 +119,9 @@ func NewContext(baseCtx *base.Context, clock *hlc.Clock, stopper *stop.Stopper)
 	}
 	ctx.Stopper = stopper
 	ctx.RemoteClocks = newRemoteClockMonitor(clock)
+	ctx.RemoteLatencies = newRemoteLatencyMonitor()
+	ctx.RPCLatencies = newRemoteLatencyMonitor()
+	ctx.Breakers = newBreakers()
 	ctx.HeartbeatInterval = defaultHeartbeatInterval
 	ctx.HeartbeatTimeout = 2 * defaultHeartbeatInterval
 
@@ -109,11 +139,42 @@ func NewContext(baseCtx *base.Context, clock *hlc.Clock, stopper *stop.Stopper)
 }
 
 // SetLocalInternalServer sets the context's local internal batch server.
+// See LocalInternalServer for the rules it must follow.
 func (ctx *Context) SetLocalInternalServer(internalServer roachpb.InternalServer, addr string) {
 	ctx.LocalInternalServer = internalServer
 	ctx.LocalAddr = addr
 }
 
+// SetLocalNodeID sets the ID of the node served by the local internal
+// server, once it is known. The requests to the replicas on that node are
+// then served locally even if they are addressed to an address other than
+// LocalAddr, e.g. the address advertised by a node behind NAT.
+func (ctx *Context) SetLocalNodeID(nodeID roachpb.NodeID) {
+	atomic.StoreInt32(&ctx.localNodeID, int32(nodeID))
+}
+
+// IsLocal returns whether the node with the given ID and address is the
+// one served by the local internal server.
+func (ctx *Context) IsLocal(nodeID roachpb.NodeID, addr string) bool {
+	if ctx.LocalInternalServer == nil {
+		return false
+	}
+	if localNodeID := atomic.LoadInt32(&ctx.localNodeID); localNodeID != 0 && roachpb.NodeID(localNodeID) == nodeID {
+		return true
+	}
+	return addr == ctx.LocalAddr
+}
+
+// RemoteLatency returns the median of the recently measured heartbeat
+// round-trip latencies to the remote address. The boolean is false if too
+// few heartbeats were measured to the address.
+func (ctx *Context) RemoteLatency(addr string) (time.Duration, bool) {
+	if ctx.RemoteLatencies == nil {
+		return 0, false
+	}
+	return ctx.RemoteLatencies.LatencyQuantile(addr, 0.5)
+}
+
 func (ctx *Context) removeConn(key string, conn *grpc.ClientConn) {
 	if err := conn.Close(); err != nil && !grpcutil.IsClosedConnection(err) {
 		if log.V(1) {
@@ -123,6 +184,15 @@ func (ctx *Context) removeConn(key string, conn *grpc.ClientConn) {
 	delete(ctx.conns.cache, key)
 }
 
+// GRPCDialNode is like GRPCDial, but also records the ID of the node at the
+// target, under which the round-trip latencies of the heartbeats to the
+// target are exported by RemoteLatencies.
+func (ctx *Context) GRPCDialNode(target string, nodeID roachpb.NodeID,
+	opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	ctx.RemoteLatencies.SetNodeID(target, nodeID)
+	return ctx.GRPCDial(target, opts...)
+}
+
 // GRPCDial calls grpc.Dial with the options appropriate for the context.
 func (ctx *Context) GRPCDial(target string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
 	ctx.conns.Lock()
@@ -143,7 +213,11 @@ func (ctx *Context) GRPCDial(target string, opts ...grpc.DialOption) (*grpc.Clie
 		dialOpt = grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
 	}
 
-	conn, err := grpc.Dial(target, append(opts, dialOpt, grpc.WithTimeout(base.NetworkTimeout))...)
+	opts = append(opts, dialOpt, grpc.WithTimeout(base.NetworkTimeout))
+	if ctx.Dialer != nil {
+		opts = append(opts, grpc.WithDialer(ctx.Dialer))
+	}
+	conn, err := grpc.Dial(target, opts...)
 	if err == nil {
 		if ctx.conns.cache == nil {
 			ctx.conns.cache = make(map[string]*grpc.ClientConn)
@@ -177,6 +251,7 @@ func (ctx *Context) runHeartbeat(cc *grpc.ClientConn, remoteAddr string) error {
 			return err
 		}
 		receiveTime := ctx.localClock.PhysicalNow()
+		ctx.RemoteLatencies.RecordLatency(remoteAddr, time.Duration(receiveTime-sendTime))
 
 		// Only update the clock offset measurement if we actually got a
 		// successful response from the server.
@@ -0,0 +1,139 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package rpc
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/util/metric"
+)
+
+const (
+	// latencyWindowSize is the number of most recent round trips kept for
+	// every remote address.
+	latencyWindowSize = 100
+	// minLatencySamples is the number of round trips which must be measured
+	// to a remote address before its latency quantiles are reported.
+	minLatencySamples = 5
+	// maxExportedLatency is the highest latency recorded in the exported
+	// histograms; higher latencies are truncated to it.
+	maxExportedLatency = 10 * time.Second
+)
+
+// RemoteLatencyMonitor keeps track of the round-trip latencies of the most
+// recent heartbeats or RPCs from this node to connected nodes.
+type RemoteLatencyMonitor struct {
+	mu      sync.Mutex
+	samples map[string]*latencySamples // Maps remote string addr to samples.
+	// nodeIDs maps the remote addresses to the IDs of the nodes listening
+	// on them, where known. See SetNodeID.
+	nodeIDs map[string]roachpb.NodeID
+	// histograms are the exported latencies of every remote node, which are
+	// registered in registry.
+	histograms map[roachpb.NodeID]*metric.Histogram
+	registry   *metric.Registry
+}
+
+// latencySamples is a ring buffer of round-trip latencies.
+type latencySamples struct {
+	latencies []time.Duration
+	next      int
+}
+
+func newRemoteLatencyMonitor() *RemoteLatencyMonitor {
+	return &RemoteLatencyMonitor{
+		samples:    map[string]*latencySamples{},
+		nodeIDs:    map[string]roachpb.NodeID{},
+		histograms: map[roachpb.NodeID]*metric.Histogram{},
+		registry:   metric.NewRegistry(),
+	}
+}
+
+// Registry returns the registry of the exported latencies: a "latency"
+// histogram for every remote node whose address is known, labeled with the
+// ID of the node.
+func (r *RemoteLatencyMonitor) Registry() *metric.Registry {
+	return r.registry
+}
+
+// SetNodeID records the ID of the node listening on the remote address, so
+// that the latencies to the address are exported for the node from then on.
+func (r *RemoteLatencyMonitor) SetNodeID(addr string, nodeID roachpb.NodeID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nodeIDs[addr] = nodeID
+}
+
+// RecordLatency records the round-trip latency of a heartbeat or RPC to the
+// remote address.
+func (r *RemoteLatencyMonitor) RecordLatency(addr string, latency time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if nodeID, ok := r.nodeIDs[addr]; ok {
+		h, ok := r.histograms[nodeID]
+		if !ok {
+			h = metric.NewHistogram(time.Minute, int64(maxExportedLatency), 2)
+			r.registry.MustAddWithLabels("latency", h,
+				map[string]string{"remote_node": strconv.Itoa(int(nodeID))})
+			r.histograms[nodeID] = h
+		}
+		h.RecordValue(latency.Nanoseconds())
+	}
+	s, ok := r.samples[addr]
+	if !ok {
+		s = &latencySamples{}
+		r.samples[addr] = s
+	}
+	if len(s.latencies) < latencyWindowSize {
+		s.latencies = append(s.latencies, latency)
+		return
+	}
+	s.latencies[s.next] = latency
+	s.next = (s.next + 1) % latencyWindowSize
+}
+
+// LatencyQuantile returns the given quantile, between 0 and 1, of the
+// recently measured round-trip latencies to the remote address. The boolean
+// is false if too few latencies were measured to the address.
+func (r *RemoteLatencyMonitor) LatencyQuantile(addr string, q float64) (time.Duration, bool) {
+	r.mu.Lock()
+	s, ok := r.samples[addr]
+	if !ok || len(s.latencies) < minLatencySamples {
+		r.mu.Unlock()
+		return 0, false
+	}
+	latencies := append([]time.Duration(nil), s.latencies...)
+	r.mu.Unlock()
+
+	sort.Sort(durations(latencies))
+	i := int(q * float64(len(latencies)))
+	if i >= len(latencies) {
+		i = len(latencies) - 1
+	} else if i < 0 {
+		i = 0
+	}
+	return latencies[i], true
+}
+
+// durations sorts time.Durations in increasing order.
+type durations []time.Duration
+
+func (d durations) Len() int           { return len(d) }
+func (d durations) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+func (d durations) Less(i, j int) bool { return d[i] < d[j] }
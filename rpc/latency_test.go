@@ -0,0 +1,95 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package rpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/util/leaktest"
+	"github.com/cockroachdb/cockroach/util/metric"
+)
+
+func TestRemoteLatencyMonitor(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	r := newRemoteLatencyMonitor()
+	const addr = "foo:26257"
+
+	for i := 1; i < minLatencySamples; i++ {
+		r.RecordLatency(addr, time.Duration(i)*time.Millisecond)
+	}
+	if _, ok := r.LatencyQuantile(addr, 0.99); ok {
+		t.Errorf("expected no latency with fewer than %d samples", minLatencySamples)
+	}
+
+	// Fill the window with latencies of 1ms to 100ms.
+	for i := minLatencySamples; i <= latencyWindowSize; i++ {
+		r.RecordLatency(addr, time.Duration(i)*time.Millisecond)
+	}
+	testCases := []struct {
+		q        float64
+		expected time.Duration
+	}{
+		{0, 1 * time.Millisecond},
+		{0.5, 51 * time.Millisecond},
+		{0.99, 100 * time.Millisecond},
+		{1, 100 * time.Millisecond},
+	}
+	for i, c := range testCases {
+		if l, ok := r.LatencyQuantile(addr, c.q); !ok || l != c.expected {
+			t.Errorf("%d: expected quantile %g to be %s, got %s (%t)", i, c.q, c.expected, l, ok)
+		}
+	}
+
+	// Older latencies are replaced by newer ones.
+	for i := 0; i < latencyWindowSize; i++ {
+		r.RecordLatency(addr, time.Second)
+	}
+	if l, _ := r.LatencyQuantile(addr, 0); l != time.Second {
+		t.Errorf("expected the oldest latencies to be replaced, got minimum %s", l)
+	}
+
+	if _, ok := r.LatencyQuantile("bar:26257", 0.99); ok {
+		t.Error("expected no latency for an unknown address")
+	}
+}
+
+// TestRemoteLatencyMonitorExport verifies that the latencies to the
+// addresses of known nodes are exported per node.
+func TestRemoteLatencyMonitorExport(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	r := newRemoteLatencyMonitor()
+
+	r.RecordLatency("foo:26257", time.Millisecond)
+	r.SetNodeID("foo:26257", 2)
+	r.RecordLatency("foo:26257", time.Millisecond)
+	r.RecordLatency("bar:26257", time.Millisecond)
+
+	var exported int
+	r.Registry().EachWithLabels(func(name string, labels map[string]string, v interface{}) {
+		h, ok := v.(*metric.Histogram)
+		if !ok || name != "latency" || labels["remote_node"] != "2" {
+			t.Errorf("unexpected metric %s%v", name, labels)
+			return
+		}
+		exported++
+		if n := h.Current().TotalCount(); n != 1 {
+			t.Errorf("expected the latency recorded once the node was known, got %d", n)
+		}
+	})
+	if exported != 1 {
+		t.Errorf("expected the latencies of one node to be exported, got %d", exported)
+	}
+}
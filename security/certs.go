@@ -18,10 +18,13 @@ package security
 
 import (
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
 	"os"
+	"time"
 
 	"github.com/cockroachdb/cockroach/util"
 )
@@ -162,3 +165,72 @@ func RunCreateClientCert(sslCA, sslCAKey, sslCert, sslCertKey string, keySize in
 
 	return writeCertificateAndKey(sslCert, sslCertKey, certificate, key)
 }
+
+// CertificateInfo describes a certificate loaded by a node.
+type CertificateInfo struct {
+	// Usage is the purpose for which the certificate is loaded, e.g. "ca"
+	// or "node".
+	Usage string `json:"usage"`
+	Path  string `json:"path"`
+	// Subject and Issuer are the common names of the subject and the issuer.
+	Subject      string    `json:"subject"`
+	Issuer       string    `json:"issuer"`
+	SerialNumber string    `json:"serial_number"`
+	NotBefore    time.Time `json:"not_before"`
+	NotAfter     time.Time `json:"not_after"`
+	// KeyAlgorithm is the algorithm of the public key, "RSA" or "ECDSA", and
+	// KeyBits its size in bits, i.e. the size of the RSA modulus or of the
+	// ECDSA curve.
+	KeyAlgorithm string   `json:"key_algorithm"`
+	KeyBits      int      `json:"key_bits"`
+	DNSNames     []string `json:"dns_names,omitempty"`
+	IPAddresses  []string `json:"ip_addresses,omitempty"`
+}
+
+// LoadCertificateInfo reads the PEM-encoded certificates in the file at
+// path and describes each of them, with the given usage.
+func LoadCertificateInfo(usage, path string) ([]CertificateInfo, error) {
+	certPEM, err := readFileFn(path)
+	if err != nil {
+		return nil, err
+	}
+	var infos []CertificateInfo
+	for {
+		var block *pem.Block
+		block, certPEM = pem.Decode(certPEM)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, util.Errorf("error parsing certificate %s: %s", path, err)
+		}
+		info := CertificateInfo{
+			Usage:        usage,
+			Path:         path,
+			Subject:      cert.Subject.CommonName,
+			Issuer:       cert.Issuer.CommonName,
+			SerialNumber: cert.SerialNumber.String(),
+			NotBefore:    cert.NotBefore,
+			NotAfter:     cert.NotAfter,
+			DNSNames:     cert.DNSNames,
+		}
+		switch pub := cert.PublicKey.(type) {
+		case *rsa.PublicKey:
+			info.KeyAlgorithm, info.KeyBits = "RSA", pub.N.BitLen()
+		case *ecdsa.PublicKey:
+			info.KeyAlgorithm, info.KeyBits = "ECDSA", pub.Curve.Params().BitSize
+		}
+		for _, ip := range cert.IPAddresses {
+			info.IPAddresses = append(info.IPAddresses, ip.String())
+		}
+		infos = append(infos, info)
+	}
+	if len(infos) == 0 {
+		return nil, util.Errorf("no certificates found in %s", path)
+	}
+	return infos, nil
+}
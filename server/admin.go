@@ -26,8 +26,10 @@ import (
 	_ "expvar"
 	"fmt"
 	"net/http"
+	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/net/context"
@@ -44,10 +46,12 @@ import (
 	"google.golang.org/grpc/credentials"
 
 	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/config"
 	"github.com/cockroachdb/cockroach/roachpb"
 	"github.com/cockroachdb/cockroach/security"
 	"github.com/cockroachdb/cockroach/sql"
 	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/ts"
 	"github.com/cockroachdb/cockroach/util"
 	"github.com/cockroachdb/cockroach/util/log"
 	"github.com/cockroachdb/cockroach/util/stop"
@@ -56,12 +60,22 @@ import (
 func init() {
 	// Tweak the authentication logic for the tracing endpoint.
 	// By default it's open for localhost only, but with Docker
-	// we want to get there from anywhere.
-	// TODO(mberhault): properly secure this once we require client certs.
+	// we want to get there from anywhere. Access to the endpoint
+	// is instead restricted by adminServer.handleDebug.
 	trace.AuthRequest = func(_ *http.Request) (bool, bool) {
 		// Open-door policy except traces marked "sensitive".
 		return true, false
 	}
+
+	// Allow clients of the grpc-gateway endpoints to request protobuf
+	// encoded responses. See forwardResponseMessage.
+	forward_Admin_Users_0 = forwardResponseMessage
+	forward_Admin_Databases_0 = forwardResponseMessage
+	forward_Admin_DatabaseDetails_0 = forwardResponseMessage
+	forward_Admin_TableDetails_0 = forwardResponseMessage
+	forward_Admin_Events_0 = forwardResponseMessage
+	forward_Admin_SetUIData_0 = forwardResponseMessage
+	forward_Admin_GetUIData_0 = forwardResponseMessage
 }
 
 const (
@@ -78,10 +92,22 @@ const (
 	healthPath = apiEndpoint + "health"
 	// quitPath is the quit endpoint.
 	quitPath = apiEndpoint + "quit"
+	// profilesPath is the endpoint used to capture, list and download
+	// runtime profile snapshots.
+	profilesPath = apiEndpoint + "profiles/"
+	// zoneConfigPath is the endpoint returning the zone config which applies
+	// to a table.
+	zoneConfigPath = apiEndpoint + "zone-config"
 
 	// eventLimit is the maximum number of events returned by any endpoints
 	// returning events.
 	apiEventLimit = 1000
+
+	// defaultMetricsInterval is the interval at which Metrics sends
+	// snapshots if the request doesn't specify one; minMetricsInterval is
+	// the shortest interval a request may specify.
+	defaultMetricsInterval = 10 * time.Second
+	minMetricsInterval     = 100 * time.Millisecond
 )
 
 var (
@@ -106,6 +132,19 @@ type adminServer struct {
 	db          *client.DB    // Key-value database client
 	stopper     *stop.Stopper // Used to shutdown the server
 	sqlExecutor *sql.Executor
+	node        *Node
+	insecure    bool // Allow unauthenticated access to the debug endpoints
+	profiles    *profileStore
+	// metricSource provides the snapshots streamed by Metrics.
+	metricSource ts.DataSource
+	// idempotency deduplicates retries of mutating requests.
+	idempotency *idempotencyCache
+	// uiGeneration caches whether the system.ui table has the generation
+	// column, which the tables of older clusters lack.
+	uiGeneration struct {
+		sync.Mutex
+		known, present bool
+	}
 	*http.ServeMux
 
 	// Mux provided by grpc-gateway to handle HTTP/gRPC proxying.
@@ -120,12 +159,19 @@ type adminServer struct {
 
 // newAdminServer allocates and returns a new REST server for
 // administrative APIs.
-func newAdminServer(db *client.DB, stopper *stop.Stopper, sqlExecutor *sql.Executor) *adminServer {
+func newAdminServer(db *client.DB, stopper *stop.Stopper, sqlExecutor *sql.Executor,
+	node *Node, insecure bool, profiles *profileStore, metricSource ts.DataSource) *adminServer {
 	server := &adminServer{
 		db:          db,
 		stopper:     stopper,
 		sqlExecutor: sqlExecutor,
+		node:        node,
+		insecure:    insecure,
+		profiles:    profiles,
 		ServeMux:    http.NewServeMux(),
+
+		metricSource: metricSource,
+		idempotency:  newIdempotencyCache(db, defaultIdempotencyKeyTTL),
 	}
 
 	// Register HTTP handlers.
@@ -133,6 +179,8 @@ func newAdminServer(db *client.DB, stopper *stop.Stopper, sqlExecutor *sql.Execu
 	// TODO(cdo): Move quit and health endpoints to gRPC.
 	server.ServeMux.HandleFunc(quitPath, server.handleQuit)
 	server.ServeMux.HandleFunc(healthPath, server.handleHealth)
+	server.ServeMux.HandleFunc(profilesPath, server.handleProfiles)
+	server.ServeMux.HandleFunc(zoneConfigPath, server.handleZoneConfig)
 
 	// Initialize grpc-gateway mux and context.
 	server.gwMux = gwruntime.NewServeMux()
@@ -141,6 +189,13 @@ func newAdminServer(db *client.DB, stopper *stop.Stopper, sqlExecutor *sql.Execu
 	return server
 }
 
+// ServeHTTP implements http.Handler. Mutating requests carrying an
+// Idempotency-Key header are deduplicated, so that clients can safely retry
+// them; see idempotencyCache.
+func (s *adminServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.idempotency.serve(w, r, s.ServeMux)
+}
+
 // RegisterGRPCGateway starts the gateway (i.e. reverse proxy) that proxies
 // HTTP requests to the appropriate gRPC endpoints.
 func (s *adminServer) RegisterGRPCGateway(serverCtx *Context) error {
@@ -165,6 +220,42 @@ func (s *adminServer) RegisterGRPCGateway(serverCtx *Context) error {
 	return nil
 }
 
+// forwardResponseMessage writes the response of a grpc-gateway endpoint. If
+// the request accepts protobuf, the response is written as a protobuf,
+// avoiding the overhead of JSON marshaling for large responses. Otherwise,
+// it falls back to gwruntime.ForwardResponseMessage, which writes JSON.
+func forwardResponseMessage(ctx context.Context, w http.ResponseWriter, req *http.Request,
+	resp proto.Message, opts ...func(context.Context, http.ResponseWriter, proto.Message) error) {
+	accept := req.Header.Get(util.AcceptHeader)
+	if !strings.Contains(accept, util.ProtoContentType) && !strings.Contains(accept, util.AltProtoContentType) {
+		gwruntime.ForwardResponseMessage(ctx, w, req, resp, opts...)
+		return
+	}
+
+	if md, ok := gwruntime.ServerMetadataFromContext(ctx); ok {
+		for k, vs := range md.HeaderMD {
+			for _, v := range vs {
+				w.Header().Add(fmt.Sprintf("Grpc-Metadata-%s", k), v)
+			}
+		}
+	}
+	for _, opt := range opts {
+		if err := opt(ctx, w, resp); err != nil {
+			gwruntime.HTTPError(ctx, w, req, err)
+			return
+		}
+	}
+	b, err := proto.Marshal(resp)
+	if err != nil {
+		gwruntime.HTTPError(ctx, w, req, err)
+		return
+	}
+	w.Header().Set(util.ContentTypeHeader, util.ProtoContentType)
+	if _, err := w.Write(b); err != nil {
+		log.Errorf("failed to write response: %s", err)
+	}
+}
+
 // Close cleans up resources used by the adminServer.
 func (s *adminServer) Close() {
 	s.gwCancel()
@@ -187,14 +278,143 @@ func (s *adminServer) handleQuit(w http.ResponseWriter, r *http.Request) {
 	}()
 }
 
+// handleZoneConfig returns the zone config which applies to the table given
+// by the database and table query parameters, following the inheritance from
+// the table's database and the default zone config. The ID of the object the
+// zone config is set on is returned along with it.
+func (s *adminServer) handleZoneConfig(w http.ResponseWriter, r *http.Request) {
+	database, table := r.URL.Query().Get("database"), r.URL.Query().Get("table")
+	if database == "" || table == "" {
+		http.Error(w, "database and table must be specified", http.StatusBadRequest)
+		return
+	}
+	cfg := s.node.ctx.Gossip.GetSystemConfig()
+	if cfg == nil {
+		http.Error(w, "system config not yet available", http.StatusServiceUnavailable)
+		return
+	}
+	zone, zoneID, err := sql.ResolveTableZoneConfig(*cfg, database, table)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	respondAsJSON(w, r, struct {
+		ZoneID     uint32             `json:"zone_id"`
+		ZoneConfig *config.ZoneConfig `json:"zone_config"`
+	}{zoneID, zone})
+}
+
+// authorizeDebugRequest verifies that the request may access the debug
+// endpoints, which expose profiles, traces and internal state of the
+// process. Unless running in insecure mode, this requires a client
+// certificate for the root or node user. The returned int is the HTTP
+// status code to respond with if access is denied.
+func authorizeDebugRequest(insecure bool, r *http.Request) (int, error) {
+	if insecure {
+		return http.StatusOK, nil
+	}
+	user, err := security.GetCertificateUser(r.TLS)
+	if err != nil {
+		return http.StatusUnauthorized, err
+	}
+	if user != security.RootUser && user != security.NodeUser {
+		return http.StatusForbidden, util.Errorf("user %s is not allowed", user)
+	}
+	return http.StatusOK, nil
+}
+
 // handleDebug passes requests with the debugPathPrefix onto the default
 // serve mux, which is preconfigured (by import of expvar and net/http/pprof)
 // to serve endpoints which access exported variables and pprof tools.
 func (s *adminServer) handleDebug(w http.ResponseWriter, r *http.Request) {
+	if code, err := authorizeDebugRequest(s.insecure, r); err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
 	handler, _ := http.DefaultServeMux.Handler(r)
 	handler.ServeHTTP(w, r)
 }
 
+// handleProfiles captures, lists and downloads runtime profile snapshots.
+// A GET of profilesPath lists the retained snapshots and a GET of
+// profilesPath + <name> downloads one. A POST of profilesPath + <type>
+// captures a heap, block or goroutine profile; for block profiles, the
+// seconds parameter sets the duration during which blocking events are
+// recorded, up to maxBlockProfileDuration. Like the debug endpoints, profiles require root or node
+// certificates.
+func (s *adminServer) handleProfiles(w http.ResponseWriter, r *http.Request) {
+	if code, err := authorizeDebugRequest(s.insecure, r); err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, profilesPath)
+
+	switch r.Method {
+	case "GET":
+		if name == "" {
+			snaps, err := s.profiles.list()
+			if err != nil {
+				log.Error(err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			respondAsJSON(w, r, struct {
+				Profiles []ProfileSnapshot `json:"profiles"`
+			}{snaps})
+			return
+		}
+		path, err := s.profiles.path(name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				http.NotFound(w, r)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set(util.ContentTypeHeader, "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+		http.ServeContent(w, r, name, info.ModTime(), f)
+
+	case "POST":
+		var duration time.Duration
+		if secs := r.URL.Query().Get("seconds"); secs != "" {
+			n, err := strconv.Atoi(secs)
+			if err != nil || n <= 0 || n > int(maxBlockProfileDuration/time.Second) {
+				http.Error(w, fmt.Sprintf("invalid seconds %q; must be between 1 and %d",
+					secs, maxBlockProfileDuration/time.Second), http.StatusBadRequest)
+				return
+			}
+			duration = time.Duration(n) * time.Second
+		}
+		if _, ok := profileTypes[name]; !ok {
+			http.Error(w, fmt.Sprintf("unknown profile type %q", name), http.StatusBadRequest)
+			return
+		}
+		snap, err := s.profiles.capture(name, duration)
+		if err != nil {
+			log.Error(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		respondAsJSON(w, r, snap)
+
+	default:
+		http.Error(w, "only GET and POST are supported", http.StatusMethodNotAllowed)
+	}
+}
+
 // getUserProto will return the authenticated user. For now, this is just a stub until we
 // figure out our authentication mechanism.
 //
@@ -540,116 +760,293 @@ func (s *adminServer) Events(c context.Context, req *EventsRequest) (*EventsResp
 	return &resp, nil
 }
 
-// getUIData returns the value and timestamp for the given UI key. Returns
-// errUIKeyNotFound if the key was not found.
-func (s *adminServer) getUIData(session *sql.Session, user, key string) ([]byte, GetUIDataResponse_Timestamp, error) {
-	zeroTimestamp := GetUIDataResponse_Timestamp{}
+// uiData is the value of a UI key along with its version.
+type uiData struct {
+	value       []byte
+	lastUpdated GetUIDataResponse_Timestamp
+	// generation is the number of times the key was set; it is zero for
+	// keys set before the generation was tracked.
+	generation int64
+}
 
+// uiHasGeneration returns whether the system.ui table has the generation
+// column. Clusters bootstrapped before the column was added lack it; their
+// keys are all at generation zero and can't be set conditionally.
+func (s *adminServer) uiHasGeneration(user string) (bool, error) {
+	s.uiGeneration.Lock()
+	defer s.uiGeneration.Unlock()
+	if s.uiGeneration.known {
+		return s.uiGeneration.present, nil
+	}
+	var session sql.Session
+	r := s.sqlExecutor.ExecuteStatements(user, &session, "SHOW COLUMNS FROM system.ui", nil)
+	if err := s.checkQueryResults(r.ResultList, 1); err != nil {
+		return false, err
+	}
+	scanner := newResultScanner(r.ResultList[0].Columns)
+	present := false
+	for _, row := range r.ResultList[0].Rows {
+		var name string
+		if err := scanner.Scan(row, "Field", &name); err != nil {
+			return false, err
+		}
+		if name == "generation" {
+			present = true
+		}
+	}
+	s.uiGeneration.known, s.uiGeneration.present = true, present
+	return present, nil
+}
+
+// getUIData returns the value, timestamp and generation for the given UI
+// key. Returns errUIKeyNotFound if the key was not found.
+func (s *adminServer) getUIData(session *sql.Session, user, key string) (uiData, error) {
+	hasGeneration, err := s.uiHasGeneration(user)
+	if err != nil {
+		return uiData{}, s.serverError(err)
+	}
 	// Query database.
 	query := "SELECT value, lastUpdated FROM system.ui WHERE key = $1"
+	if hasGeneration {
+		query = "SELECT value, lastUpdated, generation FROM system.ui WHERE key = $1"
+	}
 	params := []parser.Datum{parser.DString(key)}
 	r := s.sqlExecutor.ExecuteStatements(user, session, query, params)
 	if err := s.checkQueryResults(r.ResultList, 1); err != nil {
-		return nil, zeroTimestamp, s.serverError(err)
+		return uiData{}, s.serverError(err)
 	}
 	if len(r.ResultList[0].Rows) == 0 {
-		return nil, zeroTimestamp, errUIKeyNotFound
+		return uiData{}, errUIKeyNotFound
 	}
 
 	// Marshal results.
 	row := r.ResultList[0].Rows[0]
 	dBytes, ok := row.Values[0].(parser.DBytes)
 	if !ok {
-		return nil, zeroTimestamp, s.serverErrorf("unexpected type for UI value: %T", row.Values[0])
+		return uiData{}, s.serverErrorf("unexpected type for UI value: %T", row.Values[0])
 	}
 	dTS, ok := row.Values[1].(parser.DTimestamp)
 	if !ok {
-		return nil, zeroTimestamp,
-			s.serverErrorf("unexpected type for UI lastUpdated: %T", row.Values[1])
+		return uiData{}, s.serverErrorf("unexpected type for UI lastUpdated: %T", row.Values[1])
+	}
+	var generation int64
+	if hasGeneration && row.Values[2] != parser.DNull {
+		dGen, ok := row.Values[2].(parser.DInt)
+		if !ok {
+			return uiData{}, s.serverErrorf("unexpected type for UI generation: %T", row.Values[2])
+		}
+		generation = int64(dGen)
 	}
 	nanos := dTS.UnixNano()
 	ts := GetUIDataResponse_Timestamp{nanos / 1e9, uint32(nanos % 1e9)}
-	return []byte(dBytes), ts, nil
+	return uiData{value: []byte(dBytes), lastUpdated: ts, generation: generation}, nil
 }
 
-// SetUIData is an endpoint that sets the data associated with a key.
+// setUIData sets the value of the UI key in the transaction of the session,
+// returning the new generation of the key. If conditional, the key is only
+// set if its generation is expectedGeneration.
+func (s *adminServer) setUIData(session *sql.Session, user string, kv *SetUIDataRequest_KeyValue) (int64, error) {
+	// See if the key already exists.
+	alreadyExists := true
+	cur, err := s.getUIData(session, user, kv.Key)
+	if err != nil {
+		if err != errUIKeyNotFound {
+			return 0, err
+		}
+		alreadyExists = false
+	}
+	hasGeneration, err := s.uiHasGeneration(user)
+	if err != nil {
+		return 0, s.serverError(err)
+	}
+	if kv.Conditional && !hasGeneration {
+		return 0, grpc.Errorf(codes.FailedPrecondition,
+			"key %s can't be set conditionally: the system.ui table predates generations", kv.Key)
+	}
+	if kv.Conditional && cur.generation != kv.ExpectedGeneration {
+		return 0, grpc.Errorf(codes.Aborted, "key %s is at generation %d, expected %d",
+			kv.Key, cur.generation, kv.ExpectedGeneration)
+	}
+
+	// INSERT or UPDATE as appropriate.
+	ts := session.Txn.TxnTimestamp
+	var generation int64
+	var query string
+	switch {
+	case !hasGeneration && alreadyExists:
+		query = "UPDATE system.ui SET value = $2, lastUpdated = $3 WHERE key = $1"
+	case !hasGeneration:
+		query = "INSERT INTO system.ui (key, value, lastUpdated) VALUES ($1, $2, $3)"
+	case alreadyExists:
+		query = "UPDATE system.ui SET value = $2, lastUpdated = $3, generation = $4 WHERE key = $1"
+	default:
+		query = "INSERT INTO system.ui (key, value, lastUpdated, generation) VALUES ($1, $2, $3, $4)"
+	}
+	params := []parser.Datum{
+		parser.DString(kv.Key),               // $1
+		parser.DBytes(kv.Value),              // $2
+		parser.DTimestamp{Time: ts.GoTime()}, // $3
+	}
+	if hasGeneration {
+		generation = cur.generation + 1
+		params = append(params, parser.DInt(generation)) // $4
+	}
+	r := s.sqlExecutor.ExecuteStatements(user, session, query, params)
+	if err := s.checkQueryResults(r.ResultList, 1); err != nil {
+		return 0, s.serverError(err)
+	}
+	if a, e := r.ResultList[0].RowsAffected, 1; a != e {
+		return 0, s.serverErrorf("rows affected %d != expected %d", a, e)
+	}
+	return generation, nil
+}
+
+// SetUIData is an endpoint that sets the data associated with one or more
+// keys. The keys are set atomically.
 func (s *adminServer) SetUIData(_ context.Context, req *SetUIDataRequest) (*SetUIDataResponse, error) {
-	if len(req.Key) == 0 {
+	kvs := req.KeyValues
+	if len(req.Key) > 0 {
+		kvs = append([]*SetUIDataRequest_KeyValue{{Key: req.Key, Value: req.Value}}, kvs...)
+	}
+	if len(kvs) == 0 {
 		return nil, grpc.Errorf(codes.InvalidArgument, "key cannot be empty")
 	}
+	seen := make(map[string]struct{}, len(kvs))
+	for _, kv := range kvs {
+		if len(kv.Key) == 0 {
+			return nil, grpc.Errorf(codes.InvalidArgument, "key cannot be empty")
+		}
+		if _, ok := seen[kv.Key]; ok {
+			return nil, grpc.Errorf(codes.InvalidArgument, "key %s is set more than once", kv.Key)
+		}
+		seen[kv.Key] = struct{}{}
+	}
 
 	var session sql.Session
 	user := s.getUser(req)
 
-	// Do an upsert of the key.
 	br := s.sqlExecutor.ExecuteStatements(user, &session, "BEGIN;", nil)
 	if err := s.checkQueryResults(br.ResultList, 1); err != nil {
 		return nil, s.serverError(err)
 	}
 
-	// See if the key already exists.
-	alreadyExists := true
-	if _, _, err := s.getUIData(&session, user, req.Key); err != nil {
-		if err != errUIKeyNotFound {
-			return nil, s.serverError(err)
+	resp := &SetUIDataResponse{}
+	for _, kv := range kvs {
+		generation, err := s.setUIData(&session, user, kv)
+		if err != nil {
+			s.sqlExecutor.ExecuteStatements(user, &session, "ROLLBACK;", nil)
+			return nil, err
 		}
-		alreadyExists = false
+		resp.Generations = append(resp.Generations,
+			&SetUIDataResponse_Generation{Key: kv.Key, Generation: generation})
 	}
 
-	// INSERT or UPDATE as appropriate.
-	ts := session.Txn.TxnTimestamp
-	if alreadyExists {
-		query := "UPDATE system.ui SET value = $1, lastUpdated = $2 WHERE key = $3; COMMIT;"
-		params := []parser.Datum{
-			parser.DString(req.Value),            // $1
-			parser.DTimestamp{Time: ts.GoTime()}, // $2
-			parser.DString(req.Key),              // $3
-		}
-		r := s.sqlExecutor.ExecuteStatements(user, &session, query, params)
-		if err := s.checkQueryResults(r.ResultList, 2); err != nil {
-			return nil, s.serverError(err)
-		}
-		if a, e := r.ResultList[0].RowsAffected, 1; a != e {
-			return nil, s.serverErrorf("rows affected %d != expected %d", a, e)
-		}
-	} else {
-		query := "INSERT INTO system.ui (key, value, lastUpdated) VALUES ($1, $2, $3); COMMIT;"
-		params := []parser.Datum{
-			parser.DString(req.Key),              // $1
-			parser.DBytes(req.Value),             // $2
-			parser.DTimestamp{Time: ts.GoTime()}, // $3
-		}
-		r := s.sqlExecutor.ExecuteStatements(user, &session, query, params)
-		if err := s.checkQueryResults(r.ResultList, 2); err != nil {
-			return nil, s.serverError(err)
-		}
-		if a, e := r.ResultList[0].RowsAffected, 1; a != e {
-			return nil, s.serverErrorf("rows affected %d != expected %d", a, e)
-		}
+	r := s.sqlExecutor.ExecuteStatements(user, &session, "COMMIT;", nil)
+	if err := s.checkQueryResults(r.ResultList, 1); err != nil {
+		return nil, s.serverError(err)
 	}
-
-	return &SetUIDataResponse{}, nil
+	return resp, nil
 }
 
-// GetUIData returns data associated with the given key, which was stored
-// earlier through SetUIData.
+// GetUIData returns data associated with the given key, and/or the keys
+// given by keys, which was stored earlier through SetUIData.
 func (s *adminServer) GetUIData(_ context.Context, req *GetUIDataRequest) (*GetUIDataResponse, error) {
 	var session sql.Session
 	user := s.getUser(req)
 
-	if len(req.Key) == 0 {
+	if len(req.Key) == 0 && len(req.Keys) == 0 {
 		return nil, grpc.Errorf(codes.InvalidArgument, "key cannot be empty")
 	}
 
-	val, ts, err := s.getUIData(&session, user, req.Key)
-	if err != nil {
+	// Read all the keys in a single transaction, so that they are
+	// consistent with each other.
+	if len(req.Keys) > 0 {
+		br := s.sqlExecutor.ExecuteStatements(user, &session, "BEGIN;", nil)
+		if err := s.checkQueryResults(br.ResultList, 1); err != nil {
+			return nil, s.serverError(err)
+		}
+	}
+
+	resp := &GetUIDataResponse{}
+	if len(req.Key) > 0 {
+		data, err := s.getUIData(&session, user, req.Key)
+		if err != nil {
+			if err == errUIKeyNotFound {
+				return nil, grpc.Errorf(codes.NotFound, "key %s not found", req.Key)
+			}
+			return nil, s.serverError(err)
+		}
+		resp.Value, resp.LastUpdated, resp.Generation = data.value, &data.lastUpdated, data.generation
+	}
+	for _, key := range req.Keys {
+		data, err := s.getUIData(&session, user, key)
 		if err == errUIKeyNotFound {
-			return nil, grpc.Errorf(codes.NotFound, "key %s not found", req.Key)
+			continue
+		} else if err != nil {
+			return nil, s.serverError(err)
 		}
-		return nil, s.serverError(err)
+		resp.KeyValues = append(resp.KeyValues, &GetUIDataResponse_KeyValue{
+			Key:         key,
+			Value:       data.value,
+			LastUpdated: &data.lastUpdated,
+			Generation:  data.generation,
+		})
 	}
 
-	return &GetUIDataResponse{Value: val, LastUpdated: &ts}, nil
+	if len(req.Keys) > 0 {
+		r := s.sqlExecutor.ExecuteStatements(user, &session, "COMMIT;", nil)
+		if err := s.checkQueryResults(r.ResultList, 1); err != nil {
+			return nil, s.serverError(err)
+		}
+	}
+	return resp, nil
+}
+
+// Metrics streams snapshots of the node's metrics to the client at the
+// requested interval until the client goes away or the server drains.
+func (s *adminServer) Metrics(req *MetricsRequest, stream Admin_MetricsServer) error {
+	interval := time.Duration(req.IntervalNanos)
+	if interval == 0 {
+		interval = defaultMetricsInterval
+	} else if interval < minMetricsInterval {
+		return grpc.Errorf(codes.InvalidArgument, "interval must be at least %s", minMetricsInterval)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		snapshot := &MetricsSnapshot{}
+		for _, data := range s.metricSource.GetTimeSeriesData() {
+			if matchesMetricPrefix(data.Name, req.Prefixes) {
+				snapshot.Data = append(snapshot.Data, data)
+			}
+		}
+		if err := stream.Send(snapshot); err != nil {
+			return err
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stream.Context().Done():
+			return nil
+		case <-s.stopper.ShouldDrain():
+			return nil
+		}
+	}
+}
+
+// matchesMetricPrefix returns true if prefixes is empty or name starts with
+// one of the prefixes.
+func matchesMetricPrefix(name string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
 }
 
 // sqlQuery allows you to incrementally build a SQL query that uses
@@ -23,12 +23,15 @@
 		SetUIDataResponse
 		GetUIDataRequest
 		GetUIDataResponse
+		MetricsRequest
+		MetricsSnapshot
 */
 package server
 
 import proto "github.com/gogo/protobuf/proto"
 import fmt "fmt"
 import math "math"
+import cockroach_ts "github.com/cockroachdb/cockroach/ts"
 import _ "github.com/gengo/grpc-gateway/third_party/googleapis/google/api"
 
 // skipping weak import gogoproto "github.com/cockroachdb/gogoproto"
@@ -253,30 +256,62 @@ func (m *EventsResponse_Event_Timestamp) String() string { return proto.CompactT
 func (*EventsResponse_Event_Timestamp) ProtoMessage()    {}
 
 // SetUIDataRequest stores a value in the system.ui table with the given key
-// and value.
+// and value, and/or the values of several keys atomically.
 type SetUIDataRequest struct {
 	// key identifies the key to set.
 	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
 	// value identifies the value to store with the key.
 	Value []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// key_values are set in the same transaction as key, if any. Either all
+	// of them are set or none is.
+	KeyValues []*SetUIDataRequest_KeyValue `protobuf:"bytes,3,rep,name=key_values" json:"key_values,omitempty"`
 }
 
 func (m *SetUIDataRequest) Reset()         { *m = SetUIDataRequest{} }
 func (m *SetUIDataRequest) String() string { return proto.CompactTextString(m) }
 func (*SetUIDataRequest) ProtoMessage()    {}
 
-// SetUIDataResponse is currently an empty response.
+// KeyValue is a key to set along with its value.
+type SetUIDataRequest_KeyValue struct {
+	Key   string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// conditional, if set, makes the request fail unless the generation of
+	// the key is expected_generation, which is zero for keys which don't
+	// exist. This keeps concurrent editors from clobbering each other.
+	Conditional        bool  `protobuf:"varint,3,opt,name=conditional,proto3" json:"conditional,omitempty"`
+	ExpectedGeneration int64 `protobuf:"varint,4,opt,name=expected_generation,proto3" json:"expected_generation,omitempty"`
+}
+
+func (m *SetUIDataRequest_KeyValue) Reset()         { *m = SetUIDataRequest_KeyValue{} }
+func (m *SetUIDataRequest_KeyValue) String() string { return proto.CompactTextString(m) }
+func (*SetUIDataRequest_KeyValue) ProtoMessage()    {}
+
+// SetUIDataResponse contains the new generations of the keys which were set.
 type SetUIDataResponse struct {
+	// generations are in the order of key, if set, followed by key_values.
+	Generations []*SetUIDataResponse_Generation `protobuf:"bytes,1,rep,name=generations" json:"generations,omitempty"`
 }
 
 func (m *SetUIDataResponse) Reset()         { *m = SetUIDataResponse{} }
 func (m *SetUIDataResponse) String() string { return proto.CompactTextString(m) }
 func (*SetUIDataResponse) ProtoMessage()    {}
 
+type SetUIDataResponse_Generation struct {
+	Key        string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Generation int64  `protobuf:"varint,2,opt,name=generation,proto3" json:"generation,omitempty"`
+}
+
+func (m *SetUIDataResponse_Generation) Reset()         { *m = SetUIDataResponse_Generation{} }
+func (m *SetUIDataResponse_Generation) String() string { return proto.CompactTextString(m) }
+func (*SetUIDataResponse_Generation) ProtoMessage()    {}
+
 // GETUIDataRequest requests the value of the given key from the system.ui
 // table.
 type GetUIDataRequest struct {
 	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// keys are further keys whose values are read in the same transaction as
+	// key, if any. Keys which don't exist are omitted from the response.
+	Keys []string `protobuf:"bytes,2,rep,name=keys" json:"keys,omitempty"`
 }
 
 func (m *GetUIDataRequest) Reset()         { *m = GetUIDataRequest{} }
@@ -290,6 +325,10 @@ type GetUIDataResponse struct {
 	Value []byte `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
 	// last_updated is the time at which the value was last updated.
 	LastUpdated *GetUIDataResponse_Timestamp `protobuf:"bytes,2,opt,name=last_updated" json:"last_updated,omitempty"`
+	// generation is the number of times the key was set.
+	Generation int64 `protobuf:"varint,3,opt,name=generation,proto3" json:"generation,omitempty"`
+	// key_values are the values of the keys requested by keys.
+	KeyValues []*GetUIDataResponse_KeyValue `protobuf:"bytes,4,rep,name=key_values" json:"key_values,omitempty"`
 }
 
 func (m *GetUIDataResponse) Reset()         { *m = GetUIDataResponse{} }
@@ -311,6 +350,43 @@ func (m *GetUIDataResponse_Timestamp) Reset()         { *m = GetUIDataResponse_T
 func (m *GetUIDataResponse_Timestamp) String() string { return proto.CompactTextString(m) }
 func (*GetUIDataResponse_Timestamp) ProtoMessage()    {}
 
+// KeyValue is the value of one of the keys requested, along with its
+// version.
+type GetUIDataResponse_KeyValue struct {
+	Key         string                       `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value       []byte                       `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	LastUpdated *GetUIDataResponse_Timestamp `protobuf:"bytes,3,opt,name=last_updated" json:"last_updated,omitempty"`
+	Generation  int64                        `protobuf:"varint,4,opt,name=generation,proto3" json:"generation,omitempty"`
+}
+
+func (m *GetUIDataResponse_KeyValue) Reset()         { *m = GetUIDataResponse_KeyValue{} }
+func (m *GetUIDataResponse_KeyValue) String() string { return proto.CompactTextString(m) }
+func (*GetUIDataResponse_KeyValue) ProtoMessage()    {}
+
+// MetricsRequest requests a stream of snapshots of the node's metrics.
+type MetricsRequest struct {
+	// interval_nanos is the interval at which snapshots are sent. If zero,
+	// snapshots are sent every 10 seconds.
+	IntervalNanos int64 `protobuf:"varint,1,opt,name=interval_nanos,proto3" json:"interval_nanos,omitempty"`
+	// prefixes, if non-empty, restricts the snapshots to the metrics whose
+	// names start with one of the prefixes.
+	Prefixes []string `protobuf:"bytes,2,rep,name=prefixes" json:"prefixes,omitempty"`
+}
+
+func (m *MetricsRequest) Reset()         { *m = MetricsRequest{} }
+func (m *MetricsRequest) String() string { return proto.CompactTextString(m) }
+func (*MetricsRequest) ProtoMessage()    {}
+
+// MetricsSnapshot contains the current value of each of the node's
+// metrics, as a single datapoint per metric and source.
+type MetricsSnapshot struct {
+	Data []cockroach_ts.TimeSeriesData `protobuf:"bytes,1,rep,name=data" json:"data"`
+}
+
+func (m *MetricsSnapshot) Reset()         { *m = MetricsSnapshot{} }
+func (m *MetricsSnapshot) String() string { return proto.CompactTextString(m) }
+func (*MetricsSnapshot) ProtoMessage()    {}
+
 func init() {
 	proto.RegisterType((*DatabasesRequest)(nil), "cockroach.server.DatabasesRequest")
 	proto.RegisterType((*DatabasesResponse)(nil), "cockroach.server.DatabasesResponse")
@@ -330,10 +406,15 @@ func init() {
 	proto.RegisterType((*EventsResponse_Event)(nil), "cockroach.server.EventsResponse.Event")
 	proto.RegisterType((*EventsResponse_Event_Timestamp)(nil), "cockroach.server.EventsResponse.Event.Timestamp")
 	proto.RegisterType((*SetUIDataRequest)(nil), "cockroach.server.SetUIDataRequest")
+	proto.RegisterType((*SetUIDataRequest_KeyValue)(nil), "cockroach.server.SetUIDataRequest.KeyValue")
 	proto.RegisterType((*SetUIDataResponse)(nil), "cockroach.server.SetUIDataResponse")
+	proto.RegisterType((*SetUIDataResponse_Generation)(nil), "cockroach.server.SetUIDataResponse.Generation")
 	proto.RegisterType((*GetUIDataRequest)(nil), "cockroach.server.GetUIDataRequest")
 	proto.RegisterType((*GetUIDataResponse)(nil), "cockroach.server.GetUIDataResponse")
 	proto.RegisterType((*GetUIDataResponse_Timestamp)(nil), "cockroach.server.GetUIDataResponse.Timestamp")
+	proto.RegisterType((*GetUIDataResponse_KeyValue)(nil), "cockroach.server.GetUIDataResponse.KeyValue")
+	proto.RegisterType((*MetricsRequest)(nil), "cockroach.server.MetricsRequest")
+	proto.RegisterType((*MetricsSnapshot)(nil), "cockroach.server.MetricsSnapshot")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -367,6 +448,9 @@ type AdminClient interface {
 	SetUIData(ctx context.Context, in *SetUIDataRequest, opts ...grpc.CallOption) (*SetUIDataResponse, error)
 	// Example URL: /_admin/v1/uidata?key=MYKEY
 	GetUIData(ctx context.Context, in *GetUIDataRequest, opts ...grpc.CallOption) (*GetUIDataResponse, error)
+	// Metrics streams snapshots of the node's metrics at the requested
+	// interval. It is not exposed over HTTP.
+	Metrics(ctx context.Context, in *MetricsRequest, opts ...grpc.CallOption) (Admin_MetricsClient, error)
 }
 
 type adminClient struct {
@@ -440,6 +524,38 @@ func (c *adminClient) GetUIData(ctx context.Context, in *GetUIDataRequest, opts
 	return out, nil
 }
 
+func (c *adminClient) Metrics(ctx context.Context, in *MetricsRequest, opts ...grpc.CallOption) (Admin_MetricsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Admin_serviceDesc.Streams[0], c.cc, "/cockroach.server.Admin/Metrics", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &adminMetricsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Admin_MetricsClient interface {
+	Recv() (*MetricsSnapshot, error)
+	grpc.ClientStream
+}
+
+type adminMetricsClient struct {
+	grpc.ClientStream
+}
+
+func (x *adminMetricsClient) Recv() (*MetricsSnapshot, error) {
+	m := new(MetricsSnapshot)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Server API for Admin service
 
 type AdminServer interface {
@@ -467,6 +583,9 @@ type AdminServer interface {
 	SetUIData(context.Context, *SetUIDataRequest) (*SetUIDataResponse, error)
 	// Example URL: /_admin/v1/uidata?key=MYKEY
 	GetUIData(context.Context, *GetUIDataRequest) (*GetUIDataResponse, error)
+	// Metrics streams snapshots of the node's metrics at the requested
+	// interval. It is not exposed over HTTP.
+	Metrics(*MetricsRequest, Admin_MetricsServer) error
 }
 
 func RegisterAdminServer(s *grpc.Server, srv AdminServer) {
@@ -557,6 +676,27 @@ func _Admin_GetUIData_Handler(srv interface{}, ctx context.Context, dec func(int
 	return out, nil
 }
 
+func _Admin_Metrics_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(MetricsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AdminServer).Metrics(m, &adminMetricsServer{stream})
+}
+
+type Admin_MetricsServer interface {
+	Send(*MetricsSnapshot) error
+	grpc.ServerStream
+}
+
+type adminMetricsServer struct {
+	grpc.ServerStream
+}
+
+func (x *adminMetricsServer) Send(m *MetricsSnapshot) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _Admin_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "cockroach.server.Admin",
 	HandlerType: (*AdminServer)(nil),
@@ -590,7 +730,13 @@ var _Admin_serviceDesc = grpc.ServiceDesc{
 			Handler:    _Admin_GetUIData_Handler,
 		},
 	},
-	Streams: []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Metrics",
+			Handler:       _Admin_Metrics_Handler,
+			ServerStreams: true,
+		},
+	},
 }
 
 func (m *DatabasesRequest) Marshal() (data []byte, err error) {
@@ -1228,6 +1374,65 @@ func (m *SetUIDataRequest) MarshalTo(data []byte) (int, error) {
 			i += copy(data[i:], m.Value)
 		}
 	}
+	if len(m.KeyValues) > 0 {
+		for _, msg := range m.KeyValues {
+			data[i] = 0x1a
+			i++
+			i = encodeVarintAdmin(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *SetUIDataRequest_KeyValue) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *SetUIDataRequest_KeyValue) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Key) > 0 {
+		data[i] = 0xa
+		i++
+		i = encodeVarintAdmin(data, i, uint64(len(m.Key)))
+		i += copy(data[i:], m.Key)
+	}
+	if m.Value != nil {
+		if len(m.Value) > 0 {
+			data[i] = 0x12
+			i++
+			i = encodeVarintAdmin(data, i, uint64(len(m.Value)))
+			i += copy(data[i:], m.Value)
+		}
+	}
+	if m.Conditional {
+		data[i] = 0x18
+		i++
+		if m.Conditional {
+			data[i] = 1
+		} else {
+			data[i] = 0
+		}
+		i++
+	}
+	if m.ExpectedGeneration != 0 {
+		data[i] = 0x20
+		i++
+		i = encodeVarintAdmin(data, i, uint64(m.ExpectedGeneration))
+	}
 	return i, nil
 }
 
@@ -1246,6 +1451,47 @@ func (m *SetUIDataResponse) MarshalTo(data []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Generations) > 0 {
+		for _, msg := range m.Generations {
+			data[i] = 0xa
+			i++
+			i = encodeVarintAdmin(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *SetUIDataResponse_Generation) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *SetUIDataResponse_Generation) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Key) > 0 {
+		data[i] = 0xa
+		i++
+		i = encodeVarintAdmin(data, i, uint64(len(m.Key)))
+		i += copy(data[i:], m.Key)
+	}
+	if m.Generation != 0 {
+		data[i] = 0x10
+		i++
+		i = encodeVarintAdmin(data, i, uint64(m.Generation))
+	}
 	return i, nil
 }
 
@@ -1270,6 +1516,21 @@ func (m *GetUIDataRequest) MarshalTo(data []byte) (int, error) {
 		i = encodeVarintAdmin(data, i, uint64(len(m.Key)))
 		i += copy(data[i:], m.Key)
 	}
+	if len(m.Keys) > 0 {
+		for _, s := range m.Keys {
+			data[i] = 0x12
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				data[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			data[i] = uint8(l)
+			i++
+			i += copy(data[i:], s)
+		}
+	}
 	return i, nil
 }
 
@@ -1306,6 +1567,23 @@ func (m *GetUIDataResponse) MarshalTo(data []byte) (int, error) {
 		}
 		i += n2
 	}
+	if m.Generation != 0 {
+		data[i] = 0x18
+		i++
+		i = encodeVarintAdmin(data, i, uint64(m.Generation))
+	}
+	if len(m.KeyValues) > 0 {
+		for _, msg := range m.KeyValues {
+			data[i] = 0x22
+			i++
+			i = encodeVarintAdmin(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
 	return i, nil
 }
 
@@ -1337,6 +1615,121 @@ func (m *GetUIDataResponse_Timestamp) MarshalTo(data []byte) (int, error) {
 	return i, nil
 }
 
+func (m *GetUIDataResponse_KeyValue) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *GetUIDataResponse_KeyValue) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Key) > 0 {
+		data[i] = 0xa
+		i++
+		i = encodeVarintAdmin(data, i, uint64(len(m.Key)))
+		i += copy(data[i:], m.Key)
+	}
+	if m.Value != nil {
+		if len(m.Value) > 0 {
+			data[i] = 0x12
+			i++
+			i = encodeVarintAdmin(data, i, uint64(len(m.Value)))
+			i += copy(data[i:], m.Value)
+		}
+	}
+	if m.LastUpdated != nil {
+		data[i] = 0x1a
+		i++
+		i = encodeVarintAdmin(data, i, uint64(m.LastUpdated.Size()))
+		n3, err := m.LastUpdated.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n3
+	}
+	if m.Generation != 0 {
+		data[i] = 0x20
+		i++
+		i = encodeVarintAdmin(data, i, uint64(m.Generation))
+	}
+	return i, nil
+}
+
+func (m *MetricsRequest) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *MetricsRequest) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.IntervalNanos != 0 {
+		data[i] = 0x8
+		i++
+		i = encodeVarintAdmin(data, i, uint64(m.IntervalNanos))
+	}
+	if len(m.Prefixes) > 0 {
+		for _, s := range m.Prefixes {
+			data[i] = 0x12
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				data[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			data[i] = uint8(l)
+			i++
+			i += copy(data[i:], s)
+		}
+	}
+	return i, nil
+}
+
+func (m *MetricsSnapshot) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *MetricsSnapshot) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Data) > 0 {
+		for _, msg := range m.Data {
+			data[i] = 0xa
+			i++
+			i = encodeVarintAdmin(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
 func encodeFixed64Admin(data []byte, offset int, v uint64) int {
 	data[offset] = uint8(v)
 	data[offset+1] = uint8(v >> 8)
@@ -1636,12 +2029,59 @@ func (m *SetUIDataRequest) Size() (n int) {
 			n += 1 + l + sovAdmin(uint64(l))
 		}
 	}
-	return n
-}
+	if len(m.KeyValues) > 0 {
+		for _, e := range m.KeyValues {
+			l = e.Size()
+			n += 1 + l + sovAdmin(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *SetUIDataRequest_KeyValue) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	if m.Value != nil {
+		l = len(m.Value)
+		if l > 0 {
+			n += 1 + l + sovAdmin(uint64(l))
+		}
+	}
+	if m.Conditional {
+		n += 2
+	}
+	if m.ExpectedGeneration != 0 {
+		n += 1 + sovAdmin(uint64(m.ExpectedGeneration))
+	}
+	return n
+}
 
 func (m *SetUIDataResponse) Size() (n int) {
 	var l int
 	_ = l
+	if len(m.Generations) > 0 {
+		for _, e := range m.Generations {
+			l = e.Size()
+			n += 1 + l + sovAdmin(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *SetUIDataResponse_Generation) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	if m.Generation != 0 {
+		n += 1 + sovAdmin(uint64(m.Generation))
+	}
 	return n
 }
 
@@ -1652,6 +2092,12 @@ func (m *GetUIDataRequest) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovAdmin(uint64(l))
 	}
+	if len(m.Keys) > 0 {
+		for _, s := range m.Keys {
+			l = len(s)
+			n += 1 + l + sovAdmin(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -1668,6 +2114,15 @@ func (m *GetUIDataResponse) Size() (n int) {
 		l = m.LastUpdated.Size()
 		n += 1 + l + sovAdmin(uint64(l))
 	}
+	if m.Generation != 0 {
+		n += 1 + sovAdmin(uint64(m.Generation))
+	}
+	if len(m.KeyValues) > 0 {
+		for _, e := range m.KeyValues {
+			l = e.Size()
+			n += 1 + l + sovAdmin(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -1683,6 +2138,56 @@ func (m *GetUIDataResponse_Timestamp) Size() (n int) {
 	return n
 }
 
+func (m *GetUIDataResponse_KeyValue) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	if m.Value != nil {
+		l = len(m.Value)
+		if l > 0 {
+			n += 1 + l + sovAdmin(uint64(l))
+		}
+	}
+	if m.LastUpdated != nil {
+		l = m.LastUpdated.Size()
+		n += 1 + l + sovAdmin(uint64(l))
+	}
+	if m.Generation != 0 {
+		n += 1 + sovAdmin(uint64(m.Generation))
+	}
+	return n
+}
+
+func (m *MetricsRequest) Size() (n int) {
+	var l int
+	_ = l
+	if m.IntervalNanos != 0 {
+		n += 1 + sovAdmin(uint64(m.IntervalNanos))
+	}
+	if len(m.Prefixes) > 0 {
+		for _, s := range m.Prefixes {
+			l = len(s)
+			n += 1 + l + sovAdmin(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *MetricsSnapshot) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Data) > 0 {
+		for _, e := range m.Data {
+			l = e.Size()
+			n += 1 + l + sovAdmin(uint64(l))
+		}
+	}
+	return n
+}
+
 func sovAdmin(x uint64) (n int) {
 	for {
 		n++
@@ -3610,6 +4115,37 @@ func (m *SetUIDataRequest) Unmarshal(data []byte) error {
 				m.Value = []byte{}
 			}
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field KeyValues", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.KeyValues = append(m.KeyValues, &SetUIDataRequest_KeyValue{})
+			if err := m.KeyValues[len(m.KeyValues)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipAdmin(data[iNdEx:])
@@ -3631,7 +4167,7 @@ func (m *SetUIDataRequest) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *SetUIDataResponse) Unmarshal(data []byte) error {
+func (m *SetUIDataRequest_KeyValue) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3654,12 +4190,111 @@ func (m *SetUIDataResponse) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: SetUIDataResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: SetUIDataRequest_KeyValue: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: SetUIDataResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: SetUIDataRequest_KeyValue: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(data[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Value = append(m.Value[:0], data[iNdEx:postIndex]...)
+			if m.Value == nil {
+				m.Value = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Conditional", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Conditional = bool(v != 0)
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExpectedGeneration", wireType)
+			}
+			m.ExpectedGeneration = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.ExpectedGeneration |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipAdmin(data[iNdEx:])
@@ -3681,7 +4316,7 @@ func (m *SetUIDataResponse) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *GetUIDataRequest) Unmarshal(data []byte) error {
+func (m *SetUIDataResponse) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3704,17 +4339,17 @@ func (m *GetUIDataRequest) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: GetUIDataRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: SetUIDataResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: GetUIDataRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: SetUIDataResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Generations", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowAdmin
@@ -3724,20 +4359,22 @@ func (m *GetUIDataRequest) Unmarshal(data []byte) error {
 				}
 				b := data[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				msglen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthAdmin
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Key = string(data[iNdEx:postIndex])
+			m.Generations = append(m.Generations, &SetUIDataResponse_Generation{})
+			if err := m.Generations[len(m.Generations)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -3760,7 +4397,7 @@ func (m *GetUIDataRequest) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *GetUIDataResponse) Unmarshal(data []byte) error {
+func (m *SetUIDataResponse_Generation) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3783,17 +4420,17 @@ func (m *GetUIDataResponse) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: GetUIDataResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: SetUIDataResponse_Generation: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: GetUIDataResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: SetUIDataResponse_Generation: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
 			}
-			var byteLen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowAdmin
@@ -3803,28 +4440,26 @@ func (m *GetUIDataResponse) Unmarshal(data []byte) error {
 				}
 				b := data[iNdEx]
 				iNdEx++
-				byteLen |= (int(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthAdmin
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + intStringLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Value = append(m.Value[:0], data[iNdEx:postIndex]...)
-			if m.Value == nil {
-				m.Value = []byte{}
-			}
+			m.Key = string(data[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field LastUpdated", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Generation", wireType)
 			}
-			var msglen int
+			m.Generation = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowAdmin
@@ -3834,13 +4469,221 @@ func (m *GetUIDataResponse) Unmarshal(data []byte) error {
 				}
 				b := data[iNdEx]
 				iNdEx++
-				msglen |= (int(b) & 0x7F) << shift
+				m.Generation |= (int64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
-				return ErrInvalidLengthAdmin
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAdmin(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetUIDataRequest) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAdmin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetUIDataRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetUIDataRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(data[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Keys", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Keys = append(m.Keys, string(data[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAdmin(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetUIDataResponse) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAdmin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetUIDataResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetUIDataResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Value = append(m.Value[:0], data[iNdEx:postIndex]...)
+			if m.Value == nil {
+				m.Value = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LastUpdated", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthAdmin
 			}
 			postIndex := iNdEx + msglen
 			if postIndex > l {
@@ -3853,6 +4696,56 @@ func (m *GetUIDataResponse) Unmarshal(data []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Generation", wireType)
+			}
+			m.Generation = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.Generation |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field KeyValues", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.KeyValues = append(m.KeyValues, &GetUIDataResponse_KeyValue{})
+			if err := m.KeyValues[len(m.KeyValues)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipAdmin(data[iNdEx:])
@@ -3962,6 +4855,347 @@ func (m *GetUIDataResponse_Timestamp) Unmarshal(data []byte) error {
 	}
 	return nil
 }
+func (m *GetUIDataResponse_KeyValue) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAdmin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetUIDataResponse_KeyValue: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetUIDataResponse_KeyValue: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(data[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Value = append(m.Value[:0], data[iNdEx:postIndex]...)
+			if m.Value == nil {
+				m.Value = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LastUpdated", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.LastUpdated == nil {
+				m.LastUpdated = &GetUIDataResponse_Timestamp{}
+			}
+			if err := m.LastUpdated.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Generation", wireType)
+			}
+			m.Generation = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.Generation |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAdmin(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *MetricsRequest) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAdmin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: MetricsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: MetricsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IntervalNanos", wireType)
+			}
+			m.IntervalNanos = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.IntervalNanos |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Prefixes", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Prefixes = append(m.Prefixes, string(data[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAdmin(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *MetricsSnapshot) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAdmin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: MetricsSnapshot: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: MetricsSnapshot: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAdmin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Data = append(m.Data, cockroach_ts.TimeSeriesData{})
+			if err := m.Data[len(m.Data)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAdmin(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthAdmin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipAdmin(data []byte) (n int, err error) {
 	l := len(data)
 	iNdEx := 0
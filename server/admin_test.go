@@ -28,18 +28,27 @@ import (
 	"testing"
 	"time"
 
+	"github.com/gogo/protobuf/proto"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/cockroachdb/cockroach/config"
+	"github.com/cockroachdb/cockroach/keys"
 	"github.com/cockroachdb/cockroach/security"
 	"github.com/cockroachdb/cockroach/sql"
 	"github.com/cockroachdb/cockroach/testutils"
 	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/httputil"
 	"github.com/cockroachdb/cockroach/util/leaktest"
 )
 
 // getText fetches the HTTP response body as text in the form of a
 // byte slice from the specified URL.
 func getText(url string) ([]byte, error) {
-	// There are no particular permissions on admin endpoints, TestUser is fine.
-	client, err := testutils.NewTestBaseContext(TestUser).GetHTTPClient()
+	// The debug endpoints require root or node certificates; there are no
+	// particular permissions on the other endpoints.
+	client, err := testutils.NewTestBaseContext(security.RootUser).GetHTTPClient()
 	if err != nil {
 		return nil, err
 	}
@@ -131,22 +140,61 @@ func TestAdminNetTrace(t *testing.T) {
 // into the v parameter.
 func apiGet(s *TestServer, path string, v interface{}) error {
 	apiPath := apiEndpoint + path
-	client, err := s.Ctx.GetHTTPClient()
+	client, err := httputil.NewClient(&s.Ctx.Context)
 	if err != nil {
 		return err
 	}
-	return util.GetJSON(client, s.Ctx.HTTPRequestScheme(), s.HTTPAddr(), apiPath, v)
+	return client.GetJSON(context.Background(), s.HTTPAddr(), apiPath, v)
 }
 
 // apiPost issues a POST to the provided server using the given API path and
 // request body, marshalling the result into the v parameter.
 func apiPost(s *TestServer, path, body string, v interface{}) error {
 	apiPath := apiEndpoint + path
-	client, err := s.Ctx.GetHTTPClient()
+	client, err := httputil.NewClient(&s.Ctx.Context)
+	if err != nil {
+		return err
+	}
+	return client.PostJSON(context.Background(), s.HTTPAddr(), apiPath, json.RawMessage(body), v)
+}
+
+// apiGetProto issues a GET to the provided server using the given API path,
+// requesting a protobuf response which is unmarshaled into the msg parameter.
+func apiGetProto(s *TestServer, path string, msg proto.Message) error {
+	apiPath := apiEndpoint + path
+	client, err := httputil.NewClient(&s.Ctx.Context)
 	if err != nil {
 		return err
 	}
-	return util.PostJSON(client, s.Ctx.HTTPRequestScheme(), s.HTTPAddr(), apiPath, body, v)
+	return client.GetProto(context.Background(), s.HTTPAddr(), apiPath, msg)
+}
+
+// TestAdminAPIProtoEncoding verifies that the gateway endpoints return
+// protobuf responses when requested.
+func TestAdminAPIProtoEncoding(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := StartTestServer(t)
+	defer s.Stop()
+
+	var jsonResp, protoResp EventsResponse
+	if err := apiGet(s, "events", &jsonResp); err != nil {
+		t.Fatal(err)
+	}
+	if err := apiGetProto(s, "events", &protoResp); err != nil {
+		t.Fatal(err)
+	}
+	// Events may have been logged between the two requests.
+	if len(protoResp.Events) == 0 || len(protoResp.Events) < len(jsonResp.Events) {
+		t.Fatalf("expected at least %d events, got %d", len(jsonResp.Events), len(protoResp.Events))
+	}
+
+	var tableResp TableDetailsResponse
+	if err := apiGetProto(s, "databases/system/tables/namespace", &tableResp); err != nil {
+		t.Fatal(err)
+	}
+	if len(tableResp.Columns) == 0 {
+		t.Errorf("expected columns, got %+v", tableResp)
+	}
 }
 
 func TestAdminAPIDatabases(t *testing.T) {
@@ -537,3 +585,193 @@ func TestAdminAPIUIData(t *testing.T) {
 	mustSetUIData("bin", buf.Bytes())
 	expectValueEquals("bin", buf.Bytes())
 }
+
+// TestAdminAPIUIDataGenerations verifies that several UI keys are set
+// atomically and that conditional sets fail on generation mismatches.
+func TestAdminAPIUIDataGenerations(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := StartTestServer(t)
+	defer s.Stop()
+
+	set := func(kvs ...*SetUIDataRequest_KeyValue) (*SetUIDataResponse, error) {
+		return s.admin.SetUIData(context.Background(), &SetUIDataRequest{KeyValues: kvs})
+	}
+	expectGenerations := func(expected map[string]int64) {
+		req := &GetUIDataRequest{}
+		for key := range expected {
+			req.Keys = append(req.Keys, key)
+		}
+		resp, err := s.admin.GetUIData(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual := map[string]int64{}
+		for _, kv := range resp.KeyValues {
+			actual[kv.Key] = kv.Generation
+		}
+		for key, gen := range expected {
+			if gen == 0 {
+				delete(expected, key)
+			}
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("expected generations %v, got %v", expected, actual)
+		}
+	}
+
+	// New keys start at generation 1, and conditional sets of keys which
+	// don't exist expect generation 0.
+	resp, err := set(&SetUIDataRequest_KeyValue{Key: "a", Value: []byte("1")},
+		&SetUIDataRequest_KeyValue{Key: "b", Value: []byte("1"), Conditional: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Generations) != 2 || resp.Generations[0].Generation != 1 || resp.Generations[1].Generation != 1 {
+		t.Fatalf("unexpected generations %v", resp.Generations)
+	}
+	expectGenerations(map[string]int64{"a": 1, "b": 1, "c": 0})
+
+	// A conditional set with a stale generation fails, and none of the keys
+	// of the request are set.
+	if _, err := set(&SetUIDataRequest_KeyValue{Key: "c", Value: []byte("1")},
+		&SetUIDataRequest_KeyValue{Key: "a", Value: []byte("2"), Conditional: true, ExpectedGeneration: 0},
+	); !testutils.IsError(err, "key a is at generation 1, expected 0") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectGenerations(map[string]int64{"a": 1, "b": 1, "c": 0})
+
+	// With the current generation, it succeeds.
+	if _, err := set(&SetUIDataRequest_KeyValue{Key: "a", Value: []byte("2"), Conditional: true, ExpectedGeneration: 1},
+		&SetUIDataRequest_KeyValue{Key: "c", Value: []byte("1")}); err != nil {
+		t.Fatal(err)
+	}
+	expectGenerations(map[string]int64{"a": 2, "b": 1, "c": 1})
+
+	if _, err := set(&SetUIDataRequest_KeyValue{Key: "a"}, &SetUIDataRequest_KeyValue{Key: "a"}); !testutils.IsError(err, "set more than once") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// TestAdminAPIUIDataWithoutGenerations verifies that UI keys can be read
+// and set unconditionally if the system.ui table lacks the generation
+// column, as it does on clusters bootstrapped before it was added.
+func TestAdminAPIUIDataWithoutGenerations(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := StartTestServer(t)
+	defer s.Stop()
+
+	if present, err := s.admin.uiHasGeneration(security.RootUser); err != nil {
+		t.Fatal(err)
+	} else if !present {
+		t.Fatal("expected the generation column in a newly bootstrapped cluster")
+	}
+	// Make the server use the old schema, which the new one is compatible
+	// with as the generation column is nullable.
+	s.admin.uiGeneration.Lock()
+	s.admin.uiGeneration.present = false
+	s.admin.uiGeneration.Unlock()
+
+	for _, value := range []string{"1", "2"} {
+		resp, err := s.admin.SetUIData(context.Background(), &SetUIDataRequest{Key: "a", Value: []byte(value)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Generations) != 1 || resp.Generations[0].Generation != 0 {
+			t.Fatalf("unexpected generations %v", resp.Generations)
+		}
+		getResp, err := s.admin.GetUIData(context.Background(), &GetUIDataRequest{Key: "a"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(getResp.Value) != value || getResp.Generation != 0 {
+			t.Fatalf("expected value %s at generation 0, got %s at %d", value, getResp.Value, getResp.Generation)
+		}
+	}
+
+	if _, err := s.admin.SetUIData(context.Background(), &SetUIDataRequest{
+		KeyValues: []*SetUIDataRequest_KeyValue{{Key: "a", Value: []byte("3"), Conditional: true}},
+	}); grpc.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected a failed precondition, got %v", err)
+	}
+}
+
+// TestAdminZoneConfig verifies that the zone config endpoint resolves the
+// zone config of a table which has none of its own to the default one.
+func TestAdminZoneConfig(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := StartTestServer(t)
+	defer s.Stop()
+
+	var session sql.Session
+	for _, q := range []string{"CREATE DATABASE test", "CREATE TABLE test.tbl (k INT PRIMARY KEY)"} {
+		res := s.sqlExecutor.ExecuteStatements(security.RootUser, &session, q, nil)
+		if res.ResultList[0].PErr != nil {
+			t.Fatalf("error executing '%s': %s", q, res.ResultList[0].PErr)
+		}
+	}
+
+	var resp struct {
+		ZoneID     uint32            `json:"zone_id"`
+		ZoneConfig config.ZoneConfig `json:"zone_config"`
+	}
+	// The table is only visible once the system config has been gossiped.
+	util.SucceedsSoon(t, func() error {
+		return apiGet(s, "zone-config?database=test&table=tbl", &resp)
+	})
+	if resp.ZoneID != keys.RootNamespaceID {
+		t.Errorf("expected the default zone config, got the zone config of %d", resp.ZoneID)
+	}
+	if exp := config.DefaultZoneConfig().RangeMaxBytes; resp.ZoneConfig.RangeMaxBytes != exp {
+		t.Errorf("expected range max bytes %d, got %d", exp, resp.ZoneConfig.RangeMaxBytes)
+	}
+
+	if err := apiGet(s, "zone-config?database=test", &resp); err == nil {
+		t.Error("expected an error without a table")
+	}
+}
+
+func TestAdminMetricsStream(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := StartTestServer(t)
+	defer s.Stop()
+
+	conn, err := s.RPCContext().GRPCDial(s.ServingAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewAdminClient(conn)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const prefix = "cr.node."
+	stream, err := client.Metrics(ctx, &MetricsRequest{
+		IntervalNanos: int64(minMetricsInterval),
+		Prefixes:      []string{prefix},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		snapshot, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(snapshot.Data) == 0 {
+			t.Fatalf("%d: expected metrics in snapshot", i)
+		}
+		for _, data := range snapshot.Data {
+			if !strings.HasPrefix(data.Name, prefix) {
+				t.Errorf("%d: unexpected metric %s", i, data.Name)
+			}
+		}
+	}
+
+	// Intervals below the minimum are rejected.
+	stream, err = client.Metrics(ctx, &MetricsRequest{IntervalNanos: int64(time.Millisecond)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); grpc.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected invalid argument error, got %v", err)
+	}
+}
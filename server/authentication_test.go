@@ -96,12 +96,16 @@ func TestSSLEnforcement(t *testing.T) {
 		{"GET", healthPath, nil, noCertsContext, true, http.StatusOK},
 		{"GET", healthPath, nil, insecureContext, false, -1},
 
-		// /debug/: server.adminServer: no auth.
+		// /debug/: server.adminServer: root or node certs only.
 		{"GET", debugEndpoint + "vars", nil, rootCertsContext, true, http.StatusOK},
 		{"GET", debugEndpoint + "vars", nil, nodeCertsContext, true, http.StatusOK},
-		{"GET", debugEndpoint + "vars", nil, testCertsContext, true, http.StatusOK},
-		{"GET", debugEndpoint + "vars", nil, noCertsContext, true, http.StatusOK},
+		{"GET", debugEndpoint + "vars", nil, testCertsContext, true, http.StatusForbidden},
+		{"GET", debugEndpoint + "vars", nil, noCertsContext, true, http.StatusUnauthorized},
 		{"GET", debugEndpoint + "vars", nil, insecureContext, false, -1},
+		{"GET", debugEndpoint + "requests", nil, rootCertsContext, true, http.StatusOK},
+		{"GET", debugEndpoint + "requests", nil, testCertsContext, true, http.StatusForbidden},
+		{"GET", debugEndpoint + "pprof/", nil, nodeCertsContext, true, http.StatusOK},
+		{"GET", debugEndpoint + "pprof/", nil, noCertsContext, true, http.StatusUnauthorized},
 
 		// /_status/nodes: server.statusServer: no auth.
 		{"GET", statusNodesPrefix, nil, rootCertsContext, true, http.StatusOK},
@@ -138,3 +142,43 @@ func TestSSLEnforcement(t *testing.T) {
 		}
 	}
 }
+
+// Verify that the debug endpoints are served exclusively on the debug
+// address when one is specified.
+func TestDebugAddr(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := NewTestContext()
+	ctx.DebugAddr = "127.0.0.1:0"
+	s := StartTestServerWithContext(t, ctx)
+	defer s.Stop()
+
+	rootCertsContext := testutils.NewTestBaseContext(security.RootUser)
+	testCertsContext := testutils.NewTestBaseContext(TestUser)
+
+	testCases := []struct {
+		addr string
+		ctx  *base.Context
+		code int
+	}{
+		{s.Ctx.DebugAddr, rootCertsContext, http.StatusOK},
+		{s.Ctx.DebugAddr, testCertsContext, http.StatusForbidden},
+		// The HTTP port falls through to the UI file server.
+		{s.HTTPAddr(), rootCertsContext, http.StatusNotFound},
+	}
+
+	for tcNum, tc := range testCases {
+		client, err := tc.ctx.GetHTTPClient()
+		if err != nil {
+			t.Fatalf("[%d]: failed to get http client: %v", tcNum, err)
+		}
+		url := fmt.Sprintf("%s://%s%svars", tc.ctx.HTTPRequestScheme(), tc.addr, debugEndpoint)
+		resp, err := doHTTPReq(t, client, "GET", url, nil)
+		if err != nil {
+			t.Fatalf("[%d]: %s", tcNum, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.code {
+			t.Errorf("[%d]: expected status code %d, got %d", tcNum, tc.code, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/keys"
+	"github.com/cockroachdb/cockroach/server/status"
+	"github.com/cockroachdb/cockroach/sql"
+	"github.com/cockroachdb/cockroach/storage"
+)
+
+// clusterStatus implements sql.ClusterStatus. Node and store statuses are
+// read from the status summaries which every node periodically records, the
+// same ones served by the status server.
+type clusterStatus struct {
+	db       *client.DB
+	recorder *status.MetricsRecorder
+}
+
+var _ sql.ClusterStatus = clusterStatus{}
+
+// NodeStatuses implements sql.ClusterStatus.
+func (cs clusterStatus) NodeStatuses(maxRows int64) ([]sql.NodeStatus, error) {
+	rows, pErr := cs.db.Scan(keys.StatusNodePrefix, keys.StatusNodePrefix.PrefixEnd(), maxRows)
+	if pErr != nil {
+		return nil, pErr.GoError()
+	}
+	nodeStatuses := make([]sql.NodeStatus, 0, len(rows))
+	for _, row := range rows {
+		var ns status.NodeStatus
+		if err := row.ValueProto(&ns); err != nil {
+			return nil, err
+		}
+		nodeStatuses = append(nodeStatuses, sql.NodeStatus{
+			NodeID:           ns.Desc.NodeID,
+			Address:          ns.Desc.Address.AddressField,
+			StartedAt:        time.Unix(0, ns.StartedAt).UTC(),
+			UpdatedAt:        time.Unix(0, ns.UpdatedAt).UTC(),
+			RangeCount:       int64(ns.RangeCount),
+			LeaderRangeCount: int64(ns.LeaderRangeCount),
+			LiveBytes:        ns.Stats.LiveBytes,
+		})
+	}
+	return nodeStatuses, nil
+}
+
+// StoreStatuses implements sql.ClusterStatus.
+func (cs clusterStatus) StoreStatuses(maxRows int64) ([]sql.StoreStatus, error) {
+	rows, pErr := cs.db.Scan(keys.StatusStorePrefix, keys.StatusStorePrefix.PrefixEnd(), maxRows)
+	if pErr != nil {
+		return nil, pErr.GoError()
+	}
+	storeStatuses := make([]sql.StoreStatus, 0, len(rows))
+	for _, row := range rows {
+		var ss storage.StoreStatus
+		if err := row.ValueProto(&ss); err != nil {
+			return nil, err
+		}
+		storeStatuses = append(storeStatuses, sql.StoreStatus{
+			StoreID:          ss.Desc.StoreID,
+			NodeID:           ss.NodeID,
+			Capacity:         ss.Desc.Capacity.Capacity,
+			Available:        ss.Desc.Capacity.Available,
+			RangeCount:       int64(ss.RangeCount),
+			LeaderRangeCount: int64(ss.LeaderRangeCount),
+			LiveBytes:        ss.Stats.LiveBytes,
+		})
+	}
+	return storeStatuses, nil
+}
+
+// Metrics implements sql.ClusterStatus.
+func (cs clusterStatus) Metrics() []sql.MetricValue {
+	data := cs.recorder.GetTimeSeriesData()
+	metrics := make([]sql.MetricValue, 0, len(data))
+	for _, d := range data {
+		for _, dp := range d.Datapoints {
+			metrics = append(metrics, sql.MetricValue{
+				Name:   d.Name,
+				Source: d.Source,
+				Value:  dp.Value,
+			})
+		}
+	}
+	return metrics
+}
@@ -17,12 +17,15 @@
 package server
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math"
+	"net"
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"strconv"
 	"strings"
@@ -34,6 +37,7 @@ import (
 	"github.com/cockroachdb/cockroach/base"
 	"github.com/cockroachdb/cockroach/gossip/resolver"
 	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/security"
 	"github.com/cockroachdb/cockroach/sql"
 	"github.com/cockroachdb/cockroach/storage"
 	"github.com/cockroachdb/cockroach/storage/engine"
@@ -55,6 +59,15 @@ const (
 	defaultScanMaxIdleTime          = 5 * time.Second
 	defaultMetricsFrequency         = 10 * time.Second
 	defaultTimeUntilStoreDead       = 5 * time.Minute
+
+	defaultDiagnosticsReportingInterval = 24 * time.Hour
+	defaultProfileSnapshots             = 10
+	defaultLoadSplitQPSThreshold        = 2500
+	defaultMergeCooldown                = 10 * time.Minute
+	defaultMetricsPushPrefix            = "cockroach"
+	defaultSlowRequestsRetained         = 20
+	defaultHLCUpperBoundInterval        = time.Second
+	defaultSendParallelism              = 8
 )
 
 // Context holds parameters needed to setup a server.
@@ -72,6 +85,45 @@ type Context struct {
 	// addressed upstream. See https://github.com/grpc/grpc-go/issues/586.
 	HTTPAddr string
 
+	// DebugAddr, if set, is the host:port to bind for the debug endpoints,
+	// which are then no longer served on HTTPAddr.
+	DebugAddr string
+
+	// SQLAddr, if set, is the host:port to bind for SQL clients, which are
+	// then no longer served on Addr. This allows exposing SQL on a public
+	// interface while keeping the intra-cluster RPCs on a private one.
+	SQLAddr string
+
+	// SQLSSLCA, SQLSSLCert and SQLSSLCertKey, if set, replace SSLCA, SSLCert
+	// and SSLCertKey respectively for SQL clients.
+	SQLSSLCA      string
+	SQLSSLCert    string
+	SQLSSLCertKey string
+
+	// HTTPSSLCA, HTTPSSLCert and HTTPSSLCertKey, if set, replace SSLCA,
+	// SSLCert and SSLCertKey respectively for HTTP requests.
+	HTTPSSLCA      string
+	HTTPSSLCert    string
+	HTTPSSLCertKey string
+
+	// ListenReusePort sets SO_REUSEPORT on the listeners of the node, so
+	// that a restarting node can bind its addresses before the old process
+	// released them.
+	// Environment Variable: COCKROACH_LISTEN_REUSEPORT
+	ListenReusePort bool
+
+	// ListenKeepAlive, if positive, enables TCP keepalives with the given
+	// period on the connections accepted by the node, so that half-open
+	// connections are detected.
+	// Environment Variable: COCKROACH_LISTEN_KEEPALIVE
+	ListenKeepAlive time.Duration
+
+	// ListenBacklog, if positive, is the maximum length of the queues of
+	// pending connections of the listeners of the node. Defaults to that of
+	// the system.
+	// Environment Variable: COCKROACH_LISTEN_BACKLOG
+	ListenBacklog int
+
 	// Stores is specified to enable durable key-value storage.
 	Stores StoreSpecList
 
@@ -101,6 +153,18 @@ type Context struct {
 	// NodeAttributes is the parsed representation of Attrs.
 	NodeAttributes roachpb.Attributes
 
+	// Diagnostics enables ("on") or disables ("off") the periodic reporting
+	// of anonymous diagnostics to DiagnosticsURL.
+	Diagnostics string
+
+	// DiagnosticsURL is the URL diagnostic reports are posted to.
+	DiagnosticsURL string
+
+	// ProfileDir is the directory in which profile snapshots captured
+	// through the admin API are stored. Defaults to a "profiles"
+	// subdirectory of the first on-disk store.
+	ProfileDir string
+
 	// GossipBootstrapResolvers is a list of gossip resolvers used
 	// to find bootstrap nodes for connecting to the gossip network.
 	GossipBootstrapResolvers []resolver.Resolver
@@ -118,6 +182,11 @@ type Context struct {
 	// Environment Variable: COCKROACH_MAX_OFFSET
 	MaxOffset time.Duration
 
+	// ClockOffset is added to the wall time read by this node, skewing its
+	// clock relative to the rest of the cluster.
+	// Environment Variable: COCKROACH_CLOCK_OFFSET
+	ClockOffset time.Duration
+
 	// MetricsFrequency determines the frequency at which the server should
 	// record internal metrics.
 	// Environment Variable: COCKROACH_METRICS_FREQUENCY
@@ -142,8 +211,193 @@ type Context struct {
 	// Environment Variable: COCKROACH_TIME_UNTIL_STORE_DEAD
 	TimeUntilStoreDead time.Duration
 
+	// DiagnosticsReportingInterval is the interval at which diagnostics are
+	// reported, if enabled.
+	// Environment Variable: COCKROACH_DIAGNOSTICS_REPORTING_INTERVAL
+	DiagnosticsReportingInterval time.Duration
+
+	// MaxConcurrentStoreRequests is the maximum number of batches each store
+	// evaluates concurrently. Excess batches are queued, with background
+	// work admitted after foreground traffic. Zero disables the limit.
+	// Environment Variable: COCKROACH_MAX_CONCURRENT_STORE_REQUESTS
+	MaxConcurrentStoreRequests int
+
+	// LoadSplitQPSThreshold is the sustained request rate above which a
+	// range is split to spread its load across nodes. Zero disables
+	// load-based splitting.
+	// Environment Variable: COCKROACH_LOAD_SPLIT_QPS_THRESHOLD
+	LoadSplitQPSThreshold int
+
+	// MergeQueueEnabled enables the automatic merging of adjacent ranges
+	// which are small and receive little load.
+	// Environment Variable: COCKROACH_MERGE_QUEUE_ENABLED
+	MergeQueueEnabled bool
+
+	// MergeCooldown is the minimum time after a split before the resulting
+	// ranges are considered for merging.
+	// Environment Variable: COCKROACH_MERGE_COOLDOWN
+	MergeCooldown time.Duration
+
+	// ClosedTimestampTarget is how far behind the present range leaders
+	// close timestamps to further writes, allowing followers to serve reads
+	// at or below the closed timestamp. Zero disables closed timestamps.
+	// Environment Variable: COCKROACH_CLOSED_TIMESTAMP_TARGET
+	ClosedTimestampTarget time.Duration
+
+	// ExportDir is the directory under which Export requests may write
+	// their SSTables on this node. Requests naming a directory are
+	// rejected if it is not set.
+	// Environment Variable: COCKROACH_EXPORT_DIR
+	ExportDir string
+
+	// FollowerReads routes non-transactional reads at explicit timestamps
+	// to the nearest replica rather than the range leader. The replica
+	// serves the read if the timestamp is closed and redirects to the
+	// leader otherwise.
+	// Environment Variable: COCKROACH_FOLLOWER_READS
+	FollowerReads bool
+
+	// SQLUserRateLimits configures per-user SQL rate limits, in the format
+	// accepted by sql.ParseUserRateLimits. Empty means unlimited.
+	// Environment Variable: COCKROACH_SQL_USER_RATE_LIMITS
+	SQLUserRateLimits string
+
+	// SQLEagerCommit commits explicit SQL transactions along with their
+	// last write when the client sends it along with the COMMIT, so that
+	// the transactions which write to a single range commit in one phase.
+	// Environment Variable: COCKROACH_SQL_EAGER_COMMIT
+	SQLEagerCommit bool
+
+	// PipelineTxnWrites acknowledges the blind writes of transactions
+	// before they are replicated, deferring their outcome to the next
+	// request of the transaction touching their keys or to its commit.
+	// Environment Variable: COCKROACH_PIPELINE_TXN_WRITES
+	PipelineTxnWrites bool
+
+	// MetricsGraphiteAddr is the address of a Graphite endpoint to which the
+	// metrics of the node are pushed every MetricsFrequency. Empty disables
+	// pushing to Graphite.
+	// Environment Variable: COCKROACH_METRICS_GRAPHITE_ADDR
+	MetricsGraphiteAddr string
+
+	// MetricsStatsDAddr is the address of a StatsD endpoint to which the
+	// metrics of the node are pushed every MetricsFrequency. Empty disables
+	// pushing to StatsD.
+	// Environment Variable: COCKROACH_METRICS_STATSD_ADDR
+	MetricsStatsDAddr string
+
+	// MetricsSink is the URL of a monitoring system to which the metrics of
+	// the node are pushed every MetricsFrequency, tagged with the IDs of the
+	// node and store, e.g. opentsdb://host:port. See metric.NewSinkFromURL
+	// for the supported systems. Empty disables pushing to a sink.
+	// Environment Variable: COCKROACH_METRICS_SINK
+	MetricsSink string
+
+	// MetricsPushPrefix is the prefix of the names of the metrics pushed to
+	// Graphite, StatsD and MetricsSink.
+	// Environment Variable: COCKROACH_METRICS_PUSH_PREFIX
+	MetricsPushPrefix string
+
+	// SlowRequestThreshold is the duration above which the traces of
+	// requests are retained for debugging. Since it requires tracing every
+	// request in full, zero disables it.
+	// Environment Variable: COCKROACH_SLOW_REQUEST_THRESHOLD
+	SlowRequestThreshold time.Duration
+
+	// SlowRequestsRetained is the number of traces of slow requests retained
+	// by the node. Only the traces of the slowest requests are kept.
+	// Environment Variable: COCKROACH_SLOW_REQUESTS_RETAINED
+	SlowRequestsRetained int
+
+	// HLCUpperBoundInterval is the interval at which an upper bound on the
+	// wall time of the node's clock is persisted to its stores. On restart,
+	// the node waits for its clock to pass the persisted bound before
+	// serving, so that a clock which regressed across the restart doesn't
+	// issue timestamps below those issued before. Zero disables it.
+	// Environment Variable: COCKROACH_HLC_UPPER_BOUND_INTERVAL
+	HLCUpperBoundInterval time.Duration
+
+	// RangeDescriptorCacheInterval is the interval at which the contents of
+	// the node's range descriptor cache are persisted to its stores. On
+	// restart, the cache is warmed up with the persisted descriptors, so
+	// that the first requests don't all look up their ranges in the meta
+	// ranges. Zero disables it.
+	// Environment Variable: COCKROACH_RANGE_DESCRIPTOR_CACHE_INTERVAL
+	RangeDescriptorCacheInterval time.Duration
+
+	// SendNextTimeout is the duration after which the requests of the node
+	// to a replica whose latency is unknown are speculatively sent to the
+	// next replica as well. High-latency deployments may need to raise it.
+	// Zero uses the DistSender's default.
+	// Environment Variable: COCKROACH_SEND_NEXT_TIMEOUT
+	SendNextTimeout time.Duration
+
+	// RPCTimeout is the maximum duration of an RPC sent by the node to a
+	// replica before it fails. Zero uses the DistSender's default.
+	// Environment Variable: COCKROACH_RPC_TIMEOUT
+	RPCTimeout time.Duration
+
+	// BatchDeadline is the maximum duration of a batch sent by the node,
+	// including its retries on descriptor churn or unavailable ranges.
+	// Zero disables the deadline.
+	// Environment Variable: COCKROACH_BATCH_DEADLINE
+	BatchDeadline time.Duration
+
+	// RangeRetryBudget is the maximum number of attempts to send a batch
+	// to a range. Zero disables the budget.
+	// Environment Variable: COCKROACH_RANGE_RETRY_BUDGET
+	RangeRetryBudget int
+
+	// SendParallelism is the maximum number of ranges queried concurrently
+	// by a batch spanning several ranges whose responses are all needed
+	// regardless of each other. One or less queries the ranges one after
+	// the other.
+	// Environment Variable: COCKROACH_SEND_PARALLELISM
+	SendParallelism int
+
+	// RangeLookupRateLimit is the maximum number of range lookups per
+	// second the node sends to each of the meta1 and meta2 ranges. Zero
+	// disables the limit.
+	// Environment Variable: COCKROACH_RANGE_LOOKUP_RATE_LIMIT
+	RangeLookupRateLimit int
+
+	// StartupCatchUpTimeout, if positive, makes a starting node serve only
+	// reads which don't need the leader lease, i.e. INCONSISTENT reads and
+	// follower reads, until its stores caught up with their ranges or the
+	// timeout passed. The node reports as not ready in the meantime.
+	// Environment Variable: COCKROACH_STARTUP_CATCH_UP_TIMEOUT
+	StartupCatchUpTimeout time.Duration
+
+	// ProfileSnapshots is the number of profile snapshots retained in
+	// ProfileDir. Older snapshots are removed as new ones are captured.
+	// Environment Variable: COCKROACH_PROFILE_SNAPSHOTS
+	ProfileSnapshots int
+
+	// EventLogArchive, if set, is the path of a file to which the events
+	// pruned from the event log are appended, one JSON object per line, when
+	// this node prunes it. The event log is pruned once its events are older
+	// than the server.event_log_ttl cluster setting.
+	// Environment Variable: COCKROACH_EVENT_LOG_ARCHIVE
+	EventLogArchive string
+
+	// CORSAllowedOrigins is a comma-separated list of the origins, e.g.
+	// https://dash.example.com, from which browsers may query the admin and
+	// status endpoints. "*" allows all origins; empty disallows cross-origin
+	// requests.
+	// Environment Variable: COCKROACH_CORS_ALLOWED_ORIGINS
+	CORSAllowedOrigins string
+
+	// CORSAllowedMethods is a comma-separated list of the HTTP methods
+	// allowed for cross-origin requests. Empty allows GET and POST.
+	// Environment Variable: COCKROACH_CORS_ALLOWED_METHODS
+	CORSAllowedMethods string
+
 	// TestingMocker is used for internal test mocking only.
 	TestingMocker TestingMocker
+
+	// configSources maps the names of fields to the sources of their values,
+	// as reported by the config endpoint. See SetConfigSource.
+	configSources map[string]string
 }
 
 // TestingMocker is a struct containing facilities for mocking
@@ -151,6 +405,15 @@ type Context struct {
 type TestingMocker struct {
 	StoreTestingMocker    storage.StoreTestingMocker
 	ExecutorTestingMocker sql.ExecutorTestingMocker
+	// ClockSource, if set, replaces the wall clock as the physical clock of
+	// the server's hybrid logical clock.
+	ClockSource func() int64
+	// RPCDialer, if set, is used to establish the server's outgoing RPC
+	// connections. See rpc.Context.Dialer.
+	RPCDialer func(addr string, timeout time.Duration) (net.Conn, error)
+	// VerifyLocalCalls, if set, checks that the server does not modify the
+	// requests it serves to its own node. See rpc.Context.VerifyLocalCalls.
+	VerifyLocalCalls bool
 }
 
 // GetTotalMemory returns either the total system memory or if possible the
@@ -222,6 +485,16 @@ func (ctx *Context) InitDefaults() {
 	ctx.ConsistencyCheckInterval = defaultConsistencyCheckInterval
 	ctx.MetricsFrequency = defaultMetricsFrequency
 	ctx.TimeUntilStoreDead = defaultTimeUntilStoreDead
+	ctx.Diagnostics = diagnosticsOff
+	ctx.DiagnosticsReportingInterval = defaultDiagnosticsReportingInterval
+	ctx.ProfileSnapshots = defaultProfileSnapshots
+	ctx.LoadSplitQPSThreshold = defaultLoadSplitQPSThreshold
+	ctx.MergeQueueEnabled = true
+	ctx.MergeCooldown = defaultMergeCooldown
+	ctx.MetricsPushPrefix = defaultMetricsPushPrefix
+	ctx.SlowRequestsRetained = defaultSlowRequestsRetained
+	ctx.HLCUpperBoundInterval = defaultHLCUpperBoundInterval
+	ctx.SendParallelism = defaultSendParallelism
 	ctx.Stores.Specs = append(ctx.Stores.Specs, StoreSpec{Path: "cockroach-data"})
 }
 
@@ -269,14 +542,36 @@ func (ctx *Context) InitStores(stopper *stop.Stopper) error {
 	return nil
 }
 
-// InitNode parses node attributes and initializes the gossip bootstrap
-// resolvers.
+// InitNode reads the environment variables, parses node attributes and
+// initializes the gossip bootstrap resolvers.
 func (ctx *Context) InitNode() error {
-	ctx.readEnvironmentVariables()
+	if err := ctx.readEnvironmentVariables(); err != nil {
+		return err
+	}
 
 	// Initialize attributes.
 	ctx.NodeAttributes = parseAttributes(ctx.Attrs)
 
+	switch ctx.Diagnostics {
+	case diagnosticsOff:
+	case diagnosticsOn:
+		if ctx.DiagnosticsURL == "" {
+			return util.Errorf("diagnostics reporting requires a diagnostics URL")
+		}
+	default:
+		return util.Errorf("invalid diagnostics setting %q: must be %q or %q",
+			ctx.Diagnostics, diagnosticsOn, diagnosticsOff)
+	}
+
+	if ctx.ProfileDir == "" {
+		for _, spec := range ctx.Stores.Specs {
+			if !spec.InMemory {
+				ctx.ProfileDir = filepath.Join(spec.Path, "profiles")
+				break
+			}
+		}
+	}
+
 	// Get the gossip bootstrap resolvers.
 	resolvers, err := ctx.parseGossipBootstrapResolvers()
 	if err != nil {
@@ -289,40 +584,332 @@ func (ctx *Context) InitNode() error {
 	return nil
 }
 
-// parseDurationEnv parses a time.Duration from an environment variable. This
-// function assumes that the default value is already present in duration.
-func parseDurationEnv(env, internalName string, duration *time.Duration) {
-	if valueString := os.Getenv(env); len(valueString) != 0 {
-		if value, err := time.ParseDuration(valueString); err != nil {
-			log.Errorf("could not parse environment variable %s=%s, setting to default of %s, error: %s",
-				env, valueString, duration, err)
+// envParser reads the values of environment variables into the fields of a
+// context. Values which fail to parse leave the fields at their defaults and
+// are collected, so that they can be reported together once all variables
+// have been read.
+type envParser struct {
+	ctx  *Context
+	errs []string
+}
+
+// set records that the field value was set from the environment variable
+// env.
+func (p *envParser) set(env string, value interface{}) {
+	p.ctx.SetConfigSource(value, "env "+env)
+}
+
+func (p *envParser) lookup(env string) (string, bool) {
+	valueString := os.Getenv(env)
+	return valueString, len(valueString) != 0
+}
+
+func (p *envParser) invalid(env, valueString string, err error) {
+	p.errs = append(p.errs, fmt.Sprintf("%s=%s: %s", env, valueString, err))
+}
+
+// errNegative is reported for negative values of environment variables which
+// hold durations, sizes or counts.
+var errNegative = errors.New("must not be negative")
+
+// parseDuration parses a non-negative time.Duration from an environment
+// variable. This function assumes that the default value is already present
+// in value.
+func (p *envParser) parseDuration(env, internalName string, value *time.Duration) {
+	if valueString, ok := p.lookup(env); ok {
+		if v, err := time.ParseDuration(valueString); err != nil {
+			p.invalid(env, valueString, err)
+		} else if v < 0 {
+			p.invalid(env, valueString, errNegative)
+		} else {
+			*value = v
+			p.set(env, value)
+			log.Infof("\"%s\" set to %s based on %s environment variable", internalName, *value, env)
+		}
+	}
+}
+
+// parseSignedDuration parses a time.Duration, which may be negative, from an
+// environment variable. This function assumes that the default value is
+// already present in value.
+func (p *envParser) parseSignedDuration(env, internalName string, value *time.Duration) {
+	if valueString, ok := p.lookup(env); ok {
+		if v, err := time.ParseDuration(valueString); err != nil {
+			p.invalid(env, valueString, err)
+		} else {
+			*value = v
+			p.set(env, value)
+			log.Infof("\"%s\" set to %s based on %s environment variable", internalName, *value, env)
+		}
+	}
+}
+
+// parseInt parses a non-negative int from an environment variable. This
+// function assumes that the default value is already present in value.
+func (p *envParser) parseInt(env, internalName string, value *int) {
+	if valueString, ok := p.lookup(env); ok {
+		if v, err := strconv.Atoi(valueString); err != nil {
+			p.invalid(env, valueString, err)
+		} else if v < 0 {
+			p.invalid(env, valueString, errNegative)
+		} else {
+			*value = v
+			p.set(env, value)
+			log.Infof("\"%s\" set to %d based on %s environment variable", internalName, *value, env)
+		}
+	}
+}
+
+// parseBool parses a bool from an environment variable. This function assumes
+// that the default value is already present in value.
+func (p *envParser) parseBool(env, internalName string, value *bool) {
+	if valueString, ok := p.lookup(env); ok {
+		if v, err := strconv.ParseBool(valueString); err != nil {
+			p.invalid(env, valueString, err)
 		} else {
-			*duration = value
-			log.Infof("\"%s\" set to %s based on %s environment variable", internalName, *duration, env)
+			*value = v
+			p.set(env, value)
+			log.Infof("\"%s\" set to %t based on %s environment variable", internalName, *value, env)
 		}
 	}
 }
 
+// parseString parses a string from an environment variable. This function assumes
+// that the default value is already present in value.
+func (p *envParser) parseString(env, internalName string, value *string) {
+	if valueString, ok := p.lookup(env); ok {
+		*value = valueString
+		p.set(env, value)
+		log.Infof("\"%s\" set to %q based on %s environment variable", internalName, *value, env)
+	}
+}
+
+// err returns an error listing all the environment variables which failed to
+// parse or held invalid values, or nil if there were none.
+func (p *envParser) err() error {
+	if len(p.errs) == 0 {
+		return nil
+	}
+	return util.Errorf("invalid environment variables:\n  %s", strings.Join(p.errs, "\n  "))
+}
+
 // readEnvironmentVariables populates all context values that are environment
 // variable based. Note that this only happens when initializing a node and not
-// when NewContext is called.
-func (ctx *Context) readEnvironmentVariables() {
-	// cockroach-linearizable
-	if linearizableString := os.Getenv("COCKROACH_LINEARIZABLE"); len(linearizableString) != 0 {
-		if linearizable, err := strconv.ParseBool(linearizableString); err != nil {
-			log.Errorf("could not parse environment variable COCKROACH_LINEARIZABLE=%s, setting to default of %t, error: %s",
-				linearizableString, ctx.Linearizable, err)
-		} else {
-			ctx.Linearizable = linearizable
-			log.Infof("\"linearizable\" set to %t based on COCKROACH_LINEARIZABLE environment variable", ctx.Linearizable)
+// when NewContext is called. Durations, sizes and counts must not be negative.
+// All environment variables are read even if some are invalid; the returned
+// error lists those which were.
+func (ctx *Context) readEnvironmentVariables() error {
+	p := envParser{ctx: ctx}
+	p.parseBool("COCKROACH_LINEARIZABLE", "linearizable", &ctx.Linearizable)
+	p.parseDuration("COCKROACH_MAX_OFFSET", "max offset", &ctx.MaxOffset)
+	p.parseSignedDuration("COCKROACH_CLOCK_OFFSET", "clock offset", &ctx.ClockOffset)
+	p.parseDuration("COCKROACH_METRICS_FREQUENCY", "metrics frequency", &ctx.MetricsFrequency)
+	p.parseDuration("COCKROACH_SCAN_INTERVAL", "scan interval", &ctx.ScanInterval)
+	p.parseDuration("COCKROACH_SCAN_MAX_IDLE_TIME", "scan max idle time", &ctx.ScanMaxIdleTime)
+	p.parseDuration("COCKROACH_TIME_UNTIL_STORE_DEAD", "time until store dead", &ctx.TimeUntilStoreDead)
+	p.parseDuration("COCKROACH_DIAGNOSTICS_REPORTING_INTERVAL", "diagnostics reporting interval",
+		&ctx.DiagnosticsReportingInterval)
+	p.parseInt("COCKROACH_MAX_CONCURRENT_STORE_REQUESTS", "max concurrent store requests",
+		&ctx.MaxConcurrentStoreRequests)
+	p.parseInt("COCKROACH_PROFILE_SNAPSHOTS", "profile snapshots", &ctx.ProfileSnapshots)
+	p.parseString("COCKROACH_EVENT_LOG_ARCHIVE", "event log archive", &ctx.EventLogArchive)
+	p.parseInt("COCKROACH_LOAD_SPLIT_QPS_THRESHOLD", "load split qps threshold",
+		&ctx.LoadSplitQPSThreshold)
+	p.parseBool("COCKROACH_MERGE_QUEUE_ENABLED", "merge queue enabled", &ctx.MergeQueueEnabled)
+	p.parseDuration("COCKROACH_MERGE_COOLDOWN", "merge cooldown", &ctx.MergeCooldown)
+	p.parseDuration("COCKROACH_CLOSED_TIMESTAMP_TARGET", "closed timestamp target",
+		&ctx.ClosedTimestampTarget)
+	p.parseString("COCKROACH_EXPORT_DIR", "export dir", &ctx.ExportDir)
+	p.parseBool("COCKROACH_FOLLOWER_READS", "follower reads", &ctx.FollowerReads)
+	p.parseString("COCKROACH_METRICS_GRAPHITE_ADDR", "metrics graphite addr", &ctx.MetricsGraphiteAddr)
+	p.parseString("COCKROACH_METRICS_STATSD_ADDR", "metrics statsd addr", &ctx.MetricsStatsDAddr)
+	p.parseString("COCKROACH_METRICS_SINK", "metrics sink", &ctx.MetricsSink)
+	p.parseString("COCKROACH_METRICS_PUSH_PREFIX", "metrics push prefix", &ctx.MetricsPushPrefix)
+	p.parseDuration("COCKROACH_SLOW_REQUEST_THRESHOLD", "slow request threshold",
+		&ctx.SlowRequestThreshold)
+	p.parseInt("COCKROACH_SLOW_REQUESTS_RETAINED", "slow requests retained", &ctx.SlowRequestsRetained)
+	p.parseDuration("COCKROACH_HLC_UPPER_BOUND_INTERVAL", "hlc upper bound interval",
+		&ctx.HLCUpperBoundInterval)
+	p.parseDuration("COCKROACH_RANGE_DESCRIPTOR_CACHE_INTERVAL", "range descriptor cache interval",
+		&ctx.RangeDescriptorCacheInterval)
+	p.parseString("COCKROACH_SQL_USER_RATE_LIMITS", "sql user rate limits", &ctx.SQLUserRateLimits)
+	p.parseBool("COCKROACH_SQL_EAGER_COMMIT", "sql eager commit", &ctx.SQLEagerCommit)
+	p.parseBool("COCKROACH_PIPELINE_TXN_WRITES", "pipeline txn writes", &ctx.PipelineTxnWrites)
+	p.parseDuration("COCKROACH_SEND_NEXT_TIMEOUT", "send next timeout", &ctx.SendNextTimeout)
+	p.parseDuration("COCKROACH_RPC_TIMEOUT", "rpc timeout", &ctx.RPCTimeout)
+	p.parseDuration("COCKROACH_BATCH_DEADLINE", "batch deadline", &ctx.BatchDeadline)
+	p.parseInt("COCKROACH_RANGE_RETRY_BUDGET", "range retry budget", &ctx.RangeRetryBudget)
+	p.parseInt("COCKROACH_SEND_PARALLELISM", "send parallelism", &ctx.SendParallelism)
+	p.parseInt("COCKROACH_RANGE_LOOKUP_RATE_LIMIT", "range lookup rate limit",
+		&ctx.RangeLookupRateLimit)
+	p.parseString("COCKROACH_CORS_ALLOWED_ORIGINS", "cors allowed origins", &ctx.CORSAllowedOrigins)
+	p.parseString("COCKROACH_CORS_ALLOWED_METHODS", "cors allowed methods", &ctx.CORSAllowedMethods)
+	p.parseBool("COCKROACH_LISTEN_REUSEPORT", "listen reuseport", &ctx.ListenReusePort)
+	p.parseDuration("COCKROACH_LISTEN_KEEPALIVE", "listen keepalive", &ctx.ListenKeepAlive)
+	p.parseInt("COCKROACH_LISTEN_BACKLOG", "listen backlog", &ctx.ListenBacklog)
+	p.parseDuration("COCKROACH_STARTUP_CATCH_UP_TIMEOUT", "startup catch up timeout",
+		&ctx.StartupCatchUpTimeout)
+	return p.err()
+}
+
+// ConfigValue is the resolved value of a field of the context and its source,
+// e.g. "default", "flag --cache" or "env COCKROACH_MAX_OFFSET".
+type ConfigValue struct {
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// redactedConfigFields are the fields of the context whose values may embed
+// credentials, e.g. in the user info of a URL, and are therefore not
+// reported by the config endpoint.
+var redactedConfigFields = map[string]bool{
+	"DiagnosticsURL": true,
+	"MetricsSink":    true,
+}
+
+// visitConfigFields calls fn with the name and value of each exported field
+// of the context, including those of the embedded base context.
+func (ctx *Context) visitConfigFields(fn func(name string, v reflect.Value)) {
+	var visit func(v reflect.Value)
+	visit = func(v reflect.Value) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.Anonymous && f.Type.Kind() == reflect.Struct {
+				visit(v.Field(i))
+				continue
+			}
+			if f.PkgPath != "" {
+				continue
+			}
+			fn(f.Name, v.Field(i))
+		}
+	}
+	visit(reflect.ValueOf(ctx).Elem())
+}
+
+// SetConfigSource records the source of the value of a field of the context,
+// e.g. the command line flag or environment variable which set it, to be
+// reported by the config endpoint. field must point to the field; pointers
+// to anything else are ignored. Fields without a recorded source are
+// reported as defaults.
+func (ctx *Context) SetConfigSource(field interface{}, source string) {
+	ptr := reflect.ValueOf(field)
+	if ptr.Kind() != reflect.Ptr {
+		return
+	}
+	ctx.visitConfigFields(func(name string, v reflect.Value) {
+		if v.UnsafeAddr() != ptr.Pointer() {
+			return
+		}
+		if ctx.configSources == nil {
+			ctx.configSources = map[string]string{}
+		}
+		ctx.configSources[name] = source
+	})
+}
+
+// EffectiveConfig returns the resolved values of the fields of the context
+// and their sources, keyed by field name. Fields which don't hold plain
+// values, such as the engines and the testing mocker, are omitted and the
+// values of fields which may embed credentials are redacted.
+func (ctx *Context) EffectiveConfig() map[string]ConfigValue {
+	config := map[string]ConfigValue{}
+	ctx.visitConfigFields(func(name string, v reflect.Value) {
+		var value string
+		switch v.Kind() {
+		case reflect.Bool, reflect.String,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			value = fmt.Sprint(v.Interface())
+		default:
+			s, ok := v.Addr().Interface().(fmt.Stringer)
+			if !ok {
+				return
+			}
+			value = s.String()
+		}
+		if redactedConfigFields[name] && value != "" {
+			value = "<redacted>"
+		}
+		source, ok := ctx.configSources[name]
+		if !ok {
+			source = "default"
 		}
+		config[name] = ConfigValue{Value: value, Source: source}
+	})
+	return config
+}
+
+// listenerContext returns a base context which replaces the certificates of
+// the context with those given, where set. It returns the context's own base
+// context if none are set.
+func (ctx *Context) listenerContext(ca, cert, key string) *base.Context {
+	if ca == "" && cert == "" && key == "" {
+		return &ctx.Context
+	}
+	orDefault := func(s, def string) string {
+		if s == "" {
+			return def
+		}
+		return s
+	}
+	return &base.Context{
+		Insecure:   ctx.Insecure,
+		User:       ctx.User,
+		SSLCA:      orDefault(ca, ctx.SSLCA),
+		SSLCert:    orDefault(cert, ctx.SSLCert),
+		SSLCertKey: orDefault(key, ctx.SSLCertKey),
+	}
+}
+
+// SQLContext returns the base context used to serve SQL clients.
+func (ctx *Context) SQLContext() *base.Context {
+	return ctx.listenerContext(ctx.SQLSSLCA, ctx.SQLSSLCert, ctx.SQLSSLCertKey)
+}
+
+// HTTPContext returns the base context used to serve HTTP requests.
+func (ctx *Context) HTTPContext() *base.Context {
+	return ctx.listenerContext(ctx.HTTPSSLCA, ctx.HTTPSSLCert, ctx.HTTPSSLCertKey)
+}
+
+// certificates describes the certificates loaded by the node: those of the
+// CA and of the node, which the node uses both as a server and as a client,
+// and, if set, those replacing them for SQL clients and HTTP requests. It
+// returns nil in insecure mode.
+func (ctx *Context) certificates() ([]security.CertificateInfo, error) {
+	if ctx.Insecure {
+		return nil, nil
 	}
+	var infos []security.CertificateInfo
+	for _, c := range []struct{ usage, path string }{
+		{"ca", ctx.SSLCA},
+		{"node", ctx.SSLCert},
+		{"sql-ca", ctx.SQLSSLCA},
+		{"sql", ctx.SQLSSLCert},
+		{"http-ca", ctx.HTTPSSLCA},
+		{"http", ctx.HTTPSSLCert},
+	} {
+		if c.path == "" {
+			continue
+		}
+		certs, err := security.LoadCertificateInfo(c.usage, c.path)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, certs...)
+	}
+	return infos, nil
+}
 
-	parseDurationEnv("COCKROACH_MAX_OFFSET", "max offset", &ctx.MaxOffset)
-	parseDurationEnv("COCKROACH_METRICS_FREQUENCY", "metrics frequency", &ctx.MetricsFrequency)
-	parseDurationEnv("COCKROACH_SCAN_INTERVAL", "scan interval", &ctx.ScanInterval)
-	parseDurationEnv("COCKROACH_SCAN_MAX_IDLE_TIME", "scan max idle time", &ctx.ScanMaxIdleTime)
-	parseDurationEnv("COCKROACH_TIME_UNTIL_STORE_DEAD", "time until store dead", &ctx.TimeUntilStoreDead)
+// listen announces on the address with the socket options of the context.
+func (ctx *Context) listen(addr string) (net.Listener, error) {
+	return util.Listen(addr, util.ListenOptions{
+		ReusePort: ctx.ListenReusePort,
+		KeepAlive: ctx.ListenKeepAlive,
+		Backlog:   ctx.ListenBacklog,
+	})
 }
 
 // AdminURL returns the URL for the admin UI.
@@ -346,15 +933,23 @@ func (ctx *Context) PGURL(user string) *url.URL {
 	if ctx.Insecure {
 		options.Add("sslmode", "disable")
 	} else {
+		sslCA := ctx.SSLCA
+		if ctx.SQLSSLCA != "" {
+			sslCA = ctx.SQLSSLCA
+		}
 		options.Add("sslmode", "verify-full")
 		options.Add("sslcert", absPath(ctx.SSLCert))
 		options.Add("sslkey", absPath(ctx.SSLCertKey))
-		options.Add("sslrootcert", absPath(ctx.SSLCA))
+		options.Add("sslrootcert", absPath(sslCA))
+	}
+	host := ctx.Addr
+	if ctx.SQLAddr != "" {
+		host = ctx.SQLAddr
 	}
 	return &url.URL{
 		Scheme:   "postgresql",
 		User:     url.User(user),
-		Host:     ctx.Addr,
+		Host:     host,
 		RawQuery: options.Encode(),
 	}
 }
@@ -19,6 +19,7 @@ package server
 import (
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -88,6 +89,9 @@ func TestReadEnvironmentVariables(t *testing.T) {
 		if err := os.Unsetenv("COCKROACH_MAX_OFFSET"); err != nil {
 			t.Fatal(err)
 		}
+		if err := os.Unsetenv("COCKROACH_CLOCK_OFFSET"); err != nil {
+			t.Fatal(err)
+		}
 		if err := os.Unsetenv("COCKROACH_METRICS_FREQUENCY"); err != nil {
 			t.Fatal(err)
 		}
@@ -100,6 +104,45 @@ func TestReadEnvironmentVariables(t *testing.T) {
 		if err := os.Unsetenv("COCKROACH_TIME_UNTIL_STORE_DEAD"); err != nil {
 			t.Fatal(err)
 		}
+		if err := os.Unsetenv("COCKROACH_DIAGNOSTICS_REPORTING_INTERVAL"); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Unsetenv("COCKROACH_PROFILE_SNAPSHOTS"); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Unsetenv("COCKROACH_LOAD_SPLIT_QPS_THRESHOLD"); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Unsetenv("COCKROACH_MERGE_QUEUE_ENABLED"); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Unsetenv("COCKROACH_MERGE_COOLDOWN"); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Unsetenv("COCKROACH_CLOSED_TIMESTAMP_TARGET"); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Unsetenv("COCKROACH_FOLLOWER_READS"); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Unsetenv("COCKROACH_SEND_NEXT_TIMEOUT"); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Unsetenv("COCKROACH_RPC_TIMEOUT"); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Unsetenv("COCKROACH_BATCH_DEADLINE"); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Unsetenv("COCKROACH_RANGE_RETRY_BUDGET"); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Unsetenv("COCKROACH_SEND_PARALLELISM"); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Unsetenv("COCKROACH_RANGE_LOOKUP_RATE_LIMIT"); err != nil {
+			t.Fatal(err)
+		}
 	}
 	defer resetEnvVar()
 
@@ -108,7 +151,9 @@ func TestReadEnvironmentVariables(t *testing.T) {
 	ctxExpected := NewContext()
 
 	resetEnvVar()
-	ctx.readEnvironmentVariables()
+	if err := ctx.readEnvironmentVariables(); err != nil {
+		t.Fatal(err)
+	}
 	if !reflect.DeepEqual(ctx, ctxExpected) {
 		t.Fatalf("actual context does not match expected:\nactual:%+v\nexpected:%+v", ctx, ctxExpected)
 	}
@@ -123,6 +168,10 @@ func TestReadEnvironmentVariables(t *testing.T) {
 		t.Fatal(err)
 	}
 	ctxExpected.MaxOffset = time.Second
+	if err := os.Setenv("COCKROACH_CLOCK_OFFSET", "-200ms"); err != nil {
+		t.Fatal(err)
+	}
+	ctxExpected.ClockOffset = -200 * time.Millisecond
 	if err := os.Setenv("COCKROACH_METRICS_FREQUENCY", "1h10m"); err != nil {
 		t.Fatal(err)
 	}
@@ -139,14 +188,77 @@ func TestReadEnvironmentVariables(t *testing.T) {
 		t.Fatal(err)
 	}
 	ctxExpected.TimeUntilStoreDead = time.Millisecond * 10
+	if err := os.Setenv("COCKROACH_DIAGNOSTICS_REPORTING_INTERVAL", "1h"); err != nil {
+		t.Fatal(err)
+	}
+	ctxExpected.DiagnosticsReportingInterval = time.Hour
+	if err := os.Setenv("COCKROACH_PROFILE_SNAPSHOTS", "3"); err != nil {
+		t.Fatal(err)
+	}
+	ctxExpected.ProfileSnapshots = 3
+	if err := os.Setenv("COCKROACH_LOAD_SPLIT_QPS_THRESHOLD", "0"); err != nil {
+		t.Fatal(err)
+	}
+	ctxExpected.LoadSplitQPSThreshold = 0
+	if err := os.Setenv("COCKROACH_MERGE_QUEUE_ENABLED", "false"); err != nil {
+		t.Fatal(err)
+	}
+	ctxExpected.MergeQueueEnabled = false
+	if err := os.Setenv("COCKROACH_MERGE_COOLDOWN", "1m"); err != nil {
+		t.Fatal(err)
+	}
+	ctxExpected.MergeCooldown = time.Minute
+	if err := os.Setenv("COCKROACH_CLOSED_TIMESTAMP_TARGET", "5s"); err != nil {
+		t.Fatal(err)
+	}
+	ctxExpected.ClosedTimestampTarget = 5 * time.Second
+	if err := os.Setenv("COCKROACH_FOLLOWER_READS", "true"); err != nil {
+		t.Fatal(err)
+	}
+	ctxExpected.FollowerReads = true
+	if err := os.Setenv("COCKROACH_SEND_NEXT_TIMEOUT", "30s"); err != nil {
+		t.Fatal(err)
+	}
+	ctxExpected.SendNextTimeout = 30 * time.Second
+	if err := os.Setenv("COCKROACH_RPC_TIMEOUT", "1m"); err != nil {
+		t.Fatal(err)
+	}
+	ctxExpected.RPCTimeout = time.Minute
+	if err := os.Setenv("COCKROACH_BATCH_DEADLINE", "2m"); err != nil {
+		t.Fatal(err)
+	}
+	ctxExpected.BatchDeadline = 2 * time.Minute
+	if err := os.Setenv("COCKROACH_RANGE_RETRY_BUDGET", "20"); err != nil {
+		t.Fatal(err)
+	}
+	ctxExpected.RangeRetryBudget = 20
+	if err := os.Setenv("COCKROACH_SEND_PARALLELISM", "4"); err != nil {
+		t.Fatal(err)
+	}
+	ctxExpected.SendParallelism = 4
+	if err := os.Setenv("COCKROACH_RANGE_LOOKUP_RATE_LIMIT", "50"); err != nil {
+		t.Fatal(err)
+	}
+	ctxExpected.RangeLookupRateLimit = 50
 
-	ctx.readEnvironmentVariables()
+	if err := ctx.readEnvironmentVariables(); err != nil {
+		t.Fatal(err)
+	}
+	// The variables are recorded as the sources of the values they set.
+	config := ctx.EffectiveConfig()
+	if a, e := config["MaxOffset"], (ConfigValue{Value: "1s", Source: "env COCKROACH_MAX_OFFSET"}); a != e {
+		t.Errorf("expected %+v, got %+v", e, a)
+	}
+	if a, e := config["CacheSize"].Source, "default"; a != e {
+		t.Errorf("expected source %q, got %q", e, a)
+	}
+	ctx.configSources = nil
 	if !reflect.DeepEqual(ctx, ctxExpected) {
 		t.Fatalf("actual context does not match expected:\nactual:%+v\nexpected:%+v", ctx, ctxExpected)
 	}
 
 	// Set all the environment variables to invalid values and test that the
-	// defaults are still set.
+	// defaults are still set and that all of the variables are reported.
 	ctx = NewContext()
 	ctxExpected = NewContext()
 
@@ -156,6 +268,9 @@ func TestReadEnvironmentVariables(t *testing.T) {
 	if err := os.Setenv("COCKROACH_MAX_OFFSET", "abcd"); err != nil {
 		t.Fatal(err)
 	}
+	if err := os.Setenv("COCKROACH_CLOCK_OFFSET", "abcd"); err != nil {
+		t.Fatal(err)
+	}
 	if err := os.Setenv("COCKROACH_METRICS_FREQUENCY", "abcd"); err != nil {
 		t.Fatal(err)
 	}
@@ -168,8 +283,84 @@ func TestReadEnvironmentVariables(t *testing.T) {
 	if err := os.Setenv("COCKROACH_TIME_UNTIL_STORE_DEAD", "abcd"); err != nil {
 		t.Fatal(err)
 	}
+	if err := os.Setenv("COCKROACH_DIAGNOSTICS_REPORTING_INTERVAL", "abcd"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Setenv("COCKROACH_PROFILE_SNAPSHOTS", "abcd"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Setenv("COCKROACH_LOAD_SPLIT_QPS_THRESHOLD", "abcd"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Setenv("COCKROACH_MERGE_QUEUE_ENABLED", "abcd"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Setenv("COCKROACH_MERGE_COOLDOWN", "abcd"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Setenv("COCKROACH_CLOSED_TIMESTAMP_TARGET", "abcd"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Setenv("COCKROACH_FOLLOWER_READS", "abcd"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Setenv("COCKROACH_SEND_NEXT_TIMEOUT", "abcd"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Setenv("COCKROACH_RPC_TIMEOUT", "abcd"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Setenv("COCKROACH_BATCH_DEADLINE", "abcd"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Setenv("COCKROACH_RANGE_RETRY_BUDGET", "abcd"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Setenv("COCKROACH_SEND_PARALLELISM", "abcd"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Setenv("COCKROACH_RANGE_LOOKUP_RATE_LIMIT", "abcd"); err != nil {
+		t.Fatal(err)
+	}
+
+	err := ctx.readEnvironmentVariables()
+	if err == nil {
+		t.Fatal("expected an error for the invalid environment variables")
+	}
+	if n := strings.Count(err.Error(), "=abcd"); n != 20 {
+		t.Errorf("expected 20 invalid environment variables to be reported, got %d: %s", n, err)
+	}
+	if !reflect.DeepEqual(ctx, ctxExpected) {
+		t.Fatalf("actual context does not match expected:\nactual:%+v\nexpected:%+v", ctx, ctxExpected)
+	}
 
-	ctx.readEnvironmentVariables()
+	// Set the durations and counts to negative values and test that they are
+	// rejected too, except for the clock offset.
+	resetEnvVar()
+	ctx = NewContext()
+	ctxExpected = NewContext()
+	for env, value := range map[string]string{
+		"COCKROACH_MAX_OFFSET":         "-1s",
+		"COCKROACH_SCAN_INTERVAL":      "-1h",
+		"COCKROACH_RANGE_RETRY_BUDGET": "-1",
+		"COCKROACH_SEND_PARALLELISM":   "-1",
+	} {
+		if err := os.Setenv(env, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Setenv("COCKROACH_CLOCK_OFFSET", "-200ms"); err != nil {
+		t.Fatal(err)
+	}
+	ctxExpected.ClockOffset = -200 * time.Millisecond
+	err = ctx.readEnvironmentVariables()
+	if err == nil {
+		t.Fatal("expected an error for the negative environment variables")
+	}
+	if n := strings.Count(err.Error(), errNegative.Error()); n != 4 {
+		t.Errorf("expected 4 negative environment variables to be reported, got %d: %s", n, err)
+	}
+	ctx.configSources = nil
 	if !reflect.DeepEqual(ctx, ctxExpected) {
 		t.Fatalf("actual context does not match expected:\nactual:%+v\nexpected:%+v", ctx, ctxExpected)
 	}
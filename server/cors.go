@@ -0,0 +1,133 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/ts"
+	"github.com/cockroachdb/cockroach/util"
+)
+
+const (
+	// defaultCORSAllowedMethods are the methods allowed for cross-origin
+	// requests unless configured otherwise.
+	defaultCORSAllowedMethods = "GET, POST"
+	// corsMaxAge is the duration for which browsers may cache the result of
+	// a preflight request.
+	corsMaxAge = 10 * time.Minute
+)
+
+// corsAllowedHeaders are the request headers cross-origin requests may set.
+var corsAllowedHeaders = strings.Join([]string{
+	util.AcceptHeader,
+	util.ContentTypeHeader,
+	util.IdempotencyKeyHeader,
+}, ", ")
+
+// corsPrefixes are the prefixes of the paths of the endpoints which are
+// accessible to cross-origin requests.
+var corsPrefixes = []string{adminEndpoint, statusPrefix, healthEndpoint, ts.URLPrefix}
+
+// A corsPolicy allows browsers to query the admin and status endpoints from
+// pages hosted on other origins, e.g. custom dashboards. See
+// https://www.w3.org/TR/cors/.
+type corsPolicy struct {
+	allowAll bool
+	origins  map[string]bool
+	methods  map[string]bool
+	// allowMethods is the value of the Access-Control-Allow-Methods header.
+	allowMethods string
+}
+
+// newCORSPolicy returns the policy allowing cross-origin requests from the
+// given comma-separated origins, e.g. "https://dash.example.com", using the
+// given comma-separated methods. An origin of "*" allows all origins. It
+// returns nil if no origins are allowed.
+func newCORSPolicy(origins, methods string) *corsPolicy {
+	p := &corsPolicy{origins: map[string]bool{}, methods: map[string]bool{}}
+	for _, o := range strings.Split(origins, ",") {
+		o = strings.TrimSpace(o)
+		switch o {
+		case "":
+		case "*":
+			p.allowAll = true
+		default:
+			p.origins[strings.ToLower(strings.TrimSuffix(o, "/"))] = true
+		}
+	}
+	if !p.allowAll && len(p.origins) == 0 {
+		return nil
+	}
+	if strings.TrimSpace(methods) == "" {
+		methods = defaultCORSAllowedMethods
+	}
+	var ms []string
+	for _, m := range strings.Split(methods, ",") {
+		if m = strings.ToUpper(strings.TrimSpace(m)); m != "" {
+			ms = append(ms, m)
+			p.methods[m] = true
+		}
+	}
+	p.allowMethods = strings.Join(ms, ", ")
+	return p
+}
+
+// allowed returns whether cross-origin requests from the origin to the path
+// are allowed.
+func (p *corsPolicy) allowed(origin, path string) bool {
+	if origin == "" || (!p.allowAll && !p.origins[strings.ToLower(origin)]) {
+		return false
+	}
+	for _, prefix := range corsPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// serve sets the CORS headers of the response to an allowed cross-origin
+// request. It returns true if it answered the request itself, which is the
+// case for preflight requests and for requests using a method which isn't
+// allowed.
+func (p *corsPolicy) serve(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if p == nil || !p.allowed(origin, r.URL.Path) {
+		return false
+	}
+	h := w.Header()
+	h.Set("Access-Control-Allow-Origin", origin)
+	h.Add("Vary", "Origin")
+	if r.Method != "OPTIONS" || r.Header.Get("Access-Control-Request-Method") == "" {
+		// Simple requests, e.g. POSTs of forms, are sent by browsers without
+		// a preflight request, so the method has to be checked here.
+		if !p.methods[r.Method] {
+			http.Error(w, "method "+r.Method+" not allowed for cross-origin requests",
+				http.StatusForbidden)
+			return true
+		}
+		h.Set("Access-Control-Expose-Headers", util.IdempotentReplayedHeader)
+		return false
+	}
+	h.Set("Access-Control-Allow-Methods", p.allowMethods)
+	h.Set("Access-Control-Allow-Headers", corsAllowedHeaders)
+	h.Set("Access-Control-Max-Age", strconv.Itoa(int(corsMaxAge.Seconds())))
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
@@ -0,0 +1,96 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+func TestCORSPolicy(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	if p := newCORSPolicy(" , ", "GET"); p != nil {
+		t.Fatalf("expected no policy without origins, got %+v", p)
+	}
+
+	p := newCORSPolicy("https://dash.example.com/, http://other:8000", "get, put")
+	testCases := []struct {
+		method, path, origin, requestMethod string
+		expHandled                          bool
+		expAllowOrigin                      string
+		expStatus                           int
+	}{
+		// Same-origin requests are passed through.
+		{"GET", statusPrefix + "nodes/", "", "", false, "", http.StatusOK},
+		// Requests from other origins are passed through without CORS
+		// headers, which makes browsers hide the response.
+		{"GET", statusPrefix + "nodes/", "https://evil.example.com", "", false, "", http.StatusOK},
+		// Requests from allowed origins.
+		{"GET", statusPrefix + "nodes/", "https://dash.example.com", "", false,
+			"https://dash.example.com", http.StatusOK},
+		{"GET", apiEndpoint + "events", "http://other:8000", "", false, "http://other:8000", http.StatusOK},
+		// Only the admin and status endpoints are accessible.
+		{"GET", "/index.html", "https://dash.example.com", "", false, "", http.StatusOK},
+		// Preflight requests are answered.
+		{"OPTIONS", apiEndpoint + "uidata", "https://dash.example.com", "PUT", true,
+			"https://dash.example.com", http.StatusNoContent},
+		// Simple requests using other methods are rejected.
+		{"POST", apiEndpoint + "uidata", "https://dash.example.com", "", true,
+			"https://dash.example.com", http.StatusForbidden},
+	}
+	for i, tc := range testCases {
+		r, err := http.NewRequest(tc.method, tc.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.origin != "" {
+			r.Header.Set("Origin", tc.origin)
+		}
+		if tc.requestMethod != "" {
+			r.Header.Set("Access-Control-Request-Method", tc.requestMethod)
+		}
+		w := httptest.NewRecorder()
+		if handled := p.serve(w, r); handled != tc.expHandled {
+			t.Errorf("%d: expected handled=%t, got %t", i, tc.expHandled, handled)
+		}
+		if a := w.Header().Get("Access-Control-Allow-Origin"); a != tc.expAllowOrigin {
+			t.Errorf("%d: expected allowed origin %q, got %q", i, tc.expAllowOrigin, a)
+		}
+		if w.Code != tc.expStatus {
+			t.Errorf("%d: expected status %d, got %d", i, tc.expStatus, w.Code)
+		}
+		if tc.expStatus == http.StatusNoContent {
+			if a, e := w.Header().Get("Access-Control-Allow-Methods"), "GET, PUT"; a != e {
+				t.Errorf("%d: expected allowed methods %q, got %q", i, e, a)
+			}
+		}
+	}
+
+	// A nil policy disallows all cross-origin requests.
+	var nilPolicy *corsPolicy
+	r, err := http.NewRequest("OPTIONS", apiEndpoint+"uidata", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Origin", "https://dash.example.com")
+	w := httptest.NewRecorder()
+	if nilPolicy.serve(w, r) || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("expected nil policy to disallow cross-origin requests")
+	}
+}
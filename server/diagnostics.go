@@ -0,0 +1,176 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/cockroachdb/cockroach/base"
+	"github.com/cockroachdb/cockroach/keys"
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/storage"
+	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/metric"
+	"github.com/cockroachdb/cockroach/util/stop"
+)
+
+const (
+	// diagnosticsOn and diagnosticsOff are the accepted values of
+	// Context.Diagnostics.
+	diagnosticsOn  = "on"
+	diagnosticsOff = "off"
+)
+
+// DiagnosticStoreInfo summarizes a single store in a DiagnosticReport.
+type DiagnosticStoreInfo struct {
+	StoreID     roachpb.StoreID `json:"storeID"`
+	Replicas    int             `json:"replicas"`
+	LiveBytes   int64           `json:"liveBytes"`
+	KeyBytes    int64           `json:"keyBytes"`
+	ValBytes    int64           `json:"valBytes"`
+	KeyCount    int64           `json:"keyCount"`
+	IntentCount int64           `json:"intentCount"`
+}
+
+// A DiagnosticReport is the anonymized usage information sent by a node
+// when diagnostics reporting is enabled. It contains no keys, values,
+// addresses, or other user data; the cluster is identified only by its
+// randomly generated ID.
+type DiagnosticReport struct {
+	ClusterID string                `json:"clusterID"`
+	NodeID    roachpb.NodeID        `json:"nodeID"`
+	Build     util.BuildInfo        `json:"build"`
+	Uptime    int64                 `json:"uptimeSeconds"`
+	NumNodes  int                   `json:"numNodes"`
+	Stores    []DiagnosticStoreInfo `json:"stores"`
+	// Metrics holds the values of the node's counters and gauges, keyed by
+	// metric name.
+	Metrics map[string]int64 `json:"metrics"`
+}
+
+// A diagnosticsReporter assembles diagnostic reports and, if enabled,
+// periodically sends them to the configured URL.
+type diagnosticsReporter struct {
+	ctx        *Context
+	node       *Node
+	registries map[string]*metric.Registry
+	httpClient *http.Client
+}
+
+func newDiagnosticsReporter(ctx *Context, node *Node,
+	registries map[string]*metric.Registry) *diagnosticsReporter {
+	return &diagnosticsReporter{
+		ctx:        ctx,
+		node:       node,
+		registries: registries,
+		httpClient: &http.Client{Timeout: base.NetworkTimeout},
+	}
+}
+
+// report assembles the diagnostic report of the local node.
+func (r *diagnosticsReporter) report() (*DiagnosticReport, error) {
+	rep := &DiagnosticReport{
+		ClusterID: r.node.ClusterID.String(),
+		NodeID:    r.node.Descriptor.NodeID,
+		Build:     util.GetBuildInfo(),
+		Metrics:   map[string]int64{},
+	}
+	if r.node.startedAt != 0 {
+		rep.Uptime = int64(time.Duration(r.node.ctx.Clock.PhysicalNow() - r.node.startedAt).Seconds())
+	}
+
+	// Count the nodes which have recorded a status.
+	rows, pErr := r.node.ctx.DB.Scan(keys.StatusNodePrefix, keys.StatusNodePrefix.PrefixEnd(), 0)
+	if pErr != nil {
+		return nil, pErr.GoError()
+	}
+	rep.NumNodes = len(rows)
+
+	if err := r.node.stores.VisitStores(func(s *storage.Store) error {
+		ms := s.MVCCStats()
+		rep.Stores = append(rep.Stores, DiagnosticStoreInfo{
+			StoreID:     s.StoreID(),
+			Replicas:    s.ReplicaCount(),
+			LiveBytes:   ms.LiveBytes,
+			KeyBytes:    ms.KeyBytes,
+			ValBytes:    ms.ValBytes,
+			KeyCount:    ms.KeyCount,
+			IntentCount: ms.IntentCount,
+		})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	for prefix, registry := range r.registries {
+		registry.Visit(metric.MetricVisitor{
+			Counter: func(name string, _ map[string]string, c *metric.Counter) {
+				rep.Metrics[prefix+name] = c.Count()
+			},
+			Gauge: func(name string, _ map[string]string, g *metric.Gauge) {
+				rep.Metrics[prefix+name] = g.Value()
+			},
+		})
+	}
+	return rep, nil
+}
+
+// send posts the diagnostic report of the local node to the configured URL.
+func (r *diagnosticsReporter) send() error {
+	rep, err := r.report()
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(rep)
+	if err != nil {
+		return err
+	}
+	resp, err := r.httpClient.Post(r.ctx.DiagnosticsURL, util.JSONContentType, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return util.Errorf("diagnostics report rejected: %s", resp.Status)
+	}
+	return nil
+}
+
+// start launches the reporting loop if diagnostics reporting is enabled.
+func (r *diagnosticsReporter) start(stopper *stop.Stopper) {
+	if r.ctx.Diagnostics != diagnosticsOn {
+		return
+	}
+	log.Infof("reporting anonymous diagnostics to %s every %s",
+		r.ctx.DiagnosticsURL, r.ctx.DiagnosticsReportingInterval)
+	stopper.RunWorker(func() {
+		ticker := time.NewTicker(r.ctx.DiagnosticsReportingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := r.send(); err != nil {
+					log.Warningf("failed to report diagnostics: %s", err)
+				}
+			case <-stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,123 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/testutils"
+	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+// TestDiagnosticsResponse verifies that the diagnostics status endpoint
+// returns the local node's report.
+func TestDiagnosticsResponse(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ts := startServer(t)
+	defer ts.Stop()
+
+	var report DiagnosticReport
+	if err := json.Unmarshal(getRequest(t, ts, statusPrefix+"diagnostics/local"), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.NodeID != ts.node.Descriptor.NodeID {
+		t.Errorf("expected node %d, got %d", ts.node.Descriptor.NodeID, report.NodeID)
+	}
+	if e := ts.node.ClusterID.String(); report.ClusterID != e {
+		t.Errorf("expected cluster %s, got %s", e, report.ClusterID)
+	}
+	if report.NumNodes != 1 {
+		t.Errorf("expected 1 node, got %d", report.NumNodes)
+	}
+	if e := ts.node.stores.GetStoreCount(); len(report.Stores) != e {
+		t.Errorf("expected %d stores, got %d", e, len(report.Stores))
+	}
+	if len(report.Metrics) == 0 {
+		t.Error("expected metrics to be reported")
+	}
+}
+
+// TestDiagnosticsReporting verifies that reports are periodically posted to
+// the diagnostics URL when reporting is enabled.
+func TestDiagnosticsReporting(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var mu sync.Mutex
+	var reports []DiagnosticReport
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var report DiagnosticReport
+		if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		reports = append(reports, report)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	ctx := NewTestContext()
+	ctx.Diagnostics = diagnosticsOn
+	ctx.DiagnosticsURL = srv.URL
+	ctx.DiagnosticsReportingInterval = 10 * time.Millisecond
+	s := StartTestServerWithContext(t, ctx)
+	defer s.Stop()
+
+	util.SucceedsSoon(t, func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(reports) == 0 {
+			return util.Errorf("no report received yet")
+		}
+		if reports[0].NodeID != s.node.Descriptor.NodeID {
+			t.Fatalf("expected report from node %d, got %+v", s.node.Descriptor.NodeID, reports[0])
+		}
+		return nil
+	})
+}
+
+// TestDiagnosticsSettingValidation verifies that invalid diagnostics settings
+// are rejected.
+func TestDiagnosticsSettingValidation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		diagnostics, url string
+		expErr           string
+	}{
+		{diagnosticsOff, "", ""},
+		{diagnosticsOn, "http://localhost/report", ""},
+		{diagnosticsOn, "", "requires a diagnostics URL"},
+		{"maybe", "", "invalid diagnostics setting"},
+	}
+	for i, tc := range testCases {
+		ctx := NewContext()
+		ctx.Diagnostics = tc.diagnostics
+		ctx.DiagnosticsURL = tc.url
+		err := ctx.InitNode()
+		if tc.expErr == "" {
+			if err != nil {
+				t.Errorf("%d: unexpected error: %s", i, err)
+			}
+		} else if !testutils.IsError(err, tc.expErr) {
+			t.Errorf("%d: expected error %q, got %v", i, tc.expErr, err)
+		}
+	}
+}
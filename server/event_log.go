@@ -0,0 +1,185 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/keys"
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/sql"
+	"github.com/cockroachdb/cockroach/storage"
+	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/hlc"
+	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/settings"
+	"github.com/cockroachdb/cockroach/util/stop"
+)
+
+const (
+	// eventLogPruneInterval is the interval at which the event log is
+	// pruned.
+	eventLogPruneInterval = 10 * time.Minute
+	// eventLogPruneBatchSize is the number of events pruned per
+	// transaction, which keeps the transactions small on clusters which
+	// accumulated many events.
+	eventLogPruneBatchSize = 100
+	// eventLogTTLSetting is the name of the cluster setting holding the
+	// duration for which events are retained.
+	eventLogTTLSetting = "server.event_log_ttl"
+)
+
+// An eventLogPruner periodically removes the events older than the
+// cluster-wide TTL from the event log, so that the Events endpoint stays
+// fast on old clusters. Only the node holding the lease of the range
+// containing the event log prunes it, so that the nodes don't race each
+// other. The pruned events are optionally appended to an archive file on
+// that node once their removal committed; events are never archived twice,
+// but they are lost if archiving fails after the commit.
+type eventLogPruner struct {
+	db          *client.DB
+	eventLogger sql.EventLogger
+	archivePath string
+	now         func() time.Time
+	// holdsLease returns whether the node holds the lease of the range
+	// containing the event log.
+	holdsLease func() bool
+	// ttl is the retention period in nanoseconds; zero retains the events
+	// forever. Accessed atomically.
+	ttl int64
+}
+
+func newEventLogPruner(db *client.DB, leaseMgr *sql.LeaseManager, stores *storage.Stores,
+	clock *hlc.Clock, archivePath string) *eventLogPruner {
+	eventLogKey := roachpb.RKey(keys.MakeTablePrefix(keys.EventLogTableID))
+	return &eventLogPruner{
+		db:          db,
+		eventLogger: sql.MakeEventLogger(leaseMgr),
+		archivePath: archivePath,
+		now:         time.Now,
+		holdsLease: func() bool {
+			return stores.HoldsLease(eventLogKey, clock.Now())
+		},
+	}
+}
+
+// registerSetting registers the cluster setting holding the TTL of the
+// events.
+func (p *eventLogPruner) registerSetting(r *settings.Registry) {
+	r.Register(eventLogTTLSetting,
+		"duration for which events are retained in the event log, e.g. 2160h; 0 retains them forever",
+		"0",
+		func(v string) error {
+			_, err := parseEventLogTTL(v)
+			return err
+		},
+		func(v string) {
+			// The value was validated by the registry.
+			ttl, _ := parseEventLogTTL(v)
+			atomic.StoreInt64(&p.ttl, int64(ttl))
+		})
+}
+
+func parseEventLogTTL(v string) (time.Duration, error) {
+	ttl, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, err
+	}
+	if ttl < 0 {
+		return 0, util.Errorf("negative duration %s", ttl)
+	}
+	return ttl, nil
+}
+
+// start prunes the event log every eventLogPruneInterval until the stopper
+// stops, if a TTL is set and the node holds the lease of the event log.
+func (p *eventLogPruner) start(stopper *stop.Stopper) {
+	stopper.RunWorker(func() {
+		ticker := time.NewTicker(eventLogPruneInterval)
+		defer ticker.Stop()
+		for {
+			if ttl := time.Duration(atomic.LoadInt64(&p.ttl)); ttl > 0 && p.holdsLease() {
+				if n, err := p.prune(stopper, ttl); err != nil {
+					log.Warningf("failed to prune the event log: %s", err)
+				} else if n > 0 {
+					log.Infof("pruned %d events older than %s from the event log", n, ttl)
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
+// prune removes the events older than the TTL from the event log in
+// batches, archiving each batch once its removal committed if an archive
+// is configured, and returns the number of events removed. It returns
+// early once the stopper drains.
+func (p *eventLogPruner) prune(stopper *stop.Stopper, ttl time.Duration) (int, error) {
+	before := p.now().Add(-ttl)
+	var total int
+	for {
+		select {
+		case <-stopper.ShouldDrain():
+			return total, nil
+		default:
+		}
+		var events []sql.Event
+		if pErr := p.db.Txn(func(txn *client.Txn) *roachpb.Error {
+			var pErr *roachpb.Error
+			events, pErr = p.eventLogger.PruneEventsInTransaction(txn, before,
+				eventLogPruneBatchSize)
+			return pErr
+		}); pErr != nil {
+			return total, pErr.GoError()
+		}
+		total += len(events)
+		if p.archivePath != "" && len(events) > 0 {
+			if err := p.archive(events); err != nil {
+				return total, err
+			}
+		}
+		if len(events) < eventLogPruneBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// archive appends the events to the archive file.
+func (p *eventLogPruner) archive(events []sql.Event) error {
+	f, err := os.OpenFile(p.archivePath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, event := range events {
+		if err := enc.Encode(event); err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,129 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/sql"
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+	"github.com/cockroachdb/cockroach/util/settings"
+)
+
+// TestEventLogPruner verifies that the events older than the TTL are
+// removed from the event log and appended to the archive, and that the TTL
+// is read from the cluster setting.
+func TestEventLogPruner(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := StartTestServer(t)
+	defer s.Stop()
+
+	var session sql.Session
+	exec := func(q string) sql.Result {
+		res := s.sqlExecutor.ExecuteStatements("root", &session, q, nil)
+		if res.ResultList[0].PErr != nil {
+			t.Fatalf("error executing '%s': %s", q, res.ResultList[0].PErr)
+		}
+		return res.ResultList[0]
+	}
+	countEvents := func() int64 {
+		return int64(exec("SELECT COUNT(*) FROM system.eventlog").Rows[0].Values[0].(parser.DInt))
+	}
+	exec("CREATE DATABASE prune_test")
+	exec("CREATE TABLE prune_test.tbl1 (a INT)")
+	exec("CREATE TABLE prune_test.tbl2 (a INT)")
+	numEvents := countEvents()
+	if numEvents < 3 {
+		t.Fatalf("expected at least 3 events, got %d", numEvents)
+	}
+
+	dir, err := ioutil.TempDir("", "eventlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Error(err)
+		}
+	}()
+	archivePath := filepath.Join(dir, "archive.json")
+	p := newEventLogPruner(s.db, s.leaseMgr, s.node.stores, s.clock, archivePath)
+	// The server registered the setting for its own pruner.
+	r := settings.NewRegistry()
+	p.registerSetting(r)
+	if err := r.Validate(eventLogTTLSetting, "-1h"); err == nil {
+		t.Errorf("expected a negative TTL to be rejected")
+	}
+	r.Update(map[string]string{eventLogTTLSetting: "1h"})
+	ttl := time.Duration(atomic.LoadInt64(&p.ttl))
+	if ttl != time.Hour {
+		t.Fatalf("expected a TTL of %s, got %s", time.Hour, ttl)
+	}
+	// The only node of the cluster holds the lease of the event log, which
+	// it just wrote to.
+	if !p.holdsLease() {
+		t.Errorf("expected the node to hold the lease of the event log")
+	}
+
+	// None of the events is older than the TTL yet.
+	if n, err := p.prune(s.stopper, ttl); err != nil {
+		t.Fatal(err)
+	} else if n != 0 {
+		t.Errorf("expected no events to be pruned, got %d", n)
+	}
+
+	p.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if n, err := p.prune(s.stopper, ttl); err != nil {
+		t.Fatal(err)
+	} else if int64(n) != numEvents {
+		t.Errorf("expected %d events to be pruned, got %d", numEvents, n)
+	}
+	if n := countEvents(); n != 0 {
+		t.Errorf("expected the event log to be empty, got %d events", n)
+	}
+
+	f, err := os.Open(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var archived []sql.Event
+	for scanner := bufio.NewScanner(f); scanner.Scan(); {
+		var event sql.Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatal(err)
+		}
+		archived = append(archived, event)
+	}
+	if int64(len(archived)) != numEvents {
+		t.Fatalf("expected %d archived events, got %d", numEvents, len(archived))
+	}
+	for i := 1; i < len(archived); i++ {
+		if archived[i].Timestamp.Before(archived[i-1].Timestamp) {
+			t.Errorf("expected events to be archived oldest first, got %+v", archived)
+		}
+	}
+	if last := archived[len(archived)-1]; last.EventType != sql.EventLogCreateTable {
+		t.Errorf("expected the last archived event to be %s, got %s", sql.EventLogCreateTable, last.EventType)
+	}
+}
@@ -0,0 +1,335 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/keys"
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/security"
+	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/encoding"
+	"github.com/cockroachdb/cockroach/util/log"
+)
+
+const (
+	// defaultIdempotencyKeyTTL is the duration for which the response to a
+	// request carrying an idempotency key is kept for replay.
+	defaultIdempotencyKeyTTL = time.Hour
+	// maxIdempotencyKeyLength is the maximum length of an idempotency key.
+	maxIdempotencyKeyLength = 255
+	// maxIdempotentBodySize is the maximum size of the body of a request
+	// carrying an idempotency key. Larger responses are not recorded.
+	maxIdempotentBodySize = 1 << 20
+	// defaultMaxIdempotencyKeysPerUser is the maximum number of unexpired
+	// idempotency keys of a user.
+	defaultMaxIdempotencyKeysPerUser = 1000
+	// defaultIdempotencyWaitTimeout is the maximum duration for which a
+	// retry waits for the response to the request it retries.
+	defaultIdempotencyWaitTimeout = 30 * time.Second
+	// idempotencyPendingTTL is the duration after which a request which
+	// never completed, e.g. because its node died, is considered abandoned
+	// and its key may be used again.
+	idempotencyPendingTTL = 5 * time.Minute
+	// idempotencyPollInterval is the interval at which a retry checks
+	// whether the request it retries completed.
+	idempotencyPollInterval = 100 * time.Millisecond
+)
+
+// idempotentResponse is the record kept for an idempotency key. It is
+// stored as JSON in the KV store.
+type idempotentResponse struct {
+	Digest []byte `json:"digest"` // Digest of the request body
+	// Expires is the time at which the record expires. Until Done is set,
+	// it is the time after which the request is considered abandoned.
+	Expires time.Time `json:"expires"`
+	Done    bool      `json:"done"`
+
+	Status int         `json:"status,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+// An idempotencyCache deduplicates retries of mutating admin requests.
+// Clients mark a request with an Idempotency-Key header; the response to
+// the first request with a given key, method and path is recorded and
+// replayed to any retry by the same user instead of applying the request
+// again. The responses are kept in the KV store, so retries may be sent to
+// any node, also after restarts. Retries arriving while the first request
+// is still in flight wait for its response, up to waitTimeout. Reusing a
+// key for a request with a different body is an error. Responses are kept
+// for ttl, but responses with a server error are not kept at all so that
+// the request can be retried.
+type idempotencyCache struct {
+	db          *client.DB
+	ttl         time.Duration
+	maxKeys     int // Per user
+	waitTimeout time.Duration
+	// now is the clock of the cache, replaced in tests.
+	now func() time.Time
+}
+
+func newIdempotencyCache(db *client.DB, ttl time.Duration) *idempotencyCache {
+	return &idempotencyCache{
+		db:          db,
+		ttl:         ttl,
+		maxKeys:     defaultMaxIdempotencyKeysPerUser,
+		waitTimeout: defaultIdempotencyWaitTimeout,
+		now:         time.Now,
+	}
+}
+
+// isMutating returns whether requests using the HTTP method may modify
+// state and are thus subject to deduplication.
+func isMutating(method string) bool {
+	switch method {
+	case "POST", "PUT", "PATCH", "DELETE":
+		return true
+	}
+	return false
+}
+
+// requestUser returns the user authenticated by the client certificate of
+// the request. Requests without one share the scope of the empty user.
+func requestUser(r *http.Request) string {
+	user, err := security.GetCertificateUser(r.TLS)
+	if err != nil {
+		return ""
+	}
+	return user
+}
+
+// idempotencyUserPrefix returns the prefix of the keys of the records of
+// the given user.
+func idempotencyUserPrefix(user string) roachpb.Key {
+	return encoding.EncodeStringAscending(append(roachpb.Key(nil), keys.IdempotencyPrefix...), user)
+}
+
+// errTooManyIdempotencyKeys is returned when a user reached the maximum
+// number of unexpired idempotency keys.
+var errTooManyIdempotencyKeys = util.Errorf("too many unexpired idempotency keys")
+
+// claim looks up the record for the given key. If there is no unexpired
+// record, it stores a pending one for the request and returns nil. The
+// number of unexpired records of the user is bounded by maxKeys; expired
+// records of the user are deleted along the way.
+func (c *idempotencyCache) claim(user string, key roachpb.Key,
+	digest []byte) (*idempotentResponse, error) {
+	var existing *idempotentResponse
+	var tooMany bool
+	pErr := c.db.Txn(func(txn *client.Txn) *roachpb.Error {
+		existing, tooMany = nil, false
+		now := c.now()
+		kv, pErr := txn.Get(key)
+		if pErr != nil {
+			return pErr
+		}
+		if kv.Exists() {
+			var resp idempotentResponse
+			if err := json.Unmarshal(kv.ValueBytes(), &resp); err != nil {
+				return roachpb.NewError(err)
+			}
+			if now.Before(resp.Expires) {
+				existing = &resp
+				return nil
+			}
+		}
+
+		prefix := idempotencyUserPrefix(user)
+		rows, pErr := txn.Scan(prefix, prefix.PrefixEnd(), int64(c.maxKeys+1))
+		if pErr != nil {
+			return pErr
+		}
+		var live int
+		for _, row := range rows {
+			var resp idempotentResponse
+			if err := json.Unmarshal(row.ValueBytes(), &resp); err == nil && now.Before(resp.Expires) {
+				live++
+				continue
+			}
+			if pErr := txn.Del(row.Key); pErr != nil {
+				return pErr
+			}
+		}
+		if live >= c.maxKeys {
+			tooMany = true
+			return nil
+		}
+		value, err := json.Marshal(idempotentResponse{
+			Digest:  digest,
+			Expires: now.Add(idempotencyPendingTTL),
+		})
+		if err != nil {
+			return roachpb.NewError(err)
+		}
+		return txn.Put(key, value)
+	})
+	if pErr != nil {
+		return nil, pErr.GoError()
+	}
+	if tooMany {
+		return nil, errTooManyIdempotencyKeys
+	}
+	return existing, nil
+}
+
+// record stores the response to the request which claimed the key, or
+// releases the key if the response is not to be replayed.
+func (c *idempotencyCache) record(key roachpb.Key, resp idempotentResponse) {
+	var pErr *roachpb.Error
+	if resp.Status >= http.StatusInternalServerError || len(resp.Body) > maxIdempotentBodySize {
+		pErr = c.db.Del(key)
+	} else {
+		resp.Done = true
+		resp.Expires = c.now().Add(c.ttl)
+		value, err := json.Marshal(resp)
+		if err != nil {
+			pErr = roachpb.NewError(err)
+		} else {
+			pErr = c.db.Put(key, value)
+		}
+	}
+	if pErr != nil {
+		log.Warningf("failed to record response for idempotency key %s: %s", key, pErr)
+	}
+}
+
+// serve passes the request to the handler, unless it is a retry of a
+// request carrying the same idempotency key, in which case the recorded
+// response is replayed.
+func (c *idempotencyCache) serve(w http.ResponseWriter, r *http.Request, handler http.Handler) {
+	key := r.Header.Get(util.IdempotencyKeyHeader)
+	if key == "" || !isMutating(r.Method) {
+		handler.ServeHTTP(w, r)
+		return
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		http.Error(w, "idempotency key too long", http.StatusBadRequest)
+		return
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxIdempotentBodySize+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxIdempotentBodySize {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	digest := sha256.Sum256(body)
+	user := requestUser(r)
+	keyDigest := sha256.Sum256([]byte(r.Method + " " + r.URL.Path + " " + key))
+	recordKey := append(idempotencyUserPrefix(user), keyDigest[:]...)
+
+	timeout := time.After(c.waitTimeout)
+	for {
+		resp, err := c.claim(user, recordKey, digest[:])
+		if err == errTooManyIdempotencyKeys {
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if resp == nil {
+			break
+		}
+		if !bytes.Equal(resp.Digest, digest[:]) {
+			http.Error(w, "idempotency key "+key+" was used for a different request",
+				http.StatusUnprocessableEntity)
+			return
+		}
+		if resp.Done {
+			for k, v := range resp.Header {
+				w.Header()[k] = v
+			}
+			w.Header().Set(util.IdempotentReplayedHeader, "true")
+			w.WriteHeader(resp.Status)
+			_, _ = w.Write(resp.Body)
+			return
+		}
+		select {
+		case <-time.After(idempotencyPollInterval):
+		case <-timeout:
+			http.Error(w, "request with idempotency key "+key+" is still in progress",
+				http.StatusConflict)
+			return
+		}
+	}
+
+	rec := &recordingResponseWriter{ResponseWriter: w}
+	// Only the headers set by the handler are recorded; headers set by
+	// wrapping handlers (e.g. for compression) depend on the request.
+	before := http.Header{}
+	for k, v := range w.Header() {
+		before[k] = v
+	}
+	defer func() {
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		resp := idempotentResponse{
+			Digest: digest[:],
+			Status: rec.status,
+			Header: http.Header{},
+			Body:   rec.body.Bytes(),
+		}
+		for k, v := range w.Header() {
+			if _, ok := before[k]; !ok {
+				resp.Header[k] = v
+			}
+		}
+		c.record(recordKey, resp)
+	}()
+	handler.ServeHTTP(rec, r)
+}
+
+// recordingResponseWriter passes a response through to the wrapped writer,
+// recording its status and body. Bodies are recorded up to one byte beyond
+// maxIdempotentBodySize, which suffices to tell that they are too large.
+type recordingResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *recordingResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if n := maxIdempotentBodySize + 1 - w.body.Len(); n > 0 {
+		if len(b) < n {
+			n = len(b)
+		}
+		w.body.Write(b[:n])
+	}
+	return w.ResponseWriter.Write(b)
+}
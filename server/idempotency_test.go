@@ -0,0 +1,178 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+// serveIdempotent sends a request with the given method, user, idempotency
+// key and body through the cache to the handler.
+func serveIdempotent(t *testing.T, c *idempotencyCache, handler http.Handler,
+	method, user, key, body string) *httptest.ResponseRecorder {
+	r, err := http.NewRequest(method, "/_admin/v1/uidata", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != "" {
+		r.Header.Set(util.IdempotencyKeyHeader, key)
+	}
+	if user != "" {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: user}}
+		r.TLS = &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{cert},
+			VerifiedChains:   [][]*x509.Certificate{{cert}},
+		}
+	}
+	w := httptest.NewRecorder()
+	c.serve(w, r, handler)
+	return w
+}
+
+func TestIdempotencyCache(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := StartTestServer(t)
+	defer s.Stop()
+
+	now := time.Unix(0, 0)
+	clock := func() time.Time { return now }
+	c := newIdempotencyCache(s.db, time.Minute)
+	c.now = clock
+	// other plays the part of the cache of another node.
+	other := newIdempotencyCache(s.db, time.Minute)
+	other.now = clock
+
+	var applied int
+	status := http.StatusOK
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		applied++
+		w.Header().Set(util.ContentTypeHeader, util.PlaintextContentType)
+		w.WriteHeader(status)
+		fmt.Fprintf(w, "applied %d", applied)
+	})
+
+	testCases := []struct {
+		cache             *idempotencyCache
+		method, user, key string
+		body              string
+		advance           time.Duration
+		status            int
+		expBody           string
+		expReplayed       bool
+	}{
+		// Requests without a key are never deduplicated.
+		{c, "POST", "", "", "a", 0, http.StatusOK, "applied 1", false},
+		{c, "POST", "", "", "a", 0, http.StatusOK, "applied 2", false},
+		// Retries with the same key are replayed, also by other nodes.
+		{c, "POST", "", "k1", "a", 0, http.StatusOK, "applied 3", false},
+		{c, "POST", "", "k1", "a", 0, http.StatusOK, "applied 3", true},
+		{other, "POST", "", "k1", "a", 0, http.StatusOK, "applied 3", true},
+		// Keys are scoped by user.
+		{c, "POST", "alice", "k1", "a", 0, http.StatusOK, "applied 4", false},
+		{other, "POST", "alice", "k1", "a", 0, http.StatusOK, "applied 4", true},
+		// Reusing a key for a different request is an error.
+		{c, "POST", "", "k1", "b", 0, http.StatusUnprocessableEntity, "", false},
+		// Non-mutating requests are not deduplicated.
+		{c, "GET", "", "k1", "a", 0, http.StatusOK, "applied 5", false},
+		// Keys expire after the TTL.
+		{c, "POST", "", "k1", "a", time.Minute, http.StatusOK, "applied 6", false},
+		// Server errors are not recorded.
+		{c, "POST", "", "k2", "a", 0, http.StatusInternalServerError, "applied 7", false},
+		{c, "POST", "", "k2", "a", 0, http.StatusOK, "applied 8", false},
+		{c, "POST", "", "k2", "a", 0, http.StatusOK, "applied 8", true},
+		// Request bodies are limited.
+		{c, "POST", "", "k3", strings.Repeat("a", maxIdempotentBodySize+1), 0,
+			http.StatusRequestEntityTooLarge, "", false},
+	}
+	for i, tc := range testCases {
+		now = now.Add(tc.advance)
+		status = tc.status
+		w := serveIdempotent(t, tc.cache, handler, tc.method, tc.user, tc.key, tc.body)
+		if w.Code != tc.status {
+			t.Errorf("%d: expected status %d, got %d", i, tc.status, w.Code)
+		}
+		if tc.expBody != "" && w.Body.String() != tc.expBody {
+			t.Errorf("%d: expected body %q, got %q", i, tc.expBody, w.Body.String())
+		}
+		if replayed := w.Header().Get(util.IdempotentReplayedHeader) != ""; replayed != tc.expReplayed {
+			t.Errorf("%d: expected replayed=%t, got %t", i, tc.expReplayed, replayed)
+		}
+		if ct := w.Header().Get(util.ContentTypeHeader); w.Code == http.StatusOK && ct != util.PlaintextContentType {
+			t.Errorf("%d: unexpected content type %q", i, ct)
+		}
+	}
+}
+
+// TestIdempotencyCacheLimits verifies that the number of unexpired keys of
+// a user is bounded and that retries of a request in flight stop waiting
+// for it after a timeout.
+func TestIdempotencyCacheLimits(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := StartTestServer(t)
+	defer s.Stop()
+
+	now := time.Unix(0, 0)
+	c := newIdempotencyCache(s.db, time.Minute)
+	c.now = func() time.Time { return now }
+	c.maxKeys = 2
+	c.waitTimeout = 10 * time.Millisecond
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	for i, key := range []string{"k1", "k2"} {
+		if w := serveIdempotent(t, c, handler, "POST", "bob", key, ""); w.Code != http.StatusOK {
+			t.Fatalf("%d: expected status %d, got %d", i, http.StatusOK, w.Code)
+		}
+	}
+	if w := serveIdempotent(t, c, handler, "POST", "bob", "k3", ""); w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected status %d, got %d", http.StatusTooManyRequests, w.Code)
+	}
+	// Other users are not affected.
+	if w := serveIdempotent(t, c, handler, "POST", "carol", "k3", ""); w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	// Expired keys don't count.
+	now = now.Add(time.Minute)
+	if w := serveIdempotent(t, c, handler, "POST", "bob", "k3", ""); w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	// Claim a key as if a request carrying it were in flight.
+	keyDigest := sha256.Sum256([]byte("POST /_admin/v1/uidata k4"))
+	digest := sha256.Sum256(nil)
+	recordKey := append(idempotencyUserPrefix("carol"), keyDigest[:]...)
+	if resp, err := c.claim("carol", recordKey, digest[:]); err != nil || resp != nil {
+		t.Fatalf("expected to claim the key, got %+v, %v", resp, err)
+	}
+	if w := serveIdempotent(t, c, handler, "POST", "carol", "k4", ""); w.Code != http.StatusConflict {
+		t.Errorf("expected status %d, got %d", http.StatusConflict, w.Code)
+	}
+	// Requests which never complete are eventually abandoned.
+	now = now.Add(idempotencyPendingTTL)
+	if w := serveIdempotent(t, c, handler, "POST", "carol", "k4", ""); w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+}
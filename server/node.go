@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"google.golang.org/grpc/credentials"
@@ -72,7 +73,7 @@ var errCannotJoinSelf = errors.New("an uninitialized node cannot specify its own
 
 type nodeMetrics struct {
 	registry *metric.Registry
-	latency  metric.Histograms
+	latency  metric.Latency
 	success  metric.Rates
 	err      metric.Rates
 }
@@ -96,7 +97,7 @@ func (nm nodeMetrics) callComplete(d time.Duration, pErr *roachpb.Error) {
 	} else {
 		nm.success.Add(1)
 	}
-	nm.latency.RecordValue(d.Nanoseconds())
+	nm.latency.RecordValue(d)
 }
 
 // A Node manages a map of stores (by store ID) for which it serves
@@ -118,6 +119,10 @@ type Node struct {
 	recorder   *status.MetricsRecorder
 	startedAt  int64
 	txnMetrics *kv.TxnMetrics
+	// catchingUp is 1 while the node only serves reads which don't need
+	// the leader lease, until its stores caught up with their ranges after
+	// a restart. Accessed atomically.
+	catchingUp int32
 }
 
 // allocateNodeID increments the node id generator key to allocate
@@ -511,11 +516,17 @@ func (n *Node) startGossip(stopper *stop.Stopper) {
 		nodeTicker := time.NewTicker(gossipNodeDescriptorInterval)
 		defer storesTicker.Stop()
 		defer nodeTicker.Stop()
+		drain := stopper.ShouldDrain()
+		// Clear the draining flag gossiped before a restart.
+		n.gossipStoresDraining(false)
 		n.gossipStores() // one-off run before going to sleep
 		for {
 			select {
 			case <-storesTicker.C:
 				n.gossipStores()
+			case <-drain:
+				n.gossipStoresDraining(true)
+				drain = nil
 			case <-nodeTicker.C:
 				if err := n.ctx.Gossip.SetNodeDescriptor(&n.Descriptor); err != nil {
 					log.Warningf("couldn't gossip descriptor for node %d: %s", n.Descriptor.NodeID, err)
@@ -537,6 +548,16 @@ func (n *Node) gossipStores() {
 	}
 }
 
+// gossipStoresDraining broadcasts whether the stores are draining.
+func (n *Node) gossipStoresDraining(draining bool) {
+	if err := n.stores.VisitStores(func(s *storage.Store) error {
+		s.GossipDraining(draining)
+		return nil
+	}); err != nil {
+		panic(err)
+	}
+}
+
 // startComputePeriodidMetrics starts a loop which periodically instructs each
 // store to compute the value of metrics which cannot be incrementally
 // maintained.
@@ -631,6 +652,67 @@ func (n *Node) writeSummaries() error {
 	return err
 }
 
+// catchUpPollInterval is the interval at which a catching up node checks
+// whether its stores caught up with their ranges.
+const catchUpPollInterval = 100 * time.Millisecond
+
+// setCatchingUp makes the node admit only reads which don't need the leader
+// lease, i.e. INCONSISTENT reads and follower reads, until startCatchUp
+// sees its stores caught up.
+func (n *Node) setCatchingUp() {
+	atomic.StoreInt32(&n.catchingUp, 1)
+}
+
+// CatchingUp returns true while the node only admits reads which don't need
+// the leader lease.
+func (n *Node) CatchingUp() bool {
+	return atomic.LoadInt32(&n.catchingUp) == 1
+}
+
+// startCatchUp waits for the stores of the node to catch up with their
+// ranges, or for maxWait to pass, and then admits all requests again.
+func (n *Node) startCatchUp(maxWait time.Duration) {
+	n.stopper.RunWorker(func() {
+		defer atomic.StoreInt32(&n.catchingUp, 0)
+		deadline := time.Now().Add(maxWait)
+		ticker := time.NewTicker(catchUpPollInterval)
+		defer ticker.Stop()
+		for {
+			caughtUp := true
+			if err := n.stores.VisitStores(func(s *storage.Store) error {
+				caughtUp = caughtUp && s.CaughtUp()
+				return nil
+			}); err != nil {
+				log.Error(err)
+			}
+			if caughtUp {
+				log.Infof("node %d caught up with its ranges", n.Descriptor.NodeID)
+				return
+			}
+			if time.Now().After(deadline) {
+				log.Warningf("node %d did not catch up with its ranges within %s; admitting all requests",
+					n.Descriptor.NodeID, maxWait)
+				return
+			}
+			select {
+			case <-ticker.C:
+			case <-n.stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
+// admitWhileCatchingUp returns true if the batch is a read which doesn't
+// need the leader lease and may thus be served by a catching up node.
+func admitWhileCatchingUp(ba *roachpb.BatchRequest) bool {
+	if !ba.IsReadOnly() {
+		return false
+	}
+	return ba.ReadConsistency == roachpb.INCONSISTENT ||
+		(ba.Txn == nil && !ba.Timestamp.Equal(roachpb.ZeroTimestamp))
+}
+
 // Batch implements the roachpb.KVServer interface.
 func (n *Node) Batch(ctx context.Context, args *roachpb.BatchRequest) (*roachpb.BatchResponse, error) {
 	// TODO(marc): this code is duplicated in kv/db.go, which should be fixed.
@@ -650,6 +732,11 @@ func (n *Node) Batch(ctx context.Context, args *roachpb.BatchRequest) (*roachpb.
 		}
 	}
 
+	if n.CatchingUp() && !admitWhileCatchingUp(args) {
+		return nil, util.Errorf("node %d is catching up and only serves inconsistent and follower reads",
+			n.Descriptor.NodeID)
+	}
+
 	var br *roachpb.BatchResponse
 	opName := "node " + strconv.Itoa(int(n.Descriptor.NodeID)) // could save allocs here
 
@@ -631,3 +631,42 @@ func TestStatusSummaries(t *testing.T) {
 	compareNodeStatus(t, ts, expectedNodeStatus, 3)
 	compareStoreStatus(t, ts, s, expectedStoreStatus, 3)
 }
+
+// TestAdmitWhileCatchingUp verifies that a catching up node only admits
+// reads which don't need the leader lease.
+func TestAdmitWhileCatchingUp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	key := roachpb.Key("a")
+	txn := roachpb.NewTransaction("test", key, roachpb.NormalUserPriority,
+		roachpb.SERIALIZABLE, roachpb.Timestamp{WallTime: 1}, 0)
+	testCases := []struct {
+		consistency roachpb.ReadConsistencyType
+		timestamp   roachpb.Timestamp
+		txn         *roachpb.Transaction
+		req         roachpb.Request
+		admit       bool
+	}{
+		// Inconsistent reads are admitted.
+		{roachpb.INCONSISTENT, roachpb.ZeroTimestamp, nil, &roachpb.GetRequest{}, true},
+		// Reads at a fixed timestamp outside of a transaction are follower
+		// reads and admitted.
+		{roachpb.CONSISTENT, roachpb.Timestamp{WallTime: 1}, nil, &roachpb.ScanRequest{}, true},
+		// Consistent reads at the current time need the leader lease.
+		{roachpb.CONSISTENT, roachpb.ZeroTimestamp, nil, &roachpb.GetRequest{}, false},
+		// So do transactional reads.
+		{roachpb.CONSISTENT, roachpb.Timestamp{WallTime: 1}, txn, &roachpb.GetRequest{}, false},
+		// Writes are never admitted.
+		{roachpb.CONSISTENT, roachpb.Timestamp{WallTime: 1}, nil, &roachpb.PutRequest{}, false},
+	}
+	for i, c := range testCases {
+		ba := &roachpb.BatchRequest{}
+		ba.ReadConsistency = c.consistency
+		ba.Timestamp = c.timestamp
+		ba.Txn = c.txn
+		c.req.Header().Key = key
+		ba.Add(c.req)
+		if admit := admitWhileCatchingUp(ba); admit != c.admit {
+			t.Errorf("%d: expected admit=%t, got %t", i, c.admit, admit)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/util"
+)
+
+const (
+	// profileTimeFormat is the format of the timestamp embedded in the file
+	// name of a profile snapshot. It avoids colons, which are not allowed in
+	// file names on all platforms.
+	profileTimeFormat = "20060102T150405.000Z"
+	profileFileSuffix = ".pprof"
+
+	// defaultBlockProfileDuration is the time during which blocking events
+	// are recorded before a block profile snapshot is captured.
+	defaultBlockProfileDuration = 5 * time.Second
+	// maxBlockProfileDuration bounds the time during which blocking events
+	// are recorded, since captures are serialized and the request is held
+	// open until the snapshot is taken.
+	maxBlockProfileDuration = time.Minute
+)
+
+// profileTypes are the runtime profiles which can be captured. Contention
+// on mutexes is reported by the "block" profile.
+var profileTypes = map[string]struct{}{
+	"heap":      {},
+	"block":     {},
+	"goroutine": {},
+}
+
+// A ProfileSnapshot describes a profile captured through the admin API.
+type ProfileSnapshot struct {
+	Name string    `json:"name"`
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	Size int64     `json:"size"`
+}
+
+// A profileStore captures runtime profiles and retains the most recent
+// snapshots on disk.
+type profileStore struct {
+	dir string
+	max int
+	now func() time.Time
+
+	// mu serializes captures, so that concurrent block profiles don't
+	// interfere with each other's profiling rate.
+	mu sync.Mutex
+}
+
+func newProfileStore(dir string, max int) *profileStore {
+	return &profileStore{
+		dir: dir,
+		max: max,
+		now: time.Now,
+	}
+}
+
+// capture writes a snapshot of the given profile type to disk and removes
+// the oldest snapshots in excess of the retention limit. Since the block
+// profile only records events while enabled, blocking events are recorded
+// for the given duration (or defaultBlockProfileDuration if zero) before
+// it is captured.
+func (ps *profileStore) capture(typ string, duration time.Duration) (ProfileSnapshot, error) {
+	if ps.dir == "" {
+		return ProfileSnapshot{}, util.Errorf("no profile directory configured")
+	}
+	if _, ok := profileTypes[typ]; !ok {
+		return ProfileSnapshot{}, util.Errorf("unknown profile type %q", typ)
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	switch typ {
+	case "heap":
+		// Make sure the profile reflects the live heap.
+		runtime.GC()
+	case "block":
+		if duration == 0 {
+			duration = defaultBlockProfileDuration
+		}
+		runtime.SetBlockProfileRate(1)
+		time.Sleep(duration)
+		runtime.SetBlockProfileRate(0)
+	}
+
+	if err := os.MkdirAll(ps.dir, 0755); err != nil {
+		return ProfileSnapshot{}, err
+	}
+	snap := ProfileSnapshot{
+		Type: typ,
+		Time: ps.now().UTC(),
+	}
+	snap.Name = typ + "." + snap.Time.Format(profileTimeFormat) + profileFileSuffix
+	f, err := os.Create(filepath.Join(ps.dir, snap.Name))
+	if err != nil {
+		return ProfileSnapshot{}, err
+	}
+	err = pprof.Lookup(typ).WriteTo(f, 0)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return ProfileSnapshot{}, err
+	}
+	info, err := os.Stat(filepath.Join(ps.dir, snap.Name))
+	if err != nil {
+		return ProfileSnapshot{}, err
+	}
+	snap.Size = info.Size()
+
+	return snap, ps.prune()
+}
+
+// list returns the retained snapshots, oldest first.
+func (ps *profileStore) list() ([]ProfileSnapshot, error) {
+	if ps.dir == "" {
+		return nil, nil
+	}
+	infos, err := ioutil.ReadDir(ps.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var snaps []ProfileSnapshot
+	for _, info := range infos {
+		snap, ok := parseProfileName(info.Name())
+		if !ok || info.IsDir() {
+			continue
+		}
+		snap.Size = info.Size()
+		snaps = append(snaps, snap)
+	}
+	sort.Sort(profileSnapshotsByTime(snaps))
+	return snaps, nil
+}
+
+// path returns the path of the snapshot with the given name.
+func (ps *profileStore) path(name string) (string, error) {
+	if _, ok := parseProfileName(name); !ok || ps.dir == "" {
+		return "", util.Errorf("invalid profile snapshot %q", name)
+	}
+	return filepath.Join(ps.dir, name), nil
+}
+
+// prune removes the oldest snapshots in excess of the retention limit.
+func (ps *profileStore) prune() error {
+	snaps, err := ps.list()
+	if err != nil {
+		return err
+	}
+	for len(snaps) > ps.max {
+		if err := os.Remove(filepath.Join(ps.dir, snaps[0].Name)); err != nil {
+			return err
+		}
+		snaps = snaps[1:]
+	}
+	return nil
+}
+
+// parseProfileName parses the type and time of a snapshot from its file
+// name, returning false if the name is not that of a snapshot.
+func parseProfileName(name string) (ProfileSnapshot, bool) {
+	if !strings.HasSuffix(name, profileFileSuffix) {
+		return ProfileSnapshot{}, false
+	}
+	parts := strings.SplitN(strings.TrimSuffix(name, profileFileSuffix), ".", 2)
+	if len(parts) != 2 {
+		return ProfileSnapshot{}, false
+	}
+	if _, ok := profileTypes[parts[0]]; !ok {
+		return ProfileSnapshot{}, false
+	}
+	t, err := time.Parse(profileTimeFormat, parts[1])
+	if err != nil {
+		return ProfileSnapshot{}, false
+	}
+	return ProfileSnapshot{Name: name, Type: parts[0], Time: t}, true
+}
+
+type profileSnapshotsByTime []ProfileSnapshot
+
+func (s profileSnapshotsByTime) Len() int      { return len(s) }
+func (s profileSnapshotsByTime) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s profileSnapshotsByTime) Less(i, j int) bool {
+	return s[i].Time.Before(s[j].Time)
+}
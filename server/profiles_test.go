@@ -0,0 +1,161 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/security"
+	"github.com/cockroachdb/cockroach/testutils"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+func TestProfileStoreRetention(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	dir, err := ioutil.TempDir("", "profiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	ps := newProfileStore(dir, 2)
+	now := time.Unix(1460000000, 0)
+	ps.now = func() time.Time { return now }
+
+	var names []string
+	for _, typ := range []string{"heap", "goroutine", "heap"} {
+		snap, err := ps.capture(typ, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if snap.Type != typ || snap.Size == 0 {
+			t.Errorf("unexpected snapshot %+v", snap)
+		}
+		names = append(names, snap.Name)
+		now = now.Add(time.Second)
+	}
+
+	// Only the two most recent snapshots are retained.
+	snaps, err := ps.list()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 2 || snaps[0].Name != names[1] || snaps[1].Name != names[2] {
+		t.Fatalf("expected snapshots %s, got %+v", names[1:], snaps)
+	}
+
+	if _, err := ps.capture("cpu", 0); !testutils.IsError(err, "unknown profile type") {
+		t.Errorf("expected unknown profile type error, got %v", err)
+	}
+	for _, name := range []string{"../heap.20160101T000000.000Z.pprof", "heap.pprof", "cpu.20160101T000000.000Z.pprof"} {
+		if _, err := ps.path(name); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestAdminProfiles(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	dir, err := ioutil.TempDir("", "profiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	ctx := NewTestContext()
+	ctx.ProfileDir = dir
+	s := StartTestServerWithContext(t, ctx)
+	defer s.Stop()
+
+	client, err := testutils.NewTestBaseContext(security.RootUser).GetHTTPClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := s.Ctx.HTTPRequestScheme() + "://" + s.HTTPAddr() + profilesPath
+
+	// The duration of block profiles is bounded.
+	resp, err := client.Post(fmt.Sprintf("%sblock?seconds=%d", url, maxBlockProfileDuration/time.Second+1), "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status code %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+
+	resp, err = client.Post(url+"block?seconds=1", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snap ProfileSnapshot
+	err = json.NewDecoder(resp.Body).Decode(&snap)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Type != "block" {
+		t.Fatalf("unexpected snapshot %+v", snap)
+	}
+
+	var list struct {
+		Profiles []ProfileSnapshot `json:"profiles"`
+	}
+	body, err := getText(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Profiles) != 1 || list.Profiles[0].Name != snap.Name {
+		t.Fatalf("expected snapshot %s to be listed, got %+v", snap.Name, list.Profiles)
+	}
+
+	body, err = getText(url + snap.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(len(body)) != snap.Size {
+		t.Errorf("expected %d bytes, got %d", snap.Size, len(body))
+	}
+
+	// Profiles are restricted like the debug endpoints.
+	client, err = testutils.NewTestBaseContext(TestUser).GetHTTPClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected status code %d, got %d", http.StatusForbidden, resp.StatusCode)
+	}
+}
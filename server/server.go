@@ -26,6 +26,8 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	assetfs "github.com/elazarl/go-bindata-assetfs"
 	opentracing "github.com/opentracing/opentracing-go"
@@ -42,12 +44,15 @@ import (
 	"github.com/cockroachdb/cockroach/sql"
 	"github.com/cockroachdb/cockroach/sql/pgwire"
 	"github.com/cockroachdb/cockroach/storage"
+	"github.com/cockroachdb/cockroach/storage/engine"
 	"github.com/cockroachdb/cockroach/ts"
 	"github.com/cockroachdb/cockroach/ui"
 	"github.com/cockroachdb/cockroach/util"
 	"github.com/cockroachdb/cockroach/util/hlc"
 	"github.com/cockroachdb/cockroach/util/log"
 	"github.com/cockroachdb/cockroach/util/metric"
+	"github.com/cockroachdb/cockroach/util/retry"
+	"github.com/cockroachdb/cockroach/util/settings"
 	"github.com/cockroachdb/cockroach/util/stop"
 	"github.com/cockroachdb/cockroach/util/tracing"
 )
@@ -70,11 +75,13 @@ type Server struct {
 	gossip              *gossip.Gossip
 	storePool           *storage.StorePool
 	db                  *client.DB
+	distSender          *kv.DistSender
 	kvDB                *kv.DBServer
 	pgServer            pgwire.Server
 	node                *Node
 	recorder            *status.MetricsRecorder
 	admin               *adminServer
+	cors                *corsPolicy
 	status              *statusServer
 	tsDB                *ts.DB
 	tsServer            *ts.Server
@@ -83,6 +90,14 @@ type Server struct {
 	sqlExecutor         *sql.Executor
 	leaseMgr            *sql.LeaseManager
 	schemaChangeManager *sql.SchemaChangeManager
+	eventLogPruner      *eventLogPruner
+	settings            *settings.Registry
+	diagnostics         *diagnosticsReporter
+	slowRequests        *tracing.SlowRequests
+	runtimeSampler      *status.RuntimeStatSampler
+	// started is set to 1 once Start returned and the server serves SQL.
+	// Accessed atomically.
+	started int32
 }
 
 // NewServer creates a Server from a server.Context.
@@ -105,17 +120,38 @@ func NewServer(ctx *Context, stopper *stop.Stopper) (*Server, error) {
 	if _, err := ctx.GetClientTLSConfig(); err != nil {
 		return nil, err
 	}
+	certs, err := ctx.certificates()
+	if err != nil {
+		return nil, err
+	}
 
+	clockSource := hlc.UnixNano
+	if ctx.TestingMocker.ClockSource != nil {
+		clockSource = ctx.TestingMocker.ClockSource
+	}
+	if offset := ctx.ClockOffset.Nanoseconds(); offset != 0 {
+		log.Warningf("skewing clock by %s", ctx.ClockOffset)
+		wallClock := clockSource
+		clockSource = func() int64 {
+			return wallClock() + offset
+		}
+	}
+	slowRequests := tracing.NewSlowRequests(ctx.SlowRequestThreshold, ctx.SlowRequestsRetained)
 	s := &Server{
-		Tracer:  tracing.NewTracer(),
-		ctx:     ctx,
-		mux:     http.NewServeMux(),
-		clock:   hlc.NewClock(hlc.UnixNano),
-		stopper: stopper,
+		// The tracer passes its spans to slowRequests, which retains those
+		// of slow requests.
+		Tracer:       tracing.NewTeeTracer(slowRequests),
+		slowRequests: slowRequests,
+		ctx:          ctx,
+		mux:          http.NewServeMux(),
+		clock:        hlc.NewClock(clockSource),
+		stopper:      stopper,
 	}
 	s.clock.SetMaxOffset(ctx.MaxOffset)
 
 	s.rpcContext = rpc.NewContext(&ctx.Context, s.clock, stopper)
+	s.rpcContext.Dialer = ctx.TestingMocker.RPCDialer
+	s.rpcContext.VerifyLocalCalls = ctx.TestingMocker.VerifyLocalCalls
 	stopper.RunWorker(func() {
 		s.rpcContext.RemoteClocks.MonitorRemoteOffsets(stopper)
 	})
@@ -136,14 +172,44 @@ func NewServer(ctx *Context, stopper *stop.Stopper) (*Server, error) {
 	// DistSender needs to know that it should not retry in this situation.
 	retryOpts := kv.GetDefaultDistSenderRetryOptions()
 	retryOpts.Closer = stopper.ShouldDrain()
-	ds := kv.NewDistSender(&kv.DistSenderContext{
-		Clock:           s.clock,
-		RPCContext:      s.rpcContext,
-		RPCRetryOptions: &retryOpts,
+	distSenderRegistry := metric.NewRegistry()
+	// Warm up the range descriptor cache with the descriptors persisted
+	// before the restart. The engines are otherwise opened when the stores
+	// are started.
+	var rangeDescs []roachpb.RangeDescriptor
+	if ctx.RangeDescriptorCacheInterval > 0 {
+		for _, eng := range ctx.Engines {
+			if err := eng.Open(); err != nil {
+				return nil, err
+			}
+		}
+		if rangeDescs, err = storage.ReadRangeDescriptorCache(ctx.Engines); err != nil {
+			return nil, err
+		}
+		log.Infof("read %d range descriptors from persistent storage", len(rangeDescs))
+	}
+	s.distSender = kv.NewDistSender(&kv.DistSenderContext{
+		Clock:                    s.clock,
+		RPCContext:               s.rpcContext,
+		RPCRetryOptions:          &retryOpts,
+		FollowerReads:            ctx.FollowerReads,
+		Tracer:                   s.Tracer,
+		SlowRequests:             s.slowRequests,
+		PrefetchRangeDescriptors: true,
+		Registry:                 distSenderRegistry,
+		SendNextTimeout:          ctx.SendNextTimeout,
+		RPCTimeout:               ctx.RPCTimeout,
+		BatchDeadline:            ctx.BatchDeadline,
+		RangeRetryBudget:         ctx.RangeRetryBudget,
+		SendParallelism:          ctx.SendParallelism,
+		RangeLookupRateLimit:     float64(ctx.RangeLookupRateLimit),
+		TimeUntilStoreDead:       ctx.TimeUntilStoreDead,
+		RangeDescriptors:         rangeDescs,
 	}, s.gossip)
 	txnRegistry := metric.NewRegistry()
 	txnMetrics := kv.NewTxnMetrics(txnRegistry)
-	sender := kv.NewTxnCoordSender(ds, s.clock, ctx.Linearizable, s.Tracer, s.stopper, txnMetrics)
+	sender := kv.NewTxnCoordSender(s.distSender, s.clock, ctx.Linearizable, s.Tracer, s.stopper, txnMetrics)
+	sender.SetPipelineWrites(ctx.PipelineTxnWrites)
 	s.db = client.NewDB(sender)
 
 	s.grpc = rpc.NewServer(s.rpcContext)
@@ -152,19 +218,31 @@ func NewServer(ctx *Context, stopper *stop.Stopper) (*Server, error) {
 	s.kvDB = kv.NewDBServer(&s.ctx.Context, sender, stopper)
 	roachpb.RegisterExternalServer(s.grpc, s.kvDB)
 
-	s.leaseMgr = sql.NewLeaseManager(0, *s.db, s.clock)
+	leaseRegistry := metric.NewRegistry()
+	s.leaseMgr = sql.NewLeaseManager(0, *s.db, s.clock, leaseRegistry)
 	s.leaseMgr.RefreshLeases(s.stopper, s.db, s.gossip)
+	defaultRateLimit, userRateLimits, err := sql.ParseUserRateLimits(ctx.SQLUserRateLimits)
+	if err != nil {
+		return nil, err
+	}
+	s.recorder = status.NewMetricsRecorder(s.clock)
+	s.settings = settings.NewRegistry()
 	eCtx := sql.ExecutorContext{
-		DB:            s.db,
-		Gossip:        s.gossip,
-		LeaseManager:  s.leaseMgr,
-		TestingMocker: ctx.TestingMocker.ExecutorTestingMocker,
+		DB:                   s.db,
+		Gossip:               s.gossip,
+		LeaseManager:         s.leaseMgr,
+		DefaultUserRateLimit: defaultRateLimit,
+		UserRateLimits:       userRateLimits,
+		ClusterStatus:        clusterStatus{db: s.db, recorder: s.recorder},
+		Settings:             s.settings,
+		EagerCommit:          ctx.SQLEagerCommit,
+		TestingMocker:        ctx.TestingMocker.ExecutorTestingMocker,
 	}
 
 	sqlRegistry := metric.NewRegistry()
 	s.sqlExecutor = sql.NewExecutor(eCtx, s.stopper, sqlRegistry)
 
-	s.pgServer = pgwire.MakeServer(&s.ctx.Context, s.sqlExecutor, sqlRegistry)
+	s.pgServer = pgwire.MakeServer(s.ctx.SQLContext(), s.sqlExecutor, sqlRegistry)
 
 	// TODO(bdarnell): make StoreConfig configurable.
 	nCtx := storage.StoreContext{
@@ -176,11 +254,18 @@ func NewServer(ctx *Context, stopper *stop.Stopper) (*Server, error) {
 		ConsistencyCheckInterval: s.ctx.ConsistencyCheckInterval,
 		ScanMaxIdleTime:          s.ctx.ScanMaxIdleTime,
 		Tracer:                   s.Tracer,
+		SlowRequests:             s.slowRequests,
 		StorePool:                s.storePool,
 		SQLExecutor: sql.InternalExecutor{
 			LeaseManager: s.leaseMgr,
 		},
-		LogRangeEvents: true,
+		LogRangeEvents:        true,
+		MaxConcurrentRequests: s.ctx.MaxConcurrentStoreRequests,
+		LoadSplitQPSThreshold: s.ctx.LoadSplitQPSThreshold,
+		MergeQueueEnabled:     s.ctx.MergeQueueEnabled,
+		MergeCooldown:         s.ctx.MergeCooldown,
+		ClosedTimestampTarget: s.ctx.ClosedTimestampTarget,
+		ExportDir:             s.ctx.ExportDir,
 		AllocatorOptions: storage.AllocatorOptions{
 			AllowRebalance: true,
 			Mode:           storage.BalanceModeUsage,
@@ -188,17 +273,38 @@ func NewServer(ctx *Context, stopper *stop.Stopper) (*Server, error) {
 		TestingMocker: ctx.TestingMocker.StoreTestingMocker,
 	}
 
-	s.recorder = status.NewMetricsRecorder(s.clock)
 	s.recorder.AddNodeRegistry("sql.%s", sqlRegistry)
+	s.recorder.AddNodeRegistry("sql.lease.%s", leaseRegistry)
 	s.recorder.AddNodeRegistry("txn.%s", txnRegistry)
+	s.recorder.AddNodeRegistry("distsender.%s", distSenderRegistry)
+	s.recorder.AddNodeRegistry("rpc.%s", s.rpcContext.Breakers.Registry())
+	s.recorder.AddNodeRegistry("rpc.heartbeat.%s", s.rpcContext.RemoteLatencies.Registry())
+	runtimeRegistry := metric.NewRegistry()
+	s.runtimeSampler = status.NewRuntimeStatSampler(s.clock, runtimeRegistry)
+	s.recorder.AddNodeRegistry("sys.%s", runtimeRegistry)
 
 	s.node = NewNode(nCtx, s.recorder, s.stopper, txnMetrics)
 	roachpb.RegisterInternalServer(s.grpc, s.node)
+	s.eventLogPruner = newEventLogPruner(s.db, s.leaseMgr, s.node.stores, s.clock,
+		s.ctx.EventLogArchive)
+	s.eventLogPruner.registerSetting(s.settings)
 
-	s.admin = newAdminServer(s.db, s.stopper, s.sqlExecutor)
+	s.admin = newAdminServer(s.db, s.stopper, s.sqlExecutor, s.node, s.ctx.Insecure,
+		newProfileStore(s.ctx.ProfileDir, s.ctx.ProfileSnapshots), s.recorder)
+	s.cors = newCORSPolicy(s.ctx.CORSAllowedOrigins, s.ctx.CORSAllowedMethods)
 	s.tsDB = ts.NewDB(s.db)
 	s.tsServer = ts.NewServer(s.tsDB)
-	s.status = newStatusServer(s.db, s.gossip, s.recorder, s.ctx)
+	s.diagnostics = newDiagnosticsReporter(s.ctx, s.node, map[string]*metric.Registry{
+		"sql.":        sqlRegistry,
+		"txn.":        txnRegistry,
+		"distsender.": distSenderRegistry,
+		"rpc.":        s.rpcContext.Breakers.Registry(),
+		"exec.":       s.node.metrics.registry,
+	})
+	s.status = newStatusServer(s.db, s.gossip, s.recorder, s.diagnostics, s.node.stores,
+		s.slowRequests, s.distSender, s.stopper, s.ctx, s.ready)
+	s.status.certificates = certs
+	s.recorder.AddNodeRegistry("security.%s", certificateMetrics(certs))
 
 	return s, nil
 }
@@ -206,12 +312,28 @@ func NewServer(ctx *Context, stopper *stop.Stopper) (*Server, error) {
 // Start starts the server on the specified port, starts gossip and
 // initializes the node using the engines from the server's context.
 func (s *Server) Start() error {
+	if s.ctx.HLCUpperBoundInterval > 0 {
+		if err := s.waitHLCUpperBound(); err != nil {
+			return err
+		}
+	}
+
 	s.initHTTP()
 
+	// Admit only reads which don't need the leader lease until the stores
+	// caught up, which startCatchUp waits for once they are initialized.
+	if s.ctx.StartupCatchUpTimeout > 0 {
+		s.node.setCatchingUp()
+	}
+
 	tlsConfig, err := s.ctx.GetServerTLSConfig()
 	if err != nil {
 		return err
 	}
+	httpTLSConfig, err := s.ctx.HTTPContext().GetServerTLSConfig()
+	if err != nil {
+		return err
+	}
 
 	// The following code is a specialization of util/net.go's ListenAndServe
 	// which adds pgwire support. A single port is used to serve all protocols
@@ -233,8 +355,10 @@ func (s *Server) Start() error {
 	// Go's lack of an h2c (HTTP2 Clear Text) implementation. See inline comments
 	// in util.ListenAndServe for an explanation of how h2c is implemented there
 	// and here.
+	//
+	// If SQLAddr is set, pgwire is served on a listener of its own instead.
 
-	ln, err := net.Listen("tcp", s.ctx.Addr)
+	ln, err := s.ctx.listen(s.ctx.Addr)
 	if err != nil {
 		return err
 	}
@@ -253,10 +377,31 @@ func (s *Server) Start() error {
 	})
 
 	m := cmux.New(ln)
-	pgL := m.Match(pgwire.Match)
+	var pgL net.Listener
+	if s.ctx.SQLAddr == "" {
+		pgL = m.Match(pgwire.Match)
+	} else {
+		sqlLn, err := s.ctx.listen(s.ctx.SQLAddr)
+		if err != nil {
+			return err
+		}
+		unresolvedSQLAddr, err := officialAddr(s.ctx.SQLAddr, sqlLn.Addr())
+		if err != nil {
+			return err
+		}
+		s.ctx.SQLAddr = unresolvedSQLAddr.String()
+
+		s.stopper.RunWorker(func() {
+			<-s.stopper.ShouldDrain()
+			if err := sqlLn.Close(); err != nil {
+				log.Fatal(err)
+			}
+		})
+		pgL = sqlLn
+	}
 	anyL := m.Match(cmux.Any())
 
-	httpLn, err := net.Listen("tcp", s.ctx.HTTPAddr)
+	httpLn, err := s.ctx.listen(s.ctx.HTTPAddr)
 	if err != nil {
 		return err
 	}
@@ -273,11 +418,17 @@ func (s *Server) Start() error {
 		}
 	})
 
-	if tlsConfig != nil {
-		httpLn = tls.NewListener(httpLn, tlsConfig)
+	if httpTLSConfig != nil {
+		httpLn = tls.NewListener(httpLn, httpTLSConfig)
 	}
 
-	serveConn := util.ServeHandler(s.stopper, s, httpLn, tlsConfig)
+	serveConn := util.ServeHandler(s.stopper, s, httpLn, httpTLSConfig)
+
+	if s.ctx.DebugAddr != "" {
+		if err := s.startDebugServer(tlsConfig); err != nil {
+			return err
+		}
+	}
 
 	s.stopper.RunWorker(func() {
 		util.FatalIfUnexpected(s.grpc.Serve(anyL))
@@ -307,10 +458,23 @@ func (s *Server) Start() error {
 	if err := s.node.start(unresolvedAddr, s.ctx.Engines, s.ctx.NodeAttributes); err != nil {
 		return err
 	}
+	// Requests to the node's replicas are served locally even if they are
+	// addressed to another of the node's addresses.
+	s.rpcContext.SetLocalNodeID(s.node.Descriptor.NodeID)
+	if s.ctx.HLCUpperBoundInterval > 0 {
+		if err := s.startPersistHLCUpperBound(s.ctx.HLCUpperBoundInterval); err != nil {
+			return err
+		}
+	}
+	if s.ctx.RangeDescriptorCacheInterval > 0 {
+		s.startPersistRangeDescriptorCache(s.ctx.RangeDescriptorCacheInterval)
+	}
+	if s.ctx.StartupCatchUpTimeout > 0 {
+		s.node.startCatchUp(s.ctx.StartupCatchUpTimeout)
+	}
 
 	// Begin recording runtime statistics.
-	runtime := status.NewRuntimeStatRecorder(s.node.Descriptor.NodeID, s.clock)
-	s.tsDB.PollSource(runtime, s.ctx.MetricsFrequency, ts.Resolution10s, s.stopper)
+	s.startSampleEnvironment(s.ctx.MetricsFrequency)
 
 	// Begin recording time series data collected by the status monitor.
 	s.tsDB.PollSource(s.recorder, s.ctx.MetricsFrequency, ts.Resolution10s, s.stopper)
@@ -318,18 +482,256 @@ func (s *Server) Start() error {
 	// Begin recording status summaries.
 	s.node.startWriteSummaries(s.ctx.MetricsFrequency)
 
+	// Begin pushing metrics to the configured monitoring systems.
+	if s.ctx.MetricsGraphiteAddr != "" {
+		metric.NewExporter(s.recorder.ExportRegistry(),
+			metric.NewGraphiteSink(s.ctx.MetricsGraphiteAddr, s.ctx.MetricsPushPrefix),
+			s.ctx.MetricsFrequency).Start(s.stopper)
+	}
+	if s.ctx.MetricsStatsDAddr != "" {
+		metric.NewExporter(s.recorder.ExportRegistry(),
+			metric.NewStatsDSink(s.ctx.MetricsStatsDAddr, s.ctx.MetricsPushPrefix),
+			s.ctx.MetricsFrequency).Start(s.stopper)
+	}
+	if s.ctx.MetricsSink != "" {
+		sink, err := metric.NewSinkFromURL(s.ctx.MetricsSink, s.ctx.MetricsPushPrefix)
+		if err != nil {
+			return err
+		}
+		metric.NewExporter(s.recorder.ExportRegistry(), sink, s.ctx.MetricsFrequency).Start(s.stopper)
+	}
+	// Expose the metrics at /debug/vars for expvar-based collectors.
+	metric.PublishExpvar("cockroach", metric.ExpvarFunc(s.recorder.ExportRegistry))
+
 	s.sqlExecutor.SetNodeID(s.node.Descriptor.NodeID)
 	// Create and start the schema change manager only after a NodeID
 	// has been assigned.
 	s.schemaChangeManager = sql.NewSchemaChangeManager(*s.db, s.gossip, s.leaseMgr)
 	s.schemaChangeManager.Start(s.stopper)
+	s.startCreateMissingSystemTables()
+
+	s.eventLogPruner.start(s.stopper)
+
+	s.diagnostics.start(s.stopper)
 
 	log.Infof("starting %s server at %s", s.ctx.HTTPRequestScheme(), unresolvedHTTPAddr)
 	log.Infof("starting grpc/postgres server at %s", unresolvedAddr)
 
+	atomic.StoreInt32(&s.started, 1)
 	return nil
 }
 
+// ready returns an error describing why the server is not ready to serve
+// all requests yet, or nil once its stores are open, it is connected to the
+// gossip network, it serves SQL and it caught up with its ranges.
+func (s *Server) ready() error {
+	if s.node.stores.GetStoreCount() == 0 {
+		return util.Errorf("stores are not open")
+	}
+	select {
+	case <-s.gossip.Connected:
+	default:
+		return util.Errorf("gossip is not connected")
+	}
+	if atomic.LoadInt32(&s.started) == 0 {
+		return util.Errorf("SQL is not served yet")
+	}
+	if s.node.CatchingUp() {
+		return util.Errorf("node is catching up with its ranges")
+	}
+	return nil
+}
+
+// startDebugServer serves the debug endpoints on DebugAddr, using the same
+// TLS configuration as the main HTTP server.
+func (s *Server) startDebugServer(tlsConfig *tls.Config) error {
+	debugLn, err := s.ctx.listen(s.ctx.DebugAddr)
+	if err != nil {
+		return err
+	}
+	unresolvedDebugAddr, err := officialAddr(s.ctx.DebugAddr, debugLn.Addr())
+	if err != nil {
+		return err
+	}
+	s.ctx.DebugAddr = unresolvedDebugAddr.String()
+
+	s.stopper.RunWorker(func() {
+		<-s.stopper.ShouldDrain()
+		if err := debugLn.Close(); err != nil {
+			log.Fatal(err)
+		}
+	})
+
+	if tlsConfig != nil {
+		debugLn = tls.NewListener(debugLn, tlsConfig)
+	}
+
+	debugMux := http.NewServeMux()
+	debugMux.Handle(debugEndpoint, s.admin)
+	util.ServeHandler(s.stopper, debugMux, debugLn, tlsConfig)
+
+	log.Infof("starting debug server at %s", unresolvedDebugAddr)
+	return nil
+}
+
+// startSampleEnvironment begins periodically sampling the runtime statistics
+// of the process into the metrics of the runtime registry.
+func (s *Server) startSampleEnvironment(frequency time.Duration) {
+	s.stopper.RunWorker(func() {
+		ticker := time.NewTicker(frequency)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.runtimeSampler.SampleEnvironment()
+			case <-s.stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
+// waitHLCUpperBound waits for the clock to pass the upper bound on its wall
+// time persisted before the node was restarted, if the bound is ahead of
+// the clock.
+func (s *Server) waitHLCUpperBound() error {
+	// The engines are otherwise opened when the stores are started, which
+	// issues timestamps already. Only reading them leaves the engines of
+	// stores which aren't bootstrapped yet empty.
+	for _, eng := range s.ctx.Engines {
+		if err := eng.Open(); err != nil {
+			return err
+		}
+	}
+	bound, err := storage.ReadHLCUpperBound(s.ctx.Engines)
+	if err != nil {
+		return err
+	}
+	if wait := time.Duration(bound - s.clock.PhysicalNow()); wait > 0 {
+		log.Warningf("waiting %s for the clock to pass the wall time of the timestamps it issued before the restart", wait)
+		s.clock.SleepUntil(bound)
+	}
+	return nil
+}
+
+// startCreateMissingSystemTables creates the system tables which are missing
+// on clusters bootstrapped by an earlier version. It retries in the
+// background until it succeeds, so that an unavailable cluster does not
+// block startup.
+func (s *Server) startCreateMissingSystemTables() {
+	s.stopper.RunWorker(func() {
+		opts := retry.Options{
+			InitialBackoff: 100 * time.Millisecond,
+			MaxBackoff:     10 * time.Second,
+			Multiplier:     2,
+			Closer:         s.stopper.ShouldStop(),
+		}
+		for r := retry.Start(opts); r.Next(); {
+			pErr := sql.CreateMissingSystemTables(s.db)
+			if pErr == nil {
+				return
+			}
+			log.Warningf("unable to create missing system tables: %s", pErr)
+		}
+	})
+}
+
+// startPersistHLCUpperBound starts persisting an upper bound on the wall
+// time of the clock to the engines of the initialized stores at the given
+// interval. When the server stops, the wall time of the last timestamp
+// issued is persisted instead, so that a clean restart only waits if the
+// clock moved backwards.
+func (s *Server) startPersistHLCUpperBound(interval time.Duration) error {
+	// Each bound covers the timestamps issued until the next one is
+	// persisted, with an interval to spare.
+	persist := func() error {
+		return storage.WriteHLCUpperBound(s.storeEngines(), s.clock.WallTimeUpperBound(2*interval))
+	}
+	if err := persist(); err != nil {
+		return err
+	}
+	s.stopper.RunWorker(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := persist(); err != nil {
+					log.Warningf("failed to persist the clock's upper bound: %s", err)
+				}
+			case <-s.stopper.ShouldStop():
+				if err := storage.WriteHLCUpperBound(s.storeEngines(), s.clock.Now().WallTime); err != nil {
+					log.Warningf("failed to persist the clock's upper bound: %s", err)
+				}
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// storeEngines returns the engines of the node's initialized stores. Data
+// must not be written to the engines of the stores which aren't
+// bootstrapped yet, as bootstrapping requires them to be empty.
+func (s *Server) storeEngines() []engine.Engine {
+	var engines []engine.Engine
+	_ = s.node.stores.VisitStores(func(store *storage.Store) error {
+		engines = append(engines, store.Engine())
+		return nil
+	})
+	return engines
+}
+
+// startPersistRangeDescriptorCache starts persisting the contents of the
+// range descriptor cache to the engines of the initialized stores at the
+// given interval. Each engine is written in full once; after that, only
+// the descriptors which changed since the previous interval are written.
+func (s *Server) startPersistRangeDescriptorCache(interval time.Duration) {
+	s.stopper.RunWorker(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		// prev holds the descriptors last persisted to the engines in
+		// written.
+		var prev []roachpb.RangeDescriptor
+		written := map[engine.Engine]struct{}{}
+		for {
+			select {
+			case <-ticker.C:
+				cached := s.distSender.RangeCacheContents(roachpb.RKeyMin, 0).Descriptors
+				descs := make([]roachpb.RangeDescriptor, len(cached))
+				for i := range cached {
+					descs[i] = cached[i].Desc
+				}
+				var fresh, known []engine.Engine
+				for _, eng := range s.storeEngines() {
+					if _, ok := written[eng]; ok {
+						known = append(known, eng)
+					} else {
+						fresh = append(fresh, eng)
+					}
+				}
+				if err := storage.WriteRangeDescriptorCache(known, prev, descs); err != nil {
+					log.Warningf("failed to persist the range descriptor cache: %s", err)
+					// The engines may now hold anything between prev and
+					// descs, so write them in full next time.
+					written = map[engine.Engine]struct{}{}
+					continue
+				}
+				prev = descs
+				if err := storage.WriteRangeDescriptorCache(fresh, nil, descs); err != nil {
+					log.Warningf("failed to persist the range descriptor cache: %s", err)
+					continue
+				}
+				for _, eng := range fresh {
+					written[eng] = struct{}{}
+				}
+			case <-s.stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
 // initHTTP registers http prefixes.
 func (s *Server) initHTTP() {
 	s.mux.Handle("/", http.FileServer(
@@ -344,7 +746,11 @@ func (s *Server) initHTTP() {
 	// TODO(marc): when cookie-based authentication exists,
 	// apply it for all web endpoints.
 	s.mux.Handle(adminEndpoint, s.admin)
-	s.mux.Handle(debugEndpoint, s.admin)
+	// The debug endpoints are served on their own listener if DebugAddr is
+	// set. See startDebugServer.
+	if s.ctx.DebugAddr == "" {
+		s.mux.Handle(debugEndpoint, s.admin)
+	}
 	s.mux.Handle(statusPrefix, s.status)
 	s.mux.Handle(healthEndpoint, s.status)
 	s.mux.Handle(ts.URLPrefix, s.tsServer)
@@ -361,6 +767,10 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// This is our base handler, so catch all panics and make sure they stick.
 	defer log.FatalOnPanic()
 
+	if s.cors.serve(w, r) {
+		return
+	}
+
 	// Disable caching of responses.
 	w.Header().Set("Cache-control", "no-cache")
 
@@ -26,6 +26,7 @@ import (
 	"os"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -35,11 +36,15 @@ import (
 	"github.com/cockroachdb/cockroach/keys"
 	"github.com/cockroachdb/cockroach/kv"
 	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/security"
 	"github.com/cockroachdb/cockroach/sql"
+	"github.com/cockroachdb/cockroach/storage"
+	"github.com/cockroachdb/cockroach/storage/engine"
 	"github.com/cockroachdb/cockroach/testutils"
 	"github.com/cockroachdb/cockroach/util"
 	"github.com/cockroachdb/cockroach/util/leaktest"
 	"github.com/cockroachdb/cockroach/util/metric"
+	"github.com/cockroachdb/cockroach/util/stop"
 	"github.com/cockroachdb/cockroach/util/tracing"
 )
 
@@ -454,3 +459,203 @@ func TestOfficializeAddr(t *testing.T) {
 		checkOfficialize(t, network, ":0", "127.0.0.1:2345", net.JoinHostPort(hostname, "2345"))
 	}
 }
+
+// TestTestServerHelpers exercises the TestServer helpers for splitting
+// ranges, transferring leases and accessing metrics.
+func TestTestServerHelpers(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := StartTestServer(t)
+	defer s.Stop()
+
+	if len(s.Engines()) != 1 {
+		t.Fatalf("expected one engine, got %d", len(s.Engines()))
+	}
+
+	splitKey := roachpb.Key("helpers-split")
+	left, right, err := s.SplitRange(splitKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !left.EndKey.Equal(splitKey) || !right.StartKey.Equal(splitKey) {
+		t.Fatalf("unexpected split result: %s, %s", left, right)
+	}
+	desc, err := s.LookupRange(splitKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc.RangeID != right.RangeID {
+		t.Fatalf("expected range %d to contain %s, got %d", right.RangeID, splitKey, desc.RangeID)
+	}
+
+	// A lease can only be transferred to a replica of the range.
+	if err := s.TransferLease(splitKey, roachpb.ReplicaDescriptor{NodeID: 99, StoreID: 99}); !testutils.IsError(err, "has no replica") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Requesting the lease for the range's only replica succeeds.
+	if err := s.TransferLease(splitKey, right.Replicas[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	storeID := right.Replicas[0].StoreID
+	if n := s.MustGetStoreCounter(storeID, "ranges"); n < 2 {
+		t.Errorf("expected at least two ranges, got %d", n)
+	}
+	s.MustGetStoreGauge(storeID, "livebytes")
+	registry := metric.NewRegistry()
+	registry.Gauge("gauge").Update(5)
+	if v := MustGetGauge(registry, "gauge"); v != 5 {
+		t.Errorf("expected gauge value 5, got %d", v)
+	}
+}
+
+// TestPersistHLCUpperBound verifies that a server which bootstraps its store
+// persists an upper bound on the wall time of its clock to the store.
+func TestPersistHLCUpperBound(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := StartTestServer(t)
+	defer s.Stop()
+
+	bound, err := storage.ReadHLCUpperBound(s.Engines())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if now := s.Clock().PhysicalNow(); bound < now {
+		t.Errorf("expected an upper bound past %d, got %d", now, bound)
+	}
+}
+
+// TestCreateMissingSystemTables verifies that the system tables added after a
+// cluster was bootstrapped are created on startup.
+func TestCreateMissingSystemTables(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := StartTestServer(t)
+	defer s.Stop()
+
+	nameKey := sql.MakeNameMetadataKey(keys.SystemDatabaseID, "settings")
+	descKey := sql.MakeDescMetadataKey(keys.SettingsTableID)
+
+	// Simulate a cluster bootstrapped without the settings table.
+	if pErr := s.db.Txn(func(txn *client.Txn) *roachpb.Error {
+		b := txn.NewBatch()
+		b.Del(nameKey, descKey)
+		txn.SetSystemConfigTrigger()
+		return txn.CommitInBatch(b)
+	}); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	// Creating the missing tables is idempotent.
+	for i := 0; i < 2; i++ {
+		if pErr := sql.CreateMissingSystemTables(s.db); pErr != nil {
+			t.Fatal(pErr)
+		}
+		for _, key := range []roachpb.Key{nameKey, descKey} {
+			if gr, pErr := s.db.Get(key); pErr != nil {
+				t.Fatal(pErr)
+			} else if !gr.Exists() {
+				t.Fatalf("%d: expected %s to exist", i, key)
+			}
+		}
+	}
+}
+
+// TestPersistRangeDescriptorCache verifies that the range descriptor cache
+// is persisted to the engines of the stores and warms up the cache of the
+// server restarted on them.
+func TestPersistRangeDescriptorCache(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	// The engines outlive the first server.
+	engineStopper := stop.NewStopper()
+	defer engineStopper.Stop()
+	engines := []engine.Engine{engine.NewInMem(roachpb.Attributes{}, 100<<20, engineStopper)}
+
+	ctx := NewTestContext()
+	ctx.Engines = engines
+	ctx.RangeDescriptorCacheInterval = 10 * time.Millisecond
+	s := StartTestServerWithContext(t, ctx)
+	if err := s.DB().Put("a", "b"); err != nil {
+		s.Stop()
+		t.Fatal(err)
+	}
+	util.SucceedsSoon(t, func() error {
+		descs, err := storage.ReadRangeDescriptorCache(engines)
+		if err != nil {
+			return err
+		}
+		if len(descs) == 0 {
+			return util.Errorf("no range descriptors persisted yet")
+		}
+		return nil
+	})
+	s.Stop()
+
+	persisted, err := storage.ReadRangeDescriptorCache(engines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx = NewTestContext()
+	ctx.Engines = engines
+	ctx.RangeDescriptorCacheInterval = 10 * time.Millisecond
+	s = StartTestServerWithContext(t, ctx)
+	defer s.Stop()
+	cached := map[roachpb.RangeID]roachpb.RangeDescriptor{}
+	for _, desc := range s.distSender.RangeCacheContents(roachpb.RKeyMin, 0).Descriptors {
+		cached[desc.Desc.RangeID] = desc.Desc
+	}
+	for _, desc := range persisted {
+		if c, ok := cached[desc.RangeID]; !ok || !reflect.DeepEqual(c, desc) {
+			t.Errorf("expected persisted descriptor %+v in the cache, got %+v", desc, c)
+		}
+	}
+}
+
+// TestSQLRetryNotices verifies that a session which enabled RETRY_NOTICES is
+// told about the automatic retries of its transactions.
+func TestSQLRetryNotices(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	var mu sync.Mutex
+	retried := false
+	ctx := NewTestContext()
+	ctx.TestingMocker.StoreTestingMocker.TestingCommandFilter =
+		func(_ roachpb.StoreID, args roachpb.Request, _ roachpb.Header) error {
+			mu.Lock()
+			defer mu.Unlock()
+			cput, ok := args.(*roachpb.ConditionalPutRequest)
+			if !ok || retried || !bytes.Contains(cput.Value.RawBytes, []byte("boulanger")) {
+				return nil
+			}
+			retried = true
+			return roachpb.NewReadWithinUncertaintyIntervalError(
+				roachpb.ZeroTimestamp, roachpb.ZeroTimestamp)
+		}
+	s := StartTestServerWithContext(t, ctx)
+	defer s.Stop()
+
+	var session sql.Session
+	for _, q := range []string{
+		"CREATE DATABASE t",
+		"CREATE TABLE t.test (k CHAR PRIMARY KEY, v TEXT)",
+		"SET RETRY_NOTICES = 'on'",
+	} {
+		if res := s.sqlExecutor.ExecuteStatements(security.RootUser, &session, q, nil); res.ResultList[0].PErr != nil {
+			t.Fatal(res.ResultList[0].PErr)
+		}
+	}
+
+	res := s.sqlExecutor.ExecuteStatements(security.RootUser, &session,
+		"INSERT INTO t.test (k, v) VALUES ('a', 'boulanger')", nil)
+	result := res.ResultList[0]
+	if result.PErr != nil {
+		t.Fatal(result.PErr)
+	}
+	if len(result.Notices) != 1 || !strings.Contains(result.Notices[0], "retried automatically 1 time(s)") {
+		t.Errorf("expected a single retry notice, got %q", result.Notices)
+	}
+
+	// Statements which are not retried carry no notices.
+	res = s.sqlExecutor.ExecuteStatements(security.RootUser, &session,
+		"INSERT INTO t.test (k, v) VALUES ('b', 'boulanger')", nil)
+	if result := res.ResultList[0]; result.PErr != nil || len(result.Notices) != 0 {
+		t.Errorf("expected no error and no notices, got %v, %q", result.PErr, result.Notices)
+	}
+}
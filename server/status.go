@@ -17,24 +17,33 @@
 package server
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"regexp"
 	"runtime"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cockroachdb/cockroach/base"
 	"github.com/cockroachdb/cockroach/client"
 	"github.com/cockroachdb/cockroach/gossip"
 	"github.com/cockroachdb/cockroach/keys"
+	"github.com/cockroachdb/cockroach/kv"
 	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/security"
 	"github.com/cockroachdb/cockroach/server/status"
 	"github.com/cockroachdb/cockroach/storage"
 	"github.com/cockroachdb/cockroach/util"
 	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/metric"
+	"github.com/cockroachdb/cockroach/util/stop"
+	"github.com/cockroachdb/cockroach/util/tracing"
 	"github.com/julienschmidt/httprouter"
 )
 
@@ -55,6 +64,21 @@ const (
 		/_status/nodes/:node_id		     - a specific node's status
 		/_status/stores                  - all stores' status
 		/_status/stores/:store_id        - a specific store's status
+		/_status/metrics/:node_id        - the metrics of a specific node,
+										   optionally restricted to those
+										   matching ?prefix= or ?names=
+		/_status/metrics/metadata        - the units and descriptions of the
+										   metrics
+		/_status/diagnostics/:node_id    - the diagnostic report of a node
+		/_status/hotranges/:node_id      - the busiest ranges of a node
+		/_status/slow_requests/:node_id  - traces of the slowest requests of a
+										   node
+		/_status/tasks/:node_id          - the tasks running on a node,
+										   longest running first
+		/_status/config/:node_id         - the effective configuration of a
+										   node and the sources of its values
+		/_status/vars                    - the local node's metrics in the
+										   Prometheus text format
 	*/
 
 	// statusPrefix is the root of the cluster statistics and metrics API.
@@ -93,27 +117,112 @@ const (
 
 	// statusMetricsPattern exposes transient stats / metrics for a node.
 	statusMetricsPattern = statusPrefix + "metrics/:node_id"
+	// statusMetricsMetadataParam is the node_id parameter of
+	// statusMetricsPattern which exposes the units and descriptions of the
+	// metrics instead. It can't be a route of its own because it would
+	// conflict with the node_id parameter.
+	statusMetricsMetadataParam = "metadata"
+	// statusMetricsAggregateParam is the node_id parameter of
+	// statusMetricsPattern which exposes the store-level metrics of the local
+	// node aggregated across its stores instead, e.g. the sum of the live
+	// bytes of all stores. The prefix and names parameters apply to it too.
+	statusMetricsAggregateParam = "aggregate"
+	// statusMetricsPrefixParam is the query parameter of statusMetricsPattern
+	// which restricts the metrics to those whose time series names start with
+	// its value.
+	statusMetricsPrefixParam = "prefix"
+	// statusMetricsNamesParam is the query parameter of statusMetricsPattern
+	// which restricts the metrics to those whose time series names are in its
+	// comma-separated list. It may be repeated.
+	statusMetricsNamesParam = "names"
+
+	// statusVarsEndpoint exposes the metrics of the local node in the
+	// Prometheus text exposition format, for scraping by Prometheus.
+	statusVarsEndpoint = statusPrefix + "vars"
+
+	// statusDiagnosticsPattern exposes the anonymous diagnostic report of a
+	// node, exactly as it is sent when diagnostics reporting is enabled.
+	statusDiagnosticsPattern = statusPrefix + "diagnostics/:node_id"
+
+	// statusHotRangesPattern exposes the ranges of a node with the highest
+	// request rates.
+	statusHotRangesPattern = statusPrefix + "hotranges/:node_id"
+	// Default number of ranges returned per store by the hot ranges endpoint.
+	defaultHotRangesCount = 10
+
+	// statusExpiredTxnsPattern exposes the expired transaction records of a
+	// node whose intents have not been cleaned up yet.
+	statusExpiredTxnsPattern = statusPrefix + "expiredtxns/:node_id"
+
+	// statusSlowRequestsPattern exposes the traces of the slowest requests
+	// served by a node.
+	statusSlowRequestsPattern = statusPrefix + "slow_requests/:node_id"
+
+	// statusTasksPattern exposes the tasks running on a node, so that a
+	// shutdown which hangs while draining them can be attributed to the
+	// subsystem which ran the task.
+	statusTasksPattern = statusPrefix + "tasks/:node_id"
+
+	// statusConfigPattern exposes the effective configuration of a node,
+	// including whether each value came from a flag, an environment
+	// variable or the defaults, so that it can be verified remotely.
+	statusConfigPattern = statusPrefix + "config/:node_id"
+
+	// statusRangeCachePattern exposes the contents of the range descriptor
+	// and leader caches of a node, for debugging stale descriptors.
+	statusRangeCachePattern = statusPrefix + "range-cache/:node_id"
+	// Default number of descriptors returned per page by the range cache
+	// endpoint.
+	defaultRangeCacheLimit = 1000
+
+	// statusCertificatesPattern exposes the certificates loaded by a node,
+	// including their expiration and key sizes.
+	statusCertificatesPattern = statusPrefix + "certificates/:node_id"
 
 	// healthEndpoint is a shortcut for local details, intended for use by
-	// monitoring processes to verify that the server is up.
+	// monitoring processes to verify that the server is up. With ?ready=1,
+	// it fails while the node isn't ready to serve all requests, see
+	// handleHealth.
 	healthEndpoint = "/health"
 )
 
 // Pattern for local used when determining the node ID.
 var localRE = regexp.MustCompile(`(?i)local`)
 
+// A metricMarshaler renders the metrics of a node both as JSON and in the
+// Prometheus text exposition format, and describes them.
+type metricMarshaler interface {
+	json.Marshaler
+	Filtered(func(name string) bool) json.Marshaler
+	AggregatedStoreMetrics(func(name string) bool) map[string]float64
+	PrintAsPrometheus(io.Writer) error
+	MetricsMetadata() map[string]metric.Metadata
+}
+
 // A statusServer provides a RESTful status API.
 type statusServer struct {
 	db           *client.DB
 	gossip       *gossip.Gossip
-	metricSource json.Marshaler
+	metricSource metricMarshaler
+	diagnostics  *diagnosticsReporter
+	stores       *storage.Stores
+	slowRequests *tracing.SlowRequests
+	distSender   *kv.DistSender
+	// certificates are the certificates loaded by the node at startup.
+	certificates []security.CertificateInfo
+	stopper      *stop.Stopper
 	router       *httprouter.Router
 	ctx          *Context
 	proxyClient  *http.Client
+	// ready returns an error while the node isn't ready to serve all
+	// requests.
+	ready func() error
 }
 
 // newStatusServer allocates and returns a statusServer.
-func newStatusServer(db *client.DB, gossip *gossip.Gossip, metricSource json.Marshaler, ctx *Context) *statusServer {
+func newStatusServer(db *client.DB, gossip *gossip.Gossip, metricSource metricMarshaler,
+	diagnostics *diagnosticsReporter, stores *storage.Stores, slowRequests *tracing.SlowRequests,
+	distSender *kv.DistSender, stopper *stop.Stopper, ctx *Context, ready func() error) *statusServer {
 	// Create an http client with a timeout
 	tlsConfig, err := ctx.GetClientTLSConfig()
 	if err != nil {
@@ -129,9 +238,15 @@ func newStatusServer(db *client.DB, gossip *gossip.Gossip, metricSource json.Mar
 		db:           db,
 		gossip:       gossip,
 		metricSource: metricSource,
+		diagnostics:  diagnostics,
+		stores:       stores,
+		slowRequests: slowRequests,
+		distSender:   distSender,
+		stopper:      stopper,
 		router:       httprouter.New(),
 		ctx:          ctx,
 		proxyClient:  httpClient,
+		ready:        ready,
 	}
 
 	server.router.GET(statusGossipPattern, server.handleGossip)
@@ -145,8 +260,17 @@ func newStatusServer(db *client.DB, gossip *gossip.Gossip, metricSource json.Mar
 	server.router.GET(statusStoresPrefix, server.handleStoresStatus)
 	server.router.GET(statusStorePattern, server.handleStoreStatus)
 	server.router.GET(statusMetricsPattern, server.handleMetrics)
-
-	server.router.GET(healthEndpoint, server.handleDetailsLocal)
+	server.router.GET(statusVarsEndpoint, server.handleVars)
+	server.router.GET(statusDiagnosticsPattern, server.handleDiagnostics)
+	server.router.GET(statusHotRangesPattern, server.handleHotRanges)
+	server.router.GET(statusExpiredTxnsPattern, server.handleExpiredTxns)
+	server.router.GET(statusSlowRequestsPattern, server.handleSlowRequests)
+	server.router.GET(statusTasksPattern, server.handleTasks)
+	server.router.GET(statusConfigPattern, server.handleConfig)
+	server.router.GET(statusRangeCachePattern, server.handleRangeCache)
+	server.router.GET(statusCertificatesPattern, server.handleCertificates)
+
+	server.router.GET(healthEndpoint, server.handleHealth)
 	return server
 }
 
@@ -260,6 +384,23 @@ func (s *statusServer) handleDetailsLocal(w http.ResponseWriter, r *http.Request
 	respondAsJSON(w, r, local)
 }
 
+// handleHealth handles GET requests for the health of the node. By default
+// it is a liveness probe: the node is healthy as soon as it serves HTTP.
+// With the ready parameter set, e.g. /health?ready=1, it is a readiness
+// probe which fails with 503 Service Unavailable until the stores are open,
+// gossip is connected, SQL is served and the node caught up with its
+// ranges, so that orchestrators and load balancers can tell live and ready
+// nodes apart.
+func (s *statusServer) handleHealth(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if r.URL.Query().Get("ready") != "" && s.ready != nil {
+		if err := s.ready(); err != nil {
+			http.Error(w, "not ready: "+err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+	}
+	s.handleDetailsLocal(w, r, ps)
+}
+
 // handleDetails handles GET requests for node details.
 func (s *statusServer) handleDetails(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	nodeID, local, err := s.extractNodeID(ps)
@@ -600,6 +741,14 @@ func (s *statusServer) handleStoreStatus(w http.ResponseWriter, r *http.Request,
 }
 
 func (s *statusServer) handleMetrics(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	switch ps.ByName("node_id") {
+	case statusMetricsMetadataParam:
+		s.handleMetricsMetadata(w, r)
+		return
+	case statusMetricsAggregateParam:
+		s.handleMetricsAggregate(w, r)
+		return
+	}
 	nodeID, local, err := s.extractNodeID(ps)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -610,9 +759,365 @@ func (s *statusServer) handleMetrics(w http.ResponseWriter, r *http.Request, ps
 		s.proxyRequest(nodeID, w, r)
 		return
 	}
+	if filter := metricsFilter(r.URL.Query()); filter != nil {
+		respondAsJSON(w, r, s.metricSource.Filtered(filter))
+		return
+	}
 	respondAsJSON(w, r, s.metricSource)
 }
 
+// metricsFilter returns a filter accepting the time series names of the
+// metrics requested by the query parameters of a request to
+// statusMetricsPattern: those starting with any of the prefixes or listed in
+// the names. It returns nil if no metrics were requested, in which case all
+// metrics are returned.
+func metricsFilter(query url.Values) func(name string) bool {
+	prefixes := query[statusMetricsPrefixParam]
+	names := make(map[string]struct{})
+	for _, list := range query[statusMetricsNamesParam] {
+		for _, name := range strings.Split(list, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names[name] = struct{}{}
+			}
+		}
+	}
+	if len(prefixes) == 0 && len(names) == 0 {
+		return nil
+	}
+	return func(name string) bool {
+		if _, ok := names[name]; ok {
+			return true
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// handleMetricsMetadata handles GET requests for the metadata of the metrics,
+// keyed by the names of their time series. The metadata is the same on all
+// nodes.
+func (s *statusServer) handleMetricsMetadata(w http.ResponseWriter, r *http.Request) {
+	respondAsJSON(w, r, s.metricSource.MetricsMetadata())
+}
+
+// handleMetricsAggregate handles GET requests for the store-level metrics of
+// the local node aggregated across its stores, keyed by the names of their
+// time series.
+func (s *statusServer) handleMetricsAggregate(w http.ResponseWriter, r *http.Request) {
+	respondAsJSON(w, r, s.metricSource.AggregatedStoreMetrics(metricsFilter(r.URL.Query())))
+}
+
+// handleVars handles GET requests for the metrics of the local node in the
+// Prometheus text exposition format.
+func (s *statusServer) handleVars(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var buf bytes.Buffer
+	if err := s.metricSource.PrintAsPrometheus(&buf); err != nil {
+		log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set(util.ContentTypeHeader, metric.PrometheusContentType)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Error(err)
+	}
+}
+
+// handleDiagnostics handles GET requests for a node's diagnostic report.
+func (s *statusServer) handleDiagnostics(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	nodeID, local, err := s.extractNodeID(ps)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !local {
+		s.proxyRequest(nodeID, w, r)
+		return
+	}
+	report, err := s.diagnostics.report()
+	if err != nil {
+		log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondAsJSON(w, r, report)
+}
+
+// HotRangesStore lists the hottest ranges of a single store.
+type HotRangesStore struct {
+	StoreID roachpb.StoreID      `json:"storeID"`
+	Ranges  []storage.HotReplica `json:"ranges"`
+}
+
+// HotRangesResponse is the response of the hot ranges endpoint.
+type HotRangesResponse struct {
+	NodeID roachpb.NodeID   `json:"nodeID"`
+	Stores []HotRangesStore `json:"stores"`
+}
+
+// handleHotRanges handles GET requests for the ranges of a node with the
+// highest request rates, as measured over the last minute. The "count"
+// query parameter limits the number of ranges returned per store, and
+// setting "keys" includes a sample of the keys requested in each range.
+func (s *statusServer) handleHotRanges(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	nodeID, local, err := s.extractNodeID(ps)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !local {
+		s.proxyRequest(nodeID, w, r)
+		return
+	}
+
+	count := defaultHotRangesCount
+	if countStr := r.URL.Query().Get("count"); len(countStr) > 0 {
+		if count, err = strconv.Atoi(countStr); err != nil || count <= 0 {
+			http.Error(w, fmt.Sprintf("count %q must be a positive integer", countStr), http.StatusBadRequest)
+			return
+		}
+	}
+	withKeys := false
+	if keysStr := r.URL.Query().Get("keys"); len(keysStr) > 0 {
+		if withKeys, err = strconv.ParseBool(keysStr); err != nil {
+			http.Error(w, fmt.Sprintf("keys %q must be a boolean", keysStr), http.StatusBadRequest)
+			return
+		}
+	}
+
+	resp := HotRangesResponse{NodeID: s.gossip.GetNodeID()}
+	if err := s.stores.VisitStores(func(store *storage.Store) error {
+		resp.Stores = append(resp.Stores, HotRangesStore{
+			StoreID: store.StoreID(),
+			Ranges:  store.HotReplicas(count, withKeys),
+		})
+		return nil
+	}); err != nil {
+		log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondAsJSON(w, r, resp)
+}
+
+// ExpiredTxnsStore lists the expired transaction records of a single store.
+type ExpiredTxnsStore struct {
+	StoreID roachpb.StoreID            `json:"storeID"`
+	Txns    []storage.ExpiredTxnRecord `json:"txns"`
+}
+
+// ExpiredTxnsResponse is the response of the expired transactions endpoint.
+type ExpiredTxnsResponse struct {
+	NodeID roachpb.NodeID     `json:"nodeID"`
+	Stores []ExpiredTxnsStore `json:"stores"`
+}
+
+// handleExpiredTxns handles GET requests for the transaction records of a
+// node whose coordinator stopped heartbeating them, along with the number
+// of intents they list. Only the ranges for which the node holds the
+// leader lease are considered.
+func (s *statusServer) handleExpiredTxns(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	nodeID, local, err := s.extractNodeID(ps)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !local {
+		s.proxyRequest(nodeID, w, r)
+		return
+	}
+
+	resp := ExpiredTxnsResponse{NodeID: s.gossip.GetNodeID()}
+	if err := s.stores.VisitStores(func(store *storage.Store) error {
+		txns, err := store.ExpiredTxnRecords()
+		if err != nil {
+			return err
+		}
+		resp.Stores = append(resp.Stores, ExpiredTxnsStore{
+			StoreID: store.StoreID(),
+			Txns:    txns,
+		})
+		return nil
+	}); err != nil {
+		log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondAsJSON(w, r, resp)
+}
+
+// SlowRequestsResponse is the response of the slow requests endpoint.
+type SlowRequestsResponse struct {
+	NodeID   roachpb.NodeID        `json:"nodeID"`
+	Requests []tracing.SlowRequest `json:"requests"`
+}
+
+// handleSlowRequests handles GET requests for the traces of the slowest
+// requests served by a node, slowest first. Traces are only retained if
+// COCKROACH_SLOW_REQUEST_THRESHOLD is set.
+func (s *statusServer) handleSlowRequests(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	nodeID, local, err := s.extractNodeID(ps)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !local {
+		s.proxyRequest(nodeID, w, r)
+		return
+	}
+	respondAsJSON(w, r, SlowRequestsResponse{
+		NodeID:   s.gossip.GetNodeID(),
+		Requests: s.slowRequests.Requests(),
+	})
+}
+
+// TasksResponse is the response of the tasks endpoint.
+type TasksResponse struct {
+	NodeID roachpb.NodeID  `json:"nodeID"`
+	Tasks  []stop.TaskInfo `json:"tasks"`
+}
+
+// handleTasks handles GET requests for the tasks running on a node, longest
+// running first.
+func (s *statusServer) handleTasks(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	nodeID, local, err := s.extractNodeID(ps)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !local {
+		s.proxyRequest(nodeID, w, r)
+		return
+	}
+	respondAsJSON(w, r, TasksResponse{
+		NodeID: s.gossip.GetNodeID(),
+		Tasks:  s.stopper.Tasks(),
+	})
+}
+
+// ConfigResponse is the response of the config endpoint.
+type ConfigResponse struct {
+	NodeID roachpb.NodeID         `json:"nodeID"`
+	Config map[string]ConfigValue `json:"config"`
+}
+
+// handleConfig handles GET requests for the effective configuration of a
+// node.
+func (s *statusServer) handleConfig(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	nodeID, local, err := s.extractNodeID(ps)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !local {
+		s.proxyRequest(nodeID, w, r)
+		return
+	}
+	respondAsJSON(w, r, ConfigResponse{
+		NodeID: s.gossip.GetNodeID(),
+		Config: s.ctx.EffectiveConfig(),
+	})
+}
+
+// RangeCacheResponse is the response of the range cache endpoint.
+type RangeCacheResponse struct {
+	NodeID roachpb.NodeID `json:"nodeID"`
+	kv.RangeCacheContents
+}
+
+// handleRangeCache handles GET requests for the contents of the range
+// descriptor and leader caches of a node. The descriptors are paginated: the
+// "limit" query parameter bounds the number of descriptors returned, and the
+// "start" query parameter, the base64 encoded next_start of the previous
+// page, resumes after it.
+func (s *statusServer) handleRangeCache(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	nodeID, local, err := s.extractNodeID(ps)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !local {
+		s.proxyRequest(nodeID, w, r)
+		return
+	}
+
+	limit := defaultRangeCacheLimit
+	if limitStr := r.URL.Query().Get("limit"); len(limitStr) > 0 {
+		if limit, err = strconv.Atoi(limitStr); err != nil || limit <= 0 {
+			http.Error(w, fmt.Sprintf("limit %q must be a positive integer", limitStr), http.StatusBadRequest)
+			return
+		}
+	}
+	start := roachpb.RKeyMin
+	if startStr := r.URL.Query().Get("start"); len(startStr) > 0 {
+		if start, err = base64.StdEncoding.DecodeString(startStr); err != nil {
+			http.Error(w, fmt.Sprintf("start %q must be base64 encoded", startStr), http.StatusBadRequest)
+			return
+		}
+	}
+	respondAsJSON(w, r, RangeCacheResponse{
+		NodeID:             s.gossip.GetNodeID(),
+		RangeCacheContents: s.distSender.RangeCacheContents(start, limit),
+	})
+}
+
+// CertificatesResponse is the response of the certificates endpoint.
+type CertificatesResponse struct {
+	NodeID       roachpb.NodeID             `json:"nodeID"`
+	Certificates []security.CertificateInfo `json:"certificates"`
+}
+
+// handleCertificates handles GET requests for the certificates loaded by a
+// node.
+func (s *statusServer) handleCertificates(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	nodeID, local, err := s.extractNodeID(ps)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !local {
+		s.proxyRequest(nodeID, w, r)
+		return
+	}
+	respondAsJSON(w, r, CertificatesResponse{
+		NodeID:       s.gossip.GetNodeID(),
+		Certificates: s.certificates,
+	})
+}
+
+// certificateMetrics returns a registry of gauges holding, for each usage
+// of the certificates, the earliest expiration of the certificates with
+// that usage.
+func certificateMetrics(certs []security.CertificateInfo) *metric.Registry {
+	registry := metric.NewRegistry()
+	expirations := map[string]time.Time{}
+	for _, c := range certs {
+		if e, ok := expirations[c.Usage]; !ok || c.NotAfter.Before(e) {
+			expirations[c.Usage] = c.NotAfter
+		}
+	}
+	for usage, e := range expirations {
+		name := "certificate.expiration." + usage
+		registry.Gauge(name).Update(e.UnixNano())
+		registry.SetMetadata(name, metric.Metadata{
+			Unit: metric.UnitTimestamp,
+			Help: "Expiration of the " + usage + " certificate",
+		})
+	}
+	return registry
+}
+
 func respondAsJSON(w http.ResponseWriter, r *http.Request, response interface{}) {
 	b, contentType, err := util.MarshalResponse(r, response, []util.EncodingType{util.JSONEncoding})
 	if err != nil {
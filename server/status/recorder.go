@@ -19,6 +19,7 @@ package status
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"strconv"
 	"sync"
 
@@ -39,9 +40,6 @@ const (
 	// nodeTimeSeriesPrefix is the common prefix for time series keys which
 	// record node-specific data.
 	nodeTimeSeriesPrefix = "cr.node.%s"
-	// runtimeStatTimeSeriesFmt is the current format for time series keys which
-	// record runtime system stats on a node.
-	runtimeStatTimeSeriesNameFmt = "cr.node.sys.%s"
 )
 
 type quantile struct {
@@ -152,6 +150,46 @@ func (mr *MetricsRecorder) NodeStarted(desc roachpb.NodeDescriptor, startedAt in
 // MarshalJSON returns an appropriate JSON representation of the current values
 // of the metrics being tracked by this recorder.
 func (mr *MetricsRecorder) MarshalJSON() ([]byte, error) {
+	return mr.marshalJSON(nil)
+}
+
+// filteredMetrics is a view of the metrics of a MetricsRecorder which only
+// includes those whose time series names are accepted by a filter.
+type filteredMetrics struct {
+	mr     *MetricsRecorder
+	filter func(name string) bool
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (fm filteredMetrics) MarshalJSON() ([]byte, error) {
+	return fm.mr.marshalJSON(fm.filter)
+}
+
+// Filtered returns a view of the metrics being tracked by this recorder which
+// is marshaled to JSON like the recorder itself, but only includes the metrics
+// whose time series names (e.g. "cr.node.sql.select.count") are accepted by the
+// filter.
+func (mr *MetricsRecorder) Filtered(filter func(name string) bool) json.Marshaler {
+	return filteredMetrics{mr: mr, filter: filter}
+}
+
+// filterRegistry returns the values of the metrics of the registry whose time
+// series names, formed by the given format, are accepted by the filter. A nil
+// filter accepts all metrics, in which case the registry is returned as is.
+func filterRegistry(reg *metric.Registry, format string, filter func(string) bool) interface{} {
+	if filter == nil {
+		return reg
+	}
+	values := make(map[string]interface{})
+	reg.Each(func(name string, v interface{}) {
+		if filter(fmt.Sprintf(format, name)) {
+			values[name] = v
+		}
+	})
+	return values
+}
+
+func (mr *MetricsRecorder) marshalJSON(filter func(string) bool) ([]byte, error) {
 	mr.mu.Lock()
 	defer mr.mu.Unlock()
 	if mr.mu.nodeID == 0 {
@@ -163,17 +201,103 @@ func (mr *MetricsRecorder) MarshalJSON() ([]byte, error) {
 		return []byte("{}"), nil
 	}
 	topLevel := map[string]interface{}{
-		fmt.Sprintf("node.%d", mr.mu.nodeID): mr.nodeRegistry,
+		fmt.Sprintf("node.%d", mr.mu.nodeID): filterRegistry(mr.nodeRegistry, nodeTimeSeriesPrefix, filter),
 	}
 	// Add collection of stores to top level.
 	storeLevel := map[roachpb.StoreID]interface{}{}
 	for id, reg := range mr.mu.storeRegistries {
-		storeLevel[id] = reg
+		storeLevel[id] = filterRegistry(reg, storeTimeSeriesPrefix, filter)
 	}
 	topLevel["stores"] = storeLevel
 	return json.Marshal(topLevel)
 }
 
+// AggregatedStoreMetrics returns the current values of the store-level
+// metrics tracked by this recorder, aggregated across the stores of the node
+// according to the aggregations set in their registries. The values are keyed
+// by the time series names of the metrics (e.g. "cr.store.livebytes"), and
+// only those accepted by the filter are included. A nil filter accepts all
+// metrics.
+func (mr *MetricsRecorder) AggregatedStoreMetrics(filter func(name string) bool) map[string]float64 {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	registries := make([]*metric.Registry, 0, len(mr.mu.storeRegistries))
+	for _, reg := range mr.mu.storeRegistries {
+		registries = append(registries, reg)
+	}
+	values := make(map[string]float64)
+	for name, v := range metric.Aggregate(registries...) {
+		name = fmt.Sprintf(storeTimeSeriesPrefix, name)
+		if filter == nil || filter(name) {
+			values[name] = v
+		}
+	}
+	return values
+}
+
+// PrintAsPrometheus writes the current values of the metrics being tracked by
+// this recorder to w in the Prometheus text exposition format. Store-level
+// metrics are labeled with the ID of their store.
+func (mr *MetricsRecorder) PrintAsPrometheus(w io.Writer) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	if mr.mu.nodeID == 0 {
+		// We haven't yet processed initialization information; print nothing.
+		if log.V(1) {
+			log.Warning("MetricsRecorder.PrintAsPrometheus() called before NodeID allocation")
+		}
+		return nil
+	}
+	// The node and store metrics are written together so that their names
+	// are checked for collisions.
+	registry := metric.NewRegistry()
+	if err := registry.AddWithLabels("%s", mr.nodeRegistry, nil); err != nil {
+		return err
+	}
+	for id, reg := range mr.mu.storeRegistries {
+		labels := map[string]string{"store": strconv.FormatInt(int64(id), 10)}
+		if err := registry.AddWithLabels("%s", reg, labels); err != nil {
+			return err
+		}
+	}
+	return registry.PrintAsPrometheus(w)
+}
+
+// ExportRegistry returns a Registry containing the metrics tracked by this
+// recorder, for use by a metric.Exporter. Node-level metrics are labeled with
+// the ID of the node, and store-level metrics with the IDs of the node and
+// their store.
+func (mr *MetricsRecorder) ExportRegistry() *metric.Registry {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	nodeID := strconv.FormatInt(int64(mr.mu.nodeID), 10)
+	registry := metric.NewRegistry()
+	registry.MustAddWithLabels("%s", mr.nodeRegistry, map[string]string{"node": nodeID})
+	for id, reg := range mr.mu.storeRegistries {
+		labels := map[string]string{"node": nodeID, "store": strconv.FormatInt(int64(id), 10)}
+		registry.MustAddWithLabels("%s", reg, labels)
+	}
+	return registry
+}
+
+// MetricsMetadata returns the metadata of the metrics tracked by this
+// recorder, keyed by the names of their time series. Stores share the
+// metadata of their metrics.
+func (mr *MetricsRecorder) MetricsMetadata() map[string]metric.Metadata {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	metadata := make(map[string]metric.Metadata)
+	for name, md := range mr.nodeRegistry.Metadata() {
+		metadata[fmt.Sprintf(nodeTimeSeriesPrefix, name)] = md
+	}
+	for _, reg := range mr.mu.storeRegistries {
+		for name, md := range reg.Metadata() {
+			metadata[fmt.Sprintf(storeTimeSeriesPrefix, name)] = md
+		}
+	}
+	return metadata
+}
+
 // GetTimeSeriesData serializes registered metrics for consumption by
 // CockroachDB's time series system.
 func (mr *MetricsRecorder) GetTimeSeriesData() []ts.TimeSeriesData {
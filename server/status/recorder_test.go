@@ -254,14 +254,18 @@ func TestMetricsRecorder(t *testing.T) {
 					addExpected(reg.prefix, data.name+q.suffix, reg.source, 100, data.val)
 				}
 			case "latency":
-				reg.reg.Latency(data.name).RecordValue(data.val)
-				// Latency is simply three histograms (at different resolution
-				// time scales).
+				reg.reg.Latency(data.name).RecordValue(time.Duration(data.val))
+				// Latency is three histograms (at different resolution time
+				// scales) along with the rates of the recorded values.
 				for _, scale := range metric.DefaultTimeScales {
 					for _, q := range recordHistogramQuantiles {
 						addExpected(reg.prefix, data.name+sep+scale.Name()+q.suffix, reg.source, 100, data.val)
 					}
 				}
+				addExpected(reg.prefix, data.name+"-rate-count", reg.source, 100, 1)
+				for _, scale := range metric.DefaultTimeScales {
+					addExpected(reg.prefix, data.name+"-rate"+sep+scale.Name(), reg.source, 100, 0)
+				}
 			}
 		}
 	}
@@ -270,7 +274,7 @@ func TestMetricsRecorder(t *testing.T) {
 
 	// Zero-out timing-sensitive rate values from actual data.
 	for _, act := range actual {
-		match, err := regexp.MatchString(`testRate-\d+m`, act.Name)
+		match, err := regexp.MatchString(`(testRate|testLatency-rate)-\d+m`, act.Name)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -348,3 +352,38 @@ func TestMetricsRecorder(t *testing.T) {
 		t.Errorf("recorder did not produce expected StoreSummaries; diff:\n %v", pretty.Diff(e, a))
 	}
 }
+
+// TestMetricsRecorderReplaceStore verifies that a store added again, e.g.
+// after it was restarted, replaces the metrics of its previous incarnation.
+func TestMetricsRecorderReplaceStore(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	manual := hlc.NewManualClock(100)
+	recorder := NewMetricsRecorder(hlc.NewClock(manual.UnixNano))
+	old := fakeStore{storeID: roachpb.StoreID(1), registry: metric.NewRegistry()}
+	old.registry.Gauge("old").Update(1)
+	recorder.AddStore(old)
+	recorder.NodeStarted(roachpb.NodeDescriptor{NodeID: roachpb.NodeID(1)}, 50)
+
+	restarted := fakeStore{storeID: roachpb.StoreID(1), registry: metric.NewRegistry()}
+	restarted.registry.Gauge("new").Update(2)
+	recorder.AddStore(restarted)
+
+	var series []string
+	for _, data := range recorder.GetTimeSeriesData() {
+		if data.Source == "1" && regexp.MustCompile(`^cr\.store\.`).MatchString(data.Name) {
+			series = append(series, data.Name)
+		}
+	}
+	if expected := []string{"cr.store.new"}; !reflect.DeepEqual(series, expected) {
+		t.Errorf("expected time series %s, got %s", expected, series)
+	}
+
+	var exported []string
+	recorder.ExportRegistry().Each(func(name string, _ interface{}) {
+		exported = append(exported, name)
+	})
+	if expected := []string{"new"}; !reflect.DeepEqual(exported, expected) {
+		t.Errorf("expected exported metrics %s, got %s", expected, exported)
+	}
+}
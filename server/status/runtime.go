@@ -17,89 +17,107 @@
 package status
 
 import (
-	"fmt"
+	"io/ioutil"
+	"os"
 	"runtime"
-	"strconv"
+	"sync"
 	"syscall"
 	"time"
 
-	"github.com/cockroachdb/cockroach/roachpb"
-	"github.com/cockroachdb/cockroach/ts"
+	"github.com/elastic/gosigar"
+
 	"github.com/cockroachdb/cockroach/util/hlc"
 	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/metric"
 )
 
 const (
-	nameCgoCalls       = "cgocalls"
-	nameGoroutines     = "goroutines"
-	nameAllocBytes     = "allocbytes"
-	nameGCCount        = "gc.count"
-	nameGCPauseNS      = "gc.pause.ns"
-	nameGCPausePercent = "gc.pause.percent"
-	nameCPUUserNS      = "cpu.user.ns"
-	nameCPUUserPercent = "cpu.user.percent"
-	nameCPUSysNS       = "cpu.sys.ns"
-	nameCPUSysPercent  = "cpu.sys.percent"
+	nameCgoCalls    = "cgocalls"
+	nameGoroutines  = "goroutines"
+	nameAllocBytes  = "allocbytes"
+	nameGCCount     = "gc.count"
+	nameGCPauseNS   = "gc.pause.ns"
+	nameGCPause     = "gc.pause"
+	nameRSS         = "rss"
+	nameCPUUserNS   = "cpu.user.ns"
+	nameCPUSysNS    = "cpu.sys.ns"
+	nameFDOpen      = "fd.open"
+	procFDDirectory = "/proc/self/fd"
 )
 
-// RuntimeStatRecorder is used to periodically persist useful runtime statistics
-// as time series data. "Runtime statistics" include OS-level statistics (such as
-// memory and CPU usage) and Go runtime statistics (e.g. count of Goroutines).
-type RuntimeStatRecorder struct {
-	nodeID        roachpb.NodeID
-	clock         *hlc.Clock
-	source        string
-	lastDataCount int
-
-	// The last recorded values of some statistics are kept to compute
-	// derivative statistics.
-	lastNow       int64
-	lastUtime     int64
-	lastStime     int64
-	lastPauseTime uint64
-	lastCgoCall   int64
-	lastNumGC     uint32
-}
+// RuntimeStatSampler is used to periodically sample useful runtime statistics
+// into the gauges of a metric registry. "Runtime statistics" include OS-level
+// statistics (such as memory and CPU usage) and Go runtime statistics (e.g.
+// count of Goroutines). The registry is expected to be added to the node
+// registries of a MetricsRecorder, which persists the gauges as time series.
+type RuntimeStatSampler struct {
+	clock *hlc.Clock
 
-// NewRuntimeStatRecorder instantiates a runtime status recorder for the
-// supplied node ID.
-func NewRuntimeStatRecorder(nodeID roachpb.NodeID, clock *hlc.Clock) *RuntimeStatRecorder {
-	return &RuntimeStatRecorder{
-		nodeID: nodeID,
-		clock:  clock,
-		source: strconv.FormatInt(int64(nodeID), 10),
+	CgoCalls   *metric.Gauge
+	Goroutines *metric.Gauge
+	AllocBytes *metric.Gauge
+	GCCount    *metric.Gauge
+	GCPauseNS  *metric.Gauge
+	// GCPause tracks the durations of the individual garbage collection
+	// pauses, so that their quantiles can be exported.
+	GCPause   *metric.Histogram
+	RSS       *metric.Gauge
+	CPUUserNS *metric.Gauge
+	CPUSysNS  *metric.Gauge
+	FDOpen    *metric.Gauge
+
+	mu struct {
+		sync.Mutex
+		// The last sampled values of some statistics are kept to compute
+		// derivative statistics.
+		lastNow       int64
+		lastUtime     int64
+		lastStime     int64
+		lastPauseTime uint64
+		lastCgoCall   int64
+		lastNumGC     uint32
 	}
 }
 
-// recordFloat records a single float64 value recorded from a runtime statistic as a
-// ts.TimeSeriesData object.
-func (rsr *RuntimeStatRecorder) record(timestampNanos int64, name string,
-	data float64) ts.TimeSeriesData {
-	return ts.TimeSeriesData{
-		Name:   fmt.Sprintf(runtimeStatTimeSeriesNameFmt, name),
-		Source: rsr.source,
-		Datapoints: []*ts.TimeSeriesDatapoint{
-			{
-				TimestampNanos: timestampNanos,
-				Value:          data,
-			},
-		},
+// NewRuntimeStatSampler registers the runtime metrics in the supplied
+// registry and returns a RuntimeStatSampler which updates them.
+func NewRuntimeStatSampler(clock *hlc.Clock, r *metric.Registry) *RuntimeStatSampler {
+	rsr := &RuntimeStatSampler{
+		clock:      clock,
+		CgoCalls:   r.Gauge(nameCgoCalls),
+		Goroutines: r.Gauge(nameGoroutines),
+		AllocBytes: r.Gauge(nameAllocBytes),
+		GCCount:    r.Gauge(nameGCCount),
+		GCPauseNS:  r.Gauge(nameGCPauseNS),
+		GCPause:    r.Histogram(nameGCPause, time.Minute, int64(10*time.Second), 2),
+		RSS:        r.Gauge(nameRSS),
+		CPUUserNS:  r.Gauge(nameCPUUserNS),
+		CPUSysNS:   r.Gauge(nameCPUSysNS),
+		FDOpen:     r.Gauge(nameFDOpen),
+	}
+	for name, md := range map[string]metric.Metadata{
+		nameCgoCalls:   {Unit: metric.UnitCount, Cumulative: true, Help: "Number of cgo calls made by the process"},
+		nameGoroutines: {Unit: metric.UnitCount, Help: "Number of goroutines"},
+		nameAllocBytes: {Unit: metric.UnitBytes, Help: "Bytes of allocated heap objects"},
+		nameGCCount:    {Unit: metric.UnitCount, Cumulative: true, Help: "Number of completed garbage collections"},
+		nameGCPauseNS:  {Unit: metric.UnitNanoseconds, Cumulative: true, Help: "Total time spent in garbage collection pauses"},
+		nameGCPause:    {Unit: metric.UnitNanoseconds, Help: "Durations of garbage collection pauses"},
+		nameRSS:        {Unit: metric.UnitBytes, Help: "Resident memory of the process"},
+		nameCPUUserNS:  {Unit: metric.UnitNanoseconds, Cumulative: true, Help: "User CPU time of the process"},
+		nameCPUSysNS:   {Unit: metric.UnitNanoseconds, Cumulative: true, Help: "System CPU time of the process"},
+		nameFDOpen:     {Unit: metric.UnitCount, Help: "Number of open file descriptors"},
+	} {
+		r.SetMetadata(name, md)
 	}
+	return rsr
 }
 
-// GetTimeSeriesData returns a slice of TimeSeriesData updates based on current
-// runtime statistics.
-//
-// Calling this method will query various system packages for runtime statistics
-// and convert the information to time series data. This is currently done in
-// one method because it is convenient; however, in the future querying and
-// recording can be easily separated, similar to the way that NodeStatus is
-// separated into a monitor and a recorder.
-//
-// TODO(tschottdorf): turn various things here into gauges and register them
-// with the metrics registry.
-func (rsr *RuntimeStatRecorder) GetTimeSeriesData() []ts.TimeSeriesData {
-	data := make([]ts.TimeSeriesData, 0, rsr.lastDataCount)
+// SampleEnvironment queries the Go runtime and the OS for the current
+// statistics of the process, updates the metrics accordingly and logs a
+// summary of them.
+func (rsr *RuntimeStatSampler) SampleEnvironment() {
+	rsr.mu.Lock()
+	defer rsr.mu.Unlock()
 
 	// Record memory and call stats from the runtime package.
 	// TODO(mrtracy): memory statistics will not include usage from RocksDB.
@@ -115,39 +133,59 @@ func (rsr *RuntimeStatRecorder) GetTimeSeriesData() []ts.TimeSeriesData {
 		log.Errorf("Getrusage failed: %v", err)
 	}
 
-	// Time statistics can be compared to the total elapsed time to create a
-	// useful percentage of total CPU usage, which would be somewhat less accurate
-	// if calculated later using downsampled time series data.
+	mem := gosigar.ProcMem{}
+	if err := mem.Get(os.Getpid()); err != nil {
+		log.Errorf("unable to get resident memory: %v", err)
+	} else {
+		rsr.RSS.Update(int64(mem.Resident))
+	}
+
+	// Open file descriptors can only be listed where /proc is available.
+	if fds, err := ioutil.ReadDir(procFDDirectory); err != nil {
+		if log.V(1) {
+			log.Infof("unable to list open file descriptors: %v", err)
+		}
+	} else {
+		// Listing the directory opens a file descriptor of its own.
+		rsr.FDOpen.Update(int64(len(fds) - 1))
+	}
+
+	// The runtime only keeps the durations of the most recent pauses, so the
+	// pauses of collections which were overwritten since the last sample are
+	// lost.
+	numPauses := uint32(len(ms.PauseNs))
+	newGCs := ms.NumGC - rsr.mu.lastNumGC
+	if newGCs > numPauses {
+		newGCs = numPauses
+	}
+	for i := uint32(0); i < newGCs; i++ {
+		rsr.GCPause.RecordValue(int64(ms.PauseNs[(ms.NumGC-i+numPauses-1)%numPauses]))
+	}
+
+	// Log summary of statistics to console.
 	now := rsr.clock.PhysicalNow()
-	dur := float64(now - rsr.lastNow)
+	dur := float64(now - rsr.mu.lastNow)
 	newUtime := ru.Utime.Nano()
 	newStime := ru.Stime.Nano()
-	uPerc := float64(newUtime-rsr.lastUtime) / dur
-	sPerc := float64(newStime-rsr.lastStime) / dur
-	pausePerc := float64(ms.PauseTotalNs-rsr.lastPauseTime) / dur
-	rsr.lastNow = now
-	rsr.lastUtime = newUtime
-	rsr.lastStime = newStime
-	rsr.lastPauseTime = ms.PauseTotalNs
-
-	// Log summary of statistics to console, if requested.
+	uPerc := float64(newUtime-rsr.mu.lastUtime) / dur
+	sPerc := float64(newStime-rsr.mu.lastStime) / dur
+	pausePerc := float64(ms.PauseTotalNs-rsr.mu.lastPauseTime) / dur
 	activeMiB := float64(ms.Alloc) / (1 << 20)
-	cgoRate := float64((numCgoCall-rsr.lastCgoCall)*int64(time.Second)) / dur
+	cgoRate := float64((numCgoCall-rsr.mu.lastCgoCall)*int64(time.Second)) / dur
 	log.Infof("runtime stats: %d goroutines, %.2fMiB active, %.2fcgo/sec, %.2f/%.2f %%(u/s)time, %.2f %%gc (%dx)",
-		numGoroutine, activeMiB, cgoRate, uPerc, sPerc, pausePerc, ms.NumGC-rsr.lastNumGC)
-	rsr.lastCgoCall = numCgoCall
-	rsr.lastNumGC = ms.NumGC
-
-	data = append(data, rsr.record(now, nameCgoCalls, float64(numCgoCall)))
-	data = append(data, rsr.record(now, nameGoroutines, float64(numGoroutine)))
-	data = append(data, rsr.record(now, nameAllocBytes, float64(ms.Alloc)))
-	data = append(data, rsr.record(now, nameGCCount, float64(ms.NumGC)))
-	data = append(data, rsr.record(now, nameGCPauseNS, float64(ms.PauseTotalNs)))
-	data = append(data, rsr.record(now, nameGCPausePercent, pausePerc))
-	data = append(data, rsr.record(now, nameCPUUserNS, float64(newUtime)))
-	data = append(data, rsr.record(now, nameCPUUserPercent, uPerc))
-	data = append(data, rsr.record(now, nameCPUSysNS, float64(newStime)))
-	data = append(data, rsr.record(now, nameCPUSysPercent, sPerc))
-	rsr.lastDataCount = len(data)
-	return data
+		numGoroutine, activeMiB, cgoRate, uPerc, sPerc, pausePerc, ms.NumGC-rsr.mu.lastNumGC)
+	rsr.mu.lastNow = now
+	rsr.mu.lastUtime = newUtime
+	rsr.mu.lastStime = newStime
+	rsr.mu.lastPauseTime = ms.PauseTotalNs
+	rsr.mu.lastCgoCall = numCgoCall
+	rsr.mu.lastNumGC = ms.NumGC
+
+	rsr.CgoCalls.Update(numCgoCall)
+	rsr.Goroutines.Update(int64(numGoroutine))
+	rsr.AllocBytes.Update(int64(ms.Alloc))
+	rsr.GCCount.Update(int64(ms.NumGC))
+	rsr.GCPauseNS.Update(int64(ms.PauseTotalNs))
+	rsr.CPUUserNS.Update(newUtime)
+	rsr.CPUSysNS.Update(newStime)
 }
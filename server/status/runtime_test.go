@@ -17,20 +17,31 @@
 package status
 
 import (
+	"runtime"
 	"testing"
 
-	"github.com/cockroachdb/cockroach/roachpb"
 	"github.com/cockroachdb/cockroach/util/hlc"
 	"github.com/cockroachdb/cockroach/util/leaktest"
+	"github.com/cockroachdb/cockroach/util/metric"
 )
 
-func TestRuntimeStatRecorder(t *testing.T) {
+func TestRuntimeStatSampler(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	manual := hlc.NewManualClock(100)
-	recorder := NewRuntimeStatRecorder(roachpb.NodeID(1), hlc.NewClock(manual.UnixNano))
+	r := metric.NewRegistry()
+	rsr := NewRuntimeStatSampler(hlc.NewClock(manual.UnixNano), r)
+	runtime.GC()
+	rsr.SampleEnvironment()
 
-	data := recorder.GetTimeSeriesData()
-	if a, e := len(data), 10; a != e {
-		t.Fatalf("Expected %d series generated, got %d", a, e)
+	for _, name := range []string{nameGoroutines, nameAllocBytes, nameGCCount, nameRSS} {
+		if g := r.GetGauge(name); g == nil || g.Value() <= 0 {
+			t.Errorf("expected a positive value for %s, got %v", name, g)
+		}
+	}
+	if rsr.GCPause.Current().TotalCount() == 0 {
+		t.Error("expected the pause of the collection to be recorded")
+	}
+	if md := r.Metadata()[nameAllocBytes]; md.Unit != metric.UnitBytes {
+		t.Errorf("expected %s to be described in bytes, got %+v", nameAllocBytes, md)
 	}
 }
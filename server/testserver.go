@@ -130,6 +130,9 @@ func NewTestContext() *Context {
 	ctx.HTTPAddr = "127.0.0.1:0"
 	// Set standard user for intra-cluster traffic.
 	ctx.User = security.NodeUser
+	// Check that the requests the test servers serve to themselves aren't
+	// modified, which would go unnoticed over gRPC.
+	ctx.TestingMocker.VerifyLocalCalls = true
 
 	return ctx
 }
@@ -293,6 +296,15 @@ func (ts *TestServer) ServingAddr() string {
 	return ts.ctx.Addr
 }
 
+// SQLAddr returns the address on which the server serves SQL clients, which
+// is its serving address unless Ctx.SQLAddr is set.
+func (ts *TestServer) SQLAddr() string {
+	if ts.ctx.SQLAddr != "" {
+		return ts.ctx.SQLAddr
+	}
+	return ts.ctx.Addr
+}
+
 // HTTPAddr returns the server's HTTP address. Should be used by humans.
 func (ts *TestServer) HTTPAddr() string {
 	return ts.ctx.HTTPAddr
@@ -334,39 +346,172 @@ func (ts *TestServer) WriteSummaries() error {
 	return ts.node.writeSummaries()
 }
 
-// MustGetSQLCounter returns the value of a counter metric from the server's SQL
-// Executor. Runs in O(# of metrics) time, which is fine for test code.
-func (ts *TestServer) MustGetSQLCounter(name string) int64 {
-	var c int64
+// Engines returns the engines backing this TestServer's stores. Tests may
+// use them to inspect or manipulate on-disk state directly; writes made
+// while the server is running bypass Raft and must be used with care.
+func (ts *TestServer) Engines() []engine.Engine {
+	return ts.Ctx.Engines
+}
+
+// LookupRange returns the descriptor of the range containing the given key,
+// as recorded in the range addressing records.
+func (ts *TestServer) LookupRange(key roachpb.Key) (roachpb.RangeDescriptor, error) {
+	var desc roachpb.RangeDescriptor
+	rows, pErr := ts.DB().Scan(keys.RangeMetaKey(keys.Addr(key)).Next(), keys.MetaMax, 1)
+	if pErr != nil {
+		return desc, pErr.GoError()
+	}
+	if len(rows) == 0 {
+		return desc, util.Errorf("no range found for key %s", key)
+	}
+	if err := rows[0].ValueProto(&desc); err != nil {
+		return desc, err
+	}
+	return desc, nil
+}
+
+// SplitRange splits the range containing splitKey at splitKey and returns
+// the descriptors of the resulting left and right ranges.
+func (ts *TestServer) SplitRange(splitKey roachpb.Key) (roachpb.RangeDescriptor, roachpb.RangeDescriptor, error) {
+	var left roachpb.RangeDescriptor
+	if pErr := ts.DB().AdminSplit(splitKey); pErr != nil {
+		return left, roachpb.RangeDescriptor{}, pErr.GoError()
+	}
+	// The addressing record of the left range is keyed by its end key, which
+	// is the split key.
+	if pErr := ts.DB().GetProto(keys.RangeMetaKey(keys.Addr(splitKey)), &left); pErr != nil {
+		return left, roachpb.RangeDescriptor{}, pErr.GoError()
+	}
+	right, err := ts.LookupRange(splitKey)
+	if err != nil {
+		return left, right, err
+	}
+	if !left.EndKey.Equal(right.StartKey) {
+		return left, right, util.Errorf("split at %s produced non-adjacent ranges %s and %s",
+			splitKey, left, right)
+	}
+	return left, right, nil
+}
+
+// TransferLease requests a leader lease on the range containing key for the
+// given replica of that range. Since leases cannot be revoked, the new lease
+// begins right after the current one expires; until then, the current lease
+// holder continues to serve the range.
+func (ts *TestServer) TransferLease(key roachpb.Key, target roachpb.ReplicaDescriptor) error {
+	desc, err := ts.LookupRange(key)
+	if err != nil {
+		return err
+	}
+	if idx, _ := desc.FindReplica(target.StoreID); idx == -1 {
+		return util.Errorf("store %d has no replica of range %s", target.StoreID, desc)
+	}
+
+	// Read the current lease from whichever local store holds a replica.
+	var lease roachpb.Lease
 	var found bool
+	if err := ts.Stores().VisitStores(func(s *storage.Store) error {
+		if found {
+			return nil
+		}
+		if _, err := s.GetReplica(desc.RangeID); err != nil {
+			return nil
+		}
+		found = true
+		_, err := engine.MVCCGetProto(s.Engine(), keys.RangeLeaderLeaseKey(desc.RangeID),
+			roachpb.ZeroTimestamp, true, nil, &lease)
+		return err
+	}); err != nil {
+		return err
+	}
+	if !found {
+		return util.Errorf("no local replica of range %s", desc)
+	}
+	if lease.Replica.StoreID == target.StoreID {
+		return nil
+	}
+
+	start := ts.Clock().Now()
+	if next := lease.Expiration.Next(); start.Less(next) {
+		start = next
+	}
+	b := ts.DB().NewBatch()
+	b.InternalAddRequest(&roachpb.LeaderLeaseRequest{
+		Span: roachpb.Span{
+			Key: desc.StartKey.AsRawKey(),
+		},
+		Lease: roachpb.Lease{
+			Start:      start,
+			Expiration: start.Add(int64(storage.DefaultLeaderLeaseDuration), 0),
+			Replica:    target,
+		},
+	})
+	return ts.DB().Run(b).GoError()
+}
 
-	ts.sqlExecutor.Registry().Each(func(n string, v interface{}) {
+// mustGetMetric returns the metric with the given name in the registry,
+// including metrics of nested registries. It panics if no such metric
+// exists. Runs in O(# of metrics) time, which is fine for test code.
+func mustGetMetric(registry *metric.Registry, name string) metric.Metric {
+	var m metric.Metric
+	registry.EachMetric(func(n string, v metric.Metric) {
 		if name == n {
-			c = v.(*metric.Counter).Count()
-			found = true
+			m = v
 		}
 	})
-	if !found {
+	if m == nil {
 		panic(fmt.Sprintf("couldn't find metric %s", name))
 	}
-	return c
+	return m
+}
+
+// MustGetCounter returns the value of the counter with the given name in the
+// registry. It panics if no such counter exists.
+func MustGetCounter(registry *metric.Registry, name string) int64 {
+	c, ok := mustGetMetric(registry, name).(*metric.Counter)
+	if !ok {
+		panic(fmt.Sprintf("metric %s is not a counter", name))
+	}
+	return c.Count()
+}
+
+// MustGetGauge returns the value of the gauge with the given name in the
+// registry. It panics if no such gauge exists.
+func MustGetGauge(registry *metric.Registry, name string) int64 {
+	g, ok := mustGetMetric(registry, name).(*metric.Gauge)
+	if !ok {
+		panic(fmt.Sprintf("metric %s is not a gauge", name))
+	}
+	return g.Value()
+}
+
+// MustGetSQLCounter returns the value of a counter metric from the server's SQL
+// Executor.
+func (ts *TestServer) MustGetSQLCounter(name string) int64 {
+	return MustGetCounter(ts.sqlExecutor.Registry(), name)
 }
 
 // MustGetSQLNetworkCounter returns the value of a counter metric from the
-// server's SQL server. Runs in O(# of metrics) time, which is fine for test
-// code.
+// server's SQL server.
 func (ts *TestServer) MustGetSQLNetworkCounter(name string) int64 {
-	var c int64
-	var found bool
+	return MustGetCounter(ts.pgServer.Registry(), name)
+}
 
-	ts.pgServer.Registry().Each(func(n string, v interface{}) {
-		if name == n {
-			c = v.(*metric.Counter).Count()
-			found = true
-		}
-	})
-	if !found {
-		panic(fmt.Sprintf("couldn't find metric %s", name))
+// MustGetStoreCounter returns the value of a counter metric from the store
+// with the given ID.
+func (ts *TestServer) MustGetStoreCounter(storeID roachpb.StoreID, name string) int64 {
+	return MustGetCounter(ts.mustGetStore(storeID).Registry(), name)
+}
+
+// MustGetStoreGauge returns the value of a gauge metric from the store with
+// the given ID.
+func (ts *TestServer) MustGetStoreGauge(storeID roachpb.StoreID, name string) int64 {
+	return MustGetGauge(ts.mustGetStore(storeID).Registry(), name)
+}
+
+func (ts *TestServer) mustGetStore(storeID roachpb.StoreID) *storage.Store {
+	s, err := ts.Stores().GetStore(storeID)
+	if err != nil {
+		panic(err)
 	}
-	return c
+	return s
 }
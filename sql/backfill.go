@@ -163,7 +163,7 @@ func (p *planner) backfillBatch(b *client.Batch, oldTableDesc *TableDescriptor,
 			desc:    *oldTableDesc,
 		}
 		scan.initDescDefaults()
-		rows := p.selectIndex(&selectNode{}, scan, nil, false)
+		rows := p.selectIndex(&selectNode{}, scan, nil, false, 0)
 
 		// Construct a map from column ID to the index the value appears at within a
 		// row.
@@ -175,20 +175,20 @@ func (p *planner) backfillBatch(b *client.Batch, oldTableDesc *TableDescriptor,
 		for rows.Next() {
 			rowVals := rows.Values()
 
-			for _, newIndexDesc := range newIndexDescs {
-				secondaryIndexEntries, err := encodeSecondaryIndexes(
-					oldTableDesc.ID, []IndexDescriptor{newIndexDesc}, colIDtoRowIndex, rowVals)
-				if err != nil {
-					return roachpb.NewError(err)
-				}
+			// Encode the entries of all the new indexes at once; a uniqueness
+			// violation is attributed to its index by convertBatchError.
+			secondaryIndexEntries, err := encodeSecondaryIndexes(
+				oldTableDesc.ID, newIndexDescs, colIDtoRowIndex, rowVals)
+			if err != nil {
+				return roachpb.NewError(err)
+			}
 
-				for _, secondaryIndexEntry := range secondaryIndexEntries {
-					if log.V(2) {
-						log.Infof("CPut %s -> %v", secondaryIndexEntry.key,
-							secondaryIndexEntry.value)
-					}
-					b.CPut(secondaryIndexEntry.key, secondaryIndexEntry.value, nil)
+			for _, secondaryIndexEntry := range secondaryIndexEntries {
+				if log.V(2) {
+					log.Infof("CPut %s -> %v", secondaryIndexEntry.key,
+						secondaryIndexEntry.value)
 				}
+				b.CPut(secondaryIndexEntry.key, secondaryIndexEntry.value, nil)
 			}
 		}
 
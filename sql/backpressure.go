@@ -0,0 +1,145 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/gossip"
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/metric"
+)
+
+const (
+	// maxWriteBackpressureDelay is the longest a write statement is delayed
+	// while storage engines of the cluster stall writes.
+	maxWriteBackpressureDelay = time.Second
+	// writeBackpressurePollInterval is the interval at which a delayed write
+	// statement checks whether the engines stopped stalling writes.
+	writeBackpressurePollInterval = 50 * time.Millisecond
+)
+
+// A writeBackpressure slows down SQL writes while storage engines of the
+// cluster stall writes because compactions are falling behind. The stores
+// gossip whether their engines stall writes, and since the gateway can't
+// tell in advance which stores hold the leases of the ranges a statement
+// writes to, writes are delayed while any store of the cluster stalls.
+// Delaying writes at admission, rather than letting them pile up in the
+// engines, keeps the latency of reads from collapsing and gives compactions
+// a chance to catch up.
+type writeBackpressure struct {
+	maxDelay     time.Duration
+	pollInterval time.Duration
+
+	delayed metric.Latency
+
+	mu struct {
+		sync.Mutex
+		// stalled holds the stores whose engines are stalling writes.
+		stalled map[roachpb.StoreID]struct{}
+	}
+}
+
+func newWriteBackpressure(registry *metric.Registry) *writeBackpressure {
+	b := &writeBackpressure{
+		maxDelay:     maxWriteBackpressureDelay,
+		pollInterval: writeBackpressurePollInterval,
+		delayed:      registry.Latency("backpressure.delay"),
+	}
+	b.mu.stalled = map[roachpb.StoreID]struct{}{}
+	return b
+}
+
+// gossipUpdate is the gossip callback recording whether the engines of the
+// stores are stalling writes.
+func (b *writeBackpressure) gossipUpdate(key string, content roachpb.Value) {
+	id := strings.TrimPrefix(key, gossip.MakeKey(gossip.KeyStoreWriteStalledPrefix, ""))
+	storeID, err := strconv.ParseInt(id, 10, 32)
+	if err != nil {
+		log.Errorf("invalid store write stalled gossip key %q: %s", key, err)
+		return
+	}
+	v, err := content.GetBytes()
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(v) == 1 && v[0] == 1 {
+		b.mu.stalled[roachpb.StoreID(storeID)] = struct{}{}
+	} else {
+		delete(b.mu.stalled, roachpb.StoreID(storeID))
+	}
+}
+
+// writeStalled returns whether the engine of any store of the cluster is
+// stalling writes.
+func (b *writeBackpressure) writeStalled() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.mu.stalled) > 0
+}
+
+// isWrite returns whether the statement writes rows.
+func isWrite(stmt parser.Statement) bool {
+	switch stmt.(type) {
+	case *parser.Insert, *parser.Update, *parser.Delete, *parser.Truncate:
+		return true
+	}
+	return false
+}
+
+// admitStatement delays write statements while engines of the cluster stall
+// writes, for at most maxDelay or until the context is done. Statements of
+// transactions which already hold writes aren't delayed, as that would only
+// make them hold on to their intents longer. It returns how long the
+// statement was delayed.
+func (b *writeBackpressure) admitStatement(
+	ctx context.Context, stmt parser.Statement, holdsWrites bool,
+) time.Duration {
+	if holdsWrites || !isWrite(stmt) || !b.writeStalled() {
+		return 0
+	}
+	start := time.Now()
+wait:
+	for {
+		remaining := b.maxDelay - time.Since(start)
+		if remaining <= 0 {
+			break
+		}
+		if remaining > b.pollInterval {
+			remaining = b.pollInterval
+		}
+		select {
+		case <-time.After(remaining):
+		case <-ctx.Done():
+			break wait
+		}
+		if !b.writeStalled() {
+			break
+		}
+	}
+	delay := time.Since(start)
+	b.delayed.RecordValue(delay)
+	return delay
+}
@@ -0,0 +1,100 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/gossip"
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+	"github.com/cockroachdb/cockroach/util/metric"
+)
+
+func TestWriteBackpressure(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	b := newWriteBackpressure(metric.NewRegistry())
+	b.maxDelay = 20 * time.Millisecond
+	b.pollInterval = time.Millisecond
+
+	setStalled := func(storeID roachpb.StoreID, stalled bool) {
+		val := []byte{0}
+		if stalled {
+			val[0] = 1
+		}
+		b.gossipUpdate(gossip.MakeStoreWriteStalledKey(storeID), roachpb.MakeValueFromBytes(val))
+	}
+	parse := func(sql string) parser.Statement {
+		stmt, err := parser.ParseOneTraditional(sql)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return stmt
+	}
+	ctx := context.Background()
+
+	// Nothing is delayed until a store stalls writes.
+	if d := b.admitStatement(ctx, parse(`INSERT INTO t VALUES (1)`), false); d != 0 {
+		t.Errorf("expected writes not to be delayed, got %s", d)
+	}
+	setStalled(1, true)
+	setStalled(2, true)
+
+	// Reads are never delayed, nor are the statements of transactions which
+	// already hold writes.
+	if d := b.admitStatement(ctx, parse(`SELECT * FROM t`), false); d != 0 {
+		t.Errorf("expected reads not to be delayed, got %s", d)
+	}
+	if d := b.admitStatement(ctx, parse(`INSERT INTO t VALUES (1)`), true); d != 0 {
+		t.Errorf("expected writing transactions not to be delayed, got %s", d)
+	}
+
+	// Writes are delayed for at most maxDelay while any store stalls.
+	for _, sql := range []string{
+		`INSERT INTO t VALUES (1)`,
+		`UPDATE t SET a = 1`,
+		`DELETE FROM t`,
+	} {
+		if d := b.admitStatement(ctx, parse(sql), false); d < b.maxDelay {
+			t.Errorf("%s: expected a delay of at least %s, got %s", sql, b.maxDelay, d)
+		}
+	}
+	setStalled(1, false)
+	if d := b.admitStatement(ctx, parse(`INSERT INTO t VALUES (1)`), false); d < b.maxDelay {
+		t.Errorf("expected a delay of at least %s while a store stalls, got %s", b.maxDelay, d)
+	}
+
+	// Writes proceed as soon as the stores stop stalling, or once the
+	// context is done.
+	b.maxDelay = time.Minute
+	time.AfterFunc(10*time.Millisecond, func() { setStalled(2, false) })
+	if d := b.admitStatement(ctx, parse(`INSERT INTO t VALUES (1)`), false); d >= b.maxDelay {
+		t.Errorf("expected the delay to end once writes are no longer stalled, got %s", d)
+	}
+	if d := b.admitStatement(ctx, parse(`INSERT INTO t VALUES (1)`), false); d != 0 {
+		t.Errorf("expected writes not to be delayed, got %s", d)
+	}
+	setStalled(1, true)
+	cancelCtx, cancel := context.WithCancel(ctx)
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if d := b.admitStatement(cancelCtx, parse(`INSERT INTO t VALUES (1)`), false); d >= b.maxDelay {
+		t.Errorf("expected the delay to end once the context is done, got %s", d)
+	}
+}
@@ -17,6 +17,11 @@
 package sql
 
 import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v1"
+
 	"github.com/cockroachdb/cockroach/config"
 	"github.com/cockroachdb/cockroach/keys"
 )
@@ -29,14 +34,23 @@ func init() {
 
 // GetZoneConfig returns the zone config for the object with 'id'.
 func GetZoneConfig(cfg config.SystemConfig, id uint32) (*config.ZoneConfig, error) {
+	zone, _, err := ResolveZoneConfig(cfg, id)
+	return zone, err
+}
+
+// ResolveZoneConfig returns the zone config which applies to the object with
+// 'id' along with the ID of the object the zone config is set on. Objects
+// without a zone config inherit the one of their database, or else the
+// default zone config.
+func ResolveZoneConfig(cfg config.SystemConfig, id uint32) (*config.ZoneConfig, uint32, error) {
 	// Look in the zones table.
 	if zoneVal := cfg.GetValue(MakeZoneKey(ID(id))); zoneVal != nil {
 		zone := &config.ZoneConfig{}
 		if err := zoneVal.GetProto(zone); err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 		// We're done.
-		return zone, nil
+		return zone, id, nil
 	}
 
 	// No zone config for this ID. We need to figure out if it's a database
@@ -45,20 +59,70 @@ func GetZoneConfig(cfg config.SystemConfig, id uint32) (*config.ZoneConfig, erro
 		// Determine whether this is a database or table.
 		desc := &Descriptor{}
 		if err := descVal.GetProto(desc); err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 		if tableDesc := desc.GetTable(); tableDesc != nil {
 			// This is a table descriptor. Lookup its parent database zone config.
-			return GetZoneConfig(cfg, uint32(tableDesc.ParentID))
+			return ResolveZoneConfig(cfg, uint32(tableDesc.ParentID))
 		}
 	}
 
 	// Retrieve the default zone config, but only as long as that wasn't the ID
 	// we were trying to retrieve (avoid infinite recursion).
 	if id != keys.RootNamespaceID {
-		return GetZoneConfig(cfg, keys.RootNamespaceID)
+		return ResolveZoneConfig(cfg, keys.RootNamespaceID)
 	}
 
 	// No descriptor or not a table.
-	return nil, nil
+	return nil, 0, nil
+}
+
+// ResolveTableZoneConfig is like ResolveZoneConfig, but looks up the table by
+// its name and the name of its database.
+func ResolveTableZoneConfig(
+	cfg config.SystemConfig, database, table string) (*config.ZoneConfig, uint32, error) {
+	dbID := systemDB.ID
+	if !equalName(database, systemDB.Name) {
+		nameVal := cfg.GetValue(databaseKey{database}.Key())
+		if nameVal == nil {
+			return nil, 0, fmt.Errorf("database %q does not exist", database)
+		}
+		id, err := nameVal.GetInt()
+		if err != nil {
+			return nil, 0, err
+		}
+		dbID = ID(id)
+	}
+	nameVal := cfg.GetValue(tableKey{dbID, table}.Key())
+	if nameVal == nil {
+		return nil, 0, fmt.Errorf("table %q does not exist", table)
+	}
+	id, err := nameVal.GetInt()
+	if err != nil {
+		return nil, 0, err
+	}
+	return ResolveZoneConfig(cfg, uint32(id))
+}
+
+// getZoneConfig returns the zone config which applies to the table with the
+// given name, formatted as YAML. The name is either "database.table" or a
+// table in the session's database.
+func (p *planner) getZoneConfig(name string) (string, error) {
+	database := p.session.Database
+	table := name
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		database, table = name[:i], name[i+1:]
+	}
+	if database == "" {
+		return "", errNoDatabase
+	}
+	zone, _, err := ResolveTableZoneConfig(p.systemConfig, database, table)
+	if err != nil {
+		return "", err
+	}
+	out, err := yaml.Marshal(zone)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
 }
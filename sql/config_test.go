@@ -18,6 +18,7 @@ package sql_test
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/client"
@@ -25,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/keys"
 	"github.com/cockroachdb/cockroach/roachpb"
 	"github.com/cockroachdb/cockroach/sql"
+	"github.com/cockroachdb/cockroach/testutils"
 	"github.com/cockroachdb/cockroach/util"
 	"github.com/cockroachdb/cockroach/util/leaktest"
 	"github.com/gogo/protobuf/proto"
@@ -229,4 +231,44 @@ func TestGetZoneConfig(t *testing.T) {
 			t.Errorf("#%d: bad zone config.\nexpected: %+v\ngot: %+v", tcNum, tc.zoneCfg, zoneCfg)
 		}
 	}
+	// Resolving the zone config of a table by name also returns the object
+	// it is inherited from.
+	nameTestCases := []struct {
+		database, table string
+		zoneCfg         *config.ZoneConfig
+		zoneID          uint32
+	}{
+		{"db1", "tb1", &tb11Cfg, tb11},
+		{"db1", "tb2", &db1Cfg, db1},
+		{"db2", "tb1", &tb21Cfg, tb21},
+		{"db2", "tb2", &defaultZoneConfig, keys.RootNamespaceID},
+		{"system", "zones", &defaultZoneConfig, keys.RootNamespaceID},
+	}
+	for tcNum, tc := range nameTestCases {
+		zoneCfg, zoneID, err := sql.ResolveTableZoneConfig(*cfg, tc.database, tc.table)
+		if err != nil {
+			t.Fatalf("#%d: err=%s", tcNum, err)
+		}
+		if zoneID != tc.zoneID {
+			t.Errorf("#%d: expected zone config of %d, got %d", tcNum, tc.zoneID, zoneID)
+		}
+		if !reflect.DeepEqual(zoneCfg, tc.zoneCfg) {
+			t.Errorf("#%d: bad zone config.\nexpected: %+v\ngot: %+v", tcNum, tc.zoneCfg, zoneCfg)
+		}
+	}
+	if _, _, err := sql.ResolveTableZoneConfig(*cfg, "db1", "tb3"); !testutils.IsError(err, `table "tb3" does not exist`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// The zone_config function resolves the zone config of a table by name.
+	util.SucceedsSoon(t, func() error {
+		var zone string
+		if err := sqlDB.QueryRow(`SELECT zone_config('db1.tb2')`).Scan(&zone); err != nil {
+			return err
+		}
+		if !strings.Contains(zone, "db1") {
+			return util.Errorf("expected zone config of db1, got:\n%s", zone)
+		}
+		return nil
+	})
 }
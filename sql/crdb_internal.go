@@ -0,0 +1,394 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/keys"
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/security"
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/util/log"
+)
+
+// crdbInternalDatabase is the name of the database holding the virtual tables
+// which expose internal state of the cluster.
+const crdbInternalDatabase = "crdb_internal"
+
+// crdbInternalMaxRows bounds the number of rows listed by the crdb_internal
+// tables which read state recorded across the cluster, such as the status of
+// every store or the descriptor of every range, so that reading them can't
+// exhaust the memory of the node on large clusters.
+const crdbInternalMaxRows = 10000
+
+// crdbInternalTables maps the names of the crdb_internal virtual tables to
+// the functions which populate them.
+var crdbInternalTables = map[string]func(*planner) (*valuesNode, *roachpb.Error){
+	"nodes":       (*planner).crdbInternalNodes,
+	"stores":      (*planner).crdbInternalStores,
+	"ranges":      (*planner).crdbInternalRanges,
+	"metrics":     (*planner).crdbInternalMetrics,
+	"sessions":    (*planner).crdbInternalSessions,
+	"jobs":        (*planner).crdbInternalJobs,
+	txnWaitsTable: (*planner).txnWaits,
+}
+
+// getCrdbInternalTable returns the name of the crdb_internal virtual table
+// which the table name refers to and the function populating it, or nil if
+// the table name does not refer to one.
+func getCrdbInternalTable(
+	tableName *parser.QualifiedName) (string, func(*planner) (*valuesNode, *roachpb.Error)) {
+	if len(tableName.Indirect) != 1 || !equalName(string(tableName.Base), crdbInternalDatabase) {
+		return "", nil
+	}
+	name, ok := tableName.Indirect[0].(parser.NameIndirection)
+	if !ok {
+		return "", nil
+	}
+	normName := NormalizeName(string(name))
+	return normName, crdbInternalTables[normName]
+}
+
+// crdbInternalTable populates the named crdb_internal virtual table using
+// the given function.
+// Privileges: security.RootUser user.
+func (p *planner) crdbInternalTable(
+	name string, fn func(*planner) (*valuesNode, *roachpb.Error)) (*valuesNode, *roachpb.Error) {
+	if p.user != security.RootUser {
+		return nil, roachpb.NewUErrorf("only %s is allowed to read %s.%s",
+			security.RootUser, crdbInternalDatabase, name)
+	}
+	return fn(p)
+}
+
+// errTooManyRows is returned when a crdb_internal table would list more than
+// crdbInternalMaxRows rows.
+func errTooManyRows(name string) *roachpb.Error {
+	return roachpb.NewUErrorf("%s.%s is limited to %d rows",
+		crdbInternalDatabase, name, crdbInternalMaxRows)
+}
+
+// ClusterStatus provides the crdb_internal virtual tables with the state of
+// the cluster which is recorded outside of the sql package. It is implemented
+// by the server.
+type ClusterStatus interface {
+	// NodeStatuses returns the most recently recorded status of every node,
+	// up to maxRows of them.
+	NodeStatuses(maxRows int64) ([]NodeStatus, error)
+	// StoreStatuses returns the most recently recorded status of every
+	// store, up to maxRows of them.
+	StoreStatuses(maxRows int64) ([]StoreStatus, error)
+	// Metrics returns the current values of the metrics of the local node
+	// and its stores.
+	Metrics() []MetricValue
+}
+
+// NodeStatus is the status of a node listed in crdb_internal.nodes.
+type NodeStatus struct {
+	NodeID           roachpb.NodeID
+	Address          string
+	StartedAt        time.Time
+	UpdatedAt        time.Time
+	RangeCount       int64
+	LeaderRangeCount int64
+	LiveBytes        int64
+}
+
+// StoreStatus is the status of a store listed in crdb_internal.stores.
+type StoreStatus struct {
+	StoreID          roachpb.StoreID
+	NodeID           roachpb.NodeID
+	Capacity         int64
+	Available        int64
+	RangeCount       int64
+	LeaderRangeCount int64
+	LiveBytes        int64
+}
+
+// MetricValue is the value of a metric listed in crdb_internal.metrics. The
+// source is the ID of the node or store the metric belongs to.
+type MetricValue struct {
+	Name   string
+	Source string
+	Value  float64
+}
+
+// crdbInternalNodes returns a valuesNode listing the status of the nodes of
+// the cluster.
+func (p *planner) crdbInternalNodes() (*valuesNode, *roachpb.Error) {
+	v := &valuesNode{
+		columns: []ResultColumn{
+			{Name: "node_id", Typ: parser.DummyInt},
+			{Name: "address", Typ: parser.DummyString},
+			{Name: "started_at", Typ: parser.DummyTimestamp},
+			{Name: "updated_at", Typ: parser.DummyTimestamp},
+			{Name: "range_count", Typ: parser.DummyInt},
+			{Name: "leader_range_count", Typ: parser.DummyInt},
+			{Name: "live_bytes", Typ: parser.DummyInt},
+		},
+	}
+	if p.clusterStatus == nil {
+		return v, nil
+	}
+	nodes, err := p.clusterStatus.NodeStatuses(crdbInternalMaxRows + 1)
+	if err != nil {
+		return nil, roachpb.NewError(err)
+	}
+	if len(nodes) > crdbInternalMaxRows {
+		return nil, errTooManyRows("nodes")
+	}
+	for _, n := range nodes {
+		v.rows = append(v.rows, parser.DTuple{
+			parser.DInt(n.NodeID),
+			parser.DString(n.Address),
+			parser.DTimestamp{Time: n.StartedAt},
+			parser.DTimestamp{Time: n.UpdatedAt},
+			parser.DInt(n.RangeCount),
+			parser.DInt(n.LeaderRangeCount),
+			parser.DInt(n.LiveBytes),
+		})
+	}
+	sort.Sort(crdbInternalRows(v.rows))
+	return v, nil
+}
+
+// crdbInternalStores returns a valuesNode listing the status of the stores
+// of the cluster.
+func (p *planner) crdbInternalStores() (*valuesNode, *roachpb.Error) {
+	v := &valuesNode{
+		columns: []ResultColumn{
+			{Name: "store_id", Typ: parser.DummyInt},
+			{Name: "node_id", Typ: parser.DummyInt},
+			{Name: "capacity", Typ: parser.DummyInt},
+			{Name: "available", Typ: parser.DummyInt},
+			{Name: "range_count", Typ: parser.DummyInt},
+			{Name: "leader_range_count", Typ: parser.DummyInt},
+			{Name: "live_bytes", Typ: parser.DummyInt},
+		},
+	}
+	if p.clusterStatus == nil {
+		return v, nil
+	}
+	stores, err := p.clusterStatus.StoreStatuses(crdbInternalMaxRows + 1)
+	if err != nil {
+		return nil, roachpb.NewError(err)
+	}
+	if len(stores) > crdbInternalMaxRows {
+		return nil, errTooManyRows("stores")
+	}
+	for _, s := range stores {
+		v.rows = append(v.rows, parser.DTuple{
+			parser.DInt(s.StoreID),
+			parser.DInt(s.NodeID),
+			parser.DInt(s.Capacity),
+			parser.DInt(s.Available),
+			parser.DInt(s.RangeCount),
+			parser.DInt(s.LeaderRangeCount),
+			parser.DInt(s.LiveBytes),
+		})
+	}
+	sort.Sort(crdbInternalRows(v.rows))
+	return v, nil
+}
+
+// crdbInternalRanges returns a valuesNode listing all the ranges of the
+// cluster, as recorded in their meta2 range descriptors. The scan of meta2
+// stops once it read more than crdbInternalMaxRows descriptors.
+func (p *planner) crdbInternalRanges() (*valuesNode, *roachpb.Error) {
+	v := &valuesNode{
+		columns: []ResultColumn{
+			{Name: "range_id", Typ: parser.DummyInt},
+			{Name: "start_key", Typ: parser.DummyString},
+			{Name: "end_key", Typ: parser.DummyString},
+			{Name: "replicas", Typ: parser.DummyString},
+		},
+	}
+	if p.prepareOnly {
+		return v, nil
+	}
+	var tooMany bool
+	addRange := func(rangeDesc roachpb.RangeDescriptor) bool {
+		if len(v.rows) == crdbInternalMaxRows {
+			tooMany = true
+			return false
+		}
+		v.rows = append(v.rows, parser.DTuple{
+			parser.DInt(rangeDesc.RangeID),
+			parser.DString(rangeDesc.StartKey.String()),
+			parser.DString(rangeDesc.EndKey.String()),
+			parser.DString(formatReplicas(rangeDesc.Replicas)),
+		})
+		return true
+	}
+	if pErr := iterateRangeDescriptors(p.txn, keys.Meta2Prefix, addRange); pErr != nil {
+		return nil, pErr
+	}
+	if tooMany {
+		return nil, errTooManyRows("ranges")
+	}
+	return v, nil
+}
+
+// crdbInternalMetrics returns a valuesNode listing the current values of the
+// metrics of the local node and its stores.
+func (p *planner) crdbInternalMetrics() (*valuesNode, *roachpb.Error) {
+	v := &valuesNode{
+		columns: []ResultColumn{
+			{Name: "name", Typ: parser.DummyString},
+			{Name: "source", Typ: parser.DummyString},
+			{Name: "value", Typ: parser.DummyFloat},
+		},
+	}
+	if p.clusterStatus == nil {
+		return v, nil
+	}
+	for _, m := range p.clusterStatus.Metrics() {
+		v.rows = append(v.rows, parser.DTuple{
+			parser.DString(m.Name),
+			parser.DString(m.Source),
+			parser.DFloat(m.Value),
+		})
+	}
+	sort.Sort(crdbInternalRows(v.rows))
+	return v, nil
+}
+
+// crdbInternalSessions returns a valuesNode listing the client sessions open
+// on the local node.
+func (p *planner) crdbInternalSessions() (*valuesNode, *roachpb.Error) {
+	v := &valuesNode{
+		columns: []ResultColumn{
+			{Name: "node_id", Typ: parser.DummyInt},
+			{Name: "username", Typ: parser.DummyString},
+			{Name: "client_address", Typ: parser.DummyString},
+			{Name: "started_at", Typ: parser.DummyTimestamp},
+		},
+	}
+	if p.sessionRegistry == nil {
+		return v, nil
+	}
+	r := p.sessionRegistry
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.sessions {
+		v.rows = append(v.rows, parser.DTuple{
+			parser.DInt(p.evalCtx.NodeID),
+			parser.DString(s.user),
+			parser.DString(s.clientAddr),
+			parser.DTimestamp{Time: s.startedAt},
+		})
+	}
+	sort.Sort(crdbInternalRows(v.rows))
+	return v, nil
+}
+
+// crdbInternalJobs returns a valuesNode listing the schema changes which are
+// in progress, with one row per mutation of a table descriptor.
+func (p *planner) crdbInternalJobs() (*valuesNode, *roachpb.Error) {
+	v := &valuesNode{
+		columns: []ResultColumn{
+			{Name: "table_id", Typ: parser.DummyInt},
+			{Name: "table_name", Typ: parser.DummyString},
+			{Name: "mutation_id", Typ: parser.DummyInt},
+			{Name: "direction", Typ: parser.DummyString},
+			{Name: "descriptor", Typ: parser.DummyString},
+			{Name: "state", Typ: parser.DummyString},
+			{Name: "rollback", Typ: parser.DummyBool},
+		},
+	}
+	descKeyPrefix := keys.MakeTablePrefix(uint32(descriptorTable.ID))
+	for _, kv := range p.systemConfig.Values {
+		if !bytes.HasPrefix(kv.Key, descKeyPrefix) {
+			continue
+		}
+		var descriptor Descriptor
+		if err := kv.Value.GetProto(&descriptor); err != nil {
+			log.Warningf("%s: unable to unmarshal descriptor %v", kv.Key, kv.Value)
+			continue
+		}
+		table := descriptor.GetTable()
+		if table == nil {
+			continue
+		}
+		for _, m := range table.Mutations {
+			var name string
+			if col := m.GetColumn(); col != nil {
+				name = "COLUMN " + col.Name
+			} else if index := m.GetIndex(); index != nil {
+				name = "INDEX " + index.Name
+			}
+			v.rows = append(v.rows, parser.DTuple{
+				parser.DInt(table.ID),
+				parser.DString(table.Name),
+				parser.DInt(m.MutationID),
+				parser.DString(m.Direction.String()),
+				parser.DString(name),
+				parser.DString(m.State.String()),
+				parser.DBool(m.Rollback),
+			})
+		}
+	}
+	return v, nil
+}
+
+// sessionRegistry keeps track of the client sessions open on a node.
+type sessionRegistry struct {
+	mu       sync.Mutex
+	nextID   int64
+	sessions map[int64]registeredSession
+}
+
+type registeredSession struct {
+	user       string
+	clientAddr string
+	startedAt  time.Time
+}
+
+func newSessionRegistry() *sessionRegistry {
+	return &sessionRegistry{sessions: map[int64]registeredSession{}}
+}
+
+// register adds a session to the registry and returns a function which
+// removes it.
+func (r *sessionRegistry) register(user, clientAddr string) func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	id := r.nextID
+	r.sessions[id] = registeredSession{
+		user:       user,
+		clientAddr: clientAddr,
+		startedAt:  time.Now().UTC(),
+	}
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.sessions, id)
+	}
+}
+
+// crdbInternalRows sorts the rows of a crdb_internal table by the values of
+// their columns, in column order.
+type crdbInternalRows []parser.DTuple
+
+func (r crdbInternalRows) Len() int      { return len(r) }
+func (r crdbInternalRows) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r crdbInternalRows) Less(i, j int) bool {
+	return r[i].Compare(r[j]) < 0
+}
@@ -0,0 +1,89 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/server"
+	"github.com/cockroachdb/cockroach/testutils"
+	"github.com/cockroachdb/cockroach/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+func TestCrdbInternalTables(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, sqlDB, _ := setup(t)
+	defer cleanup(s, sqlDB)
+
+	count := func(query string) int {
+		var n int
+		if err := sqlDB.QueryRow(query).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	// The ranges cover the whole keyspace.
+	if n := count(`SELECT COUNT(*) FROM crdb_internal.ranges`); n == 0 {
+		t.Error("expected at least one range")
+	}
+
+	// The connection used by the test is a session on the node.
+	var user string
+	if err := sqlDB.QueryRow(`SELECT username FROM crdb_internal.sessions`).Scan(&user); err != nil {
+		t.Fatal(err)
+	}
+	if user != "root" {
+		t.Errorf("expected session of user root, got %s", user)
+	}
+
+	if n := count(`SELECT COUNT(*) FROM crdb_internal.jobs`); n != 0 {
+		t.Errorf("expected no schema changes in progress, got %d", n)
+	}
+
+	// Node and store statuses are recorded periodically.
+	util.SucceedsSoon(t, func() error {
+		if n := count(`SELECT COUNT(*) FROM crdb_internal.nodes WHERE node_id = 1`); n != 1 {
+			return util.Errorf("expected status of node 1, got %d rows", n)
+		}
+		if n := count(`SELECT COUNT(*) FROM crdb_internal.stores WHERE node_id = 1`); n == 0 {
+			return util.Errorf("expected status of the stores of node 1")
+		}
+		return nil
+	})
+
+	if n := count(`SELECT COUNT(*) FROM crdb_internal.metrics WHERE name = 'cr.node.sql.select.count'`); n != 1 {
+		t.Errorf("expected the select count metric, got %d rows", n)
+	}
+
+	if _, err := sqlDB.Exec(`SELECT * FROM crdb_internal.foo`); !testutils.IsError(err, `database "crdb_internal" does not exist`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// Only the root user may read the tables.
+	url, cleanupFn := sqlutils.PGUrl(t, &s.TestServer, server.TestUser, "TestCrdbInternalTables")
+	defer cleanupFn()
+	userDB, err := sql.Open("postgres", url.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer userDB.Close()
+	if _, err := userDB.Exec(`SELECT * FROM crdb_internal.ranges`); !testutils.IsError(err, `only root is allowed to read crdb_internal.ranges`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -142,8 +142,8 @@ func (p *planner) Delete(n *parser.Delete, autoCommit bool) (planNode, *roachpb.
 		// An auto-txn can commit the transaction with the batch. This is an
 		// optimization to avoid an extra round-trip to the transaction
 		// coordinator.
-		pErr = p.txn.CommitInBatch(b)
-	} else {
+		pErr = p.commitInBatch(b)
+	} else if !p.deferWrites(b, tableDesc) {
 		pErr = p.txn.Run(b)
 	}
 	if pErr != nil {
@@ -163,7 +163,7 @@ func canDeleteWithoutScan(n *parser.Delete, scan *scanNode, indexCount int) bool
 		}
 		return false
 	}
-	if n.Returning != nil {
+	if len(n.Returning) > 0 {
 		if log.V(2) {
 			log.Infof("delete forced to scan: values required for RETURNING")
 		}
@@ -199,7 +199,7 @@ func (p *planner) fastDelete(scan *scanNode, result *returningNode, autoCommit b
 		// An auto-txn can commit the transaction with the batch. This is an
 		// optimization to avoid an extra round-trip to the transaction
 		// coordinator.
-		if pErr := p.txn.CommitInBatch(b); pErr != nil {
+		if pErr := p.commitInBatch(b); pErr != nil {
 			return nil, pErr
 		}
 	} else {
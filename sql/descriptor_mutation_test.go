@@ -491,6 +491,64 @@ CREATE TABLE t.test (k CHAR PRIMARY KEY, v CHAR, INDEX foo (v));
 	}
 }
 
+// TestUniqueViolationWithIndexMutation verifies that a uniqueness
+// violation of an index which is being added is attributed to that index.
+func TestUniqueViolationWithIndexMutation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	// The descriptor changes made must have an immediate effect.
+	defer csql.TestDisableTableLeases()()
+	// Disable external processing of mutations.
+	defer csql.TestDisableAsyncSchemaChangeExec()()
+	server, sqlDB, kvDB := setup(t)
+	defer cleanup(server, sqlDB)
+
+	if _, err := sqlDB.Exec(`
+CREATE DATABASE t;
+CREATE TABLE t.test (k CHAR PRIMARY KEY, v CHAR, w CHAR, INDEX bar (w), UNIQUE INDEX foo (v));
+INSERT INTO t.test VALUES ('a', 'z', 'z');
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	nameKey := csql.MakeNameMetadataKey(keys.MaxReservedDescID+1, "test")
+	gr, err := kvDB.Get(nameKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gr.Exists() {
+		t.Fatalf("Name entry %q does not exist", nameKey)
+	}
+	descKey := csql.MakeDescMetadataKey(csql.ID(gr.ValueInt()))
+	desc := &csql.Descriptor{}
+	if err := kvDB.GetProto(descKey, desc); err != nil {
+		t.Fatal(err)
+	}
+
+	mTest := mutationTest{
+		T:       t,
+		kvDB:    kvDB,
+		sqlDB:   sqlDB,
+		descKey: descKey,
+		desc:    desc,
+	}
+	mTest.writeIndexMutation("foo", csql.DescriptorMutation{
+		State:     csql.DescriptorMutation_WRITE_ONLY,
+		Direction: csql.DescriptorMutation_ADD,
+	})
+
+	if _, err := sqlDB.Exec(`INSERT INTO t.test VALUES ('b', 'z', 'y')`); !testutils.IsError(err,
+		`duplicate key value \(v\)=\('z'\) violates unique constraint "foo"`) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := sqlDB.Exec(`INSERT INTO t.test VALUES ('b', 'y', 'y')`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlDB.Exec(`UPDATE t.test SET v = 'z' WHERE k = 'b'`); !testutils.IsError(err,
+		`duplicate key value \(v\)=\('z'\) violates unique constraint "foo"`) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
 func TestCommandsWithPendingMutations(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	// The descriptor changes made must have an immediate effect
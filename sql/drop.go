@@ -17,6 +17,8 @@
 package sql
 
 import (
+	"time"
+
 	"github.com/cockroachdb/cockroach/client"
 	"github.com/cockroachdb/cockroach/config"
 	"github.com/cockroachdb/cockroach/keys"
@@ -187,8 +189,6 @@ func (p *planner) DropIndex(n *parser.DropIndex) (planNode, *roachpb.Error) {
 //   Notes: postgres allows only the table owner to DROP a table.
 //          mysql requires the DROP privilege on the table.
 func (p *planner) DropTable(n *parser.DropTable) (planNode, *roachpb.Error) {
-	// TODO(XisiHuang): should do truncate and delete descriptor in
-	// the same txn
 	for i := range n.Names {
 		droppedDesc, err := p.dropTableImpl(n.Names, i)
 		if err != nil {
@@ -222,8 +222,6 @@ func (p *planner) DropTable(n *parser.DropTable) (planNode, *roachpb.Error) {
 // either a DROP TABLE or DROP DATABASE statement. This method returns the
 // dropped table descriptor, to be used for the purpose of logging the event.
 func (p *planner) dropTableImpl(names parser.QualifiedNames, index int) (*TableDescriptor, *roachpb.Error) {
-	// TODO(XisiHuang): should do truncate and delete descriptor in
-	// the same txn
 	tableQualifiedName := names[index]
 	if err := tableQualifiedName.NormalizeTableName(p.session.Database); err != nil {
 		return nil, roachpb.NewError(err)
@@ -264,14 +262,23 @@ func (p *planner) dropTableImpl(names parser.QualifiedNames, index int) (*TableD
 		return nil, roachpb.NewError(err)
 	}
 
-	if _, pErr := p.Truncate(&parser.Truncate{Tables: names[index : index+1]}); pErr != nil {
-		return nil, pErr
+	// Rather than deleting the table data transactionally, schedule it to be
+	// cleared once the GC TTL of the table's zone has expired, so that
+	// historical reads of the dropped table remain possible until then.
+	clearAfter := p.txn.Proto.OrigTimestamp.WallTime
+	if !p.session.ClearDroppedDataImmediately {
+		zone, err := p.systemConfig.GetZoneConfigForKey(keys.MakeTablePrefix(uint32(tableDesc.ID)))
+		if err != nil {
+			return nil, roachpb.NewError(err)
+		}
+		clearAfter += int64(zone.GC.TTLSeconds) * int64(time.Second)
 	}
 
 	zoneKey := MakeZoneKey(tableDesc.ID)
 
 	// Delete table descriptor
 	b := &client.Batch{}
+	b.Put(keys.TableClearKey(uint32(tableDesc.ID)), clearAfter)
 	b.Del(descKey)
 	b.Del(nameKey)
 	// Delete the zone config entry for this table.
@@ -17,18 +17,22 @@
 package sql_test
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/cockroach/config"
 	"github.com/cockroachdb/cockroach/keys"
 	"github.com/cockroachdb/cockroach/roachpb"
 	"github.com/cockroachdb/cockroach/sql"
+	"github.com/cockroachdb/cockroach/util"
 	"github.com/cockroachdb/cockroach/util/leaktest"
 	"github.com/gogo/protobuf/proto"
 )
 
 func TestDropDatabase(t *testing.T) {
 	defer leaktest.AfterTest(t)()
+	defer sql.TestSpeedupTableClear()()
 	s, sqlDB, kvDB := setup(t)
 	defer cleanup(s, sqlDB)
 
@@ -104,15 +108,24 @@ INSERT INTO t.kv VALUES ('c', 'e'), ('a', 'c'), ('b', 'd');
 		t.Fatalf("expected %d key value pairs, but got %d", l, len(kvs))
 	}
 
-	if _, err := sqlDB.Exec(`DROP DATABASE t`); err != nil {
+	// The session variable and the DROP must use the same connection.
+	if _, err := sqlDB.Exec(`SET CLEAR_DROPPED_DATA = 'immediate'; DROP DATABASE t`); err != nil {
 		t.Fatal(err)
 	}
 
-	if kvs, err := kvDB.Scan(tableStartKey, tableEndKey, 0); err != nil {
-		t.Fatal(err)
-	} else if l := 0; len(kvs) != l {
-		t.Fatalf("expected %d key value pairs, but got %d", l, len(kvs))
-	}
+	util.SucceedsSoon(t, func() error {
+		if kvs, err := kvDB.Scan(tableStartKey, tableEndKey, 0); err != nil {
+			return err.GoError()
+		} else if l := 0; len(kvs) != l {
+			return fmt.Errorf("expected %d key value pairs, but got %d", l, len(kvs))
+		}
+		if gr, err := kvDB.Get(keys.TableClearKey(uint32(tbDesc.ID))); err != nil {
+			return err.GoError()
+		} else if gr.Exists() {
+			return fmt.Errorf("table clear key still exists after the table data is cleared")
+		}
+		return nil
+	})
 
 	if gr, err := kvDB.Get(tbDescKey); err != nil {
 		t.Fatal(err)
@@ -281,12 +294,21 @@ INSERT INTO t.kv VALUES ('c', 'e'), ('a', 'c'), ('b', 'd');
 		t.Fatal(err)
 	}
 
+	// The table data is retained until the GC TTL of the zone has expired.
 	if kvs, err := kvDB.Scan(tableStartKey, tableEndKey, 0); err != nil {
 		t.Fatal(err)
-	} else if l := 0; len(kvs) != l {
+	} else if l := 6; len(kvs) != l {
 		t.Fatalf("expected %d key value pairs, but got %d", l, len(kvs))
 	}
 
+	if gr, err := kvDB.Get(keys.TableClearKey(uint32(tableDesc.ID))); err != nil {
+		t.Fatal(err)
+	} else if !gr.Exists() {
+		t.Fatalf("table clear key not found")
+	} else if min := time.Now().Add(time.Duration(cfg.GC.TTLSeconds-60) * time.Second); gr.ValueInt() < min.UnixNano() {
+		t.Fatalf("expected table data to be cleared after %s, but got %s", min, time.Unix(0, gr.ValueInt()))
+	}
+
 	if gr, err := kvDB.Get(descKey); err != nil {
 		t.Fatal(err)
 	} else if gr.Exists() {
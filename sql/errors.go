@@ -58,7 +58,10 @@ func convertBatchError(tableDesc *TableDescriptor, b client.Batch, origPErr *roa
 			if err != nil {
 				return roachpb.NewError(err)
 			}
-			index, err := tableDesc.FindIndexByID(indexID)
+			// The writes to all the indexes of a row are sent in a single
+			// batch, and the index violated is determined by the key. It may
+			// be an index which is still being added.
+			index, err := tableDesc.findIndexByIDWithMutations(indexID)
 			if err != nil {
 				return roachpb.NewError(err)
 			}
@@ -24,7 +24,9 @@ import (
 	"github.com/cockroachdb/cockroach/client"
 	"github.com/cockroachdb/cockroach/keys"
 	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/sql/parser"
 	"github.com/cockroachdb/cockroach/sql/privilege"
+	"github.com/cockroachdb/cockroach/util"
 )
 
 // EventLogType represents an event type that can be recorded in the event log.
@@ -39,6 +41,8 @@ const (
 	EventLogCreateTable EventLogType = "create_table"
 	// EventLogDropTable is recorded when a table is dropped.
 	EventLogDropTable EventLogType = "drop_table"
+	// EventLogSetClusterSetting is recorded when a cluster setting is changed.
+	EventLogSetClusterSetting EventLogType = "set_cluster_setting"
 )
 
 // eventTableSchema describes the schema of the event log table.
@@ -122,3 +126,90 @@ func (ev EventLogger) selectEventTimestamp(input roachpb.Timestamp) time.Time {
 	}
 	return input.GoTime()
 }
+
+// An Event is an entry of the event log.
+type Event struct {
+	Timestamp   time.Time    `json:"timestamp"`
+	EventType   EventLogType `json:"eventType"`
+	TargetID    int64        `json:"targetID"`
+	ReportingID int64        `json:"reportingID"`
+	Info        string       `json:"info,omitempty"`
+	UniqueID    []byte       `json:"uniqueID"`
+}
+
+// PruneEventsInTransaction deletes up to limit of the events recorded
+// before the given time from the event log as part of the provided
+// transaction, oldest first, and returns them.
+func (ev EventLogger) PruneEventsInTransaction(txn *client.Txn, before time.Time,
+	limit int) ([]Event, *roachpb.Error) {
+	const selectEventsStmt = `
+SELECT timestamp, eventType, targetID, reportingID, info, uniqueID
+FROM system.eventlog
+WHERE timestamp < $1
+ORDER BY timestamp
+LIMIT $2
+`
+	const deleteEventStmt = `
+DELETE FROM system.eventlog WHERE timestamp = $1 AND uniqueID = $2
+`
+	rows, pErr := ev.QueryRowsInTransaction(txn, selectEventsStmt, before, limit)
+	if pErr != nil {
+		return nil, pErr
+	}
+	events := make([]Event, 0, len(rows))
+	for _, row := range rows {
+		event, err := makeEvent(row)
+		if err != nil {
+			return nil, roachpb.NewError(err)
+		}
+		events = append(events, event)
+	}
+	for _, event := range events {
+		if _, pErr := ev.ExecuteStatementInTransaction(txn, deleteEventStmt,
+			event.Timestamp, event.UniqueID); pErr != nil {
+			return nil, pErr
+		}
+	}
+	return events, nil
+}
+
+// makeEvent converts a row of the event log into an Event.
+func makeEvent(row parser.DTuple) (Event, error) {
+	var event Event
+	if len(row) != 6 {
+		return event, util.Errorf("expected 6 columns in event log row, got %d", len(row))
+	}
+	ts, ok := row[0].(parser.DTimestamp)
+	if !ok {
+		return event, util.Errorf("unexpected type for event timestamp: %T", row[0])
+	}
+	event.Timestamp = ts.Time
+	eventType, ok := row[1].(parser.DString)
+	if !ok {
+		return event, util.Errorf("unexpected type for event type: %T", row[1])
+	}
+	event.EventType = EventLogType(eventType)
+	targetID, ok := row[2].(parser.DInt)
+	if !ok {
+		return event, util.Errorf("unexpected type for event target ID: %T", row[2])
+	}
+	event.TargetID = int64(targetID)
+	reportingID, ok := row[3].(parser.DInt)
+	if !ok {
+		return event, util.Errorf("unexpected type for event reporting ID: %T", row[3])
+	}
+	event.ReportingID = int64(reportingID)
+	if row[4] != parser.DNull {
+		info, ok := row[4].(parser.DString)
+		if !ok {
+			return event, util.Errorf("unexpected type for event info: %T", row[4])
+		}
+		event.Info = string(info)
+	}
+	uniqueID, ok := row[5].(parser.DBytes)
+	if !ok {
+		return event, util.Errorf("unexpected type for event unique ID: %T", row[5])
+	}
+	event.UniqueID = []byte(uniqueID)
+	return event, nil
+}
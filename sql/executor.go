@@ -25,6 +25,7 @@ import (
 	"sync"
 	"time"
 
+	"golang.org/x/net/context"
 	"gopkg.in/inf.v0"
 
 	"github.com/cockroachdb/cockroach/client"
@@ -36,6 +37,7 @@ import (
 	"github.com/cockroachdb/cockroach/util/log"
 	"github.com/cockroachdb/cockroach/util/metric"
 	"github.com/cockroachdb/cockroach/util/retry"
+	"github.com/cockroachdb/cockroach/util/settings"
 	"github.com/cockroachdb/cockroach/util/stop"
 )
 
@@ -116,6 +118,9 @@ type Result struct {
 	// the result set of the result.
 	// TODO(nvanbenschoten): Can this be streamed from the planNode?
 	Rows []ResultRow
+	// Notices are informational messages for the client about the execution
+	// of the statement, sent before its result.
+	Notices []string
 }
 
 // ResultColumn contains the name and type of a SQL "cell".
@@ -141,7 +146,8 @@ type Executor struct {
 
 	// Transient stats.
 	registry      *metric.Registry
-	latency       metric.Histograms
+	latency       metric.Latency
+	queryCount    metric.Rates
 	selectCount   *metric.Counter
 	txnBeginCount *metric.Counter
 
@@ -156,6 +162,28 @@ type Executor struct {
 	ddlCount         *metric.Counter
 	miscCount        *metric.Counter
 
+	// throttler enforces per-user rate limits.
+	throttler *userThrottler
+
+	// backpressure delays writes while the storage engines stall writes.
+	backpressure *writeBackpressure
+
+	// parallelWrites holds the writes deferred by the statements executed
+	// in parallel in the open transactions of the sessions.
+	parallelWrites sessionParallelWrites
+	// drainCtx is cancelled when the node starts draining.
+	drainCtx context.Context
+
+	// onePC counts the transactions committed along with their first
+	// writes.
+	onePC *onePCMetrics
+
+	// txnWaits holds the gossiped transaction waits of all stores.
+	txnWaits *txnWaitsCache
+
+	// sessions holds the client sessions open on this node.
+	sessions *sessionRegistry
+
 	// System Config and mutex.
 	systemConfig   config.SystemConfig
 	databaseCache  *databaseCache
@@ -175,6 +203,29 @@ type ExecutorContext struct {
 	Gossip       *gossip.Gossip
 	LeaseManager *LeaseManager
 
+	// DefaultUserRateLimit is the rate limit applied to users which have no
+	// entry in UserRateLimits. The zero value is unlimited.
+	DefaultUserRateLimit UserRateLimit
+	// UserRateLimits overrides the rate limit of individual users.
+	UserRateLimits map[string]UserRateLimit
+
+	// ClusterStatus, if set, provides the status of the nodes and stores of
+	// the cluster listed in the crdb_internal virtual tables.
+	ClusterStatus ClusterStatus
+
+	// Settings is the registry of the cluster settings, which are read from
+	// the system.settings table whenever the system config changes. The
+	// settings of the executor are registered with it.
+	Settings *settings.Registry
+
+	// EagerCommit, if set, commits explicit transactions along with their
+	// last write when it is directly followed by COMMIT in the same
+	// request, e.g. "BEGIN; INSERT ...; COMMIT", so that the transactions
+	// which only write to a single range commit in one phase. Such commits
+	// are bounded by the expiration of the table leases used by the
+	// transaction.
+	EagerCommit bool
+
 	TestingMocker ExecutorTestingMocker
 }
 
@@ -192,12 +243,16 @@ type ExecutorTestingMocker struct {
 // NewExecutor creates an Executor and registers a callback on the
 // system config.
 func NewExecutor(ctx ExecutorContext, stopper *stop.Stopper, registry *metric.Registry) *Executor {
+	if ctx.Settings == nil {
+		ctx.Settings = settings.NewRegistry()
+	}
 	exec := &Executor{
 		ctx:     ctx,
 		reCache: parser.NewRegexpCache(512),
 
 		registry:         registry,
 		latency:          registry.Latency("latency"),
+		queryCount:       registry.RatesWithScales("query", metric.LoadAverageTimeScales...),
 		txnBeginCount:    registry.Counter("txn.begin.count"),
 		txnCommitCount:   registry.Counter("txn.commit.count"),
 		txnAbortCount:    registry.Counter("txn.abort.count"),
@@ -208,8 +263,25 @@ func NewExecutor(ctx ExecutorContext, stopper *stop.Stopper, registry *metric.Re
 		deleteCount:      registry.Counter("delete.count"),
 		ddlCount:         registry.Counter("ddl.count"),
 		miscCount:        registry.Counter("misc.count"),
+		throttler:        newUserThrottler(ctx.DefaultUserRateLimit, ctx.UserRateLimits, registry),
+		backpressure:     newWriteBackpressure(registry),
+		onePC:            newOnePCMetrics(registry),
+		txnWaits:         newTxnWaitsCache(),
+		sessions:         newSessionRegistry(),
 	}
 	exec.systemConfigCond = sync.NewCond(exec.systemConfigMu.RLocker())
+	exec.throttler.registerSetting(ctx.Settings)
+	ctx.Gossip.RegisterCallback(gossip.MakePrefixPattern(gossip.KeyTxnWaitsPrefix), exec.txnWaits.gossipUpdate)
+	ctx.Gossip.RegisterCallback(gossip.MakePrefixPattern(gossip.KeyStoreWriteStalledPrefix), exec.backpressure.gossipUpdate)
+
+	// Statements delayed by the write backpressure proceed once the node
+	// starts draining.
+	var cancel func()
+	exec.drainCtx, cancel = context.WithCancel(context.Background())
+	stopper.RunWorker(func() {
+		<-stopper.ShouldDrain()
+		cancel()
+	})
 
 	gossipUpdateC := ctx.Gossip.RegisterSystemConfigChannel()
 	stopper.RunWorker(func() {
@@ -227,6 +299,18 @@ func NewExecutor(ctx ExecutorContext, stopper *stop.Stopper, registry *metric.Re
 	return exec
 }
 
+// RegisterSession records that a client session was opened by the user from
+// the given address, so that it is listed in crdb_internal.sessions. The
+// returned function must be called once the session is closed.
+func (e *Executor) RegisterSession(session *Session, user, clientAddr string) func() {
+	unregister := e.sessions.register(user, clientAddr)
+	return func() {
+		unregister()
+		// Drop the writes deferred by a transaction left open.
+		e.parallelWrites.take(session)
+	}
+}
+
 // SetNodeID sets the node ID for the SQL server. This method must be called
 // before actually using the Executor.
 func (e *Executor) SetNodeID(nodeID roachpb.NodeID) {
@@ -236,6 +320,7 @@ func (e *Executor) SetNodeID(nodeID roachpb.NodeID) {
 
 // updateSystemConfig is called whenever the system config gossip entry is updated.
 func (e *Executor) updateSystemConfig(cfg *config.SystemConfig) {
+	e.ctx.Settings.Update(settingsFromSystemConfig(*cfg))
 	e.systemConfigMu.Lock()
 	e.systemConfig = *cfg
 	// The database cache gets reset whenever the system config changes.
@@ -283,15 +368,22 @@ func (e *Executor) Prepare(user string, query string, session *Session, args par
 	*planMaker = planner{
 		user: user,
 		evalCtx: parser.EvalContext{
-			NodeID:      e.nodeID,
-			ReCache:     e.reCache,
-			GetLocation: session.getLocation,
-			Args:        args,
+			NodeID:        e.nodeID,
+			ReCache:       e.reCache,
+			GetLocation:   session.getLocation,
+			Args:          args,
+			GetZoneConfig: planMaker.getZoneConfig,
 		},
-		leaseMgr:      e.ctx.LeaseManager,
-		systemConfig:  cfg,
-		databaseCache: cache,
-		session:       session,
+		leaseMgr:        e.ctx.LeaseManager,
+		systemConfig:    cfg,
+		databaseCache:   cache,
+		txnWaitsCache:   e.txnWaits,
+		clusterStatus:   e.ctx.ClusterStatus,
+		sessionRegistry: e.sessions,
+		settings:        e.ctx.Settings,
+		session:         session,
+		eagerCommit:     e.ctx.EagerCommit,
+		onePC:           e.onePC,
 	}
 
 	timestamp := time.Now()
@@ -417,6 +509,9 @@ type txnState struct {
 	txnTimestamp time.Time
 	// The schema change closures to run when this txn is done.
 	schemaChangers schemaChangerCollection
+	// The writes deferred by the statements executed in parallel, which
+	// are kept with the session until the txn commits.
+	parallel *parallelWrites
 }
 
 type transactionState int
@@ -450,14 +545,21 @@ func (e *Executor) ExecuteStatements(
 	*planMaker = planner{
 		user: user,
 		evalCtx: parser.EvalContext{
-			NodeID:      e.nodeID,
-			ReCache:     e.reCache,
-			GetLocation: session.getLocation,
+			NodeID:        e.nodeID,
+			ReCache:       e.reCache,
+			GetLocation:   session.getLocation,
+			GetZoneConfig: planMaker.getZoneConfig,
 		},
-		leaseMgr:      e.ctx.LeaseManager,
-		systemConfig:  cfg,
-		databaseCache: cache,
-		session:       session,
+		leaseMgr:        e.ctx.LeaseManager,
+		systemConfig:    cfg,
+		databaseCache:   cache,
+		txnWaitsCache:   e.txnWaits,
+		clusterStatus:   e.ctx.ClusterStatus,
+		sessionRegistry: e.sessions,
+		settings:        e.ctx.Settings,
+		session:         session,
+		eagerCommit:     e.ctx.EagerCommit,
+		onePC:           e.onePC,
 	}
 
 	// Move the transaction state from the session to curTxnState, a struct
@@ -478,6 +580,7 @@ func (e *Executor) ExecuteStatements(
 			curTxnState.txn.SetSystemConfigTrigger()
 		}
 		curTxnState.txnTimestamp = session.Txn.TxnTimestamp.GoTime()
+		curTxnState.parallel = e.parallelWrites.take(session)
 	}
 	session.Txn = Session_Transaction{}
 
@@ -499,6 +602,9 @@ func (e *Executor) ExecuteStatements(
 		}
 		session.Txn.MutatesSystemConfig = curTxnState.txn.SystemConfigTrigger()
 		session.Txn.TxnAborted = curTxnState.aborted
+		if !curTxnState.aborted {
+			e.parallelWrites.put(session, curTxnState.parallel)
+		}
 	} else {
 		session.Txn.Txn = nil
 		session.Txn.TxnAborted = curTxnState.aborted
@@ -512,7 +618,7 @@ func (e *Executor) ExecuteStatements(
 // On error, the returned integer is an HTTP error code.
 func (e *Executor) Execute(args Request) (Response, int, error) {
 	defer func(start time.Time) {
-		e.latency.RecordValue(time.Now().Sub(start).Nanoseconds())
+		e.latency.RecordValue(time.Now().Sub(start))
 	}(time.Now())
 	results := e.ExecuteStatements(
 		args.User, args.Session, args.SQL, args.Params)
@@ -604,6 +710,12 @@ func (e *Executor) execRequest(
 		if txnState.state() == noTransaction {
 			panic("we failed to initialize a txn")
 		}
+		var retryErrs []*roachpb.Error
+		if planMaker.session.RetryNotices {
+			execOpt.OnRetry = func(pErr *roachpb.Error) {
+				retryErrs = append(retryErrs, pErr)
+			}
+		}
 		// Now actually run some statements.
 		var remainingStmts parser.StatementList
 		var results []Result
@@ -615,6 +727,9 @@ func (e *Executor) execRequest(
 		}
 		// This is where the magic happens - we ask db to run a KV txn and possibly retry it.
 		pErr := txnState.txn.Exec(execOpt, txnClosure)
+		if len(retryErrs) > 0 && len(results) > 0 {
+			results[0].Notices = append(results[0].Notices, retryNotice(retryErrs))
+		}
 		res.ResultList = append(res.ResultList, results...)
 		// Now make sense of the state we got into and update txnState.
 		if pErr != nil {
@@ -657,6 +772,19 @@ func (e *Executor) execRequest(
 	return res
 }
 
+// Values of the RETRY_NOTICES session variable.
+const (
+	retryNoticesOn  = "On"
+	retryNoticesOff = "Off"
+)
+
+// retryNotice summarizes the automatic retries of a transaction, given the
+// errors which caused them, for the client which sent its statements.
+func retryNotice(retryErrs []*roachpb.Error) string {
+	return fmt.Sprintf("transaction retried automatically %d time(s), last because of: %s",
+		len(retryErrs), retryErrs[len(retryErrs)-1])
+}
+
 func (e *Executor) checkTestingWaitForGossipUpdateOrDie(
 	planMaker *planner, stmts parser.StatementList) {
 	if e.ctx.TestingMocker.WaitForGossipUpdate {
@@ -713,6 +841,13 @@ func runTxnAttempt(
 	// different batches of statements in the same txn.
 	txnState.schemaChangers = schemaChangerCollection{}
 	planMaker.schemaChangeCallback = txnState.schemaChangers.queueSchemaChanger
+	// (re)init the writes deferred by the statements executed in parallel,
+	// unless they were deferred by the previous requests of the
+	// transaction.
+	if opt.AutoRetry || txnState.parallel == nil {
+		txnState.parallel = &parallelWrites{}
+	}
+	planMaker.parallel = txnState.parallel
 
 	planMaker.setTxn(txn, txnState.txnTimestamp)
 	var pErr *roachpb.Error
@@ -784,8 +919,21 @@ func (e *Executor) execStmtsInCurrentTxn(
 		if txnState.state() == abortedTransaction {
 			res, pErr = e.execStmtInAbortedTxn(stmt, txnState)
 		} else {
+			// Implicit transactions are committed along with their
+			// statement. With EagerCommit, so are explicit transactions
+			// along with the statement directly preceding their COMMIT.
+			autoCommit := implicitTxn
+			if e.ctx.EagerCommit && !implicitTxn && i+1 < len(stmts) {
+				_, autoCommit = stmts[i+1].(*parser.CommitTransaction)
+			}
+			planMaker.implicitTxn = implicitTxn
+			// Statements executed in parallel only queue their writes.
+			planMaker.parallelStmt = !implicitTxn && isParallelizable(stmt)
+			if planMaker.parallelStmt {
+				autoCommit = false
+			}
 			res, pErr = e.execStmtInOpenTxn(
-				stmt, planMaker, implicitTxn, txnBeginning && (i == 0), /* firstInTxn */
+				stmt, planMaker, implicitTxn, autoCommit, txnBeginning && (i == 0), /* firstInTxn */
 				stmtTimestamp, txnState)
 		}
 		if e.ctx.TestingMocker.CheckStmtStringChange {
@@ -847,8 +995,9 @@ func (e *Executor) execStmtInAbortedTxn(
 // implicitTxn: set if the current transaction was implicitly
 //  created by the system (i.e. the client sent the statement outside of
 //  a transaction).
-//  COMMIT/ROLLBACK statements are rejected if set. Also, the transaction
-//  might be auto-committed in this function.
+//  COMMIT/ROLLBACK statements are rejected if set.
+// autoCommit: set if the transaction may be committed along with the
+//  statement. Always set for implicit transactions.
 // firstInTxn: set for the first statement in a transaction. Used
 //  so that nested BEGIN statements are caught.
 // stmtTimestamp: Used as the statement_timestamp().
@@ -859,6 +1008,7 @@ func (e *Executor) execStmtInAbortedTxn(
 func (e *Executor) execStmtInOpenTxn(
 	stmt parser.Statement, planMaker *planner,
 	implicitTxn bool,
+	autoCommit bool,
 	firstInTxn bool,
 	stmtTimestamp parser.DTimestamp,
 	txnState *txnState) (Result, *roachpb.Error) {
@@ -888,6 +1038,18 @@ func (e *Executor) execStmtInOpenTxn(
 		}
 	}
 
+	switch stmt.(type) {
+	case *parser.CommitTransaction, *parser.RollbackTransaction:
+		// Never prevent a user from ending its transaction.
+	default:
+		if err := e.throttler.admitStatement(planMaker.user); err != nil {
+			txnState.aborted = true
+			pErr := roachpb.NewError(err)
+			return Result{PErr: pErr}, pErr
+		}
+		e.backpressure.admitStatement(e.drainCtx, stmt, planMaker.txn.Proto.Writing)
+	}
+
 	// Bind all the placeholder variables in the stmt to actual values.
 	stmt, err := parser.FillArgs(stmt, &planMaker.params)
 	if err != nil {
@@ -896,8 +1058,27 @@ func (e *Executor) execStmtInOpenTxn(
 		return Result{PErr: pErr}, pErr
 	}
 
-	result, pErr := e.execStmt(stmt, planMaker, time.Now(),
-		implicitTxn /* autoCommit */)
+	// The statements which aren't executed in parallel run after the
+	// writes deferred by the preceding ones, except ROLLBACK which discards
+	// them, COMMIT which sends them along with the commit, and the queries
+	// which only wait for them if they read the tables written.
+	switch stmt.(type) {
+	case *parser.RollbackTransaction:
+		planMaker.discardParallelWrites()
+	case *parser.CommitTransaction, *parser.Select, *parser.ParenSelect:
+	default:
+		if !planMaker.parallelStmt {
+			if pErr := planMaker.flushParallelWrites(); pErr != nil {
+				txnState.aborted = true
+				return Result{PErr: pErr}, pErr
+			}
+		}
+	}
+
+	planMaker.rowsRead = 0
+	result, pErr := e.execStmt(stmt, planMaker, time.Now(), autoCommit)
+	planMaker.parallelStmt = false
+	e.throttler.recordRowsRead(planMaker.user, planMaker.rowsRead)
 	txnDone := planMaker.txn == nil
 	if pErr != nil {
 		result = Result{PErr: pErr}
@@ -955,6 +1136,7 @@ func (e *Executor) execStmt(
 // updateStmtCounts updates metrics for the number of times the different types of SQL
 // statements have been received by this node.
 func (e *Executor) updateStmtCounts(stmt parser.Statement) {
+	e.queryCount.Add(1)
 	switch stmt.(type) {
 	case *parser.BeginTransaction:
 		e.txnBeginCount.Inc(1)
@@ -289,8 +289,8 @@ func (p *planner) Insert(n *parser.Insert, autoCommit bool) (planNode, *roachpb.
 		// An auto-txn can commit the transaction with the batch. This is an
 		// optimization to avoid an extra round-trip to the transaction
 		// coordinator.
-		pErr = p.txn.CommitInBatch(b)
-	} else {
+		pErr = p.commitInBatch(b)
+	} else if !p.deferWrites(b, &tableDesc) {
 		pErr = p.txn.Run(b)
 	}
 	if pErr != nil {
@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/client"
 	"github.com/cockroachdb/cockroach/roachpb"
 	"github.com/cockroachdb/cockroach/security"
+	"github.com/cockroachdb/cockroach/sql/parser"
 )
 
 // InternalExecutor can be used internally by cockroach to execute SQL
@@ -33,9 +34,32 @@ type InternalExecutor struct {
 // ExecuteStatementInTransaction executes the supplied SQL statement as part of
 // the supplied transaction. Statements are currently executed as the root user.
 func (ie InternalExecutor) ExecuteStatementInTransaction(txn *client.Txn, statement string, params ...interface{}) (int, *roachpb.Error) {
+	p := ie.makePlanner(txn)
+	return p.exec(statement, params...)
+}
+
+// QueryRowsInTransaction executes the supplied SQL query as part of the
+// supplied transaction and returns the resulting rows. Queries are currently
+// executed as the root user.
+func (ie InternalExecutor) QueryRowsInTransaction(txn *client.Txn, query string, params ...interface{}) ([]parser.DTuple, *roachpb.Error) {
+	p := ie.makePlanner(txn)
+	plan, pErr := p.query(query, params...)
+	if pErr != nil {
+		return nil, pErr
+	}
+	var rows []parser.DTuple
+	for plan.Next() {
+		rows = append(rows, append(parser.DTuple(nil), plan.Values()...))
+	}
+	return rows, plan.PErr()
+}
+
+// makePlanner returns a planner executing statements as the root user as
+// part of the supplied transaction.
+func (ie InternalExecutor) makePlanner(txn *client.Txn) *planner {
 	p := makePlanner()
 	p.setTxn(txn, txn.Proto.Timestamp.GoTime())
 	p.user = security.RootUser
 	p.leaseMgr = ie.LeaseManager
-	return p.exec(statement, params...)
+	return p
 }
@@ -96,6 +96,15 @@ func MakeZoneKey(id ID) roachpb.Key {
 	return keys.MakeColumnKey(k, uint32(zonesTable.Columns[1].ID))
 }
 
+// makeSettingKey returns the key for the value of the named setting in the
+// system.settings table.
+func makeSettingKey(name string) roachpb.Key {
+	k := keys.MakeTablePrefix(uint32(settingsTable.ID))
+	k = encoding.EncodeUvarintAscending(k, uint64(settingsTable.PrimaryIndex.ID))
+	k = encoding.EncodeStringAscending(k, name)
+	return keys.MakeColumnKey(k, uint32(settingsTable.Columns[1].ID))
+}
+
 // MakeIndexKeyPrefix returns the key prefix used for the index's data.
 func MakeIndexKeyPrefix(tableID ID, indexID IndexID) []byte {
 	key := keys.MakeTablePrefix(uint32(tableID))
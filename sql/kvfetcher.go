@@ -114,6 +114,9 @@ type kvFetcher struct {
 	kvs          []client.KeyValue
 	kvIndex      int
 	totalFetched int64
+	// resumeSpan is the part of a single span which remains to be fetched
+	// after a batch was stopped by its limit.
+	resumeSpan *roachpb.Span
 }
 
 // makeKVFetcher initializes a kvFetcher for the given spans. If non-zero, firstBatchLimit limits
@@ -127,11 +130,16 @@ func makeKVFetcher(txn *client.Txn, spans spans, reverse bool, firstBatchLimit i
 
 // fetch retrieves spans from the kv
 func (f *kvFetcher) fetch() *roachpb.Error {
+	if f.streamable() {
+		return f.fetchStream()
+	}
+
 	// Retrieve all the spans.
 	b := &client.Batch{}
 
 	// TODO(radu): until we have a per-batch limit (issue #4696), we
-	// only do batching if we have a single span.
+	// only do batching if we have a single span, or multiple spans
+	// without limits which are streamed.
 	if len(f.spans) == 1 {
 		count := int64(kvBatchSize)
 		if f.firstBatchLimit != 0 && f.firstBatchLimit < count && len(f.kvs) == 0 {
@@ -147,32 +155,15 @@ func (f *kvFetcher) fetch() *roachpb.Error {
 				count = remaining
 			}
 		}
+		start, end := f.spans[0].start, f.spans[0].end
+		if f.resumeSpan != nil {
+			// Resume where the previous batch was stopped by its limit.
+			start, end = f.resumeSpan.Key, f.resumeSpan.EndKey
+		}
 		if f.reverse {
-			end := f.spans[0].end
-			if len(f.kvs) > 0 {
-				// the new range ends at the last key (non-inclusive)
-				end = f.kvs[len(f.kvs)-1].Key
-				if end.Equal(f.spans[0].start) {
-					// No more keys
-					f.kvs = nil
-					f.fetchEnd = true
-					return nil
-				}
-			}
-			b.ReverseScan(f.spans[0].start, end, count)
+			b.ReverseScan(start, end, count)
 		} else {
-			start := f.spans[0].start
-			if len(f.kvs) > 0 {
-				// the new range starts after the last key
-				start = f.kvs[len(f.kvs)-1].Key.Next()
-				if start.Equal(f.spans[0].end) {
-					// No more keys
-					f.kvs = nil
-					f.fetchEnd = true
-					return nil
-				}
-			}
-			b.Scan(start, f.spans[0].end, count)
+			b.Scan(start, end, count)
 		}
 	} else {
 		if f.reverse {
@@ -206,8 +197,15 @@ func (f *kvFetcher) fetch() *roachpb.Error {
 	f.totalFetched += int64(len(f.kvs))
 	f.kvIndex = 0
 
-	if !(len(f.spans) == 1 && len(f.kvs) == kvBatchSize &&
-		(f.spans[0].count == 0 || f.spans[0].count > f.totalFetched)) {
+	// A single span is fetched in batches for as long as the batches are
+	// stopped by their limit before the end of the span, and the rows of
+	// the span's own limit haven't all been fetched.
+	f.resumeSpan = nil
+	if len(f.spans) == 1 {
+		f.resumeSpan = b.Results[0].ResumeSpan
+	}
+	if f.resumeSpan == nil || bytes.Compare(f.resumeSpan.Key, f.resumeSpan.EndKey) >= 0 ||
+		(f.spans[0].count != 0 && f.spans[0].count <= f.totalFetched) {
 		f.fetchEnd = true
 	}
 
@@ -217,6 +215,82 @@ func (f *kvFetcher) fetch() *roachpb.Error {
 	return nil
 }
 
+// streamable returns whether the spans are retrieved by streaming the rows of
+// the ranges they span, which is the case for multiple spans without limits.
+func (f *kvFetcher) streamable() bool {
+	if len(f.spans) < 2 {
+		return false
+	}
+	for _, s := range f.spans {
+		if s.count != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// fetchStream retrieves the next batch of rows of multiple spans. The rows
+// are streamed one range at a time, and the ranges which remain aren't read
+// once a batch worth of rows arrived; the spans are then trimmed to the rows
+// which remain for the next call.
+func (f *kvFetcher) fetchStream() *roachpb.Error {
+	b := &client.Batch{}
+	if f.reverse {
+		for i := len(f.spans) - 1; i >= 0; i-- {
+			b.ReverseScan(f.spans[i].start, f.spans[i].end, 0)
+		}
+	} else {
+		for i := 0; i < len(f.spans); i++ {
+			b.Scan(f.spans[i].start, f.spans[i].end, 0)
+		}
+	}
+
+	f.kvs = f.kvs[:0]
+	f.kvIndex = 0
+	stopped := false
+	if pErr := f.txn.RunStream(b, func(rows []client.KeyValue) (bool, error) {
+		f.kvs = append(f.kvs, rows...)
+		stopped = len(f.kvs) >= kvBatchSize
+		return stopped, nil
+	}); pErr != nil {
+		return pErr
+	}
+	f.totalFetched += int64(len(f.kvs))
+
+	if !stopped {
+		f.fetchEnd = true
+		return nil
+	}
+	// Trim the spans to the keys after (or before, in reverse) the last key
+	// retrieved.
+	last := f.kvs[len(f.kvs)-1].Key
+	// The spans are shared with the caller, so the trimmed ones are copied.
+	remaining := make(spans, 0, len(f.spans))
+	for _, s := range f.spans {
+		if f.reverse {
+			if s.start.Compare(last) >= 0 {
+				continue
+			}
+			if s.end.Compare(last) > 0 {
+				s.end = last
+			}
+		} else {
+			if s.end.Compare(last.Next()) <= 0 {
+				continue
+			}
+			if s.start.Compare(last.Next()) < 0 {
+				s.start = last.Next()
+			}
+		}
+		remaining = append(remaining, s)
+	}
+	f.spans = remaining
+	if len(f.spans) == 0 {
+		f.fetchEnd = true
+	}
+	return nil
+}
+
 // nextKV returns the next key/value (initiating fetches as necessary). When there are no more keys,
 // returns false and an empty key/value.
 func (f *kvFetcher) nextKV() (bool, client.KeyValue, *roachpb.Error) {
@@ -33,6 +33,7 @@ import (
 	"github.com/cockroachdb/cockroach/util"
 	"github.com/cockroachdb/cockroach/util/hlc"
 	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/metric"
 	"github.com/cockroachdb/cockroach/util/retry"
 	"github.com/cockroachdb/cockroach/util/stop"
 	"github.com/gogo/protobuf/proto"
@@ -72,12 +73,58 @@ func (s *LeaseState) Refcount() int {
 	return s.refcount
 }
 
+// leaseMetrics holds the metrics of a LeaseManager.
+type leaseMetrics struct {
+	// acquisitions, acquisitionErrors and releases count the leases
+	// inserted into and deleted from the lease table by the node.
+	acquisitions      *metric.Counter
+	acquisitionErrors *metric.Counter
+	releases          *metric.Counter
+	// cacheHits counts the requests for a lease served by a lease the node
+	// already held, and cacheMisses those which had to wait for the node to
+	// acquire one.
+	cacheHits   *metric.Counter
+	cacheMisses *metric.Counter
+	// blocked records how long the requests which missed waited for a
+	// lease.
+	blocked metric.Latency
+	// publishWait records how long the publication of a new version of a
+	// table descriptor waited for the leases on the previous version to be
+	// released or to expire.
+	publishWait metric.Latency
+}
+
+func makeLeaseMetrics(registry *metric.Registry) leaseMetrics {
+	return leaseMetrics{
+		acquisitions:      registry.Counter("acquisitions"),
+		acquisitionErrors: registry.Counter("acquisition.errors"),
+		releases:          registry.Counter("releases"),
+		cacheHits:         registry.Counter("cache.hits"),
+		cacheMisses:       registry.Counter("cache.misses"),
+		blocked:           registry.Latency("blocked"),
+		publishWait:       registry.Latency("publish.wait"),
+	}
+}
+
+// recordLookup records a request for a lease which was served, after
+// waiting for the node to acquire a lease since blockedSince unless it is
+// zero.
+func (m *leaseMetrics) recordLookup(blockedSince time.Time) {
+	if blockedSince.IsZero() {
+		m.cacheHits.Inc(1)
+		return
+	}
+	m.cacheMisses.Inc(1)
+	m.blocked.RecordValue(time.Since(blockedSince))
+}
+
 // LeaseStore implements the operations for acquiring and releasing leases and
 // publishing a new version of a descriptor. Exported only for testing.
 type LeaseStore struct {
-	db     client.DB
-	clock  *hlc.Clock
-	nodeID uint32
+	db      client.DB
+	clock   *hlc.Clock
+	nodeID  uint32
+	metrics *leaseMetrics
 }
 
 // jitteredLeaseDuration returns a randomly jittered duration from the interval
@@ -152,6 +199,11 @@ func (s LeaseStore) Acquire(txn *client.Txn, tableID ID, minVersion DescriptorVe
 		}
 		return nil
 	})
+	if pErr != nil {
+		s.metrics.acquisitionErrors.Inc(1)
+	} else {
+		s.metrics.acquisitions.Inc(1)
+	}
 	return lease, pErr
 }
 
@@ -173,6 +225,9 @@ func (s LeaseStore) Release(lease *LeaseState) error {
 		}
 		return nil
 	})
+	if pErr == nil {
+		s.metrics.releases.Inc(1)
+	}
 	return pErr.GoError()
 }
 
@@ -183,6 +238,9 @@ func (s LeaseStore) Release(lease *LeaseState) error {
 // invariant that no new leases for desc.Version-1 will be granted once
 // desc.Version exists.
 func (s LeaseStore) waitForOneVersion(tableID ID, retryOpts retry.Options) (DescriptorVersion, error) {
+	defer func(start time.Time) {
+		s.metrics.publishWait.RecordValue(time.Since(start))
+	}(time.Now())
 	desc := &Descriptor{}
 	descKey := MakeDescMetadataKey(tableID)
 	var tableDesc *TableDescriptor
@@ -424,6 +482,9 @@ func (t *tableState) acquire(txn *client.Txn, version DescriptorVersion, store L
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
+	// blockedSince is the time at which the request started waiting for
+	// the node to acquire a lease, if it had to.
+	var blockedSince time.Time
 	for {
 		s := t.active.findNewest(version)
 		if s != nil {
@@ -438,6 +499,7 @@ func (t *tableState) acquire(txn *client.Txn, version DescriptorVersion, store L
 				if log.V(3) {
 					log.Infof("acquire: descID=%d version=%d refcount=%d", s.ID, s.Version, s.refcount)
 				}
+				store.metrics.recordLookup(blockedSince)
 				return s, nil
 			}
 			minDesiredExpiration := store.clock.Now().GoTime().Add(MinLeaseDuration)
@@ -446,6 +508,7 @@ func (t *tableState) acquire(txn *client.Txn, version DescriptorVersion, store L
 				if log.V(3) {
 					log.Infof("acquire: descID=%d version=%d refcount=%d", s.ID, s.Version, s.refcount)
 				}
+				store.metrics.recordLookup(blockedSince)
 				return s, nil
 			}
 		} else if version != 0 {
@@ -456,6 +519,9 @@ func (t *tableState) acquire(txn *client.Txn, version DescriptorVersion, store L
 			}
 		}
 
+		if blockedSince.IsZero() {
+			blockedSince = time.Now()
+		}
 		if t.acquiring != nil {
 			// There is already a lease acquisition in progress. Wait for it to complete.
 			t.acquireWait()
@@ -558,13 +624,16 @@ type LeaseManager struct {
 	tables map[ID]*tableState
 }
 
-// NewLeaseManager creates a new LeaseManager.
-func NewLeaseManager(nodeID uint32, db client.DB, clock *hlc.Clock) *LeaseManager {
+// NewLeaseManager creates a new LeaseManager. Its metrics are added to the
+// given registry.
+func NewLeaseManager(nodeID uint32, db client.DB, clock *hlc.Clock, registry *metric.Registry) *LeaseManager {
+	metrics := makeLeaseMetrics(registry)
 	return &LeaseManager{
 		LeaseStore: LeaseStore{
-			db:     db,
-			clock:  clock,
-			nodeID: nodeID,
+			db:      db,
+			clock:   clock,
+			nodeID:  nodeID,
+			metrics: &metrics,
 		},
 		tables: make(map[ID]*tableState),
 	}
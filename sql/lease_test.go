@@ -30,6 +30,7 @@ import (
 	csql "github.com/cockroachdb/cockroach/sql"
 	"github.com/cockroachdb/cockroach/testutils"
 	"github.com/cockroachdb/cockroach/util/leaktest"
+	"github.com/cockroachdb/cockroach/util/metric"
 )
 
 type leaseTest struct {
@@ -131,7 +132,7 @@ func (t *leaseTest) mustPublish(nodeID uint32, descID csql.ID) {
 func (t *leaseTest) node(nodeID uint32) *csql.LeaseManager {
 	mgr := t.nodes[nodeID]
 	if mgr == nil {
-		mgr = csql.NewLeaseManager(nodeID, *t.server.DB(), t.server.Clock())
+		mgr = csql.NewLeaseManager(nodeID, *t.server.DB(), t.server.Clock(), metric.NewRegistry())
 		t.nodes[nodeID] = mgr
 	}
 	return mgr
@@ -277,6 +278,49 @@ func TestLeaseManagerReacquire(testingT *testing.T) {
 	t.mustRelease(1, l3)
 }
 
+// TestLeaseManagerMetrics verifies that the lease manager counts the leases
+// it acquires and releases, and the requests served by the leases it holds.
+func TestLeaseManagerMetrics(testingT *testing.T) {
+	defer leaktest.AfterTest(testingT)
+	t := newLeaseTest(testingT)
+	defer t.cleanup()
+
+	registry := metric.NewRegistry()
+	t.nodes[1] = csql.NewLeaseManager(1, *t.server.DB(), t.server.Clock(), registry)
+	expectCounts := func(expected map[string]int64) {
+		for name, count := range expected {
+			if c := registry.GetCounter(name).Count(); c != count {
+				t.Errorf("expected %s to be %d, but found %d", name, count, c)
+			}
+		}
+	}
+
+	const descID = keys.LeaseTableID
+
+	// The first request waits for the node to acquire a lease, which serves
+	// the second.
+	l1 := t.mustAcquire(1, descID, 0)
+	l2 := t.mustAcquire(1, descID, 0)
+	expectCounts(map[string]int64{
+		"acquisitions": 1,
+		"releases":     0,
+		"cache.hits":   1,
+		"cache.misses": 1,
+	})
+
+	// A new version replaces the lease on the old one once it's unused.
+	t.mustRelease(1, l1)
+	t.mustRelease(1, l2)
+	t.mustPublish(1, descID)
+	t.mustRelease(1, t.mustAcquire(1, descID, 2))
+	expectCounts(map[string]int64{
+		"acquisitions": 2,
+		"releases":     1,
+		"cache.hits":   1,
+		"cache.misses": 2,
+	})
+}
+
 func TestLeaseManagerPublishVersionChanged(testingT *testing.T) {
 	defer leaktest.AfterTest(testingT)
 	t := newLeaseTest(testingT)
@@ -30,9 +30,16 @@ func (p *planner) limit(limit *parser.Limit, plan planNode) (planNode, error) {
 	if limit == nil {
 		return plan, nil
 	}
+	count, offset, err := p.evalLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+	return makeLimit(plan, count, offset), nil
+}
 
-	var count, offset int64
-
+// evalLimit evaluates the LIMIT and OFFSET clauses. A missing or NULL count
+// is returned as math.MaxInt64.
+func (p *planner) evalLimit(limit *parser.Limit) (count, offset int64, err error) {
 	data := []struct {
 		name       string
 		src        parser.Expr
@@ -48,16 +55,16 @@ func (p *planner) limit(limit *parser.Limit, plan planNode) (planNode, error) {
 			*datum.dst = datum.defaultVal
 		} else {
 			if parser.ContainsVars(datum.src) {
-				return nil, util.Errorf("argument of %s must not contain variables", datum.name)
+				return 0, 0, util.Errorf("argument of %s must not contain variables", datum.name)
 			}
 
 			normalized, err := p.parser.NormalizeExpr(p.evalCtx, datum.src)
 			if err != nil {
-				return nil, err
+				return 0, 0, err
 			}
 			dstDatum, err := normalized.Eval(p.evalCtx)
 			if err != nil {
-				return nil, err
+				return 0, 0, err
 			}
 
 			if dstDatum == parser.DNull {
@@ -70,15 +77,26 @@ func (p *planner) limit(limit *parser.Limit, plan planNode) (planNode, error) {
 				continue
 			}
 
-			return nil, fmt.Errorf("argument of %s must be type %s, not type %s", datum.name, parser.DummyInt.Type(), dstDatum.Type())
+			return 0, 0, fmt.Errorf("argument of %s must be type %s, not type %s", datum.name, parser.DummyInt.Type(), dstDatum.Type())
 		}
 	}
+	return count, offset, nil
+}
 
+// makeLimit wraps the plan with a limitNode returning count rows after the
+// first offset rows.
+func makeLimit(plan planNode, count, offset int64) planNode {
 	if count != math.MaxInt64 {
 		plan.SetLimitHint(offset + count)
+		// A sort directly beneath the limit only needs to retain the rows the
+		// limit returns. A sort beneath a DISTINCT doesn't know how many of
+		// its rows are needed.
+		if sort, ok := plan.(*sortNode); ok {
+			sort.limit = offset + count
+		}
 	}
 
-	return &limitNode{planNode: plan, count: count, offset: offset}, nil
+	return &limitNode{planNode: plan, count: count, offset: offset}
 }
 
 type limitNode struct {
@@ -3,8 +3,13 @@ package sql_test
 import (
 	"testing"
 
+	"github.com/cockroachdb/cockroach/keys"
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/server"
+	"github.com/cockroachdb/cockroach/sql"
 	"github.com/cockroachdb/cockroach/testutils"
 	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/encoding"
 	"github.com/cockroachdb/cockroach/util/leaktest"
 )
 
@@ -146,3 +151,85 @@ func TestAbortCountErrorDuringTransaction(t *testing.T) {
 	checkCounterEQ(t, s, "txn.begin.count", 1)
 	checkCounterEQ(t, s, "select.count", 1)
 }
+
+// TestOnePCCounts tests that explicit transactions are committed along with
+// their last write when the executor commits eagerly, and that the
+// transactions committed along with their first writes are counted.
+func TestOnePCCounts(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := server.NewTestContext()
+	ctx.SQLEagerCommit = true
+	s, sqlDB, kvDB := setupWithContext(t, ctx)
+	defer cleanup(s, sqlDB)
+
+	if _, err := sqlDB.Exec(`
+CREATE DATABASE t;
+CREATE TABLE t.kv (k INT PRIMARY KEY);
+`); err != nil {
+		t.Fatal(err)
+	}
+	// Split the table before k = 10, so that the transactions writing on
+	// either side of it span two ranges.
+	gr, err := kvDB.Get(sql.MakeNameMetadataKey(keys.MaxReservedDescID+1, "kv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	splitKey := roachpb.Key(sql.MakeIndexKeyPrefix(sql.ID(gr.ValueInt()), 1))
+	splitKey = encoding.EncodeVarintAscending(splitKey, 10)
+	if err := kvDB.AdminSplit(splitKey); err != nil {
+		t.Fatal(err)
+	}
+	attempts := s.MustGetSQLCounter("txn.1pc.attempts")
+	commits := s.MustGetSQLCounter("txn.1pc.commits")
+
+	var testcases = []struct {
+		query    string
+		attempts int64
+		commits  int64
+	}{
+		// Implicit transactions are always committed along with their
+		// statement.
+		{"INSERT INTO t.kv VALUES (1)", 1, 1},
+		// The INSERT is committed eagerly, along with its BeginTransaction.
+		{"BEGIN; INSERT INTO t.kv VALUES (2); COMMIT", 1, 1},
+		// The last INSERT is committed eagerly, but the transaction
+		// started writing before.
+		{"BEGIN; INSERT INTO t.kv VALUES (3); INSERT INTO t.kv VALUES (4); COMMIT", 0, 0},
+		// Nothing is written.
+		{"BEGIN; DELETE FROM t.kv WHERE k = 10; COMMIT", 0, 0},
+		// The writes span two ranges, so the transaction commits in two
+		// phases.
+		{"INSERT INTO t.kv VALUES (5), (15)", 1, 0},
+		{"BEGIN; INSERT INTO t.kv VALUES (6), (16); COMMIT", 1, 0},
+	}
+	for _, tc := range testcases {
+		if _, err := sqlDB.Exec(tc.query); err != nil {
+			t.Fatalf("unexpected error executing '%s': %s'", tc.query, err)
+		}
+		attempts += tc.attempts
+		commits += tc.commits
+		checkCounterEQ(t, s, "txn.1pc.attempts", attempts)
+		checkCounterEQ(t, s, "txn.1pc.commits", commits)
+	}
+
+	// A COMMIT sent on its own commits normally.
+	txn, err := sqlDB.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txn.Exec("INSERT INTO t.kv VALUES (7)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	checkCounterEQ(t, s, "txn.1pc.attempts", attempts)
+
+	var count int
+	if err := sqlDB.QueryRow("SELECT COUNT(*) FROM t.kv").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 9 {
+		t.Fatalf("expected 9 rows, got %d", count)
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"sync"
+
+	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/sql/parser"
+)
+
+// parallelWrites holds the writes of the INSERT, UPDATE and DELETE
+// statements executed with RETURNING NOTHING in an explicit transaction.
+// Such statements return as soon as their writes are planned, and their
+// writes are sent later together in a single KV batch. The writes are kept
+// with the session across requests, and sent along with the commit of the
+// transaction, or before a statement reads one of the tables written or
+// writes itself; their errors are returned by that statement.
+type parallelWrites struct {
+	pending []parallelWrite
+}
+
+type parallelWrite struct {
+	b         *client.Batch
+	tableDesc *TableDescriptor
+}
+
+// sessionParallelWrites holds the writes deferred by the open transactions
+// of the sessions between their requests.
+type sessionParallelWrites struct {
+	mu       sync.Mutex
+	sessions map[*Session]*parallelWrites
+}
+
+// take removes and returns the writes deferred by the transaction of the
+// session, if any.
+func (s *sessionParallelWrites) take(session *Session) *parallelWrites {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w := s.sessions[session]
+	delete(s.sessions, session)
+	return w
+}
+
+// put keeps the writes deferred by the transaction of the session until
+// its next request.
+func (s *sessionParallelWrites) put(session *Session, w *parallelWrites) {
+	if w == nil || len(w.pending) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessions == nil {
+		s.sessions = map[*Session]*parallelWrites{}
+	}
+	s.sessions[session] = w
+}
+
+// isParallelizable returns whether the statement can be executed in
+// parallel with the statements which follow it.
+func isParallelizable(stmt parser.Statement) bool {
+	switch t := stmt.(type) {
+	case *parser.Insert:
+		return t.Returning.IsNothing()
+	case *parser.Update:
+		return t.Returning.IsNothing()
+	case *parser.Delete:
+		return t.Returning.IsNothing()
+	}
+	return false
+}
+
+// deferWrites queues the writes of the batch if the current statement is
+// executed in parallel. If it returns false, the caller must run the
+// batch itself. tableDesc, if not nil, is used to report uniqueness
+// violations.
+func (p *planner) deferWrites(b *client.Batch, tableDesc *TableDescriptor) bool {
+	if !p.parallelStmt {
+		return false
+	}
+	p.parallel.pending = append(p.parallel.pending, parallelWrite{b: b, tableDesc: tableDesc})
+	return true
+}
+
+// awaitParallelWrites sends the deferred writes if one of them is to the
+// table with the given ID, so that the current statement observes them.
+func (p *planner) awaitParallelWrites(id ID) *roachpb.Error {
+	if p.parallel == nil {
+		return nil
+	}
+	for _, w := range p.parallel.pending {
+		if w.tableDesc == nil || w.tableDesc.ID == id {
+			return p.flushParallelWrites()
+		}
+	}
+	return nil
+}
+
+// flushParallelWrites sends the deferred writes and returns the error of
+// the first statement whose writes failed.
+func (p *planner) flushParallelWrites() *roachpb.Error {
+	if !p.hasParallelWrites() {
+		return nil
+	}
+	return p.sendParallelWrites(p.txn.RunBatches)
+}
+
+// commitParallelWrites sends the deferred writes along with the commit of
+// the transaction, and returns the error of the first statement whose
+// writes failed, or that of the commit.
+func (p *planner) commitParallelWrites() *roachpb.Error {
+	return p.sendParallelWrites(p.txn.CommitInBatches)
+}
+
+// hasParallelWrites returns whether writes were deferred.
+func (p *planner) hasParallelWrites() bool {
+	return p.parallel != nil && len(p.parallel.pending) > 0
+}
+
+func (p *planner) sendParallelWrites(send func(...*client.Batch) *roachpb.Error) *roachpb.Error {
+	pending := p.parallel.pending
+	p.parallel.pending = nil
+	batches := make([]*client.Batch, len(pending))
+	for i := range pending {
+		batches[i] = pending[i].b
+	}
+	pErr := send(batches...)
+	if pErr == nil {
+		return nil
+	}
+	for _, w := range pending {
+		for _, r := range w.b.Results {
+			if r.PErr == pErr && w.tableDesc != nil {
+				return convertBatchError(w.tableDesc, *w.b, pErr)
+			}
+		}
+	}
+	return pErr
+}
+
+// discardParallelWrites drops the deferred writes of a transaction which
+// is rolled back.
+func (p *planner) discardParallelWrites() {
+	if p.parallel != nil {
+		p.parallel.pending = nil
+	}
+}
@@ -507,6 +507,24 @@ var builtins = map[string][]builtin{
 		},
 	},
 
+	"zone_config": {
+		builtin{
+			types:      argTypes{stringType},
+			returnType: typeString,
+			impure:     true,
+			fn: func(ctx EvalContext, args DTuple) (Datum, error) {
+				if ctx.GetZoneConfig == nil {
+					return DNull, errors.New("zone configs are not available in this context")
+				}
+				zone, err := ctx.GetZoneConfig(string(args[0].(DString)))
+				if err != nil {
+					return DNull, err
+				}
+				return DString(zone), nil
+			},
+		},
+	},
+
 	"greatest": {
 		builtin{
 			types:      anyType{},
@@ -744,6 +744,10 @@ type EvalContext struct {
 	ReCache       *RegexpCache
 	GetLocation   func() (*time.Location, error)
 	Args          MapArgs
+	// GetZoneConfig returns the zone config which applies to the table with
+	// the given name, following the inheritance from databases and the
+	// default zone config. It is used by the zone_config function.
+	GetZoneConfig func(table string) (string, error)
 }
 
 var defaultContext = EvalContext{
@@ -3,234 +3,238 @@
 package parser
 
 var keywords = map[string]int{
-	"ACTION":            ACTION,
-	"ADD":               ADD,
-	"ALL":               ALL,
-	"ALTER":             ALTER,
-	"ANALYSE":           ANALYSE,
-	"ANALYZE":           ANALYZE,
-	"AND":               AND,
-	"ANY":               ANY,
-	"ARRAY":             ARRAY,
-	"AS":                AS,
-	"ASC":               ASC,
-	"ASYMMETRIC":        ASYMMETRIC,
-	"AT":                AT,
-	"BEGIN":             BEGIN,
-	"BETWEEN":           BETWEEN,
-	"BIGINT":            BIGINT,
-	"BIT":               BIT,
-	"BLOB":              BLOB,
-	"BOOL":              BOOL,
-	"BOOLEAN":           BOOLEAN,
-	"BOTH":              BOTH,
-	"BY":                BY,
-	"BYTEA":             BYTEA,
-	"BYTES":             BYTES,
-	"CASCADE":           CASCADE,
-	"CASE":              CASE,
-	"CAST":              CAST,
-	"CHAR":              CHAR,
-	"CHARACTER":         CHARACTER,
-	"CHARACTERISTICS":   CHARACTERISTICS,
-	"CHECK":             CHECK,
-	"COALESCE":          COALESCE,
-	"COLLATE":           COLLATE,
-	"COLLATION":         COLLATION,
-	"COLUMN":            COLUMN,
-	"COLUMNS":           COLUMNS,
-	"COMMIT":            COMMIT,
-	"COMMITTED":         COMMITTED,
-	"CONFLICT":          CONFLICT,
-	"CONSTRAINT":        CONSTRAINT,
-	"COVERING":          COVERING,
-	"CREATE":            CREATE,
-	"CROSS":             CROSS,
-	"CUBE":              CUBE,
-	"CURRENT":           CURRENT,
-	"CURRENT_CATALOG":   CURRENT_CATALOG,
-	"CURRENT_DATE":      CURRENT_DATE,
-	"CURRENT_ROLE":      CURRENT_ROLE,
-	"CURRENT_TIME":      CURRENT_TIME,
-	"CURRENT_TIMESTAMP": CURRENT_TIMESTAMP,
-	"CURRENT_USER":      CURRENT_USER,
-	"CYCLE":             CYCLE,
-	"DATA":              DATA,
-	"DATABASE":          DATABASE,
-	"DATABASES":         DATABASES,
-	"DATE":              DATE,
-	"DAY":               DAY,
-	"DEC":               DEC,
-	"DECIMAL":           DECIMAL,
-	"DEFAULT":           DEFAULT,
-	"DEFERRABLE":        DEFERRABLE,
-	"DELETE":            DELETE,
-	"DESC":              DESC,
-	"DISTINCT":          DISTINCT,
-	"DO":                DO,
-	"DOUBLE":            DOUBLE,
-	"DROP":              DROP,
-	"ELSE":              ELSE,
-	"END":               END,
-	"EXCEPT":            EXCEPT,
-	"EXISTS":            EXISTS,
-	"EXPLAIN":           EXPLAIN,
-	"EXTRACT":           EXTRACT,
-	"FALSE":             FALSE,
-	"FETCH":             FETCH,
-	"FILTER":            FILTER,
-	"FIRST":             FIRST,
-	"FLOAT":             FLOAT,
-	"FOLLOWING":         FOLLOWING,
-	"FOR":               FOR,
-	"FOREIGN":           FOREIGN,
-	"FROM":              FROM,
-	"FULL":              FULL,
-	"GRANT":             GRANT,
-	"GRANTS":            GRANTS,
-	"GREATEST":          GREATEST,
-	"GROUP":             GROUP,
-	"GROUPING":          GROUPING,
-	"HAVING":            HAVING,
-	"HIGH":              HIGH,
-	"HOUR":              HOUR,
-	"IF":                IF,
-	"IFNULL":            IFNULL,
-	"IN":                IN,
-	"INDEX":             INDEX,
-	"INDEXES":           INDEXES,
-	"INITIALLY":         INITIALLY,
-	"INNER":             INNER,
-	"INSERT":            INSERT,
-	"INT":               INT,
-	"INT64":             INT64,
-	"INTEGER":           INTEGER,
-	"INTERSECT":         INTERSECT,
-	"INTERVAL":          INTERVAL,
-	"INTO":              INTO,
-	"IS":                IS,
-	"ISOLATION":         ISOLATION,
-	"JOIN":              JOIN,
-	"KEY":               KEY,
-	"KEYS":              KEYS,
-	"LATERAL":           LATERAL,
-	"LEADING":           LEADING,
-	"LEAST":             LEAST,
-	"LEFT":              LEFT,
-	"LEVEL":             LEVEL,
-	"LIKE":              LIKE,
-	"LIMIT":             LIMIT,
-	"LOCAL":             LOCAL,
-	"LOCALTIME":         LOCALTIME,
-	"LOCALTIMESTAMP":    LOCALTIMESTAMP,
-	"LOW":               LOW,
-	"MATCH":             MATCH,
-	"MINUTE":            MINUTE,
-	"MONTH":             MONTH,
-	"NAME":              NAME,
-	"NAMES":             NAMES,
-	"NATURAL":           NATURAL,
-	"NEXT":              NEXT,
-	"NO":                NO,
-	"NORMAL":            NORMAL,
-	"NOT":               NOT,
-	"NOTHING":           NOTHING,
-	"NULL":              NULL,
-	"NULLIF":            NULLIF,
-	"NULLS":             NULLS,
-	"NUMERIC":           NUMERIC,
-	"OF":                OF,
-	"OFF":               OFF,
-	"OFFSET":            OFFSET,
-	"ON":                ON,
-	"ONLY":              ONLY,
-	"OR":                OR,
-	"ORDER":             ORDER,
-	"ORDINALITY":        ORDINALITY,
-	"OUT":               OUT,
-	"OUTER":             OUTER,
-	"OVER":              OVER,
-	"OVERLAPS":          OVERLAPS,
-	"OVERLAY":           OVERLAY,
-	"PARTIAL":           PARTIAL,
-	"PARTITION":         PARTITION,
-	"PLACING":           PLACING,
-	"POSITION":          POSITION,
-	"PRECEDING":         PRECEDING,
-	"PRECISION":         PRECISION,
-	"PRIMARY":           PRIMARY,
-	"PRIORITY":          PRIORITY,
-	"RANGE":             RANGE,
-	"READ":              READ,
-	"REAL":              REAL,
-	"RECURSIVE":         RECURSIVE,
-	"REF":               REF,
-	"REFERENCES":        REFERENCES,
-	"RENAME":            RENAME,
-	"REPEATABLE":        REPEATABLE,
-	"RESTRICT":          RESTRICT,
-	"RETURNING":         RETURNING,
-	"REVOKE":            REVOKE,
-	"RIGHT":             RIGHT,
-	"ROLLBACK":          ROLLBACK,
-	"ROLLUP":            ROLLUP,
-	"ROW":               ROW,
-	"ROWS":              ROWS,
-	"SEARCH":            SEARCH,
-	"SECOND":            SECOND,
-	"SELECT":            SELECT,
-	"SERIALIZABLE":      SERIALIZABLE,
-	"SESSION":           SESSION,
-	"SESSION_USER":      SESSION_USER,
-	"SET":               SET,
-	"SHOW":              SHOW,
-	"SIMILAR":           SIMILAR,
-	"SIMPLE":            SIMPLE,
-	"SMALLINT":          SMALLINT,
-	"SNAPSHOT":          SNAPSHOT,
-	"SOME":              SOME,
-	"SQL":               SQL,
-	"START":             START,
-	"STORING":           STORING,
-	"STRICT":            STRICT,
-	"STRING":            STRING,
-	"SUBSTRING":         SUBSTRING,
-	"SYMMETRIC":         SYMMETRIC,
-	"TABLE":             TABLE,
-	"TABLES":            TABLES,
-	"TEXT":              TEXT,
-	"THEN":              THEN,
-	"TIME":              TIME,
-	"TIMESTAMP":         TIMESTAMP,
-	"TO":                TO,
-	"TRAILING":          TRAILING,
-	"TRANSACTION":       TRANSACTION,
-	"TREAT":             TREAT,
-	"TRIM":              TRIM,
-	"TRUE":              TRUE,
-	"TRUNCATE":          TRUNCATE,
-	"TYPE":              TYPE,
-	"UNBOUNDED":         UNBOUNDED,
-	"UNCOMMITTED":       UNCOMMITTED,
-	"UNION":             UNION,
-	"UNIQUE":            UNIQUE,
-	"UNKNOWN":           UNKNOWN,
-	"UPDATE":            UPDATE,
-	"USER":              USER,
-	"USING":             USING,
-	"VALID":             VALID,
-	"VALIDATE":          VALIDATE,
-	"VALUE":             VALUE,
-	"VALUES":            VALUES,
-	"VARCHAR":           VARCHAR,
-	"VARIADIC":          VARIADIC,
-	"VARYING":           VARYING,
-	"WHEN":              WHEN,
-	"WHERE":             WHERE,
-	"WINDOW":            WINDOW,
-	"WITH":              WITH,
-	"WITHIN":            WITHIN,
-	"WITHOUT":           WITHOUT,
-	"YEAR":              YEAR,
-	"ZONE":              ZONE,
+	"ACTION":              ACTION,
+	"ADD":                 ADD,
+	"ALL":                 ALL,
+	"ALTER":               ALTER,
+	"ANALYSE":             ANALYSE,
+	"ANALYZE":             ANALYZE,
+	"AND":                 AND,
+	"ANY":                 ANY,
+	"ARRAY":               ARRAY,
+	"AS":                  AS,
+	"ASC":                 ASC,
+	"ASYMMETRIC":          ASYMMETRIC,
+	"AT":                  AT,
+	"BEGIN":               BEGIN,
+	"BETWEEN":             BETWEEN,
+	"BIGINT":              BIGINT,
+	"BIT":                 BIT,
+	"BLOB":                BLOB,
+	"BOOL":                BOOL,
+	"BOOLEAN":             BOOLEAN,
+	"BOTH":                BOTH,
+	"BY":                  BY,
+	"BYTEA":               BYTEA,
+	"BYTES":               BYTES,
+	"CASCADE":             CASCADE,
+	"CASE":                CASE,
+	"CAST":                CAST,
+	"CHAR":                CHAR,
+	"CHARACTER":           CHARACTER,
+	"CHARACTERISTICS":     CHARACTERISTICS,
+	"CHECK":               CHECK,
+	"CLUSTER":             CLUSTER,
+	"COALESCE":            COALESCE,
+	"COLLATE":             COLLATE,
+	"COLLATION":           COLLATION,
+	"COLUMN":              COLUMN,
+	"COLUMNS":             COLUMNS,
+	"COMMIT":              COMMIT,
+	"COMMITTED":           COMMITTED,
+	"CONFLICT":            CONFLICT,
+	"CONSTRAINT":          CONSTRAINT,
+	"COVERING":            COVERING,
+	"CREATE":              CREATE,
+	"CROSS":               CROSS,
+	"CUBE":                CUBE,
+	"CURRENT":             CURRENT,
+	"CURRENT_CATALOG":     CURRENT_CATALOG,
+	"CURRENT_DATE":        CURRENT_DATE,
+	"CURRENT_ROLE":        CURRENT_ROLE,
+	"CURRENT_TIME":        CURRENT_TIME,
+	"CURRENT_TIMESTAMP":   CURRENT_TIMESTAMP,
+	"CURRENT_USER":        CURRENT_USER,
+	"CYCLE":               CYCLE,
+	"DATA":                DATA,
+	"DATABASE":            DATABASE,
+	"DATABASES":           DATABASES,
+	"DATE":                DATE,
+	"DAY":                 DAY,
+	"DEC":                 DEC,
+	"DECIMAL":             DECIMAL,
+	"DEFAULT":             DEFAULT,
+	"DEFERRABLE":          DEFERRABLE,
+	"DELETE":              DELETE,
+	"DESC":                DESC,
+	"DISTINCT":            DISTINCT,
+	"DO":                  DO,
+	"DOUBLE":              DOUBLE,
+	"DROP":                DROP,
+	"ELSE":                ELSE,
+	"END":                 END,
+	"EXCEPT":              EXCEPT,
+	"EXISTS":              EXISTS,
+	"EXPERIMENTAL_RANGES": EXPERIMENTAL_RANGES,
+	"EXPLAIN":             EXPLAIN,
+	"EXTRACT":             EXTRACT,
+	"FALSE":               FALSE,
+	"FETCH":               FETCH,
+	"FILTER":              FILTER,
+	"FIRST":               FIRST,
+	"FLOAT":               FLOAT,
+	"FOLLOWING":           FOLLOWING,
+	"FOR":                 FOR,
+	"FOREIGN":             FOREIGN,
+	"FROM":                FROM,
+	"FULL":                FULL,
+	"GRANT":               GRANT,
+	"GRANTS":              GRANTS,
+	"GREATEST":            GREATEST,
+	"GROUP":               GROUP,
+	"GROUPING":            GROUPING,
+	"HAVING":              HAVING,
+	"HIGH":                HIGH,
+	"HOUR":                HOUR,
+	"IF":                  IF,
+	"IFNULL":              IFNULL,
+	"IN":                  IN,
+	"INDEX":               INDEX,
+	"INDEXES":             INDEXES,
+	"INITIALLY":           INITIALLY,
+	"INNER":               INNER,
+	"INSERT":              INSERT,
+	"INT":                 INT,
+	"INT64":               INT64,
+	"INTEGER":             INTEGER,
+	"INTERSECT":           INTERSECT,
+	"INTERVAL":            INTERVAL,
+	"INTO":                INTO,
+	"IS":                  IS,
+	"ISOLATION":           ISOLATION,
+	"JOIN":                JOIN,
+	"KEY":                 KEY,
+	"KEYS":                KEYS,
+	"LATERAL":             LATERAL,
+	"LEADING":             LEADING,
+	"LEAST":               LEAST,
+	"LEFT":                LEFT,
+	"LEVEL":               LEVEL,
+	"LIKE":                LIKE,
+	"LIMIT":               LIMIT,
+	"LOCAL":               LOCAL,
+	"LOCALTIME":           LOCALTIME,
+	"LOCALTIMESTAMP":      LOCALTIMESTAMP,
+	"LOW":                 LOW,
+	"MATCH":               MATCH,
+	"MINUTE":              MINUTE,
+	"MONTH":               MONTH,
+	"NAME":                NAME,
+	"NAMES":               NAMES,
+	"NATURAL":             NATURAL,
+	"NEXT":                NEXT,
+	"NO":                  NO,
+	"NORMAL":              NORMAL,
+	"NOT":                 NOT,
+	"NOTHING":             NOTHING,
+	"NULL":                NULL,
+	"NULLIF":              NULLIF,
+	"NULLS":               NULLS,
+	"NUMERIC":             NUMERIC,
+	"OF":                  OF,
+	"OFF":                 OFF,
+	"OFFSET":              OFFSET,
+	"ON":                  ON,
+	"ONLY":                ONLY,
+	"OR":                  OR,
+	"ORDER":               ORDER,
+	"ORDINALITY":          ORDINALITY,
+	"OUT":                 OUT,
+	"OUTER":               OUTER,
+	"OVER":                OVER,
+	"OVERLAPS":            OVERLAPS,
+	"OVERLAY":             OVERLAY,
+	"PARTIAL":             PARTIAL,
+	"PARTITION":           PARTITION,
+	"PLACING":             PLACING,
+	"POSITION":            POSITION,
+	"PRECEDING":           PRECEDING,
+	"PRECISION":           PRECISION,
+	"PRIMARY":             PRIMARY,
+	"PRIORITY":            PRIORITY,
+	"RANGE":               RANGE,
+	"READ":                READ,
+	"REAL":                REAL,
+	"RECURSIVE":           RECURSIVE,
+	"REF":                 REF,
+	"REFERENCES":          REFERENCES,
+	"RENAME":              RENAME,
+	"REPEATABLE":          REPEATABLE,
+	"RESTRICT":            RESTRICT,
+	"RETURNING":           RETURNING,
+	"REVOKE":              REVOKE,
+	"RIGHT":               RIGHT,
+	"ROLLBACK":            ROLLBACK,
+	"ROLLUP":              ROLLUP,
+	"ROW":                 ROW,
+	"ROWS":                ROWS,
+	"SEARCH":              SEARCH,
+	"SECOND":              SECOND,
+	"SELECT":              SELECT,
+	"SERIALIZABLE":        SERIALIZABLE,
+	"SESSION":             SESSION,
+	"SESSION_USER":        SESSION_USER,
+	"SET":                 SET,
+	"SETTING":             SETTING,
+	"SETTINGS":            SETTINGS,
+	"SHOW":                SHOW,
+	"SIMILAR":             SIMILAR,
+	"SIMPLE":              SIMPLE,
+	"SMALLINT":            SMALLINT,
+	"SNAPSHOT":            SNAPSHOT,
+	"SOME":                SOME,
+	"SQL":                 SQL,
+	"START":               START,
+	"STORING":             STORING,
+	"STRICT":              STRICT,
+	"STRING":              STRING,
+	"SUBSTRING":           SUBSTRING,
+	"SYMMETRIC":           SYMMETRIC,
+	"TABLE":               TABLE,
+	"TABLES":              TABLES,
+	"TEXT":                TEXT,
+	"THEN":                THEN,
+	"TIME":                TIME,
+	"TIMESTAMP":           TIMESTAMP,
+	"TO":                  TO,
+	"TRAILING":            TRAILING,
+	"TRANSACTION":         TRANSACTION,
+	"TREAT":               TREAT,
+	"TRIM":                TRIM,
+	"TRUE":                TRUE,
+	"TRUNCATE":            TRUNCATE,
+	"TYPE":                TYPE,
+	"UNBOUNDED":           UNBOUNDED,
+	"UNCOMMITTED":         UNCOMMITTED,
+	"UNION":               UNION,
+	"UNIQUE":              UNIQUE,
+	"UNKNOWN":             UNKNOWN,
+	"UPDATE":              UPDATE,
+	"USER":                USER,
+	"USING":               USING,
+	"VALID":               VALID,
+	"VALIDATE":            VALIDATE,
+	"VALUE":               VALUE,
+	"VALUES":              VALUES,
+	"VARCHAR":             VARCHAR,
+	"VARIADIC":            VARIADIC,
+	"VARYING":             VARYING,
+	"WHEN":                WHEN,
+	"WHERE":               WHERE,
+	"WINDOW":              WINDOW,
+	"WITH":                WITH,
+	"WITHIN":              WITHIN,
+	"WITHOUT":             WITHOUT,
+	"YEAR":                YEAR,
+	"ZONE":                ZONE,
 }
@@ -87,6 +87,7 @@ func TestParse(t *testing.T) {
 		{`DELETE FROM a WHERE a = b RETURNING a, b`},
 		{`DELETE FROM a WHERE a = b RETURNING 1, 2`},
 		{`DELETE FROM a WHERE a = b RETURNING a + b`},
+		{`DELETE FROM a WHERE a = b RETURNING NOTHING`},
 
 		{`DROP DATABASE a`},
 		{`DROP DATABASE IF EXISTS a`},
@@ -113,6 +114,11 @@ func TestParse(t *testing.T) {
 		{`SHOW COLUMNS FROM a.b.c`},
 		{`SHOW INDEXES FROM a`},
 		{`SHOW INDEXES FROM a.b.c`},
+		{`SHOW EXPERIMENTAL_RANGES FROM TABLE a`},
+		{`SHOW EXPERIMENTAL_RANGES FROM TABLE a.b.c`},
+		{`SHOW CLUSTER SETTING a`},
+		{`SHOW CLUSTER SETTING a.b`},
+		{`SHOW ALL CLUSTER SETTINGS`},
 		{`SHOW TABLES FROM a; SHOW COLUMNS FROM b`},
 
 		// Tables are the default, but can also be specified with
@@ -159,6 +165,7 @@ func TestParse(t *testing.T) {
 		{`INSERT INTO a VALUES (1) RETURNING a, b`},
 		{`INSERT INTO a VALUES (1, 2) RETURNING 1, 2`},
 		{`INSERT INTO a VALUES (1, 2) RETURNING a + b, c`},
+		{`INSERT INTO a VALUES (1, 2) RETURNING NOTHING`},
 
 		{`SELECT 1 + 1`},
 		{`SELECT - - 5`},
@@ -350,6 +357,9 @@ func TestParse(t *testing.T) {
 		{`SET TIME ZONE -7.3`},
 		{`SET TIME ZONE DEFAULT`},
 		{`SET TIME ZONE LOCAL`},
+		{`SET CLUSTER SETTING a = 3`},
+		{`SET CLUSTER SETTING a.b = 'c'`},
+		{`SET CLUSTER SETTING a = DEFAULT`},
 
 		{`SELECT OVERLAY('w333333rce' PLACING 'resou' FROM 3)`},
 		{`SELECT OVERLAY('w333333rce' PLACING 'resou' FROM 3 FOR 5)`},
@@ -374,6 +384,7 @@ func TestParse(t *testing.T) {
 		{`UPDATE a SET b = 3 WHERE a = b RETURNING a`},
 		{`UPDATE a SET b = 3 WHERE a = b RETURNING 1, 2`},
 		{`UPDATE a SET b = 3 WHERE a = b RETURNING a, a + b`},
+		{`UPDATE a SET b = 3 WHERE a = b RETURNING NOTHING`},
 
 		{`UPDATE T AS "0" SET K = ''`},                 // "0" lost its quotes
 		{`SELECT * FROM "0" JOIN "0" USING (id, "0")`}, // last "0" lost its quotes.
@@ -529,6 +540,10 @@ func TestParse2(t *testing.T) {
 			`SET TIME ZONE 'Europe/Rome'`},
 		{`SET TIME ZONE INTERVAL '-7h'`,
 			`SET TIME ZONE INTERVAL '-7h0m0s'`},
+		{`SET CLUSTER SETTING a TO true`,
+			`SET CLUSTER SETTING a = true`},
+		{`SET CLUSTER SETTING a TO DEFAULT`,
+			`SET CLUSTER SETTING a = DEFAULT`},
 		// Special substring syntax
 		{`SELECT SUBSTRING('RoacH' from 2 for 3)`,
 			`SELECT SUBSTRING('RoacH', 2, 3)`},
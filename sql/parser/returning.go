@@ -18,10 +18,19 @@ package parser
 
 import "fmt"
 
-// ReturningExprs represents RETURNING expressions.
+// ReturningExprs represents RETURNING expressions. RETURNING NOTHING is
+// represented by empty, non-nil ReturningExprs.
 type ReturningExprs SelectExprs
 
+// IsNothing returns whether the expressions represent RETURNING NOTHING.
+func (r ReturningExprs) IsNothing() bool {
+	return r != nil && len(r) == 0
+}
+
 func (r ReturningExprs) String() string {
+	if r.IsNothing() {
+		return " RETURNING NOTHING"
+	}
 	if len(r) == 0 {
 		return ""
 	}
@@ -30,8 +39,16 @@ func (r ReturningExprs) String() string {
 
 // StatementType implements the Statement interface.
 func (r ReturningExprs) StatementType() StatementType {
-	if r != nil {
+	if len(r) > 0 {
 		return Rows
 	}
 	return RowsAffected
 }
+
+// copyNode returns a copy of the expressions, preserving RETURNING NOTHING.
+func (r ReturningExprs) copyNode() ReturningExprs {
+	if r == nil {
+		return nil
+	}
+	return append(ReturningExprs{}, r...)
+}
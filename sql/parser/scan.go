@@ -74,7 +74,7 @@ func (s *scanner) Lex(lval *sqlSymType) int {
 	}
 
 	switch lval.id {
-	case NOT, NULLS, WITH:
+	case NOT, NULLS, WITH, RETURNING:
 	default:
 		s.lastTok = *lval
 		return lval.id
@@ -95,6 +95,12 @@ func (s *scanner) Lex(lval *sqlSymType) int {
 		case TIME, ORDINALITY:
 			lval.id = WITH_LA
 		}
+
+	case RETURNING:
+		switch s.nextTok.id {
+		case NOTHING:
+			lval.id = RETURNING_LA
+		}
 	}
 
 	s.lastTok = *lval
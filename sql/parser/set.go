@@ -40,6 +40,20 @@ func (node *Set) String() string {
 	return fmt.Sprintf("SET %s = %v", node.Name, node.Values)
 }
 
+// SetClusterSetting represents a SET CLUSTER SETTING statement. A nil
+// Value resets the setting to its default.
+type SetClusterSetting struct {
+	Name  *QualifiedName
+	Value Expr
+}
+
+func (node *SetClusterSetting) String() string {
+	if node.Value == nil {
+		return fmt.Sprintf("SET CLUSTER SETTING %s = DEFAULT", node.Name)
+	}
+	return fmt.Sprintf("SET CLUSTER SETTING %s = %s", node.Name, node.Value)
+}
+
 // SetTransaction represents a SET TRANSACTION statement.
 type SetTransaction struct {
 	Isolation    IsolationLevel
@@ -36,6 +36,19 @@ func (node *Show) String() string {
 	return fmt.Sprintf("SHOW %s", node.Name)
 }
 
+// ShowClusterSetting represents a SHOW CLUSTER SETTING statement. A nil
+// Name shows all cluster settings.
+type ShowClusterSetting struct {
+	Name *QualifiedName
+}
+
+func (node *ShowClusterSetting) String() string {
+	if node.Name == nil {
+		return "SHOW ALL CLUSTER SETTINGS"
+	}
+	return fmt.Sprintf("SHOW CLUSTER SETTING %s", node.Name)
+}
+
 // ShowColumns represents a SHOW COLUMNS statement.
 type ShowColumns struct {
 	Table *QualifiedName
@@ -65,6 +78,15 @@ func (node *ShowIndex) String() string {
 	return fmt.Sprintf("SHOW INDEXES FROM %s", node.Table)
 }
 
+// ShowRanges represents a SHOW EXPERIMENTAL_RANGES statement.
+type ShowRanges struct {
+	Table *QualifiedName
+}
+
+func (node *ShowRanges) String() string {
+	return fmt.Sprintf("SHOW EXPERIMENTAL_RANGES FROM TABLE %s", node.Table)
+}
+
 // ShowTables represents a SHOW TABLES statement.
 type ShowTables struct {
 	Name *QualifiedName
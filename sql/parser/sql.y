@@ -479,7 +479,7 @@ func (u *sqlSymUnion) idxElems() IndexElemList {
 
 %token <str>   CASCADE CASE CAST CHAR
 %token <str>   CHARACTER CHARACTERISTICS CHECK
-%token <str>   COALESCE COLLATE COLLATION COLUMN COLUMNS COMMIT
+%token <str>   CLUSTER COALESCE COLLATE COLLATION COLUMN COLUMNS COMMIT
 %token <str>   COMMITTED CONCAT CONFLICT CONSTRAINT
 %token <str>   COVERING CREATE
 %token <str>   CROSS CUBE CURRENT CURRENT_CATALOG CURRENT_DATE
@@ -491,7 +491,7 @@ func (u *sqlSymUnion) idxElems() IndexElemList {
 %token <str>   DISTINCT DO DOUBLE DROP
 
 %token <str>   ELSE END ESCAPE EXCEPT
-%token <str>   EXISTS EXPLAIN EXTRACT
+%token <str>   EXISTS EXPERIMENTAL_RANGES EXPLAIN EXTRACT
 
 %token <str>   FALSE FETCH FILTER FIRST FLOAT FOLLOWING FOR
 %token <str>   FOREIGN FROM FULL
@@ -531,7 +531,7 @@ func (u *sqlSymUnion) idxElems() IndexElemList {
 %token <str>   ROW ROWS RSHIFT
 
 %token <str>   SEARCH SECOND SELECT
-%token <str>   SERIALIZABLE SESSION SESSION_USER SET SHOW
+%token <str>   SERIALIZABLE SESSION SESSION_USER SET SETTING SETTINGS SHOW
 %token <str>   SIMILAR SIMPLE SMALLINT SNAPSHOT SOME SQL
 %token <str>   START STRICT STRING STORING SUBSTRING
 %token <str>   SYMMETRIC
@@ -558,8 +558,9 @@ func (u *sqlSymUnion) idxElems() IndexElemList {
 // NOT_LA exists so that productions such as NOT LIKE can be given the same
 // precedence as LIKE; otherwise they'd effectively have the same precedence as
 // NOT, at least with respect to their left-hand subexpression. WITH_LA is
-// needed to make the grammar LALR(1).
-%token     NOT_LA WITH_LA
+// needed to make the grammar LALR(1). RETURNING_LA allows RETURNING NOTHING
+// to be told apart from the RETURNING of an expression.
+%token     NOT_LA WITH_LA RETURNING_LA
 
 // Precedence: lowest to highest
 %nonassoc  SET                 // see relation_expr_opt_alias
@@ -962,11 +963,28 @@ grantee_list:
 
 // SET name TO 'var_value'
 // SET TIME ZONE 'var_value'
+// SET CLUSTER SETTING name TO 'var_value'
 set_stmt:
   SET set_rest
   {
     $$.val = $2.stmt()
   }
+| SET CLUSTER SETTING var_name TO var_value
+  {
+    $$.val = &SetClusterSetting{Name: $4.qname(), Value: $6.expr()}
+  }
+| SET CLUSTER SETTING var_name '=' var_value
+  {
+    $$.val = &SetClusterSetting{Name: $4.qname(), Value: $6.expr()}
+  }
+| SET CLUSTER SETTING var_name TO DEFAULT
+  {
+    $$.val = &SetClusterSetting{Name: $4.qname()}
+  }
+| SET CLUSTER SETTING var_name '=' DEFAULT
+  {
+    $$.val = &SetClusterSetting{Name: $4.qname()}
+  }
 | SET LOCAL set_rest
   {
     $$.val = $3.stmt()
@@ -1202,6 +1220,10 @@ show_stmt:
   {
     $$.val = &ShowIndex{Table: $4.qname()}
   }
+| SHOW EXPERIMENTAL_RANGES FROM TABLE var_name
+  {
+    $$.val = &ShowRanges{Table: $5.qname()}
+  }
 | SHOW TABLES opt_from_var_name_clause
   {
     $$.val = &ShowTables{Name: $3.qname()}
@@ -1222,6 +1244,14 @@ show_stmt:
   {
     $$.val = Statement(nil)
   }
+| SHOW CLUSTER SETTING var_name
+  {
+    $$.val = &ShowClusterSetting{Name: $4.qname()}
+  }
+| SHOW ALL CLUSTER SETTINGS
+  {
+    $$.val = &ShowClusterSetting{}
+  }
 
 opt_from_var_name_clause:
   FROM var_name
@@ -1741,6 +1771,10 @@ returning_clause:
   {
     $$.val = $2.selExprs()
   }
+| RETURNING_LA NOTHING
+  {
+    $$.val = SelectExprs{}
+  }
 | /* EMPTY */
   {
     $$.val = SelectExprs(nil)
@@ -3840,6 +3874,7 @@ unreserved_keyword:
 | BLOB
 | BY
 | CASCADE
+| CLUSTER
 | COLUMNS
 | COMMIT
 | COMMITTED
@@ -3855,6 +3890,7 @@ unreserved_keyword:
 | DELETE
 | DOUBLE
 | DROP
+| EXPERIMENTAL_RANGES
 | EXPLAIN
 | FILTER
 | FIRST
@@ -3904,6 +3940,8 @@ unreserved_keyword:
 | SERIALIZABLE
 | SESSION
 | SET
+| SETTING
+| SETTINGS
 | SHOW
 | SIMPLE
 | SNAPSHOT
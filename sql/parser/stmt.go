@@ -201,6 +201,12 @@ func (*Set) StatementType() StatementType { return Ack }
 // StatementTag returns a short string identifying the type of statement.
 func (*Set) StatementTag() string { return "SET" }
 
+// StatementType implements the Statement interface.
+func (*SetClusterSetting) StatementType() StatementType { return Ack }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*SetClusterSetting) StatementTag() string { return "SET CLUSTER SETTING" }
+
 // StatementType implements the Statement interface.
 func (*SetTransaction) StatementType() StatementType { return Ack }
 
@@ -225,6 +231,12 @@ func (*Show) StatementType() StatementType { return Rows }
 // StatementTag returns a short string identifying the type of statement.
 func (*Show) StatementTag() string { return "SHOW" }
 
+// StatementType implements the Statement interface.
+func (*ShowClusterSetting) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ShowClusterSetting) StatementTag() string { return "SHOW CLUSTER SETTING" }
+
 // StatementType implements the Statement interface.
 func (*ShowColumns) StatementType() StatementType { return Rows }
 
@@ -249,6 +261,12 @@ func (*ShowIndex) StatementType() StatementType { return Rows }
 // StatementTag returns a short string identifying the type of statement.
 func (*ShowIndex) StatementTag() string { return "SHOW INDEX" }
 
+// StatementType implements the Statement interface.
+func (*ShowRanges) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ShowRanges) StatementTag() string { return "SHOW EXPERIMENTAL_RANGES" }
+
 // StatementType implements the Statement interface.
 func (*ShowTables) StatementType() StatementType { return Rows }
 
@@ -415,7 +415,7 @@ func (stmt *Delete) CopyNode() *Delete {
 		wCopy := *stmt.Where
 		stmtCopy.Where = &wCopy
 	}
-	stmtCopy.Returning = ReturningExprs(append([]SelectExpr(nil), stmt.Returning...))
+	stmtCopy.Returning = stmt.Returning.copyNode()
 	return &stmtCopy
 }
 
@@ -464,7 +464,7 @@ func (stmt *Insert) CopyNode() *Insert {
 	tableCopy := *stmt.Table
 	stmtCopy.Table = &tableCopy
 	stmtCopy.Columns = copyQualifiedNames(stmt.Columns)
-	stmtCopy.Returning = ReturningExprs(append([]SelectExpr(nil), stmt.Returning...))
+	stmtCopy.Returning = stmt.Returning.copyNode()
 	return &stmtCopy
 }
 
@@ -652,7 +652,7 @@ func (stmt *Update) CopyNode() *Update {
 		wCopy := *stmt.Where
 		stmtCopy.Where = &wCopy
 	}
-	stmtCopy.Returning = ReturningExprs(append([]SelectExpr(nil), stmt.Returning...))
+	stmtCopy.Returning = stmt.Returning.copyNode()
 	return &stmtCopy
 }
 
@@ -53,6 +53,7 @@ const (
 	serverMsgCommandComplete      serverMessageType = 'C'
 	serverMsgDataRow              serverMessageType = 'D'
 	serverMsgErrorResponse        serverMessageType = 'E'
+	serverMsgNoticeResponse       serverMessageType = 'N'
 	serverMsgParseComplete        serverMessageType = '1'
 	serverMsgReady                serverMessageType = 'Z'
 	serverMsgRowDescription       serverMessageType = 'T'
@@ -102,6 +103,9 @@ type v3Conn struct {
 	tagBuf   [64]byte
 	session  sql.Session
 
+	// remoteAddr is the address of the client.
+	remoteAddr string
+
 	preparedStatements map[string]preparedStatement
 	preparedPortals    map[string]preparedPortal
 
@@ -122,6 +126,7 @@ func makeV3Conn(conn net.Conn, executor *sql.Executor, metrics *serverMetrics) v
 		rd:                 bufio.NewReader(conn),
 		wr:                 bufio.NewWriter(conn),
 		executor:           executor,
+		remoteAddr:         conn.RemoteAddr().String(),
 		writeBuf:           writeBuffer{bytecount: metrics.bytesOutCount},
 		preparedStatements: make(map[string]preparedStatement),
 		preparedPortals:    make(map[string]preparedPortal),
@@ -162,6 +167,7 @@ func (c *v3Conn) serve(authenticationHook func(string, bool) error) error {
 			return c.sendError(err.Error())
 		}
 	}
+	defer c.executor.RegisterSession(&c.session, c.opts.user, c.remoteAddr)()
 	c.writeBuf.initMsg(serverMsgAuth)
 	c.writeBuf.putInt32(authOK)
 	if err := c.writeBuf.finishMsg(c.wr); err != nil {
@@ -605,11 +611,45 @@ func (c *v3Conn) sendError(errToSend string) error {
 	return c.writeBuf.finishMsg(c.wr)
 }
 
+// sendNotice sends a NoticeResponse, which clients display or log
+// without affecting the result of the statement.
+func (c *v3Conn) sendNotice(notice string) error {
+	c.writeBuf.initMsg(serverMsgNoticeResponse)
+	if err := c.writeBuf.WriteByte('S'); err != nil {
+		return err
+	}
+	if err := c.writeBuf.writeString("NOTICE"); err != nil {
+		return err
+	}
+	if err := c.writeBuf.WriteByte('C'); err != nil {
+		return err
+	}
+	// "00000" is "successful completion".
+	if err := c.writeBuf.writeString("00000"); err != nil {
+		return err
+	}
+	if err := c.writeBuf.WriteByte('M'); err != nil {
+		return err
+	}
+	if err := c.writeBuf.writeString(notice); err != nil {
+		return err
+	}
+	if err := c.writeBuf.WriteByte(0); err != nil {
+		return err
+	}
+	return c.writeBuf.finishMsg(c.wr)
+}
+
 func (c *v3Conn) sendResponse(resp sql.Response, formatCodes []formatCode, sendDescription bool, limit int32) error {
 	if len(resp.Results.ResultList) == 0 {
 		return c.sendCommandComplete(nil)
 	}
 	for _, result := range resp.Results.ResultList {
+		for _, notice := range result.Notices {
+			if err := c.sendNotice(notice); err != nil {
+				return err
+			}
+		}
 		if result.PErr != nil {
 			if err := c.sendError(result.PErr.String()); err != nil {
 				return err
@@ -195,6 +195,43 @@ func TestPGWireDBName(t *testing.T) {
 	}
 }
 
+// TestPGWireSQLAddr verifies that SQL clients are served on SQLAddr, and
+// no longer on the serving address of the node, when it is set.
+func TestPGWireSQLAddr(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := server.NewTestContext()
+	ctx.SQLAddr = "127.0.0.1:0"
+	s := server.StartTestServerWithContext(t, ctx)
+	defer s.Stop()
+
+	if s.SQLAddr() == s.ServingAddr() {
+		t.Fatalf("expected SQL to be served on a separate address, got %s", s.SQLAddr())
+	}
+
+	pgURL, cleanupFn := sqlutils.PGUrl(t, s, security.RootUser, "TestPGWireSQLAddr")
+	defer cleanupFn()
+	db, err := sql.Open("postgres", pgURL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`SELECT 1`); err != nil {
+		t.Fatal(err)
+	}
+
+	// The serving address only accepts RPCs now.
+	pgURL.Host = s.ServingAddr()
+	rpcDB, err := sql.Open("postgres", pgURL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rpcDB.Close()
+	if _, err := rpcDB.Exec(`SELECT 1`); err == nil {
+		t.Fatal("expected SQL on the serving address to fail")
+	}
+}
+
 func TestPGPrepareFail(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
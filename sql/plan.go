@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/roachpb"
 	"github.com/cockroachdb/cockroach/sql/parser"
 	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/settings"
 	"github.com/cockroachdb/cockroach/util/tracing"
 )
 
@@ -40,6 +41,13 @@ type planner struct {
 	leaseMgr      *LeaseManager
 	systemConfig  config.SystemConfig
 	databaseCache *databaseCache
+	txnWaitsCache *txnWaitsCache
+	// clusterStatus and sessionRegistry populate the crdb_internal virtual
+	// tables. Either may be nil.
+	clusterStatus   ClusterStatus
+	sessionRegistry *sessionRegistry
+	// settings is the registry of the cluster settings.
+	settings *settings.Registry
 
 	// TODO(mjibson): remove prepareOnly in favor of a 2-step prepare-exec solution
 	// that is also able to save the plan to skip work during the exec step.
@@ -55,6 +63,24 @@ type planner struct {
 	// Callback used when a node wants to schedule a SchemaChanger
 	// for execution at the end of the current transaction.
 	schemaChangeCallback func(schemaChanger SchemaChanger)
+
+	// eagerCommit is set if the executor commits explicit transactions
+	// along with their last write. See ExecutorContext.EagerCommit.
+	eagerCommit bool
+	// onePC, if set, counts the transactions committed along with their
+	// first writes.
+	onePC *onePCMetrics
+	// parallel holds the deferred writes of the statements executed in
+	// parallel, and parallelStmt is set while executing such a statement.
+	// parallel is shared with the planners of subqueries.
+	parallel     *parallelWrites
+	parallelStmt bool
+	// implicitTxn is set while executing a statement in a transaction of
+	// its own.
+	implicitTxn bool
+	// rowsRead counts the rows scanned by the statement being executed,
+	// whether or not they pass its filters.
+	rowsRead int
 }
 
 func makePlanner() *planner {
@@ -140,6 +166,8 @@ func (p *planner) makePlan(stmt parser.Statement, autoCommit bool) (planNode, *r
 		return p.SelectClause(n)
 	case *parser.Set:
 		return p.Set(n)
+	case *parser.SetClusterSetting:
+		return p.SetClusterSetting(n)
 	case *parser.SetTimeZone:
 		pNode, err := p.SetTimeZone(n)
 		return pNode, roachpb.NewError(err)
@@ -152,6 +180,8 @@ func (p *planner) makePlan(stmt parser.Statement, autoCommit bool) (planNode, *r
 	case *parser.Show:
 		pNode, err := p.Show(n)
 		return pNode, roachpb.NewError(err)
+	case *parser.ShowClusterSetting:
+		return p.ShowClusterSetting(n)
 	case *parser.ShowColumns:
 		return p.ShowColumns(n)
 	case *parser.ShowDatabases:
@@ -160,6 +190,8 @@ func (p *planner) makePlan(stmt parser.Statement, autoCommit bool) (planNode, *r
 		return p.ShowGrants(n)
 	case *parser.ShowIndex:
 		return p.ShowIndex(n)
+	case *parser.ShowRanges:
+		return p.ShowRanges(n)
 	case *parser.ShowTables:
 		return p.ShowTables(n)
 	case *parser.Truncate:
@@ -189,6 +221,8 @@ func (p *planner) prepare(stmt parser.Statement) (planNode, *roachpb.Error) {
 	case *parser.Show:
 		pNode, err := p.Show(n)
 		return pNode, roachpb.NewError(err)
+	case *parser.ShowClusterSetting:
+		return p.ShowClusterSetting(n)
 	case *parser.ShowColumns:
 		return p.ShowColumns(n)
 	case *parser.ShowDatabases:
@@ -197,6 +231,8 @@ func (p *planner) prepare(stmt parser.Statement) (planNode, *roachpb.Error) {
 		return p.ShowGrants(n)
 	case *parser.ShowIndex:
 		return p.ShowIndex(n)
+	case *parser.ShowRanges:
+		return p.ShowRanges(n)
 	case *parser.ShowTables:
 		return p.ShowTables(n)
 	case *parser.Update:
@@ -307,6 +307,12 @@ func (n *scanNode) initVisibleCols(visibleCols []ColumnDescriptor, numImplicit i
 
 // initScan initializes but does not perform the key-value scan.
 func (n *scanNode) initScan() bool {
+	// Send the writes deferred by the statements executed in parallel
+	// which the scan needs to observe.
+	if n.pErr = n.planner.awaitParallelWrites(n.desc.ID); n.pErr != nil {
+		return false
+	}
+
 	// Initialize our key/values.
 	if len(n.spans) == 0 {
 		// If no spans were specified retrieve all of the keys that start with our
@@ -534,6 +540,7 @@ func (n *scanNode) maybeOutputRow() bool {
 			!bytes.HasPrefix(n.kv.Key, n.indexKey)) {
 		// The current key belongs to a new row. Output the current row.
 		n.indexKey = nil
+		n.planner.rowsRead++
 
 		// Fill in any missing values with NULLs
 		for i, col := range n.visibleCols {
@@ -168,8 +168,8 @@ func TestScanBatches(t *testing.T) {
 
 	// The table will have one key for the even rows, and two keys for the odd rows.
 	batchSizes := []int{1, 2, 3, 5, 10, 13, 100, 3*numRows/2 - 1, 3 * numRows / 2, 3*numRows/2 + 1}
-	// We can test with at most one one span for now (see kvFetcher.fetch)
-	numSpanValues := []int{0, 1}
+	// Multiple spans are streamed rather than batched (see kvFetcher.fetch).
+	numSpanValues := []int{0, 1, 2, 3}
 
 	for _, batch := range batchSizes {
 		csql.SetKVBatchSize(batch)
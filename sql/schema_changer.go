@@ -27,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/keys"
 	"github.com/cockroachdb/cockroach/roachpb"
 	"github.com/cockroachdb/cockroach/security"
+	"github.com/cockroachdb/cockroach/util"
 	"github.com/cockroachdb/cockroach/util/log"
 	"github.com/cockroachdb/cockroach/util/retry"
 	"github.com/cockroachdb/cockroach/util/stop"
@@ -54,6 +55,8 @@ func (sc *SchemaChanger) applyMutations(lease *TableDescriptor_SchemaChangeLease
 	}
 	*lease = l
 	return sc.db.Txn(func(txn *client.Txn) *roachpb.Error {
+		// The backfill yields to foreground traffic on overloaded nodes.
+		txn.AdmissionClass = roachpb.BACKGROUND
 		// TODO(vivek): Use the original users privileges.
 		p := makePlanner()
 		p.user = security.RootUser
@@ -220,7 +223,9 @@ func (sc SchemaChanger) exec() *roachpb.Error {
 
 	// Apply backfill.
 	if pErr := sc.applyMutations(&lease); pErr != nil {
-		// Purge the mutations if the application of the mutations fail.
+		// Purge the mutations if the application of the mutations fail,
+		// so that the table descriptor is returned to its state before
+		// the schema change.
 		if errPurge := sc.purgeMutations(&lease); errPurge != nil {
 			return roachpb.NewErrorf("error purging mutation: %s, after error: %s", errPurge, pErr)
 		}
@@ -336,6 +341,10 @@ func (sc *SchemaChanger) done() *roachpb.Error {
 // Purge all mutations with the mutationID. This is called after
 // hitting an irrecoverable error. Reverse the direction of the mutations
 // and run through the state machine until the mutations are deleted.
+// The reversed mutations are marked as a rollback, which is persisted
+// with the table descriptor: if the rollback itself fails, it is left in
+// place for the SchemaChangeManager to retry instead of being reversed
+// again, which would reapply the failed schema change.
 func (sc *SchemaChanger) purgeMutations(lease *TableDescriptor_SchemaChangeLease) error {
 	// Reverse the flow of the state machine.
 	if pErr := sc.leaseMgr.Publish(sc.tableID, func(desc *TableDescriptor) error {
@@ -345,6 +354,9 @@ func (sc *SchemaChanger) purgeMutations(lease *TableDescriptor_SchemaChangeLease
 				// mutations if they have the mutation ID we're looking for.
 				break
 			}
+			if mutation.Rollback {
+				return util.Errorf("rollback of mutation %d failed; it will be retried", sc.mutationID)
+			}
 			log.Warningf("Purging schema change mutation: %v", desc.Mutations[i])
 			switch mutation.Direction {
 			case DescriptorMutation_ADD:
@@ -353,6 +365,7 @@ func (sc *SchemaChanger) purgeMutations(lease *TableDescriptor_SchemaChangeLease
 			case DescriptorMutation_DROP:
 				desc.Mutations[i].Direction = DescriptorMutation_ADD
 			}
+			desc.Mutations[i].Rollback = true
 		}
 		// Publish() will increment the version.
 		return nil
@@ -365,11 +378,9 @@ func (sc *SchemaChanger) purgeMutations(lease *TableDescriptor_SchemaChangeLease
 		return err
 	}
 
-	// Apply backfill and don't run purge on hitting an error.
-	// TODO(vivek): If this fails we can get into a permanent
-	// failure with some mutations, where subsequent schema
-	// changers keep attempting to apply and purge mutations.
-	// This is a theoretical problem at this stage (2015/12).
+	// Apply backfill and don't run purge on hitting an error. The
+	// mutations remain marked as a rollback, so the SchemaChangeManager
+	// will retry the rollback.
 	if pErr := sc.applyMutations(lease); pErr != nil {
 		return pErr.GoError()
 	}
@@ -478,11 +489,13 @@ func (s *SchemaChangeManager) newTimer() *time.Timer {
 }
 
 // Start starts a goroutine that runs outstanding schema changes
-// for tables received in the latest system configuration via gossip.
+// for tables received in the latest system configuration via gossip,
+// along with a goroutine that clears the data of dropped tables.
 func (s *SchemaChangeManager) Start(stopper *stop.Stopper) {
 	if disableAsyncSchemaChangeExec {
 		return
 	}
+	startTableClearer(s.db, stopper)
 	stopper.RunWorker(func() {
 		descKeyPrefix := keys.MakeTablePrefix(uint32(descriptorTable.ID))
 		gossipUpdateC := s.gossip.RegisterSystemConfigChannel()
@@ -571,7 +584,7 @@ func (s *SchemaChangeManager) Start(stopper *stop.Stopper) {
 				timer = s.newTimer()
 
 			case <-timer.C:
-				for _, sc := range s.schemaChangers {
+				for tableID, sc := range s.schemaChangers {
 					if time.Since(sc.execAfter) > 0 {
 						pErr := sc.exec()
 						if _, ok := pErr.GetDetail().(*roachpb.ExistingSchemaChangeLeaseError); !ok && pErr != nil {
@@ -580,6 +593,7 @@ func (s *SchemaChangeManager) Start(stopper *stop.Stopper) {
 						// Advance the execAfter time so that this schema changer
 						// doesn't get called again for a while.
 						sc.execAfter = time.Now().Add(asyncSchemaChangeExecDelay)
+						s.schemaChangers[tableID] = sc
 					}
 					// Only attempt to run one schema changer.
 					break
@@ -24,9 +24,11 @@ import (
 	"github.com/cockroachdb/cockroach/keys"
 	"github.com/cockroachdb/cockroach/roachpb"
 	csql "github.com/cockroachdb/cockroach/sql"
+	"github.com/cockroachdb/cockroach/testutils"
 	"github.com/cockroachdb/cockroach/util"
 	"github.com/cockroachdb/cockroach/util/hlc"
 	"github.com/cockroachdb/cockroach/util/leaktest"
+	"github.com/cockroachdb/cockroach/util/metric"
 	"github.com/cockroachdb/cockroach/util/retry"
 )
 
@@ -123,7 +125,7 @@ func TestSchemaChangeProcess(t *testing.T) {
 	var id = csql.ID(keys.MaxReservedDescID + 2)
 	var node = roachpb.NodeID(2)
 	db := server.DB()
-	leaseMgr := csql.NewLeaseManager(0, *db, hlc.NewClock(hlc.UnixNano))
+	leaseMgr := csql.NewLeaseManager(0, *db, hlc.NewClock(hlc.UnixNano), metric.NewRegistry())
 	changer := csql.NewSchemaChangerForTesting(id, 0, node, *db, leaseMgr)
 
 	if _, err := sqlDB.Exec(`
@@ -406,3 +408,155 @@ ALTER INDEX t.test@foo RENAME TO ufo
 		_ = mTest.checkQueryResponse(indexQuery, [][]string{{"b"}, {"d"}})
 	}
 }
+
+// Test that a schema change which fails to apply is rolled back: the
+// table descriptor is left without mutations and the index data written
+// by the failed backfill is removed.
+func TestSchemaChangeRollback(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	server, sqlDB, kvDB := setup(t)
+	defer cleanup(server, sqlDB)
+
+	if _, err := sqlDB.Exec(`
+CREATE DATABASE t;
+CREATE TABLE t.test (k CHAR PRIMARY KEY, v CHAR);
+INSERT INTO t.test VALUES ('a', 'b'), ('c', 'b');
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	nameKey := csql.MakeNameMetadataKey(keys.MaxReservedDescID+1, "test")
+	gr, pErr := kvDB.Get(nameKey)
+	if pErr != nil {
+		t.Fatal(pErr)
+	}
+	if !gr.Exists() {
+		t.Fatalf("Name entry %q does not exist", nameKey)
+	}
+	descKey := csql.MakeDescMetadataKey(csql.ID(gr.ValueInt()))
+	desc := &csql.Descriptor{}
+	if pErr := kvDB.GetProto(descKey, desc); pErr != nil {
+		t.Fatal(pErr)
+	}
+	tablePrefix := roachpb.Key(keys.MakeTablePrefix(uint32(desc.GetTable().ID)))
+	kvs, pErr := kvDB.Scan(tablePrefix, tablePrefix.PrefixEnd(), 0)
+	if pErr != nil {
+		t.Fatal(pErr)
+	}
+	expectedKVs := len(kvs)
+
+	// The unique index cannot be created over the duplicate values.
+	if _, err := sqlDB.Exec(`CREATE UNIQUE INDEX foo ON t.test (v)`); !testutils.IsError(err, "duplicate key value") {
+		t.Fatalf("expected duplicate key error, got %v", err)
+	}
+
+	if pErr := kvDB.GetProto(descKey, desc); pErr != nil {
+		t.Fatal(pErr)
+	}
+	table := desc.GetTable()
+	if l := len(table.Mutations); l != 0 {
+		t.Fatalf("expected no mutations after the rollback, got %d: %v", l, table.Mutations)
+	}
+	if l := len(table.Indexes); l != 0 {
+		t.Fatalf("expected no indexes after the rollback, got %d: %v", l, table.Indexes)
+	}
+	if kvs, pErr := kvDB.Scan(tablePrefix, tablePrefix.PrefixEnd(), 0); pErr != nil {
+		t.Fatal(pErr)
+	} else if len(kvs) != expectedKVs {
+		t.Fatalf("expected %d key value pairs, but got %d", expectedKVs, len(kvs))
+	}
+}
+
+// Test that a rollback which failed is retried by the asynchronous schema
+// changer: the mutation remains marked as a rollback in the table
+// descriptor and is completed in the direction of the rollback rather
+// than reversed again.
+func TestSchemaChangeRollbackRetry(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	// Disable synchronous schema change execution so that the asynchronous
+	// schema changer retries the rollback.
+	defer csql.TestDisableSyncSchemaChangeExec()()
+	// The descriptor changes made must have an immediate effect
+	// so disable leases on tables.
+	defer csql.TestDisableTableLeases()()
+	server, sqlDB, kvDB := setup(t)
+	defer cleanup(server, sqlDB)
+
+	if _, err := sqlDB.Exec(`
+CREATE DATABASE t;
+CREATE TABLE t.test (k CHAR PRIMARY KEY, v CHAR, INDEX foo(v));
+INSERT INTO t.test VALUES ('a', 'b'), ('c', 'd');
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	nameKey := csql.MakeNameMetadataKey(keys.MaxReservedDescID+1, "test")
+	gr, pErr := kvDB.Get(nameKey)
+	if pErr != nil {
+		t.Fatal(pErr)
+	}
+	if !gr.Exists() {
+		t.Fatalf("Name entry %q does not exist", nameKey)
+	}
+	descKey := csql.MakeDescMetadataKey(csql.ID(gr.ValueInt()))
+	desc := &csql.Descriptor{}
+	if pErr := kvDB.GetProto(descKey, desc); pErr != nil {
+		t.Fatal(pErr)
+	}
+	table := desc.GetTable()
+	tablePrefix := roachpb.Key(keys.MakeTablePrefix(uint32(table.ID)))
+	kvs, pErr := kvDB.Scan(tablePrefix, tablePrefix.PrefixEnd(), 0)
+	if pErr != nil {
+		t.Fatal(pErr)
+	}
+	expectedKVs := len(kvs)
+
+	// Leave the state of a failed rollback of the addition of index bar:
+	// the mutation was reversed and marked as a rollback, and the failed
+	// backfill left data behind in the index.
+	index := util.CloneProto(&table.Indexes[0]).(*csql.IndexDescriptor)
+	index.Name = "bar"
+	index.ID = table.NextIndexID
+	table.NextIndexID++
+	table.Mutations = append(table.Mutations, csql.DescriptorMutation{
+		Descriptor_: &csql.DescriptorMutation_Index{Index: index},
+		Direction:   csql.DescriptorMutation_DROP,
+		State:       csql.DescriptorMutation_WRITE_ONLY,
+		MutationID:  table.NextMutationID,
+		Rollback:    true,
+	})
+	table.NextMutationID++
+	table.UpVersion = true
+	indexKey := append(roachpb.Key(csql.MakeIndexKeyPrefix(table.ID, index.ID)), "x"...)
+	if pErr := kvDB.Put(indexKey, "y"); pErr != nil {
+		t.Fatal(pErr)
+	}
+	if pErr := kvDB.Put(descKey, desc); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	retryOpts := retry.Options{
+		InitialBackoff: 20 * time.Millisecond,
+		MaxBackoff:     200 * time.Millisecond,
+		Multiplier:     2,
+	}
+	// Wait until the rollback is retried.
+	for r := retry.Start(retryOpts); r.Next(); {
+		if pErr := kvDB.GetProto(descKey, desc); pErr != nil {
+			t.Fatal(pErr)
+		}
+		if len(desc.GetTable().Mutations) == 0 {
+			break
+		}
+	}
+
+	table = desc.GetTable()
+	if l := len(table.Indexes); l != 1 || table.Indexes[0].Name != "foo" {
+		t.Fatalf("expected only index foo after the rollback, got %v", table.Indexes)
+	}
+	if kvs, pErr := kvDB.Scan(tablePrefix, tablePrefix.PrefixEnd(), 0); pErr != nil {
+		t.Fatal(pErr)
+	} else if len(kvs) != expectedKVs {
+		t.Fatalf("expected %d key value pairs, but got %d", expectedKVs, len(kvs))
+	}
+}
@@ -19,6 +19,7 @@ package sql
 import (
 	"bytes"
 	"fmt"
+	"math"
 	"reflect"
 	"sort"
 
@@ -262,6 +263,16 @@ func (p *planner) initSelect(
 		ordering = sort.Ordering().ordering
 	}
 
+	// Evaluate the LIMIT and OFFSET before index selection, so that a scan
+	// which provides the requested ordering can be bounded by them.
+	count, offset := int64(math.MaxInt64), int64(0)
+	if limit != nil {
+		var err error
+		if count, offset, err = p.evalLimit(limit); err != nil {
+			return nil, roachpb.NewError(err)
+		}
+	}
+
 	if scan, ok := s.table.node.(*scanNode); ok {
 		// Find the set of columns that we actually need values for. This is an optimization to avoid
 		// unmarshaling unnecessary values and is also used for index selection.
@@ -294,7 +305,14 @@ func (p *planner) initSelect(
 			}
 		}
 
-		plan := p.selectIndex(s, scan, ordering, grouping)
+		// The scan can only be bounded by the limit if each of its rows is a
+		// result row.
+		var scanLimit int64
+		if group == nil && !parsed.Distinct && count != math.MaxInt64 {
+			scanLimit = offset + count
+		}
+
+		plan := p.selectIndex(s, scan, ordering, grouping, scanLimit)
 
 		// Update s.table with the new plan.
 		s.table.node = plan
@@ -303,11 +321,11 @@ func (p *planner) initSelect(
 	s.ordering = s.computeOrdering(s.table.node.Ordering())
 
 	// Wrap this node as necessary.
-	limitNode, err := p.limit(limit, p.distinct(parsed, sort.wrap(group.wrap(s))))
-	if err != nil {
-		return nil, roachpb.NewError(err)
+	plan := p.distinct(parsed, sort.wrap(group.wrap(s)))
+	if limit != nil {
+		plan = makeLimit(plan, count, offset)
 	}
-	return limitNode, nil
+	return plan, nil
 }
 
 // Initializes the table node, given the parsed select expression
@@ -326,6 +344,15 @@ func (s *selectNode) initFrom(p *planner, parsed *parser.SelectClause) *roachpb.
 
 		switch expr := ate.Expr.(type) {
 		case *parser.QualifiedName:
+			if name, fn := getCrdbInternalTable(expr); fn != nil {
+				// Virtual table exposing internal state of the cluster.
+				s.table.alias = name
+				s.table.node, s.pErr = p.crdbInternalTable(name, fn)
+				if s.pErr != nil {
+					return s.pErr
+				}
+				break
+			}
 			// Usual case: a table.
 			scan := &scanNode{planner: p, txn: p.txn}
 			s.table.alias, s.pErr = scan.initTable(p, expr)
@@ -667,7 +694,12 @@ func (s *selectNode) computeOrdering(fromOrder orderingInfo) orderingInfo {
 // transformed into a set of spans to scan within the index.
 //
 // If grouping is true, the ordering is the desired ordering for grouping.
-func (p *planner) selectIndex(sel *selectNode, s *scanNode, ordering columnOrdering, grouping bool) planNode {
+//
+// If limit is non-zero, only the first limit rows of the scan in the desired
+// ordering are needed.
+func (p *planner) selectIndex(
+	sel *selectNode, s *scanNode, ordering columnOrdering, grouping bool, limit int64,
+) planNode {
 	if s.desc.isEmpty() || (s.filter == nil && ordering == nil) {
 		// No table or no where-clause and no ordering.
 		s.initOrdering(0)
@@ -795,6 +827,20 @@ func (p *planner) selectIndex(sel *selectNode, s *scanNode, ordering columnOrder
 		}
 	}
 
+	if limit > 0 && len(s.spans) == 1 && noFilter && sel.filter == nil &&
+		c.covering && s.isSecondaryIndex {
+		// If there is a single span for which the filter is true and the scan
+		// provides the desired ordering, the scan is bounded by the limit:
+		// each row of a secondary index is a single key, which isn't the case
+		// for the rows of the primary index (they are only bounded by the
+		// limit hint).
+		existingOrdering := sel.computeOrdering(plan.Ordering())
+		match := computeOrderingMatch(ordering, existingOrdering, false)
+		if match == len(ordering) {
+			s.spans[0].count = limit
+		}
+	}
+
 	if log.V(3) {
 		log.Infof("%s: filter=%v", c.index.Name, s.filter)
 		for i, span := range s.spans {
@@ -30,6 +30,12 @@ type Session struct {
 	//	*Session_Offset
 	Timezone              isSession_Timezone               `protobuf_oneof:"timezone"`
 	DefaultIsolationLevel cockroach_roachpb1.IsolationType `protobuf:"varint,7,opt,name=default_isolation_level,enum=cockroach.roachpb.IsolationType" json:"default_isolation_level"`
+	// If set, the data of dropped and truncated tables is cleared right away
+	// instead of after the GC TTL of the table's zone has expired.
+	ClearDroppedDataImmediately bool `protobuf:"varint,8,opt,name=clear_dropped_data_immediately" json:"clear_dropped_data_immediately"`
+	// If set, statements which were retried internally are followed by a
+	// notice to the client summarizing the retries.
+	RetryNotices bool `protobuf:"varint,9,opt,name=retry_notices" json:"retry_notices"`
 }
 
 func (m *Session) Reset()         { *m = Session{} }
@@ -199,6 +205,22 @@ func (m *Session) MarshalTo(data []byte) (int, error) {
 	data[i] = 0x38
 	i++
 	i = encodeVarintSession(data, i, uint64(m.DefaultIsolationLevel))
+	data[i] = 0x40
+	i++
+	if m.ClearDroppedDataImmediately {
+		data[i] = 1
+	} else {
+		data[i] = 0
+	}
+	i++
+	data[i] = 0x48
+	i++
+	if m.RetryNotices {
+		data[i] = 1
+	} else {
+		data[i] = 0
+	}
+	i++
 	return i, nil
 }
 
@@ -335,6 +357,8 @@ func (m *Session) Size() (n int) {
 		n += m.Timezone.Size()
 	}
 	n += 1 + sovSession(uint64(m.DefaultIsolationLevel))
+	n += 2
+	n += 2
 	return n
 }
 
@@ -562,6 +586,46 @@ func (m *Session) Unmarshal(data []byte) error {
 					break
 				}
 			}
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ClearDroppedDataImmediately", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSession
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.ClearDroppedDataImmediately = bool(v != 0)
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RetryNotices", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSession
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.RetryNotices = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipSession(data[iNdEx:])
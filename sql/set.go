@@ -62,6 +62,36 @@ func (p *planner) Set(n *parser.Set) (planNode, *roachpb.Error) {
 			return nil, roachpb.NewUErrorf("%s: \"%s\" is not in (%q, %q)", name, s, parser.Modern, parser.Traditional)
 		}
 
+	case `CLEAR_DROPPED_DATA`:
+		s, err := p.getStringVal(name, n.Values)
+		if err != nil {
+			return nil, roachpb.NewError(err)
+		}
+		switch NormalizeName(s) {
+		case NormalizeName(clearDroppedDataImmediate):
+			p.session.ClearDroppedDataImmediately = true
+		case NormalizeName(clearDroppedDataDeferred):
+			p.session.ClearDroppedDataImmediately = false
+		default:
+			return nil, roachpb.NewUErrorf("%s: \"%s\" is not in (%q, %q)", name, s,
+				clearDroppedDataImmediate, clearDroppedDataDeferred)
+		}
+
+	case `RETRY_NOTICES`:
+		s, err := p.getStringVal(name, n.Values)
+		if err != nil {
+			return nil, roachpb.NewError(err)
+		}
+		switch NormalizeName(s) {
+		case NormalizeName(retryNoticesOn):
+			p.session.RetryNotices = true
+		case NormalizeName(retryNoticesOff):
+			p.session.RetryNotices = false
+		default:
+			return nil, roachpb.NewUErrorf("%s: \"%s\" is not in (%q, %q)", name, s,
+				retryNoticesOn, retryNoticesOff)
+		}
+
 	case `EXTRA_FLOAT_DIGITS`:
 		// These settings are sent by the JDBC driver but we silently ignore them.
 
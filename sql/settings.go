@@ -0,0 +1,175 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/config"
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/security"
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/encoding"
+	"github.com/cockroachdb/cockroach/util/log"
+)
+
+// settingsFromSystemConfig returns the values of the cluster settings stored
+// in the system.settings table, keyed by setting name. The table is part of
+// the system config, so changes to it are gossiped to all nodes.
+func settingsFromSystemConfig(cfg config.SystemConfig) map[string]string {
+	prefix := roachpb.Key(MakeIndexKeyPrefix(settingsTable.ID, settingsTable.PrimaryIndex.ID))
+	values := map[string]string{}
+	i := sort.Search(len(cfg.Values), func(i int) bool {
+		return bytes.Compare(cfg.Values[i].Key, prefix) >= 0
+	})
+	for ; i < len(cfg.Values); i++ {
+		kv := cfg.Values[i]
+		if !bytes.HasPrefix(kv.Key, prefix) {
+			break
+		}
+		_, name, err := encoding.DecodeStringAscending(kv.Key[len(prefix):], nil)
+		if err != nil {
+			log.Warningf("unable to decode setting key %s: %s", kv.Key, err)
+			continue
+		}
+		if !kv.Key.Equal(makeSettingKey(name)) {
+			// Not the value column.
+			continue
+		}
+		d, err := unmarshalColumnValue(ColumnType_STRING, &kv.Value)
+		if err != nil {
+			log.Warningf("unable to decode value of setting %s: %s", name, err)
+			continue
+		}
+		if s, ok := d.(parser.DString); ok {
+			values[name] = string(s)
+		}
+	}
+	return values
+}
+
+// SetClusterSetting changes the value of a cluster setting. The new value is
+// stored in the system.settings table, from which it is propagated to all
+// nodes through gossip.
+// Privileges: security.RootUser user.
+func (p *planner) SetClusterSetting(n *parser.SetClusterSetting) (planNode, *roachpb.Error) {
+	if p.user != security.RootUser {
+		return nil, roachpb.NewUErrorf("only %s is allowed to set cluster settings", security.RootUser)
+	}
+	name := NormalizeName(n.Name.String())
+	if _, ok := p.settings.Get(name); !ok {
+		return nil, roachpb.NewUErrorf("unknown cluster setting %q", name)
+	}
+
+	value, isSet := "", n.Value != nil
+	if isSet {
+		d, err := n.Value.Eval(p.evalCtx)
+		if err != nil {
+			return nil, roachpb.NewError(err)
+		}
+		if s, ok := d.(parser.DString); ok {
+			value = string(s)
+		} else {
+			value = d.String()
+		}
+		if err := p.settings.Validate(name, value); err != nil {
+			return nil, roachpb.NewUErrorf("%s", err)
+		}
+	}
+
+	ie := InternalExecutor{LeaseManager: p.leaseMgr}
+	deleted, pErr := ie.ExecuteStatementInTransaction(p.txn,
+		`DELETE FROM system.settings WHERE name = $1`, name)
+	if pErr != nil {
+		return nil, pErr
+	}
+	if !isSet && deleted == 0 {
+		// The setting already has its default value.
+		return &emptyNode{}, nil
+	}
+	if isSet {
+		if _, pErr := ie.ExecuteStatementInTransaction(p.txn,
+			`INSERT INTO system.settings (name, value, lastUpdated) VALUES ($1, $2, now())`,
+			name, value); pErr != nil {
+			return nil, pErr
+		}
+	}
+
+	// The event is logged after the setting was written, so that the
+	// transaction is anchored on the system config span.
+	loggedValue := value
+	if !isSet {
+		loggedValue = "DEFAULT"
+	}
+	if pErr := MakeEventLogger(p.leaseMgr).insertEventRecord(p.txn,
+		EventLogSetClusterSetting,
+		0, /* no target */
+		int32(p.evalCtx.NodeID),
+		struct {
+			SettingName string
+			Value       string
+			User        string
+		}{name, loggedValue, p.user},
+	); pErr != nil {
+		return nil, pErr
+	}
+
+	old, wasSet := settingsFromSystemConfig(p.systemConfig)[name]
+	if old != value || wasSet != isSet {
+		p.testingVerifyMetadata = func(cfg config.SystemConfig) error {
+			if v, ok := settingsFromSystemConfig(cfg)[name]; v != value || ok != isSet {
+				return util.Errorf("expected setting %s to be %q (set=%t), got %q", name, value, isSet, v)
+			}
+			return nil
+		}
+	}
+	return &emptyNode{}, nil
+}
+
+// ShowClusterSetting shows the value of a cluster setting as seen by this
+// node, or the values, defaults and descriptions of all cluster settings.
+// Privileges: None.
+func (p *planner) ShowClusterSetting(n *parser.ShowClusterSetting) (planNode, *roachpb.Error) {
+	if n.Name == nil {
+		v := &valuesNode{
+			columns: []ResultColumn{
+				{Name: "Name", Typ: parser.DummyString},
+				{Name: "Value", Typ: parser.DummyString},
+				{Name: "Default", Typ: parser.DummyString},
+				{Name: "Description", Typ: parser.DummyString},
+			},
+		}
+		for _, s := range p.settings.Values() {
+			v.rows = append(v.rows, []parser.Datum{
+				parser.DString(s.Name),
+				parser.DString(s.Value),
+				parser.DString(s.Default),
+				parser.DString(s.Description),
+			})
+		}
+		return v, nil
+	}
+
+	name := NormalizeName(n.Name.String())
+	value, ok := p.settings.Get(name)
+	if !ok {
+		return nil, roachpb.NewUErrorf("unknown cluster setting %q", name)
+	}
+	v := &valuesNode{columns: []ResultColumn{{Name: name, Typ: parser.DummyString}}}
+	v.rows = append(v.rows, []parser.Datum{parser.DString(value)})
+	return v, nil
+}
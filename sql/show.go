@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/cockroachdb/cockroach/client"
 	"github.com/cockroachdb/cockroach/keys"
 	"github.com/cockroachdb/cockroach/roachpb"
 	"github.com/cockroachdb/cockroach/sql/parser"
@@ -44,6 +45,12 @@ func (p *planner) Show(n *parser.Show) (planNode, error) {
 		v.rows = append(v.rows, []parser.Datum{parser.DString(loc.String())})
 	case `SYNTAX`:
 		v.rows = append(v.rows, []parser.Datum{parser.DString(parser.Syntax(p.session.Syntax).String())})
+	case `RETRY_NOTICES`:
+		val := retryNoticesOff
+		if p.session.RetryNotices {
+			val = retryNoticesOn
+		}
+		v.rows = append(v.rows, []parser.Datum{parser.DString(val)})
 	case `DEFAULT_TRANSACTION_ISOLATION`:
 		level := p.session.DefaultIsolationLevel.String()
 		v.rows = append(v.rows, []parser.Datum{parser.DString(level)})
@@ -216,6 +223,113 @@ func (p *planner) ShowIndex(n *parser.ShowIndex) (planNode, *roachpb.Error) {
 	return v, nil
 }
 
+// rangeScanBatchSize is the number of range descriptors read at a time by
+// SHOW EXPERIMENTAL_RANGES.
+const rangeScanBatchSize = 100
+
+// ShowRanges returns the ranges of a table, as recorded in the meta ranges,
+// along with the nodes holding their leases.
+// The first and last range may extend beyond the table.
+// Privileges: None.
+func (p *planner) ShowRanges(n *parser.ShowRanges) (planNode, *roachpb.Error) {
+	desc, pErr := p.getTableDesc(n.Table)
+	if pErr != nil {
+		return nil, pErr
+	}
+
+	v := &valuesNode{
+		columns: []ResultColumn{
+			{Name: "Start Key", Typ: parser.DummyString},
+			{Name: "End Key", Typ: parser.DummyString},
+			{Name: "Range ID", Typ: parser.DummyInt},
+			{Name: "Replicas", Typ: parser.DummyString},
+			{Name: "Lease Holder", Typ: parser.DummyInt},
+		},
+	}
+	if p.prepareOnly {
+		return v, nil
+	}
+
+	tableStart := roachpb.RKey(keys.MakeTablePrefix(uint32(desc.ID)))
+	tableEnd := tableStart.PrefixEnd()
+	// The descriptor of a range is stored under the meta key of its end key,
+	// so the first range of the table is the first one whose meta key
+	// follows the meta key of the table's start key.
+	start := keys.RangeMetaKey(tableStart).Next()
+	// The lease of each range is requested from its lease holder.
+	b := &client.Batch{}
+	pErr = iterateRangeDescriptors(p.txn, start, func(rangeDesc roachpb.RangeDescriptor) bool {
+		v.rows = append(v.rows, []parser.Datum{
+			parser.DString(rangeDesc.StartKey.String()),
+			parser.DString(rangeDesc.EndKey.String()),
+			parser.DInt(rangeDesc.RangeID),
+			parser.DString(formatReplicas(rangeDesc.Replicas)),
+			parser.DNull,
+		})
+		b.InternalAddRequest(&roachpb.LeaseInfoRequest{
+			Span: roachpb.Span{Key: rangeDesc.StartKey.AsRawKey()},
+		})
+		return rangeDesc.EndKey.Less(tableEnd)
+	})
+	if pErr != nil {
+		return nil, pErr
+	}
+	if len(v.rows) == 0 {
+		return v, nil
+	}
+	br, pErr := p.txn.RunWithResponse(b)
+	if pErr != nil {
+		return nil, pErr
+	}
+	for i, resp := range br.Responses {
+		lease := resp.GetInner().(*roachpb.LeaseInfoResponse).Lease
+		if lease.Replica.NodeID != 0 {
+			v.rows[i][4] = parser.DInt(lease.Replica.NodeID)
+		}
+	}
+	return v, nil
+}
+
+// iterateRangeDescriptors calls f with the range descriptors stored in the
+// meta2 keys starting at start, in key order, until f returns false or all
+// the descriptors have been visited.
+func iterateRangeDescriptors(
+	txn *client.Txn, start roachpb.Key, f func(roachpb.RangeDescriptor) bool) *roachpb.Error {
+	for {
+		rows, pErr := txn.Scan(start, keys.Meta2KeyMax, rangeScanBatchSize)
+		if pErr != nil {
+			return pErr
+		}
+		for _, row := range rows {
+			var rangeDesc roachpb.RangeDescriptor
+			if err := row.ValueProto(&rangeDesc); err != nil {
+				return roachpb.NewError(err)
+			}
+			if !f(rangeDesc) {
+				return nil
+			}
+		}
+		if len(rows) < rangeScanBatchSize {
+			return nil
+		}
+		start = rows[len(rows)-1].Key.Next()
+	}
+}
+
+// formatReplicas formats the node IDs of the given replicas as "{1,2,3}".
+func formatReplicas(replicas []roachpb.ReplicaDescriptor) string {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, replica := range replicas {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		fmt.Fprintf(&buf, "%d", replica.NodeID)
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
 // ShowTables returns all the tables.
 // Privileges: None.
 //   Notes: postgres does not have a SHOW TABLES statement.
@@ -0,0 +1,69 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql_test
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+func TestShowRanges(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, sqlDB, _ := setup(t)
+	defer cleanup(s, sqlDB)
+
+	if _, err := sqlDB.Exec(`
+CREATE DATABASE t;
+CREATE TABLE t.kv (k CHAR PRIMARY KEY, v CHAR);
+INSERT INTO t.kv VALUES ('a', 'b'), ('c', 'd');
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := sqlDB.Query(`SHOW EXPERIMENTAL_RANGES FROM TABLE t.kv`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var count int
+	var lastEndKey string
+	for rows.Next() {
+		var startKey, endKey, replicas string
+		var rangeID, leaseHolder int64
+		if err := rows.Scan(&startKey, &endKey, &rangeID, &replicas, &leaseHolder); err != nil {
+			t.Fatal(err)
+		}
+		if count > 0 && startKey != lastEndKey {
+			t.Errorf("expected range %d to start at %s, got %s", rangeID, lastEndKey, startKey)
+		}
+		// The test server has a single node.
+		if replicas != "{1}" {
+			t.Errorf("expected range %d to have replicas {1}, got %s", rangeID, replicas)
+		}
+		if leaseHolder != 1 {
+			t.Errorf("expected the lease of range %d to be held by node 1, got %d", rangeID, leaseHolder)
+		}
+		lastEndKey = endKey
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if count == 0 {
+		t.Fatal("expected at least one range")
+	}
+}
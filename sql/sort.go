@@ -17,6 +17,7 @@
 package sql
 
 import (
+	"container/heap"
 	"fmt"
 	"sort"
 	"strings"
@@ -163,6 +164,9 @@ type sortNode struct {
 	ordering columnOrdering
 	needSort bool
 	pErr     *roachpb.Error
+	// If non-zero, only the first limit rows of the ordering are returned, so
+	// the sort only retains those rows (a top-k sort).
+	limit int64
 }
 
 func (n *sortNode) Columns() []ResultColumn {
@@ -205,6 +209,9 @@ func (n *sortNode) PErr() *roachpb.Error {
 func (n *sortNode) ExplainPlan() (name, description string, children []planNode) {
 	if n.needSort {
 		name = "sort"
+		if n.limit > 0 {
+			name = "topk"
+		}
 	} else {
 		name = "nosort"
 	}
@@ -219,6 +226,9 @@ func (n *sortNode) ExplainPlan() (name, description string, children []planNode)
 		strs[i] = fmt.Sprintf("%c%s", prefix, columns[o.colIdx].Name)
 	}
 	description = strings.Join(strs, ",")
+	if n.needSort && n.limit > 0 {
+		description = fmt.Sprintf("%s (%d rows)", description, n.limit)
+	}
 
 	return name, description, []planNode{n.plan}
 }
@@ -272,11 +282,28 @@ func (n *sortNode) initValues() bool {
 		// TODO(andrei): If we're scanning an index with a prefix matching an
 		// ordering prefix, we should only accumulate values for equal fields
 		// in this prefix, then sort the accumulated chunk and output.
+		h := topKHeap{v}
 		for n.plan.Next() {
 			values := n.plan.Values()
+			if n.limit > 0 && int64(len(v.rows)) == n.limit {
+				// Only the first limit rows are needed: the row replaces the last
+				// of the rows retained so far if it precedes it.
+				v.rows = append(v.rows, values)
+				precedes := v.Less(len(v.rows)-1, 0)
+				v.rows = v.rows[:len(v.rows)-1]
+				if !precedes {
+					continue
+				}
+				copy(v.rows[0], values)
+				heap.Fix(h, 0)
+				continue
+			}
 			valuesCopy := make(parser.DTuple, len(values))
 			copy(valuesCopy, values)
 			v.rows = append(v.rows, valuesCopy)
+			if n.limit > 0 && int64(len(v.rows)) == n.limit {
+				heap.Init(h)
+			}
 		}
 		n.pErr = n.plan.PErr()
 		if n.pErr != nil {
@@ -287,3 +314,24 @@ func (n *sortNode) initValues() bool {
 	n.plan = v
 	return true
 }
+
+// topKHeap is a max-heap of the rows of a valuesNode according to its
+// ordering, holding the rows retained by a top-k sort. The last of the
+// retained rows is at the root.
+type topKHeap struct {
+	*valuesNode
+}
+
+func (h topKHeap) Less(i, j int) bool {
+	return h.valuesNode.Less(j, i)
+}
+
+func (h topKHeap) Push(x interface{}) {
+	h.rows = append(h.rows, x.(parser.DTuple))
+}
+
+func (h topKHeap) Pop() interface{} {
+	row := h.rows[len(h.rows)-1]
+	h.rows = h.rows[:len(h.rows)-1]
+	return row
+}
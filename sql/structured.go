@@ -586,6 +586,20 @@ func (desc *TableDescriptor) FindIndexByID(id IndexID) (*IndexDescriptor, error)
 	return nil, fmt.Errorf("index-id \"%d\" does not exist", id)
 }
 
+// findIndexByIDWithMutations is like FindIndexByID, but also finds the
+// indexes which are being added or dropped.
+func (desc *TableDescriptor) findIndexByIDWithMutations(id IndexID) (*IndexDescriptor, error) {
+	if index, err := desc.FindIndexByID(id); err == nil {
+		return index, nil
+	}
+	for _, m := range desc.Mutations {
+		if index := m.GetIndex(); index != nil && index.ID == id {
+			return index, nil
+		}
+	}
+	return nil, fmt.Errorf("index-id \"%d\" does not exist", id)
+}
+
 func (desc *TableDescriptor) makeMutationComplete(m DescriptorMutation) {
 	switch m.Direction {
 	case DescriptorMutation_ADD:
@@ -289,6 +289,9 @@ type DescriptorMutation struct {
 	// involve adding two mutations: one for the column, and another for the
 	// unique constraint index.
 	MutationID MutationID `protobuf:"varint,5,opt,name=mutation_id,casttype=MutationID" json:"mutation_id"`
+	// Indicates that the mutation reverses a schema change which failed to
+	// apply. A rollback that fails is retried rather than reversed again.
+	Rollback bool `protobuf:"varint,6,opt,name=rollback" json:"rollback"`
 }
 
 func (m *DescriptorMutation) Reset()         { *m = DescriptorMutation{} }
@@ -937,6 +940,14 @@ func (m *DescriptorMutation) MarshalTo(data []byte) (int, error) {
 	data[i] = 0x28
 	i++
 	i = encodeVarintStructured(data, i, uint64(m.MutationID))
+	data[i] = 0x30
+	i++
+	if m.Rollback {
+		data[i] = 1
+	} else {
+		data[i] = 0
+	}
+	i++
 	return i, nil
 }
 
@@ -1306,6 +1317,7 @@ func (m *DescriptorMutation) Size() (n int) {
 	n += 1 + sovStructured(uint64(m.State))
 	n += 1 + sovStructured(uint64(m.Direction))
 	n += 1 + sovStructured(uint64(m.MutationID))
+	n += 2
 	return n
 }
 
@@ -2126,6 +2138,26 @@ func (m *DescriptorMutation) Unmarshal(data []byte) error {
 					break
 				}
 			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Rollback", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStructured
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Rollback = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipStructured(data[iNdEx:])
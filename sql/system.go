@@ -17,6 +17,7 @@
 package sql
 
 import (
+	"github.com/cockroachdb/cockroach/client"
 	"github.com/cockroachdb/cockroach/config"
 	"github.com/cockroachdb/cockroach/keys"
 	"github.com/cockroachdb/cockroach/roachpb"
@@ -64,12 +65,22 @@ CREATE TABLE system.zones (
   config BYTES
 );`
 
-	// blobs based on unique keys.
+	// Cluster settings which were changed from their defaults.
+	settingsTableSchema = `
+CREATE TABLE system.settings (
+  name        STRING PRIMARY KEY,
+  value       STRING NOT NULL,
+  lastUpdated TIMESTAMP NOT NULL
+);`
+
+	// blobs based on unique keys. The generation of a key is incremented
+	// every time it is set.
 	uiTableSchema = `
 CREATE TABLE system.ui (
 	key         STRING PRIMARY KEY,
 	value       BYTES,
-	lastUpdated TIMESTAMP NOT NULL
+	lastUpdated TIMESTAMP NOT NULL,
+	generation  INT
 );`
 )
 
@@ -95,6 +106,9 @@ var (
 	// zonesTable is the descriptor for the zones table.
 	zonesTable = createSystemTable(keys.ZonesTableID, zonesTableSchema)
 
+	// settingsTable is the descriptor for the settings table.
+	settingsTable = createSystemTable(keys.SettingsTableID, settingsTableSchema)
+
 	// SystemAllowedPrivileges describes the privileges allowed for each
 	// system object. No user may have more than those privileges, and
 	// the root user must have exactly those privileges.
@@ -105,6 +119,7 @@ var (
 		keys.DescriptorTableID: privilege.ReadData,
 		keys.UsersTableID:      privilege.ReadWriteData,
 		keys.ZonesTableID:      privilege.ReadWriteData,
+		keys.SettingsTableID:   privilege.ReadWriteData,
 		keys.LeaseTableID:      privilege.ReadWriteData,
 		keys.RangeEventTableID: privilege.ReadWriteData,
 		keys.UITableID:         privilege.ReadWriteData,
@@ -176,6 +191,7 @@ func addSystemDatabaseToSchema(target *MetadataSchema) {
 	target.AddDescriptor(keys.SystemDatabaseID, &descriptorTable)
 	target.AddDescriptor(keys.SystemDatabaseID, &usersTable)
 	target.AddDescriptor(keys.SystemDatabaseID, &zonesTable)
+	target.AddDescriptor(keys.SystemDatabaseID, &settingsTable)
 
 	// Add other system tables.
 	target.AddTable(keys.LeaseTableID, leaseTableSchema, privilege.List{privilege.ALL})
@@ -184,6 +200,35 @@ func addSystemDatabaseToSchema(target *MetadataSchema) {
 	target.otherKV = append(target.otherKV, createDefaultZoneConfig()...)
 }
 
+// CreateMissingSystemTables creates the system tables which were added after
+// the cluster was bootstrapped, so that clusters created by an earlier version
+// can use them. Tables which already exist are left alone, which makes it
+// safe for every node to call this on startup.
+func CreateMissingSystemTables(db *client.DB) *roachpb.Error {
+	for _, desc := range []*TableDescriptor{&settingsTable} {
+		desc := desc
+		if pErr := db.Txn(func(txn *client.Txn) *roachpb.Error {
+			nameKey := MakeNameMetadataKey(keys.SystemDatabaseID, desc.GetName())
+			gr, pErr := txn.Get(nameKey)
+			if pErr != nil {
+				return pErr
+			}
+			if gr.Exists() {
+				return nil
+			}
+			log.Infof("creating missing system table %q", desc.GetName())
+			b := txn.NewBatch()
+			b.CPut(nameKey, desc.GetID(), nil)
+			b.CPut(MakeDescMetadataKey(desc.GetID()), wrapDescriptor(desc), nil)
+			txn.SetSystemConfigTrigger()
+			return txn.CommitInBatch(b)
+		}); pErr != nil {
+			return pErr
+		}
+	}
+	return nil
+}
+
 // isSystemConfigID returns true if this ID is for a system config object.
 func isSystemConfigID(id ID) bool {
 	return id > 0 && id <= keys.MaxSystemConfigDescID
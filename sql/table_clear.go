@@ -0,0 +1,98 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/keys"
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/stop"
+)
+
+// Values of the CLEAR_DROPPED_DATA session variable.
+const (
+	clearDroppedDataImmediate = "Immediate"
+	clearDroppedDataDeferred  = "Deferred"
+)
+
+// tableClearInterval is the interval at which the keys of dropped tables
+// are checked for data which can be cleared.
+var tableClearInterval = 1 * time.Minute
+
+// TestSpeedupTableClear is used in tests to make the data of dropped
+// tables get cleared almost immediately after it becomes eligible. It must
+// be called before the server is started.
+func TestSpeedupTableClear() func() {
+	tableClearInterval = 20 * time.Millisecond
+	return func() {
+		tableClearInterval = 1 * time.Minute
+	}
+}
+
+// startTableClearer starts a worker which periodically clears the data of
+// dropped tables once the time recorded under their table clear key has
+// passed.
+func startTableClearer(db client.DB, stopper *stop.Stopper) {
+	stopper.RunWorker(func() {
+		ticker := time.NewTicker(tableClearInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if pErr := clearDroppedTables(db, time.Now().UnixNano()); pErr != nil {
+					log.Warningf("unable to clear dropped tables: %s", pErr)
+				}
+			case <-stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
+// clearDroppedTables clears the data of all dropped tables which were
+// scheduled to be cleared at or before now, a wall time in nanoseconds.
+// The data is cleared with a non-transactional ClearRange, after which the
+// table clear key is removed. A table whose data was only partially cleared
+// keeps its key and is retried.
+func clearDroppedTables(db client.DB, now int64) *roachpb.Error {
+	rows, pErr := db.Scan(keys.TableClearPrefix, keys.TableClearPrefix.PrefixEnd(), 0)
+	if pErr != nil {
+		return pErr
+	}
+	for _, row := range rows {
+		if row.ValueInt() > now {
+			continue
+		}
+		tableID, err := keys.DecodeTableClearKey(row.Key)
+		if err != nil {
+			return roachpb.NewError(err)
+		}
+		tableStartKey := roachpb.Key(keys.MakeTablePrefix(tableID))
+		tableEndKey := tableStartKey.PrefixEnd()
+		if log.V(2) {
+			log.Infof("ClearRange %s - %s", tableStartKey, tableEndKey)
+		}
+		if pErr := db.ClearRange(tableStartKey, tableEndKey); pErr != nil {
+			return pErr
+		}
+		if pErr := db.Del(row.Key); pErr != nil {
+			return pErr
+		}
+	}
+	return nil
+}
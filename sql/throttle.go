@@ -0,0 +1,288 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/security"
+	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/metric"
+	"github.com/cockroachdb/cockroach/util/settings"
+)
+
+// userRateLimitsSetting is the cluster setting which overrides the per-user
+// rate limits configured on the nodes. Its value has the format accepted by
+// ParseUserRateLimits; an empty value restores the configured limits.
+const userRateLimitsSetting = "sql.user_rate_limits"
+
+// UserRateLimit bounds the rate at which a single user may execute SQL
+// statements and read rows on a node. A zero value means unlimited.
+type UserRateLimit struct {
+	QueriesPerSecond  float64
+	RowsReadPerSecond float64
+}
+
+func (l UserRateLimit) unlimited() bool {
+	return l.QueriesPerSecond <= 0 && l.RowsReadPerSecond <= 0
+}
+
+// ParseUserRateLimits parses a comma-separated list of per-user rate
+// limits of the form "<user>=<queries/sec>:<rows read/sec>". Either rate
+// may be omitted or set to zero to leave it unlimited. The special user
+// "*" sets the limit applied to all users without an explicit entry. For
+// example:
+//
+//   *=100:10000,reporting=10:1000000
+func ParseUserRateLimits(s string) (UserRateLimit, map[string]UserRateLimit, error) {
+	var defaultLimit UserRateLimit
+	limits := map[string]UserRateLimit{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return UserRateLimit{}, nil, util.Errorf("invalid rate limit %q: expected <user>=<qps>:<rows>", entry)
+		}
+		rates := strings.SplitN(parts[1], ":", 2)
+		var limit UserRateLimit
+		for i, dst := range []*float64{&limit.QueriesPerSecond, &limit.RowsReadPerSecond} {
+			if i >= len(rates) || rates[i] == "" {
+				continue
+			}
+			v, err := strconv.ParseFloat(rates[i], 64)
+			if err != nil || v < 0 {
+				return UserRateLimit{}, nil, util.Errorf("invalid rate %q in rate limit %q", rates[i], entry)
+			}
+			*dst = v
+		}
+		if parts[0] == "*" {
+			defaultLimit = limit
+		} else {
+			limits[parts[0]] = limit
+		}
+	}
+	return defaultLimit, limits, nil
+}
+
+// errUserRateLimitExceeded is returned when a statement is rejected because
+// its user exceeded one of its rate limits.
+type errUserRateLimitExceeded struct {
+	user  string
+	what  string
+	limit float64
+}
+
+func (e errUserRateLimitExceeded) Error() string {
+	return fmt.Sprintf("user %s exceeded the rate limit of %g %s per second on this node; "+
+		"retry later", e.user, e.limit, e.what)
+}
+
+// A tokenBucket accrues tokens at a fixed rate, up to one second worth of
+// tokens.
+type tokenBucket struct {
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, now time.Time) *tokenBucket {
+	return &tokenBucket{rate: rate, tokens: rate, last: now}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens += b.rate * now.Sub(b.last).Seconds()
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+	b.last = now
+}
+
+// full returns whether the bucket accrued as many tokens as it holds.
+func (b *tokenBucket) full(now time.Time) bool {
+	b.refill(now)
+	return b.tokens >= b.rate
+}
+
+// userBuckets holds the token buckets of a single user. A nil bucket is
+// unlimited.
+type userBuckets struct {
+	queries *tokenBucket
+	rows    *tokenBucket
+}
+
+// idle returns whether all buckets of the user are full, in which case
+// they are indistinguishable from the new buckets of a user.
+func (b *userBuckets) idle(now time.Time) bool {
+	return (b.queries == nil || b.queries.full(now)) && (b.rows == nil || b.rows.full(now))
+}
+
+// userThrottlerSweepInterval is the interval at which the throttler
+// forgets the users which have been idle long enough to have their budgets
+// fully replenished.
+const userThrottlerSweepInterval = time.Minute
+
+// A userThrottler enforces per-user rate limits on statement execution.
+// The root user is never throttled.
+type userThrottler struct {
+	defaultLimit UserRateLimit
+	limits       map[string]UserRateLimit
+	now          func() time.Time
+
+	// queriesRejected and rowsRejected count the statements rejected
+	// because their user exhausted its statement and row budget,
+	// respectively.
+	queriesRejected *metric.Counter
+	rowsRejected    *metric.Counter
+
+	mu        sync.Mutex
+	users     map[string]*userBuckets
+	lastSweep time.Time
+}
+
+func newUserThrottler(defaultLimit UserRateLimit, limits map[string]UserRateLimit,
+	registry *metric.Registry) *userThrottler {
+	return &userThrottler{
+		defaultLimit:    defaultLimit,
+		limits:          limits,
+		now:             time.Now,
+		queriesRejected: registry.Counter("ratelimit.queries.rejected.count"),
+		rowsRejected:    registry.Counter("ratelimit.rows.rejected.count"),
+		users:           map[string]*userBuckets{},
+	}
+}
+
+// setLimits replaces the limits of the throttler. The budgets of all users
+// are reset.
+func (t *userThrottler) setLimits(defaultLimit UserRateLimit, limits map[string]UserRateLimit) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.defaultLimit = defaultLimit
+	t.limits = limits
+	t.users = map[string]*userBuckets{}
+}
+
+// registerSetting registers the cluster setting overriding the limits the
+// throttler was created with.
+func (t *userThrottler) registerSetting(r *settings.Registry) {
+	defaultLimit, limits := t.defaultLimit, t.limits
+	r.Register(userRateLimitsSetting,
+		"per-user rate limits overriding those configured on the nodes, e.g. *=100:10000,reporting=10:1000000",
+		"",
+		func(v string) error {
+			_, _, err := ParseUserRateLimits(v)
+			return err
+		},
+		func(v string) {
+			if v == "" {
+				t.setLimits(defaultLimit, limits)
+				return
+			}
+			// The value was validated by the registry.
+			d, l, _ := ParseUserRateLimits(v)
+			t.setLimits(d, l)
+		})
+}
+
+// bucketsLocked returns the buckets for the given user, or nil if the user
+// is not subject to any limit.
+func (t *userThrottler) bucketsLocked(user string, now time.Time) *userBuckets {
+	if now.Sub(t.lastSweep) >= userThrottlerSweepInterval {
+		t.sweepLocked(now)
+	}
+	if b, ok := t.users[user]; ok {
+		return b
+	}
+	limit, ok := t.limits[user]
+	if !ok {
+		limit = t.defaultLimit
+	}
+	var b *userBuckets
+	if user != security.RootUser && !limit.unlimited() {
+		b = &userBuckets{}
+		if limit.QueriesPerSecond > 0 {
+			b.queries = newTokenBucket(limit.QueriesPerSecond, now)
+		}
+		if limit.RowsReadPerSecond > 0 {
+			b.rows = newTokenBucket(limit.RowsReadPerSecond, now)
+		}
+	}
+	t.users[user] = b
+	return b
+}
+
+// sweepLocked forgets the users whose buckets are full, as well as those
+// which aren't subject to any limit, so that the users map doesn't grow
+// without bound.
+func (t *userThrottler) sweepLocked(now time.Time) {
+	for user, b := range t.users {
+		if b == nil || b.idle(now) {
+			delete(t.users, user)
+		}
+	}
+	t.lastSweep = now
+}
+
+// admitStatement consumes a statement token for the given user. An error
+// is returned if the user has exhausted either its statement budget or,
+// through previously executed statements, its row budget.
+func (t *userThrottler) admitStatement(user string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	b := t.bucketsLocked(user, now)
+	if b == nil {
+		return nil
+	}
+	if b.rows != nil {
+		b.rows.refill(now)
+		if b.rows.tokens < 0 {
+			t.rowsRejected.Inc(1)
+			return errUserRateLimitExceeded{user: user, what: "rows read", limit: b.rows.rate}
+		}
+	}
+	if b.queries != nil {
+		b.queries.refill(now)
+		if b.queries.tokens < 1 {
+			t.queriesRejected.Inc(1)
+			return errUserRateLimitExceeded{user: user, what: "queries", limit: b.queries.rate}
+		}
+		b.queries.tokens--
+	}
+	return nil
+}
+
+// recordRowsRead charges the given number of rows scanned by a statement
+// to the user's row budget. Statements are never interrupted midway; a user which goes over
+// its budget has its subsequent statements rejected until the budget has
+// been replenished.
+func (t *userThrottler) recordRowsRead(user string, rows int) {
+	if rows == 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	if b := t.bucketsLocked(user, now); b != nil && b.rows != nil {
+		b.rows.refill(now)
+		b.rows.tokens -= float64(rows)
+	}
+}
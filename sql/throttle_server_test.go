@@ -0,0 +1,71 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql_test
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/server"
+	"github.com/cockroachdb/cockroach/testutils"
+	"github.com/cockroachdb/cockroach/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+// TestUserRateLimitRowsScanned verifies that statements are charged the
+// rows they scan rather than the rows they return.
+func TestUserRateLimitRowsScanned(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx, _ := createTestServerContext()
+	ctx.SQLUserRateLimits = server.TestUser + "=:5"
+	s, sqlDB, _ := setupWithContext(t, ctx)
+	defer cleanup(s, sqlDB)
+
+	values := make([]string, 100)
+	for i := range values {
+		values[i] = fmt.Sprintf("(%d)", i)
+	}
+	if _, err := sqlDB.Exec(`
+CREATE DATABASE t;
+CREATE TABLE t.kv (k INT PRIMARY KEY);
+INSERT INTO t.kv VALUES ` + strings.Join(values, ",") + `;
+GRANT SELECT ON TABLE t.kv TO testuser;
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	url, cleanupFn := sqlutils.PGUrl(t, &s.TestServer, server.TestUser, "TestUserRateLimitRowsScanned")
+	defer cleanupFn()
+	userDB, err := sql.Open("postgres", url.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer userDB.Close()
+
+	// Counting the rows returns a single row but scans all of them, which
+	// exhausts the row budget for many seconds.
+	var count int
+	if err := userDB.QueryRow("SELECT COUNT(*) FROM t.kv").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 100 {
+		t.Fatalf("expected 100 rows, got %d", count)
+	}
+	if _, err := userDB.Exec("SELECT 1"); !testutils.IsError(err, "exceeded the rate limit of 5 rows read") {
+		t.Fatalf("expected the statement to be rejected, got %v", err)
+	}
+}
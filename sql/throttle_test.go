@@ -0,0 +1,174 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/security"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+	"github.com/cockroachdb/cockroach/util/metric"
+	"github.com/cockroachdb/cockroach/util/settings"
+)
+
+func TestParseUserRateLimits(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	def, limits, err := ParseUserRateLimits("*=100:10000, reporting=:500,app=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := (UserRateLimit{QueriesPerSecond: 100, RowsReadPerSecond: 10000}); def != e {
+		t.Errorf("expected default limit %+v, got %+v", e, def)
+	}
+	expected := map[string]UserRateLimit{
+		"reporting": {RowsReadPerSecond: 500},
+		"app":       {QueriesPerSecond: 2},
+	}
+	if !reflect.DeepEqual(limits, expected) {
+		t.Errorf("expected %+v, got %+v", expected, limits)
+	}
+
+	for _, s := range []string{"app", "=1:2", "app=x", "app=-1"} {
+		if _, _, err := ParseUserRateLimits(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestUserThrottler(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	registry := metric.NewRegistry()
+	th := newUserThrottler(UserRateLimit{QueriesPerSecond: 2}, map[string]UserRateLimit{
+		"reader": {RowsReadPerSecond: 10},
+	}, registry)
+	now := time.Unix(0, 0)
+	th.now = func() time.Time { return now }
+
+	// The default limit admits two statements per second.
+	for i := 0; i < 2; i++ {
+		if err := th.admitStatement("app"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := th.admitStatement("app"); err == nil {
+		t.Fatal("expected statement to be rejected")
+	}
+	if c := th.queriesRejected.Count(); c != 1 {
+		t.Errorf("expected 1 rejected statement, got %d", c)
+	}
+	now = now.Add(500 * time.Millisecond)
+	if err := th.admitStatement("app"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reading more rows than allowed rejects subsequent statements until
+	// the budget has been replenished.
+	if err := th.admitStatement("reader"); err != nil {
+		t.Fatal(err)
+	}
+	th.recordRowsRead("reader", 30)
+	if err := th.admitStatement("reader"); err == nil {
+		t.Fatal("expected statement to be rejected")
+	}
+	if c := th.rowsRejected.Count(); c != 1 {
+		t.Errorf("expected 1 rejected statement, got %d", c)
+	}
+	now = now.Add(2 * time.Second)
+	if err := th.admitStatement("reader"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The root user is never throttled.
+	for i := 0; i < 10; i++ {
+		if err := th.admitStatement(security.RootUser); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestUserThrottlerSetting(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	th := newUserThrottler(UserRateLimit{QueriesPerSecond: 1}, nil, metric.NewRegistry())
+	now := time.Unix(0, 0)
+	th.now = func() time.Time { return now }
+	r := settings.NewRegistry()
+	th.registerSetting(r)
+
+	if err := r.Validate(userRateLimitsSetting, "app"); err == nil {
+		t.Error("expected invalid limits to be rejected")
+	}
+
+	admit := func(n int) int {
+		var admitted int
+		for i := 0; i < n; i++ {
+			if th.admitStatement("app") == nil {
+				admitted++
+			}
+		}
+		return admitted
+	}
+	if a := admit(5); a != 1 {
+		t.Errorf("expected 1 admitted statement, got %d", a)
+	}
+	// The setting overrides the configured limits.
+	r.Update(map[string]string{userRateLimitsSetting: "*=3"})
+	if a := admit(5); a != 3 {
+		t.Errorf("expected 3 admitted statements, got %d", a)
+	}
+	// Removing it restores them.
+	r.Update(nil)
+	if a := admit(5); a != 1 {
+		t.Errorf("expected 1 admitted statement, got %d", a)
+	}
+}
+
+// TestUserThrottlerSweep verifies that the throttler forgets the users
+// whose budgets have been fully replenished.
+func TestUserThrottlerSweep(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	th := newUserThrottler(UserRateLimit{QueriesPerSecond: 1, RowsReadPerSecond: 10}, nil,
+		metric.NewRegistry())
+	now := time.Unix(0, 0)
+	th.now = func() time.Time { return now }
+
+	for _, user := range []string{"idle", "indebted", security.RootUser} {
+		if err := th.admitStatement(user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	th.recordRowsRead("indebted", 1000)
+	now = now.Add(userThrottlerSweepInterval)
+	if err := th.admitStatement("active"); err != nil {
+		t.Fatal(err)
+	}
+
+	th.mu.Lock()
+	defer th.mu.Unlock()
+	var users []string
+	for user := range th.users {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+	if expected := []string{"active", "indebted"}; !reflect.DeepEqual(users, expected) {
+		t.Errorf("expected users %s, got %s", expected, users)
+	}
+}
@@ -25,7 +25,12 @@ import (
 	"github.com/cockroachdb/cockroach/util/log"
 )
 
-// Truncate deletes all rows from a table.
+// Truncate deletes all rows from a table. If the session has opted into
+// CLEAR_DROPPED_DATA = 'immediate' and the statement runs in a transaction
+// of its own, the rows are cleared right away with a non-transactional
+// ClearRange, which leaves no versions behind for historical reads. Inside
+// an explicit transaction the rows are always deleted transactionally, so
+// that they are restored if the transaction aborts.
 // Privileges: DROP on table.
 //   Notes: postgres requires TRUNCATE.
 //          mysql requires DROP (for mysql >= 5.1.16, DELETE before that).
@@ -46,6 +51,15 @@ func (p *planner) Truncate(n *parser.Truncate) (planNode, *roachpb.Error) {
 		// Delete rows and indexes starting with the table's prefix.
 		tableStartKey := roachpb.Key(tablePrefix)
 		tableEndKey := tableStartKey.PrefixEnd()
+		if p.session.ClearDroppedDataImmediately && p.implicitTxn {
+			if log.V(2) {
+				log.Infof("ClearRange %s - %s", tableStartKey, tableEndKey)
+			}
+			if pErr := p.leaseMgr.db.ClearRange(tableStartKey, tableEndKey); pErr != nil {
+				return nil, pErr
+			}
+			continue
+		}
 		if log.V(2) {
 			log.Infof("DelRange %s - %s", tableStartKey, tableEndKey)
 		}
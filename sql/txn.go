@@ -17,11 +17,29 @@
 package sql
 
 import (
+	"github.com/cockroachdb/cockroach/client"
 	"github.com/cockroachdb/cockroach/roachpb"
 	"github.com/cockroachdb/cockroach/sql/parser"
 	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/metric"
 )
 
+// onePCMetrics count the transactions attempting to commit in the same batch
+// as their first writes, and those of them which committed in one phase,
+// as reported by the replica evaluating the commit, because their writes
+// touched a single range.
+type onePCMetrics struct {
+	attempts *metric.Counter
+	commits  *metric.Counter
+}
+
+func newOnePCMetrics(registry *metric.Registry) *onePCMetrics {
+	return &onePCMetrics{
+		attempts: registry.Counter("txn.1pc.attempts"),
+		commits:  registry.Counter("txn.1pc.commits"),
+	}
+}
+
 // BeginTransaction starts a new transaction.
 func (p *planner) BeginTransaction(n *parser.BeginTransaction) (planNode, error) {
 	if p.txn == nil {
@@ -38,12 +56,67 @@ func (p *planner) BeginTransaction(n *parser.BeginTransaction) (planNode, error)
 
 // CommitTransaction commits a transaction.
 func (p *planner) CommitTransaction(n *parser.CommitTransaction) (planNode, *roachpb.Error) {
-	pErr := p.txn.Commit()
+	var pErr *roachpb.Error
+	// The transaction was already committed along with its last write if
+	// the executor commits eagerly. The writes deferred by the statements
+	// executed in parallel are sent along with the commit; if they fail,
+	// the transaction is rolled back and ends all the same.
+	if p.hasParallelWrites() {
+		if pErr = p.commitParallelWrites(); pErr != nil {
+			p.txn.Cleanup(pErr)
+		}
+	} else if p.txn.Proto.Status != roachpb.COMMITTED {
+		pErr = p.txn.Commit()
+	}
 	// Reset transaction.
 	p.resetTxn()
 	return &emptyNode{}, pErr
 }
 
+// commitInBatch commits the transaction along with the writes of the batch,
+// saving a round-trip to the transaction coordinator. If the batch carries
+// the first writes of the transaction, it also carries its
+// BeginTransaction, and the transaction commits in one phase when the
+// writes touch a single range.
+//
+// If the executor commits an explicit transaction eagerly, the commit is
+// bounded by the expiration of the table leases used by the transaction,
+// past which the descriptors it used may have changed.
+func (p *planner) commitInBatch(b *client.Batch) *roachpb.Error {
+	if p.onePC != nil && !p.txn.Proto.Writing && len(b.Results) > 0 {
+		p.onePC.attempts.Inc(1)
+	}
+	var br *roachpb.BatchResponse
+	var pErr *roachpb.Error
+	if deadline, ok := p.leaseDeadline(); ok && p.eagerCommit && !p.implicitTxn {
+		br, pErr = p.txn.CommitInBatchByWithResponse(b, deadline)
+	} else {
+		br, pErr = p.txn.CommitInBatchWithResponse(b)
+	}
+	if pErr == nil && p.onePC != nil && len(br.Responses) > 0 {
+		// The replica evaluating the commit reports whether the transaction
+		// committed in one phase; a multi-range transaction doesn't.
+		last := br.Responses[len(br.Responses)-1].GetInner()
+		if reply, ok := last.(*roachpb.EndTransactionResponse); ok && reply.OnePhaseCommit {
+			p.onePC.commits.Inc(1)
+		}
+	}
+	return pErr
+}
+
+// leaseDeadline returns the earliest expiration of the table leases held by
+// the planner, if any.
+func (p *planner) leaseDeadline() (roachpb.Timestamp, bool) {
+	var deadline roachpb.Timestamp
+	for i, lease := range p.leases {
+		exp := roachpb.Timestamp{WallTime: lease.Expiration().UnixNano()}
+		if i == 0 || exp.Less(deadline) {
+			deadline = exp
+		}
+	}
+	return deadline, len(p.leases) > 0
+}
+
 // RollbackTransaction rolls back a transaction.
 func (p *planner) RollbackTransaction(n *parser.RollbackTransaction) (planNode, *roachpb.Error) {
 	pErr := p.txn.Rollback()
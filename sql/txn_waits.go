@@ -0,0 +1,91 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/sql/parser"
+	"github.com/cockroachdb/cockroach/util/log"
+)
+
+// txnWaitsTable is the name of the virtual table listing the transactions
+// which are blocked on the intents of other transactions.
+const txnWaitsTable = "txn_waits"
+
+// txnWaitsCache holds the transaction waits gossiped by all stores of the
+// cluster.
+type txnWaitsCache struct {
+	mu    sync.Mutex
+	waits map[roachpb.StoreID][]roachpb.TxnWait
+}
+
+func newTxnWaitsCache() *txnWaitsCache {
+	return &txnWaitsCache{waits: map[roachpb.StoreID][]roachpb.TxnWait{}}
+}
+
+// gossipUpdate is the gossip callback used to keep track of the
+// transaction waits of each store.
+func (c *txnWaitsCache) gossipUpdate(_ string, content roachpb.Value) {
+	var waits roachpb.TxnWaits
+	if err := content.GetProto(&waits); err != nil {
+		log.Error(err)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(waits.Waits) == 0 {
+		delete(c.waits, waits.StoreID)
+		return
+	}
+	c.waits[waits.StoreID] = waits.Waits
+}
+
+// txnWaits returns a valuesNode listing the current transaction waits of
+// the cluster, with one row per pusher/pushee pair.
+func (p *planner) txnWaits() (*valuesNode, *roachpb.Error) {
+	v := &valuesNode{
+		columns: []ResultColumn{
+			{Name: "store_id", Typ: parser.DummyInt},
+			{Name: "pusher_id", Typ: parser.DummyString},
+			{Name: "pusher_priority", Typ: parser.DummyInt},
+			{Name: "pushee_id", Typ: parser.DummyString},
+			{Name: "waiting_since", Typ: parser.DummyTimestamp},
+		},
+	}
+	if p.txnWaitsCache == nil {
+		return v, nil
+	}
+
+	c := p.txnWaitsCache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for storeID, waits := range c.waits {
+		for _, wait := range waits {
+			v.rows = append(v.rows, parser.DTuple{
+				parser.DInt(storeID),
+				parser.DString(wait.Pusher.ID.String()),
+				parser.DInt(wait.PusherPriority),
+				parser.DString(wait.Pushee.ID.String()),
+				parser.DTimestamp{Time: time.Unix(0, wait.Since).UTC()},
+			})
+		}
+	}
+	sort.Sort(crdbInternalRows(v.rows))
+	return v, nil
+}
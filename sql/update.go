@@ -333,8 +333,8 @@ func (p *planner) Update(n *parser.Update, autoCommit bool) (planNode, *roachpb.
 		// An auto-txn can commit the transaction with the batch. This is an
 		// optimization to avoid an extra round-trip to the transaction
 		// coordinator.
-		pErr = p.txn.CommitInBatch(b)
-	} else {
+		pErr = p.commitInBatch(b)
+	} else if !p.deferWrites(b, tableDesc) {
 		pErr = p.txn.Run(b)
 	}
 	if pErr != nil {
@@ -50,11 +50,21 @@ const (
 	// probabilistic "jitter" to shouldRebalance() function: the store will not
 	// take every rebalancing opportunity available.
 	rebalanceShouldRebalanceChance = 0.2
+	// maxReadAmplificationThreshold: if the read amplification of a store's
+	// engine exceeds this value, its LSM tree is considered unhealthy. The
+	// store is then avoided as an allocation target and sheds its replicas
+	// to healthy stores.
+	maxReadAmplificationThreshold = 20
+	// maxPendingCompactionBytesThreshold: if the compaction backlog of a
+	// store's engine exceeds this value, its LSM tree is considered
+	// unhealthy.
+	maxPendingCompactionBytesThreshold = 64 << 30 // 64 GB
 
 	// priorities for various repair operations.
 	removeDeadReplicaPriority  float64 = 10000
 	addMissingReplicaPriority  float64 = 1000
 	removeExtraReplicaPriority float64 = 100
+	promoteLearnerPriority     float64 = 50
 )
 
 // AllocatorAction enumerates the various replication adjustments that may be
@@ -68,6 +78,7 @@ const (
 	AllocatorRemove
 	AllocatorAdd
 	AllocatorRemoveDead
+	AllocatorPromoteLearner
 )
 
 // A BalanceMode is a configurable mode which effects how the allocator makes
@@ -153,7 +164,8 @@ type AllocatorOptions struct {
 // more balanced cluster, while still spreading load over all
 // available servers. "Load" is defined according to fraction of bytes
 // used, if greater than minFractionUsedThreshold; otherwise it's
-// defined according to range count.
+// defined according to range count. Stores whose LSM tree is unhealthy
+// (see lsmScore) are only chosen if no healthy store is available.
 //
 // When choosing a rebalance target, a random store is selected from
 // amongst the set of stores with fraction of bytes within
@@ -202,14 +214,27 @@ func (a *Allocator) ComputeAction(zone config.ZoneConfig, desc *roachpb.RangeDes
 	if len(deadReplicas) > 0 {
 		// The range has dead replicas, which should be removed immediately.
 		// Adjust the priority by the number of dead replicas the range has.
-		quorum := computeQuorum(len(desc.Replicas))
-		liveReplicas := len(desc.Replicas) - len(deadReplicas)
+		// Learners don't count towards the quorum of the range.
+		quorum := computeQuorum(countVoters(desc.Replicas))
+		liveReplicas := countVoters(desc.Replicas) - countVoters(deadReplicas)
 		return AllocatorRemoveDead, removeDeadReplicaPriority + float64(quorum-liveReplicas)
 	}
 
+	// Learners do not count towards the replication factor of the range.
+	// A learner added by a rebalance is promoted once it has caught up, after
+	// which the range has a voting replica too many and one is removed. The
+	// promotion and the removal are separate replication changes, so the
+	// range may be left over-replicated in between, in which case the
+	// removal is computed below like for any other over-replicated range.
+	for _, replica := range desc.Replicas {
+		if replica.Learner {
+			return AllocatorPromoteLearner, promoteLearnerPriority
+		}
+	}
+
 	// TODO(mrtracy): Handle non-homogeneous and mismatched attribute sets.
 	need := len(zone.ReplicaAttrs)
-	have := len(desc.Replicas)
+	have := countVoters(desc.Replicas)
 	if have < need {
 		// Range is under-replicated, and should add an additional replica.
 		// Priority is adjusted by the difference between the current replica
@@ -963,6 +963,50 @@ func TestAllocatorComputeAction(t *testing.T) {
 			},
 			expectedAction: AllocatorRemove,
 		},
+		// Three replicas have three, and a learner left over by a rebalance.
+		{
+			zone: config.ZoneConfig{
+				ReplicaAttrs: []roachpb.Attributes{
+					{
+						Attrs: []string{"us-east"},
+					},
+					{
+						Attrs: []string{"us-east"},
+					},
+					{
+						Attrs: []string{"us-east"},
+					},
+				},
+				RangeMinBytes: 0,
+				RangeMaxBytes: 64000,
+			},
+			desc: roachpb.RangeDescriptor{
+				Replicas: []roachpb.ReplicaDescriptor{
+					{
+						StoreID:   1,
+						NodeID:    1,
+						ReplicaID: 1,
+					},
+					{
+						StoreID:   2,
+						NodeID:    2,
+						ReplicaID: 2,
+					},
+					{
+						StoreID:   3,
+						NodeID:    3,
+						ReplicaID: 3,
+					},
+					{
+						StoreID:   4,
+						NodeID:    4,
+						ReplicaID: 4,
+						Learner:   true,
+					},
+				},
+			},
+			expectedAction: AllocatorPromoteLearner,
+		},
 		// Three replicas have three, none of the replicas in the store pool.
 		{
 			zone: config.ZoneConfig{
@@ -1055,6 +1099,57 @@ func TestAllocatorComputeAction(t *testing.T) {
 	}
 }
 
+// TestAllocatorComputeActionLearners verifies the actions computed for a
+// range through the steps of a rebalance over a learner replica, which only
+// counts towards the replication factor of the range once promoted.
+func TestAllocatorComputeActionLearners(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper, _, sp, a := createTestAllocator()
+	defer stopper.Stop()
+
+	mockStorePool(sp, []roachpb.StoreID{1, 2, 3, 4}, nil)
+
+	zone := config.ZoneConfig{
+		ReplicaAttrs:  []roachpb.Attributes{{}, {}, {}},
+		RangeMinBytes: 0,
+		RangeMaxBytes: 64000,
+	}
+	replicas := func(learners ...bool) []roachpb.ReplicaDescriptor {
+		var reps []roachpb.ReplicaDescriptor
+		for i, learner := range learners {
+			reps = append(reps, roachpb.ReplicaDescriptor{
+				StoreID:   roachpb.StoreID(i + 1),
+				NodeID:    roachpb.NodeID(i + 1),
+				ReplicaID: roachpb.ReplicaID(i + 1),
+				Learner:   learner,
+			})
+		}
+		return reps
+	}
+
+	testCases := []struct {
+		replicas       []roachpb.ReplicaDescriptor
+		expectedAction AllocatorAction
+	}{
+		// The learner was added and hasn't been promoted yet.
+		{replicas(false, false, false, true), AllocatorPromoteLearner},
+		// The learner was promoted, but no replica was removed yet: the range
+		// is left over-replicated until the next replication change.
+		{replicas(false, false, false, false), AllocatorRemove},
+		// A replica was removed.
+		{replicas(false, false, false), AllocatorNoop},
+		// The learner doesn't count towards the replication factor.
+		{replicas(false, false, true), AllocatorPromoteLearner},
+		{replicas(false, false), AllocatorAdd},
+	}
+	for i, tc := range testCases {
+		desc := roachpb.RangeDescriptor{Replicas: tc.replicas}
+		if action, _ := a.ComputeAction(zone, &desc); action != tc.expectedAction {
+			t.Errorf("%d: expected action %d, got action %d", i, tc.expectedAction, action)
+		}
+	}
+}
+
 type testStore struct {
 	roachpb.StoreDescriptor
 }
@@ -1211,3 +1306,76 @@ func Example_rebalancing() {
 	// 972 966 916 945 931 996 940 929 924 961 999 965 935 988 958 961 946 951 940 928
 	// Total bytes=990773690, ranges=1903
 }
+
+// TestAllocatorUnhealthyLSM verifies that stores whose LSM tree is
+// unhealthy are avoided as targets and shed their replicas.
+func TestAllocatorUnhealthyLSM(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper, g, _, a := createTestAllocator()
+	defer stopper.Stop()
+
+	// Store 1 is the least utilized store, but its read amplification is too
+	// high; store 2 has a large compaction backlog.
+	stores := []*roachpb.StoreDescriptor{
+		{
+			StoreID: 1,
+			Node:    roachpb.NodeDescriptor{NodeID: 1},
+			Capacity: roachpb.StoreCapacity{Capacity: 100, Available: 100, RangeCount: 1,
+				ReadAmplification: 2 * maxReadAmplificationThreshold},
+		},
+		{
+			StoreID: 2,
+			Node:    roachpb.NodeDescriptor{NodeID: 2},
+			Capacity: roachpb.StoreCapacity{Capacity: 100, Available: 100, RangeCount: 10,
+				PendingCompactionBytes: 3 * maxPendingCompactionBytesThreshold / 2},
+		},
+		{
+			StoreID:  3,
+			Node:     roachpb.NodeDescriptor{NodeID: 3},
+			Capacity: roachpb.StoreCapacity{Capacity: 100, Available: 100, RangeCount: 10},
+		},
+		{
+			StoreID:  4,
+			Node:     roachpb.NodeDescriptor{NodeID: 4},
+			Capacity: roachpb.StoreCapacity{Capacity: 100, Available: 100, RangeCount: 10},
+		},
+	}
+	gossiputil.NewStoreGossiper(g).GossipStores(stores, t)
+
+	for i := 0; i < 10; i++ {
+		result, err := a.AllocateTarget(roachpb.Attributes{}, []roachpb.ReplicaDescriptor{}, false, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.StoreID != 3 && result.StoreID != 4 {
+			t.Errorf("%d: expected store 3 or 4; got %d", i, result.StoreID)
+		}
+		if result := a.RebalanceTarget(4, roachpb.Attributes{}, []roachpb.ReplicaDescriptor{}); result != nil &&
+			result.StoreID != 3 {
+			t.Errorf("%d: expected store 3 or no rebalance target; got %d", i, result.StoreID)
+		}
+	}
+
+	// The replica on the store with the worst LSM tree is removed first.
+	replicas := []roachpb.ReplicaDescriptor{
+		{StoreID: 1, NodeID: 1, ReplicaID: 1},
+		{StoreID: 2, NodeID: 2, ReplicaID: 2},
+		{StoreID: 3, NodeID: 3, ReplicaID: 3},
+	}
+	targetRepl, err := a.RemoveTarget(replicas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, e := targetRepl, replicas[0]; a != e {
+		t.Fatalf("RemoveTarget did not select expected replica; expected %v, got %v", e, a)
+	}
+
+	// The unhealthy stores should rebalance; the healthy ones should not.
+	a.options.Deterministic = true
+	for i, store := range stores {
+		result := a.ShouldRebalance(store.StoreID)
+		if expResult := (i < 2); expResult != result {
+			t.Errorf("%d: expected rebalance %t; got %t", i, expResult, result)
+		}
+	}
+}
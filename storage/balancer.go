@@ -16,7 +16,11 @@
 
 package storage
 
-import "github.com/cockroachdb/cockroach/roachpb"
+import (
+	"math"
+
+	"github.com/cockroachdb/cockroach/roachpb"
+)
 
 type nodeIDSet map[roachpb.NodeID]struct{}
 
@@ -181,6 +185,9 @@ func (db usageBalancer) selectGood(sl StoreList, excluded nodeIDSet) *roachpb.St
 }
 
 func (db usageBalancer) selectBad(sl StoreList) *roachpb.StoreDescriptor {
+	if worst := selectWorstLSM(sl); worst != nil {
+		return worst
+	}
 	if sl.used.mean < minFractionUsedThreshold {
 		rcb := rangeCountBalancer{db.rand}
 		return rcb.selectBad(sl)
@@ -190,19 +197,65 @@ func (db usageBalancer) selectBad(sl StoreList) *roachpb.StoreDescriptor {
 }
 
 func (db usageBalancer) improve(store *roachpb.StoreDescriptor, sl StoreList, excluded nodeIDSet) *roachpb.StoreDescriptor {
+	if lsmUnhealthy(store.Capacity) {
+		// Move replicas away from a store whose LSM tree is unhealthy, as
+		// long as a healthy store can take them.
+		if candidate := db.selectGood(sl, excluded); candidate != nil && !lsmUnhealthy(candidate.Capacity) {
+			return candidate
+		}
+		return nil
+	}
+	var candidate *roachpb.StoreDescriptor
 	if sl.used.mean < minFractionUsedThreshold {
 		rcb := rangeCountBalancer{db.rand}
-		return rcb.improve(store, sl, excluded)
+		candidate = rcb.improve(store, sl, excluded)
+	} else {
+		ucb := usedCapacityBalancer{db.rand}
+		candidate = ucb.improve(store, sl, excluded)
 	}
-	ucb := usedCapacityBalancer{db.rand}
-	return ucb.improve(store, sl, excluded)
+	// Never move replicas from a healthy store to an unhealthy one.
+	if candidate != nil && lsmUnhealthy(candidate.Capacity) {
+		return nil
+	}
+	return candidate
+}
+
+// lsmScore measures the health of a store's LSM tree as the larger of its
+// read amplification and its compaction backlog, relative to their
+// respective thresholds. A score above 1 means that the LSM tree is
+// unhealthy.
+func lsmScore(capacity roachpb.StoreCapacity) float64 {
+	readAmp := float64(capacity.ReadAmplification) / maxReadAmplificationThreshold
+	backlog := float64(capacity.PendingCompactionBytes) / maxPendingCompactionBytesThreshold
+	return math.Max(readAmp, backlog)
+}
+
+// lsmUnhealthy returns whether the LSM tree of the store is unhealthy.
+func lsmUnhealthy(capacity roachpb.StoreCapacity) bool {
+	return lsmScore(capacity) > 1
+}
+
+// selectWorstLSM returns the store with the least healthy LSM tree from
+// the given store list, or nil if all stores are healthy.
+func selectWorstLSM(sl StoreList) *roachpb.StoreDescriptor {
+	var worst *roachpb.StoreDescriptor
+	for _, candidate := range sl.stores {
+		if !lsmUnhealthy(candidate.Capacity) {
+			continue
+		}
+		if worst == nil || lsmScore(candidate.Capacity) > lsmScore(worst.Capacity) {
+			worst = candidate
+		}
+	}
+	return worst
 }
 
 // selectRandom chooses up to count random store descriptors from the given
-// store list.
+// store list. Stores whose LSM tree is unhealthy are only chosen if there
+// are no healthy ones.
 func selectRandom(randGen allocatorRand, count int, sl StoreList,
 	excluded nodeIDSet) []*roachpb.StoreDescriptor {
-	var descs []*roachpb.StoreDescriptor
+	var descs, unhealthy []*roachpb.StoreDescriptor
 	// Randomly permute available stores matching the required attributes.
 	randGen.Lock()
 	defer randGen.Unlock()
@@ -212,12 +265,22 @@ func selectRandom(randGen allocatorRand, count int, sl StoreList,
 		if _, ok := excluded[desc.Node.NodeID]; ok {
 			continue
 		}
+		if lsmUnhealthy(desc.Capacity) {
+			unhealthy = append(unhealthy, desc)
+			continue
+		}
 		// Add this store; exit loop if we've satisfied count.
 		descs = append(descs, sl.stores[idx])
 		if len(descs) >= count {
 			break
 		}
 	}
+	if len(descs) == 0 {
+		if len(unhealthy) > count {
+			unhealthy = unhealthy[:count]
+		}
+		descs = unhealthy
+	}
 	if len(descs) == 0 {
 		return nil
 	}
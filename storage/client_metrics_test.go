@@ -131,6 +131,17 @@ func TestStoreMetrics(t *testing.T) {
 	verifyStats(t, store0)
 	verifyStats(t, store1)
 
+	// Both the leader and the follower applied the raft commands.
+	for i, s := range []*storage.Store{store0, store1} {
+		h := s.Registry().GetHistogram("raft.apply.latency")
+		if h == nil {
+			t.Fatalf("%d: store did not contain histogram raft.apply.latency", i)
+		}
+		if n := h.Current().TotalCount(); n == 0 {
+			t.Errorf("%d: expected the latency of applied raft commands to be recorded", i)
+		}
+	}
+
 	// Create a transaction statement that fails, but will add an entry to the
 	// sequence cache. Regression test for #4969.
 	if pErr := mtc.dbs[0].Txn(func(txn *client.Txn) *roachpb.Error {
@@ -1256,6 +1256,65 @@ func TestStoreRangeRebalance(t *testing.T) {
 	}
 }
 
+// TestLearnerReplica verifies that a learner replica is not part of the raft
+// group of its range until it has caught up and is promoted.
+func TestLearnerReplica(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	mtc := startMultiTestContext(t, 4)
+	defer mtc.Stop()
+
+	store0 := mtc.stores[0]
+	replica := store0.LookupReplica(roachpb.RKeyMin, nil)
+	mtc.replicateRange(replica.RangeID, 1, 2)
+
+	learner := roachpb.ReplicaDescriptor{
+		NodeID:  mtc.stores[3].Ident.NodeID,
+		StoreID: mtc.stores[3].Ident.StoreID,
+		Learner: true,
+	}
+	if err := replica.ChangeReplicas(roachpb.ADD_REPLICA, learner, replica.Desc()); err != nil {
+		t.Fatal(err)
+	}
+	_, added := replica.Desc().FindReplica(learner.StoreID)
+	if added == nil || !added.Learner {
+		t.Fatalf("expected learner on store %d, got %+v", learner.StoreID, replica.Desc().Replicas)
+	}
+	if _, ok := replica.RaftStatus().Progress[uint64(added.ReplicaID)]; ok {
+		t.Fatalf("expected learner %d not to be part of the raft group", added.ReplicaID)
+	}
+
+	util.SucceedsSoon(t, func() error {
+		caughtUp, err := replica.catchUpLearner(*added)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !caughtUp {
+			return util.Errorf("learner %d has not caught up", added.ReplicaID)
+		}
+		return nil
+	})
+	if _, err := mtc.stores[3].GetReplica(replica.RangeID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := replica.PromoteLearner(*added, replica.Desc()); err != nil {
+		t.Fatal(err)
+	}
+	rangeDesc := getRangeMetadata(roachpb.RKeyMin, mtc, t)
+	if len(rangeDesc.Replicas) != 4 {
+		t.Fatalf("expected 4 replicas, got %+v", rangeDesc.Replicas)
+	}
+	if _, repl := rangeDesc.FindReplica(learner.StoreID); repl == nil || repl.Learner {
+		t.Errorf("expected replica on store %d to be promoted, got %+v", learner.StoreID, repl)
+	}
+	util.SucceedsSoon(t, func() error {
+		if _, ok := replica.RaftStatus().Progress[uint64(added.ReplicaID)]; !ok {
+			return util.Errorf("expected replica %d to be part of the raft group", added.ReplicaID)
+		}
+		return nil
+	})
+}
+
 // TestReplicateRogueRemovedNode ensures that a rogue removed node
 // (i.e. a node that has been removed from the range but doesn't know
 // it yet because it was down or partitioned away when it happened)
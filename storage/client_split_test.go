@@ -37,9 +37,11 @@ import (
 	"github.com/cockroachdb/cockroach/testutils"
 	"github.com/cockroachdb/cockroach/util"
 	"github.com/cockroachdb/cockroach/util/encoding"
+	"github.com/cockroachdb/cockroach/util/hlc"
 	"github.com/cockroachdb/cockroach/util/leaktest"
 	"github.com/cockroachdb/cockroach/util/log"
 	"github.com/cockroachdb/cockroach/util/randutil"
+	"github.com/cockroachdb/cockroach/util/stop"
 	"github.com/cockroachdb/cockroach/util/tracing"
 )
 
@@ -470,6 +472,80 @@ func TestStoreRangeSplitStats(t *testing.T) {
 	}
 }
 
+// TestStoreRangeSplitStatsRaced verifies that the stats of both halves of a
+// split are repaired if commands raced with the computation of the stats of
+// the first half by the proposer of the split.
+func TestStoreRangeSplitStatsRaced(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer config.TestingDisableTableSplits()()
+	keyPrefix := keys.MakeTablePrefix(keys.MaxReservedDescID + 1)
+	keyPrefix = keys.MakeNonColumnKey(keyPrefix)
+	midKey := append([]byte(nil), keyPrefix...)
+	midKey = append(midKey, []byte("Z")...)
+	midKey = keys.MakeNonColumnKey(midKey)
+
+	sCtx := storage.TestStoreContext()
+	sCtx.TestingMocker.TestingCommandFilter =
+		func(_ roachpb.StoreID, args roachpb.Request, _ roachpb.Header) error {
+			et, ok := args.(*roachpb.EndTransactionRequest)
+			if !ok {
+				return nil
+			}
+			st := et.InternalCommitTrigger.GetSplitTrigger()
+			if st == nil || !st.NewDesc.StartKey.Equal(midKey) {
+				return nil
+			}
+			// Pretend that a command was applied after the proposer
+			// computed the stats, which are therefore wrong.
+			var err error
+			if st.LeftStats, err = (&engine.MVCCStats{}).Marshal(); err != nil {
+				return err
+			}
+			st.OrigStats, err = (&engine.MVCCStats{LiveCount: 1}).Marshal()
+			return err
+		}
+	store, stopper := createTestStoreWithContext(t, &sCtx)
+	defer stopper.Stop()
+
+	args := adminSplitArgs(roachpb.KeyMin, keyPrefix)
+	if _, pErr := client.SendWrapped(rg1(store), nil, &args); pErr != nil {
+		t.Fatal(pErr)
+	}
+	rng := store.LookupReplica(keyPrefix, nil)
+	writeRandomDataToRange(t, store, rng.RangeID, keyPrefix)
+
+	args = adminSplitArgs(keyPrefix, midKey)
+	if _, pErr := client.SendWrappedWith(rg1(store), nil, roachpb.Header{
+		RangeID: rng.RangeID,
+	}, &args); pErr != nil {
+		t.Fatal(pErr)
+	}
+	rngRight := store.LookupReplica(midKey, nil)
+
+	// The ages depend on the time of the recomputation; ignore them.
+	withoutAges := func(ms engine.MVCCStats) engine.MVCCStats {
+		ms.LastUpdateNanos, ms.IntentAge, ms.GCBytesAge = 0, 0, 0
+		return ms
+	}
+	util.SucceedsSoon(t, func() error {
+		for _, r := range []*storage.Replica{rng, rngRight} {
+			var ms engine.MVCCStats
+			if err := engine.MVCCGetRangeStats(store.Engine(), r.RangeID, &ms); err != nil {
+				return err
+			}
+			actualMs, err := storage.ComputeStatsForRange(r.Desc(), store.Engine(), 0)
+			if err != nil {
+				return err
+			}
+			if withoutAges(ms) != withoutAges(actualMs) {
+				return util.Errorf("range %d: expected stats %+v to be repaired to %+v",
+					r.RangeID, ms, actualMs)
+			}
+		}
+		return nil
+	})
+}
+
 // fillRange writes keys with the given prefix and associated values
 // until bytes bytes have been written or the given range has split.
 func fillRange(store *storage.Store, rangeID roachpb.RangeID, prefix roachpb.Key, bytes int64, t *testing.T) {
@@ -1065,3 +1141,42 @@ func writeRandomDataToRange(t testing.TB, store *storage.Store, rangeID roachpb.
 		}
 	}
 }
+
+// TestStoreRangeSplitByLoad verifies that a range receiving a sustained
+// request rate above the load-based split threshold is split between the
+// keys it is receiving requests for.
+func TestStoreRangeSplitByLoad(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	sCtx := storage.TestStoreContext()
+	sCtx.LoadSplitQPSThreshold = 10
+	manual := hlc.NewManualClock(0)
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+	store := createTestStoreWithEngine(t,
+		engine.NewInMem(roachpb.Attributes{}, 10<<20, stopper),
+		hlc.NewClock(manual.UnixNano),
+		true, &sCtx, stopper)
+
+	// Read keys "a" through "j" at 100 qps.
+	for i := 0; i < 1000; i++ {
+		args := getArgs(roachpb.Key{byte('a' + i%10)})
+		if _, pErr := client.SendWrapped(rg1(store), nil, &args); pErr != nil {
+			t.Fatal(pErr)
+		}
+	}
+	manual.Increment(int64(10 * time.Second))
+
+	util.SucceedsSoon(t, func() error {
+		// Trigger the split queue.
+		if err := store.Gossip().AddInfoProto(gossip.KeySystemConfig, &config.SystemConfig{}, 0); err != nil {
+			t.Fatal(err)
+		}
+		if rng := store.LookupReplica(roachpb.RKey("a"), nil); rng.Desc().ContainsKey(roachpb.RKey("j")) {
+			return util.Errorf("range %s has not been split due to load", rng)
+		}
+		return nil
+	})
+	if c := store.Registry().GetCounter("ranges.loadsplits").Count(); c != 1 {
+		t.Errorf("expected 1 load-based split, got %d", c)
+	}
+}
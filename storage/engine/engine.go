@@ -21,10 +21,17 @@ package engine
 import (
 	"sync"
 
+	"golang.org/x/net/context"
+
 	"github.com/cockroachdb/cockroach/roachpb"
 	"github.com/gogo/protobuf/proto"
 )
 
+// contextCheckInterval is the number of keys visited by long-running
+// iterations between checks of whether their context is done, after which
+// they stop and return the error of the context.
+const contextCheckInterval = 128
+
 // Iterator is an interface for iterating over key/value pairs in an
 // engine. Iterator implementations are thread safe unless otherwise
 // noted.
@@ -96,8 +103,10 @@ type Engine interface {
 	// key/value pairs. On each key value pair, the function f is
 	// invoked. If f returns an error or if the scan itself encounters
 	// an error, the iteration will stop and return the error.
-	// If the first result of f is true, the iteration stops.
-	Iterate(start, end MVCCKey, f func(MVCCKeyValue) (bool, error)) error
+	// If the first result of f is true, the iteration stops. The
+	// iteration also stops with the error of the context once it is
+	// done.
+	Iterate(ctx context.Context, start, end MVCCKey, f func(MVCCKeyValue) (bool, error)) error
 	// Clear removes the item from the db with the given key.
 	// Note that clear actually removes entries from the storage
 	// engine, rather than inserting tombstones.
@@ -187,7 +196,7 @@ func PutProto(engine Engine, key MVCCKey, msg proto.Message) (keyBytes, valBytes
 // Specify max=0 for unbounded scans.
 func Scan(engine Engine, start, end MVCCKey, max int64) ([]MVCCKeyValue, error) {
 	var kvs []MVCCKeyValue
-	err := engine.Iterate(start, end, func(kv MVCCKeyValue) (bool, error) {
+	err := engine.Iterate(context.TODO(), start, end, func(kv MVCCKeyValue) (bool, error) {
 		if max != 0 && int64(len(kvs)) >= max {
 			return true, nil
 		}
@@ -207,7 +216,7 @@ func ClearRange(engine Engine, start, end MVCCKey) (int, error) {
 	b := engine.NewBatch()
 	defer b.Close()
 	count := 0
-	if err := engine.Iterate(start, end, func(kv MVCCKeyValue) (bool, error) {
+	if err := engine.Iterate(context.TODO(), start, end, func(kv MVCCKeyValue) (bool, error) {
 		if err := b.Clear(kv.Key); err != nil {
 			return false, err
 		}
@@ -31,6 +31,7 @@ import (
 	"github.com/cockroachdb/cockroach/util/randutil"
 	"github.com/cockroachdb/cockroach/util/stop"
 	"github.com/gogo/protobuf/proto"
+	"golang.org/x/net/context"
 )
 
 func ensureRangeEqual(t *testing.T, sortedKeys []string, keyMap map[string][]byte, keyvals []MVCCKeyValue) {
@@ -526,6 +527,34 @@ func TestEngineDeleteRange(t *testing.T) {
 	}, t)
 }
 
+// TestEngineIterateCancel verifies that Iterate stops with the error of its
+// context once the context is cancelled.
+func TestEngineIterateCancel(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	runWithAllEngines(func(engine Engine, t *testing.T) {
+		for i := 0; i < 2*contextCheckInterval; i++ {
+			if err := engine.Put(mvccKey(fmt.Sprintf("%05d", i)), []byte("value")); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		var visited int
+		err := engine.Iterate(ctx, mvccKey(roachpb.RKeyMin), mvccKey(roachpb.RKeyMax),
+			func(MVCCKeyValue) (bool, error) {
+				visited++
+				cancel()
+				return false, nil
+			})
+		if err != context.Canceled {
+			t.Errorf("expected %s, got %v", context.Canceled, err)
+		}
+		if visited >= 2*contextCheckInterval {
+			t.Errorf("expected the iteration to stop early, visited %d keys", visited)
+		}
+	}, t)
+}
+
 func TestSnapshot(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	runWithAllEngines(func(engine Engine, t *testing.T) {
@@ -637,7 +666,7 @@ func TestSnapshotMethods(t *testing.T) {
 
 		// Verify Iterate.
 		index := 0
-		if err := snap.Iterate(mvccKey(roachpb.RKeyMin), mvccKey(roachpb.RKeyMax), func(kv MVCCKeyValue) (bool, error) {
+		if err := snap.Iterate(context.Background(), mvccKey(roachpb.RKeyMin), mvccKey(roachpb.RKeyMax), func(kv MVCCKeyValue) (bool, error) {
 			if !kv.Key.Equal(keys[index]) || !bytes.Equal(kv.Value, vals[index]) {
 				t.Errorf("%d: key/value not equal between expected and snapshot: %s/%s, %s/%s",
 					index, keys[index], vals[index], kv.Key, kv.Value)
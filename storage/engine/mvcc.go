@@ -24,6 +24,7 @@ import (
 	"sync"
 
 	"github.com/gogo/protobuf/proto"
+	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/keys"
 	"github.com/cockroachdb/cockroach/roachpb"
@@ -1107,7 +1108,7 @@ func MVCCDeleteRange(engine Engine, ms *MVCCStats, key, endKey roachpb.Key, max
 	// In order to detect the potential write intent by another
 	// concurrent transaction with a newer timestamp, we need
 	// to use the max timestamp for scan.
-	_, err := MVCCIterate(engine, key, endKey, roachpb.MaxTimestamp, true, txn, false, f)
+	_, err := MVCCIterate(context.TODO(), engine, key, endKey, roachpb.MaxTimestamp, true, txn, false, f)
 
 	iter.Close()
 	putBufferPool.Put(buf)
@@ -1174,17 +1175,26 @@ func getReverseScanMeta(iter Iterator, encEndKey MVCCKey, meta *MVCCMetadata) (M
 }
 
 // mvccScanInternal scans the key range [start,end) up to some maximum number
-// of results. Specify max=0 for unbounded scans. Specify reverse=true to scan
-// in descending instead of ascending order.
-func mvccScanInternal(engine Engine, key, endKey roachpb.Key, max int64, timestamp roachpb.Timestamp,
-	consistent bool, txn *roachpb.Transaction, reverse bool) ([]roachpb.KeyValue, []roachpb.Intent, error) {
+// of results. Specify max=0 for unbounded scans. If targetBytes is positive,
+// the scan also stops after the row at which the total size of the keys and
+// values returned reaches targetBytes. Specify reverse=true to scan in
+// descending instead of ascending order. If the context is done, the scan
+// stops and its error is returned.
+func mvccScanInternal(ctx context.Context, engine Engine, key, endKey roachpb.Key, max, targetBytes int64,
+	timestamp roachpb.Timestamp, consistent bool, txn *roachpb.Transaction,
+	reverse bool) ([]roachpb.KeyValue, []roachpb.Intent, error) {
 	var res []roachpb.KeyValue
-	intents, err := MVCCIterate(engine, key, endKey, timestamp, consistent, txn, reverse,
+	var resBytes int64
+	intents, err := MVCCIterate(ctx, engine, key, endKey, timestamp, consistent, txn, reverse,
 		func(kv roachpb.KeyValue) (bool, error) {
 			res = append(res, kv)
 			if max != 0 && max == int64(len(res)) {
 				return true, nil
 			}
+			resBytes += int64(len(kv.Key) + len(kv.Value.RawBytes))
+			if targetBytes > 0 && resBytes >= targetBytes {
+				return true, nil
+			}
 			return false, nil
 		})
 
@@ -1198,7 +1208,18 @@ func mvccScanInternal(engine Engine, key, endKey roachpb.Key, max int64, timesta
 // results in ascending order. Specify max=0 for unbounded scans.
 func MVCCScan(engine Engine, key, endKey roachpb.Key, max int64, timestamp roachpb.Timestamp,
 	consistent bool, txn *roachpb.Transaction) ([]roachpb.KeyValue, []roachpb.Intent, error) {
-	return mvccScanInternal(engine, key, endKey, max, timestamp,
+	return mvccScanInternal(context.TODO(), engine, key, endKey, max, 0, timestamp,
+		consistent, txn, false /* !reverse */)
+}
+
+// MVCCScanWithTargetBytes is like MVCCScan, but additionally stops after the
+// row at which the total size of the keys and values returned reaches
+// targetBytes. Specify targetBytes=0 for no byte limit. If the context is
+// done, the scan stops and its error is returned.
+func MVCCScanWithTargetBytes(ctx context.Context, engine Engine, key, endKey roachpb.Key, max, targetBytes int64,
+	timestamp roachpb.Timestamp, consistent bool,
+	txn *roachpb.Transaction) ([]roachpb.KeyValue, []roachpb.Intent, error) {
+	return mvccScanInternal(ctx, engine, key, endKey, max, targetBytes, timestamp,
 		consistent, txn, false /* !reverse */)
 }
 
@@ -1206,15 +1227,27 @@ func MVCCScan(engine Engine, key, endKey roachpb.Key, max int64, timestamp roach
 // results in descending order. Specify max=0 for unbounded scans.
 func MVCCReverseScan(engine Engine, key, endKey roachpb.Key, max int64, timestamp roachpb.Timestamp,
 	consistent bool, txn *roachpb.Transaction) ([]roachpb.KeyValue, []roachpb.Intent, error) {
-	return mvccScanInternal(engine, key, endKey, max, timestamp,
+	return mvccScanInternal(context.TODO(), engine, key, endKey, max, 0, timestamp,
+		consistent, txn, true /* reverse */)
+}
+
+// MVCCReverseScanWithTargetBytes is like MVCCReverseScan, but additionally
+// stops after the row at which the total size of the keys and values
+// returned reaches targetBytes. Specify targetBytes=0 for no byte limit. If
+// the context is done, the scan stops and its error is returned.
+func MVCCReverseScanWithTargetBytes(ctx context.Context, engine Engine, key, endKey roachpb.Key, max, targetBytes int64,
+	timestamp roachpb.Timestamp, consistent bool,
+	txn *roachpb.Transaction) ([]roachpb.KeyValue, []roachpb.Intent, error) {
+	return mvccScanInternal(ctx, engine, key, endKey, max, targetBytes, timestamp,
 		consistent, txn, true /* reverse */)
 }
 
 // MVCCIterate iterates over the key range [start,end). At each step of the
 // iteration, f() is invoked with the current key/value pair. If f returns true
 // (done) or an error, the iteration stops and the error is propagated. If the
-// reverse is flag set the iterator will be moved in reverse order.
-func MVCCIterate(engine Engine, startKey, endKey roachpb.Key, timestamp roachpb.Timestamp,
+// reverse is flag set the iterator will be moved in reverse order. If the
+// context is done, the iteration stops and its error is returned.
+func MVCCIterate(ctx context.Context, engine Engine, startKey, endKey roachpb.Key, timestamp roachpb.Timestamp,
 	consistent bool, txn *roachpb.Transaction, reverse bool, f func(roachpb.KeyValue) (bool, error)) ([]roachpb.Intent, error) {
 	if !consistent && txn != nil {
 		return nil, util.Errorf("cannot allow inconsistent reads within a transaction")
@@ -1277,7 +1310,12 @@ func MVCCIterate(engine Engine, startKey, endKey roachpb.Key, timestamp roachpb.
 	// the scan is consistent.
 	var wiErr error
 
-	for {
+	for n := 1; ; n++ {
+		if n%contextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
 		metaKey, err := getMeta(iter, encEndKey, &buf.meta)
 		if err != nil {
 			return nil, err
@@ -1766,7 +1804,7 @@ func MVCCFindSplitKey(engine Engine, rangeID roachpb.RangeID, key, endKey roachp
 	bestSplitDiff := int64(math.MaxInt64)
 	var lastKey roachpb.Key
 
-	if err := engine.Iterate(encStartKey, encEndKey, func(kv MVCCKeyValue) (bool, error) {
+	if err := engine.Iterate(context.TODO(), encStartKey, encEndKey, func(kv MVCCKeyValue) (bool, error) {
 		// Is key within a legal key range?
 		valid := IsValidSplitKey(kv.Key.Key)
 
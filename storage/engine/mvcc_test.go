@@ -29,6 +29,7 @@ import (
 	"unsafe"
 
 	"github.com/gogo/protobuf/proto"
+	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/keys"
 	"github.com/cockroachdb/cockroach/roachpb"
@@ -1092,6 +1093,59 @@ func TestMVCCScanMaxNum(t *testing.T) {
 	}
 }
 
+func TestMVCCScanTargetBytes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+	engine := createTestEngine(stopper)
+
+	for _, kv := range []struct {
+		key   roachpb.Key
+		value roachpb.Value
+	}{
+		{testKey1, value1},
+		{testKey2, value2},
+		{testKey3, value3},
+		{testKey4, value4},
+	} {
+		if err := MVCCPut(engine, nil, kv.key, makeTS(1, 0), kv.value, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rowSize := int64(len(testKey1) + len(value1.RawBytes))
+	testCases := []struct {
+		targetBytes int64
+		reverse     bool
+		expKeys     []roachpb.Key
+	}{
+		{0, false, []roachpb.Key{testKey1, testKey2, testKey3, testKey4}},
+		{1, false, []roachpb.Key{testKey1}},
+		{rowSize, false, []roachpb.Key{testKey1}},
+		{rowSize + 1, false, []roachpb.Key{testKey1, testKey2}},
+		{1, true, []roachpb.Key{testKey4}},
+		{2*rowSize + 1, true, []roachpb.Key{testKey4, testKey3, testKey2}},
+	}
+	for i, test := range testCases {
+		scan := MVCCScanWithTargetBytes
+		if test.reverse {
+			scan = MVCCReverseScanWithTargetBytes
+		}
+		kvs, _, err := scan(context.Background(), engine, testKey1, testKey4.Next(), 0, test.targetBytes,
+			makeTS(1, 0), true, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var keys []roachpb.Key
+		for _, kv := range kvs {
+			keys = append(keys, kv.Key)
+		}
+		if !reflect.DeepEqual(keys, test.expKeys) {
+			t.Errorf("%d: expected keys %s, got %s", i, test.expKeys, keys)
+		}
+	}
+}
+
 func TestMVCCScanWithKeyPrefix(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	stopper := stop.NewStopper()
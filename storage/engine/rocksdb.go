@@ -30,6 +30,7 @@ import (
 	"github.com/dustin/go-humanize"
 	"github.com/elastic/gosigar"
 	"github.com/gogo/protobuf/proto"
+	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/roachpb"
 	"github.com/cockroachdb/cockroach/storage/engine/rocksdb"
@@ -134,7 +135,7 @@ func (r *RocksDB) Open() error {
 	go func() {
 		<-r.deallocated
 	}()
-	r.stopper.AddCloser(r)
+	r.stopper.AddCloserLast(r)
 	return nil
 }
 
@@ -204,12 +205,36 @@ func (r *RocksDB) Clear(key MVCCKey) error {
 
 // Iterate iterates from start to end keys, invoking f on each
 // key/value pair. See engine.Iterate for details.
-func (r *RocksDB) Iterate(start, end MVCCKey, f func(MVCCKeyValue) (bool, error)) error {
-	return dbIterate(r.rdb, start, end, f)
+func (r *RocksDB) Iterate(ctx context.Context, start, end MVCCKey, f func(MVCCKeyValue) (bool, error)) error {
+	return dbIterate(ctx, r.rdb, start, end, f)
 }
 
-// Capacity queries the underlying file system for disk capacity information.
+// Capacity queries the underlying file system for disk capacity information
+// and RocksDB for the health of its LSM tree.
 func (r *RocksDB) Capacity() (roachpb.StoreCapacity, error) {
+	capacity, err := r.diskCapacity()
+	if err != nil {
+		return roachpb.StoreCapacity{}, err
+	}
+	var stats C.DBEngineStats
+	if err := statusToError(C.DBGetEngineStats(r.rdb, &stats)); err != nil {
+		return roachpb.StoreCapacity{}, err
+	}
+	capacity.ReadAmplification = int32(stats.read_amplification)
+	capacity.L0FileCount = int32(stats.l0_file_count)
+	if stats.pending_compaction_bytes < 0 {
+		// The estimate is only advisory; don't fail the capacity query
+		// without it.
+		log.Warningf("pending compaction bytes unavailable for rocksdb instance at %q", r.dir)
+	} else {
+		capacity.PendingCompactionBytes = int64(stats.pending_compaction_bytes)
+	}
+	return capacity, nil
+}
+
+// diskCapacity queries the underlying file system for disk capacity
+// information.
+func (r *RocksDB) diskCapacity() (roachpb.StoreCapacity, error) {
 	fileSystemUsage := gosigar.FileSystemUsage{}
 	dir := r.dir
 	if dir == "" {
@@ -380,8 +405,8 @@ func (r *rocksDBSnapshot) GetProto(key MVCCKey, msg proto.Message) (
 // Iterate iterates over the keys between start inclusive and end
 // exclusive, invoking f() on each key/value pair using the snapshot
 // handle.
-func (r *rocksDBSnapshot) Iterate(start, end MVCCKey, f func(MVCCKeyValue) (bool, error)) error {
-	return dbIterate(r.handle, start, end, f)
+func (r *rocksDBSnapshot) Iterate(ctx context.Context, start, end MVCCKey, f func(MVCCKeyValue) (bool, error)) error {
+	return dbIterate(ctx, r.handle, start, end, f)
 }
 
 // Clear is illegal for snapshot and returns an error.
@@ -481,8 +506,8 @@ func (r *rocksDBBatch) GetProto(key MVCCKey, msg proto.Message) (
 	return dbGetProto(r.batch, key, msg)
 }
 
-func (r *rocksDBBatch) Iterate(start, end MVCCKey, f func(MVCCKeyValue) (bool, error)) error {
-	return dbIterate(r.batch, start, end, f)
+func (r *rocksDBBatch) Iterate(ctx context.Context, start, end MVCCKey, f func(MVCCKeyValue) (bool, error)) error {
+	return dbIterate(ctx, r.batch, start, end, f)
 }
 
 func (r *rocksDBBatch) Clear(key MVCCKey) error {
@@ -665,6 +690,48 @@ func (r *rocksDBIterator) ComputeStats(start, end MVCCKey, nowNanos int64) (MVCC
 	return ms, nil
 }
 
+// RocksDBSstFileWriter writes key/value pairs to an SSTable file which
+// uses the same key encoding as RocksDB engines and can be ingested by
+// them.
+type RocksDBSstFileWriter struct {
+	fw *C.DBSstFileWriter
+}
+
+// MakeRocksDBSstFileWriter creates a new RocksDBSstFileWriter which
+// writes to the file at the specified path.
+func MakeRocksDBSstFileWriter(path string) (RocksDBSstFileWriter, error) {
+	var fw *C.DBSstFileWriter
+	if err := statusToError(C.DBSstFileWriterOpen(&fw, goToCSlice([]byte(path)))); err != nil {
+		return RocksDBSstFileWriter{}, err
+	}
+	return RocksDBSstFileWriter{fw: fw}, nil
+}
+
+// Add puts a key/value pair into the SSTable. Keys must be added in
+// increasing order.
+func (fw *RocksDBSstFileWriter) Add(kv MVCCKeyValue) error {
+	if len(kv.Key.Key) == 0 {
+		return emptyKeyError()
+	}
+	return statusToError(C.DBSstFileWriterAdd(fw.fw, goToCKey(kv.Key), goToCSlice(kv.Value)))
+}
+
+// Finish completes the SSTable, flushing it to disk. No further keys
+// may be added afterwards.
+func (fw *RocksDBSstFileWriter) Finish() error {
+	return statusToError(C.DBSstFileWriterFinish(fw.fw))
+}
+
+// Close frees the resources held by the writer. It must be called
+// whether or not Finish was.
+func (fw *RocksDBSstFileWriter) Close() {
+	if fw.fw == nil {
+		return
+	}
+	C.DBSstFileWriterClose(fw.fw)
+	fw.fw = nil
+}
+
 // goToCSlice converts a go byte slice to a DBSlice. Note that this is
 // potentially dangerous as the DBSlice holds a reference to the go
 // byte slice memory that the Go GC does not know about. This method
@@ -842,7 +909,7 @@ func dbClear(rdb *C.DBEngine, key MVCCKey) error {
 	return statusToError(C.DBDelete(rdb, goToCKey(key)))
 }
 
-func dbIterate(rdb *C.DBEngine, start, end MVCCKey,
+func dbIterate(ctx context.Context, rdb *C.DBEngine, start, end MVCCKey,
 	f func(MVCCKeyValue) (bool, error)) error {
 	if !start.Less(end) {
 		return nil
@@ -851,11 +918,17 @@ func dbIterate(rdb *C.DBEngine, start, end MVCCKey,
 	defer it.Close()
 
 	it.Seek(start)
-	for ; it.Valid(); it.Next() {
+	for n := 1; it.Valid(); it.Next() {
 		k := it.Key()
 		if !it.Key().Less(end) {
 			break
 		}
+		if n%contextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		n++
 		if done, err := f(MVCCKeyValue{Key: k, Value: it.Value()}); done || err != nil {
 			return err
 		}
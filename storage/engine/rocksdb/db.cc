@@ -25,9 +25,11 @@
 #include "rocksdb/db.h"
 #include "rocksdb/env.h"
 #include "rocksdb/filter_policy.h"
+#include "rocksdb/immutable_options.h"
 #include "rocksdb/merge_operator.h"
 #include "rocksdb/options.h"
 #include "rocksdb/slice_transform.h"
+#include "rocksdb/sst_file_writer.h"
 #include "rocksdb/statistics.h"
 #include "rocksdb/table.h"
 #include "rocksdb/utilities/write_batch_with_index.h"
@@ -1316,6 +1318,35 @@ uint64_t DBApproximateSize(DBEngine* db, DBKey start, DBKey end) {
   return result;
 }
 
+DBStatus DBGetEngineStats(DBEngine* db, DBEngineStats* stats) {
+  int32_t read_amp = 0;
+  int32_t l0_files = 0;
+  for (int level = 0; level < db->rep->NumberLevels(); level++) {
+    std::string files;
+    const std::string property =
+        "rocksdb.num-files-at-level" + std::to_string(level);
+    if (!db->rep->GetProperty(property, &files)) {
+      return FmtStatus("unable to get property %s", property.c_str());
+    }
+    const int n = atoi(files.c_str());
+    if (level == 0) {
+      l0_files = n;
+      read_amp += n;
+    } else if (n > 0) {
+      read_amp++;
+    }
+  }
+  uint64_t pending_bytes = 0;
+  stats->read_amplification = read_amp;
+  stats->l0_file_count = l0_files;
+  if (db->rep->GetIntProperty("rocksdb.estimate-pending-compaction-bytes", &pending_bytes)) {
+    stats->pending_compaction_bytes = pending_bytes;
+  } else {
+    stats->pending_compaction_bytes = -1;
+  }
+  return kSuccess;
+}
+
 DBStatus DBImpl::Put(DBKey key, DBSlice value) {
   rocksdb::WriteOptions options;
   return ToDBStatus(rep->Put(options, EncodeKey(key), ToSlice(value)));
@@ -1515,6 +1546,41 @@ DBStatus DBMergeOne(DBSlice existing, DBSlice update, DBString* new_value) {
   return MergeResult(&meta, new_value);
 }
 
+struct DBSstFileWriter {
+  rocksdb::Options options;
+  std::unique_ptr<rocksdb::ImmutableCFOptions> ioptions;
+  std::unique_ptr<rocksdb::SstFileWriter> rep;
+};
+
+DBStatus DBSstFileWriterOpen(DBSstFileWriter** fw, DBSlice path) {
+  std::unique_ptr<DBSstFileWriter> w(new DBSstFileWriter);
+  rocksdb::BlockBasedTableOptions table_options;
+  table_options.format_version = 2;
+  w->options.comparator = &kComparator;
+  w->options.table_factory.reset(rocksdb::NewBlockBasedTableFactory(table_options));
+  w->ioptions.reset(new rocksdb::ImmutableCFOptions(w->options));
+  w->rep.reset(new rocksdb::SstFileWriter(
+      rocksdb::EnvOptions(), *w->ioptions, &kComparator));
+  rocksdb::Status status = w->rep->Open(ToString(path));
+  if (!status.ok()) {
+    return ToDBStatus(status);
+  }
+  *fw = w.release();
+  return kSuccess;
+}
+
+DBStatus DBSstFileWriterAdd(DBSstFileWriter* fw, DBKey key, DBSlice val) {
+  return ToDBStatus(fw->rep->Add(EncodeKey(key), ToSlice(val)));
+}
+
+DBStatus DBSstFileWriterFinish(DBSstFileWriter* fw) {
+  return ToDBStatus(fw->rep->Finish());
+}
+
+void DBSstFileWriterClose(DBSstFileWriter* fw) {
+  delete fw;
+}
+
 const int64_t kNanosecondPerSecond = 1e9;
 
 inline int64_t age_factor(int64_t fromNS, int64_t toNS) {
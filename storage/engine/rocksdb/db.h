@@ -56,6 +56,7 @@ typedef DBString DBStatus;
 
 typedef struct DBEngine DBEngine;
 typedef struct DBIterator DBIterator;
+typedef struct DBSstFileWriter DBSstFileWriter;
 
 // DBOptions contains local database options.
 typedef struct {
@@ -91,6 +92,21 @@ DBStatus DBCompactRange(DBEngine* db, DBKey* start, DBKey* end);
 // range [start,end].
 uint64_t DBApproximateSize(DBEngine* db, DBKey start, DBKey end);
 
+// DBEngineStats contains statistics about the health of the LSM tree.
+typedef struct {
+  // The number of files a point lookup may have to consult: one per L0
+  // file plus one per other non-empty level.
+  int32_t read_amplification;
+  // The number of files in L0.
+  int32_t l0_file_count;
+  // The estimated number of bytes compactions need to rewrite, or -1 if
+  // the estimate is not available.
+  int64_t pending_compaction_bytes;
+} DBEngineStats;
+
+// Retrieves statistics about the health of the LSM tree.
+DBStatus DBGetEngineStats(DBEngine* db, DBEngineStats* stats);
+
 // Sets the database entry for "key" to "value".
 DBStatus DBPut(DBEngine* db, DBKey key, DBSlice value);
 
@@ -156,6 +172,21 @@ DBStatus DBIterError(DBIterator* iter);
 // Go code.
 DBStatus DBMergeOne(DBSlice existing, DBSlice update, DBString* new_value);
 
+// Creates a new writer of sstables at "path", using the same key
+// encoding and comparator as the database. It is the caller's
+// responsibility to call DBSstFileWriterClose().
+DBStatus DBSstFileWriterOpen(DBSstFileWriter** fw, DBSlice path);
+
+// Adds a key/value pair to the sstable. Keys must be added in
+// increasing order.
+DBStatus DBSstFileWriterAdd(DBSstFileWriter* fw, DBKey key, DBSlice val);
+
+// Finishes writing the sstable, flushing it to disk.
+DBStatus DBSstFileWriterFinish(DBSstFileWriter* fw);
+
+// Closes the writer, freeing memory and other resources.
+void DBSstFileWriterClose(DBSstFileWriter* fw);
+
 typedef struct {
   DBStatus status;
   int64_t live_bytes;
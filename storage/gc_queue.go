@@ -295,6 +295,7 @@ func (gcq *gcQueue) process(now roachpb.Timestamp, repl *Replica,
 	// Technically not needed since we're talking directly to the Range.
 	ba.RangeID = desc.RangeID
 	ba.Timestamp = now
+	ba.AdmissionClass = roachpb.BACKGROUND
 	ba.Add(gcArgs)
 	if _, pErr := repl.Send(repl.context(), ba); pErr != nil {
 		return pErr.GoError()
@@ -370,7 +371,7 @@ func processTransactionTable(r *Replica, txnMap map[uuid.UUID]*roachpb.Transacti
 	startKey := keys.TransactionKey(roachpb.KeyMin, uuid.EmptyUUID)
 	endKey := keys.TransactionKey(roachpb.KeyMax, uuid.EmptyUUID)
 
-	_, err := engine.MVCCIterate(snap, startKey, endKey, roachpb.ZeroTimestamp, true /* consistent */, nil /* txn */, false /* !reverse */, func(kv roachpb.KeyValue) (bool, error) {
+	_, err := engine.MVCCIterate(r.context(), snap, startKey, endKey, roachpb.ZeroTimestamp, true /* consistent */, nil /* txn */, false /* !reverse */, func(kv roachpb.KeyValue) (bool, error) {
 		return false, handleOne(kv)
 	})
 	return gcKeys, err
@@ -466,7 +467,7 @@ func pushTxn(repl *Replica, now roachpb.Timestamp, txn *roachpb.Transaction,
 		PusheeTxn: txn.TxnMeta,
 		PushType:  typ,
 	}
-	b := &client.Batch{}
+	b := &client.Batch{AdmissionClass: roachpb.BACKGROUND}
 	b.InternalAddRequest(pushArgs)
 	br, err := repl.store.DB().RunWithResponse(b)
 	if err != nil {
@@ -0,0 +1,163 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/config"
+	"github.com/cockroachdb/cockroach/gossip"
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/util/log"
+)
+
+const (
+	// mergeQueueMaxSize is the max size of the merge queue.
+	mergeQueueMaxSize = 100
+	// mergeQueueTimerDuration is the duration between merges of queued
+	// ranges. Merges are not urgent, so they are paced to limit the churn
+	// of range descriptors.
+	mergeQueueTimerDuration = time.Second
+)
+
+// mergeQueue manages a queue of ranges slated to be merged with their
+// right-hand neighbor because both are small and receive little load.
+type mergeQueue struct {
+	baseQueue
+	db *client.DB
+}
+
+// newMergeQueue returns a new instance of mergeQueue.
+func newMergeQueue(db *client.DB, gossip *gossip.Gossip) *mergeQueue {
+	mq := &mergeQueue{
+		db: db,
+	}
+	mq.baseQueue = makeBaseQueue("merge", mq, gossip, mergeQueueMaxSize)
+	return mq
+}
+
+func (*mergeQueue) needsLeaderLease() bool {
+	return true
+}
+
+func (*mergeQueue) acceptsUnsplitRanges() bool {
+	return false
+}
+
+// shouldQueue determines whether a range should be queued for merging
+// with its right-hand neighbor. See mergeCandidate for the conditions.
+// The priority is higher the smaller the merged range would be.
+func (*mergeQueue) shouldQueue(now roachpb.Timestamp, rng *Replica,
+	sysCfg *config.SystemConfig) (shouldQ bool, priority float64) {
+
+	rightRng, ok := mergeCandidate(now, rng, sysCfg)
+	if !ok {
+		return false, 0
+	}
+	zone, err := sysCfg.GetZoneConfigForKey(rng.Desc().StartKey)
+	if err != nil {
+		log.Error(err)
+		return false, 0
+	}
+	size := rng.stats.GetSize() + rightRng.stats.GetSize()
+	return true, 1 - float64(size)/float64(zone.RangeMinBytes)
+}
+
+// process merges the range with its right-hand neighbor if it is still a
+// merge candidate.
+func (mq *mergeQueue) process(now roachpb.Timestamp, rng *Replica,
+	sysCfg *config.SystemConfig) error {
+
+	rightRng, ok := mergeCandidate(now, rng, sysCfg)
+	if !ok {
+		return nil
+	}
+	desc := rng.Desc()
+	log.Infof("merging %s into %s: size=%d+%d", rightRng, rng, rng.stats.GetSize(), rightRng.stats.GetSize())
+	if _, pErr := client.SendWrapped(rng, rng.context(), &roachpb.AdminMergeRequest{
+		Span: roachpb.Span{Key: desc.StartKey.AsRawKey()},
+	}); pErr != nil {
+		return pErr.GoError()
+	}
+	rng.store.metrics.mergeCount.Inc(1)
+	return nil
+}
+
+// mergeCandidate returns the right-hand neighbor of the range if the two
+// should be merged. This is the case if merging is enabled, the neighbor
+// is on the same store with the same replicas, no split boundary from the
+// zone configs separates them, their combined size is below the zone's
+// minimum range size, their combined request rate is well below the
+// load-based split threshold and neither was split recently. The neighbor's
+// request rate is only known to its lease holder, so it must hold the lease
+// locally.
+func mergeCandidate(now roachpb.Timestamp, rng *Replica,
+	sysCfg *config.SystemConfig) (*Replica, bool) {
+	ctx := rng.store.ctx
+	if !ctx.MergeQueueEnabled {
+		return nil, false
+	}
+	desc := rng.Desc()
+	if desc.EndKey.Equal(roachpb.RKeyMax) {
+		return nil, false
+	}
+	rightRng := rng.store.LookupReplica(desc.EndKey, nil)
+	if rightRng == nil {
+		return nil, false
+	}
+	if lease := rightRng.getLeaderLease(); !lease.OwnedBy(rng.store.StoreID()) || !lease.Covers(now) {
+		return nil, false
+	}
+	rightDesc := rightRng.Desc()
+	if !replicaSetsEqual(desc.Replicas, rightDesc.Replicas) {
+		return nil, false
+	}
+	if len(sysCfg.ComputeSplitKeys(desc.StartKey, rightDesc.EndKey)) > 0 {
+		return nil, false
+	}
+
+	zone, err := sysCfg.GetZoneConfigForKey(desc.StartKey)
+	if err != nil {
+		log.Error(err)
+		return nil, false
+	}
+	if rng.stats.GetSize()+rightRng.stats.GetSize() >= zone.RangeMinBytes {
+		return nil, false
+	}
+
+	// Both ranges must have been measured for a while, which also enforces
+	// a cooldown after splits since splitting resets the measurement.
+	if rng.load.age() < ctx.MergeCooldown || rightRng.load.age() < ctx.MergeCooldown {
+		return nil, false
+	}
+	// Leave ample headroom below the split threshold so that the merged
+	// range is not immediately split again.
+	if threshold := ctx.LoadSplitQPSThreshold; threshold > 0 &&
+		rng.load.qps()+rightRng.load.qps() >= float64(threshold)/2 {
+		return nil, false
+	}
+	return rightRng, true
+}
+
+// timer returns interval between processing successive queued merges.
+func (*mergeQueue) timer() time.Duration {
+	return mergeQueueTimerDuration
+}
+
+// purgatoryChan returns nil.
+func (*mergeQueue) purgatoryChan() <-chan struct{} {
+	return nil
+}
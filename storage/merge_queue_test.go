@@ -0,0 +1,103 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/config"
+	"github.com/cockroachdb/cockroach/gossip"
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/storage/engine"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+// TestMergeQueueShouldQueue verifies that small, cold ranges are queued
+// for merging with their right-hand neighbor once the cooldown after a
+// split has passed.
+func TestMergeQueueShouldQueue(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tc := testContext{}
+	ctx := TestStoreContext()
+	ctx.MergeQueueEnabled = true
+	ctx.MergeCooldown = time.Minute
+	ctx.LoadSplitQPSThreshold = 10
+	tc.StartWithStoreContext(t, ctx)
+	defer tc.Stop()
+
+	if err := tc.gossip.AddInfoProto(gossip.KeySystemConfig, &config.SystemConfig{}, 0); err != nil {
+		t.Fatal(err)
+	}
+	cfg := tc.gossip.GetSystemConfig()
+	if cfg == nil {
+		t.Fatal("nil config")
+	}
+
+	for _, key := range []string{"b", "m", "z"} {
+		splitTestRange(tc.store, roachpb.RKey(key), roachpb.RKey(key), t)
+	}
+	left := tc.store.LookupReplica(roachpb.RKey("b"), nil)
+	right := tc.store.LookupReplica(roachpb.RKey("m"), nil)
+	mergeQ := newMergeQueue(nil, tc.gossip)
+
+	checkShouldQueue := func(expected bool) {
+		if shouldQ, _ := mergeQ.shouldQueue(tc.clock.Now(), left, cfg); shouldQ != expected {
+			t.Errorf("expected should queue %t; got %t", expected, shouldQ)
+		}
+	}
+	acquireRightLease := func() {
+		sp := tc.store.Tracer().StartSpan("test")
+		defer sp.Finish()
+		if pErr := right.redirectOnOrAcquireLeaderLease(sp, right.context()); pErr != nil {
+			t.Fatal(pErr)
+		}
+	}
+
+	// The ranges were just split.
+	checkShouldQueue(false)
+
+	tc.manualClock.Increment(int64(time.Minute))
+	// The right-hand range's request rate is only known to its lease holder.
+	checkShouldQueue(false)
+	acquireRightLease()
+	checkShouldQueue(true)
+	if _, priority := mergeQ.shouldQueue(tc.clock.Now(), left, cfg); priority != 1 {
+		t.Errorf("expected priority 1 for empty ranges; got %f", priority)
+	}
+
+	// 10 qps on the right-hand range exceeds half the split threshold.
+	for i := 0; i < 600; i++ {
+		right.load.record(roachpb.Key("n"))
+	}
+	checkShouldQueue(false)
+	tc.manualClock.Increment(int64(2 * replicaLoadWindow))
+	acquireRightLease()
+	checkShouldQueue(true)
+
+	// Ranges which are too large together are not merged.
+	if err := left.stats.SetMVCCStats(tc.engine, engine.MVCCStats{KeyBytes: 1 << 20}); err != nil {
+		t.Fatal(err)
+	}
+	checkShouldQueue(false)
+	if err := left.stats.SetMVCCStats(tc.engine, engine.MVCCStats{}); err != nil {
+		t.Fatal(err)
+	}
+	checkShouldQueue(true)
+
+	// Merging can be disabled.
+	tc.store.ctx.MergeQueueEnabled = false
+	checkShouldQueue(false)
+}
@@ -183,6 +183,7 @@ type baseQueue struct {
 
 	// TODO(tamird): update all queues to use eventLog.
 	eventLog queueLog
+	metrics  *queueMetrics
 }
 
 // makeBaseQueue returns a new instance of baseQueue with the
@@ -202,6 +203,7 @@ func makeBaseQueue(name string, impl queueImpl, gossip *gossip.Gossip, maxSize i
 			traceLog: trace.NewEventLog("queue", name),
 			prefix:   fmt.Sprintf("[%s] ", name),
 		},
+		metrics: newQueueMetrics(),
 	}
 	bq.mu.Locker = new(sync.Mutex)
 	bq.mu.replicas = map[roachpb.RangeID]*replicaItem{}
@@ -316,6 +318,7 @@ func (bq *baseQueue) addInternal(repl *Replica, should bool, priority float64) e
 	if pqLen := bq.mu.priorityQ.Len(); pqLen > bq.maxSize {
 		bq.remove(bq.mu.priorityQ[pqLen-1])
 	}
+	bq.updateLengthMetricsLocked()
 	// Signal the processLoop that a replica has been added.
 	select {
 	case bq.incoming <- struct{}{}:
@@ -422,7 +425,9 @@ func (bq *baseQueue) processReplica(repl *Replica, clock *hlc.Clock) error {
 
 	bq.eventLog.Infof(log.V(3), "%s: processing", repl)
 	start := time.Now()
-	if err := bq.impl.process(clock.Now(), repl, cfg); err != nil {
+	err := bq.impl.process(clock.Now(), repl, cfg)
+	bq.metrics.recordProcessing(time.Since(start), err)
+	if err != nil {
 		return err
 	}
 	bq.eventLog.Infof(log.V(2), "%s: done: %s", repl, time.Since(start))
@@ -457,6 +462,7 @@ func (bq *baseQueue) maybeAddToPurgatory(repl *Replica, err error, clock *hlc.Cl
 	// If purgatory already exists, just add to the map and we're done.
 	if bq.mu.purgatory != nil {
 		bq.mu.purgatory[repl.RangeID] = err
+		bq.updateLengthMetricsLocked()
 		return
 	}
 
@@ -464,6 +470,7 @@ func (bq *baseQueue) maybeAddToPurgatory(repl *Replica, err error, clock *hlc.Cl
 	bq.mu.purgatory = map[roachpb.RangeID]error{
 		repl.RangeID: err,
 	}
+	bq.updateLengthMetricsLocked()
 
 	stopper.RunWorker(func() {
 		ticker := time.NewTicker(purgatoryReportInterval)
@@ -521,6 +528,7 @@ func (bq *baseQueue) pop() *Replica {
 	}
 	item := heap.Pop(&bq.mu.priorityQ).(*replicaItem)
 	delete(bq.mu.replicas, item.value.RangeID)
+	bq.updateLengthMetricsLocked()
 	return item.value
 }
 
@@ -533,6 +541,7 @@ func (bq *baseQueue) remove(item *replicaItem) {
 		heap.Remove(&bq.mu.priorityQ, item.index)
 	}
 	delete(bq.mu.replicas, item.value.RangeID)
+	bq.updateLengthMetricsLocked()
 }
 
 // DrainQueue locks the queue and processes the remaining queued replicas. It
@@ -0,0 +1,132 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/util/metric"
+)
+
+// queueMetricsMetadata describes the metrics of a queue.
+var queueMetricsMetadata = map[string]metric.Metadata{
+	"pending":         {Unit: metric.UnitCount, Help: "Number of replicas waiting to be processed"},
+	"purgatory":       {Unit: metric.UnitCount, Help: "Number of replicas in purgatory"},
+	"process.success": {Unit: metric.UnitCount, Cumulative: true, Help: "Number of replicas processed successfully"},
+	"process.failure": {Unit: metric.UnitCount, Cumulative: true, Help: "Number of replicas which failed processing"},
+	"process.errors": {
+		Unit:       metric.UnitCount,
+		Cumulative: true,
+		Help:       "Number of replicas which failed processing, by type of error",
+	},
+}
+
+// queueMetrics are the metrics of a queue. They are kept in a registry of
+// their own, which the store adds to its registry under
+// "queue.<metricsName>.", so that all the queues report the same set of
+// metrics.
+type queueMetrics struct {
+	registry *metric.Registry
+
+	pending         *metric.Gauge
+	purgatory       *metric.Gauge
+	successes       *metric.Counter
+	failures        *metric.Counter
+	processingNanos metric.Latency
+
+	mu struct {
+		sync.Mutex
+		// errors holds the failure counters of the queue, keyed by the
+		// type of the errors they count. They are created as the errors
+		// are encountered.
+		errors map[string]*metric.Counter
+	}
+}
+
+func newQueueMetrics() *queueMetrics {
+	registry := metric.NewRegistry()
+	for name, metadata := range queueMetricsMetadata {
+		registry.SetMetadata(name, metadata)
+	}
+	qm := &queueMetrics{
+		registry:        registry,
+		pending:         registry.Gauge("pending"),
+		purgatory:       registry.Gauge("purgatory"),
+		successes:       registry.Counter("process.success"),
+		failures:        registry.Counter("process.failure"),
+		processingNanos: registry.Latency("processingnanos"),
+	}
+	qm.mu.errors = map[string]*metric.Counter{}
+	return qm
+}
+
+// metricsName returns the name of the queue in the names of its metrics:
+// its name in lower case without spaces.
+func (bq *baseQueue) metricsName() string {
+	return strings.ToLower(strings.Replace(bq.name, " ", "", -1))
+}
+
+// updateLengthMetricsLocked updates the gauges of the number of replicas
+// waiting in the queue and in purgatory. Caller must hold mutex.
+func (bq *baseQueue) updateLengthMetricsLocked() {
+	bq.metrics.pending.Update(int64(bq.mu.priorityQ.Len()))
+	bq.metrics.purgatory.Update(int64(len(bq.mu.purgatory)))
+}
+
+// recordProcessing records the outcome and the duration of the processing
+// of a replica.
+func (qm *queueMetrics) recordProcessing(d time.Duration, err error) {
+	qm.processingNanos.RecordValue(d)
+	if err == nil {
+		qm.successes.Inc(1)
+		return
+	}
+	qm.failures.Inc(1)
+	errType := queueErrorType(err)
+	qm.mu.Lock()
+	c, ok := qm.mu.errors[errType]
+	if !ok {
+		c = qm.registry.CounterWithLabels("process.errors", map[string]string{"error": errType})
+		qm.mu.errors[errType] = c
+	}
+	qm.mu.Unlock()
+	c.Inc(1)
+}
+
+// queueErrorType returns the type of an error returned by the processing
+// of a replica, as reported by the failure metrics of the queues: the name
+// of the structured error for roachpb errors, "purgatory" for errors which
+// send replicas to purgatory, and "other" for unstructured errors.
+func queueErrorType(err error) string {
+	var detail interface{} = err
+	if pErr, ok := err.(*roachpb.Error); ok {
+		if pErr.Detail == nil {
+			return "other"
+		}
+		detail = pErr.Detail.GetValue()
+	}
+	switch detail.(type) {
+	case purgatoryError:
+		return "purgatory"
+	case roachpb.ErrorDetailInterface:
+		name := fmt.Sprintf("%T", detail)
+		return name[strings.LastIndex(name, ".")+1:]
+	}
+	return "other"
+}
@@ -548,3 +548,74 @@ func TestBaseQueuePurgatory(t *testing.T) {
 	}
 	bq.mu.Unlock()
 }
+
+// TestBaseQueueMetrics verifies that the queue reports the number of
+// pending replicas and the outcome of their processing, including the
+// types of the errors it encountered.
+func TestBaseQueueMetrics(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	g, stopper := gossipForTest(t)
+	defer stopper.Stop()
+
+	repls := make([]*Replica, 3)
+	for i := range repls {
+		rangeID := roachpb.RangeID(i + 1)
+		repls[i] = &Replica{RangeID: rangeID}
+		if err := repls[i].setDesc(&roachpb.RangeDescriptor{RangeID: rangeID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	testQueue := &testQueueImpl{
+		shouldQueueFn: func(now roachpb.Timestamp, r *Replica) (bool, float64) {
+			return true, 1.0
+		},
+	}
+	bq := makeBaseQueue("test", testQueue, g, len(repls))
+	mc := hlc.NewManualClock(0)
+	clock := hlc.NewClock(mc.UnixNano)
+
+	for _, repl := range repls {
+		if err := bq.Add(repl, 1.0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if v := bq.metrics.pending.Value(); v != int64(len(repls)) {
+		t.Errorf("expected %d pending replicas, got %d", len(repls), v)
+	}
+	bq.DrainQueue(clock)
+	if v := bq.metrics.pending.Value(); v != 0 {
+		t.Errorf("expected no pending replicas, got %d", v)
+	}
+	if c := bq.metrics.successes.Count(); c != int64(len(repls)) {
+		t.Errorf("expected %d successes, got %d", len(repls), c)
+	}
+
+	for _, err := range []error{
+		roachpb.NewError(&roachpb.NotLeaderError{}),
+		&testError{},
+		util.Errorf("unstructured"),
+	} {
+		testQueue.err = err
+		if err := bq.Add(repls[0], 1.0); err != nil {
+			t.Fatal(err)
+		}
+		bq.DrainQueue(clock)
+	}
+	if c := bq.metrics.failures.Count(); c != 3 {
+		t.Errorf("expected 3 failures, got %d", c)
+	}
+	bq.metrics.mu.Lock()
+	for _, errType := range []string{"NotLeaderError", "purgatory", "other"} {
+		if c, ok := bq.metrics.mu.errors[errType]; !ok || c.Count() != 1 {
+			t.Errorf("expected one failure of type %s", errType)
+		}
+	}
+	bq.metrics.mu.Unlock()
+
+	if name := bq.metricsName(); name != "test" {
+		t.Errorf("expected the queue's metrics to be named test, got %s", name)
+	}
+	if name := (&baseQueue{name: "replica consistency checker"}).metricsName(); name != "replicaconsistencychecker" {
+		t.Errorf("expected spaces to be removed from the metrics name, got %s", name)
+	}
+}
@@ -13,6 +13,8 @@
 		RaftMessageRequest
 		RaftMessageResponse
 		ConfChangeContext
+		SnapshotChunk
+		SnapshotAck
 		StoreStatus
 */
 package storage
@@ -46,6 +48,13 @@ type RaftMessageRequest struct {
 	FromReplica cockroach_roachpb.ReplicaDescriptor              `protobuf:"bytes,2,opt,name=from_replica" json:"from_replica"`
 	ToReplica   cockroach_roachpb.ReplicaDescriptor              `protobuf:"bytes,3,opt,name=to_replica" json:"to_replica"`
 	Message     raftpb.Message                                   `protobuf:"bytes,4,opt,name=message" json:"message"`
+	// SnapshotChunk is set on requests carrying a piece of a snapshot which
+	// was too large to be sent in a single message. The first chunk carries
+	// the snapshot message itself, stripped of its data.
+	SnapshotChunk *SnapshotChunk `protobuf:"bytes,5,opt,name=snapshot_chunk" json:"snapshot_chunk,omitempty"`
+	// SnapshotAck is set on requests acknowledging snapshot chunks to the
+	// sender of the snapshot. These requests carry no raft message.
+	SnapshotAck *SnapshotAck `protobuf:"bytes,6,opt,name=snapshot_ack" json:"snapshot_ack,omitempty"`
 }
 
 func (m *RaftMessageRequest) Reset()         { *m = RaftMessageRequest{} }
@@ -75,10 +84,42 @@ func (m *ConfChangeContext) Reset()         { *m = ConfChangeContext{} }
 func (m *ConfChangeContext) String() string { return proto.CompactTextString(m) }
 func (*ConfChangeContext) ProtoMessage()    {}
 
+// SnapshotChunk is a piece of the data of a snapshot.
+type SnapshotChunk struct {
+	// SnapshotIndex is the raft index of the snapshot the chunk belongs to.
+	SnapshotIndex uint64 `protobuf:"varint,1,opt,name=snapshot_index" json:"snapshot_index"`
+	Index         int32  `protobuf:"varint,2,opt,name=index" json:"index"`
+	Data          []byte `protobuf:"bytes,3,opt,name=data" json:"data,omitempty"`
+	// Checksums holds the CRC32 checksum of every chunk of the snapshot. It
+	// is only set on the first chunk.
+	Checksums []uint32 `protobuf:"varint,4,rep,name=checksums" json:"checksums,omitempty"`
+}
+
+func (m *SnapshotChunk) Reset()         { *m = SnapshotChunk{} }
+func (m *SnapshotChunk) String() string { return proto.CompactTextString(m) }
+func (*SnapshotChunk) ProtoMessage()    {}
+
+// SnapshotAck acknowledges the receipt of all the chunks of a snapshot
+// before Next.
+type SnapshotAck struct {
+	SnapshotIndex uint64 `protobuf:"varint,1,opt,name=snapshot_index" json:"snapshot_index"`
+	Next          int32  `protobuf:"varint,2,opt,name=next" json:"next"`
+	// Resend is set when the recipient received a chunk out of order or
+	// which failed its checksum, and asks for the chunks to be sent again
+	// starting at Next.
+	Resend bool `protobuf:"varint,3,opt,name=resend" json:"resend"`
+}
+
+func (m *SnapshotAck) Reset()         { *m = SnapshotAck{} }
+func (m *SnapshotAck) String() string { return proto.CompactTextString(m) }
+func (*SnapshotAck) ProtoMessage()    {}
+
 func init() {
 	proto.RegisterType((*RaftMessageRequest)(nil), "cockroach.storage.RaftMessageRequest")
 	proto.RegisterType((*RaftMessageResponse)(nil), "cockroach.storage.RaftMessageResponse")
 	proto.RegisterType((*ConfChangeContext)(nil), "cockroach.storage.ConfChangeContext")
+	proto.RegisterType((*SnapshotChunk)(nil), "cockroach.storage.SnapshotChunk")
+	proto.RegisterType((*SnapshotAck)(nil), "cockroach.storage.SnapshotAck")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -224,6 +265,26 @@ func (m *RaftMessageRequest) MarshalTo(data []byte) (int, error) {
 		return 0, err
 	}
 	i += n3
+	if m.SnapshotChunk != nil {
+		data[i] = 0x2a
+		i++
+		i = encodeVarintRaft(data, i, uint64(m.SnapshotChunk.Size()))
+		n4, err := m.SnapshotChunk.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n4
+	}
+	if m.SnapshotAck != nil {
+		data[i] = 0x32
+		i++
+		i = encodeVarintRaft(data, i, uint64(m.SnapshotAck.Size()))
+		n5, err := m.SnapshotAck.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n5
+	}
 	return i, nil
 }
 
@@ -273,11 +334,80 @@ func (m *ConfChangeContext) MarshalTo(data []byte) (int, error) {
 	data[i] = 0x1a
 	i++
 	i = encodeVarintRaft(data, i, uint64(m.Replica.Size()))
-	n4, err := m.Replica.MarshalTo(data[i:])
+	n6, err := m.Replica.MarshalTo(data[i:])
 	if err != nil {
 		return 0, err
 	}
-	i += n4
+	i += n6
+	return i, nil
+}
+
+func (m *SnapshotChunk) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *SnapshotChunk) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	data[i] = 0x8
+	i++
+	i = encodeVarintRaft(data, i, uint64(m.SnapshotIndex))
+	data[i] = 0x10
+	i++
+	i = encodeVarintRaft(data, i, uint64(m.Index))
+	if m.Data != nil {
+		data[i] = 0x1a
+		i++
+		i = encodeVarintRaft(data, i, uint64(len(m.Data)))
+		i += copy(data[i:], m.Data)
+	}
+	if len(m.Checksums) > 0 {
+		for _, num := range m.Checksums {
+			data[i] = 0x20
+			i++
+			i = encodeVarintRaft(data, i, uint64(num))
+		}
+	}
+	return i, nil
+}
+
+func (m *SnapshotAck) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *SnapshotAck) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	data[i] = 0x8
+	i++
+	i = encodeVarintRaft(data, i, uint64(m.SnapshotIndex))
+	data[i] = 0x10
+	i++
+	i = encodeVarintRaft(data, i, uint64(m.Next))
+	data[i] = 0x18
+	i++
+	if m.Resend {
+		data[i] = 1
+	} else {
+		data[i] = 0
+	}
+	i++
 	return i, nil
 }
 
@@ -318,6 +448,14 @@ func (m *RaftMessageRequest) Size() (n int) {
 	n += 1 + l + sovRaft(uint64(l))
 	l = m.Message.Size()
 	n += 1 + l + sovRaft(uint64(l))
+	if m.SnapshotChunk != nil {
+		l = m.SnapshotChunk.Size()
+		n += 1 + l + sovRaft(uint64(l))
+	}
+	if m.SnapshotAck != nil {
+		l = m.SnapshotAck.Size()
+		n += 1 + l + sovRaft(uint64(l))
+	}
 	return n
 }
 
@@ -341,6 +479,32 @@ func (m *ConfChangeContext) Size() (n int) {
 	return n
 }
 
+func (m *SnapshotChunk) Size() (n int) {
+	var l int
+	_ = l
+	n += 1 + sovRaft(uint64(m.SnapshotIndex))
+	n += 1 + sovRaft(uint64(m.Index))
+	if m.Data != nil {
+		l = len(m.Data)
+		n += 1 + l + sovRaft(uint64(l))
+	}
+	if len(m.Checksums) > 0 {
+		for _, e := range m.Checksums {
+			n += 1 + sovRaft(uint64(e))
+		}
+	}
+	return n
+}
+
+func (m *SnapshotAck) Size() (n int) {
+	var l int
+	_ = l
+	n += 1 + sovRaft(uint64(m.SnapshotIndex))
+	n += 1 + sovRaft(uint64(m.Next))
+	n += 2
+	return n
+}
+
 func sovRaft(x uint64) (n int) {
 	for {
 		n++
@@ -492,6 +656,72 @@ func (m *RaftMessageRequest) Unmarshal(data []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SnapshotChunk", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaft
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.SnapshotChunk == nil {
+				m.SnapshotChunk = &SnapshotChunk{}
+			}
+			if err := m.SnapshotChunk.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SnapshotAck", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaft
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.SnapshotAck == nil {
+				m.SnapshotAck = &SnapshotAck{}
+			}
+			if err := m.SnapshotAck.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRaft(data[iNdEx:])
@@ -703,6 +933,253 @@ func (m *ConfChangeContext) Unmarshal(data []byte) error {
 	}
 	return nil
 }
+func (m *SnapshotChunk) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRaft
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SnapshotChunk: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SnapshotChunk: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SnapshotIndex", wireType)
+			}
+			m.SnapshotIndex = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.SnapshotIndex |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Index", wireType)
+			}
+			m.Index = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.Index |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthRaft
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Data = append(m.Data[:0], data[iNdEx:postIndex]...)
+			if m.Data == nil {
+				m.Data = []byte{}
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Checksums", wireType)
+			}
+			var v uint32
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				v |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Checksums = append(m.Checksums, v)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRaft(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthRaft
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SnapshotAck) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRaft
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SnapshotAck: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SnapshotAck: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SnapshotIndex", wireType)
+			}
+			m.SnapshotIndex = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.SnapshotIndex |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Next", wireType)
+			}
+			m.Next = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.Next |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Resend", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Resend = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRaft(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthRaft
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipRaft(data []byte) (n int, err error) {
 	l := len(data)
 	iNdEx := 0
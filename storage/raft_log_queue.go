@@ -134,7 +134,7 @@ func (rlq *raftLogQueue) process(now roachpb.Timestamp, r *Replica, _ *config.Sy
 		if log.V(1) {
 			log.Infof("truncating the raft log of range %d to %d", r.RangeID, oldestIndex)
 		}
-		b := &client.Batch{}
+		b := &client.Batch{AdmissionClass: roachpb.BACKGROUND}
 		b.InternalAddRequest(&roachpb.TruncateLogRequest{
 			Span:    roachpb.Span{Key: r.Desc().StartKey.AsRawKey()},
 			Index:   oldestIndex,
@@ -64,6 +64,9 @@ type RaftTransport struct {
 		sync.Mutex
 		handlers map[roachpb.StoreID]raftMessageHandler
 		queues   map[roachpb.NodeID]chan *RaftMessageRequest
+		// Chunked snapshots being sent and received by the transport.
+		outgoingSnapshots map[snapshotKey]*outgoingSnapshot
+		incomingSnapshots map[snapshotKey]*incomingSnapshot
 	}
 }
 
@@ -81,6 +84,8 @@ func NewRaftTransport(resolver NodeAddressResolver, grpcServer *grpc.Server, rpc
 	}
 	t.mu.handlers = make(map[roachpb.StoreID]raftMessageHandler)
 	t.mu.queues = make(map[roachpb.NodeID]chan *RaftMessageRequest)
+	t.mu.outgoingSnapshots = make(map[snapshotKey]*outgoingSnapshot)
+	t.mu.incomingSnapshots = make(map[snapshotKey]*incomingSnapshot)
 
 	if grpcServer != nil {
 		RegisterMultiRaftServer(grpcServer, t)
@@ -102,6 +107,30 @@ func (t *RaftTransport) RaftMessage(stream MultiRaft_RaftMessageServer) (err err
 						return err
 					}
 
+					// Snapshot chunks and their acknowledgments are handled
+					// by the transport. Only whole snapshots are passed on.
+					if req.SnapshotAck != nil {
+						for _, chunk := range t.handleSnapshotAck(req) {
+							if err := t.enqueue(chunk); err != nil {
+								log.Warning(err)
+								break
+							}
+						}
+						continue
+					}
+					if req.SnapshotChunk != nil {
+						ack, snapReq := t.handleSnapshotChunk(req)
+						if ack != nil {
+							if err := t.enqueue(ack); err != nil {
+								log.Warning(err)
+							}
+						}
+						if snapReq == nil {
+							continue
+						}
+						req = snapReq
+					}
+
 					t.mu.Lock()
 					handler, ok := t.mu.handlers[req.ToReplica.StoreID]
 					t.mu.Unlock()
@@ -172,7 +201,7 @@ func (t *RaftTransport) processQueue(nodeID roachpb.NodeID) {
 	if log.V(1) {
 		log.Infof("dialing node %d at %s", nodeID, addr)
 	}
-	conn, err := t.rpcContext.GRPCDial(addr.String())
+	conn, err := t.rpcContext.GRPCDialNode(addr.String(), nodeID)
 	if err != nil {
 		if log.V(1) {
 			log.Errorf("failed to dial: %s", err)
@@ -204,8 +233,12 @@ func (t *RaftTransport) processQueue(nodeID roachpb.NodeID) {
 
 	var raftIdleTimer util.Timer
 	defer raftIdleTimer.Stop()
+	raftIdleTimer.Reset(raftIdleTimeout)
+	// Snapshot chunks lost along with a previous stream, or which could not
+	// be queued, are sent again when the snapshot is found to be stalled.
+	snapshotTicker := time.NewTicker(snapshotResendInterval)
+	defer snapshotTicker.Stop()
 	for {
-		raftIdleTimer.Reset(raftIdleTimeout)
 		select {
 		case <-t.rpcContext.Stopper.ShouldStop():
 			return
@@ -224,17 +257,55 @@ func (t *RaftTransport) processQueue(nodeID roachpb.NodeID) {
 				}
 			}
 			return
+		case <-snapshotTicker.C:
+			reqs := t.stalledSnapshotChunks(nodeID)
+			for _, req := range reqs {
+				if err := stream.Send(req); err != nil {
+					log.Error(err)
+					return
+				}
+			}
+			if len(reqs) > 0 {
+				raftIdleTimer.Reset(raftIdleTimeout)
+			}
 		case req := <-ch:
 			if err := stream.Send(req); err != nil {
 				log.Error(err)
 				return
 			}
+			raftIdleTimer.Reset(raftIdleTimeout)
 		}
 	}
 }
 
-// Send a message to the recipient specified in the request.
+// Send a message to the recipient specified in the request. Snapshots which
+// are too large to be sent in a single message are streamed in chunks.
 func (t *RaftTransport) Send(req *RaftMessageRequest) error {
+	if isChunkedSnapshot(req) {
+		return t.sendSnapshot(req, nil)
+	}
+	return t.enqueue(req)
+}
+
+// SendSnapshot sends a snapshot to the recipient specified in the request
+// like Send, and calls done with its outcome once its last chunk was
+// acknowledged, or once it was abandoned. Snapshots small enough to be sent
+// in a single message are considered delivered once they are queued. done is
+// not called if an error is returned.
+func (t *RaftTransport) SendSnapshot(req *RaftMessageRequest, done func(error)) error {
+	if isChunkedSnapshot(req) {
+		return t.sendSnapshot(req, done)
+	}
+	if err := t.enqueue(req); err != nil {
+		return err
+	}
+	done(nil)
+	return nil
+}
+
+// enqueue queues the request on the queue of the recipient node, starting
+// the processing of the queue if necessary.
+func (t *RaftTransport) enqueue(req *RaftMessageRequest) error {
 	isRunning := true
 	t.mu.Lock()
 	ch, ok := t.mu.queues[req.ToReplica.NodeID]
@@ -0,0 +1,364 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package storage
+
+import (
+	"hash/crc32"
+	"time"
+
+	"github.com/coreos/etcd/raft/raftpb"
+
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/log"
+)
+
+// Snapshots which are too large to be sent in a single message are streamed
+// in chunks. The first chunk carries the snapshot message stripped of its
+// data, along with a manifest of the CRC32 checksums of all the chunks. The
+// recipient acknowledges every chunk it accepts, and asks for the chunks to
+// be sent again when one arrives out of order or fails its checksum. The
+// sender only keeps a window of unacknowledged chunks in flight, and resumes
+// stalled snapshots from the last acknowledged chunk, so that a snapshot
+// interrupted by a network failure does not have to be sent from scratch.
+const (
+	// snapshotChunkSize is the size of the chunks snapshots are split into.
+	// Snapshots no larger than that are sent in a single message.
+	snapshotChunkSize = 256 << 10
+	// snapshotChunkWindow is the number of chunks of a snapshot which are
+	// sent ahead of the last acknowledged one.
+	snapshotChunkWindow = 16
+	// snapshotResendInterval is the duration after which the unacknowledged
+	// chunks of a snapshot are sent again.
+	snapshotResendInterval = 2 * time.Second
+	// snapshotTimeout is the duration after which a snapshot whose transfer
+	// made no progress is abandoned.
+	snapshotTimeout = time.Minute
+)
+
+// snapshotKey identifies a chunked snapshot of a range being sent from one
+// store to another.
+type snapshotKey struct {
+	rangeID roachpb.RangeID
+	from    roachpb.StoreID
+	to      roachpb.StoreID
+}
+
+// outgoingSnapshot is the state of a chunked snapshot on its sender.
+type outgoingSnapshot struct {
+	// header is the request the snapshot was sent with, stripped of the
+	// snapshot data.
+	header    RaftMessageRequest
+	data      []byte
+	checksums []uint32
+	// acked is the number of chunks acknowledged by the recipient and sent
+	// the number of chunks handed to the transport.
+	acked, sent  int32
+	lastAck      time.Time
+	lastSent     time.Time
+	lastProgress time.Time
+	// done, if set, is called with the outcome of the snapshot once its last
+	// chunk is acknowledged or it is abandoned.
+	done func(error)
+}
+
+func newOutgoingSnapshot(req *RaftMessageRequest, done func(error), now time.Time) *outgoingSnapshot {
+	s := &outgoingSnapshot{
+		header:       *req,
+		data:         req.Message.Snapshot.Data,
+		lastProgress: now,
+		done:         done,
+	}
+	s.header.Message.Snapshot.Data = nil
+	for i := int32(0); int(i)*snapshotChunkSize < len(s.data); i++ {
+		s.checksums = append(s.checksums, crc32.ChecksumIEEE(s.chunkData(i)))
+	}
+	return s
+}
+
+func (s *outgoingSnapshot) index() uint64 {
+	return s.header.Message.Snapshot.Metadata.Index
+}
+
+func (s *outgoingSnapshot) chunkData(i int32) []byte {
+	start := int(i) * snapshotChunkSize
+	end := start + snapshotChunkSize
+	if end > len(s.data) {
+		end = len(s.data)
+	}
+	return s.data[start:end]
+}
+
+// chunk returns the request carrying the i-th chunk of the snapshot.
+func (s *outgoingSnapshot) chunk(i int32) *RaftMessageRequest {
+	req := &RaftMessageRequest{
+		GroupID:     s.header.GroupID,
+		FromReplica: s.header.FromReplica,
+		ToReplica:   s.header.ToReplica,
+		SnapshotChunk: &SnapshotChunk{
+			SnapshotIndex: s.index(),
+			Index:         i,
+			Data:          s.chunkData(i),
+		},
+	}
+	if i == 0 {
+		req.Message = s.header.Message
+		req.SnapshotChunk.Checksums = s.checksums
+	}
+	return req
+}
+
+// nextChunks returns the requests carrying the chunks which are to be sent
+// next, up to the window ahead of the last acknowledged chunk.
+func (s *outgoingSnapshot) nextChunks(now time.Time) []*RaftMessageRequest {
+	var reqs []*RaftMessageRequest
+	for ; s.sent < int32(len(s.checksums)) && s.sent < s.acked+snapshotChunkWindow; s.sent++ {
+		reqs = append(reqs, s.chunk(s.sent))
+	}
+	if len(reqs) > 0 {
+		s.lastSent = now
+	}
+	return reqs
+}
+
+// incomingSnapshot is the state of a chunked snapshot on its recipient.
+type incomingSnapshot struct {
+	snapshotIndex uint64
+	// header is the request carried by the first chunk. It is nil until the
+	// first chunk is received and once the snapshot is complete.
+	header    *RaftMessageRequest
+	checksums []uint32
+	data      []byte
+	// next is the index of the next chunk expected.
+	next int32
+	// resendRequested is the value of next at which chunks were last asked
+	// to be sent again, so that they are only asked for once.
+	resendRequested int32
+	lastProgress    time.Time
+}
+
+// isChunkedSnapshot returns whether the request carries a snapshot which is
+// too large to be sent in a single message.
+func isChunkedSnapshot(req *RaftMessageRequest) bool {
+	return req.Message.Type == raftpb.MsgSnap && len(req.Message.Snapshot.Data) > snapshotChunkSize
+}
+
+// sendSnapshot starts streaming the snapshot carried by the request in
+// chunks. If the same snapshot is already being streamed to the recipient,
+// it is resumed from the last acknowledged chunk.
+func (t *RaftTransport) sendSnapshot(req *RaftMessageRequest, done func(error)) error {
+	key := snapshotKey{
+		rangeID: req.GroupID,
+		from:    req.FromReplica.StoreID,
+		to:      req.ToReplica.StoreID,
+	}
+	now := time.Now()
+	var superseded func(error)
+	t.mu.Lock()
+	s, ok := t.mu.outgoingSnapshots[key]
+	if ok && s.index() == req.Message.Snapshot.Metadata.Index {
+		s.sent = s.acked
+		if done != nil {
+			s.done = done
+		}
+	} else {
+		if ok {
+			superseded = s.done
+		}
+		s = newOutgoingSnapshot(req, done, now)
+		t.mu.outgoingSnapshots[key] = s
+	}
+	reqs := s.nextChunks(now)
+	t.mu.Unlock()
+	if superseded != nil {
+		superseded(util.Errorf("snapshot of range %d to store %d superseded", key.rangeID, key.to))
+	}
+
+	for _, req := range reqs {
+		if err := t.enqueue(req); err != nil {
+			// The remaining chunks are sent again once the snapshot is
+			// found to be stalled, and its outcome is reported then.
+			log.Warningf("unable to send snapshot of range %d to store %d: %s", key.rangeID, key.to, err)
+			break
+		}
+	}
+	return nil
+}
+
+// stalledSnapshotChunks returns the chunks of the snapshots being sent to
+// the node which have not been acknowledged in a while, starting at the last
+// acknowledged chunk. Snapshots which made no progress for too long are
+// abandoned and reported as failed.
+func (t *RaftTransport) stalledSnapshotChunks(nodeID roachpb.NodeID) []*RaftMessageRequest {
+	now := time.Now()
+	var reqs []*RaftMessageRequest
+	var abandoned []func(error)
+	t.mu.Lock()
+	defer func() {
+		t.mu.Unlock()
+		for _, done := range abandoned {
+			done(util.Errorf("snapshot abandoned after %s without progress", snapshotTimeout))
+		}
+	}()
+	for key, s := range t.mu.outgoingSnapshots {
+		if s.header.ToReplica.NodeID != nodeID {
+			continue
+		}
+		if now.Sub(s.lastProgress) > snapshotTimeout {
+			log.Warningf("abandoning snapshot of range %d at index %d to store %d after %s without progress",
+				key.rangeID, s.index(), key.to, snapshotTimeout)
+			delete(t.mu.outgoingSnapshots, key)
+			if s.done != nil {
+				abandoned = append(abandoned, s.done)
+			}
+			continue
+		}
+		if now.Sub(s.lastSent) < snapshotResendInterval || now.Sub(s.lastAck) < snapshotResendInterval {
+			continue
+		}
+		if log.V(1) {
+			log.Infof("resuming snapshot of range %d at index %d to store %d at chunk %d of %d",
+				key.rangeID, s.index(), key.to, s.acked, len(s.checksums))
+		}
+		s.sent = s.acked
+		reqs = append(reqs, s.nextChunks(now)...)
+	}
+	return reqs
+}
+
+// handleSnapshotAck processes the acknowledgment of the chunks of a snapshot
+// sent by this transport and returns the chunks which are to be sent next.
+// The snapshot is reported as done once its last chunk is acknowledged.
+func (t *RaftTransport) handleSnapshotAck(req *RaftMessageRequest) []*RaftMessageRequest {
+	ack := req.SnapshotAck
+	key := snapshotKey{
+		rangeID: req.GroupID,
+		from:    req.ToReplica.StoreID,
+		to:      req.FromReplica.StoreID,
+	}
+	now := time.Now()
+	var finished func(error)
+	t.mu.Lock()
+	defer func() {
+		t.mu.Unlock()
+		if finished != nil {
+			finished(nil)
+		}
+	}()
+	s, ok := t.mu.outgoingSnapshots[key]
+	if !ok || s.index() != ack.SnapshotIndex {
+		return nil
+	}
+	if int(ack.Next) >= len(s.checksums) {
+		delete(t.mu.outgoingSnapshots, key)
+		finished = s.done
+		return nil
+	}
+	s.lastAck = now
+	if ack.Resend {
+		// The recipient is missing chunks, possibly all of them if it lost
+		// track of the snapshot.
+		s.acked, s.sent = ack.Next, ack.Next
+	} else if ack.Next > s.acked {
+		s.acked = ack.Next
+		s.lastProgress = now
+	}
+	if s.sent < s.acked {
+		s.sent = s.acked
+	}
+	return s.nextChunks(now)
+}
+
+// handleSnapshotChunk processes a chunk of a snapshot sent to this
+// transport. It returns the acknowledgment to send back, if any, and the
+// request carrying the whole snapshot once its last chunk is received.
+func (t *RaftTransport) handleSnapshotChunk(req *RaftMessageRequest) (*RaftMessageRequest, *RaftMessageRequest) {
+	chunk := req.SnapshotChunk
+	key := snapshotKey{
+		rangeID: req.GroupID,
+		from:    req.FromReplica.StoreID,
+		to:      req.ToReplica.StoreID,
+	}
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for k, s := range t.mu.incomingSnapshots {
+		if now.Sub(s.lastProgress) > snapshotTimeout {
+			delete(t.mu.incomingSnapshots, k)
+		}
+	}
+
+	s, ok := t.mu.incomingSnapshots[key]
+	if !ok || s.snapshotIndex != chunk.SnapshotIndex || (chunk.Index == 0 && s.next == 0) {
+		// Chunks of a snapshot whose first chunk was not received are only
+		// recorded, so that the first chunk is asked for once.
+		s = &incomingSnapshot{
+			snapshotIndex:   chunk.SnapshotIndex,
+			resendRequested: -1,
+			lastProgress:    now,
+		}
+		t.mu.incomingSnapshots[key] = s
+		if chunk.Index == 0 {
+			header := *req
+			header.SnapshotChunk = nil
+			s.header = &header
+			s.checksums = chunk.Checksums
+		}
+	}
+
+	makeAck := func(resend bool) *RaftMessageRequest {
+		return &RaftMessageRequest{
+			GroupID:     req.GroupID,
+			FromReplica: req.ToReplica,
+			ToReplica:   req.FromReplica,
+			SnapshotAck: &SnapshotAck{
+				SnapshotIndex: chunk.SnapshotIndex,
+				Next:          s.next,
+				Resend:        resend,
+			},
+		}
+	}
+
+	if chunk.Index < s.next {
+		// A chunk sent again after an acknowledgment was lost.
+		return makeAck(false), nil
+	}
+	if chunk.Index > s.next || s.header == nil ||
+		int(chunk.Index) >= len(s.checksums) || crc32.ChecksumIEEE(chunk.Data) != s.checksums[chunk.Index] {
+		if s.header != nil && chunk.Index == s.next {
+			log.Warningf("chunk %d of snapshot of range %d at index %d from store %d failed its checksum",
+				chunk.Index, key.rangeID, chunk.SnapshotIndex, key.from)
+		}
+		if s.resendRequested == s.next {
+			return nil, nil
+		}
+		s.resendRequested = s.next
+		return makeAck(true), nil
+	}
+
+	s.data = append(s.data, chunk.Data...)
+	s.next++
+	s.lastProgress = now
+	if int(s.next) < len(s.checksums) {
+		return makeAck(false), nil
+	}
+	// The snapshot is complete. Its state is kept without the data until it
+	// times out, so that chunks sent again are acknowledged.
+	snapReq := s.header
+	snapReq.Message.Snapshot.Data = s.data
+	s.header, s.data = nil, nil
+	return makeAck(false), snapReq
+}
@@ -0,0 +1,194 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package storage
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/raft/raftpb"
+
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+// TestSnapshotChunks verifies that the chunks of a snapshot which are lost,
+// corrupted or delivered out of order are sent again, and that a stalled
+// snapshot resumes from the last acknowledged chunk. The snapshot is only
+// reported as done once its last chunk is acknowledged.
+func TestSnapshotChunks(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	data := make([]byte, 5*snapshotChunkSize+100)
+	rand.Read(data)
+	req := &RaftMessageRequest{
+		GroupID:     1,
+		FromReplica: roachpb.ReplicaDescriptor{NodeID: 1, StoreID: 1, ReplicaID: 1},
+		ToReplica:   roachpb.ReplicaDescriptor{NodeID: 2, StoreID: 2, ReplicaID: 2},
+		Message: raftpb.Message{
+			Type: raftpb.MsgSnap,
+			Snapshot: raftpb.Snapshot{
+				Data:     data,
+				Metadata: raftpb.SnapshotMetadata{Index: 10, Term: 1},
+			},
+		},
+	}
+	if !isChunkedSnapshot(req) {
+		t.Fatal("expected the snapshot to be sent in chunks")
+	}
+
+	sender := NewDummyRaftTransport()
+	recipient := NewDummyRaftTransport()
+	key := snapshotKey{rangeID: 1, from: 1, to: 2}
+	var reported []error
+	s := newOutgoingSnapshot(req, func(err error) { reported = append(reported, err) }, time.Now())
+	sender.mu.outgoingSnapshots[key] = s
+	if len(s.checksums) != 6 {
+		t.Fatalf("expected 6 chunks, got %d", len(s.checksums))
+	}
+
+	// deliver passes the chunks to the recipient and its acknowledgments
+	// back to the sender until no more chunks are sent. The filter decides
+	// whether a chunk is delivered, possibly after altering it.
+	var snapReq *RaftMessageRequest
+	var delivered []int32
+	deliver := func(chunks []*RaftMessageRequest, filter func(*RaftMessageRequest) bool) {
+		for len(chunks) > 0 {
+			chunk := chunks[0]
+			chunks = chunks[1:]
+			if !filter(chunk) {
+				continue
+			}
+			delivered = append(delivered, chunk.SnapshotChunk.Index)
+			ack, done := recipient.handleSnapshotChunk(chunk)
+			if done != nil {
+				snapReq = done
+			}
+			if ack != nil {
+				chunks = append(chunks, sender.handleSnapshotAck(ack)...)
+			}
+		}
+	}
+
+	// Lose chunk 1 and corrupt chunk 2 the first time they are sent, then
+	// lose everything after chunk 3 as if the stream had failed.
+	sent := map[int32]int{}
+	deliver(s.nextChunks(time.Now()), func(chunk *RaftMessageRequest) bool {
+		i := chunk.SnapshotChunk.Index
+		sent[i]++
+		switch {
+		case i == 1 && sent[i] == 1:
+			return false
+		case i == 2 && sent[i] == 1:
+			corrupted := append([]byte(nil), chunk.SnapshotChunk.Data...)
+			corrupted[0]++
+			chunk.SnapshotChunk.Data = corrupted
+		case i > 3:
+			return false
+		}
+		return true
+	})
+	if snapReq != nil {
+		t.Fatal("unexpected complete snapshot")
+	}
+	if s.acked != 4 {
+		t.Fatalf("expected 4 acknowledged chunks, got %d", s.acked)
+	}
+	if len(reported) != 0 {
+		t.Fatalf("expected the interrupted snapshot not to be reported, got %v", reported)
+	}
+
+	// The stalled snapshot is resumed from the last acknowledged chunk.
+	sender.mu.Lock()
+	s.lastSent = s.lastSent.Add(-snapshotResendInterval)
+	s.lastAck = s.lastAck.Add(-snapshotResendInterval)
+	sender.mu.Unlock()
+	delivered = nil
+	deliver(sender.stalledSnapshotChunks(2), func(*RaftMessageRequest) bool { return true })
+	if expected := []int32{4, 5}; !equalInt32s(delivered, expected) {
+		t.Errorf("expected chunks %v to be resumed, got %v", expected, delivered)
+	}
+
+	if snapReq == nil {
+		t.Fatal("expected complete snapshot")
+	}
+	if !bytes.Equal(snapReq.Message.Snapshot.Data, data) {
+		t.Error("snapshot data does not match")
+	}
+	if snapReq.SnapshotChunk != nil || snapReq.Message.Snapshot.Metadata.Index != 10 {
+		t.Errorf("unexpected snapshot request %+v", snapReq)
+	}
+	if len(sender.mu.outgoingSnapshots) != 0 {
+		t.Errorf("expected the snapshot to be done, got %+v", sender.mu.outgoingSnapshots)
+	}
+	if len(reported) != 1 || reported[0] != nil {
+		t.Errorf("expected the snapshot to be reported once as delivered, got %v", reported)
+	}
+
+	// A chunk sent again once the snapshot is complete is acknowledged.
+	ack, done := recipient.handleSnapshotChunk(s.chunk(3))
+	if done != nil || ack == nil || ack.SnapshotAck.Next != 6 || ack.SnapshotAck.Resend {
+		t.Errorf("unexpected acknowledgment %+v", ack)
+	}
+}
+
+// TestSnapshotAbandoned verifies that a snapshot which makes no progress is
+// abandoned and reported as failed.
+func TestSnapshotAbandoned(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	req := &RaftMessageRequest{
+		GroupID:     1,
+		FromReplica: roachpb.ReplicaDescriptor{NodeID: 1, StoreID: 1, ReplicaID: 1},
+		ToReplica:   roachpb.ReplicaDescriptor{NodeID: 2, StoreID: 2, ReplicaID: 2},
+		Message: raftpb.Message{
+			Type: raftpb.MsgSnap,
+			Snapshot: raftpb.Snapshot{
+				Data:     make([]byte, 2*snapshotChunkSize),
+				Metadata: raftpb.SnapshotMetadata{Index: 10, Term: 1},
+			},
+		},
+	}
+	sender := NewDummyRaftTransport()
+	var reported []error
+	s := newOutgoingSnapshot(req, func(err error) { reported = append(reported, err) },
+		time.Now().Add(-snapshotTimeout-time.Second))
+	sender.mu.outgoingSnapshots[snapshotKey{rangeID: 1, from: 1, to: 2}] = s
+
+	if chunks := sender.stalledSnapshotChunks(2); len(chunks) != 0 {
+		t.Errorf("expected no chunks to be sent, got %d", len(chunks))
+	}
+	if len(sender.mu.outgoingSnapshots) != 0 {
+		t.Errorf("expected the snapshot to be abandoned")
+	}
+	if len(reported) != 1 || reported[0] == nil {
+		t.Errorf("expected the snapshot to be reported once as failed, got %v", reported)
+	}
+}
+
+func equalInt32s(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
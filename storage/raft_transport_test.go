@@ -17,6 +17,7 @@
 package storage_test
 
 import (
+	"bytes"
 	"math/rand"
 	"reflect"
 	"testing"
@@ -287,3 +288,72 @@ func TestInOrderDelivery(t *testing.T) {
 		}
 	}
 }
+
+// TestSendLargeSnapshot verifies that a snapshot too large to be sent in a
+// single message is delivered whole.
+func TestSendLargeSnapshot(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+	nodeRPCContext := rpc.NewContext(testutils.NewNodeTestBaseContext(), nil, stopper)
+	g := gossip.New(nodeRPCContext, nil, stopper)
+
+	grpcServer := rpc.NewServer(nodeRPCContext)
+	ln, err := util.ListenAndServeGRPC(stopper, grpcServer, util.TestAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodeID := roachpb.NodeID(2)
+	serverTransport := storage.NewRaftTransport(storage.GossipAddressResolver(g), grpcServer, nodeRPCContext)
+	serverChannel := newChannelServer(1, 0)
+	serverTransport.Listen(roachpb.StoreID(nodeID), serverChannel.RaftMessage)
+	addr := ln.Addr()
+	g.SetNodeID(nodeID)
+	if err := g.AddInfoProto(gossip.MakeNodeIDKey(nodeID),
+		&roachpb.NodeDescriptor{
+			Address: util.MakeUnresolvedAddr(addr.Network(), addr.String()),
+		},
+		time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	clientNodeID := roachpb.NodeID(1)
+	clientTransport := storage.NewRaftTransport(storage.GossipAddressResolver(g), nil, nodeRPCContext)
+
+	data := make([]byte, 3<<20)
+	rand.Read(data)
+	req := &storage.RaftMessageRequest{
+		GroupID: 1,
+		Message: raftpb.Message{
+			Type: raftpb.MsgSnap,
+			To:   uint64(nodeID),
+			From: uint64(clientNodeID),
+			Snapshot: raftpb.Snapshot{
+				Data:     data,
+				Metadata: raftpb.SnapshotMetadata{Index: 10, Term: 1},
+			},
+		},
+		ToReplica: roachpb.ReplicaDescriptor{
+			NodeID:    nodeID,
+			StoreID:   roachpb.StoreID(nodeID),
+			ReplicaID: roachpb.ReplicaID(nodeID),
+		},
+		FromReplica: roachpb.ReplicaDescriptor{
+			NodeID:    clientNodeID,
+			StoreID:   roachpb.StoreID(clientNodeID),
+			ReplicaID: roachpb.ReplicaID(clientNodeID),
+		},
+	}
+	if err := clientTransport.Send(req); err != nil {
+		t.Fatal(err)
+	}
+
+	received := <-serverChannel.ch
+	if received.Message.Type != raftpb.MsgSnap || received.Message.Snapshot.Metadata.Index != 10 {
+		t.Errorf("unexpected message %+v", received.Message)
+	}
+	if !bytes.Equal(received.Message.Snapshot.Data, data) {
+		t.Error("snapshot data does not match")
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/keys"
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/storage/engine"
+	"github.com/cockroachdb/cockroach/util"
+)
+
+// RemoveDeadReplicas rewrites the range descriptors stored on the supplied
+// engine for every range which has permanently lost a quorum of its
+// replicas. A range is considered to have lost quorum when a majority of
+// the voting replicas in its descriptor reside on one of deadStoreIDs. For each
+// such range of which storeID is still a member, the dead replicas are
+// removed from the descriptor so that the surviving replicas are able to
+// elect a leader again once the store is restarted. Ranges which still
+// have a quorum of live replicas are left untouched; the replicate queue
+// will repair them through the normal up-replication process.
+//
+// The meta1 and meta2 addressing records of the rewritten ranges are
+// updated as well where they are stored in a range of which storeID is a
+// member, and the stats of the ranges written to are kept up to date.
+//
+// The rewritten descriptors are returned. The engine is expected to be a
+// batch; callers which only want to know which ranges would be affected
+// can simply discard it without committing.
+//
+// This is UNSAFE: any writes which were committed by the lost replicas
+// but had not yet been replicated to the survivors are lost, and the
+// resulting cluster may contain inconsistent data. It exists only to
+// recover a cluster from a disaster which cannot be repaired otherwise,
+// and must only be run against the engines of stopped stores.
+func RemoveDeadReplicas(eng engine.Engine, storeID roachpb.StoreID,
+	deadStoreIDs []roachpb.StoreID, now roachpb.Timestamp) ([]roachpb.RangeDescriptor, error) {
+	if len(deadStoreIDs) == 0 {
+		return nil, util.Errorf("no dead stores specified")
+	}
+	dead := make(map[roachpb.StoreID]struct{}, len(deadStoreIDs))
+	for _, id := range deadStoreIDs {
+		if id == storeID {
+			return nil, util.Errorf("store %d cannot remove itself from its ranges", storeID)
+		}
+		dead[id] = struct{}{}
+	}
+
+	// localDescs holds the descriptors of all ranges of which storeID is a
+	// member, and newDescs the rewritten ones.
+	var localDescs, newDescs []roachpb.RangeDescriptor
+	start := keys.RangeDescriptorKey(roachpb.RKeyMin)
+	end := keys.RangeDescriptorKey(roachpb.RKeyMax)
+	_, err := engine.MVCCIterate(context.Background(), eng, start, end, now, false /* !consistent */, nil, /* txn */
+		false /* !reverse */, func(kv roachpb.KeyValue) (bool, error) {
+			// Only consider range metadata entries; ignore others.
+			_, suffix, _, err := keys.DecodeRangeKey(kv.Key)
+			if err != nil {
+				return false, err
+			}
+			if !bytes.Equal(suffix, keys.LocalRangeDescriptorSuffix) {
+				return false, nil
+			}
+			var desc roachpb.RangeDescriptor
+			if err := kv.Value.GetProto(&desc); err != nil {
+				return false, err
+			}
+			if _, repDesc := desc.FindReplica(storeID); repDesc == nil {
+				return false, nil
+			}
+			localDescs = append(localDescs, desc)
+
+			// Learners are not part of the raft group, so only the voting
+			// replicas count towards its quorum.
+			var live []roachpb.ReplicaDescriptor
+			for _, rep := range desc.Replicas {
+				if _, ok := dead[rep.StoreID]; !ok {
+					live = append(live, rep)
+				}
+			}
+			liveVoters := countVoters(live)
+			if quorum := computeQuorum(countVoters(desc.Replicas)); liveVoters >= quorum {
+				return false, nil
+			}
+			if liveVoters == 0 {
+				return false, util.Errorf("range %d has no live voting replicas left", desc.RangeID)
+			}
+			desc.Replicas = live
+			newDescs = append(newDescs, desc)
+			return false, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	// stats holds the stats of the ranges written to, which are written
+	// back once all records are.
+	stats := map[roachpb.RangeID]*engine.MVCCStats{}
+	rangeStats := func(rangeID roachpb.RangeID) (*engine.MVCCStats, error) {
+		if ms, ok := stats[rangeID]; ok {
+			return ms, nil
+		}
+		ms := &engine.MVCCStats{}
+		if err := engine.MVCCGetRangeStats(eng, rangeID, ms); err != nil {
+			return nil, err
+		}
+		stats[rangeID] = ms
+		return ms, nil
+	}
+	// put writes the record to the local range which contains its key, if
+	// any.
+	put := func(key roachpb.Key, desc *roachpb.RangeDescriptor) error {
+		addr := keys.Addr(key)
+		for i := range localDescs {
+			if !localDescs[i].ContainsKey(addr) {
+				continue
+			}
+			ms, err := rangeStats(localDescs[i].RangeID)
+			if err != nil {
+				return err
+			}
+			return engine.MVCCPutProto(eng, ms, key, now, nil /* txn */, desc)
+		}
+		return nil
+	}
+
+	for i := range newDescs {
+		desc := &newDescs[i]
+		if err := desc.Validate(); err != nil {
+			return nil, err
+		}
+		if err := put(keys.RangeDescriptorKey(desc.StartKey), desc); err != nil {
+			return nil, err
+		}
+		// Rewrite the addressing records like updateRangeAddressing does,
+		// so that the dead replicas aren't looked up anymore.
+		var metaKeys []roachpb.Key
+		if err := rangeAddressing(nil, desc, func(_ *client.Batch, key roachpb.Key, _ *roachpb.RangeDescriptor) {
+			metaKeys = append(metaKeys, key)
+		}); err != nil {
+			return nil, err
+		}
+		for _, key := range metaKeys {
+			if err := put(key, desc); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for rangeID, ms := range stats {
+		if err := engine.MVCCSetRangeStats(eng, rangeID, ms); err != nil {
+			return nil, err
+		}
+	}
+	return newDescs, nil
+}
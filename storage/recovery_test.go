@@ -0,0 +1,112 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/keys"
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/storage/engine"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+	"github.com/cockroachdb/cockroach/util/stop"
+)
+
+func TestRemoveDeadReplicas(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+	eng := engine.NewInMem(roachpb.Attributes{}, 1<<20, stopper)
+
+	replicas := func(storeIDs ...roachpb.StoreID) []roachpb.ReplicaDescriptor {
+		var reps []roachpb.ReplicaDescriptor
+		for i, id := range storeIDs {
+			reps = append(reps, roachpb.ReplicaDescriptor{
+				NodeID:    roachpb.NodeID(id),
+				StoreID:   id,
+				ReplicaID: roachpb.ReplicaID(i + 1),
+			})
+		}
+		return reps
+	}
+	descs := []roachpb.RangeDescriptor{
+		// Lost quorum: only store 1 survives.
+		{RangeID: 1, StartKey: roachpb.RKeyMin, EndKey: roachpb.RKey("b"), Replicas: replicas(1, 2, 3)},
+		// Healthy: stores 1 and 4 survive.
+		{RangeID: 2, StartKey: roachpb.RKey("b"), EndKey: roachpb.RKey("c"), Replicas: replicas(1, 2, 4)},
+		// Not a member; must be ignored.
+		{RangeID: 3, StartKey: roachpb.RKey("c"), EndKey: roachpb.RKeyMax, Replicas: replicas(2, 3, 4)},
+	}
+	ts := roachpb.Timestamp{WallTime: 1}
+	for i := range descs {
+		descs[i].NextReplicaID = 4
+		if err := engine.MVCCPutProto(eng, nil, keys.RangeDescriptorKey(descs[i].StartKey), ts, nil, &descs[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := roachpb.Timestamp{WallTime: 2}
+	dead := []roachpb.StoreID{2, 3}
+
+	// Discarding the batch leaves the engine untouched.
+	batch := eng.NewBatch()
+	newDescs, err := RemoveDeadReplicas(batch, 1, dead, now)
+	batch.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(newDescs) != 1 || newDescs[0].RangeID != 1 {
+		t.Fatalf("expected only range 1 to be rewritten; got %+v", newDescs)
+	}
+	var desc roachpb.RangeDescriptor
+	if _, err := engine.MVCCGetProto(eng, keys.RangeDescriptorKey(roachpb.RKeyMin), now, true, nil, &desc); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(desc.Replicas, replicas(1, 2, 3)) {
+		t.Fatalf("expected descriptor to be unchanged; got %+v", desc)
+	}
+
+	if _, err := RemoveDeadReplicas(eng, 1, dead, now); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.MVCCGetProto(eng, keys.RangeDescriptorKey(roachpb.RKeyMin), now, true, nil, &desc); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(desc.Replicas, replicas(1)) {
+		t.Fatalf("expected only store 1 to remain; got %+v", desc)
+	}
+	// Range 1 holds its own meta1 and meta2 addressing records, and its
+	// stats account for the rewritten records.
+	for _, key := range []roachpb.Key{keys.RangeMetaKey(roachpb.RKey("b")), keys.Meta1KeyMax} {
+		var meta roachpb.RangeDescriptor
+		if ok, err := engine.MVCCGetProto(eng, key, now, true, nil, &meta); err != nil {
+			t.Fatal(err)
+		} else if !ok || !reflect.DeepEqual(meta.Replicas, replicas(1)) {
+			t.Fatalf("expected the addressing record %s to list only store 1; got %+v", key, meta)
+		}
+	}
+	var ms engine.MVCCStats
+	if err := engine.MVCCGetRangeStats(eng, 1, &ms); err != nil {
+		t.Fatal(err)
+	}
+	if ms.SysCount != 2 {
+		t.Fatalf("expected the stats to count the 2 new addressing records; got %+v", ms)
+	}
+
+	if _, err := RemoveDeadReplicas(eng, 1, []roachpb.StoreID{1}, now); err == nil {
+		t.Fatal("expected error when removing the local store")
+	}
+}
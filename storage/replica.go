@@ -70,6 +70,10 @@ const (
 
 	// configGossipTTL is the time-to-live for configuration maps.
 	configGossipTTL = 0 // does not expire
+	// leaderLeaseGossipTTL is the time-to-live of a gossiped leader lease.
+	// Leases are only gossiped when acquired by a new holder, which keeps
+	// them through extensions, so the info outlives the lease itself.
+	leaderLeaseGossipTTL = time.Minute
 	// configGossipInterval is the interval at which range leaders gossip
 	// their config maps. Even if config maps do not expire, we still
 	// need a periodic gossip to safeguard against failure of a leader
@@ -131,6 +135,16 @@ func usesTimestampCache(r roachpb.Request) bool {
 	return tsCacheMethods[m]
 }
 
+// isMerge returns true if the batch commits a range merge.
+func isMerge(ba roachpb.BatchRequest) bool {
+	args, ok := ba.GetArg(roachpb.EndTransaction)
+	if !ok {
+		return false
+	}
+	etArgs := args.(*roachpb.EndTransactionRequest)
+	return etArgs.Commit && etArgs.InternalCommitTrigger.GetMergeTrigger() != nil
+}
+
 // A pendingCmd holds a done channel for a command sent to Raft. Once
 // committed to the Raft log, the command is executed and the result returned
 // via the done channel.
@@ -164,6 +178,7 @@ type Replica struct {
 	RangeID      roachpb.RangeID // Should only be set by the constructor.
 	store        *Store
 	stats        *rangeStats    // Range statistics
+	load         *replicaLoad   // Request rate and sampled request keys
 	systemDBHash []byte         // sha1 hash of the system config @ last gossip
 	sequence     *SequenceCache // Provides txn replay protection
 
@@ -191,6 +206,13 @@ type Replica struct {
 		proposeRaftCommandFn func(cmdIDKey, *pendingCmd) error
 		checksums            map[uuid.UUID]replicaChecksum // computed checksum at a snapshot UUID.
 		checksumNotify       map[uuid.UUID]chan []byte     // notify of computed checksum.
+		// closedTimestamp is the timestamp at or below which no further
+		// writes are applied. Consistent reads at or below it may be served
+		// without the leader lease.
+		closedTimestamp roachpb.Timestamp
+		// learners tracks the catch-up of the learner replicas of the range
+		// to which this replica sent a snapshot.
+		learners map[roachpb.ReplicaID]*learnerProgress
 	}
 }
 
@@ -204,6 +226,7 @@ func NewReplica(desc *roachpb.RangeDescriptor, store *Store, replicaID roachpb.R
 	r := &Replica{
 		store:    store,
 		sequence: NewSequenceCache(desc.RangeID),
+		load:     newReplicaLoad(store.Clock().PhysicalNow),
 		RangeID:  desc.RangeID,
 	}
 
@@ -230,6 +253,7 @@ func (r *Replica) newReplicaInner(desc *roachpb.RangeDescriptor, clock *hlc.Cloc
 	r.mu.pendingCmds = map[cmdIDKey]*pendingCmd{}
 	r.mu.checksums = map[uuid.UUID]replicaChecksum{}
 	r.mu.checksumNotify = map[uuid.UUID]chan []byte{}
+	r.mu.learners = map[roachpb.ReplicaID]*learnerProgress{}
 	r.setDescWithoutProcessUpdateLocked(desc)
 
 	var err error
@@ -248,6 +272,11 @@ func (r *Replica) newReplicaInner(desc *roachpb.RangeDescriptor, clock *hlc.Cloc
 		return err
 	}
 
+	r.mu.closedTimestamp, err = loadClosedTimestamp(r.store.Engine(), desc.RangeID)
+	if err != nil {
+		return err
+	}
+
 	if r.isInitializedLocked() && replicaID != 0 {
 		return util.Errorf("replicaID must be 0 when creating an initialized replica")
 	}
@@ -388,6 +417,77 @@ func loadLeaderLease(eng engine.Engine, rangeID roachpb.RangeID) (*roachpb.Lease
 	return lease, nil
 }
 
+func loadClosedTimestamp(eng engine.Engine, rangeID roachpb.RangeID) (roachpb.Timestamp, error) {
+	var ts roachpb.Timestamp
+	_, err := engine.MVCCGetProto(eng, keys.RangeClosedTimestampKey(rangeID), roachpb.ZeroTimestamp, true, nil, &ts)
+	return ts, err
+}
+
+func setClosedTimestamp(eng engine.Engine, ms *engine.MVCCStats, rangeID roachpb.RangeID, ts roachpb.Timestamp) error {
+	return engine.MVCCPutProto(eng, ms, keys.RangeClosedTimestampKey(rangeID), roachpb.ZeroTimestamp, nil, &ts)
+}
+
+// getClosedTimestamp returns the closed timestamp.
+func (r *Replica) getClosedTimestamp() roachpb.Timestamp {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.mu.closedTimestamp
+}
+
+// writesPastClosedTimestamp returns whether the batch contains writes which
+// must be moved past the closed timestamp, which are those which use the
+// timestamp cache and transaction commits.
+func (r *Replica) writesPastClosedTimestamp(ba roachpb.BatchRequest) bool {
+	for _, union := range ba.Requests {
+		switch args := union.GetInner().(type) {
+		case *roachpb.EndTransactionRequest:
+			if args.Commit {
+				return true
+			}
+		default:
+			if usesTimestampCache(args) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// maybeCloseTimestamp proposes a no-op command, which closes the timestamps
+// lagging the present by the target like every command, if the replica
+// holds the leader lease and its closed timestamp lags the present by more
+// than twice the target. Closing timestamps otherwise relies on commands,
+// which idle ranges don't receive.
+func (r *Replica) maybeCloseTimestamp(target time.Duration) {
+	now := r.store.Clock().Now()
+	if lease := r.getLeaderLease(); !lease.OwnedBy(r.store.StoreID()) || !lease.Covers(now) {
+		return
+	}
+	if !r.getClosedTimestamp().Less(now.Add(-2*target.Nanoseconds(), 0)) {
+		return
+	}
+	ba := roachpb.BatchRequest{}
+	ba.Timestamp = now
+	ba.RangeID = r.RangeID
+	ba.Add(&roachpb.NoopRequest{})
+	// The command is applied asynchronously; a failure to close the
+	// timestamps is retried by the next call.
+	if _, err := r.proposeRaftCommand(r.context(), ba); err != nil && log.V(1) {
+		log.Infoc(r.context(), "unable to close timestamps: %s", err)
+	}
+}
+
+// canServeFollowerRead returns true if the batch may be served without the
+// leader lease. This is the case for non-transactional batches at or below
+// the closed timestamp, since writes at these timestamps are forwarded past
+// the closed timestamp when applied.
+func (r *Replica) canServeFollowerRead(ba roachpb.BatchRequest) bool {
+	if ba.Txn != nil || ba.Timestamp.Equal(roachpb.ZeroTimestamp) {
+		return false
+	}
+	return !r.getClosedTimestamp().Less(ba.Timestamp)
+}
+
 // getLeaderLease returns the current leader lease.
 func (r *Replica) getLeaderLease() *roachpb.Lease {
 	r.mu.Lock()
@@ -436,6 +536,10 @@ func (r *Replica) requestLeaderLease(timestamp roachpb.Timestamp) <-chan *roachp
 			if replica == nil {
 				return roachpb.NewError(roachpb.NewRangeNotFoundError(r.RangeID))
 			}
+			// Learners may not have caught up with the range yet.
+			if replica.Learner {
+				return roachpb.NewError(r.newNotLeaderError(nil, r.store.StoreID()))
+			}
 			args := &roachpb.LeaderLeaseRequest{
 				Span: roachpb.Span{
 					Key: desc.StartKey.AsRawKey(),
@@ -693,6 +797,14 @@ func (r *Replica) RaftStatus() *raft.Status {
 	return r.mu.raftGroup.Status()
 }
 
+// hasPendingCmds returns true if the replica has commands which are proposed
+// but not yet applied.
+func (r *Replica) hasPendingCmds() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.mu.pendingCmds) > 0
+}
+
 // Send adds a command for execution on this range. The command's
 // affected keys are verified to be contained within the range and the
 // range's leadership is confirmed. The command is then dispatched
@@ -704,6 +816,9 @@ func (r *Replica) Send(ctx context.Context, ba roachpb.BatchRequest) (*roachpb.B
 	if err := r.checkBatchRequest(ba); err != nil {
 		return nil, roachpb.NewError(err)
 	}
+	if !ba.IsAdmin() && len(ba.Requests) > 0 {
+		r.load.record(ba.Requests[0].GetInner().Header().Key)
+	}
 
 	sp, cleanupSp := tracing.SpanFromContext(opReplica, r.store.Tracer(), ctx)
 	defer cleanupSp()
@@ -895,8 +1010,9 @@ func (r *Replica) addReadOnlyCmd(ctx context.Context, ba roachpb.BatchRequest) (
 	sp, cleanupSp := tracing.SpanFromContext(opReplica, r.store.Tracer(), ctx)
 	defer cleanupSp()
 
-	// If the read is consistent, the read requires the leader lease.
-	if ba.ReadConsistency != roachpb.INCONSISTENT {
+	// If the read is consistent, the read requires the leader lease unless
+	// its timestamp is closed.
+	if ba.ReadConsistency != roachpb.INCONSISTENT && !r.canServeFollowerRead(ba) {
 		if pErr = r.redirectOnOrAcquireLeaderLease(sp, ctx); pErr != nil {
 			return nil, pErr
 		}
@@ -922,7 +1038,7 @@ func (r *Replica) addReadOnlyCmd(ctx context.Context, ba roachpb.BatchRequest) (
 	// that holding readMu throughout is important to avoid reads from the
 	// "wrong" key range being served after the range has been split.
 	var intents []intentsWithArg
-	br, intents, pErr = r.executeBatch(r.store.Engine(), nil, ba)
+	br, intents, pErr = r.executeBatch(ctx, r.store.Engine(), nil, ba)
 
 	if pErr == nil && ba.Txn != nil {
 		// Checking the sequence cache on reads makes sure that when our
@@ -1097,6 +1213,12 @@ func (r *Replica) proposeRaftCommand(ctx context.Context, ba roachpb.BatchReques
 			Cmd:           ba,
 		},
 	}
+	if target := r.store.ctx.ClosedTimestampTarget; target > 0 {
+		// Close the timestamps lagging the present by the target. The lag
+		// leaves room for the timestamps of writes in flight, which would
+		// otherwise be forwarded when applied.
+		pendingCmd.raftCmd.ClosedTimestamp = r.store.Clock().Now().Add(-target.Nanoseconds(), 0)
+	}
 
 	if _, ok := r.mu.pendingCmds[idKey]; ok {
 		log.Fatalf("pending command already exists for %s", idKey)
@@ -1132,9 +1254,11 @@ func (r *Replica) proposePendingCmdLocked(idKey cmdIDKey, p *pendingCmd) error {
 		if !ok {
 			continue
 		}
-		if crt := etr.InternalCommitTrigger.GetChangeReplicasTrigger(); crt != nil {
+		if crt := etr.InternalCommitTrigger.GetChangeReplicasTrigger(); crt != nil && !crt.Replica.Learner {
 			// EndTransactionRequest with a ChangeReplicasTrigger is special because raft
 			// needs to understand it; it cannot simply be an opaque command.
+			// Learners are not part of the raft group, so adding or removing
+			// them is.
 			log.Infof("raft: proposing %s %v for range %d", crt.ChangeType, crt.Replica, p.raftCmd.RangeID)
 
 			ctx := ConfChangeContext{
@@ -1314,28 +1438,46 @@ func (r *Replica) sendRaftMessage(msg raftpb.Message) {
 		log.Warningf("failed to lookup sender replica %d in group %s: %s", msg.From, groupID, fromErr)
 		return
 	}
-	err := r.store.ctx.Transport.Send(&RaftMessageRequest{
+	req := &RaftMessageRequest{
 		GroupID:     groupID,
 		ToReplica:   toReplica,
 		FromReplica: fromReplica,
 		Message:     msg,
-	})
-	snapStatus := raft.SnapshotFinish
+	}
+	var err error
+	if msg.Type == raftpb.MsgSnap {
+		// The outcome of a snapshot is reported once the recipient has
+		// acknowledged all of it, or once it was abandoned, so that raft
+		// sends it again if it was interrupted.
+		err = r.store.ctx.Transport.SendSnapshot(req, func(err error) {
+			r.reportSnapshotStatus(msg.To, err)
+		})
+	} else {
+		err = r.store.ctx.Transport.Send(req)
+	}
 	if err != nil {
 		log.Warningf("group %s on store %s failed to send message to %s: %s", groupID,
 			r.store.StoreID(), toReplica.StoreID, err)
 		r.mu.Lock()
 		r.mu.raftGroup.ReportUnreachable(msg.To)
 		r.mu.Unlock()
-		snapStatus = raft.SnapshotFailure
+		if msg.Type == raftpb.MsgSnap {
+			r.reportSnapshotStatus(msg.To, err)
+		}
 	}
-	if msg.Type == raftpb.MsgSnap {
-		// TODO(bdarnell): add an ack for snapshots and don't report status until
-		// ack, error, or timeout.
-		r.mu.Lock()
-		r.mu.raftGroup.ReportSnapshot(msg.To, snapStatus)
-		r.mu.Unlock()
+}
+
+// reportSnapshotStatus reports the outcome of a snapshot sent to the given
+// replica to the raft group.
+func (r *Replica) reportSnapshotStatus(to uint64, err error) {
+	snapStatus := raft.SnapshotFinish
+	if err != nil {
+		snapStatus = raft.SnapshotFailure
 	}
+	r.mu.Lock()
+	r.mu.raftGroup.ReportSnapshot(to, snapStatus)
+	r.mu.Unlock()
+	r.store.enqueueRaftUpdateCheck(r.RangeID)
 }
 
 // processRaftCommand processes a raft command by unpacking the command
@@ -1368,7 +1510,9 @@ func (r *Replica) processRaftCommand(idKey cmdIDKey, index uint64, raftCmd roach
 	// applyRaftCommand will return "expected" errors, but may also indicate
 	// replica corruption (as of now, signaled by a replicaCorruptionError).
 	// We feed its return through maybeSetCorrupt to act when that happens.
-	br, err := r.applyRaftCommand(ctx, index, raftCmd.OriginReplica, raftCmd.Cmd)
+	start := time.Now()
+	br, err := r.applyRaftCommand(ctx, index, raftCmd.OriginReplica, raftCmd.Cmd, raftCmd.ClosedTimestamp)
+	r.store.metrics.raftApplyLatency.RecordValue(time.Since(start).Nanoseconds())
 	err = r.maybeSetCorrupt(err)
 
 	if cmd != nil {
@@ -1381,11 +1525,12 @@ func (r *Replica) processRaftCommand(idKey cmdIDKey, index uint64, raftCmd roach
 }
 
 // applyRaftCommand applies a raft command from the replicated log to the
-// underlying state machine (i.e. the engine).
+// underlying state machine (i.e. the engine). The closed timestamp is
+// advanced to the one carried by the command, if that is later.
 // When certain critical operations fail, a replicaCorruptionError may be
 // returned and must be handled by the caller.
 func (r *Replica) applyRaftCommand(ctx context.Context, index uint64, originReplica roachpb.ReplicaDescriptor,
-	ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+	ba roachpb.BatchRequest, closedTS roachpb.Timestamp) (*roachpb.BatchResponse, *roachpb.Error) {
 	if index <= 0 {
 		log.Fatalc(ctx, "raft command index is <= 0")
 	}
@@ -1394,17 +1539,35 @@ func (r *Replica) applyRaftCommand(ctx context.Context, index uint64, originRepl
 	// to update anything or run the command. Simply return a corruption error.
 	r.mu.Lock()
 	oldIndex := r.mu.appliedIndex
+	oldClosedTS := r.mu.closedTimestamp
 	r.mu.Unlock()
 	if oldIndex >= index {
 		return nil, roachpb.NewError(newReplicaCorruptionError(util.Errorf("applied index moved backwards: %d >= %d", oldIndex, index)))
 	}
+	closedTS.Forward(oldClosedTS)
 
 	// Call the helper, which returns a batch containing data written
 	// during command execution and any associated error.
 	ms := engine.MVCCStats{}
-	batch, br, intents, rErr := r.applyRaftCommandInBatch(ctx, index, originReplica, ba, &ms)
+	batch, br, intents, rErr := r.applyRaftCommandInBatch(ctx, index, originReplica, ba, closedTS, &ms)
 	defer batch.Close()
 
+	// A merge carries over the closed timestamp of the subsumed range (see
+	// mergeTrigger), which may be later than ours.
+	persistedClosedTS := oldClosedTS
+	if rErr == nil && isMerge(ba) {
+		var err error
+		if persistedClosedTS, err = loadClosedTimestamp(batch, r.RangeID); err != nil {
+			log.Fatalc(ctx, "loading closed timestamp from a batch should never fail: %s", err)
+		}
+		closedTS.Forward(persistedClosedTS)
+	}
+	if !closedTS.Equal(persistedClosedTS) {
+		if err := setClosedTimestamp(batch, &ms, r.RangeID, closedTS); err != nil {
+			log.Fatalc(ctx, "setting closed timestamp in a batch should never fail: %s", err)
+		}
+	}
+
 	// Advance the last applied index and commit the batch.
 	if err := setAppliedIndex(batch, &ms, r.RangeID, index); err != nil {
 		log.Fatalc(ctx, "setting applied index in a batch should never fail: %s", err)
@@ -1425,6 +1588,7 @@ func (r *Replica) applyRaftCommand(ctx context.Context, index uint64, originRepl
 		// Update cached appliedIndex if we were able to set the applied index
 		// on disk.
 		r.mu.appliedIndex = index
+		r.mu.closedTimestamp = closedTS
 		// Invalidate the cache and let raftTruncatedStateLocked() read the
 		// value the next time it's required.
 		if _, ok := ba.GetArg(roachpb.TruncateLog); ok {
@@ -1450,10 +1614,11 @@ func (r *Replica) applyRaftCommand(ctx context.Context, index uint64, originRepl
 }
 
 // applyRaftCommandInBatch executes the command in a batch engine and
-// returns the batch containing the results. The caller is responsible
-// for committing the batch, even on error.
+// returns the batch containing the results. Writes at or below the closed
+// timestamp are forwarded past it. The caller is responsible for
+// committing the batch, even on error.
 func (r *Replica) applyRaftCommandInBatch(ctx context.Context, index uint64, originReplica roachpb.ReplicaDescriptor,
-	ba roachpb.BatchRequest, ms *engine.MVCCStats) (engine.Engine, *roachpb.BatchResponse, []intentsWithArg, *roachpb.Error) {
+	ba roachpb.BatchRequest, closedTS roachpb.Timestamp, ms *engine.MVCCStats) (engine.Engine, *roachpb.BatchResponse, []intentsWithArg, *roachpb.Error) {
 	// Create a new batch for the command to ensure all or nothing semantics.
 	btch := r.store.Engine().NewBatch()
 
@@ -1497,10 +1662,28 @@ func (r *Replica) applyRaftCommandInBatch(ctx context.Context, index uint64, ori
 		}
 	}
 
+	// Reads at or below the closed timestamp may have been served without
+	// updating the timestamp cache, so writes must be moved past it just
+	// like past the reads recorded in the timestamp cache. This depends
+	// only on replicated state and so is done identically on all replicas.
+	// The transaction's timestamp is forwarded as well, so that a commit at
+	// or below the closed timestamp is forced to retry.
+	if r.writesPastClosedTimestamp(ba) {
+		if !closedTS.Less(ba.Timestamp) {
+			ba.Timestamp = closedTS.Next()
+		}
+		if ba.Txn != nil && !closedTS.Less(ba.Txn.Timestamp) {
+			txn := ba.Txn.Clone()
+			txn.Timestamp = closedTS.Next()
+			ba.Txn = &txn
+		}
+	}
+
 	// Execute the commands. If this returns without an error, the batch must
 	// be committed (EndTransaction with a CommitTrigger may unlock
-	// readOnlyCmdMu via a batch.Defer).
-	br, intents, err := r.executeBatch(btch, ms, ba)
+	// readOnlyCmdMu via a batch.Defer). The commands are not cancellable, as
+	// they must be applied identically on all replicas.
+	br, intents, err := r.executeBatch(context.Background(), btch, ms, ba)
 
 	// Regardless of error, add result to the sequence cache if this is
 	// a write method. This must be done as part of the execution of
@@ -1586,7 +1769,16 @@ type intentsWithArg struct {
 	intents []roachpb.Intent
 }
 
-func (r *Replica) executeBatch(batch engine.Engine, ms *engine.MVCCStats, ba roachpb.BatchRequest) (*roachpb.BatchResponse, []intentsWithArg, *roachpb.Error) {
+func (r *Replica) executeBatch(ctx context.Context, batch engine.Engine, ms *engine.MVCCStats, ba roachpb.BatchRequest) (*roachpb.BatchResponse, []intentsWithArg, *roachpb.Error) {
+	if ba.AdmissionClass == roachpb.FOREGROUND {
+		// Only the time spent evaluating the batch reflects the load of the
+		// store; waiting on the command queue or on conflicting intents
+		// doesn't.
+		defer func(start time.Time) {
+			r.store.load.recordLatency(time.Since(start))
+		}(time.Now())
+	}
+
 	br := &roachpb.BatchResponse{}
 	var intents []intentsWithArg
 	// If transactional, we use ba.Txn for each individual command and
@@ -1618,6 +1810,9 @@ func (r *Replica) executeBatch(batch engine.Engine, ms *engine.MVCCStats, ba roa
 		// remaining results we can return.
 		remScanResults = ba.Header.MaxScanResults
 	}
+	// remScanBytes is the remaining size of the results which Scan and
+	// ReverseScan requests can return if the batch has a TargetBytes limit.
+	remScanBytes := ba.Header.TargetBytes
 
 	// TODO(tschottdorf): provisionals ahead. This loop needs to execute each
 	// command and propagate txn and timestamp to the next (and, eventually,
@@ -1643,8 +1838,9 @@ func (r *Replica) executeBatch(batch engine.Engine, ms *engine.MVCCStats, ba roa
 		if fiddleWithTimestamps && args.Method() != roachpb.PushTxn {
 			header.Timestamp = ba.Timestamp.Add(0, int32(index))
 		}
+		header.TargetBytes = remScanBytes
 
-		reply, curIntents, pErr := r.executeCmd(batch, ms, header, remScanResults, args)
+		reply, curIntents, pErr := r.executeCmd(ctx, batch, ms, header, remScanResults, args)
 
 		// Collect intents skipped over the course of execution.
 		if len(curIntents) > 0 {
@@ -1666,6 +1862,25 @@ func (r *Replica) executeBatch(batch engine.Engine, ms *engine.MVCCStats, ba roa
 				remScanResults -= retResults
 			}
 		}
+		if remScanBytes > 0 {
+			if sReply, ok := reply.(roachpb.Sizable); ok {
+				remScanBytes -= sReply.ByteSize()
+				if remScanBytes <= 0 {
+					// The target size has been reached; the remaining scans
+					// in the batch must not return any results.
+					remScanResults = 0
+				}
+			}
+		}
+
+		// A transaction which commits in the batch which began it, without
+		// intents left to resolve on other ranges, commits in one phase.
+		if etReply, ok := reply.(*roachpb.EndTransactionResponse); ok && len(curIntents) == 0 &&
+			etReply.Txn != nil && etReply.Txn.Status == roachpb.COMMITTED {
+			if _, ok := ba.GetArg(roachpb.BeginTransaction); ok {
+				etReply.OnePhaseCommit = true
+			}
+		}
 
 		// Add the response to the batch, updating the timestamp.
 		reply.Header().Timestamp.Forward(header.Timestamp)
@@ -1828,6 +2043,20 @@ func (r *Replica) maybeGossipSystemConfig() {
 	r.systemDBHash = hash
 }
 
+// gossipLeaderLease gossips a leader lease newly acquired by this replica,
+// so that other nodes update their leader caches without first sending
+// requests to the previous holder. It is called by the new holder only,
+// once it has applied the lease.
+func (r *Replica) gossipLeaderLease(lease roachpb.Lease) {
+	// Leases applied when replaying the Raft log may have expired long ago.
+	if r.store.Gossip() == nil || !lease.Covers(r.store.Clock().Now()) {
+		return
+	}
+	if err := r.store.Gossip().AddInfoProto(gossip.MakeLeaderLeaseKey(r.RangeID), &lease, leaderLeaseGossipTTL); err != nil {
+		log.Warningc(r.context(), "failed to gossip leader lease: %s", err)
+	}
+}
+
 func (r *Replica) handleSkippedIntents(intents []intentsWithArg) {
 	if len(intents) == 0 {
 		return
@@ -2056,7 +2285,7 @@ func (r *Replica) loadSystemConfigSpan() ([]roachpb.KeyValue, []byte, error) {
 	ba.ReadConsistency = roachpb.INCONSISTENT
 	ba.Timestamp = r.store.Clock().Now()
 	ba.Add(&roachpb.ScanRequest{Span: keys.SystemConfigSpan})
-	br, intents, pErr := r.executeBatch(r.store.Engine(), nil, ba)
+	br, intents, pErr := r.executeBatch(r.context(), r.store.Engine(), nil, ba)
 	if pErr != nil {
 		return nil, nil, pErr.GoError()
 	}
@@ -24,9 +24,13 @@ import (
 	"crypto/sha512"
 	"encoding/binary"
 	"fmt"
+	"io/ioutil"
 	"math"
 
 	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"golang.org/x/net/context"
@@ -47,7 +51,7 @@ import (
 // execution.  If an error is returned, any returned intents should still be resolved.
 // remScanResults is the number of scan results remaining for this batch (MaxInt64 for no
 // limit).
-func (r *Replica) executeCmd(batch engine.Engine, ms *engine.MVCCStats, h roachpb.Header, remScanResults int64,
+func (r *Replica) executeCmd(ctx context.Context, batch engine.Engine, ms *engine.MVCCStats, h roachpb.Header, remScanResults int64,
 	args roachpb.Request) (roachpb.Response, []roachpb.Intent, *roachpb.Error) {
 	ts := h.Timestamp
 
@@ -103,13 +107,25 @@ func (r *Replica) executeCmd(batch engine.Engine, ms *engine.MVCCStats, h roachp
 		var resp roachpb.DeleteRangeResponse
 		resp, err = r.DeleteRange(batch, ms, h, *tArgs)
 		reply = &resp
+	case *roachpb.ClearRangeRequest:
+		var resp roachpb.ClearRangeResponse
+		resp, err = r.ClearRange(ctx, batch, ms, h, *tArgs)
+		reply = &resp
+	case *roachpb.RecomputeStatsRequest:
+		var resp roachpb.RecomputeStatsResponse
+		resp, err = r.RecomputeStats(batch, ms, h, *tArgs)
+		reply = &resp
 	case *roachpb.ScanRequest:
 		var resp roachpb.ScanResponse
-		resp, intents, err = r.Scan(batch, h, remScanResults, *tArgs)
+		resp, intents, err = r.Scan(ctx, batch, h, remScanResults, *tArgs)
 		reply = &resp
 	case *roachpb.ReverseScanRequest:
 		var resp roachpb.ReverseScanResponse
-		resp, intents, err = r.ReverseScan(batch, h, remScanResults, *tArgs)
+		resp, intents, err = r.ReverseScan(ctx, batch, h, remScanResults, *tArgs)
+		reply = &resp
+	case *roachpb.ExportRequest:
+		var resp roachpb.ExportResponse
+		resp, intents, err = r.Export(ctx, batch, h, *tArgs)
 		reply = &resp
 	case *roachpb.BeginTransactionRequest:
 		var resp roachpb.BeginTransactionResponse
@@ -149,12 +165,16 @@ func (r *Replica) executeCmd(batch engine.Engine, ms *engine.MVCCStats, h roachp
 		reply = &resp
 	case *roachpb.TruncateLogRequest:
 		var resp roachpb.TruncateLogResponse
-		resp, err = r.TruncateLog(batch, ms, h, *tArgs)
+		resp, err = r.TruncateLog(ctx, batch, ms, h, *tArgs)
 		reply = &resp
 	case *roachpb.LeaderLeaseRequest:
 		var resp roachpb.LeaderLeaseResponse
 		resp, err = r.LeaderLease(batch, ms, h, *tArgs)
 		reply = &resp
+	case *roachpb.LeaseInfoRequest:
+		var resp roachpb.LeaseInfoResponse
+		resp, err = r.LeaseInfo(batch, h, *tArgs)
+		reply = &resp
 	case *roachpb.ComputeChecksumRequest:
 		var resp roachpb.ComputeChecksumResponse
 		resp, err = r.ComputeChecksum(batch, ms, h, *tArgs)
@@ -235,6 +255,70 @@ func (r *Replica) DeleteRange(batch engine.Engine, ms *engine.MVCCStats, h roach
 	return reply, err
 }
 
+// clearRangeChunkSize is the number of keys ClearRange collects from the
+// batch at a time before clearing them.
+var clearRangeChunkSize = 1000
+
+// ClearRange removes all values in the specified span, including all of
+// their versions and any intents. Unlike DeleteRange, no tombstones are
+// written, so the data is gone immediately instead of after the GC TTL.
+// The MVCC stats of the span are subtracted from those of the range.
+func (r *Replica) ClearRange(ctx context.Context, batch engine.Engine, ms *engine.MVCCStats, h roachpb.Header, args roachpb.ClearRangeRequest) (roachpb.ClearRangeResponse, error) {
+	var reply roachpb.ClearRangeResponse
+	start := engine.MakeMVCCMetadataKey(args.Key)
+	end := engine.MakeMVCCMetadataKey(args.EndKey)
+
+	iter := batch.NewIterator(nil)
+	spanMs, err := iter.ComputeStats(start, end, h.Timestamp.WallTime)
+	iter.Close()
+	if err != nil {
+		return reply, err
+	}
+	// Clear the keys directly in the batch, so that they are removed
+	// atomically with the other effects of the command. The keys are
+	// cleared in chunks, each of which is collected first to avoid mutating
+	// the batch while iterating over it.
+	clearKeys := make([]engine.MVCCKey, 0, clearRangeChunkSize)
+	for {
+		clearKeys = clearKeys[:0]
+		if err := batch.Iterate(ctx, start, end, func(kv engine.MVCCKeyValue) (bool, error) {
+			clearKeys = append(clearKeys, kv.Key)
+			return len(clearKeys) == clearRangeChunkSize, nil
+		}); err != nil {
+			return reply, err
+		}
+		for _, key := range clearKeys {
+			if err := batch.Clear(key); err != nil {
+				return reply, err
+			}
+		}
+		if len(clearKeys) < clearRangeChunkSize {
+			break
+		}
+		start = clearKeys[len(clearKeys)-1].Next()
+	}
+	ms.Subtract(spanMs)
+	return reply, nil
+}
+
+// RecomputeStats repairs the MVCC stats of the range by adding the delta
+// between the incrementally maintained stats and a recomputation over all of
+// its data, which the lease holder computed once in checkStats, to the stats
+// of the command.
+func (r *Replica) RecomputeStats(batch engine.Engine, ms *engine.MVCCStats, h roachpb.Header, args roachpb.RecomputeStatsRequest) (roachpb.RecomputeStatsResponse, error) {
+	var reply roachpb.RecomputeStatsResponse
+	if len(args.Delta) == 0 {
+		return reply, util.Errorf("missing MVCC stats delta")
+	}
+	var delta engine.MVCCStats
+	if err := delta.Unmarshal(args.Delta); err != nil {
+		return reply, err
+	}
+	log.Warningf("%s: repairing MVCC stats which drifted by %+v", r, delta)
+	ms.Add(delta)
+	return reply, nil
+}
+
 // scanMaxResultsValue returns the max results value to pass to a scan or reverse scan request (0
 // for no limit).
 //    remScanResults is the number of remaining results for this batch (MaxInt64 for no
@@ -253,36 +337,175 @@ func scanMaxResultsValue(remScanResults int64, scanMaxResults int64) int64 {
 	return remScanResults
 }
 
+// scanLimitReached returns whether a scan which returned the given rows
+// stopped because it reached either its maximum number of results or its
+// target size (0 for no limit).
+func scanLimitReached(rows []roachpb.KeyValue, maxResults, targetBytes int64) bool {
+	if maxResults > 0 && int64(len(rows)) == maxResults {
+		return true
+	}
+	if targetBytes > 0 {
+		var size int64
+		for _, kv := range rows {
+			size += int64(len(kv.Key) + len(kv.Value.RawBytes))
+		}
+		return size >= targetBytes
+	}
+	return false
+}
+
 // Scan scans the key range specified by start key through end key in ascending order up to some
 // maximum number of results. remScanResults stores the number of scan results remaining for this
-// batch (MaxInt64 for no limit).
-func (r *Replica) Scan(batch engine.Engine, h roachpb.Header, remScanResults int64,
+// batch (MaxInt64 for no limit), and h.TargetBytes the remaining size of the results (0 for no
+// limit). If a limit stops the scan early, the response's ResumeSpan is set to the part of the
+// key range which was not scanned.
+func (r *Replica) Scan(ctx context.Context, batch engine.Engine, h roachpb.Header, remScanResults int64,
 	args roachpb.ScanRequest) (roachpb.ScanResponse, []roachpb.Intent, error) {
+	var reply roachpb.ScanResponse
 	if remScanResults == 0 {
 		// We can't return any more results; skip the scan
-		return roachpb.ScanResponse{}, nil, nil
+		reply.ResumeSpan = &roachpb.Span{Key: args.Key, EndKey: args.EndKey}
+		return reply, nil, nil
 	}
 	maxResults := scanMaxResultsValue(remScanResults, args.MaxResults)
 
-	rows, intents, err := engine.MVCCScan(batch, args.Key, args.EndKey, maxResults, h.Timestamp,
-		h.ReadConsistency == roachpb.CONSISTENT, h.Txn)
-	return roachpb.ScanResponse{Rows: rows}, intents, err
+	rows, intents, err := engine.MVCCScanWithTargetBytes(ctx, batch, args.Key, args.EndKey, maxResults,
+		h.TargetBytes, h.Timestamp, h.ReadConsistency == roachpb.CONSISTENT, h.Txn)
+	reply.Rows = rows
+	if err == nil && scanLimitReached(rows, maxResults, h.TargetBytes) {
+		reply.ResumeSpan = &roachpb.Span{Key: rows[len(rows)-1].Key.Next(), EndKey: args.EndKey}
+	}
+	return reply, intents, err
 }
 
 // ReverseScan scans the key range specified by start key through end key in descending order up to
 // some maximum number of results. remScanResults stores the number of scan results remaining for
-// this batch (MaxInt64 for no limit).
-func (r *Replica) ReverseScan(batch engine.Engine, h roachpb.Header, remScanResults int64,
+// this batch (MaxInt64 for no limit), and h.TargetBytes the remaining size of the results (0 for
+// no limit). If a limit stops the scan early, the response's ResumeSpan is set to the part of the
+// key range which was not scanned.
+func (r *Replica) ReverseScan(ctx context.Context, batch engine.Engine, h roachpb.Header, remScanResults int64,
 	args roachpb.ReverseScanRequest) (roachpb.ReverseScanResponse, []roachpb.Intent, error) {
+	var reply roachpb.ReverseScanResponse
 	if remScanResults == 0 {
 		// We can't return any more results; skip the scan
-		return roachpb.ReverseScanResponse{}, nil, nil
+		reply.ResumeSpan = &roachpb.Span{Key: args.Key, EndKey: args.EndKey}
+		return reply, nil, nil
 	}
 	maxResults := scanMaxResultsValue(remScanResults, args.MaxResults)
 
-	rows, intents, err := engine.MVCCReverseScan(batch, args.Key, args.EndKey, maxResults,
-		h.Timestamp, h.ReadConsistency == roachpb.CONSISTENT, h.Txn)
-	return roachpb.ReverseScanResponse{Rows: rows}, intents, err
+	rows, intents, err := engine.MVCCReverseScanWithTargetBytes(ctx, batch, args.Key, args.EndKey,
+		maxResults, h.TargetBytes, h.Timestamp, h.ReadConsistency == roachpb.CONSISTENT, h.Txn)
+	reply.Rows = rows
+	if err == nil && scanLimitReached(rows, maxResults, h.TargetBytes) {
+		reply.ResumeSpan = &roachpb.Span{Key: args.Key, EndKey: rows[len(rows)-1].Key}
+	}
+	return reply, intents, err
+}
+
+// maxInlineExportSize is the largest SSTable an Export request returns in
+// its response. Larger exports must name a directory to write to.
+var maxInlineExportSize int64 = 64 << 20
+
+// exportDir resolves the directory named by an Export request to a
+// directory under the store's export root, rejecting absolute paths and
+// paths which would escape the root.
+func (r *Replica) exportDir(dir string) (string, error) {
+	root := r.store.ctx.ExportDir
+	if root == "" {
+		return "", util.Errorf("exports to a directory are disabled on store %d", r.store.StoreID())
+	}
+	if filepath.IsAbs(dir) {
+		return "", util.Errorf("export directory %q must be relative", dir)
+	}
+	dir = filepath.Clean(dir)
+	if dir == ".." || strings.HasPrefix(dir, ".."+string(filepath.Separator)) {
+		return "", util.Errorf("export directory %q is outside of the export root", dir)
+	}
+	dir = filepath.Join(root, dir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// Export writes the latest values of the keys in the specified span as of
+// the timestamp of the batch to an SSTable. If args.Dir is set, the file is
+// left in that directory below the store's export root and its path
+// returned; otherwise its contents are returned, up to maxInlineExportSize.
+// No file is produced if the span contains no values. Export is an internal
+// method, so only callers holding the node certificate may issue it.
+func (r *Replica) Export(ctx context.Context, batch engine.Engine, h roachpb.Header,
+	args roachpb.ExportRequest) (roachpb.ExportResponse, []roachpb.Intent, error) {
+	var reply roachpb.ExportResponse
+
+	var dir string
+	if args.Dir != "" {
+		var err error
+		if dir, err = r.exportDir(args.Dir); err != nil {
+			return reply, nil, err
+		}
+	} else {
+		tempDir, err := ioutil.TempDir("", "export")
+		if err != nil {
+			return reply, nil, err
+		}
+		defer func() {
+			if err := os.RemoveAll(tempDir); err != nil {
+				log.Warningf("could not remove export directory %s: %s", tempDir, err)
+			}
+		}()
+		dir = tempDir
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%d-%d.%d.sst",
+		r.RangeID, h.Timestamp.WallTime, h.Timestamp.Logical))
+
+	var sst *engine.RocksDBSstFileWriter
+	defer func() {
+		if sst != nil {
+			sst.Close()
+		}
+	}()
+	intents, err := engine.MVCCIterate(ctx, batch, args.Key, args.EndKey, h.Timestamp,
+		h.ReadConsistency == roachpb.CONSISTENT, h.Txn, false /* !reverse */, func(kv roachpb.KeyValue) (bool, error) {
+			if sst == nil {
+				w, err := engine.MakeRocksDBSstFileWriter(path)
+				if err != nil {
+					return true, err
+				}
+				sst = &w
+			}
+			return false, sst.Add(engine.MVCCKeyValue{
+				Key:   engine.MVCCKey{Key: kv.Key, Timestamp: kv.Value.Timestamp},
+				Value: kv.Value.RawBytes,
+			})
+		})
+	if err != nil || sst == nil {
+		return reply, intents, err
+	}
+	if err := sst.Finish(); err != nil {
+		return reply, intents, err
+	}
+
+	file := roachpb.ExportedFile{Span: args.Span}
+	if args.Dir != "" {
+		file.Path = path
+	} else {
+		// The writer streams the table to disk as keys are added; only
+		// inline exports are read back into memory, so bound their size.
+		info, err := os.Stat(path)
+		if err != nil {
+			return reply, intents, err
+		}
+		if info.Size() > maxInlineExportSize {
+			return reply, intents, util.Errorf("export of %s is %d bytes, more than the %d which may be "+
+				"returned inline; specify a directory", args.Span, info.Size(), maxInlineExportSize)
+		}
+		if file.SST, err = ioutil.ReadFile(path); err != nil {
+			return reply, intents, err
+		}
+	}
+	reply.Files = append(reply.Files, file)
+	return reply, intents, nil
 }
 
 func verifyTransaction(h roachpb.Header, args roachpb.Request) error {
@@ -437,6 +660,18 @@ func (r *Replica) EndTransaction(batch engine.Engine, ms *engine.MVCCStats, h ro
 		desc = &mergeTrigger.UpdatedDesc
 	}
 
+	// If this is a split whose proposer computed the stats of its first half,
+	// the stats of the intents resolved there are tracked separately as they
+	// are not included yet.
+	var splitTrigger *roachpb.SplitTrigger
+	var splitLeftMs engine.MVCCStats
+	if reply.Txn.Status == roachpb.COMMITTED {
+		if splitTrigger = args.InternalCommitTrigger.GetSplitTrigger(); splitTrigger != nil &&
+			len(splitTrigger.LeftStats) == 0 {
+			splitTrigger = nil
+		}
+	}
+
 	iterAndBuf := engine.GetIterAndBuf(batch)
 	defer iterAndBuf.Cleanup()
 
@@ -459,6 +694,9 @@ func (r *Replica) EndTransaction(batch engine.Engine, ms *engine.MVCCStats, h ro
 					// merge trigger.
 					resolveMs = nil
 				}
+				if splitTrigger != nil && containsKey(splitTrigger.UpdatedDesc, span.Key) {
+					resolveMs = &splitLeftMs
+				}
 				return engine.MVCCResolveWriteIntentUsingIter(batch, iterAndBuf, resolveMs, intent)
 			}
 			// For intent ranges, cut into parts inside and outside our key
@@ -470,12 +708,32 @@ func (r *Replica) EndTransaction(batch engine.Engine, ms *engine.MVCCStats, h ro
 				outIntent.Span = span
 				externalIntents = append(externalIntents, outIntent)
 			}
-			if inSpan != nil {
-				intent.Span = *inSpan
-				_, err := engine.MVCCResolveWriteIntentRangeUsingIter(batch, iterAndBuf, ms, intent, 0)
-				return err
+			if inSpan == nil {
+				return nil
 			}
-			return nil
+			if splitTrigger != nil {
+				// Cut the part inside our key range into the parts on
+				// either side of the split.
+				leftSpan, rightSpans := intersectSpan(*inSpan, splitTrigger.UpdatedDesc)
+				if leftSpan != nil {
+					leftIntent := intent
+					leftIntent.Span = *leftSpan
+					if _, err := engine.MVCCResolveWriteIntentRangeUsingIter(batch, iterAndBuf, &splitLeftMs, leftIntent, 0); err != nil {
+						return err
+					}
+				}
+				for _, span := range rightSpans {
+					rightIntent := intent
+					rightIntent.Span = span
+					if _, err := engine.MVCCResolveWriteIntentRangeUsingIter(batch, iterAndBuf, ms, rightIntent, 0); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			intent.Span = *inSpan
+			_, err := engine.MVCCResolveWriteIntentRangeUsingIter(batch, iterAndBuf, ms, intent, 0)
+			return err
 		}(); err != nil {
 			// TODO(tschottdorf): any legitimate reason for this to happen?
 			// Figure that out and if not, should still be ReplicaCorruption
@@ -483,6 +741,9 @@ func (r *Replica) EndTransaction(batch engine.Engine, ms *engine.MVCCStats, h ro
 			panic(fmt.Sprintf("error resolving intent at %s on end transaction [%s]: %s", span, reply.Txn.Status, err))
 		}
 	}
+	if splitTrigger != nil {
+		ms.Add(splitLeftMs)
+	}
 
 	// Persist the transaction record with updated status (& possibly timestamp).
 	// If we've already resolved all intents locally, we actually delete the
@@ -519,7 +780,7 @@ func (r *Replica) EndTransaction(batch engine.Engine, ms *engine.MVCCStats, h ro
 
 		if err := func() error {
 			if ct.GetSplitTrigger() != nil {
-				if err := r.splitTrigger(batch, ms, ct.SplitTrigger); err != nil {
+				if err := r.splitTrigger(batch, ms, splitLeftMs, ct.SplitTrigger); err != nil {
 					return err
 				}
 				*ms = engine.MVCCStats{} // clear stats, as split recomputed.
@@ -1159,7 +1420,7 @@ func (r *Replica) Merge(batch engine.Engine, ms *engine.MVCCStats, h roachpb.Hea
 // TruncateLog discards a prefix of the raft log. Truncating part of a log that
 // has already been truncated has no effect. If this range is not the one
 // specified within the request body, the request will also be ignored.
-func (r *Replica) TruncateLog(batch engine.Engine, ms *engine.MVCCStats, h roachpb.Header, args roachpb.TruncateLogRequest) (roachpb.TruncateLogResponse, error) {
+func (r *Replica) TruncateLog(ctx context.Context, batch engine.Engine, ms *engine.MVCCStats, h roachpb.Header, args roachpb.TruncateLogRequest) (roachpb.TruncateLogResponse, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	var reply roachpb.TruncateLogResponse
@@ -1194,7 +1455,7 @@ func (r *Replica) TruncateLog(batch engine.Engine, ms *engine.MVCCStats, h roach
 	}
 	start := keys.RaftLogKey(r.RangeID, 0)
 	end := keys.RaftLogKey(r.RangeID, args.Index)
-	if err = batch.Iterate(engine.MakeMVCCMetadataKey(start), engine.MakeMVCCMetadataKey(end),
+	if err = batch.Iterate(ctx, engine.MakeMVCCMetadataKey(start), engine.MakeMVCCMetadataKey(end),
 		func(kv engine.MVCCKeyValue) (bool, error) {
 			return false, batch.Clear(kv.Key)
 		}); err != nil {
@@ -1207,6 +1468,16 @@ func (r *Replica) TruncateLog(batch engine.Engine, ms *engine.MVCCStats, h roach
 	return reply, engine.MVCCPutProto(batch, ms, keys.RaftTruncatedStateKey(r.RangeID), roachpb.ZeroTimestamp, nil, &tState)
 }
 
+// LeaseInfo returns the leader lease of this range. As a consistent read, it
+// is served by the lease holder.
+func (r *Replica) LeaseInfo(batch engine.Engine, h roachpb.Header, args roachpb.LeaseInfoRequest) (roachpb.LeaseInfoResponse, error) {
+	var reply roachpb.LeaseInfoResponse
+	if lease := r.getLeaderLease(); lease != nil {
+		reply.Lease = *lease
+	}
+	return reply, nil
+}
+
 // LeaderLease sets the leader lease for this range. The command fails
 // only if the desired start timestamp collides with a previous lease.
 // Otherwise, the start timestamp is wound back to right after the expiration
@@ -1290,10 +1561,15 @@ func (r *Replica) LeaderLease(batch engine.Engine, ms *engine.MVCCStats, h roach
 	// clock offset to account for any difference in clocks
 	// between the expiration (set by a remote node) and this
 	// node.
-	if r.mu.leaderLease.Replica.StoreID == r.store.StoreID() &&
+	if r.mu.leaderLease.OwnedBy(r.store.StoreID()) &&
 		prevLease.Replica.StoreID != r.mu.leaderLease.Replica.StoreID {
 		r.mu.tsCache.SetLowWater(prevLease.Expiration.Add(int64(r.store.Clock().MaxOffset()), 0))
 		log.Infof("range %d: new leader lease %s", r.RangeID, args.Lease)
+		// Only the new holder gossips the lease, once it has been applied.
+		lease := args.Lease
+		batch.Defer(func() {
+			r.gossipLeaderLease(lease)
+		})
 	}
 
 	return reply, nil
@@ -1363,6 +1639,43 @@ func (r *Replica) CheckConsistency(args roachpb.CheckConsistencyRequest, desc *r
 	return roachpb.CheckConsistencyResponse{}, nil
 }
 
+// checkStats compares the MVCC stats of the range to a recomputation on a
+// snapshot of its data. If they have drifted, a RecomputeStats command
+// carrying the difference is sent to repair them, so that the data is only
+// iterated over by this replica.
+func (r *Replica) checkStats() error {
+	desc := r.Desc()
+	snap := r.store.NewSnapshot()
+	defer snap.Close()
+
+	var storedMs engine.MVCCStats
+	if err := engine.MVCCGetRangeStats(snap, desc.RangeID, &storedMs); err != nil {
+		return err
+	}
+	actualMs, err := ComputeStatsForRange(desc, snap, r.store.Clock().PhysicalNow())
+	if err != nil {
+		return err
+	}
+	delta, drifted := mvccStatsDelta(storedMs, actualMs)
+	if !drifted {
+		return nil
+	}
+	log.Warningf("%s: MVCC stats drifted by %+v from recomputation", r, delta)
+	deltaBytes, err := delta.Marshal()
+	if err != nil {
+		return err
+	}
+
+	var ba roachpb.BatchRequest
+	ba.RangeID = desc.RangeID
+	ba.Add(&roachpb.RecomputeStatsRequest{
+		Span:  roachpb.Span{Key: desc.StartKey.AsRawKey()},
+		Delta: deltaBytes,
+	})
+	_, pErr := r.Send(r.context(), ba)
+	return pErr.GoError()
+}
+
 const (
 	replicaChecksumVersion    = 0
 	replicaChecksumGCInterval = time.Hour
@@ -1645,8 +1958,20 @@ func (r *Replica) AdminSplit(ctx context.Context, args roachpb.AdminSplitRequest
 		if pErr := InsertRange(txn, b, newDesc.StartKey); pErr != nil {
 			return pErr
 		}
+		if err := txn.Run(b); err != nil {
+			return err
+		}
+		// Now that the transaction has made all of its writes, compute the
+		// stats of the first half of the split so that the replicas applying
+		// it don't have to iterate over its data.
+		leftStats, origStats, err := r.splitStats(&updatedDesc)
+		if err != nil {
+			return roachpb.NewError(err)
+		}
+		sp.LogEvent("computed stats for old range")
 		// End the transaction manually, instead of letting RunTransaction
 		// loop do it, in order to provide a split trigger.
+		b = &client.Batch{}
 		b.InternalAddRequest(&roachpb.EndTransactionRequest{
 			Commit: true,
 			InternalCommitTrigger: &roachpb.InternalCommitTrigger{
@@ -1658,6 +1983,8 @@ func (r *Replica) AdminSplit(ctx context.Context, args roachpb.AdminSplitRequest
 					// correctness, but for best performance it should be one
 					// that we believe is currently up.
 					InitialLeaderStoreID: r.store.StoreID(),
+					LeftStats:            leftStats,
+					OrigStats:            origStats,
 				},
 			},
 		})
@@ -1670,11 +1997,38 @@ func (r *Replica) AdminSplit(ctx context.Context, args roachpb.AdminSplitRequest
 	return reply, nil
 }
 
+// splitStats returns the marshaled MVCC stats of the given first half of a
+// split of the range, computed on a snapshot of its data, and the stored
+// stats of the range at that snapshot.
+func (r *Replica) splitStats(leftDesc *roachpb.RangeDescriptor) ([]byte, []byte, error) {
+	snap := r.store.NewSnapshot()
+	defer snap.Close()
+
+	var origMs engine.MVCCStats
+	if err := engine.MVCCGetRangeStats(snap, r.RangeID, &origMs); err != nil {
+		return nil, nil, err
+	}
+	leftMs, err := ComputeStatsForRange(leftDesc, snap, r.store.Clock().PhysicalNow())
+	if err != nil {
+		return nil, nil, err
+	}
+	leftStats, err := leftMs.Marshal()
+	if err != nil {
+		return nil, nil, err
+	}
+	origStats, err := origMs.Marshal()
+	if err != nil {
+		return nil, nil, err
+	}
+	return leftStats, origStats, nil
+}
+
 // splitTrigger is called on a successful commit of an AdminSplit
 // transaction. It copies the sequence cache for the new range and
-// recomputes stats for both the existing, updated range and the new
-// range.
-func (r *Replica) splitTrigger(batch engine.Engine, ms *engine.MVCCStats, split *roachpb.SplitTrigger) error {
+// sets the stats of both the existing, updated range and the new
+// range. leftDeltaMs are the stats of the intents which the committing
+// transaction resolved in the updated range; they are included in ms.
+func (r *Replica) splitTrigger(batch engine.Engine, ms *engine.MVCCStats, leftDeltaMs engine.MVCCStats, split *roachpb.SplitTrigger) error {
 	// TODO(tschottdorf): should have an incoming context from the corresponding
 	// EndTransaction, but the plumbing has not been done yet.
 	sp := r.store.Tracer().StartSpan("split")
@@ -1697,13 +2051,34 @@ func (r *Replica) splitTrigger(batch engine.Engine, ms *engine.MVCCStats, split
 		return util.Errorf("unable to account for MVCCStats's own stats impact: %s", err)
 	}
 
-	// Compute stats for updated range.
+	// Use the stats for the updated range computed by the proposer of the
+	// split, or compute them if it didn't.
 	now := r.store.Clock().Timestamp()
-	leftMs, err := ComputeStatsForRange(&split.UpdatedDesc, batch, now.WallTime)
-	if err != nil {
-		return util.Errorf("unable to compute stats for updated range after split: %s", err)
+	var leftMs engine.MVCCStats
+	var raced bool
+	if len(split.LeftStats) > 0 {
+		var proposerMs engine.MVCCStats
+		if err := leftMs.Unmarshal(split.LeftStats); err != nil {
+			return util.Errorf("unable to unmarshal stats for updated range: %s", err)
+		}
+		if err := proposerMs.Unmarshal(split.OrigStats); err != nil {
+			return util.Errorf("unable to unmarshal stats for original range: %s", err)
+		}
+		leftMs.Add(leftDeltaMs)
+		// Commands applied since the proposer computed the stats may have
+		// changed either half of the range, in which case they can't be
+		// apportioned and the stats of both halves are repaired after the
+		// split.
+		raced = proposerMs != origStats
+		sp.LogEvent("used proposed stats for old range")
+	} else {
+		var err error
+		leftMs, err = ComputeStatsForRange(&split.UpdatedDesc, batch, now.WallTime)
+		if err != nil {
+			return util.Errorf("unable to compute stats for updated range after split: %s", err)
+		}
+		sp.LogEvent("computed stats for old range")
 	}
-	sp.LogEvent("computed stats for old range")
 	if err := r.stats.SetMVCCStats(batch, leftMs); err != nil {
 		return util.Errorf("unable to write MVCC stats: %s", err)
 	}
@@ -1726,6 +2101,15 @@ func (r *Replica) splitTrigger(batch engine.Engine, ms *engine.MVCCStats, split
 		return util.Errorf("unable to copy last verification timestamp: %s", err)
 	}
 
+	// Copy the closed timestamp. Reads of keys now in the new range may
+	// have been served up to it.
+	closedTS := r.getClosedTimestamp()
+	if !closedTS.Equal(roachpb.ZeroTimestamp) {
+		if err := setClosedTimestamp(batch, &deltaMs, split.NewDesc.RangeID, closedTS); err != nil {
+			return util.Errorf("unable to copy closed timestamp: %s", err)
+		}
+	}
+
 	// Initialize the new range's sequence cache by copying the original's.
 	seqCount, err := r.sequence.CopyInto(batch, &deltaMs, split.NewDesc.RangeID)
 	if err != nil {
@@ -1752,10 +2136,24 @@ func (r *Replica) splitTrigger(batch engine.Engine, ms *engine.MVCCStats, split
 	}
 	sp.LogEvent("computed stats for new range")
 
+	if checkStatsDrift {
+		// The new range's stats are derived from the original range's
+		// incrementally maintained ones, which carries over any drift.
+		actualMs, err := ComputeStatsForRange(&split.NewDesc, batch, now.WallTime)
+		if err != nil {
+			return util.Errorf("unable to verify stats for new range after split: %s", err)
+		}
+		if delta, drifted := mvccStatsDelta(rightMs, actualMs); drifted {
+			log.Errorf("%s: MVCC stats of new range %d drifted by %+v from recomputation",
+				r, split.NewDesc.RangeID, delta)
+		}
+	}
+
 	// Copy the timestamp cache into the new range.
 	r.mu.Lock()
 	newRng.mu.Lock()
 	r.mu.tsCache.MergeInto(newRng.mu.tsCache, true /* clear */)
+	newRng.mu.closedTimestamp = closedTS
 	newRng.mu.Unlock()
 	r.mu.Unlock()
 	sp.LogEvent("copied timestamp cache")
@@ -1771,6 +2169,19 @@ func (r *Replica) splitTrigger(batch engine.Engine, ms *engine.MVCCStats, split
 		// Update store stats with difference in stats before and after split.
 		r.store.metrics.addMVCCStats(deltaMs)
 
+		// The lease holder recomputes the stats of both halves if they
+		// couldn't be apportioned, so that the data is only iterated over by
+		// one replica.
+		if raced && r.getLeaderLease().OwnedBy(r.store.StoreID()) {
+			r.store.stopper.RunAsyncTask(func() {
+				for _, rng := range []*Replica{r, newRng} {
+					if err := rng.checkStats(); err != nil {
+						log.Warningf("%s: unable to repair MVCC stats after split: %s", rng, err)
+					}
+				}
+			})
+		}
+
 		// To avoid leaving the new range unavailable as it waits to elect
 		// its leader, one (and only one) of the nodes should start an
 		// election as soon as the split is processed.
@@ -1942,6 +2353,23 @@ func (r *Replica) mergeTrigger(batch engine.Engine, ms *engine.MVCCStats, merge
 		return util.Errorf("subsumed range ID must be provided: %d", subsumedRangeID)
 	}
 
+	// Carry over the subsumed range's closed timestamp if it is later than
+	// ours, since reads of its keys may have been served up to it. The
+	// caller picks up the result from the batch.
+	closedTS, err := loadClosedTimestamp(batch, r.RangeID)
+	if err != nil {
+		return err
+	}
+	subsumedClosedTS, err := loadClosedTimestamp(batch, subsumedRangeID)
+	if err != nil {
+		return err
+	}
+	if closedTS.Less(subsumedClosedTS) {
+		if err := setClosedTimestamp(batch, ms, r.RangeID, subsumedClosedTS); err != nil {
+			return util.Errorf("unable to carry over closed timestamp: %s", err)
+		}
+	}
+
 	// Compute stats for premerged range, including current transaction.
 	var mergedMs = r.GetMVCCStats()
 	mergedMs.Add(*ms)
@@ -1956,7 +2384,7 @@ func (r *Replica) mergeTrigger(batch engine.Engine, ms *engine.MVCCStats, merge
 	mergedMs.Add(rightMs)
 
 	// Copy the subsumed range's sequence cache to the subsuming one.
-	_, err := r.sequence.CopyFrom(batch, &mergedMs, subsumedRangeID)
+	_, err = r.sequence.CopyFrom(batch, &mergedMs, subsumedRangeID)
 	if err != nil {
 		return util.Errorf("unable to copy sequence cache to new split range: %s", err)
 	}
@@ -2010,6 +2438,9 @@ func (r *Replica) changeReplicasTrigger(batch engine.Engine, change *roachpb.Cha
 	if err := r.setDesc(&cpy); err != nil {
 		return err
 	}
+	r.mu.Lock()
+	r.pruneLearnersLocked()
+	r.mu.Unlock()
 	// If we're removing the current replica, add it to the range GC queue.
 	if change.ChangeType == roachpb.REMOVE_REPLICA && r.store.StoreID() == change.Replica.StoreID {
 		// Defer this to make it run as late as possible, maximizing the chances
@@ -2030,6 +2461,8 @@ func (r *Replica) changeReplicasTrigger(batch engine.Engine, change *roachpb.Cha
 // ChangeReplicas adds or removes a replica of a range. The change is performed
 // in a distributed transaction and takes effect when that transaction is committed.
 // When removing a replica, only the NodeID and StoreID fields of the Replica are used.
+// A replica added with Learner set is not made part of the raft group until
+// it is promoted by PromoteLearner.
 //
 // The supplied RangeDescriptor is used as a form of optimistic lock. See the
 // comment of "AdminSplit" for more information on this pattern.
@@ -2050,6 +2483,7 @@ func (r *Replica) ChangeReplicas(changeType roachpb.ReplicaChangeType, replica r
 				existingRep.StoreID == replica.StoreID {
 				found = i
 				replica.ReplicaID = existingRep.ReplicaID
+				replica.Learner = existingRep.Learner
 				break
 			}
 		}
@@ -2078,19 +2512,55 @@ func (r *Replica) ChangeReplicas(changeType roachpb.ReplicaChangeType, replica r
 	if err != nil {
 		return err
 	}
+	return r.changeReplicas(changeType, replica, desc, &updatedDesc)
+}
+
+// PromoteLearner makes the learner replica a full member of the range and of
+// its raft group. The learner must have caught up with the range, see
+// catchUpLearner.
+//
+// Promoting a learner added by a rebalance leaves the range over-replicated
+// until the replicate queue removes a replica in a separate replication
+// change. Raft only changes the membership of the group one replica at a
+// time, so the two can't be made atomic, and the range keeps an extra
+// voting replica if the removal fails.
+//
+// The supplied RangeDescriptor is used as a form of optimistic lock, as in
+// ChangeReplicas.
+func (r *Replica) PromoteLearner(learner roachpb.ReplicaDescriptor, desc *roachpb.RangeDescriptor) error {
+	updatedDesc := *desc
+	updatedDesc.Replicas = append([]roachpb.ReplicaDescriptor(nil), desc.Replicas...)
+	found := false
+	for i, existingRep := range updatedDesc.Replicas {
+		if existingRep.ReplicaID == learner.ReplicaID && existingRep.Learner {
+			updatedDesc.Replicas[i].Learner = false
+			learner = updatedDesc.Replicas[i]
+			found = true
+			break
+		}
+	}
+	if !found {
+		return util.Errorf("promoting learner %v which is not present in range %d", learner, desc.RangeID)
+	}
+	return r.changeReplicas(roachpb.ADD_REPLICA, learner, desc, &updatedDesc)
+}
 
+// changeReplicas replaces desc with updatedDesc in a distributed
+// transaction whose commit trigger applies the given replica change.
+func (r *Replica) changeReplicas(changeType roachpb.ReplicaChangeType, replica roachpb.ReplicaDescriptor,
+	desc, updatedDesc *roachpb.RangeDescriptor) error {
 	pErr := r.store.DB().Txn(func(txn *client.Txn) *roachpb.Error {
 		// Important: the range descriptor must be the first thing touched in the transaction
 		// so the transaction record is co-located with the range being modified.
 		b := &client.Batch{}
 		descKey := keys.RangeDescriptorKey(updatedDesc.StartKey)
 
-		if err := updateRangeDescriptor(b, descKey, desc, &updatedDesc); err != nil {
+		if err := updateRangeDescriptor(b, descKey, desc, updatedDesc); err != nil {
 			return roachpb.NewError(err)
 		}
 
 		// Update range descriptor addressing record(s).
-		if err := updateRangeAddressing(b, &updatedDesc); err != nil {
+		if err := updateRangeAddressing(b, updatedDesc); err != nil {
 			return roachpb.NewError(err)
 		}
 
@@ -2100,7 +2570,7 @@ func (r *Replica) ChangeReplicas(changeType roachpb.ReplicaChangeType, replica r
 		}
 
 		// Log replica change into range event log.
-		if err := r.store.logChange(txn, changeType, replica, updatedDesc); err != nil {
+		if err := r.store.logChange(txn, changeType, replica, *updatedDesc); err != nil {
 			return err
 		}
 
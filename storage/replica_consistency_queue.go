@@ -60,6 +60,11 @@ func (q *replicaConsistencyQueue) process(_ roachpb.Timestamp, rng *Replica, _ *
 	if pErr != nil {
 		log.Error(pErr.GoError())
 	}
+	if checkStatsDrift {
+		if err := rng.checkStats(); err != nil {
+			log.Error(err)
+		}
+	}
 	return nil
 }
 
@@ -98,7 +98,7 @@ func (q *replicaGCQueue) process(now roachpb.Timestamp, rng *Replica, _ *config.
 	// want to do a consistent read here. This is important when we are
 	// considering one of the metadata ranges: we must not do an
 	// inconsistent lookup in our own copy of the range.
-	b := &client.Batch{}
+	b := &client.Batch{AdmissionClass: roachpb.BACKGROUND}
 	b.InternalAddRequest(&roachpb.RangeLookupRequest{
 		Span: roachpb.Span{
 			Key: keys.RangeMetaKey(desc.StartKey),
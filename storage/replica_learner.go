@@ -0,0 +1,197 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License. See the AUTHORS file
+// for names of contributors.
+
+package storage
+
+import (
+	"time"
+
+	"github.com/coreos/etcd/raft/raftpb"
+
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/log"
+)
+
+// Learner replicas are members of the range descriptor which are not part of
+// the range's raft group, so that they don't count towards its quorum while
+// they catch up. A learner is caught up by a snapshot which the replica
+// adding it sends outside of raft. Once the learner has applied the
+// snapshot, it acknowledges its index and is promoted to a full member of
+// the raft group, which then only has to send it the few entries committed
+// since.
+
+// learnerCatchUpTimeout is the time a learner is given to apply its snapshot
+// before it is removed again.
+const learnerCatchUpTimeout = 1 * time.Minute
+
+// learnerProgress tracks the catch-up of a learner replica to which this
+// replica sent a snapshot.
+type learnerProgress struct {
+	// start is the time at which the snapshot was sent.
+	start time.Time
+	// index is the index of the snapshot.
+	index uint64
+	// match is the highest log index acknowledged by the learner.
+	match uint64
+	// failed is set if the snapshot could not be delivered, in which case
+	// it is sent again.
+	failed bool
+}
+
+// catchUpLearner returns whether the given learner replica has caught up with
+// the snapshot this replica sent it, sending the snapshot on the first call
+// and again if it could not be delivered.
+// An error is returned if the learner failed to catch up in time, in which
+// case it should be removed.
+func (r *Replica) catchUpLearner(learner roachpb.ReplicaDescriptor) (bool, error) {
+	r.mu.Lock()
+	p, ok := r.mu.learners[learner.ReplicaID]
+	var caughtUp, failed bool
+	start := time.Now()
+	if ok {
+		caughtUp, failed, start = p.match >= p.index, p.failed, p.start
+	}
+	r.mu.Unlock()
+	if caughtUp {
+		return true, nil
+	}
+	if time.Since(start) > learnerCatchUpTimeout {
+		return false, util.Errorf("%s: learner %v did not catch up within %s",
+			r, learner, learnerCatchUpTimeout)
+	}
+	if ok && !failed {
+		return false, nil
+	}
+
+	from := r.GetReplica()
+	if from == nil {
+		return false, util.Errorf("%s: unable to find replica of store %d", r, r.store.StoreID())
+	}
+	// Only capture the state of the replica under the lock, and copy the
+	// data of the range outside of it.
+	r.mu.Lock()
+	build, err := r.prepareSnapshotLocked()
+	term := r.mu.raftGroup.Status().Term
+	r.mu.Unlock()
+	if err != nil {
+		return false, err
+	}
+	snap, err := build()
+	if err != nil {
+		return false, err
+	}
+	p = &learnerProgress{
+		start: start,
+		index: snap.Metadata.Index,
+	}
+	r.mu.Lock()
+	r.mu.learners[learner.ReplicaID] = p
+	r.mu.Unlock()
+	if err := r.store.ctx.Transport.SendSnapshot(&RaftMessageRequest{
+		GroupID:     r.RangeID,
+		FromReplica: *from,
+		ToReplica:   learner,
+		Message: raftpb.Message{
+			Type:     raftpb.MsgSnap,
+			From:     uint64(from.ReplicaID),
+			To:       uint64(learner.ReplicaID),
+			Term:     term,
+			Snapshot: snap,
+		},
+	}, func(err error) {
+		if err != nil {
+			r.learnerSnapshotFailed(p, err)
+		}
+	}); err != nil {
+		r.learnerSnapshotFailed(p, err)
+	}
+	return false, nil
+}
+
+// learnerSnapshotFailed marks the snapshot sent to a learner as failed, and
+// queues the replica so that the snapshot is sent again.
+func (r *Replica) learnerSnapshotFailed(p *learnerProgress, err error) {
+	log.Warningf("%s: unable to send snapshot to learner: %s", r, err)
+	r.mu.Lock()
+	p.failed = true
+	r.mu.Unlock()
+	r.store.replicateQueue.MaybeAdd(r, r.store.Clock().Now())
+}
+
+// handleLearnerMessage records the index acknowledged by a learner replica of
+// the range and returns whether the message was sent by one. The messages of
+// learners are not passed to the raft group, which they are not part of.
+func (r *Replica) handleLearnerMessage(req *RaftMessageRequest) bool {
+	if _, replica := r.Desc().FindReplica(req.FromReplica.StoreID); replica == nil ||
+		replica.ReplicaID != req.FromReplica.ReplicaID || !replica.Learner {
+		return false
+	}
+	if req.Message.Type != raftpb.MsgAppResp || req.Message.Reject {
+		return true
+	}
+	r.mu.Lock()
+	p, ok := r.mu.learners[req.FromReplica.ReplicaID]
+	caughtUp := ok && p.match < p.index && req.Message.Index >= p.index
+	if ok && req.Message.Index > p.match {
+		p.match = req.Message.Index
+	}
+	r.mu.Unlock()
+	if caughtUp {
+		// Promote the learner.
+		r.store.replicateQueue.MaybeAdd(r, r.store.Clock().Now())
+	}
+	return true
+}
+
+// votingReplicas returns the IDs of the replicas of the range which are part
+// of its raft group, that is all of them except learners.
+func votingReplicas(desc *roachpb.RangeDescriptor) []uint64 {
+	var ids []uint64
+	for _, rep := range desc.Replicas {
+		if !rep.Learner {
+			ids = append(ids, uint64(rep.ReplicaID))
+		}
+	}
+	return ids
+}
+
+// countVoters returns the number of the supplied replicas which are not
+// learners.
+func countVoters(replicas []roachpb.ReplicaDescriptor) int {
+	var n int
+	for _, rep := range replicas {
+		if !rep.Learner {
+			n++
+		}
+	}
+	return n
+}
+
+// pruneLearnersLocked forgets the catch-up of the replicas which are no
+// longer learners of the range. It requires that the replica lock is held.
+func (r *Replica) pruneLearnersLocked() {
+	learners := map[roachpb.ReplicaID]struct{}{}
+	for _, rep := range r.mu.desc.Replicas {
+		if rep.Learner {
+			learners[rep.ReplicaID] = struct{}{}
+		}
+	}
+	for id := range r.mu.learners {
+		if _, ok := learners[id]; !ok {
+			delete(r.mu.learners, id)
+		}
+	}
+}
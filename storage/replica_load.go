@@ -0,0 +1,171 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/roachpb"
+)
+
+const (
+	// replicaLoadWindow is the duration over which the request rate of a
+	// replica is measured.
+	replicaLoadWindow = time.Minute
+	// replicaLoadBuckets is the number of buckets the window is divided
+	// into. The window slides forward one bucket at a time.
+	replicaLoadBuckets = 6
+	// replicaLoadKeySamples is the number of request keys sampled per
+	// window.
+	replicaLoadKeySamples = 20
+)
+
+// A replicaLoad tracks the rate of requests to a replica over a sliding
+// window and keeps a uniform sample of the keys they addressed.
+type replicaLoad struct {
+	now func() int64 // Wall time in nanoseconds.
+
+	mu struct {
+		sync.Mutex
+		// counts holds the number of requests per bucket. The bucket at idx
+		// started at bucketStart and is still being filled.
+		counts      [replicaLoadBuckets]int64
+		idx         int
+		bucketStart int64
+		// windowStart is the time at which tracking started, used to avoid
+		// underestimating the rate before a full window has elapsed.
+		windowStart int64
+		// keys is a reservoir sample of the keys requested since the sample
+		// was last reset, out of sampled requests in total.
+		keys    []roachpb.Key
+		sampled int64
+	}
+}
+
+func newReplicaLoad(now func() int64) *replicaLoad {
+	rl := &replicaLoad{now: now}
+	rl.reset()
+	return rl
+}
+
+// reset discards all recorded requests.
+func (rl *replicaLoad) reset() {
+	now := rl.now()
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.mu.counts = [replicaLoadBuckets]int64{}
+	rl.mu.idx = 0
+	rl.mu.bucketStart = now
+	rl.mu.windowStart = now
+	rl.mu.keys = nil
+	rl.mu.sampled = 0
+}
+
+// record records a request addressed to the given key.
+func (rl *replicaLoad) record(key roachpb.Key) {
+	now := rl.now()
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.rotateLocked(now)
+	rl.mu.counts[rl.mu.idx]++
+
+	rl.mu.sampled++
+	if len(rl.mu.keys) < replicaLoadKeySamples {
+		rl.mu.keys = append(rl.mu.keys, key)
+	} else if i := rand.Int63n(rl.mu.sampled); i < replicaLoadKeySamples {
+		rl.mu.keys[i] = key
+	}
+}
+
+// rotateLocked advances the current bucket to the one containing now,
+// clearing the buckets skipped along the way. The key sample is reset
+// whenever the window has slid completely past it.
+func (rl *replicaLoad) rotateLocked(now int64) {
+	bucketDuration := int64(replicaLoadWindow / replicaLoadBuckets)
+	for now-rl.mu.bucketStart >= bucketDuration {
+		rl.mu.idx = (rl.mu.idx + 1) % replicaLoadBuckets
+		rl.mu.counts[rl.mu.idx] = 0
+		rl.mu.bucketStart += bucketDuration
+		if rl.mu.idx == 0 {
+			rl.mu.keys = rl.mu.keys[:0]
+			rl.mu.sampled = 0
+		}
+		if now-rl.mu.bucketStart >= int64(replicaLoadWindow) {
+			// Skip ahead rather than clearing each bucket repeatedly after a
+			// long idle period.
+			rl.mu.counts = [replicaLoadBuckets]int64{}
+			rl.mu.bucketStart = now - (now-rl.mu.bucketStart)%bucketDuration
+			rl.mu.keys = rl.mu.keys[:0]
+			rl.mu.sampled = 0
+		}
+	}
+}
+
+// qps returns the average number of requests per second over the window,
+// or over the time since tracking started if that is shorter.
+func (rl *replicaLoad) qps() float64 {
+	now := rl.now()
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.rotateLocked(now)
+	var count int64
+	for _, c := range rl.mu.counts {
+		count += c
+	}
+	// The window covers the completed buckets plus the elapsed part of the
+	// current one.
+	elapsed := time.Duration(now - rl.mu.bucketStart + int64(replicaLoadWindow/replicaLoadBuckets)*(replicaLoadBuckets-1))
+	if tracked := time.Duration(now - rl.mu.windowStart); tracked < elapsed {
+		elapsed = tracked
+	}
+	if elapsed < time.Second {
+		elapsed = time.Second
+	}
+	return float64(count) / elapsed.Seconds()
+}
+
+// age returns the time since tracking started.
+func (rl *replicaLoad) age() time.Duration {
+	now := rl.now()
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return time.Duration(now - rl.mu.windowStart)
+}
+
+// sampledKeys returns a copy of the sampled request keys.
+func (rl *replicaLoad) sampledKeys() []roachpb.Key {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return append([]roachpb.Key(nil), rl.mu.keys...)
+}
+
+// A HotReplica describes the request load of a replica.
+type HotReplica struct {
+	RangeID  roachpb.RangeID `json:"rangeID"`
+	StartKey roachpb.RKey    `json:"startKey"`
+	EndKey   roachpb.RKey    `json:"endKey"`
+	QPS      float64         `json:"qps"`
+	// SampledKeys is a uniform sample of the keys requested within the
+	// current window. It is only populated on request.
+	SampledKeys []roachpb.Key `json:"sampledKeys,omitempty"`
+}
+
+type hotReplicasByQPS []HotReplica
+
+func (h hotReplicasByQPS) Len() int           { return len(h) }
+func (h hotReplicasByQPS) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h hotReplicasByQPS) Less(i, j int) bool { return h[i].QPS > h[j].QPS }
@@ -0,0 +1,103 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/util/hlc"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+func TestReplicaLoad(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	manual := hlc.NewManualClock(int64(time.Hour))
+	rl := newReplicaLoad(manual.UnixNano)
+
+	checkQPS := func(expected float64) {
+		if qps := rl.qps(); math.Abs(qps-expected) > 0.01 {
+			t.Errorf("expected %.2f qps, got %.2f", expected, qps)
+		}
+	}
+
+	// 10 requests per second for a full window.
+	for i := 0; i < int(replicaLoadWindow/time.Second); i++ {
+		for j := 0; j < 10; j++ {
+			rl.record(roachpb.Key(fmt.Sprintf("%03d", j)))
+		}
+		expKeys := 10 * (i + 1)
+		if expKeys > replicaLoadKeySamples {
+			expKeys = replicaLoadKeySamples
+		}
+		if keys := rl.sampledKeys(); len(keys) != expKeys {
+			t.Fatalf("%d: expected %d sampled keys, got %d", i, expKeys, len(keys))
+		}
+		manual.Increment(int64(time.Second))
+	}
+	checkQPS(10)
+
+	// Half a window later, the buckets covering the first half of the
+	// requests have expired, leaving 200 requests in the last 50 seconds.
+	manual.Increment(int64(replicaLoadWindow / 2))
+	checkQPS(4)
+
+	// After a long idle period, the rate drops to zero and the sample is
+	// discarded.
+	manual.Increment(int64(time.Hour))
+	checkQPS(0)
+	if keys := rl.sampledKeys(); len(keys) != 0 {
+		t.Errorf("expected no sampled keys, got %s", keys)
+	}
+
+	// Shortly after a reset, the rate is not diluted by the full window.
+	rl.reset()
+	for i := 0; i < 20; i++ {
+		rl.record(roachpb.Key("a"))
+	}
+	manual.Increment(int64(2 * time.Second))
+	checkQPS(10)
+}
+
+func TestStoreHotReplicas(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	store, _, stopper := createTestStore(t)
+	defer stopper.Stop()
+
+	splitKey := roachpb.RKey("m")
+	splitTestRange(store, roachpb.RKeyMin, splitKey, t)
+
+	for i := 0; i < 100; i++ {
+		args := getArgs(roachpb.Key("z"))
+		if _, pErr := client.SendWrapped(store.testSender(), nil, &args); pErr != nil {
+			t.Fatal(pErr)
+		}
+	}
+
+	hot := store.HotReplicas(1, true)
+	if len(hot) != 1 {
+		t.Fatalf("expected 1 hot replica, got %+v", hot)
+	}
+	if !hot[0].StartKey.Equal(splitKey) || hot[0].QPS == 0 {
+		t.Errorf("expected range starting at %s to be hottest, got %+v", splitKey, hot[0])
+	}
+	if len(hot[0].SampledKeys) == 0 || !hot[0].SampledKeys[0].Equal(roachpb.Key("z")) {
+		t.Errorf("expected sampled key %q, got %s", "z", hot[0].SampledKeys)
+	}
+}
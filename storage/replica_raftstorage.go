@@ -27,6 +27,7 @@ import (
 	"github.com/coreos/etcd/raft"
 	"github.com/coreos/etcd/raft/raftpb"
 	"github.com/gogo/protobuf/proto"
+	"golang.org/x/net/context"
 )
 
 // All calls to raft.RawNode require that an exclusive lock is held. All of the
@@ -68,9 +69,7 @@ func (r *Replica) InitialState() (raftpb.HardState, raftpb.ConfState, error) {
 	var cs raftpb.ConfState
 	// For uninitialized ranges, membership is unknown at this point.
 	if found || initialized {
-		for _, rep := range r.mu.desc.Replicas {
-			cs.Nodes = append(cs.Nodes, uint64(rep.ReplicaID))
-		}
+		cs.Nodes = votingReplicas(r.mu.desc)
 	}
 
 	return hs, cs, nil
@@ -113,7 +112,7 @@ func (r *Replica) entries(e engine.Engine, lo, hi, maxBytes uint64) ([]raftpb.En
 	}
 
 	rangeID := r.RangeID
-	_, err := engine.MVCCIterate(e,
+	_, err := engine.MVCCIterate(context.Background(), e,
 		keys.RaftLogKey(rangeID, lo),
 		keys.RaftLogKey(rangeID, hi),
 		roachpb.ZeroTimestamp,
@@ -339,28 +338,62 @@ func setLastIndex(eng engine.Engine, rangeID roachpb.RangeID, lastIndex uint64)
 // Snapshot implements the raft.Storage interface.
 // Snapshot requires that the replica lock is held.
 func (r *Replica) Snapshot() (raftpb.Snapshot, error) {
+	build, err := r.prepareSnapshotLocked()
+	if err != nil {
+		return raftpb.Snapshot{}, err
+	}
+	return build()
+}
+
+// prepareSnapshotLocked captures the state of the replica at the current
+// applied index and returns a function which builds the snapshot from it.
+// Only prepareSnapshotLocked requires that the replica lock is held, so
+// that callers outside of raft can build the snapshot, which copies all
+// the data of the range, without holding the lock. The returned function
+// must be called exactly once.
+func (r *Replica) prepareSnapshotLocked() (func() (raftpb.Snapshot, error), error) {
 	// Copy all the data from a consistent RocksDB snapshot into a RaftSnapshotData.
 	snap := r.store.NewSnapshot()
-	defer snap.Close()
-	var snapData roachpb.RaftSnapshotData
 
 	firstIndex, err := r.FirstIndex()
 	if err != nil {
-		return raftpb.Snapshot{}, err
+		snap.Close()
+		return nil, err
 	}
 
 	// Read the range metadata from the snapshot instead of the members
 	// of the Range struct because they might be changed concurrently.
 	appliedIndex, err := r.loadAppliedIndexLocked(snap)
 	if err != nil {
-		return raftpb.Snapshot{}, err
+		snap.Close()
+		return nil, err
+	}
+
+	term, err := r.Term(appliedIndex)
+	if err != nil {
+		snap.Close()
+		return nil, util.Errorf("failed to fetch term of %d: %s", appliedIndex, err)
 	}
 
+	startKey := r.mu.desc.StartKey
+	return func() (raftpb.Snapshot, error) {
+		defer snap.Close()
+		return r.snapshot(snap, startKey, firstIndex, appliedIndex, term)
+	}, nil
+}
+
+// snapshot builds the snapshot of the range starting at startKey at the
+// given applied index from the engine snapshot. It doesn't require the
+// replica lock to be held.
+func (r *Replica) snapshot(snap engine.Engine, startKey roachpb.RKey,
+	firstIndex, appliedIndex, term uint64) (raftpb.Snapshot, error) {
+	var snapData roachpb.RaftSnapshotData
+
 	var desc roachpb.RangeDescriptor
 	// We ignore intents on the range descriptor (consistent=false) because we
 	// know they cannot be committed yet; operations that modify range
 	// descriptors resolve their own intents when they commit.
-	ok, err := engine.MVCCGetProto(snap, keys.RangeDescriptorKey(r.mu.desc.StartKey),
+	ok, err := engine.MVCCGetProto(snap, keys.RangeDescriptorKey(startKey),
 		r.store.Clock().Now(), false /* !consistent */, nil, &desc)
 	if err != nil {
 		return raftpb.Snapshot{}, util.Errorf("failed to get desc: %s", err)
@@ -398,15 +431,7 @@ func (r *Replica) Snapshot() (raftpb.Snapshot, error) {
 	}
 
 	// Synthesize our raftpb.ConfState from desc.
-	var cs raftpb.ConfState
-	for _, rep := range desc.Replicas {
-		cs.Nodes = append(cs.Nodes, uint64(rep.ReplicaID))
-	}
-
-	term, err := r.Term(appliedIndex)
-	if err != nil {
-		return raftpb.Snapshot{}, util.Errorf("failed to fetch term of %d: %s", appliedIndex, err)
-	}
+	cs := raftpb.ConfState{Nodes: votingReplicas(&desc)}
 
 	return raftpb.Snapshot{
 		Data: data,
@@ -570,6 +595,12 @@ func (r *Replica) applySnapshot(batch engine.Engine, snap raftpb.Snapshot) (uint
 		return 0, err
 	}
 
+	// Read the closed timestamp.
+	closedTS, err := loadClosedTimestamp(batch, desc.RangeID)
+	if err != nil {
+		return 0, err
+	}
+
 	// Load updated range stats. The local newStats variable will be assigned
 	// to r.stats after the batch commits.
 	newStats, err := newRangeStats(desc.RangeID, batch)
@@ -598,6 +629,7 @@ func (r *Replica) applySnapshot(batch engine.Engine, snap raftpb.Snapshot) (uint
 		// the snapshot.
 		r.mu.appliedIndex = snap.Metadata.Index
 		r.mu.leaderLease = lease
+		r.mu.closedTimestamp = closedTS
 		r.mu.Unlock()
 
 		// Update other fields which are uninitialized or need updating.
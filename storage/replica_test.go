@@ -20,7 +20,10 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"math"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
@@ -661,6 +664,58 @@ func TestRangeGossipConfigsOnLease(t *testing.T) {
 	})
 }
 
+// TestRangeGossipLeaderLease verifies that a leader lease is gossiped by the
+// replica which newly acquires it, and not by the other replicas applying it.
+func TestRangeGossipLeaderLease(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tc := testContext{}
+	tc.Start(t)
+	defer tc.Stop()
+
+	secondReplica := roachpb.ReplicaDescriptor{
+		NodeID:    2,
+		StoreID:   2,
+		ReplicaID: 2,
+	}
+	rngDesc := tc.rng.Desc()
+	rngDesc.Replicas = append(rngDesc.Replicas, secondReplica)
+	tc.rng.setDescWithoutProcessUpdate(rngDesc)
+
+	// Expire our own lease and give the lease to the second replica, which
+	// this replica must not gossip.
+	tc.manualClock.Increment(int64(DefaultLeaderLeaseDuration + 1))
+	now := tc.clock.Now()
+	setLeaderLease(t, tc.rng, &roachpb.Lease{
+		Start:      now,
+		Expiration: now.Add(10, 0),
+		Replica:    secondReplica,
+	})
+	var lease roachpb.Lease
+	if err := tc.gossip.GetInfoProto(gossip.MakeLeaderLeaseKey(tc.rng.RangeID), &lease); err == nil &&
+		lease.OwnedBy(secondReplica.StoreID) {
+		t.Errorf("unexpected gossip of lease %s by a replica not holding it", lease)
+	}
+
+	// Give the lease back to this replica, which gossips it.
+	tc.manualClock.Increment(11 + int64(tc.clock.MaxOffset()))
+	now = tc.clock.Now()
+	newLease := roachpb.Lease{
+		Start:      now,
+		Expiration: now.Add(20, 0),
+		Replica:    *tc.rng.GetReplica(),
+	}
+	setLeaderLease(t, tc.rng, &newLease)
+	util.SucceedsSoon(t, func() error {
+		if err := tc.gossip.GetInfoProto(gossip.MakeLeaderLeaseKey(tc.rng.RangeID), &lease); err != nil {
+			return err
+		}
+		if !lease.OwnedBy(tc.store.StoreID()) || !lease.Expiration.Equal(newLease.Expiration) {
+			return util.Errorf("expected lease %s to be gossiped, got %s", newLease, lease)
+		}
+		return nil
+	})
+}
+
 // TestRangeTSCacheLowWaterOnLease verifies that the low water mark is
 // set on the timestamp cache when the node is granted the leader
 // lease after not holding it and it is not set when the node is
@@ -1383,6 +1438,148 @@ func TestRangeUseTSCache(t *testing.T) {
 	}
 }
 
+// TestRangeClosedTimestamp verifies that commands close timestamps, that
+// writes at closed timestamps are forwarded and that consistent reads at
+// closed timestamps are served without the leader lease.
+func TestRangeClosedTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tc := testContext{}
+	ctx := TestStoreContext()
+	ctx.ClosedTimestampTarget = time.Second
+	tc.StartWithStoreContext(t, ctx)
+	defer tc.Stop()
+
+	tc.manualClock.Set((10 * time.Second).Nanoseconds())
+	pArgs := putArgs([]byte("a"), []byte("value"))
+	if _, pErr := client.SendWrapped(tc.Sender(), tc.rng.context(), &pArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+	closedTS := tc.rng.getClosedTimestamp()
+	if closedTS.WallTime != (9 * time.Second).Nanoseconds() {
+		t.Fatalf("expected closed timestamp at 9s; got %s", closedTS)
+	}
+	if ts, err := loadClosedTimestamp(tc.engine, tc.rng.RangeID); err != nil {
+		t.Fatal(err)
+	} else if !ts.Equal(closedTS) {
+		t.Fatalf("expected persisted closed timestamp %s; got %s", closedTS, ts)
+	}
+
+	// A write at a closed timestamp is forwarded past it.
+	pArgs = putArgs([]byte("b"), []byte("value"))
+	reply, pErr := client.SendWrappedWith(tc.Sender(), tc.rng.context(), roachpb.Header{
+		Timestamp: makeTS((5 * time.Second).Nanoseconds(), 0),
+	}, &pArgs)
+	if pErr != nil {
+		t.Fatal(pErr)
+	}
+	if pReply := reply.(*roachpb.PutResponse); !closedTS.Less(pReply.Timestamp) {
+		t.Errorf("expected write timestamp to be forwarded past %s; got %s", closedTS, pReply.Timestamp)
+	}
+
+	// A transactional write at a closed timestamp forwards the transaction's
+	// timestamp past it, and a commit at a closed timestamp must retry.
+	closedTS = tc.rng.getClosedTimestamp()
+	for _, commit := range []bool{false, true} {
+		key := roachpb.Key(fmt.Sprintf("txn-%t", commit))
+		txn := newTransaction("test", key, 1, roachpb.SERIALIZABLE, nil)
+		txn.Timestamp = makeTS((5 * time.Second).Nanoseconds(), 0)
+		txn.OrigTimestamp = txn.Timestamp
+		txn.MaxTimestamp = txn.Timestamp
+		bt, _ := beginTxnArgs(key, txn)
+		put := putArgs(key, []byte("value"))
+		var ba roachpb.BatchRequest
+		ba.Header = roachpb.Header{Txn: txn}
+		ba.Add(&bt, &put)
+		if commit {
+			et, _ := endTxnArgs(txn, true)
+			et.IntentSpans = []roachpb.Span{{Key: key}}
+			ba.Add(&et)
+		}
+		br, pErr := tc.Sender().Send(tc.rng.context(), ba)
+		if commit {
+			if _, ok := pErr.GetDetail().(*roachpb.TransactionRetryError); !ok {
+				t.Errorf("expected a retry error committing at a closed timestamp; got %v", pErr)
+			}
+			continue
+		}
+		if pErr != nil {
+			t.Fatal(pErr)
+		}
+		if !closedTS.Less(br.Txn.Timestamp) {
+			t.Errorf("expected txn timestamp to be forwarded past %s; got %s", closedTS, br.Txn.Timestamp)
+		}
+	}
+
+	// Lose the lease. Reads at the closed timestamp are still served.
+	secondReplica := roachpb.ReplicaDescriptor{
+		NodeID:    2,
+		StoreID:   2,
+		ReplicaID: 2,
+	}
+	rngDesc := tc.rng.Desc()
+	rngDesc.Replicas = append(rngDesc.Replicas, secondReplica)
+	tc.rng.setDescWithoutProcessUpdate(rngDesc)
+	start := tc.rng.getLeaderLease().Expiration.Add(1, 0)
+	tc.manualClock.Set(start.WallTime)
+	setLeaderLease(t, tc.rng, &roachpb.Lease{
+		Start:      start,
+		Expiration: start.Add(10, 0),
+		Replica:    secondReplica,
+	})
+
+	closedTS = tc.rng.getClosedTimestamp()
+	gArgs := getArgs([]byte("a"))
+	if _, pErr := client.SendWrappedWith(tc.Sender(), tc.rng.context(), roachpb.Header{
+		Timestamp: closedTS,
+	}, &gArgs); pErr != nil {
+		t.Errorf("expected success reading at closed timestamp: %s", pErr)
+	}
+	_, pErr = client.SendWrappedWith(tc.Sender(), tc.rng.context(), roachpb.Header{
+		Timestamp: closedTS.Next(),
+	}, &gArgs)
+	if _, ok := pErr.GetDetail().(*roachpb.NotLeaderError); !ok {
+		t.Errorf("expected not leader error; got %s", pErr)
+	}
+}
+
+// TestRangeCloseIdleTimestamp verifies that the timestamps of a range which
+// receives no commands are closed by its leader.
+func TestRangeCloseIdleTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tc := testContext{}
+	ctx := TestStoreContext()
+	const target = 100 * time.Millisecond
+	ctx.ClosedTimestampTarget = target
+	tc.StartWithStoreContext(t, ctx)
+	defer tc.Stop()
+
+	// Acquire a fresh leader lease, which closes timestamps like every
+	// command.
+	tc.manualClock.Set((10 * time.Second).Nanoseconds())
+	gArgs := getArgs([]byte("a"))
+	if _, pErr := client.SendWrapped(tc.Sender(), tc.rng.context(), &gArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	// Timestamps are only closed once they lag the present by more than
+	// twice the target.
+	closedTS := tc.rng.getClosedTimestamp()
+	tc.manualClock.Set(closedTS.WallTime + (150 * time.Millisecond).Nanoseconds())
+	tc.rng.maybeCloseTimestamp(target)
+	if ts := tc.rng.getClosedTimestamp(); !ts.Equal(closedTS) {
+		t.Fatalf("expected closed timestamp %s; got %s", closedTS, ts)
+	}
+
+	tc.manualClock.Set(closedTS.WallTime + (500 * time.Millisecond).Nanoseconds())
+	tc.rng.maybeCloseTimestamp(target)
+	util.SucceedsSoon(t, func() error {
+		if ts := tc.rng.getClosedTimestamp(); !closedTS.Less(ts) {
+			return util.Errorf("expected closed timestamp past %s; got %s", closedTS, ts)
+		}
+		return nil
+	})
+}
+
 // TestRangeNoTSCacheInconsistent verifies that the timestamp cache
 // is not affected by inconsistent reads.
 func TestRangeNoTSCacheInconsistent(t *testing.T) {
@@ -2933,6 +3130,230 @@ func TestRangeStatsComputation(t *testing.T) {
 	verifyRangeStats(tc.engine, tc.rng.RangeID, expMS, t)
 }
 
+// TestReplicaClearRange verifies that the ClearRange command removes all
+// versions of the keys in its span and adjusts the range stats as if the
+// keys had never been written.
+func TestReplicaClearRange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	// Clear the keys in several chunks.
+	defer func(size int) { clearRangeChunkSize = size }(clearRangeChunkSize)
+	clearRangeChunkSize = 2
+	tc := testContext{
+		bootstrapMode: bootstrapRangeOnly,
+	}
+	tc.Start(t)
+	defer tc.Stop()
+
+	pArgs := putArgs([]byte("c"), []byte("value"))
+	if _, pErr := client.SendWrapped(tc.Sender(), tc.rng.context(), &pArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+	var expMS engine.MVCCStats
+	if err := engine.MVCCGetRangeStats(tc.engine, tc.rng.RangeID, &expMS); err != nil {
+		t.Fatal(err)
+	}
+
+	// Write two versions of "a" and one of "b", all of which are cleared.
+	for _, key := range []string{"a", "a", "b"} {
+		pArgs := putArgs([]byte(key), []byte("value"))
+		if _, pErr := client.SendWrapped(tc.Sender(), tc.rng.context(), &pArgs); pErr != nil {
+			t.Fatal(pErr)
+		}
+	}
+
+	cArgs := &roachpb.ClearRangeRequest{
+		Span: roachpb.Span{
+			Key:    roachpb.Key("a"),
+			EndKey: roachpb.Key("c"),
+		},
+	}
+	if _, pErr := client.SendWrapped(tc.Sender(), tc.rng.context(), cArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	kvs, _, err := engine.MVCCScan(tc.engine, roachpb.Key("a"), roachpb.Key("d"), 0, tc.clock.Now(), true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 1 || !kvs[0].Key.Equal(roachpb.Key("c")) {
+		t.Fatalf("expected only key \"c\" to remain; got %v", kvs)
+	}
+	verifyRangeStats(tc.engine, tc.rng.RangeID, expMS, t)
+}
+
+// TestReplicaExport verifies that the Export command writes the values in
+// its span to an SSTable which is either returned or left in the requested
+// directory below the export root, that no file is produced for an empty
+// span, and that directories outside of the root are rejected.
+func TestReplicaExport(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	root, err := ioutil.TempDir("", "TestReplicaExport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.RemoveAll(root); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	tc := testContext{}
+	tsc := TestStoreContext()
+	tsc.ExportDir = root
+	tc.StartWithStoreContext(t, tsc)
+	defer tc.Stop()
+
+	for _, key := range []string{"a", "a", "b"} {
+		pArgs := putArgs([]byte(key), []byte("value"))
+		if _, pErr := client.SendWrapped(tc.Sender(), tc.rng.context(), &pArgs); pErr != nil {
+			t.Fatal(pErr)
+		}
+	}
+
+	// Build the SSTable the export is expected to produce from the latest
+	// values of the exported keys.
+	kvs, _, err := engine.MVCCScan(tc.engine, roachpb.Key("a"), roachpb.Key("c"), 0,
+		tc.clock.Now(), true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expPath := filepath.Join(root, "expected.sst")
+	w, err := engine.MakeRocksDBSstFileWriter(expPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, kv := range kvs {
+		if err := w.Add(engine.MVCCKeyValue{
+			Key:   engine.MVCCKey{Key: kv.Key, Timestamp: kv.Value.Timestamp},
+			Value: kv.Value.RawBytes,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	expSST, err := ioutil.ReadFile(expPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		key, endKey string
+		dir         string
+		expFiles    int
+		expErr      string
+	}{
+		{"a", "c", "", 1, ""},
+		{"a", "c", "backup", 1, ""},
+		{"a", "c", "backup/../nested", 1, ""},
+		{"x", "z", "", 0, ""},
+		{"a", "c", root, 0, "must be relative"},
+		{"a", "c", "../escape", 0, "outside of the export root"},
+		{"a", "c", "backup/../../escape", 0, "outside of the export root"},
+	}
+	for i, test := range testCases {
+		eArgs := &roachpb.ExportRequest{
+			Span: roachpb.Span{
+				Key:    roachpb.Key(test.key),
+				EndKey: roachpb.Key(test.endKey),
+			},
+			Dir: test.dir,
+		}
+		reply, pErr := client.SendWrapped(tc.Sender(), tc.rng.context(), eArgs)
+		if test.expErr != "" {
+			if !testutils.IsPError(pErr, test.expErr) {
+				t.Errorf("%d: expected error %q; got %v", i, test.expErr, pErr)
+			}
+			continue
+		}
+		if pErr != nil {
+			t.Fatalf("%d: %s", i, pErr)
+		}
+		files := reply.(*roachpb.ExportResponse).Files
+		if len(files) != test.expFiles {
+			t.Fatalf("%d: expected %d files; got %d", i, test.expFiles, len(files))
+		}
+		for _, file := range files {
+			if !file.Span.Equal(eArgs.Span) {
+				t.Errorf("%d: expected span %+v; got %+v", i, eArgs.Span, file.Span)
+			}
+			sst := file.SST
+			if test.dir == "" {
+				if file.Path != "" {
+					t.Errorf("%d: expected no path; got %s", i, file.Path)
+				}
+			} else {
+				if len(sst) != 0 {
+					t.Errorf("%d: expected no SST contents; got %d bytes", i, len(sst))
+				}
+				if expDir := filepath.Join(root, test.dir); filepath.Dir(file.Path) != expDir {
+					t.Errorf("%d: expected a path in %s; got %s", i, expDir, file.Path)
+				}
+				if sst, err = ioutil.ReadFile(file.Path); err != nil {
+					t.Fatalf("%d: %s", i, err)
+				}
+			}
+			if !bytes.Equal(sst, expSST) {
+				t.Errorf("%d: exported SSTable differs from the expected one", i)
+			}
+		}
+	}
+
+	// Exports naming a directory are refused if the store has no export
+	// root.
+	tc.store.ctx.ExportDir = ""
+	eArgs := &roachpb.ExportRequest{
+		Span: roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("c")},
+		Dir:  "backup",
+	}
+	if _, pErr := client.SendWrapped(tc.Sender(), tc.rng.context(), eArgs); !testutils.IsPError(pErr, "disabled") {
+		t.Errorf("expected exports to a directory to be disabled; got %v", pErr)
+	}
+}
+
+// TestReplicaRecomputeStats verifies that drift in the incrementally
+// maintained stats of a range is detected and repaired.
+func TestReplicaRecomputeStats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tc := testContext{}
+	tc.Start(t)
+	defer tc.Stop()
+
+	pArgs := putArgs([]byte("a"), []byte("value"))
+	if _, pErr := client.SendWrapped(tc.Sender(), tc.rng.context(), &pArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	// Introduce drift by overwriting the stats outside of a command.
+	driftedMs := tc.rng.GetMVCCStats()
+	driftedMs.LiveBytes += 100
+	driftedMs.KeyCount--
+	if err := tc.rng.stats.SetMVCCStats(tc.engine, driftedMs); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tc.rng.checkStats(); err != nil {
+		t.Fatal(err)
+	}
+
+	var storedMs engine.MVCCStats
+	if err := engine.MVCCGetRangeStats(tc.engine, tc.rng.RangeID, &storedMs); err != nil {
+		t.Fatal(err)
+	}
+	actualMs, err := ComputeStatsForRange(tc.rng.Desc(), tc.engine, tc.clock.PhysicalNow())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delta, drifted := mvccStatsDelta(storedMs, actualMs); drifted {
+		t.Errorf("expected stats to be repaired; drifted by %+v", delta)
+	}
+	if inMemMs := tc.rng.GetMVCCStats(); inMemMs != storedMs {
+		t.Errorf("expected in-memory stats %+v to match stored stats %+v", inMemMs, storedMs)
+	}
+}
+
 // TestMerge verifies that the Merge command is behaving as
 // expected. Merge semantics for different data types are tested more
 // robustly at the engine level; this test is intended only to show
@@ -114,10 +114,10 @@ func (rq *replicateQueue) process(now roachpb.Timestamp, repl *Replica, sysCfg *
 	action, _ := rq.allocator.ComputeAction(*zone, desc)
 
 	// Avoid taking action if the range has too many dead replicas to make
-	// quorum.
+	// quorum. Only the voting replicas are part of the quorum.
 	deadReplicas := rq.allocator.storePool.deadReplicas(desc.Replicas)
-	quorum := computeQuorum(len(desc.Replicas))
-	liveReplicaCount := len(desc.Replicas) - len(deadReplicas)
+	quorum := computeQuorum(countVoters(desc.Replicas))
+	liveReplicaCount := countVoters(desc.Replicas) - countVoters(deadReplicas)
 	if liveReplicaCount < quorum {
 		return util.Errorf("range requires a replication change, but lacks a quorum of live nodes.")
 	}
@@ -147,6 +147,31 @@ func (rq *replicateQueue) process(now roachpb.Timestamp, repl *Replica, sysCfg *
 		if removeReplica.StoreID == repl.store.StoreID() {
 			return nil
 		}
+	case AllocatorPromoteLearner:
+		// The learner is sent a snapshot and promoted once it has applied it.
+		// Rather than waiting here, the replica is queued again when the
+		// learner acknowledges the snapshot, or by the scanner. The promotion
+		// leaves the range with a voting replica too many, which is removed
+		// by a separate replication change once the replica is requeued.
+		for _, replica := range desc.Replicas {
+			if !replica.Learner {
+				continue
+			}
+			caughtUp, err := repl.catchUpLearner(replica)
+			if err != nil {
+				if rmErr := repl.ChangeReplicas(roachpb.REMOVE_REPLICA, replica, desc); rmErr != nil {
+					log.Warningf("%s: unable to remove learner %v: %s", repl, replica, rmErr)
+				}
+				return err
+			}
+			if !caughtUp {
+				return nil
+			}
+			if err = repl.PromoteLearner(replica, desc); err != nil {
+				return err
+			}
+			break
+		}
 	case AllocatorRemoveDead:
 		if len(deadReplicas) == 0 {
 			if log.V(1) {
@@ -169,6 +194,7 @@ func (rq *replicateQueue) process(now roachpb.Timestamp, repl *Replica, sysCfg *
 		rebalanceReplica := roachpb.ReplicaDescriptor{
 			NodeID:  rebalanceStore.Node.NodeID,
 			StoreID: rebalanceStore.StoreID,
+			Learner: true,
 		}
 		if err = repl.ChangeReplicas(roachpb.ADD_REPLICA, rebalanceReplica, desc); err != nil {
 			return err
@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/util"
 	"github.com/cockroachdb/cockroach/util/hlc"
 	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/metric"
 	"github.com/cockroachdb/cockroach/util/stop"
 )
 
@@ -68,7 +69,9 @@ type storeStats struct {
 // complete approximately one full scan per target interval in a large
 // store (in small stores it may complete faster than the target
 // interval).  Each replica is tested for inclusion in a sequence of
-// prioritized replica queues.
+// prioritized replica queues. The target interval is stretched while the
+// store is loaded, so that the queues don't compete with foreground
+// traffic.
 type replicaScanner struct {
 	targetInterval time.Duration  // Target duration interval for scan loop
 	maxIdleTime    time.Duration  // Max idle time for scan loop
@@ -76,6 +79,12 @@ type replicaScanner struct {
 	replicas       replicaSet     // Replicas to be scanned
 	queues         []replicaQueue // Replica queues managed by this scanner
 	removed        chan *Replica  // Replicas to remove from queues
+	// slowdown, if set, returns the factor by which the target interval and
+	// the max idle time are stretched. It may only be set before Start().
+	slowdown func() float64
+	// period, if set, is updated with the duration of each completed scan.
+	// It may only be set before Start().
+	period *metric.Gauge
 	// Count of times and total duration through the scanning loop but locked by the completedScan
 	// mutex.
 	completedScan *sync.Cond
@@ -150,8 +159,14 @@ func (rs *replicaScanner) WaitForScanCompletion() int64 {
 // paceInterval returns a duration between iterations to allow us to pace
 // the scan.
 func (rs *replicaScanner) paceInterval(start, now time.Time) time.Duration {
+	targetInterval, maxIdleTime := rs.targetInterval, rs.maxIdleTime
+	if rs.slowdown != nil {
+		slowdown := rs.slowdown()
+		targetInterval = time.Duration(float64(targetInterval) * slowdown)
+		maxIdleTime = time.Duration(float64(maxIdleTime) * slowdown)
+	}
 	elapsed := now.Sub(start)
-	remainingNanos := rs.targetInterval.Nanoseconds() - elapsed.Nanoseconds()
+	remainingNanos := targetInterval.Nanoseconds() - elapsed.Nanoseconds()
 	if remainingNanos < 0 {
 		remainingNanos = 0
 	}
@@ -160,8 +175,8 @@ func (rs *replicaScanner) paceInterval(start, now time.Time) time.Duration {
 		count = 1
 	}
 	interval := time.Duration(remainingNanos / int64(count))
-	if rs.maxIdleTime > 0 && interval > rs.maxIdleTime {
-		interval = rs.maxIdleTime
+	if maxIdleTime > 0 && interval > maxIdleTime {
+		interval = maxIdleTime
 	}
 	return interval
 }
@@ -233,6 +248,9 @@ func (rs *replicaScanner) scanLoop(clock *hlc.Clock, stopper *stop.Stopper) {
 				rs.completedScan.L.Lock()
 				rs.count++
 				rs.total += time.Now().Sub(start)
+				if rs.period != nil {
+					rs.period.Update(time.Now().Sub(start).Nanoseconds())
+				}
 				rs.completedScan.Broadcast()
 				rs.completedScan.L.Unlock()
 				if log.V(6) {
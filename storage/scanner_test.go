@@ -299,6 +299,36 @@ func TestScannerPaceInterval(t *testing.T) {
 	}
 }
 
+// TestScannerPaceIntervalSlowdown verifies that the slowdown of the scanner
+// stretches the target interval and the max idle time.
+func TestScannerPaceIntervalSlowdown(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	const count = 4
+	slowdown := 1.0
+	s := newReplicaScanner(time.Second, 100*time.Millisecond, newTestRangeSet(count, t))
+	s.slowdown = func() float64 { return slowdown }
+
+	start := time.Now()
+	testCases := []struct {
+		slowdown float64
+		elapsed  time.Duration
+		expected time.Duration
+	}{
+		{1, 0, 100 * time.Millisecond},
+		{2, 0, 200 * time.Millisecond},
+		{4, 0, 400 * time.Millisecond},
+		{10, 0, time.Second},
+		{10, 6 * time.Second, time.Second},
+		{10, 10 * time.Second, 0},
+	}
+	for i, tc := range testCases {
+		slowdown = tc.slowdown
+		if interval := s.paceInterval(start, start.Add(tc.elapsed)); interval != tc.expected {
+			t.Errorf("%d: expected interval %s, got %s", i, tc.expected, interval)
+		}
+	}
+}
+
 // TestScannerEmptyRangeSet verifies that an empty range set doesn't busy loop.
 func TestScannerEmptyRangeSet(t *testing.T) {
 	defer leaktest.AfterTest(t)()
@@ -22,6 +22,7 @@ import (
 	"math"
 
 	"github.com/gogo/protobuf/proto"
+	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/keys"
 	"github.com/cockroachdb/cockroach/roachpb"
@@ -135,7 +136,7 @@ func (sc *SequenceCache) GetAllTransactionID(e engine.Engine, txnID *uuid.UUID)
 // each unmarshaled entry with the key, the transaction ID and the decoded
 // entry.
 func (sc *SequenceCache) Iterate(e engine.Engine, f func([]byte, *uuid.UUID, roachpb.SequenceCacheEntry)) {
-	_, _ = engine.MVCCIterate(e, sc.min, sc.max, roachpb.ZeroTimestamp,
+	_, _ = engine.MVCCIterate(context.TODO(), e, sc.min, sc.max, roachpb.ZeroTimestamp,
 		true /* consistent */, nil /* txn */, false, /* !reverse */
 		func(kv roachpb.KeyValue) (bool, error) {
 			var entry roachpb.SequenceCacheEntry
@@ -154,7 +155,7 @@ func (sc *SequenceCache) Iterate(e engine.Engine, f func([]byte, *uuid.UUID, roa
 func copySeqCache(e engine.Engine, ms *engine.MVCCStats, srcID, dstID roachpb.RangeID, keyMin, keyMax engine.MVCCKey) (int, error) {
 	var scratch [64]byte
 	var count int
-	err := e.Iterate(keyMin, keyMax,
+	err := e.Iterate(context.TODO(), keyMin, keyMax,
 		func(kv engine.MVCCKeyValue) (bool, error) {
 			// Decode the key into a cmd, skipping on error. Otherwise,
 			// write it to the corresponding key in the new cache.
@@ -17,12 +17,16 @@
 package storage
 
 import (
+	"bytes"
+	"sort"
 	"time"
 
 	"github.com/cockroachdb/cockroach/client"
 	"github.com/cockroachdb/cockroach/config"
 	"github.com/cockroachdb/cockroach/gossip"
+	"github.com/cockroachdb/cockroach/keys"
 	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/storage/engine"
 	"github.com/cockroachdb/cockroach/util"
 	"github.com/cockroachdb/cockroach/util/log"
 )
@@ -32,10 +36,13 @@ const (
 	splitQueueMaxSize = 100
 	// splitQueueTimerDuration is the duration between splits of queued ranges.
 	splitQueueTimerDuration = 0 // zero duration to process splits greedily.
+	// loadSplitMinAge is the minimum time over which the request rate of a
+	// range must have been measured before it is split due to load.
+	loadSplitMinAge = replicaLoadWindow / replicaLoadBuckets
 )
 
-// splitQueue manages a queue of ranges slated to be split due to size
-// or along intersecting zone config boundaries.
+// splitQueue manages a queue of ranges slated to be split due to size,
+// along intersecting zone config boundaries or due to sustained load.
 type splitQueue struct {
 	baseQueue
 	db *client.DB
@@ -60,7 +67,8 @@ func (*splitQueue) acceptsUnsplitRanges() bool {
 
 // shouldQueue determines whether a range should be queued for
 // splitting. This is true if the range is intersected by a zone config
-// prefix or if the range's size in bytes exceeds the limit for the zone.
+// prefix, if the range's size in bytes exceeds the limit for the zone or
+// if the range's request rate exceeds the load-based split threshold.
 func (*splitQueue) shouldQueue(now roachpb.Timestamp, rng *Replica,
 	sysCfg *config.SystemConfig) (shouldQ bool, priority float64) {
 
@@ -83,6 +91,11 @@ func (*splitQueue) shouldQueue(now roachpb.Timestamp, rng *Replica,
 		priority += ratio
 		shouldQ = true
 	}
+
+	if ratio := loadSplitRatio(rng); ratio > 1 {
+		priority += ratio
+		shouldQ = true
+	}
 	return
 }
 
@@ -116,10 +129,79 @@ func (sq *splitQueue) process(now roachpb.Timestamp, rng *Replica,
 		}); pErr != nil {
 			return pErr.GoError()
 		}
+		return nil
+	}
+
+	// Finally handle case of splitting due to load.
+	if ratio := loadSplitRatio(rng); ratio > 1 {
+		splitKey := loadSplitKey(desc, rng.load.sampledKeys())
+		if splitKey == nil {
+			if log.V(1) {
+				log.Infof("no load-based split key found for %s", rng)
+			}
+			return nil
+		}
+		log.Infof("splitting %s at key %s due to load: %.2f times the qps threshold", rng, splitKey, ratio)
+		if _, pErr := client.SendWrapped(rng, rng.context(), &roachpb.AdminSplitRequest{
+			Span:     roachpb.Span{Key: desc.StartKey.AsRawKey()},
+			SplitKey: splitKey,
+		}); pErr != nil {
+			return pErr.GoError()
+		}
+		rng.store.metrics.loadSplitCount.Inc(1)
 	}
 	return nil
 }
 
+// loadSplitRatio returns the ratio of the request rate of the range to the
+// load-based split threshold of its store, or zero if load-based splitting
+// is disabled or the rate has not been measured for long enough.
+func loadSplitRatio(rng *Replica) float64 {
+	threshold := rng.store.ctx.LoadSplitQPSThreshold
+	if threshold <= 0 || rng.load.age() < loadSplitMinAge {
+		return 0
+	}
+	return rng.load.qps() / float64(threshold)
+}
+
+// loadSplitKey chooses the median of the sampled request keys as the key
+// at which to split a range due to load. Keys which are not valid split
+// keys within the range are ignored. Returns nil if no key would leave
+// sampled requests on both sides of the split.
+func loadSplitKey(desc *roachpb.RangeDescriptor, sampledKeys []roachpb.Key) roachpb.Key {
+	var candidates []roachpb.Key
+	for _, key := range sampledKeys {
+		if !keys.Addr(key).Equal(key) {
+			// Range-local keys are addressed by the key they are local to.
+			continue
+		}
+		splitKey, err := keys.MakeSplitKey(key)
+		if err != nil || !engine.IsValidSplitKey(splitKey) {
+			continue
+		}
+		if rk := roachpb.RKey(splitKey); !desc.StartKey.Less(rk) || !rk.Less(desc.EndKey) {
+			continue
+		}
+		candidates = append(candidates, splitKey)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	sort.Sort(keySlice(candidates))
+	splitKey := candidates[len(candidates)/2]
+	if candidates[0].Equal(splitKey) {
+		// All requests would end up in the new range.
+		return nil
+	}
+	return splitKey
+}
+
+type keySlice []roachpb.Key
+
+func (k keySlice) Len() int           { return len(k) }
+func (k keySlice) Swap(i, j int)      { k[i], k[j] = k[j], k[i] }
+func (k keySlice) Less(i, j int) bool { return bytes.Compare(k[i], k[j]) < 0 }
+
 // timer returns interval between processing successive queued splits.
 func (*splitQueue) timer() time.Duration {
 	return splitQueueTimerDuration
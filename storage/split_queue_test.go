@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/keys"
 	"github.com/cockroachdb/cockroach/roachpb"
 	"github.com/cockroachdb/cockroach/storage/engine"
+	"github.com/cockroachdb/cockroach/util/encoding"
 	"github.com/cockroachdb/cockroach/util/leaktest"
 )
 
@@ -98,6 +99,105 @@ func TestSplitQueueShouldQueue(t *testing.T) {
 	}
 }
 
+// TestSplitQueueShouldQueueLoad verifies that ranges whose sustained
+// request rate exceeds the threshold are queued for splitting.
+func TestSplitQueueShouldQueueLoad(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tc := testContext{}
+	ctx := TestStoreContext()
+	ctx.LoadSplitQPSThreshold = 10
+	tc.StartWithStoreContext(t, ctx)
+	defer tc.Stop()
+
+	if err := tc.gossip.AddInfoProto(gossip.KeySystemConfig, &config.SystemConfig{}, 0); err != nil {
+		t.Fatal(err)
+	}
+	cfg := tc.gossip.GetSystemConfig()
+	if cfg == nil {
+		t.Fatal("nil config")
+	}
+
+	copy := *tc.rng.Desc()
+	copy.StartKey = roachpb.RKey("a")
+	copy.EndKey = roachpb.RKey("b")
+	if err := tc.rng.setDesc(&copy); err != nil {
+		t.Fatal(err)
+	}
+	splitQ := newSplitQueue(nil, tc.gossip)
+
+	tc.rng.load.reset()
+	for i := 0; i < 1000; i++ {
+		tc.rng.load.record(roachpb.Key("a"))
+	}
+	// A burst of requests is not enough to split.
+	if shouldQ, _ := splitQ.shouldQueue(roachpb.ZeroTimestamp, tc.rng, cfg); shouldQ {
+		t.Errorf("expected range not to be queued before %s", loadSplitMinAge)
+	}
+
+	// 1000 requests over loadSplitMinAge are 100 qps, 10 times the
+	// threshold.
+	tc.manualClock.Increment(int64(loadSplitMinAge))
+	shouldQ, priority := splitQ.shouldQueue(roachpb.ZeroTimestamp, tc.rng, cfg)
+	if !shouldQ || math.Abs(priority-10) > 0.00001 {
+		t.Errorf("expected range to be queued with priority 10; got %t, %f", shouldQ, priority)
+	}
+
+	// Load-based splitting can be disabled.
+	tc.store.ctx.LoadSplitQPSThreshold = 0
+	if shouldQ, _ := splitQ.shouldQueue(roachpb.ZeroTimestamp, tc.rng, cfg); shouldQ {
+		t.Error("expected range not to be queued with load-based splitting disabled")
+	}
+}
+
+// TestLoadSplitKey verifies the choice of split keys from sampled request
+// keys.
+func TestLoadSplitKey(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	desc := &roachpb.RangeDescriptor{StartKey: roachpb.RKey("b"), EndKey: roachpb.RKey("y")}
+	sample := func(keyStrs ...string) []roachpb.Key {
+		var sampled []roachpb.Key
+		for _, k := range keyStrs {
+			sampled = append(sampled, roachpb.Key(k))
+		}
+		return sampled
+	}
+
+	testCases := []struct {
+		sampled  []roachpb.Key
+		expected roachpb.Key
+	}{
+		// No samples.
+		{nil, nil},
+		// The median is chosen regardless of the order of samples.
+		{sample("e", "c", "d"), roachpb.Key("d")},
+		{sample("c", "d", "e", "f"), roachpb.Key("e")},
+		// Samples outside of the range and at its start key are ignored.
+		{sample("a", "b", "c", "d", "z"), roachpb.Key("d")},
+		// A single hot key cannot be split off from itself.
+		{sample("c", "c", "c"), nil},
+		// Range-local keys are ignored.
+		{append(sample("c", "d"), keys.RangeDescriptorKey(roachpb.RKey("x"))), roachpb.Key("d")},
+	}
+	for i, test := range testCases {
+		if splitKey := loadSplitKey(desc, test.sampled); !splitKey.Equal(test.expected) {
+			t.Errorf("%d: expected split key %s; got %s", i, test.expected, splitKey)
+		}
+	}
+
+	// Split keys do not fall in the middle of SQL rows.
+	tableKey := keys.MakeTablePrefix(50)
+	var sampled []roachpb.Key
+	for i := 0; i < 3; i++ {
+		row := encoding.EncodeUvarintAscending(append([]byte(nil), tableKey...), uint64(i))
+		sampled = append(sampled, keys.MakeColumnKey(row, 1))
+	}
+	desc = &roachpb.RangeDescriptor{StartKey: roachpb.RKey(tableKey), EndKey: roachpb.RKeyMax}
+	expected := encoding.EncodeUvarintAscending(append([]byte(nil), tableKey...), 1)
+	if splitKey := loadSplitKey(desc, sampled); !splitKey.Equal(expected) {
+		t.Errorf("expected split key %s; got %s", roachpb.Key(expected), splitKey)
+	}
+}
+
 ////
 // NOTE: tests which actually verify processing of the split queue are
 // in client_split_test.go, which is in a different test package in
@@ -17,12 +17,21 @@
 package storage
 
 import (
+	"os"
 	"sync"
 
 	"github.com/cockroachdb/cockroach/roachpb"
 	"github.com/cockroachdb/cockroach/storage/engine"
 )
 
+// checkStatsDrift enables checking the incrementally maintained stats of
+// ranges against a full recomputation: the derived stats of the new range are
+// verified on splits, and the consistency checker compares each range's
+// stats to a recomputation and repairs any drift it finds. Each check costs
+// as much IO as the recomputation the incremental stats avoid, so this is
+// meant for tests and debugging.
+var checkStatsDrift = os.Getenv("COCKROACH_VERIFY_RANGE_STATS") == "1"
+
 // A rangeStats encapsulates access to a range's stats. Range
 // statistics are maintained on every range operation using
 // stat increments accumulated via MVCCStats structs. Stats are
@@ -145,3 +154,11 @@ func ComputeStatsForRange(d *roachpb.RangeDescriptor, e engine.Engine, nowNanos
 	}
 	return ms, nil
 }
+
+// mvccStatsDelta returns the delta which, when added to the stored stats,
+// yields the recomputed stats, and whether the two differ.
+func mvccStatsDelta(stored, computed engine.MVCCStats) (engine.MVCCStats, bool) {
+	delta := computed
+	delta.Subtract(stored)
+	return delta, delta != engine.MVCCStats{LastUpdateNanos: delta.LastUpdateNanos}
+}
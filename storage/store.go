@@ -19,6 +19,7 @@ package storage
 import (
 	"bytes"
 	"fmt"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -38,6 +39,7 @@ import (
 	"github.com/cockroachdb/cockroach/sql"
 	"github.com/cockroachdb/cockroach/storage/engine"
 	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/admission"
 	"github.com/cockroachdb/cockroach/util/cache"
 	"github.com/cockroachdb/cockroach/util/hlc"
 	"github.com/cockroachdb/cockroach/util/log"
@@ -67,6 +69,18 @@ const (
 
 	raftReqBufferSize = 100
 
+	// writeStallL0FileCountThreshold and
+	// writeStallPendingCompactionBytesThreshold are the number of L0 files
+	// and the compaction backlog at which RocksDB starts slowing down writes
+	// (its level0_slowdown_writes_trigger and
+	// soft_pending_compaction_bytes_limit). Past them, the cluster delays
+	// SQL writes to let compactions catch up.
+	writeStallL0FileCountThreshold            = 20
+	writeStallPendingCompactionBytesThreshold = 64 << 30 // 64 GB
+	// writeStallCheckInterval is the interval at which a store checks
+	// whether its engine is stalling writes.
+	writeStallCheckInterval = time.Second
+
 	opStore = "store"
 )
 
@@ -260,6 +274,7 @@ type Store struct {
 	rangeIDAlloc            *idAllocator             // Range ID allocator
 	gcQueue                 *gcQueue                 // Garbage collection queue
 	splitQueue              *splitQueue              // Range splitting queue
+	mergeQueue              *mergeQueue              // Range merging queue
 	verifyQueue             *verifyQueue             // Checksum verification queue
 	replicateQueue          *replicateQueue          // Replication queue
 	replicaGCQueue          *replicaGCQueue          // Replica GC queue
@@ -270,11 +285,16 @@ type Store struct {
 	metrics                 *storeMetrics
 	wakeRaftLoop            chan struct{}
 	started                 int32
+	writeStalled            int32     // Whether the engine is stalling writes; accessed atomically
+	load                    storeLoad // Paces the scanners according to the load of the store
 	stopper                 *stop.Stopper
 	startedAt               int64
 	nodeDesc                *roachpb.NodeDescriptor
 	initComplete            sync.WaitGroup // Signaled by async init tasks
 	raftRequestChan         chan *RaftMessageRequest
+	admission               *admission.Queue // Limits concurrently evaluated batches
+	txnWaits                *txnWaitGraph    // Transactions waiting on intents
+	txnReaper               *txnReaper       // Cleans up after expired transactions
 
 	// Locking notes: To avoid deadlocks, the following lock order
 	// must be obeyed: processRaftMu < Store.mu.Mutex <
@@ -362,10 +382,43 @@ type StoreContext struct {
 	// Tracer is a request tracer.
 	Tracer opentracing.Tracer
 
+	// SlowRequests, if set, retains the traces of requests which are slow
+	// to complete.
+	SlowRequests *tracing.SlowRequests
+
 	// If LogRangeEvents is true, major changes to ranges will be logged into
 	// the range event log.
 	LogRangeEvents bool
 
+	// MaxConcurrentRequests is the maximum number of batches the store
+	// evaluates concurrently. Batches beyond the limit are queued and
+	// admitted in the order of their AdmissionClass. A value of zero
+	// disables admission control.
+	MaxConcurrentRequests int
+
+	// LoadSplitQPSThreshold is the request rate above which a range is split
+	// to spread its load. A value of zero disables load-based splitting.
+	LoadSplitQPSThreshold int
+
+	// MergeQueueEnabled enables the merging of adjacent ranges which are
+	// small and receive little load.
+	MergeQueueEnabled bool
+
+	// MergeCooldown is the minimum time after a split before either side
+	// of the split is considered for merging.
+	MergeCooldown time.Duration
+
+	// ClosedTimestampTarget is the lag behind the present at which leaders
+	// close timestamps to further writes. Replicas serve consistent reads
+	// at or below their closed timestamp without holding the leader lease.
+	// A value of zero disables closed timestamps.
+	ClosedTimestampTarget time.Duration
+
+	// ExportDir is the directory under which Export requests may leave
+	// their files. The directory named by a request is interpreted relative
+	// to it. If empty, only exports returning their contents are served.
+	ExportDir string
+
 	TestingMocker StoreTestingMocker
 }
 
@@ -386,6 +439,14 @@ type storeMetrics struct {
 	leaderRangeCount     *metric.Gauge
 	replicatedRangeCount *metric.Gauge
 	availableRangeCount  *metric.Gauge
+	loadSplitCount       *metric.Counter
+	mergeCount           *metric.Counter
+
+	// Transaction metrics.
+	txnDeadlocks      *metric.Counter
+	expiredTxnCount   *metric.Gauge
+	expiredTxnIntents *metric.Gauge
+	txnReaped         *metric.Counter
 
 	// Storage metrics.
 	liveBytes       *metric.Gauge
@@ -404,6 +465,19 @@ type storeMetrics struct {
 	sysBytes        *metric.Gauge
 	sysCount        *metric.Gauge
 
+	// RocksDB metrics.
+	readAmplification      *metric.Gauge
+	pendingCompactionBytes *metric.Gauge
+	l0FileCount            *metric.Gauge
+	writeStalled           *metric.Gauge
+
+	// Scanner metrics.
+	scanPeriod            *metric.Gauge
+	consistencyScanPeriod *metric.Gauge
+
+	// Raft metrics.
+	raftApplyLatency *metric.Histogram
+
 	// Stats for efficient merges.
 	// TODO(mrtracy): This should be removed as part of #4465. This is only
 	// maintained to keep the current structure of StatusSummaries; it would be
@@ -413,29 +487,102 @@ type storeMetrics struct {
 	stats engine.MVCCStats
 }
 
+// storeMetricsMetadata describes the metrics of a store whose values are
+// not plain counts.
+var storeMetricsMetadata = map[string]metric.Metadata{
+	"livebytes":   {Unit: metric.UnitBytes, Help: "Number of bytes of live data (keys plus values)"},
+	"keybytes":    {Unit: metric.UnitBytes, Help: "Number of bytes taken up by keys"},
+	"valbytes":    {Unit: metric.UnitBytes, Help: "Number of bytes taken up by values"},
+	"intentbytes": {Unit: metric.UnitBytes, Help: "Number of bytes in intent KV pairs"},
+	"sysbytes":    {Unit: metric.UnitBytes, Help: "Number of bytes in system KV pairs"},
+	"intentage":   {Unit: metric.UnitSeconds, Help: "Cumulative age of intents"},
+	"gcbytesage":  {Unit: metric.UnitSeconds, Help: "Cumulative age of non-live data"},
+	"lastupdatenanos": {
+		Unit: metric.UnitTimestamp,
+		Help: "Time at which the MVCC statistics were last updated",
+	},
+	"raft.apply.latency": {
+		Unit: metric.UnitNanoseconds,
+		Help: "Latency of applying committed raft commands to the store",
+	},
+	"capacity":           {Unit: metric.UnitBytes, Help: "Total storage capacity"},
+	"capacity.available": {Unit: metric.UnitBytes, Help: "Available storage capacity"},
+	"rocksdb.compactions.pending-bytes": {
+		Unit: metric.UnitBytes,
+		Help: "Estimated number of bytes RocksDB needs to compact",
+	},
+	"rocksdb.l0-files": {
+		Unit: metric.UnitCount,
+		Help: "Number of files in level 0 of RocksDB",
+	},
+	"rocksdb.write-stalled": {
+		Unit: metric.UnitCount,
+		Help: "1 if RocksDB is stalling writes and SQL writes are being delayed, 0 otherwise",
+	},
+	"scanner.period": {
+		Unit: metric.UnitNanoseconds,
+		Help: "Duration of the last complete scan of the replicas by the replica scanner",
+	},
+	"scanner.consistency.period": {
+		Unit: metric.UnitNanoseconds,
+		Help: "Duration of the last complete scan of the replicas by the consistency checker",
+	},
+}
+
+// storeMetricsAggregations are the aggregations of the metrics of a store
+// whose values across the stores of a node are not summed.
+var storeMetricsAggregations = map[string]metric.Aggregation{
+	"lastupdatenanos":            metric.AggregateMax,
+	"rocksdb.read-amplification": metric.AggregateMax,
+	"rocksdb.l0-files":           metric.AggregateMax,
+	"rocksdb.write-stalled":      metric.AggregateMax,
+	"scanner.period":             metric.AggregateMax,
+	"scanner.consistency.period": metric.AggregateMax,
+}
+
 func newStoreMetrics() *storeMetrics {
 	storeRegistry := metric.NewRegistry()
+	for name, metadata := range storeMetricsMetadata {
+		storeRegistry.SetMetadata(name, metadata)
+	}
+	for name, agg := range storeMetricsAggregations {
+		storeRegistry.SetAggregation(name, agg)
+	}
 	return &storeMetrics{
-		registry:             storeRegistry,
-		rangeCount:           storeRegistry.Counter("ranges"),
-		leaderRangeCount:     storeRegistry.Gauge("ranges.leader"),
-		replicatedRangeCount: storeRegistry.Gauge("ranges.replicated"),
-		availableRangeCount:  storeRegistry.Gauge("ranges.available"),
-		liveBytes:            storeRegistry.Gauge("livebytes"),
-		keyBytes:             storeRegistry.Gauge("keybytes"),
-		valBytes:             storeRegistry.Gauge("valbytes"),
-		intentBytes:          storeRegistry.Gauge("intentbytes"),
-		liveCount:            storeRegistry.Gauge("livecount"),
-		keyCount:             storeRegistry.Gauge("keycount"),
-		valCount:             storeRegistry.Gauge("valcount"),
-		intentCount:          storeRegistry.Gauge("intentcount"),
-		intentAge:            storeRegistry.Gauge("intentage"),
-		gcBytesAge:           storeRegistry.Gauge("gcbytesage"),
-		lastUpdateNanos:      storeRegistry.Gauge("lastupdatenanos"),
-		capacity:             storeRegistry.Gauge("capacity"),
-		available:            storeRegistry.Gauge("capacity.available"),
-		sysBytes:             storeRegistry.Gauge("sysbytes"),
-		sysCount:             storeRegistry.Gauge("syscount"),
+		registry:               storeRegistry,
+		rangeCount:             storeRegistry.Counter("ranges"),
+		leaderRangeCount:       storeRegistry.Gauge("ranges.leader"),
+		replicatedRangeCount:   storeRegistry.Gauge("ranges.replicated"),
+		availableRangeCount:    storeRegistry.Gauge("ranges.available"),
+		loadSplitCount:         storeRegistry.Counter("ranges.loadsplits"),
+		mergeCount:             storeRegistry.Counter("ranges.merges"),
+		liveBytes:              storeRegistry.Gauge("livebytes"),
+		keyBytes:               storeRegistry.Gauge("keybytes"),
+		valBytes:               storeRegistry.Gauge("valbytes"),
+		intentBytes:            storeRegistry.Gauge("intentbytes"),
+		liveCount:              storeRegistry.Gauge("livecount"),
+		keyCount:               storeRegistry.Gauge("keycount"),
+		valCount:               storeRegistry.Gauge("valcount"),
+		intentCount:            storeRegistry.Gauge("intentcount"),
+		intentAge:              storeRegistry.Gauge("intentage"),
+		gcBytesAge:             storeRegistry.Gauge("gcbytesage"),
+		lastUpdateNanos:        storeRegistry.Gauge("lastupdatenanos"),
+		capacity:               storeRegistry.Gauge("capacity"),
+		available:              storeRegistry.Gauge("capacity.available"),
+		sysBytes:               storeRegistry.Gauge("sysbytes"),
+		sysCount:               storeRegistry.Gauge("syscount"),
+		readAmplification:      storeRegistry.Gauge("rocksdb.read-amplification"),
+		pendingCompactionBytes: storeRegistry.Gauge("rocksdb.compactions.pending-bytes"),
+		l0FileCount:            storeRegistry.Gauge("rocksdb.l0-files"),
+		writeStalled:           storeRegistry.Gauge("rocksdb.write-stalled"),
+		scanPeriod:             storeRegistry.Gauge("scanner.period"),
+		consistencyScanPeriod:  storeRegistry.Gauge("scanner.consistency.period"),
+		txnDeadlocks:           storeRegistry.Counter("txn.deadlocks"),
+		expiredTxnCount:        storeRegistry.Gauge("txn.expired"),
+		expiredTxnIntents:      storeRegistry.Gauge("txn.expired.intents"),
+		txnReaped:              storeRegistry.Counter("txn.reaped"),
+		raftApplyLatency: storeRegistry.Histogram("raft.apply.latency",
+			time.Minute, int64(10*time.Second), 2),
 	}
 }
 
@@ -461,11 +608,19 @@ func (sm *storeMetrics) updateMVCCGaugesLocked() {
 	sm.sysCount.Update(sm.stats.SysCount)
 }
 
-func (sm *storeMetrics) updateCapacityGauges(capacity roachpb.StoreCapacity) {
+func (sm *storeMetrics) updateCapacityGauges(capacity roachpb.StoreCapacity, writeStalled bool) {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 	sm.capacity.Update(capacity.Capacity)
 	sm.available.Update(capacity.Available)
+	sm.readAmplification.Update(int64(capacity.ReadAmplification))
+	sm.pendingCompactionBytes.Update(capacity.PendingCompactionBytes)
+	sm.l0FileCount.Update(int64(capacity.L0FileCount))
+	if writeStalled {
+		sm.writeStalled.Update(1)
+	} else {
+		sm.writeStalled.Update(0)
+	}
 }
 
 func (sm *storeMetrics) updateReplicationGauges(leaders, replicated, available int64) {
@@ -535,6 +690,9 @@ func NewStore(ctx StoreContext, eng engine.Engine, nodeDesc *roachpb.NodeDescrip
 		wakeRaftLoop:    make(chan struct{}, 1),
 		raftRequestChan: make(chan *RaftMessageRequest, raftReqBufferSize),
 		metrics:         newStoreMetrics(),
+		admission:       admission.NewQueue(ctx.MaxConcurrentRequests, nil),
+		txnWaits:        newTxnWaitGraph(),
+		txnReaper:       newTxnReaper(),
 	}
 
 	s.mu.Lock()
@@ -555,16 +713,30 @@ func NewStore(ctx StoreContext, eng engine.Engine, nodeDesc *roachpb.NodeDescrip
 	s.scanner = newReplicaScanner(ctx.ScanInterval, ctx.ScanMaxIdleTime, newStoreRangeSet(s))
 	s.gcQueue = newGCQueue(s.ctx.Gossip)
 	s.splitQueue = newSplitQueue(s.db, s.ctx.Gossip)
+	s.mergeQueue = newMergeQueue(s.db, s.ctx.Gossip)
 	s.verifyQueue = newVerifyQueue(s.ctx.Gossip, s.ReplicaCount)
 	s.replicateQueue = newReplicateQueue(s.ctx.Gossip, s.allocator, s.ctx.Clock, s.ctx.AllocatorOptions)
 	s.replicaGCQueue = newReplicaGCQueue(s.db, s.ctx.Gossip)
 	s.raftLogQueue = newRaftLogQueue(s.db, s.ctx.Gossip)
-	s.scanner.AddQueues(s.gcQueue, s.splitQueue, s.verifyQueue, s.replicateQueue, s.replicaGCQueue, s.raftLogQueue)
+	s.scanner.AddQueues(s.gcQueue, s.splitQueue, s.mergeQueue, s.verifyQueue, s.replicateQueue,
+		s.replicaGCQueue, s.raftLogQueue)
 
 	// Add consistency check scanner.
 	s.consistencyScanner = newReplicaScanner(ctx.ConsistencyCheckInterval, ctx.ScanMaxIdleTime, newStoreRangeSet(s))
 	s.replicaConsistencyQueue = newReplicaConsistencyQueue(s.ctx.Gossip)
 	s.consistencyScanner.AddQueues(s.replicaConsistencyQueue)
+	for _, bq := range []*baseQueue{
+		&s.gcQueue.baseQueue, &s.splitQueue.baseQueue, &s.mergeQueue.baseQueue,
+		&s.verifyQueue.baseQueue, &s.replicateQueue.baseQueue, &s.replicaGCQueue.baseQueue,
+		&s.raftLogQueue.baseQueue, &s.replicaConsistencyQueue.baseQueue,
+	} {
+		s.metrics.registry.MustAdd("queue."+bq.metricsName()+".%s", bq.metrics.registry)
+	}
+	s.load.now = s.ctx.Clock.PhysicalNow
+	s.scanner.slowdown = s.load.scanSlowdown
+	s.scanner.period = s.metrics.scanPeriod
+	s.consistencyScanner.slowdown = s.load.scanSlowdown
+	s.consistencyScanner.period = s.metrics.consistencyScanPeriod
 
 	return s
 }
@@ -604,6 +776,7 @@ func (s *Store) Start(stopper *stop.Stopper) error {
 	s.stopper.AddCloser(stop.CloserFn(func() {
 		s.gcQueue.Close()
 		s.splitQueue.Close()
+		s.mergeQueue.Close()
 		s.verifyQueue.Close()
 		s.replicateQueue.Close()
 		s.replicaGCQueue.Close()
@@ -654,7 +827,7 @@ func (s *Store) Start(stopper *stop.Stopper) error {
 	// due to a split crashing halfway will simply be resolved on the
 	// next split attempt. They can otherwise be ignored.
 	s.mu.Lock()
-	_, err = engine.MVCCIterate(s.engine, start, end, now, false /* !consistent */, nil, /* txn */
+	_, err = engine.MVCCIterate(context.Background(), s.engine, start, end, now, false /* !consistent */, nil, /* txn */
 		false /* !reverse */, func(kv roachpb.KeyValue) (bool, error) {
 			// Only consider range metadata entries; ignore others.
 			_, suffix, _, err := keys.DecodeRangeKey(kv.Key)
@@ -706,6 +879,11 @@ func (s *Store) Start(stopper *stop.Stopper) error {
 	s.ctx.Transport.Listen(s.StoreID(), s.enqueueRaftMessage)
 	s.processRaft()
 
+	// Start closing the timestamps of the ranges which receive no commands.
+	if target := s.ctx.ClosedTimestampTarget; target > 0 {
+		s.startCloseTimestamps(target)
+	}
+
 	// Gossip is only ever nil while bootstrapping a cluster and
 	// in unittests.
 	if s.ctx.Gossip != nil {
@@ -731,6 +909,15 @@ func (s *Store) Start(stopper *stop.Stopper) error {
 		// running.
 		s.startGossip()
 
+		// Start gossiping transaction waits and detecting deadlocks.
+		s.startTxnWaitDetection()
+
+		// Start cleaning up after expired transactions.
+		s.startTxnReaper()
+
+		// Start checking whether the engine stalls writes.
+		s.startWriteStallCheck()
+
 		// Start the scanner. The construction here makes sure that the scanner
 		// only starts after Gossip has connected, and that it does not block Start
 		// from returning (as doing so might prevent Gossip from ever connecting).
@@ -761,6 +948,28 @@ func (s *Store) Start(stopper *stop.Stopper) error {
 	return nil
 }
 
+// startCloseTimestamps runs a worker which closes the timestamps of the
+// replicas holding the leader lease whose ranges received no commands
+// recently, so that followers can serve reads at recent timestamps on idle
+// ranges too.
+func (s *Store) startCloseTimestamps(target time.Duration) {
+	s.stopper.RunWorker(func() {
+		ticker := time.NewTicker(target)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				newStoreRangeSet(s).Visit(func(r *Replica) bool {
+					r.maybeCloseTimestamp(target)
+					return true
+				})
+			case <-s.stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
 // WaitForInit waits for any asynchronous processes begun in Start()
 // to complete their initialization. In particular, this includes
 // gossiping. In some cases this may block until the range GC queue
@@ -817,6 +1026,56 @@ func (s *Store) startGossip() {
 	})
 }
 
+// startTxnWaitDetection subscribes to the transaction waits gossiped by
+// other stores and runs a goroutine which periodically gossips the local
+// waits and aborts transactions which are part of a deadlock.
+func (s *Store) startTxnWaitDetection() {
+	s.ctx.Gossip.RegisterCallback(gossip.MakePrefixPattern(gossip.KeyTxnWaitsPrefix), s.txnWaitsGossipUpdate)
+	s.stopper.RunWorker(func() {
+		ticker := time.NewTicker(txnWaitGossipInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.detectTxnDeadlocks()
+			case <-s.stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
+// txnWaitsGossipUpdate is the gossip callback used to keep track of the
+// transaction waits of other stores.
+func (s *Store) txnWaitsGossipUpdate(_ string, content roachpb.Value) {
+	var waits roachpb.TxnWaits
+	if err := content.GetProto(&waits); err != nil {
+		log.Error(err)
+		return
+	}
+	if waits.StoreID == s.StoreID() {
+		return
+	}
+	s.txnWaits.updateRemote(waits, s.Clock().PhysicalNow())
+}
+
+// detectTxnDeadlocks gossips the local transaction waits if they changed or
+// are still pending, and aborts the local waiters of transactions chosen
+// to break a deadlock.
+func (s *Store) detectTxnDeadlocks() {
+	ctx := s.Context(nil)
+	waits, dirty := s.txnWaits.localWaits(s.StoreID())
+	if dirty || len(waits.Waits) > 0 {
+		if err := s.ctx.Gossip.AddInfoProto(gossip.MakeTxnWaitsKey(s.StoreID()), &waits, txnWaitGossipTTL); err != nil {
+			log.Warningc(ctx, "error gossiping txn waits: %s", err)
+		}
+	}
+	for _, txn := range s.txnWaits.abortDeadlocked(s.StoreID(), s.Clock().PhysicalNow()) {
+		s.metrics.txnDeadlocks.Inc(1)
+		log.Infoc(ctx, "aborting txn %s to break deadlock", txn.ID)
+	}
+}
+
 // maybeGossipFirstRange checks whether the store has a replica of the
 // first range and if so instructs it to gossip the cluster ID,
 // sentinel gossip and first range descriptor. This is done in a retry
@@ -891,6 +1150,34 @@ func (s *Store) GossipStore() {
 	if err := s.ctx.Gossip.AddInfoProto(gossipStoreKey, storeDesc, ttlStoreGossip); err != nil {
 		log.Warningc(ctx, "%s", err)
 	}
+	// Refresh the write stall state before it expires.
+	s.GossipWriteStalled()
+}
+
+// GossipWriteStalled gossips whether the engine of the store is stalling
+// writes, so that the SQL gateways of the cluster delay writes meanwhile.
+func (s *Store) GossipWriteStalled() {
+	ctx := s.Context(nil)
+	val := []byte{0}
+	if s.WriteStalled() {
+		val[0] = 1
+	}
+	if err := s.ctx.Gossip.AddInfo(gossip.MakeStoreWriteStalledKey(s.StoreID()), val, ttlStoreGossip); err != nil {
+		log.Warningc(ctx, "%s", err)
+	}
+}
+
+// GossipDraining gossips whether the store is draining, so that the
+// DistSenders of the cluster try its replicas last while it shuts down.
+func (s *Store) GossipDraining(draining bool) {
+	ctx := s.Context(nil)
+	val := []byte{0}
+	if draining {
+		val[0] = 1
+	}
+	if err := s.ctx.Gossip.AddInfo(gossip.MakeStoreDrainingKey(s.StoreID()), val, ttlStoreGossip); err != nil {
+		log.Warningc(ctx, "%s", err)
+	}
 }
 
 // Bootstrap writes a new store ident to the underlying engine. To
@@ -1155,6 +1442,8 @@ func (s *Store) SplitRange(origRng, newRng *Replica) error {
 	copyDesc := *origDesc
 	copyDesc.EndKey = append([]byte(nil), newDesc.StartKey...)
 	origRng.setDescWithoutProcessUpdate(&copyDesc)
+	// The load of the original range is now shared with the new range.
+	origRng.load.reset()
 
 	if s.mu.replicasByKey.ReplaceOrInsert(origRng) != nil {
 		return util.Errorf("couldn't insert range %v in rangesByKey btree", origRng)
@@ -1417,6 +1706,26 @@ func (s *Store) Descriptor() (*roachpb.StoreDescriptor, error) {
 	}, nil
 }
 
+// CaughtUp returns true if every replica of the store applied all the
+// commands it knows to be committed and, unless it is quiescent, knows the
+// leader of its range, as is the case once the store caught up with its
+// ranges after a restart. A replica is quiescent while it has no pending
+// commands; an idle range may well have no leader. Replicas which were
+// removed from their range and await GC are ignored.
+func (s *Store) CaughtUp() bool {
+	caughtUp := true
+	newStoreRangeSet(s).Visit(func(r *Replica) bool {
+		if _, repDesc := r.Desc().FindReplica(s.StoreID()); repDesc == nil {
+			return true
+		}
+		status := r.RaftStatus()
+		caughtUp = status != nil && status.Applied >= status.Commit &&
+			(status.Lead != raft.None || !r.hasPendingCmds())
+		return caughtUp
+	})
+	return caughtUp
+}
+
 // ReplicaCount returns the number of replicas contained by this store.
 func (s *Store) ReplicaCount() int {
 	s.mu.Lock()
@@ -1424,6 +1733,34 @@ func (s *Store) ReplicaCount() int {
 	return len(s.mu.replicas)
 }
 
+// HotReplicas returns up to n replicas of this store with the highest
+// request rates, hottest first. If withKeys is true, the sampled request
+// keys of each replica are included.
+func (s *Store) HotReplicas(n int, withKeys bool) []HotReplica {
+	s.mu.Lock()
+	hot := make([]HotReplica, 0, len(s.mu.replicas))
+	for _, rng := range s.mu.replicas {
+		desc := rng.Desc()
+		h := HotReplica{
+			RangeID:  rng.RangeID,
+			StartKey: desc.StartKey,
+			EndKey:   desc.EndKey,
+			QPS:      rng.load.qps(),
+		}
+		if withKeys {
+			h.SampledKeys = rng.load.sampledKeys()
+		}
+		hot = append(hot, h)
+	}
+	s.mu.Unlock()
+
+	sort.Sort(hotReplicasByQPS(hot))
+	if len(hot) > n {
+		hot = hot[:n]
+	}
+	return hot
+}
+
 // Send fetches a range based on the header's replica, assembles method, args &
 // reply into a Raft Cmd struct and executes the command using the fetched
 // range.
@@ -1437,6 +1774,8 @@ func (s *Store) ReplicaCount() int {
 // a transaction set which should be used to update the client transaction.
 func (s *Store) Send(ctx context.Context, ba roachpb.BatchRequest) (br *roachpb.BatchResponse, pErr *roachpb.Error) {
 	ctx = s.Context(ctx)
+	ctx, finishTrace := s.ctx.SlowRequests.Trace(ctx, s.Tracer(), opStore)
+	defer finishTrace()
 	sp, cleanupSp := tracing.SpanFromContext(opStore, s.Tracer(), ctx)
 	defer cleanupSp()
 
@@ -1448,6 +1787,10 @@ func (s *Store) Send(ctx context.Context, ba roachpb.BatchRequest) (br *roachpb.
 		}
 	}
 
+	var drain <-chan struct{}
+	if s.stopper != nil {
+		drain = s.stopper.ShouldDrain()
+	}
 	if ba.Txn == nil {
 		// When not transactional, allow empty timestamp and simply use local
 		// clock.
@@ -1550,6 +1893,21 @@ func (s *Store) Send(ctx context.Context, ba roachpb.BatchRequest) (br *roachpb.
 	s.mu.Lock()
 	retryOpts := s.ctx.RangeRetryOptions
 	s.mu.Unlock()
+
+	// A transactional request which is blocked on the intents of other
+	// transactions is recorded in the store's wait graph, so that it can be
+	// aborted if it turns out to be part of a deadlock.
+	var waiter *txnWaiter
+	if ba.Txn != nil && ba.Txn.ID != nil && retryOpts.Closer == nil {
+		waiter = &txnWaiter{
+			pusher:   ba.Txn.TxnMeta,
+			priority: ba.Txn.Priority,
+			abort:    make(chan struct{}),
+		}
+		retryOpts.Closer = waiter.abort
+		defer s.txnWaits.done(waiter)
+	}
+
 	for r := retry.Start(retryOpts); next(&r); {
 		// Get range and add command to the range for execution.
 		var err error
@@ -1559,8 +1917,17 @@ func (s *Store) Send(ctx context.Context, ba roachpb.BatchRequest) (br *roachpb.
 			return nil, pErr
 		}
 
+		// Wait for admission if the store is overloaded. Background work is
+		// delayed in favor of foreground traffic. Only the evaluation of the
+		// batch holds a slot: the backoff, pushes and intent resolution below
+		// send batches to the store themselves, which could otherwise wait
+		// for a slot forever once all of them are taken.
+		if err := s.admission.Admit(ctx, ba.AdmissionClass, drain); err != nil {
+			return nil, roachpb.NewError(err)
+		}
 		var br *roachpb.BatchResponse
 		br, pErr = rng.Send(ctx, ba)
+		s.admission.Release()
 		if pErr == nil {
 			return br, nil
 		}
@@ -1631,6 +1998,9 @@ func (s *Store) Send(ctx context.Context, ba roachpb.BatchRequest) (br *roachpb.
 					ba.Timestamp = intent.Txn.Timestamp.Next()
 				}
 			}
+			if waiter != nil {
+				s.txnWaits.wait(waiter, t.Intents, s.Clock().PhysicalNow())
+			}
 			if log.V(1) {
 				log.Warning(pErr)
 			}
@@ -1639,6 +2009,15 @@ func (s *Store) Send(ctx context.Context, ba roachpb.BatchRequest) (br *roachpb.
 		return nil, pErr
 	}
 
+	if waiter != nil {
+		select {
+		case <-waiter.abort:
+			sp.LogEvent("aborted to break deadlock")
+			return nil, roachpb.NewErrorWithTxn(roachpb.NewTransactionAbortedError(), ba.Txn)
+		default:
+		}
+	}
+
 	// By default, retries are indefinite. However, some unittests set a
 	// maximum retry count; return txn retry error for transactional cases
 	// and the original error otherwise.
@@ -1661,11 +2040,14 @@ func (s *Store) Send(ctx context.Context, ba roachpb.BatchRequest) (br *roachpb.
 //
 // Callers are involved with
 // a) conflict resolution for commands being executed at the Store with the
-//    client waiting,
+//
+//	client waiting,
+//
 // b) resolving intents encountered during inconsistent operations, and
 // c) resolving intents upon EndTransaction which are not local to the given
-//    range. This is the only path in which the transaction is going to be
-//    in non-pending state and doesn't require a push.
+//
+//	range. This is the only path in which the transaction is going to be
+//	in non-pending state and doesn't require a push.
 func (s *Store) resolveWriteIntentError(ctx context.Context, wiErr *roachpb.WriteIntentError, rng *Replica, args roachpb.Request, h roachpb.Header, pushType roachpb.PushTxnType) ([]roachpb.Intent, *roachpb.Error) {
 	method := args.Method()
 	pusherTxn := h.Txn
@@ -1822,6 +2204,9 @@ func (s *Store) handleRaftMessage(req *RaftMessageRequest) error {
 	if err != nil {
 		return err
 	}
+	if r.handleLearnerMessage(req) {
+		return nil
+	}
 	r.mu.Lock()
 	err = r.mu.raftGroup.Step(req.Message)
 	r.mu.Unlock()
@@ -2124,7 +2509,9 @@ func (s *Store) ComputeMetrics() error {
 	if err != nil {
 		return err
 	}
-	s.metrics.updateCapacityGauges(desc.Capacity)
+	stalled := s.updateWriteStalled(desc.Capacity)
+	s.metrics.updateCapacityGauges(desc.Capacity, stalled)
+	s.load.updateCapacity(desc.Capacity)
 
 	// broadcast replication status.
 	now := s.ctx.Clock.Now().WallTime
@@ -2134,6 +2521,67 @@ func (s *Store) ComputeMetrics() error {
 	return nil
 }
 
+// writeStalled returns whether an engine with the given capacity is
+// stalling writes, i.e. whether it has accumulated more L0 files or a
+// larger compaction backlog than RocksDB tolerates before slowing down
+// writes.
+func writeStalled(capacity roachpb.StoreCapacity) bool {
+	return capacity.L0FileCount >= writeStallL0FileCountThreshold ||
+		capacity.PendingCompactionBytes >= writeStallPendingCompactionBytesThreshold
+}
+
+// updateWriteStalled records whether the engine, with the given capacity,
+// is stalling writes, and gossips it when that changes so that the SQL
+// gateways of the cluster start or stop delaying writes right away.
+func (s *Store) updateWriteStalled(capacity roachpb.StoreCapacity) bool {
+	stalled := writeStalled(capacity)
+	var val int32
+	if stalled {
+		val = 1
+	}
+	if atomic.SwapInt32(&s.writeStalled, val) == val {
+		return stalled
+	}
+	if stalled {
+		log.Warningf("store %s: engine is stalling writes (%d L0 files, %d bytes pending compaction)",
+			s, capacity.L0FileCount, capacity.PendingCompactionBytes)
+	} else {
+		log.Infof("store %s: engine is no longer stalling writes", s)
+	}
+	if s.ctx.Gossip != nil {
+		s.GossipWriteStalled()
+	}
+	return stalled
+}
+
+// WriteStalled returns whether the engine of the store was stalling writes
+// when it was last checked.
+func (s *Store) WriteStalled() bool {
+	return atomic.LoadInt32(&s.writeStalled) == 1
+}
+
+// startWriteStallCheck runs a worker which checks whether the engine is
+// stalling writes more often than the store metrics are computed.
+func (s *Store) startWriteStallCheck() {
+	s.stopper.RunWorker(func() {
+		ticker := time.NewTicker(writeStallCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				capacity, err := s.engine.Capacity()
+				if err != nil {
+					log.Warningc(s.Context(nil), "could not check whether the engine stalls writes: %s", err)
+					continue
+				}
+				s.updateWriteStalled(capacity)
+			case <-s.stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
 // ComputeMVCCStatsTest immediately computes correct total MVCC usage statistics
 // for the store, returning the computed values (but without modifying the
 // store). This is intended for use only by unit tests.
@@ -0,0 +1,113 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/roachpb"
+)
+
+const (
+	// storeLoadLatencyWeight is the weight of each new sample in the moving
+	// average of the evaluation latency of foreground batches.
+	storeLoadLatencyWeight = 0.1
+	// storeLoadLatencyHalfLife is the time over which the moving average
+	// of the latency halves in the absence of foreground traffic, so that a
+	// burst of slow batches doesn't slow the scanners down indefinitely
+	// once the store goes idle.
+	storeLoadLatencyHalfLife = 10 * time.Second
+	// scanSlowdownLatencyThreshold is the average latency of foreground
+	// batches past which the replica scanners slow down proportionally.
+	scanSlowdownLatencyThreshold = 50 * time.Millisecond
+	// maxScanSlowdown bounds the factor by which the replica scanners slow
+	// down under load, so that the queues still get to see every replica.
+	maxScanSlowdown = 10
+)
+
+// A storeLoad tracks the IO utilization of a store's engine and the
+// evaluation latency of the foreground batches it serves, from which the replica
+// scanners derive how much to slow down so as to leave the store's
+// resources to foreground traffic.
+type storeLoad struct {
+	now func() int64 // Wall time in nanoseconds.
+
+	mu struct {
+		sync.Mutex
+		// ioUtilization is the fraction of the thresholds at which RocksDB
+		// stalls writes reached by the engine when the capacity was last
+		// computed.
+		ioUtilization float64
+		// latency is the exponentially weighted moving average of the
+		// evaluation latency of foreground batches, as of lastUpdate.
+		latency    time.Duration
+		lastUpdate int64
+	}
+}
+
+// updateCapacity updates the IO utilization from the given capacity of the
+// engine.
+func (l *storeLoad) updateCapacity(capacity roachpb.StoreCapacity) {
+	utilization := float64(capacity.L0FileCount) / writeStallL0FileCountThreshold
+	if u := float64(capacity.PendingCompactionBytes) / writeStallPendingCompactionBytesThreshold; u > utilization {
+		utilization = u
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.mu.ioUtilization = utilization
+}
+
+// decayLatencyLocked decays the moving average of the latency for the time
+// elapsed since it was last updated.
+func (l *storeLoad) decayLatencyLocked() {
+	now := l.now()
+	if elapsed := now - l.mu.lastUpdate; elapsed > 0 {
+		l.mu.latency = time.Duration(float64(l.mu.latency) *
+			math.Exp2(-float64(elapsed)/float64(storeLoadLatencyHalfLife)))
+	}
+	l.mu.lastUpdate = now
+}
+
+// recordLatency records the evaluation latency of a foreground batch.
+func (l *storeLoad) recordLatency(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.decayLatencyLocked()
+	l.mu.latency += time.Duration(storeLoadLatencyWeight * float64(d-l.mu.latency))
+}
+
+// scanSlowdown returns the factor by which the replica scanners stretch
+// their scan interval. The scanners run at full pace while the engine is
+// under half of the thresholds at which RocksDB stalls writes and the
+// foreground latency is under scanSlowdownLatencyThreshold, and slow down
+// proportionally past them, by up to maxScanSlowdown.
+func (l *storeLoad) scanSlowdown() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.decayLatencyLocked()
+	slowdown := 1.0
+	if s := 2 * l.mu.ioUtilization; s > slowdown {
+		slowdown = s
+	}
+	if s := float64(l.mu.latency) / float64(scanSlowdownLatencyThreshold); s > slowdown {
+		slowdown = s
+	}
+	if slowdown > maxScanSlowdown {
+		slowdown = maxScanSlowdown
+	}
+	return slowdown
+}
@@ -0,0 +1,77 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/util/hlc"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+func TestStoreLoadScanSlowdown(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	manual := hlc.NewManualClock(int64(time.Hour))
+	l := storeLoad{now: manual.UnixNano}
+	if s := l.scanSlowdown(); s != 1 {
+		t.Errorf("expected no slowdown of an idle store, got %f", s)
+	}
+
+	// The scanners slow down once the engine is past half of the write
+	// stall thresholds.
+	l.updateCapacity(roachpb.StoreCapacity{L0FileCount: writeStallL0FileCountThreshold / 4})
+	if s := l.scanSlowdown(); s != 1 {
+		t.Errorf("expected no slowdown, got %f", s)
+	}
+	l.updateCapacity(roachpb.StoreCapacity{L0FileCount: writeStallL0FileCountThreshold})
+	if s := l.scanSlowdown(); s != 2 {
+		t.Errorf("expected a slowdown of 2, got %f", s)
+	}
+	l.updateCapacity(roachpb.StoreCapacity{
+		PendingCompactionBytes: 2 * writeStallPendingCompactionBytesThreshold,
+	})
+	if s := l.scanSlowdown(); s != 4 {
+		t.Errorf("expected a slowdown of 4, got %f", s)
+	}
+	l.updateCapacity(roachpb.StoreCapacity{})
+
+	// The moving average of the foreground latency converges to the
+	// recorded latencies.
+	for i := 0; i < 100; i++ {
+		l.recordLatency(3 * scanSlowdownLatencyThreshold)
+	}
+	if s := l.scanSlowdown(); s < 2.9 || s > 3 {
+		t.Errorf("expected a slowdown of about 3, got %f", s)
+	}
+	for i := 0; i < 100; i++ {
+		l.recordLatency(time.Second)
+	}
+	if s := l.scanSlowdown(); s != maxScanSlowdown {
+		t.Errorf("expected the slowdown to be capped at %d, got %f", maxScanSlowdown, s)
+	}
+
+	// Without foreground traffic, the moving average decays over time.
+	manual.Increment(int64(2 * storeLoadLatencyHalfLife))
+	if s := l.scanSlowdown(); s < 4.9 || s > 5.1 {
+		t.Errorf("expected a slowdown of about 5 after two half-lives, got %f", s)
+	}
+	manual.Increment(int64(10 * storeLoadLatencyHalfLife))
+	if s := l.scanSlowdown(); s != 1 {
+		t.Errorf("expected no slowdown of an idle store, got %f", s)
+	}
+}
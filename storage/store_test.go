@@ -26,6 +26,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/coreos/etcd/raft"
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/client"
@@ -286,6 +287,53 @@ func createRange(s *Store, rangeID roachpb.RangeID, start, end roachpb.RKey) *Re
 	return r
 }
 
+// TestStoreCaughtUp verifies that neither quiescent replicas without a
+// leader nor removed replicas keep a store from catching up.
+func TestStoreCaughtUp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	store, _, stopper := createTestStore(t)
+	defer stopper.Stop()
+	util.SucceedsSoon(t, func() error {
+		if !store.CaughtUp() {
+			return util.Errorf("store did not catch up")
+		}
+		return nil
+	})
+
+	// A replica whose other member doesn't exist never learns of a leader,
+	// but it has no pending commands.
+	desc := roachpb.RangeDescriptor{
+		RangeID:  2,
+		StartKey: roachpb.RKey("a"),
+		EndKey:   roachpb.RKey("b"),
+		Replicas: []roachpb.ReplicaDescriptor{
+			{NodeID: 1, StoreID: 1, ReplicaID: 1},
+			{NodeID: 2, StoreID: 2, ReplicaID: 2},
+		},
+		NextReplicaID: 3,
+	}
+	rng, err := NewReplica(&desc, store, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AddReplicaTest(rng); err != nil {
+		t.Fatal(err)
+	}
+	if status := rng.RaftStatus(); status.Lead != raft.None {
+		t.Fatalf("expected no leader, got %d", status.Lead)
+	}
+	if !store.CaughtUp() {
+		t.Error("expected a quiescent replica without a leader not to keep the store from catching up")
+	}
+
+	// Neither does a replica which was removed from its range.
+	desc.Replicas = desc.Replicas[1:]
+	rng.setDescWithoutProcessUpdate(&desc)
+	if !store.CaughtUp() {
+		t.Error("expected a removed replica not to keep the store from catching up")
+	}
+}
+
 func TestStoreAddRemoveRanges(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	store, _, stopper := createTestStore(t)
@@ -1024,6 +1072,47 @@ func TestStoreResolveWriteIntent(t *testing.T) {
 	}
 }
 
+// TestStoreAdmissionResolveWriteIntent verifies that a batch which pushes
+// a transaction and resolves its intent doesn't hold its admission slot
+// while doing so, since the push and the resolution wait for admission
+// themselves.
+func TestStoreAdmissionResolveWriteIntent(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := TestStoreContext()
+	ctx.MaxConcurrentRequests = 1
+	store, mc, stopper := createTestStoreWithContext(t, &ctx)
+	defer stopper.Stop()
+
+	key := roachpb.Key("a")
+	pusher := newTransaction("test", key, 1, roachpb.SERIALIZABLE, store.ctx.Clock)
+	pushee := newTransaction("test", key, 1, roachpb.SERIALIZABLE, store.ctx.Clock)
+	pushee.Priority = 1
+	pusher.Priority = 2 // Pusher will win.
+
+	pArgs := putArgs(key, []byte("value"))
+	h := roachpb.Header{Txn: pushee}
+	pushee.Sequence++
+	if _, err := maybeWrapWithBeginTransaction(store.testSender(), nil, h, &pArgs); err != nil {
+		t.Fatal(err)
+	}
+
+	mc.Increment(100)
+	h.Txn = pusher
+	errCh := make(chan *roachpb.Error, 1)
+	go func() {
+		_, pErr := client.SendWrappedWith(store.testSender(), nil, h, &pArgs)
+		errCh <- pErr
+	}()
+	select {
+	case pErr := <-errCh:
+		if pErr != nil {
+			t.Fatalf("expected intent resolved; got unexpected error: %s", pErr)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("resolving the write intent didn't finish")
+	}
+}
+
 // TestStoreResolveWriteIntentRollback verifies that resolving a write
 // intent by aborting it yields the previous value.
 func TestStoreResolveWriteIntentRollback(t *testing.T) {
@@ -1717,3 +1806,53 @@ func TestMaybeRemove(t *testing.T) {
 		t.Errorf("Unexpected removed range %v", removedRng)
 	}
 }
+
+func TestWriteStalled(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		capacity roachpb.StoreCapacity
+		expected bool
+	}{
+		{roachpb.StoreCapacity{}, false},
+		{roachpb.StoreCapacity{L0FileCount: writeStallL0FileCountThreshold - 1}, false},
+		{roachpb.StoreCapacity{L0FileCount: writeStallL0FileCountThreshold}, true},
+		{roachpb.StoreCapacity{PendingCompactionBytes: writeStallPendingCompactionBytesThreshold / 2}, false},
+		{roachpb.StoreCapacity{PendingCompactionBytes: writeStallPendingCompactionBytesThreshold}, true},
+	}
+	for i, tc := range testCases {
+		if stalled := writeStalled(tc.capacity); stalled != tc.expected {
+			t.Errorf("%d: expected stalled=%t for %+v, got %t", i, tc.expected, tc.capacity, stalled)
+		}
+	}
+
+	// An unstalled store doesn't delay writes.
+	store, _, stopper := createTestStore(t)
+	defer stopper.Stop()
+	if err := store.ComputeMetrics(); err != nil {
+		t.Fatal(err)
+	}
+	if store.WriteStalled() {
+		t.Error("expected a fresh store not to stall writes")
+	}
+
+	// A store gossips when its engine starts and stops stalling writes.
+	key := gossip.MakeStoreWriteStalledKey(store.StoreID())
+	for _, stalled := range []bool{true, false} {
+		capacity := roachpb.StoreCapacity{}
+		expected := []byte{0}
+		if stalled {
+			capacity.L0FileCount = writeStallL0FileCountThreshold
+			expected[0] = 1
+		}
+		store.updateWriteStalled(capacity)
+		if store.WriteStalled() != stalled {
+			t.Errorf("expected stalled=%t", stalled)
+		}
+		if val, err := store.Gossip().GetInfo(key); err != nil {
+			t.Error(err)
+		} else if !bytes.Equal(val, expected) {
+			t.Errorf("expected %v to be gossiped, got %v", expected, val)
+		}
+	}
+}
@@ -18,8 +18,10 @@ package storage
 
 import (
 	"fmt"
+	"reflect"
 	"sync"
 
+	opentracing "github.com/opentracing/opentracing-go"
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/client"
@@ -127,6 +129,21 @@ func (ls *Stores) VisitStores(visitor func(s *Store) error) error {
 	return nil
 }
 
+// HoldsLease returns whether one of the stores holds a valid lease at the
+// given timestamp on the range containing the given key.
+func (ls *Stores) HoldsLease(key roachpb.RKey, now roachpb.Timestamp) bool {
+	var holds bool
+	_ = ls.VisitStores(func(s *Store) error {
+		if rng := s.LookupReplica(key, nil); rng != nil {
+			if lease := rng.getLeaderLease(); lease.Covers(now) && lease.OwnedBy(s.StoreID()) {
+				holds = true
+			}
+		}
+		return nil
+	})
+	return holds
+}
+
 // Send implements the client.Sender interface. The store is looked up from the
 // store map if specified by the request; otherwise, the command is being
 // executed locally, and the replica is determined via lookup through each
@@ -243,8 +260,9 @@ func (ls *Stores) FirstRange() (*roachpb.RangeDescriptor, *roachpb.Error) {
 }
 
 // RangeLookup implements the RangeDescriptorDB interface. It looks up
-// the descriptors for the given (meta) key.
-func (ls *Stores) RangeLookup(key roachpb.RKey, _ *roachpb.RangeDescriptor, considerIntents, useReverseScan bool) ([]roachpb.RangeDescriptor, *roachpb.Error) {
+// the descriptors for the given (meta) key. The lookup is traced as part
+// of the trace of the given context, if any.
+func (ls *Stores) RangeLookup(ctx context.Context, key roachpb.RKey, _ *roachpb.RangeDescriptor, considerIntents, useReverseScan bool) ([]roachpb.RangeDescriptor, *roachpb.Error) {
 	ba := roachpb.BatchRequest{}
 	ba.ReadConsistency = roachpb.INCONSISTENT
 	ba.Add(&roachpb.RangeLookupRequest{
@@ -256,7 +274,13 @@ func (ls *Stores) RangeLookup(key roachpb.RKey, _ *roachpb.RangeDescriptor, cons
 		ConsiderIntents: considerIntents,
 		Reverse:         useReverseScan,
 	})
-	br, pErr := ls.Send(context.Background(), ba)
+	// Only the trace of the request is carried over: the lookup may be
+	// shared by other requests, so it mustn't be canceled along with it.
+	lookupCtx := context.Background()
+	if sp := opentracing.SpanFromContext(ctx); sp != nil {
+		lookupCtx = opentracing.ContextWithSpan(lookupCtx, sp)
+	}
+	br, pErr := ls.Send(lookupCtx, ba)
 	if pErr != nil {
 		return nil, pErr
 	}
@@ -320,3 +344,122 @@ func (ls *Stores) updateBootstrapInfo(bi *gossip.BootstrapInfo) error {
 	}
 	return nil
 }
+
+// ReadHLCUpperBound returns the highest upper bound on the wall time of the
+// node's hybrid logical clock persisted to any of the engines, or zero if
+// none has been persisted yet.
+func ReadHLCUpperBound(engines []engine.Engine) (int64, error) {
+	var bound int64
+	for _, eng := range engines {
+		var ts roachpb.Timestamp
+		ok, err := engine.MVCCGetProto(eng, keys.StoreHLCUpperBoundKey(), roachpb.ZeroTimestamp, true, nil, &ts)
+		if err != nil {
+			return 0, err
+		}
+		if ok && ts.WallTime > bound {
+			bound = ts.WallTime
+		}
+	}
+	return bound, nil
+}
+
+// WriteHLCUpperBound persists the upper bound on the wall time of the node's
+// hybrid logical clock to every engine. Returns the first error encountered
+// writing to the engines.
+func WriteHLCUpperBound(engines []engine.Engine, wallTime int64) error {
+	ts := roachpb.Timestamp{WallTime: wallTime}
+	for _, eng := range engines {
+		if err := engine.MVCCPutProto(eng, nil, keys.StoreHLCUpperBoundKey(), roachpb.ZeroTimestamp, nil, &ts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadRangeDescriptorCache returns the range descriptors persisted to the
+// first engine which has any, in key order. Store-local keys aren't part
+// of any range, so the descriptors aren't affected by the snapshots
+// applied to the stores.
+func ReadRangeDescriptorCache(engines []engine.Engine) ([]roachpb.RangeDescriptor, error) {
+	start := keys.StoreRangeDescriptorCachePrefix()
+	for _, eng := range engines {
+		kvs, _, err := engine.MVCCScan(eng, start, start.PrefixEnd(), 0, roachpb.ZeroTimestamp, true, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(kvs) == 0 {
+			continue
+		}
+		descs := make([]roachpb.RangeDescriptor, len(kvs))
+		for i := range kvs {
+			if err := kvs[i].Value.GetProto(&descs[i]); err != nil {
+				return nil, err
+			}
+		}
+		return descs, nil
+	}
+	return nil, nil
+}
+
+// WriteRangeDescriptorCache persists the given range descriptors to the
+// engines. If prev holds the descriptors persisted to the engines by the
+// previous call, only the descriptors which were added, changed or removed
+// since are written; if it is nil, all descriptors persisted before are
+// replaced. The descriptors of each engine are updated atomically. Returns
+// the first error encountered writing to the engines.
+func WriteRangeDescriptorCache(engines []engine.Engine, prev, descs []roachpb.RangeDescriptor) error {
+	start := keys.StoreRangeDescriptorCachePrefix()
+	var removed []roachpb.RKey
+	var changed []roachpb.RangeDescriptor
+	if prev == nil {
+		changed = descs
+	} else {
+		prevByKey := make(map[string]*roachpb.RangeDescriptor, len(prev))
+		for i := range prev {
+			prevByKey[string(prev[i].EndKey)] = &prev[i]
+		}
+		for i := range descs {
+			key := string(descs[i].EndKey)
+			if p, ok := prevByKey[key]; !ok || !reflect.DeepEqual(*p, descs[i]) {
+				changed = append(changed, descs[i])
+			}
+			delete(prevByKey, key)
+		}
+		if len(changed) == 0 && len(prevByKey) == 0 {
+			return nil
+		}
+		for _, p := range prevByKey {
+			removed = append(removed, p.EndKey)
+		}
+	}
+	for _, eng := range engines {
+		if err := func() error {
+			b := eng.NewBatch()
+			defer b.Close()
+			if prev == nil {
+				if err := eng.Iterate(context.TODO(), engine.MakeMVCCMetadataKey(start),
+					engine.MakeMVCCMetadataKey(start.PrefixEnd()), func(kv engine.MVCCKeyValue) (bool, error) {
+						return false, b.Clear(kv.Key)
+					}); err != nil {
+					return err
+				}
+			}
+			for _, endKey := range removed {
+				key := keys.StoreRangeDescriptorCacheKey(endKey)
+				if err := b.Clear(engine.MakeMVCCMetadataKey(key)); err != nil {
+					return err
+				}
+			}
+			for i := range changed {
+				key := keys.StoreRangeDescriptorCacheKey(changed[i].EndKey)
+				if err := engine.MVCCPutProto(b, nil, key, roachpb.ZeroTimestamp, nil, &changed[i]); err != nil {
+					return err
+				}
+			}
+			return b.Commit()
+		}(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
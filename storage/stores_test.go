@@ -313,3 +313,91 @@ func TestStoresGossipStorageReadLatest(t *testing.T) {
 		t.Errorf("bootstrap info %+v not equal to expected %+v", verifyBI, bi)
 	}
 }
+
+// TestHLCUpperBound verifies that the highest upper bound persisted to any
+// of the engines is read back.
+func TestHLCUpperBound(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+	engines := []engine.Engine{
+		engine.NewInMem(roachpb.Attributes{}, 1<<20, stopper),
+		engine.NewInMem(roachpb.Attributes{}, 1<<20, stopper),
+	}
+
+	if bound, err := ReadHLCUpperBound(engines); err != nil {
+		t.Fatal(err)
+	} else if bound != 0 {
+		t.Errorf("expected no upper bound, got %d", bound)
+	}
+	if err := WriteHLCUpperBound(engines, 200); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteHLCUpperBound(engines[:1], 100); err != nil {
+		t.Fatal(err)
+	}
+	if bound, err := ReadHLCUpperBound(engines); err != nil {
+		t.Fatal(err)
+	} else if bound != 200 {
+		t.Errorf("expected upper bound 200, got %d", bound)
+	}
+}
+
+// TestRangeDescriptorCache verifies that the persisted range descriptors
+// are replaced by full writes, updated by incremental ones, and read back
+// in key order.
+func TestRangeDescriptorCache(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+	engines := []engine.Engine{
+		engine.NewInMem(roachpb.Attributes{}, 1<<20, stopper),
+		engine.NewInMem(roachpb.Attributes{}, 1<<20, stopper),
+	}
+
+	if descs, err := ReadRangeDescriptorCache(engines); err != nil {
+		t.Fatal(err)
+	} else if len(descs) != 0 {
+		t.Errorf("expected no descriptors, got %v", descs)
+	}
+	descs := []roachpb.RangeDescriptor{
+		{RangeID: 2, StartKey: roachpb.RKey("c"), EndKey: roachpb.RKeyMax},
+		{RangeID: 1, StartKey: roachpb.RKey("a"), EndKey: roachpb.RKey("c")},
+	}
+	if err := WriteRangeDescriptorCache(engines, nil, descs); err != nil {
+		t.Fatal(err)
+	}
+	// A full write to the second engine replaces all its descriptors.
+	prev := []roachpb.RangeDescriptor{
+		{RangeID: 1, StartKey: roachpb.RKey("a"), EndKey: roachpb.RKey("b")},
+		{RangeID: 3, StartKey: roachpb.RKey("b"), EndKey: roachpb.RKey("c")},
+		{RangeID: 4, StartKey: roachpb.RKey("x"), EndKey: roachpb.RKey("z")},
+	}
+	if err := WriteRangeDescriptorCache(engines[1:], nil, prev); err != nil {
+		t.Fatal(err)
+	}
+	// An incremental write adds, updates and removes descriptors.
+	descs = []roachpb.RangeDescriptor{
+		{RangeID: 1, StartKey: roachpb.RKey("a"), EndKey: roachpb.RKey("b")},
+		{RangeID: 3, StartKey: roachpb.RKey("b"), EndKey: roachpb.RKey("c"), NextReplicaID: 2},
+		{RangeID: 5, StartKey: roachpb.RKey("c"), EndKey: roachpb.RKey("d")},
+	}
+	if err := WriteRangeDescriptorCache(engines[1:], prev, descs); err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range [][]roachpb.RangeDescriptor{
+		{
+			{RangeID: 1, StartKey: roachpb.RKey("a"), EndKey: roachpb.RKey("c")},
+			{RangeID: 2, StartKey: roachpb.RKey("c"), EndKey: roachpb.RKeyMax},
+		},
+		descs,
+	} {
+		read, err := ReadRangeDescriptorCache(engines[i:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(expected, read) {
+			t.Errorf("%d: expected %v, got %v", i, expected, read)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/client"
+	"github.com/cockroachdb/cockroach/keys"
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/storage/engine"
+	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/uuid"
+)
+
+// txnReaperInterval is the interval at which a store looks for expired
+// transaction records and cleans up after them.
+const txnReaperInterval = 10 * time.Second
+
+// An ExpiredTxnRecord describes a transaction record whose coordinator
+// stopped heartbeating it, typically because the gateway crashed, but
+// whose intents have not been cleaned up yet. Such a record is either
+// still PENDING, or has been finalized but lists intents which still
+// need to be resolved.
+type ExpiredTxnRecord struct {
+	RangeID roachpb.RangeID     `json:"rangeID"`
+	Txn     roachpb.Transaction `json:"txn"`
+	// Intents is the number of intent spans listed in the record. It is
+	// zero for transactions which never attempted to commit.
+	Intents int `json:"intents"`
+}
+
+// A txnReaper keeps track of the finalized transactions whose intents it
+// has already resolved, so that they are neither reported nor resolved
+// again until their records are garbage collected.
+type txnReaper struct {
+	mu       sync.Mutex
+	resolved map[uuid.UUID]int64 // txn ID -> wall time of resolution
+}
+
+func newTxnReaper() *txnReaper {
+	return &txnReaper{resolved: map[uuid.UUID]int64{}}
+}
+
+// isExpired returns whether the transaction record has expired as of the
+// given expiration timestamp and still needs to be cleaned up.
+func (tr *txnReaper) isExpired(txn *roachpb.Transaction, expiry roachpb.Timestamp) bool {
+	ts := txn.Timestamp
+	if txn.LastHeartbeat != nil {
+		ts.Forward(*txn.LastHeartbeat)
+	}
+	if !ts.Less(expiry) {
+		return false
+	}
+	if txn.Status == roachpb.PENDING {
+		return true
+	}
+	if len(txn.Intents) == 0 {
+		return false
+	}
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	_, ok := tr.resolved[*txn.ID]
+	return !ok
+}
+
+// markResolved records that the intents of the transaction were resolved.
+// Entries are forgotten once the transaction record would have been
+// garbage collected.
+func (tr *txnReaper) markResolved(txnID uuid.UUID, now int64) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.resolved[txnID] = now
+	for id, resolved := range tr.resolved {
+		if now-resolved > txnCleanupThreshold.Nanoseconds() {
+			delete(tr.resolved, id)
+		}
+	}
+}
+
+// ExpiredTxnRecords returns the expired transaction records of the ranges
+// for which this store holds the leader lease.
+func (s *Store) ExpiredTxnRecords() ([]ExpiredTxnRecord, error) {
+	now := s.Clock().Now()
+	// Use the same expiration as PushTxn, so that expired PENDING
+	// transactions can be aborted by any pusher.
+	expiry := now
+	expiry.WallTime -= 2 * DefaultHeartbeatInterval.Nanoseconds()
+
+	var records []ExpiredTxnRecord
+	_, err := engine.MVCCIterate(context.TODO(), s.engine, keys.LocalRangePrefix, keys.LocalRangeMax, roachpb.ZeroTimestamp,
+		true /* consistent */, nil /* txn */, false /* !reverse */, func(kv roachpb.KeyValue) (bool, error) {
+			// Only consider transaction records; ignore other range-local keys.
+			anchor, suffix, _, err := keys.DecodeRangeKey(kv.Key)
+			if err != nil {
+				return false, err
+			}
+			if !bytes.Equal(suffix, keys.LocalTransactionSuffix) {
+				return false, nil
+			}
+			var txn roachpb.Transaction
+			if err := kv.Value.GetProto(&txn); err != nil {
+				return false, err
+			}
+			if !s.txnReaper.isExpired(&txn, expiry) {
+				return false, nil
+			}
+			rng := s.LookupReplica(roachpb.RKey(anchor), nil)
+			if rng == nil {
+				return false, nil
+			}
+			if lease := rng.getLeaderLease(); !lease.Covers(now) || !lease.OwnedBy(s.StoreID()) {
+				return false, nil
+			}
+			records = append(records, ExpiredTxnRecord{
+				RangeID: rng.RangeID,
+				Txn:     txn,
+				Intents: len(txn.Intents),
+			})
+			return false, nil
+		})
+	return records, err
+}
+
+// startTxnReaper runs a goroutine which periodically cleans up after
+// expired transactions.
+func (s *Store) startTxnReaper() {
+	s.stopper.RunWorker(func() {
+		ticker := time.NewTicker(txnReaperInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.reapExpiredTxns()
+			case <-s.stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
+// reapExpiredTxns cleans up after expired transactions. Intents on which
+// requests are waiting are handled first, as they block foreground
+// traffic. Then the expired transaction records of the store are
+// reported in the store's metrics, PENDING ones are aborted and the
+// intents of finalized ones are resolved.
+func (s *Store) reapExpiredTxns() {
+	ctx := s.Context(nil)
+	s.reapBlockingIntents()
+
+	records, err := s.ExpiredTxnRecords()
+	if err != nil {
+		log.Warningc(ctx, "unable to list expired txn records: %s", err)
+		return
+	}
+	var intents int64
+	for _, record := range records {
+		intents += int64(record.Intents)
+	}
+	s.metrics.expiredTxnCount.Update(int64(len(records)))
+	s.metrics.expiredTxnIntents.Update(intents)
+
+	for _, record := range records {
+		txn := record.Txn
+		if txn.Status == roachpb.PENDING {
+			if s.pushExpiredTxn(&txn) {
+				s.metrics.txnReaped.Inc(1)
+			}
+			continue
+		}
+		rng, err := s.GetReplica(record.RangeID)
+		if err != nil {
+			continue
+		}
+		if pErr := rng.resolveIntents(ctx, roachpb.AsIntents(txn.Intents, &txn),
+			true /* wait */, false /* !poison */); pErr != nil {
+			log.Warningc(ctx, "unable to resolve intents of expired txn %s: %s", txn.ID, pErr)
+			continue
+		}
+		s.txnReaper.markResolved(*txn.ID, s.Clock().PhysicalNow())
+		s.metrics.txnReaped.Inc(1)
+	}
+}
+
+// reapBlockingIntents aborts the expired transactions whose intents block
+// requests waiting on this store, and resolves those intents.
+func (s *Store) reapBlockingIntents() {
+	ctx := s.Context(nil)
+	pushed := map[uuid.UUID]*roachpb.Transaction{}
+	for _, intent := range s.txnWaits.blockingIntents() {
+		txn, ok := pushed[*intent.Txn.ID]
+		if !ok {
+			txn = &roachpb.Transaction{TxnMeta: intent.Txn, Status: roachpb.PENDING}
+			if s.pushExpiredTxn(txn) {
+				s.metrics.txnReaped.Inc(1)
+			}
+			pushed[*intent.Txn.ID] = txn
+		}
+		if txn.Status == roachpb.PENDING {
+			continue
+		}
+		rng := s.LookupReplica(keys.Addr(intent.Key), nil)
+		if rng == nil {
+			continue
+		}
+		intent.Txn = txn.TxnMeta
+		intent.Status = txn.Status
+		if pErr := rng.resolveIntents(ctx, []roachpb.Intent{intent},
+			false /* !wait */, false /* !poison */); pErr != nil {
+			log.Warningc(ctx, "unable to resolve blocking intent %s: %s", intent, pErr)
+		}
+	}
+}
+
+// pushExpiredTxn pushes the transaction in cleanup mode, which aborts it
+// only if its coordinator stopped heartbeating it. The supplied txn is
+// updated on success. Returns whether the transaction was aborted.
+func (s *Store) pushExpiredTxn(txn *roachpb.Transaction) bool {
+	b := &client.Batch{AdmissionClass: roachpb.BACKGROUND}
+	b.InternalAddRequest(&roachpb.PushTxnRequest{
+		Span: roachpb.Span{
+			Key: txn.Key,
+		},
+		Now:       s.Clock().Now(),
+		PusherTxn: roachpb.Transaction{Priority: roachpb.MaxUserPriority},
+		PusheeTxn: txn.TxnMeta,
+		PushType:  roachpb.PUSH_TOUCH,
+	})
+	br, err := s.db.RunWithResponse(b)
+	if err != nil {
+		// Pushing a live transaction in cleanup mode fails.
+		if log.V(1) {
+			log.Infof("push of txn %s failed: %s", txn, err)
+		}
+		return false
+	}
+	*txn = br.Responses[0].GetInner().(*roachpb.PushTxnResponse).PusheeTxn
+	return txn.Status == roachpb.ABORTED
+}
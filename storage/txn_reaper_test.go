@@ -0,0 +1,76 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/keys"
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/storage/engine"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+// TestStoreExpiredTxnRecords verifies that transaction records which are
+// no longer heartbeat are reported as expired, and that the reaper aborts
+// them.
+func TestStoreExpiredTxnRecords(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	store, manual, stopper := createTestStore(t)
+	defer stopper.Stop()
+
+	key := roachpb.Key("a")
+	txn := newTransaction("test", key, 1, roachpb.SERIALIZABLE, store.ctx.Clock)
+	txnKey := keys.TransactionKey(txn.Key, txn.ID)
+	if err := engine.MVCCPutProto(store.Engine(), nil, txnKey, roachpb.ZeroTimestamp, nil, txn); err != nil {
+		t.Fatal(err)
+	}
+
+	expectRecords := func(expected int) []ExpiredTxnRecord {
+		records, err := store.ExpiredTxnRecords()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != expected {
+			t.Fatalf("expected %d expired txn records, got %+v", expected, records)
+		}
+		return records
+	}
+
+	// The record was just written, so it has not expired.
+	expectRecords(0)
+
+	manual.Increment(3 * DefaultHeartbeatInterval.Nanoseconds())
+	// Make sure the store holds a valid leader lease.
+	if _, err := store.DB().Get(key); err != nil {
+		t.Fatal(err)
+	}
+	records := expectRecords(1)
+	if records[0].RangeID != 1 || *records[0].Txn.ID != *txn.ID || records[0].Intents != 0 {
+		t.Errorf("unexpected expired txn record %+v", records[0])
+	}
+
+	// Reaping aborts the transaction, after which it no longer needs to be
+	// cleaned up.
+	store.reapExpiredTxns()
+	expectRecords(0)
+	var reaped roachpb.Transaction
+	if ok, err := engine.MVCCGetProto(store.Engine(), txnKey, roachpb.ZeroTimestamp, true, nil, &reaped); err != nil || !ok {
+		t.Fatalf("unable to read txn record: %t, %v", ok, err)
+	}
+	if reaped.Status != roachpb.ABORTED {
+		t.Errorf("expected txn to be aborted, got %s", reaped)
+	}
+}
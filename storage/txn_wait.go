@@ -0,0 +1,266 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/util/uuid"
+)
+
+const (
+	// txnWaitGossipInterval is the interval at which a store gossips its
+	// transaction waits and checks the wait graph for deadlocks.
+	txnWaitGossipInterval = 1 * time.Second
+	// txnWaitGossipTTL is the time to live of gossiped transaction waits.
+	// Waits received from other stores are ignored once they are older.
+	txnWaitGossipTTL = 3 * txnWaitGossipInterval
+)
+
+// A txnWaiter represents a transactional request which is blocked in the
+// store's retry loop on intents it failed to push. Closing abort causes
+// the request to give up and abort its transaction.
+type txnWaiter struct {
+	pusher   roachpb.TxnMeta
+	priority int32
+	intents  []roachpb.Intent // the intents of the pushees
+	since    int64
+	abort    chan struct{}
+	aborted  bool
+}
+
+// remoteTxnWaits are the transaction waits gossiped by another store,
+// along with the time at which they were received.
+type remoteTxnWaits struct {
+	waits    []roachpb.TxnWait
+	received int64
+}
+
+// A txnWaitGraph tracks which transactions are waiting on which other
+// transactions. The local waits of a store are gossiped and combined with
+// those of all other stores, so that every store sees the cluster-wide
+// pusher/pushee graph. A cycle in this graph is a deadlock: none of its
+// transactions can make progress until one of them is aborted. To break
+// the cycle quickly and deterministically, every store picks the same
+// victim, namely the transaction in the cycle with the lowest priority
+// (ties broken by transaction ID), and the store on which the victim is
+// waiting aborts it.
+type txnWaitGraph struct {
+	mu     sync.Mutex
+	local  map[*txnWaiter]struct{}
+	remote map[roachpb.StoreID]remoteTxnWaits
+	// dirty is set when the local waits changed since they were last
+	// gossiped.
+	dirty bool
+}
+
+// newTxnWaitGraph returns an empty txnWaitGraph.
+func newTxnWaitGraph() *txnWaitGraph {
+	return &txnWaitGraph{
+		local:  map[*txnWaiter]struct{}{},
+		remote: map[roachpb.StoreID]remoteTxnWaits{},
+	}
+}
+
+// wait records that the pusher transaction of w is waiting on the
+// transactions of the given intents. The wait replaces any intents
+// previously recorded for w.
+func (g *txnWaitGraph) wait(w *txnWaiter, intents []roachpb.Intent, now int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	w.intents = w.intents[:0]
+	for _, intent := range intents {
+		if intent.Txn.ID != nil {
+			w.intents = append(w.intents, intent)
+		}
+	}
+	if _, ok := g.local[w]; !ok {
+		w.since = now
+		g.local[w] = struct{}{}
+	}
+	g.dirty = true
+}
+
+// done removes w from the graph. It is a no-op if w was never waiting.
+func (g *txnWaitGraph) done(w *txnWaiter) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.local[w]; ok {
+		delete(g.local, w)
+		g.dirty = true
+	}
+}
+
+// updateRemote replaces the waits known for another store.
+func (g *txnWaitGraph) updateRemote(waits roachpb.TxnWaits, now int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(waits.Waits) == 0 {
+		delete(g.remote, waits.StoreID)
+		return
+	}
+	g.remote[waits.StoreID] = remoteTxnWaits{waits: waits.Waits, received: now}
+}
+
+// localWaits returns the local waits for gossiping, along with whether
+// they changed since the last call.
+func (g *txnWaitGraph) localWaits(storeID roachpb.StoreID) (roachpb.TxnWaits, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	dirty := g.dirty
+	g.dirty = false
+	return g.localWaitsLocked(storeID), dirty
+}
+
+func (g *txnWaitGraph) localWaitsLocked(storeID roachpb.StoreID) roachpb.TxnWaits {
+	waits := roachpb.TxnWaits{StoreID: storeID}
+	for w := range g.local {
+		for _, intent := range w.intents {
+			waits.Waits = append(waits.Waits, roachpb.TxnWait{
+				Pusher:         w.pusher,
+				PusherPriority: w.priority,
+				Pushee:         intent.Txn,
+				Since:          w.since,
+			})
+		}
+	}
+	return waits
+}
+
+// blockingIntents returns the intents on which local requests are
+// currently waiting.
+func (g *txnWaitGraph) blockingIntents() []roachpb.Intent {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var intents []roachpb.Intent
+	for w := range g.local {
+		intents = append(intents, w.intents...)
+	}
+	return intents
+}
+
+// abortDeadlocked searches the wait graph for cycles passing through
+// transactions which are waiting locally and aborts the local waiters of
+// the victim of each cycle. It returns the aborted transactions.
+func (g *txnWaitGraph) abortDeadlocked(storeID roachpb.StoreID, now int64) []roachpb.TxnMeta {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	waits := g.localWaitsLocked(storeID)
+
+	edges := map[uuid.UUID][]uuid.UUID{}
+	metas := map[uuid.UUID]roachpb.TxnMeta{}
+	priorities := map[uuid.UUID]int32{}
+	addWait := func(wait roachpb.TxnWait) {
+		pusher, pushee := *wait.Pusher.ID, *wait.Pushee.ID
+		edges[pusher] = append(edges[pusher], pushee)
+		metas[pusher] = wait.Pusher
+		priorities[pusher] = wait.PusherPriority
+	}
+	for _, wait := range waits.Waits {
+		addWait(wait)
+	}
+	for id, remote := range g.remote {
+		if now-remote.received > txnWaitGossipTTL.Nanoseconds() {
+			delete(g.remote, id)
+			continue
+		}
+		for _, wait := range remote.waits {
+			addWait(wait)
+		}
+	}
+	for _, pushees := range edges {
+		sort.Sort(uuidSlice(pushees))
+	}
+
+	var starts uuidSlice
+	for w := range g.local {
+		starts = append(starts, *w.pusher.ID)
+	}
+	sort.Sort(starts)
+
+	victims := map[uuid.UUID]struct{}{}
+	for _, start := range starts {
+		cycle := findTxnWaitCycle(edges, start)
+		if cycle == nil {
+			continue
+		}
+		victim := cycle[0]
+		for _, id := range cycle[1:] {
+			if txnWaitLess(id, priorities[id], victim, priorities[victim]) {
+				victim = id
+			}
+		}
+		victims[victim] = struct{}{}
+	}
+
+	var aborted []roachpb.TxnMeta
+	for w := range g.local {
+		if _, ok := victims[*w.pusher.ID]; !ok || w.aborted {
+			continue
+		}
+		w.aborted = true
+		close(w.abort)
+		aborted = append(aborted, metas[*w.pusher.ID])
+	}
+	return aborted
+}
+
+// findTxnWaitCycle returns the transactions of a cycle in the wait graph
+// which passes through start, or nil if there is none.
+func findTxnWaitCycle(edges map[uuid.UUID][]uuid.UUID, start uuid.UUID) []uuid.UUID {
+	visited := map[uuid.UUID]struct{}{}
+	var path []uuid.UUID
+	var visit func(id uuid.UUID) bool
+	visit = func(id uuid.UUID) bool {
+		path = append(path, id)
+		visited[id] = struct{}{}
+		for _, next := range edges[id] {
+			if next == start {
+				return true
+			}
+			if _, ok := visited[next]; ok {
+				continue
+			}
+			if visit(next) {
+				return true
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+	if visit(start) {
+		return path
+	}
+	return nil
+}
+
+// txnWaitLess orders transactions for the choice of deadlock victims: by
+// priority, then by ID.
+func txnWaitLess(a uuid.UUID, aPri int32, b uuid.UUID, bPri int32) bool {
+	if aPri != bPri {
+		return aPri < bPri
+	}
+	return bytes.Compare(a.Bytes(), b.Bytes()) < 0
+}
+
+type uuidSlice []uuid.UUID
+
+func (s uuidSlice) Len() int           { return len(s) }
+func (s uuidSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s uuidSlice) Less(i, j int) bool { return bytes.Compare(s[i].Bytes(), s[j].Bytes()) < 0 }
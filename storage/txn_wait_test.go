@@ -0,0 +1,97 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+	"github.com/cockroachdb/cockroach/util/uuid"
+)
+
+func newTestTxnWaiter(txn roachpb.TxnMeta, priority int32) *txnWaiter {
+	return &txnWaiter{pusher: txn, priority: priority, abort: make(chan struct{})}
+}
+
+func isTxnWaiterAborted(w *txnWaiter) bool {
+	select {
+	case <-w.abort:
+		return true
+	default:
+		return false
+	}
+}
+
+// TestTxnWaitGraphDeadlock verifies that a cycle spanning local and
+// remote waits is detected and that only the lowest priority transaction
+// of the cycle is aborted, and only by the store it is waiting on.
+func TestTxnWaitGraphDeadlock(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	a := roachpb.TxnMeta{ID: uuid.NewV4(), Key: roachpb.Key("a")}
+	b := roachpb.TxnMeta{ID: uuid.NewV4(), Key: roachpb.Key("b")}
+	c := roachpb.TxnMeta{ID: uuid.NewV4(), Key: roachpb.Key("c")}
+	intent := func(txn roachpb.TxnMeta) []roachpb.Intent {
+		return []roachpb.Intent{{Span: roachpb.Span{Key: txn.Key}, Txn: txn}}
+	}
+
+	g := newTxnWaitGraph()
+	wa := newTestTxnWaiter(a, 10)
+	wb := newTestTxnWaiter(b, 5)
+	g.wait(wa, intent(b), 1)
+	g.wait(wb, intent(c), 1)
+
+	// Without the remote wait of c on a, there is no cycle.
+	if aborted := g.abortDeadlocked(1, 2); len(aborted) != 0 {
+		t.Fatalf("expected no aborted txns, got %v", aborted)
+	}
+
+	g.updateRemote(roachpb.TxnWaits{
+		StoreID: 2,
+		Waits:   []roachpb.TxnWait{{Pusher: c, PusherPriority: 7, Pushee: a, Since: 1}},
+	}, 2)
+	aborted := g.abortDeadlocked(1, 3)
+	if len(aborted) != 1 || *aborted[0].ID != *b.ID {
+		t.Fatalf("expected txn %s to be aborted, got %v", b.ID, aborted)
+	}
+	if isTxnWaiterAborted(wa) || !isTxnWaiterAborted(wb) {
+		t.Fatalf("expected only the waiter of txn %s to be aborted", b.ID)
+	}
+	// The victim is only aborted once.
+	if aborted := g.abortDeadlocked(1, 3); len(aborted) != 0 {
+		t.Fatalf("expected no aborted txns, got %v", aborted)
+	}
+
+	// Once b is done and c's priority is the lowest, the victim is c, which
+	// is waiting on the other store.
+	g.done(wb)
+	wb = newTestTxnWaiter(b, 20)
+	g.wait(wb, intent(c), 3)
+	if aborted := g.abortDeadlocked(1, 4); len(aborted) != 0 {
+		t.Fatalf("expected no aborted txns, got %v", aborted)
+	}
+
+	// Stale remote waits are ignored.
+	g.updateRemote(roachpb.TxnWaits{
+		StoreID: 2,
+		Waits:   []roachpb.TxnWait{{Pusher: c, PusherPriority: 30, Pushee: a, Since: 1}},
+	}, 4)
+	if aborted := g.abortDeadlocked(1, 5+txnWaitGossipTTL.Nanoseconds()); len(aborted) != 0 {
+		t.Fatalf("expected no aborted txns, got %v", aborted)
+	}
+	if waits, _ := g.localWaits(1); len(waits.Waits) != 2 {
+		t.Fatalf("expected 2 local waits, got %v", waits)
+	}
+}
@@ -43,7 +43,7 @@ import (
 // Args:
 //  prefix: A prefix to be prepended to the temp file names generated, for debugging.
 func PGUrl(t testing.TB, ts *server.TestServer, user, prefix string) (url.URL, func()) {
-	host, port, err := net.SplitHostPort(ts.ServingAddr())
+	host, port, err := net.SplitHostPort(ts.SQLAddr())
 	if err != nil {
 		t.Fatal(err)
 	}
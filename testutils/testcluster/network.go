@@ -0,0 +1,149 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package testcluster
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/util"
+)
+
+// A link identifies the (bidirectional) connection between two nodes. The
+// lower node index always comes first.
+type link [2]int
+
+func makeLink(i, j int) link {
+	if i > j {
+		i, j = j, i
+	}
+	return link{i, j}
+}
+
+// A network intercepts the RPC connections between the nodes of a
+// TestCluster so that links between them can be severed and restored.
+type network struct {
+	mu          sync.Mutex
+	addrs       map[string]int // serving address -> node index
+	partitioned map[link]bool
+	// conns holds the open connections initiated by each node, keyed by the
+	// address they were dialed with.
+	conns map[int]map[string]map[*trackedConn]struct{}
+}
+
+func newNetwork() *network {
+	return &network{
+		addrs:       map[string]int{},
+		partitioned: map[link]bool{},
+		conns:       map[int]map[string]map[*trackedConn]struct{}{},
+	}
+}
+
+// register records the serving address of the given node.
+func (n *network) register(node int, addr string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.addrs[addr] = node
+}
+
+// isPartitionedLocked returns whether the link between node and the node
+// serving addr is currently severed.
+func (n *network) isPartitionedLocked(node int, addr string) bool {
+	to, ok := n.addrs[addr]
+	return ok && n.partitioned[makeLink(node, to)]
+}
+
+// dialer returns the function used by the given node to establish its
+// outgoing connections.
+func (n *network) dialer(node int) func(string, time.Duration) (net.Conn, error) {
+	return func(addr string, timeout time.Duration) (net.Conn, error) {
+		n.mu.Lock()
+		partitioned := n.isPartitionedLocked(node, addr)
+		n.mu.Unlock()
+		if partitioned {
+			return nil, util.Errorf("node %d is partitioned from %s", node, addr)
+		}
+
+		conn, err := net.DialTimeout("tcp", addr, timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		// The link may have been severed while we were dialing.
+		if n.isPartitionedLocked(node, addr) {
+			_ = conn.Close()
+			return nil, util.Errorf("node %d is partitioned from %s", node, addr)
+		}
+		tc := &trackedConn{Conn: conn, network: n, node: node, addr: addr}
+		if n.conns[node] == nil {
+			n.conns[node] = map[string]map[*trackedConn]struct{}{}
+		}
+		if n.conns[node][addr] == nil {
+			n.conns[node][addr] = map[*trackedConn]struct{}{}
+		}
+		n.conns[node][addr][tc] = struct{}{}
+		return tc, nil
+	}
+}
+
+// partition severs the link between nodes i and j, closing all open
+// connections between them and failing any subsequent attempt to connect.
+func (n *network) partition(i, j int) {
+	n.mu.Lock()
+	n.partitioned[makeLink(i, j)] = true
+	var toClose []*trackedConn
+	for addr, to := range n.addrs {
+		for _, l := range [][2]int{{i, j}, {j, i}} {
+			if to != l[1] {
+				continue
+			}
+			for c := range n.conns[l[0]][addr] {
+				toClose = append(toClose, c)
+			}
+		}
+	}
+	n.mu.Unlock()
+
+	for _, c := range toClose {
+		_ = c.Close()
+	}
+}
+
+// heal restores the link between nodes i and j.
+func (n *network) heal(i, j int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.partitioned, makeLink(i, j))
+}
+
+// A trackedConn is a connection which unregisters itself from its network
+// when closed.
+type trackedConn struct {
+	net.Conn
+	network *network
+	node    int
+	addr    string
+}
+
+// Close implements net.Conn.
+func (c *trackedConn) Close() error {
+	c.network.mu.Lock()
+	delete(c.network.conns[c.node][c.addr], c)
+	c.network.mu.Unlock()
+	return c.Conn.Close()
+}
@@ -0,0 +1,214 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package testcluster starts clusters of in-process nodes for tests which
+// exercise replication and need more than a single server, without having
+// to resort to the Docker-based acceptance tests.
+package testcluster
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/server"
+	"github.com/cockroachdb/cockroach/storage"
+	"github.com/cockroachdb/cockroach/storage/engine"
+	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/hlc"
+	"github.com/cockroachdb/cockroach/util/stop"
+)
+
+// A TestCluster is a set of in-process TestServers which have joined each
+// other to form a cluster. Each node uses its own manual clock and all RPC
+// connections between the nodes go through a fault injection layer which
+// allows partitioning them.
+//
+// Ranges are not up-replicated automatically; tests add replicas
+// explicitly through AddReplicas so that replica placement is
+// deterministic. Example usage:
+//
+//   tc := testcluster.StartTestCluster(t, 3)
+//   defer tc.Stop()
+//   desc, err := tc.AddReplicas(key, 1, 2)
+//
+type TestCluster struct {
+	Servers []*server.TestServer
+	// Clocks holds the physical clock of each node. Clocks are started at
+	// the current wall time and only move when advanced by the test.
+	Clocks []*hlc.ManualClock
+
+	network *network
+	// stopper owns the nodes' engines.
+	stopper *stop.Stopper
+}
+
+// StartTestCluster starts a cluster of numNodes nodes, each with a single
+// in-memory store. The first node bootstraps the cluster and the others
+// join it. The test fails if any node cannot be started.
+func StartTestCluster(t util.Tester, numNodes int) *TestCluster {
+	if numNodes < 1 {
+		t.Fatal("invalid cluster size")
+	}
+	tc := &TestCluster{
+		network: newNetwork(),
+		stopper: stop.NewStopper(),
+	}
+	now := time.Now().UnixNano()
+	for i := 0; i < numNodes; i++ {
+		clock := hlc.NewManualClock(now)
+		ctx := server.NewTestContext()
+		ctx.TestingMocker.ClockSource = clock.UnixNano
+		ctx.TestingMocker.RPCDialer = tc.network.dialer(i)
+		ctx.Engines = []engine.Engine{engine.NewInMem(roachpb.Attributes{}, 100<<20, tc.stopper)}
+		if i > 0 {
+			ctx.JoinUsing = tc.Servers[0].ServingAddr()
+		}
+		s := &server.TestServer{Ctx: ctx}
+		if err := s.Start(); err != nil {
+			tc.Stop()
+			t.Fatalf("could not start node %d: %s", i, err)
+		}
+		tc.network.register(i, s.ServingAddr())
+		tc.Servers = append(tc.Servers, s)
+		tc.Clocks = append(tc.Clocks, clock)
+	}
+	return tc
+}
+
+// Stop stops all nodes of the cluster.
+func (tc *TestCluster) Stop() {
+	// Stop the nodes in reverse order so that the default zone config
+	// overrides installed by each TestServer are undone in order.
+	for i := len(tc.Servers) - 1; i >= 0; i-- {
+		tc.Servers[i].Stop()
+	}
+	tc.stopper.Stop()
+}
+
+// NumNodes returns the number of nodes in the cluster.
+func (tc *TestCluster) NumNodes() int {
+	return len(tc.Servers)
+}
+
+// AdvanceClocks moves the clocks of all nodes forward by d.
+func (tc *TestCluster) AdvanceClocks(d time.Duration) {
+	for _, c := range tc.Clocks {
+		c.Increment(d.Nanoseconds())
+	}
+}
+
+// Partition severs the network link between nodes i and j. Open
+// connections between them are closed and new ones are refused until the
+// link is healed.
+func (tc *TestCluster) Partition(i, j int) {
+	tc.network.partition(i, j)
+}
+
+// Isolate severs the network links between node i and all other nodes.
+func (tc *TestCluster) Isolate(i int) {
+	for j := range tc.Servers {
+		if j != i {
+			tc.network.partition(i, j)
+		}
+	}
+}
+
+// Heal restores the network link between nodes i and j.
+func (tc *TestCluster) Heal(i, j int) {
+	tc.network.heal(i, j)
+}
+
+// HealAll restores all network links.
+func (tc *TestCluster) HealAll() {
+	for i := range tc.Servers {
+		for j := i + 1; j < len(tc.Servers); j++ {
+			tc.network.heal(i, j)
+		}
+	}
+}
+
+// Target returns the descriptor of the store of node i, suitable for
+// adding a replica on that node.
+func (tc *TestCluster) Target(i int) roachpb.ReplicaDescriptor {
+	s := tc.Servers[i]
+	target := roachpb.ReplicaDescriptor{NodeID: s.Gossip().GetNodeID()}
+	if err := s.Stores().VisitStores(func(store *storage.Store) error {
+		target.StoreID = store.StoreID()
+		return nil
+	}); err != nil {
+		panic(err)
+	}
+	return target
+}
+
+// LookupRange returns the descriptor of the range containing key.
+func (tc *TestCluster) LookupRange(key roachpb.Key) (roachpb.RangeDescriptor, error) {
+	return tc.Servers[0].LookupRange(key)
+}
+
+// findReplica returns a replica of the range with the given ID on any of
+// the cluster's nodes.
+func (tc *TestCluster) findReplica(rangeID roachpb.RangeID) (*storage.Replica, error) {
+	for _, s := range tc.Servers {
+		var rep *storage.Replica
+		if err := s.Stores().VisitStores(func(store *storage.Store) error {
+			if r, err := store.GetReplica(rangeID); err == nil {
+				rep = r
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+		if rep != nil {
+			return rep, nil
+		}
+	}
+	return nil, util.Errorf("no replica of range %d found", rangeID)
+}
+
+// AddReplicas adds replicas of the range containing key on the given nodes
+// and waits until each of them has been initialized. It returns the updated
+// range descriptor.
+func (tc *TestCluster) AddReplicas(key roachpb.Key, nodes ...int) (roachpb.RangeDescriptor, error) {
+	desc, err := tc.LookupRange(key)
+	if err != nil {
+		return desc, err
+	}
+	for _, i := range nodes {
+		rep, err := tc.findReplica(desc.RangeID)
+		if err != nil {
+			return desc, err
+		}
+		if err := rep.ChangeReplicas(roachpb.ADD_REPLICA, tc.Target(i), rep.Desc()); err != nil {
+			return desc, err
+		}
+		if err := util.RetryForDuration(10*time.Second, func() error {
+			store, err := tc.Servers[i].Stores().GetStore(tc.Target(i).StoreID)
+			if err != nil {
+				return err
+			}
+			r, err := store.GetReplica(desc.RangeID)
+			if err != nil {
+				return err
+			}
+			if !r.IsInitialized() {
+				return util.Errorf("replica of range %d on node %d not initialized yet", desc.RangeID, i)
+			}
+			return nil
+		}); err != nil {
+			return desc, err
+		}
+	}
+	return tc.LookupRange(key)
+}
@@ -0,0 +1,103 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package testcluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+func TestClusterReplication(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tc := StartTestCluster(t, 3)
+	defer tc.Stop()
+
+	key := roachpb.Key("replicated")
+	if _, _, err := tc.Servers[0].SplitRange(key); err != nil {
+		t.Fatal(err)
+	}
+	desc, err := tc.AddReplicas(key, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(desc.Replicas) != 3 {
+		t.Fatalf("expected 3 replicas, got %+v", desc.Replicas)
+	}
+
+	// A write through one node is readable through every other node.
+	if pErr := tc.Servers[1].DB().Put(key, "value"); pErr != nil {
+		t.Fatal(pErr)
+	}
+	for i, s := range tc.Servers {
+		kv, pErr := s.DB().Get(key)
+		if pErr != nil {
+			t.Fatal(pErr)
+		}
+		if v := string(kv.ValueBytes()); v != "value" {
+			t.Errorf("node %d: expected \"value\", got %q", i, v)
+		}
+	}
+}
+
+func TestClusterClocks(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tc := StartTestCluster(t, 2)
+	defer tc.Stop()
+
+	before := tc.Servers[1].Clock().PhysicalNow()
+	tc.AdvanceClocks(time.Minute)
+	if after := tc.Servers[1].Clock().PhysicalNow(); after-before != time.Minute.Nanoseconds() {
+		t.Errorf("expected clock to advance by %s, advanced by %s", time.Minute, time.Duration(after-before))
+	}
+}
+
+func TestNetworkPartition(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tc := StartTestCluster(t, 3)
+	defer tc.Stop()
+
+	dial := func(from, to int) error {
+		conn, err := tc.network.dialer(from)(tc.Servers[to].ServingAddr(), time.Second)
+		if err == nil {
+			err = conn.Close()
+		}
+		return err
+	}
+
+	tc.Partition(0, 1)
+	for _, l := range [][2]int{{0, 1}, {1, 0}} {
+		if err := dial(l[0], l[1]); err == nil {
+			t.Errorf("expected dial from %d to %d to fail", l[0], l[1])
+		}
+	}
+	if err := dial(0, 2); err != nil {
+		t.Errorf("unexpected error dialing unpartitioned node: %s", err)
+	}
+
+	// Requests keep being served through the nodes which are still
+	// connected.
+	if pErr := tc.Servers[2].DB().Put("a", "b"); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	tc.HealAll()
+	util.SucceedsSoon(t, func() error {
+		return dial(0, 1)
+	})
+}
@@ -0,0 +1,167 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package admission provides a queue which limits the number of requests
+// processed concurrently, and admits the waiting requests by admission
+// class.
+package admission
+
+import (
+	"sync"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/util/metric"
+)
+
+// NumClasses is the number of distinct roachpb.AdmissionClass values.
+// Classes with lower values are admitted first.
+const NumClasses = int(roachpb.BACKGROUND) + 1
+
+// maxSkips is the number of slots in a row which may be handed to more
+// important classes while requests of a class wait, after which the next
+// slot goes to that class. This guarantees the less important classes a
+// minimum share of the slots, so that background work doesn't starve under
+// sustained foreground load.
+const maxSkips = 10
+
+// A Queue limits the number of requests which are processed concurrently.
+// Once the limit has been reached, incoming requests wait in a FIFO queue
+// per admission class. Whenever a slot becomes available it is handed to
+// the oldest waiter of the most important class, unless a less important
+// class has been passed over maxSkips times in a row, so that when the
+// resource is overloaded, background work is delayed before foreground
+// traffic without being starved.
+type Queue struct {
+	limit  int
+	queued *metric.Counter
+
+	mu       sync.Mutex
+	inFlight int
+	waiters  [NumClasses][]chan struct{}
+	// skips counts the slots handed to other classes since a request of
+	// each class was last admitted while some of them waited.
+	skips [NumClasses]int
+}
+
+// NewQueue returns a Queue which admits at most limit concurrent requests.
+// A limit of zero or less disables admission control. If not nil, queued
+// counts the requests which had to wait.
+func NewQueue(limit int, queued *metric.Counter) *Queue {
+	return &Queue{limit: limit, queued: queued}
+}
+
+// Admit blocks until a request of the given class may be processed, or
+// until either the context is canceled or done is closed. On success, the
+// caller must invoke Release once the request has been processed.
+func (q *Queue) Admit(ctx context.Context, class roachpb.AdmissionClass,
+	done <-chan struct{}) error {
+	if q.limit <= 0 {
+		return nil
+	}
+	if int(class) < 0 || int(class) >= NumClasses {
+		class = roachpb.FOREGROUND
+	}
+	q.mu.Lock()
+	if q.inFlight < q.limit {
+		q.inFlight++
+		q.mu.Unlock()
+		return nil
+	}
+	ch := make(chan struct{})
+	q.waiters[class] = append(q.waiters[class], ch)
+	q.mu.Unlock()
+	if q.queued != nil {
+		q.queued.Inc(1)
+	}
+
+	var err error
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-done:
+		err = &roachpb.NodeUnavailableError{}
+	}
+
+	q.mu.Lock()
+	for i, w := range q.waiters[class] {
+		if w == ch {
+			q.waiters[class] = append(q.waiters[class][:i], q.waiters[class][i+1:]...)
+			q.mu.Unlock()
+			return err
+		}
+	}
+	q.mu.Unlock()
+	// The slot was handed to us concurrently with our giving up on it;
+	// pass it on.
+	q.Release()
+	return err
+}
+
+// Release returns a slot obtained via Admit, handing it to the next
+// waiting request, if any.
+func (q *Queue) Release() {
+	if q.limit <= 0 {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	class := q.nextLocked()
+	if class < 0 {
+		q.inFlight--
+		return
+	}
+	ch := q.waiters[class][0]
+	q.waiters[class] = q.waiters[class][1:]
+	close(ch)
+}
+
+// nextLocked returns the class of the request to admit next, or -1 if no
+// request waits. Caller must hold the mutex.
+func (q *Queue) nextLocked() int {
+	next := -1
+	for class := range q.waiters {
+		if len(q.waiters[class]) == 0 {
+			q.skips[class] = 0
+			continue
+		}
+		if next < 0 {
+			next = class
+		} else if q.skips[class] >= maxSkips {
+			next = class
+			break
+		}
+	}
+	if next < 0 {
+		return -1
+	}
+	for class := range q.waiters {
+		if class != next && len(q.waiters[class]) > 0 {
+			q.skips[class]++
+		}
+	}
+	q.skips[next] = 0
+	return next
+}
+
+// Queued returns the number of requests of the given class currently
+// waiting for admission.
+func (q *Queue) Queued(class roachpb.AdmissionClass) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.waiters[class])
+}
@@ -0,0 +1,159 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package admission
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/leaktest"
+)
+
+// TestQueueOrdering verifies that once the limit is reached,
+// waiting foreground requests are admitted before background requests.
+func TestQueueOrdering(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	q := NewQueue(1, nil)
+	ctx := context.Background()
+	if err := q.Admit(ctx, roachpb.FOREGROUND, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	admitted := make(chan roachpb.AdmissionClass, 2)
+	wait := func(class roachpb.AdmissionClass) {
+		if err := q.Admit(ctx, class, nil); err != nil {
+			t.Error(err)
+		}
+		admitted <- class
+	}
+	go wait(roachpb.BACKGROUND)
+	util.SucceedsSoon(t, func() error {
+		if q.Queued(roachpb.BACKGROUND) != 1 {
+			return util.Errorf("background request not queued yet")
+		}
+		return nil
+	})
+	go wait(roachpb.FOREGROUND)
+	util.SucceedsSoon(t, func() error {
+		if q.Queued(roachpb.FOREGROUND) != 1 {
+			return util.Errorf("foreground request not queued yet")
+		}
+		return nil
+	})
+
+	for _, expected := range []roachpb.AdmissionClass{roachpb.FOREGROUND, roachpb.BACKGROUND} {
+		q.Release()
+		if class := <-admitted; class != expected {
+			t.Fatalf("expected %s to be admitted, got %s", expected, class)
+		}
+	}
+	q.Release()
+	if q.inFlight != 0 {
+		t.Fatalf("expected no requests in flight, got %d", q.inFlight)
+	}
+}
+
+// TestQueueCancel verifies that a waiting request gives up when
+// its context is canceled and that it doesn't leak its slot.
+func TestQueueCancel(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	q := NewQueue(1, nil)
+	if err := q.Admit(context.Background(), roachpb.FOREGROUND, nil); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := q.Admit(ctx, roachpb.BACKGROUND, nil); err != context.Canceled {
+		t.Fatalf("expected %s, got %v", context.Canceled, err)
+	}
+	if n := q.Queued(roachpb.BACKGROUND); n != 0 {
+		t.Fatalf("expected canceled request to be dequeued, found %d waiters", n)
+	}
+	q.Release()
+	if q.inFlight != 0 {
+		t.Fatalf("expected no requests in flight, got %d", q.inFlight)
+	}
+}
+
+// TestQueueDisabled verifies that a limit of zero never blocks.
+func TestQueueDisabled(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	q := NewQueue(0, nil)
+	for i := 0; i < 10; i++ {
+		if err := q.Admit(context.Background(), roachpb.BACKGROUND, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestQueueMinimumShare verifies that under sustained foreground load,
+// waiting background requests are still admitted after at most maxSkips
+// foreground requests.
+func TestQueueMinimumShare(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	q := NewQueue(1, nil)
+	ctx := context.Background()
+	if err := q.Admit(ctx, roachpb.FOREGROUND, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	admitted := make(chan roachpb.AdmissionClass)
+	wait := func(class roachpb.AdmissionClass) {
+		if err := q.Admit(ctx, class, nil); err != nil {
+			t.Error(err)
+		}
+		admitted <- class
+	}
+	go wait(roachpb.BACKGROUND)
+	util.SucceedsSoon(t, func() error {
+		if q.Queued(roachpb.BACKGROUND) != 1 {
+			return util.Errorf("background request not queued yet")
+		}
+		return nil
+	})
+
+	// Keep a foreground request waiting at all times; the background
+	// request must get the slot after maxSkips foreground requests.
+	for i := 0; ; i++ {
+		go wait(roachpb.FOREGROUND)
+		util.SucceedsSoon(t, func() error {
+			if q.Queued(roachpb.FOREGROUND) != 1 {
+				return util.Errorf("foreground request not queued yet")
+			}
+			return nil
+		})
+		q.Release()
+		if class := <-admitted; class == roachpb.BACKGROUND {
+			if i != maxSkips {
+				t.Fatalf("expected background request after %d foreground requests, got %d",
+					maxSkips, i)
+			}
+			break
+		}
+		if i >= maxSkips {
+			t.Fatalf("background request starved after %d foreground requests", i+1)
+		}
+	}
+	// Drain the remaining foreground waiter.
+	q.Release()
+	<-admitted
+	q.Release()
+	if q.inFlight != 0 {
+		t.Fatalf("expected no requests in flight, got %d", q.inFlight)
+	}
+}
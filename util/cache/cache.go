@@ -295,6 +295,15 @@ func (mc *UnorderedCache) length() int {
 	return len(mc.hmap)
 }
 
+// Do invokes f on all of the entries in the cache in reverse eviction
+// order, i.e. starting with the entry which would be evicted last.
+func (mc *UnorderedCache) Do(f func(k, v interface{})) {
+	for e := mc.ll.Front(); e != nil; e = e.Next() {
+		entry := e.Value.(*Entry)
+		f(entry.Key, entry.Value)
+	}
+}
+
 // OrderedCache is a cache which supports binary searches using Ceil
 // and Floor methods. It is backed by a left-leaning red black tree.
 // See comments in UnorderedCache for more details on cache functionality.
@@ -154,6 +154,22 @@ func TestCacheLRU(t *testing.T) {
 	}
 }
 
+func TestUnorderedCacheDo(t *testing.T) {
+	mc := NewUnorderedCache(Config{Policy: CacheLRU, ShouldEvict: evictThreeOrMore})
+	mc.Add(testKey("a"), 1)
+	mc.Add(testKey("b"), 2)
+	if _, ok := mc.Get(testKey("a")); !ok {
+		t.Fatal("failed to get key a")
+	}
+	var keys []testKey
+	mc.Do(func(k, v interface{}) {
+		keys = append(keys, k.(testKey))
+	})
+	if !reflect.DeepEqual(keys, []testKey{"a", "b"}) {
+		t.Errorf("expected keys [a b], got %v", keys)
+	}
+}
+
 func TestCacheFIFO(t *testing.T) {
 	mc := NewUnorderedCache(Config{Policy: CacheFIFO, ShouldEvict: evictThreeOrMore})
 	// Insert two keys into cache.
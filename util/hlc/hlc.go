@@ -34,6 +34,10 @@ import (
 // repeatedly. This is expected during NTP updates, but may
 // indicate a broken clock in some cases.
 
+// sleepUntilStep is the longest SleepUntil sleeps without re-reading the
+// physical clock.
+const sleepUntilStep = 100 * time.Millisecond
+
 // Clock is a hybrid logical clock. Objects of this
 // type model causality while maintaining a relation
 // to physical time. Roughly speaking, timestamps
@@ -180,6 +184,40 @@ func (c *Clock) PhysicalTime() time.Time {
 	return time.Unix(physNow/1E9, physNow%1E9)
 }
 
+// WallTimeUpperBound returns an upper bound on the wall time of the
+// timestamps issued by the clock within the given duration from now. It
+// accounts for the clock being updated with timestamps from other nodes, as
+// long as those don't exceed the maximum offset. Persisting the bound
+// periodically allows a restarted node to wait out regressions of its
+// physical clock with SleepUntil.
+func (c *Clock) WallTimeUpperBound(delta time.Duration) int64 {
+	c.Lock()
+	defer c.Unlock()
+	wallTime := c.physicalClock() + c.maxOffset.Nanoseconds()
+	if c.state.WallTime > wallTime {
+		wallTime = c.state.WallTime
+	}
+	return wallTime + delta.Nanoseconds()
+}
+
+// SleepUntil blocks until the physical clock has passed the given wall time,
+// so that the clock no longer issues timestamps below it. It returns
+// immediately if the physical clock is already past the wall time.
+func (c *Clock) SleepUntil(wallTime int64) {
+	for {
+		remaining := time.Duration(wallTime - c.physicalClock())
+		if remaining < 0 {
+			return
+		}
+		// Sleep in steps, re-reading the physical clock in between, as it
+		// may be adjusted while we wait.
+		if remaining > sleepUntilStep {
+			remaining = sleepUntilStep
+		}
+		time.Sleep(remaining)
+	}
+}
+
 // Update takes a hybrid timestamp, usually originating from
 // an event received from another member of a distributed
 // system. The clock is updated and the hybrid timestamp
@@ -167,3 +167,44 @@ func ExampleManualClock() {
 		log.Fatalf("manual clock error")
 	}
 }
+
+func TestWallTimeUpperBound(t *testing.T) {
+	m := NewManualClock(100)
+	c := NewClock(m.UnixNano)
+	c.SetMaxOffset(10)
+	if bound := c.WallTimeUpperBound(5); bound != 115 {
+		t.Errorf("expected upper bound 115, got %d", bound)
+	}
+	// A clock updated beyond the maximum offset is bounded by its state.
+	c.Update(roachpb.Timestamp{WallTime: 200})
+	if bound := c.WallTimeUpperBound(5); bound != 205 {
+		t.Errorf("expected upper bound 205, got %d", bound)
+	}
+}
+
+func TestSleepUntil(t *testing.T) {
+	m := NewManualClock(100)
+	c := NewClock(m.UnixNano)
+	// The physical clock is already past the wall time.
+	c.SleepUntil(50)
+
+	done := make(chan struct{})
+	go func() {
+		c.SleepUntil(200)
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("expected SleepUntil to wait for the physical clock")
+	case <-time.After(10 * time.Millisecond):
+	}
+	m.Set(201)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected SleepUntil to return once the physical clock passed the wall time")
+	}
+	if now := c.Now(); now.WallTime <= 200 {
+		t.Errorf("expected a timestamp above 200, got %s", now)
+	}
+}
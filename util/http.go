@@ -18,8 +18,6 @@ package util
 
 import (
 	"encoding/json"
-	"fmt"
-	"io/ioutil"
 	"math"
 	"net/http"
 	"reflect"
@@ -40,6 +38,12 @@ const (
 	ContentEncodingHeader = "Content-Encoding"
 	// ContentTypeHeader is the canonical header name for content type.
 	ContentTypeHeader = "Content-Type"
+	// IdempotencyKeyHeader is the canonical header name for the key which
+	// identifies retries of the same mutating request.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses which were replayed for
+	// a retried request instead of applying it again.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
 	// JSONContentType is the JSON content type.
 	JSONContentType = "application/json"
 	// AltJSONContentType is the alternate JSON content type.
@@ -241,44 +245,3 @@ func MarshalResponse(r *http.Request, value interface{}, allowed []EncodingType)
 	}
 	return
 }
-
-// GetJSON uses the supplied client to retrieve the URL specified by the parameters and
-// unmarshals the result into the supplied interface.
-//
-// TODO(cdo): Refactor the *JSON methods to handle more encodings.
-func GetJSON(httpClient *http.Client, scheme, hostport, path string, v interface{}) error {
-	url := fmt.Sprintf("%s://%s%s", scheme, hostport, path)
-	resp, err := httpClient.Get(url)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	b, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return err
-	}
-	if resp.StatusCode != http.StatusOK {
-		return Errorf("status: %s, error: %s", resp.Status, b)
-	}
-	return json.Unmarshal(b, v)
-}
-
-// PostJSON uses the supplied client to perform a POST to the URL specified
-// by the parameters and unmarshals the result into the supplied interface.
-// This function assumes that the body is also JSON.
-func PostJSON(httpClient *http.Client, scheme, hostport, path, body string, v interface{}) error {
-	url := fmt.Sprintf("%s://%s%s", scheme, hostport, path)
-	resp, err := httpClient.Post(url, JSONContentType, strings.NewReader(body))
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	b, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return err
-	}
-	if resp.StatusCode != http.StatusOK {
-		return Errorf("status: %s, error: %s", resp.Status, b)
-	}
-	return json.Unmarshal(b, v)
-}
@@ -0,0 +1,222 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package httputil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"golang.org/x/net/context"
+	"golang.org/x/net/context/ctxhttp"
+
+	"github.com/cockroachdb/cockroach/base"
+	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/retry"
+)
+
+// DefaultRetryOptions are the retry options of clients created by
+// NewClient.
+var DefaultRetryOptions = retry.Options{
+	InitialBackoff: 50 * time.Millisecond,
+	MaxBackoff:     time.Second,
+	Multiplier:     2,
+	MaxRetries:     3,
+}
+
+// A StatusError is returned when a request completes with a status other
+// than 200 OK.
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	// Body is the body of the response, which usually describes the error.
+	Body []byte
+}
+
+// Error implements the error interface.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s: status: %s, error: %s", e.Method, e.URL, e.Status, e.Body)
+}
+
+// Temporary returns true if the request may succeed when retried.
+func (e *StatusError) Temporary() bool {
+	return e.StatusCode >= http.StatusInternalServerError
+}
+
+// IsStatus returns true if err is a StatusError with the given status code.
+func IsStatus(err error, code int) bool {
+	statusErr, ok := err.(*StatusError)
+	return ok && statusErr.StatusCode == code
+}
+
+// A Client issues requests to the HTTP endpoints of cockroach nodes and
+// decodes their JSON or protobuf responses.
+type Client struct {
+	HTTPClient *http.Client
+	// Scheme is the URL scheme ("http" or "https") of requests.
+	Scheme string
+	// RetryOptions, if set, configures the retry of requests which fail
+	// due to network errors or server errors. If nil, requests are not
+	// retried.
+	RetryOptions *retry.Options
+	// RetryNonIdempotent, if set, retries POST requests as well. They are
+	// not retried by default, as a request which failed may nonetheless
+	// have been applied, and the endpoint it was posted to may not be
+	// idempotent.
+	RetryNonIdempotent bool
+}
+
+// idempotent returns true if requests of the given HTTP method can safely
+// be retried.
+func idempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+// NewClient returns a client using the TLS configuration of the supplied
+// context and DefaultRetryOptions, which only retries idempotent requests.
+func NewClient(ctx *base.Context) (*Client, error) {
+	httpClient, err := ctx.GetHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	opts := DefaultRetryOptions
+	return &Client{
+		HTTPClient:   httpClient,
+		Scheme:       ctx.HTTPRequestScheme(),
+		RetryOptions: &opts,
+	}, nil
+}
+
+// GetJSON retrieves the path from the node at hostport and unmarshals the
+// JSON response into response.
+func (c *Client) GetJSON(ctx context.Context, hostport, path string, response interface{}) error {
+	b, err := c.do(ctx, "GET", hostport, path, util.JSONContentType, "", nil)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, response)
+}
+
+// PostJSON posts request, marshaled as JSON, to the path of the node at
+// hostport and unmarshals the JSON response into response. To post a
+// request which is already JSON encoded, pass it as a json.RawMessage.
+func (c *Client) PostJSON(ctx context.Context, hostport, path string, request, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	b, err := c.do(ctx, "POST", hostport, path, util.JSONContentType, util.JSONContentType, body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, response)
+}
+
+// GetProto retrieves the path from the node at hostport and unmarshals the
+// protobuf response into response.
+func (c *Client) GetProto(ctx context.Context, hostport, path string, response proto.Message) error {
+	b, err := c.do(ctx, "GET", hostport, path, util.ProtoContentType, "", nil)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(b, response)
+}
+
+// PostProto posts request, marshaled as a protobuf, to the path of the
+// node at hostport and unmarshals the protobuf response into response.
+func (c *Client) PostProto(ctx context.Context, hostport, path string, request, response proto.Message) error {
+	body, err := proto.Marshal(request)
+	if err != nil {
+		return err
+	}
+	b, err := c.do(ctx, "POST", hostport, path, util.ProtoContentType, util.ProtoContentType, body)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(b, response)
+}
+
+// do issues the request, retrying it according to RetryOptions if its method
+// is idempotent or RetryNonIdempotent is set, and returns the body of the
+// response.
+func (c *Client) do(ctx context.Context, method, hostport, path, accept, contentType string,
+	body []byte) ([]byte, error) {
+	url := fmt.Sprintf("%s://%s%s", c.Scheme, hostport, path)
+	if c.RetryOptions == nil || !(idempotent(method) || c.RetryNonIdempotent) {
+		return c.doOnce(ctx, method, url, accept, contentType, body)
+	}
+
+	opts := *c.RetryOptions
+	opts.Closer = ctx.Done()
+	var err error
+	for r := retry.Start(opts); r.Next(); {
+		var b []byte
+		if b, err = c.doOnce(ctx, method, url, accept, contentType, body); err == nil {
+			return b, nil
+		}
+		if statusErr, ok := err.(*StatusError); ok && !statusErr.Temporary() {
+			return nil, err
+		}
+		if log.V(1) {
+			log.Infof("%s %s failed; retrying: %s", method, url, err)
+		}
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	return nil, err
+}
+
+func (c *Client) doOnce(ctx context.Context, method, url, accept, contentType string,
+	body []byte) ([]byte, error) {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(util.AcceptHeader, accept)
+	if contentType != "" {
+		req.Header.Set(util.ContentTypeHeader, contentType)
+	}
+	resp, err := ctxhttp.Do(ctx, c.HTTPClient, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{
+			Method:     method,
+			URL:        url,
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Body:       b,
+		}
+	}
+	return b, nil
+}
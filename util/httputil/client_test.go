@@ -0,0 +1,179 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package httputil
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/retry"
+)
+
+func newTestClient(srv *httptest.Server, maxRetries int) (*Client, string) {
+	return &Client{
+		HTTPClient: http.DefaultClient,
+		Scheme:     "http",
+		RetryOptions: &retry.Options{
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     time.Millisecond,
+			MaxRetries:     maxRetries,
+		},
+	}, strings.TrimPrefix(srv.URL, "http://")
+}
+
+func TestClientJSON(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if accept := r.Header.Get(util.AcceptHeader); accept != util.JSONContentType {
+			t.Errorf("unexpected accept header %q", accept)
+		}
+		var req map[string]string
+		if r.Method == "POST" {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Error(err)
+			}
+		}
+		if err := json.NewEncoder(w).Encode(map[string]string{"path": r.URL.Path, "key": req["key"]}); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+	c, hostport := newTestClient(srv, 0)
+
+	var resp map[string]string
+	if err := c.GetJSON(context.Background(), hostport, "/foo", &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp["path"] != "/foo" {
+		t.Errorf("unexpected response %v", resp)
+	}
+	if err := c.PostJSON(context.Background(), hostport, "/bar", map[string]string{"key": "a"}, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp["path"] != "/bar" || resp["key"] != "a" {
+		t.Errorf("unexpected response %v", resp)
+	}
+}
+
+func TestClientProto(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if accept := r.Header.Get(util.AcceptHeader); accept != util.ProtoContentType {
+			t.Errorf("unexpected accept header %q", accept)
+		}
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		var req roachpb.GetRequest
+		if err := proto.Unmarshal(b, &req); err != nil {
+			t.Error(err)
+		}
+		resp := &roachpb.GetResponse{Value: &roachpb.Value{}}
+		resp.Value.SetBytes(req.Key)
+		if b, err = proto.Marshal(resp); err != nil {
+			t.Error(err)
+		}
+		if _, err := w.Write(b); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+	c, hostport := newTestClient(srv, 0)
+
+	req := &roachpb.GetRequest{Span: roachpb.Span{Key: roachpb.Key("a")}}
+	var resp roachpb.GetResponse
+	if err := c.PostProto(context.Background(), hostport, "/", req, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := resp.Value.GetBytes(); err != nil || string(s) != "a" {
+		t.Errorf("unexpected response %+v (%v)", resp, err)
+	}
+}
+
+func TestClientRetry(t *testing.T) {
+	var attempts int32
+	code := int32(http.StatusServiceUnavailable)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			http.Error(w, "try again", int(atomic.LoadInt32(&code)))
+			return
+		}
+		if _, err := w.Write([]byte("{}")); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+	c, hostport := newTestClient(srv, 5)
+
+	// Server errors are retried.
+	var resp struct{}
+	if err := c.GetJSON(context.Background(), hostport, "/", &resp); err != nil {
+		t.Fatal(err)
+	}
+	if a := atomic.LoadInt32(&attempts); a != 3 {
+		t.Errorf("expected 3 attempts, got %d", a)
+	}
+
+	// Client errors are not.
+	atomic.StoreInt32(&attempts, 0)
+	atomic.StoreInt32(&code, http.StatusNotFound)
+	err := c.GetJSON(context.Background(), hostport, "/", &resp)
+	if !IsStatus(err, http.StatusNotFound) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	if a := atomic.LoadInt32(&attempts); a != 1 {
+		t.Errorf("expected 1 attempt, got %d", a)
+	}
+
+	// POST requests are only retried if the client opts in.
+	atomic.StoreInt32(&attempts, 0)
+	atomic.StoreInt32(&code, http.StatusServiceUnavailable)
+	err = c.PostJSON(context.Background(), hostport, "/", struct{}{}, &resp)
+	if !IsStatus(err, http.StatusServiceUnavailable) {
+		t.Fatalf("expected service unavailable error, got %v", err)
+	}
+	if a := atomic.LoadInt32(&attempts); a != 1 {
+		t.Errorf("expected 1 attempt, got %d", a)
+	}
+	atomic.StoreInt32(&attempts, 0)
+	c.RetryNonIdempotent = true
+	if err := c.PostJSON(context.Background(), hostport, "/", struct{}{}, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if a := atomic.LoadInt32(&attempts); a != 3 {
+		t.Errorf("expected 3 attempts, got %d", a)
+	}
+
+	// A canceled context stops the retry loop.
+	atomic.StoreInt32(&attempts, -100)
+	atomic.StoreInt32(&code, http.StatusServiceUnavailable)
+	c.RetryOptions.MaxRetries = 0
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := c.GetJSON(ctx, hostport, "/", &resp); err != context.DeadlineExceeded {
+		t.Fatalf("expected %s, got %v", context.DeadlineExceeded, err)
+	}
+}
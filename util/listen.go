@@ -0,0 +1,81 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package util
+
+import (
+	"net"
+	"time"
+)
+
+// ListenOptions are the socket options of a TCP listener. The zero value
+// listens like net.Listen.
+type ListenOptions struct {
+	// ReusePort sets SO_REUSEPORT on the listening socket, which allows
+	// another process to bind the same address concurrently, e.g. a
+	// restarting node taking over from the old one without refusing
+	// connections in between. Only supported on Linux and the BSDs.
+	ReusePort bool
+	// KeepAlive, if positive, enables TCP keepalives on the accepted
+	// connections with the given period, so that half-open connections of
+	// vanished clients are eventually closed.
+	KeepAlive time.Duration
+	// Backlog, if positive, is the maximum length of the queue of pending
+	// connections. Defaults to that of the system. Only supported on Linux
+	// and the BSDs.
+	Backlog int
+}
+
+// Listen announces on the TCP address with the given socket options.
+func Listen(addr string, opts ListenOptions) (net.Listener, error) {
+	var ln net.Listener
+	var err error
+	if opts.ReusePort || opts.Backlog > 0 {
+		ln, err = listenSocket(addr, opts)
+	} else {
+		ln, err = net.Listen("tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if opts.KeepAlive > 0 {
+		if tcpLn, ok := ln.(*net.TCPListener); ok {
+			ln = keepAliveListener{TCPListener: tcpLn, period: opts.KeepAlive}
+		}
+	}
+	return ln, nil
+}
+
+// keepAliveListener enables TCP keepalives on the connections it accepts.
+type keepAliveListener struct {
+	*net.TCPListener
+	period time.Duration
+}
+
+// Accept implements the net.Listener interface.
+func (ln keepAliveListener) Accept() (net.Conn, error) {
+	conn, err := ln.AcceptTCP()
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.SetKeepAlive(true); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if err := conn.SetKeepAlivePeriod(ln.period); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package util
+
+import (
+	"net"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// TestListenReusePort verifies that two listeners with SO_REUSEPORT can bind
+// the same address, and that connections to them are accepted.
+func TestListenReusePort(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skipf("SO_REUSEPORT semantics differ on %s", runtime.GOOS)
+	}
+	opts := ListenOptions{ReusePort: true, KeepAlive: time.Second, Backlog: 16}
+	ln1, err := Listen("127.0.0.1:0", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln1.Close()
+	ln2, err := Listen(ln1.Addr().String(), opts)
+	if err != nil {
+		t.Fatalf("expected a second listener on %s, got %s", ln1.Addr(), err)
+	}
+	ln2.Close()
+
+	// Without SO_REUSEPORT, the address is taken.
+	if ln, err := Listen(ln1.Addr().String(), ListenOptions{}); err == nil {
+		ln.Close()
+		t.Fatalf("expected listening on %s to fail", ln1.Addr())
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		conn, err := ln1.Accept()
+		if err == nil {
+			err = conn.Close()
+		}
+		errCh <- err
+	}()
+	conn, err := net.Dial("tcp", ln1.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestListenIPv6 verifies that listeners with socket options bind IPv6
+// addresses, and that unspecified addresses are bound for both IPv4 and
+// IPv6.
+func TestListenIPv6(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skipf("SO_REUSEPORT semantics differ on %s", runtime.GOOS)
+	}
+	if ln, err := net.Listen("tcp", "[::1]:0"); err != nil {
+		t.Skipf("IPv6 is not supported: %s", err)
+	} else {
+		ln.Close()
+	}
+	opts := ListenOptions{ReusePort: true, Backlog: 16}
+
+	testCases := []struct {
+		addr  string
+		hosts []string
+	}{
+		{"[::1]:0", []string{"::1"}},
+		{":0", []string{"127.0.0.1", "::1"}},
+	}
+	for i, c := range testCases {
+		ln, err := Listen(c.addr, opts)
+		if err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+		_, port, err := net.SplitHostPort(ln.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, host := range c.hosts {
+			errCh := make(chan error, 1)
+			go func() {
+				conn, err := ln.Accept()
+				if err == nil {
+					err = conn.Close()
+				}
+				errCh <- err
+			}()
+			conn, err := net.Dial("tcp", net.JoinHostPort(host, port))
+			if err != nil {
+				t.Fatalf("%d: expected %s to accept connections to %s, got %s", i, c.addr, host, err)
+			}
+			conn.Close()
+			if err := <-errCh; err != nil {
+				t.Fatal(err)
+			}
+		}
+		ln.Close()
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package util
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// listenSocket creates the listening socket itself in order to set the
+// socket options which net.Listen doesn't support.
+func listenSocket(addr string, opts ListenOptions) (net.Listener, error) {
+	tcpAddr, err := net.ResolveTCPAddr("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Like net.Listen, unspecified addresses are bound for both IPv4 and
+	// IPv6 if the system supports it, and for IPv4 only otherwise.
+	wildcard := tcpAddr.IP == nil || tcpAddr.IP.IsUnspecified()
+	var fd int
+	var sa syscall.Sockaddr
+	if ip4 := tcpAddr.IP.To4(); ip4 != nil && !wildcard {
+		if fd, err = socket(syscall.AF_INET); err != nil {
+			return nil, err
+		}
+		sa4 := &syscall.SockaddrInet4{Port: tcpAddr.Port}
+		copy(sa4.Addr[:], ip4)
+		sa = sa4
+	} else if fd, err = socket(syscall.AF_INET6); err == nil {
+		sa6 := &syscall.SockaddrInet6{Port: tcpAddr.Port}
+		if !wildcard {
+			copy(sa6.Addr[:], tcpAddr.IP.To16())
+			if sa6.ZoneId, err = zoneID(tcpAddr.Zone); err != nil {
+				_ = syscall.Close(fd)
+				return nil, err
+			}
+		} else if err := syscall.SetsockoptInt(fd, syscall.IPPROTO_IPV6, syscall.IPV6_V6ONLY, 0); err != nil {
+			_ = syscall.Close(fd)
+			return nil, os.NewSyscallError("setsockopt", err)
+		}
+		sa = sa6
+	} else if wildcard {
+		if fd, err = socket(syscall.AF_INET); err != nil {
+			return nil, err
+		}
+		sa = &syscall.SockaddrInet4{Port: tcpAddr.Port}
+	} else {
+		return nil, err
+	}
+
+	if err := listenFD(fd, sa, opts); err != nil {
+		_ = syscall.Close(fd)
+		return nil, err
+	}
+
+	// FileListener duplicates the descriptor, so the file is closed either
+	// way.
+	f := os.NewFile(uintptr(fd), "tcp:"+addr)
+	defer f.Close()
+	return net.FileListener(f)
+}
+
+// socket creates a TCP socket of the address family.
+func socket(family int) (int, error) {
+	fd, err := syscall.Socket(family, syscall.SOCK_STREAM, syscall.IPPROTO_TCP)
+	if err != nil {
+		return 0, os.NewSyscallError("socket", err)
+	}
+	syscall.CloseOnExec(fd)
+	return fd, nil
+}
+
+// zoneID returns the index of the interface named by the zone of an IPv6
+// address, which is either the name or the index of the interface.
+func zoneID(zone string) (uint32, error) {
+	if zone == "" {
+		return 0, nil
+	}
+	if ifi, err := net.InterfaceByName(zone); err == nil {
+		return uint32(ifi.Index), nil
+	}
+	index, err := strconv.Atoi(zone)
+	if err != nil {
+		return 0, Errorf("unknown zone %q", zone)
+	}
+	return uint32(index), nil
+}
+
+// listenFD sets the socket options of the socket, binds it to the address
+// and listens on it.
+func listenFD(fd int, sa syscall.Sockaddr, opts ListenOptions) error {
+	if err := syscall.SetsockoptInt(fd, syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1); err != nil {
+		return os.NewSyscallError("setsockopt", err)
+	}
+	if opts.ReusePort {
+		if err := syscall.SetsockoptInt(fd, syscall.SOL_SOCKET, soReusePort, 1); err != nil {
+			return os.NewSyscallError("setsockopt", err)
+		}
+	}
+	if err := syscall.Bind(fd, sa); err != nil {
+		return os.NewSyscallError("bind", err)
+	}
+	backlog := opts.Backlog
+	if backlog <= 0 {
+		backlog = syscall.SOMAXCONN
+	}
+	if err := syscall.Listen(fd, backlog); err != nil {
+		return os.NewSyscallError("listen", err)
+	}
+	return nil
+}
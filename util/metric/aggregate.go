@@ -0,0 +1,136 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metric
+
+// An Aggregation determines how the values of a metric in several registries
+// with the same metrics, such as those of the stores of a node, are combined
+// into a single value.
+type Aggregation string
+
+// The aggregations of metrics.
+const (
+	// AggregateSum sums the values. It applies to all metrics for which no
+	// other aggregation was set.
+	AggregateSum Aggregation = "sum"
+	// AggregateMax takes the largest value.
+	AggregateMax Aggregation = "max"
+	// AggregateMin takes the smallest value.
+	AggregateMin Aggregation = "min"
+	// AggregateAvg takes the mean of the values.
+	AggregateAvg Aggregation = "avg"
+)
+
+// SetAggregation sets the aggregation of the metric registered with the
+// given name. Like metadata, it can be set before or after the metric is
+// registered.
+func (r *Registry) SetAggregation(name string, agg Aggregation) {
+	r.Lock()
+	defer r.Unlock()
+	r.aggregations[name] = agg
+}
+
+// eachAggregation calls the given closure for all the aggregations set in the
+// registry and its sub-registries, with the names of their metrics formatted
+// as they are by Each.
+func (r *Registry) eachAggregation(f func(name string, agg Aggregation)) {
+	r.Lock()
+	defer r.Unlock()
+	for name, agg := range r.aggregations {
+		f(name, agg)
+	}
+	for _, t := range r.tracked {
+		if sub, ok := t.item.(*Registry); ok {
+			format := t.format
+			sub.eachAggregation(func(name string, agg Aggregation) {
+				f(formatName(format, name), agg)
+			})
+		}
+	}
+}
+
+// aggregate accumulates the values of a metric.
+type aggregate struct {
+	agg   Aggregation
+	value float64
+	count int
+}
+
+func (a *aggregate) add(v float64) {
+	a.count++
+	switch {
+	case a.count == 1:
+		a.value = v
+	case a.agg == AggregateMax:
+		if v > a.value {
+			a.value = v
+		}
+	case a.agg == AggregateMin:
+		if v < a.value {
+			a.value = v
+		}
+	default:
+		a.value += v
+	}
+}
+
+func (a *aggregate) result() float64 {
+	if a.agg == AggregateAvg {
+		return a.value / float64(a.count)
+	}
+	return a.value
+}
+
+// Aggregate combines the current values of the counters, gauges and rates of
+// the given registries into a single value per metric, according to the
+// aggregations set in the registries. The values are keyed by the names of
+// the metrics, followed by their labels in braces if they have any.
+// Histograms are not aggregated.
+func Aggregate(registries ...*Registry) map[string]float64 {
+	aggs := make(map[string]*aggregate)
+	for _, reg := range registries {
+		rules := make(map[string]Aggregation)
+		reg.eachAggregation(func(name string, agg Aggregation) {
+			rules[name] = agg
+		})
+		add := func(name string, labels map[string]string, v float64) {
+			key := trackedKey(name, labels)
+			a, ok := aggs[key]
+			if !ok {
+				a = &aggregate{agg: AggregateSum}
+				if agg, ok := rules[name]; ok {
+					a.agg = agg
+				}
+				aggs[key] = a
+			}
+			a.add(v)
+		}
+		reg.Visit(MetricVisitor{
+			Counter: func(name string, labels map[string]string, c *Counter) {
+				add(name, labels, float64(c.Count()))
+			},
+			Gauge: func(name string, labels map[string]string, g *Gauge) {
+				add(name, labels, float64(g.Value()))
+			},
+			Rate: func(name string, labels map[string]string, r *Rate) {
+				add(name, labels, r.Value())
+			},
+		})
+	}
+	values := make(map[string]float64, len(aggs))
+	for key, a := range aggs {
+		values[key] = a.result()
+	}
+	return values
+}
@@ -0,0 +1,55 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metric
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAggregate(t *testing.T) {
+	var registries []*Registry
+	for i := int64(1); i <= 3; i++ {
+		r := NewRegistry()
+		r.SetAggregation("max", AggregateMax)
+		r.SetAggregation("min", AggregateMin)
+		r.SetAggregation("avg", AggregateAvg)
+		r.Counter("sum").Inc(i)
+		r.Gauge("max").Update(i)
+		r.Gauge("min").Update(i)
+		r.Gauge("avg").Update(i)
+		r.GaugeWithLabels("labeled", map[string]string{"kind": "a"}).Update(i)
+		r.Histogram("histogram", time.Minute, 1000, 1).RecordValue(i)
+
+		sub := NewRegistry()
+		sub.SetAggregation("max", AggregateMax)
+		sub.Gauge("max").Update(10 * i)
+		r.MustAdd("sub.%s", sub)
+		registries = append(registries, r)
+	}
+
+	expected := map[string]float64{
+		"sum":               6,
+		"max":               3,
+		"min":               1,
+		"avg":               2,
+		`labeled{kind="a"}`: 6,
+		"sub.max":           30,
+	}
+	if values := Aggregate(registries...); !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+}
@@ -41,6 +41,27 @@ accessed through the "selectCount" variable, which can be updated as follows:
 To add the metric to the web UI, modify the appropriate file in "ui/ts/pages/*.ts". Someone more
 qualified than me can elaborate, like @maxlang.
 
+Values which are already tracked elsewhere can be exported with GaugeFunc, which computes the value
+of the gauge by calling a closure whenever it is read instead of requiring the value to be copied
+into the gauge:
+
+	registry.GaugeFunc("goroutines", func() int64 { return int64(runtime.NumGoroutine()) })
+
+Rates registers a cumulative counter along with EWMA-based rates of its increase over several time
+windows, each exported under the name of the rates followed by the name of its window. Rates uses
+DefaultTimeScales; RatesWithScales accepts other windows, such as LoadAverageTimeScales for rates
+which should show both short-term spikes and longer trends:
+
+	queryCount: sqlRegistry.RatesWithScales("query", metric.LoadAverageTimeScales...)
+
+This registers "query-1m", "query-5m", "query-15m" and "query-count".
+
+Metrics can be described with their unit and a help string, which are served along with whether
+the metric is cumulative by the /_status/metrics/metadata endpoint so that the values can be
+rendered correctly:
+
+	registry.SetMetadata("livebytes", metric.Metadata{Unit: metric.UnitBytes, Help: "..."})
+
 Sub-registries
 
 It's common for a Registry to become part of another Registry through the "Add" and "MustAdd"
@@ -58,6 +79,47 @@ methods. For example:
 I recommend keeping a root-level registry (for CockroachDB, that's Server.registry) and creating
 a hierarchy of Registry instances underneath that to make your metrics more manageable.
 
+Walking a registry
+
+The metrics of a registry, including those of its sub-registries, can be enumerated with EachMetric,
+or with Visit, which passes each metric to the function matching its type:
+
+	registry.Visit(metric.MetricVisitor{
+		Counter: func(name string, labels map[string]string, c *metric.Counter) {
+			fmt.Println(name, c.Count())
+		},
+	})
+
+Labels
+
+Dimensions such as the store a metric belongs to can be attached as labels instead of being
+encoded in the metric's name. Labels are attached with the "WithLabels" variants of the
+registration methods, and the labels of a sub-registry apply to all of its metrics:
+
+	storeRegistry.CounterWithLabels("ranges.count", map[string]string{"store": "1"})
+	serverRegistry.MustAddWithLabels("%s", storeRegistry, map[string]string{"node": "1"})
+
+Labels are exported by the /_status/vars endpoint, which serves the metrics in the Prometheus
+text format.
+
+Pushing metrics
+
+Metrics can also be pushed periodically to monitoring systems which do not poll the HTTP endpoints.
+An Exporter walks a Registry at a fixed interval and pushes the values of its metrics to a Sink,
+such as the Graphite and StatsD sinks provided by this package:
+
+	sink := metric.NewGraphiteSink("graphite:2003", "cockroach")
+	metric.NewExporter(serverRegistry, sink, 10*time.Second).Start(stopper)
+
+Some monitoring systems expect the increments of counters rather than their totals. The Deltas
+method of a Registry resets its counters and returns the increments since the previous call. Since
+the time series recorded by the server are totals, Deltas must not be called on the registries of
+the server, only on registries dedicated to such a system.
+
+Labels are pushed as segments of the metric's path, so that the gauge above is pushed as
+"cockroach.node.1.store.1.ranges.count". The server pushes its metrics to the endpoints set in the
+COCKROACH_METRICS_GRAPHITE_ADDR and COCKROACH_METRICS_STATSD_ADDR environment variables.
+
 Testing
 
 After your test does something to trigger your new metric update, you'll
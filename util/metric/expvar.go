@@ -0,0 +1,99 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metric
+
+import (
+	"encoding/json"
+	"expvar"
+	"strconv"
+	"sync"
+)
+
+// expvarRegistry adapts the registry returned by a function to the
+// expvar.Var interface.
+type expvarRegistry func() *Registry
+
+// String implements the expvar.Var interface. It renders the current values
+// of the metrics of the registry, including those of nested registries, as a
+// JSON object keyed by the names of the metrics followed by their labels, e.g.
+// livebytes{store="1"}.
+func (f expvarRegistry) String() string {
+	values := make(map[string]interface{})
+	f().EachWithLabels(func(name string, labels map[string]string, v interface{}) {
+		values[name+withLabels(formatLabels(labels), "")] = v
+	})
+	b, err := json.Marshal(values)
+	if err != nil {
+		// expvar requires valid JSON, so the error is rendered as a string.
+		return strconv.Quote(err.Error())
+	}
+	return string(b)
+}
+
+// Expvar returns an expvar.Var exposing the metrics of the registry. The
+// metrics are read whenever the variable is rendered, so sub-registries added
+// to the registry after the variable is published are exposed as well.
+func (r *Registry) Expvar() expvar.Var {
+	return expvarRegistry(func() *Registry { return r })
+}
+
+// ExpvarFunc is like Registry.Expvar, but exposes the registry returned by
+// source whenever the variable is rendered, for registries which are
+// assembled on demand.
+func ExpvarFunc(source func() *Registry) expvar.Var {
+	return expvarRegistry(source)
+}
+
+// publishedVar is a published expvar.Var which delegates to a replaceable
+// variable, since expvar doesn't allow republishing a name.
+type publishedVar struct {
+	sync.Mutex
+	v expvar.Var
+}
+
+// String implements the expvar.Var interface.
+func (p *publishedVar) String() string {
+	p.Lock()
+	v := p.v
+	p.Unlock()
+	return v.String()
+}
+
+// published holds the variables published by PublishExpvar, by name.
+var published struct {
+	sync.Mutex
+	vars map[string]*publishedVar
+}
+
+// PublishExpvar publishes the variable under the given name, making it
+// available at /debug/vars. Unlike expvar.Publish, publishing a name again
+// replaces the previous variable instead of panicking, so that servers can be
+// restarted within a process.
+func PublishExpvar(name string, v expvar.Var) {
+	published.Lock()
+	defer published.Unlock()
+	if p, ok := published.vars[name]; ok {
+		p.Lock()
+		p.v = v
+		p.Unlock()
+		return
+	}
+	if published.vars == nil {
+		published.vars = make(map[string]*publishedVar)
+	}
+	p := &publishedVar{v: v}
+	published.vars[name] = p
+	expvar.Publish(name, p)
+}
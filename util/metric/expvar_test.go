@@ -0,0 +1,63 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metric
+
+import (
+	"encoding/json"
+	"expvar"
+	"reflect"
+	"testing"
+)
+
+func expvarValues(t *testing.T, name string) map[string]float64 {
+	v := expvar.Get(name)
+	if v == nil {
+		t.Fatalf("%s not published", name)
+	}
+	var values map[string]float64
+	if err := json.Unmarshal([]byte(v.String()), &values); err != nil {
+		t.Fatal(err)
+	}
+	return values
+}
+
+func TestPublishExpvar(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("counter").Inc(3)
+	PublishExpvar("test-registry", r.Expvar())
+
+	expected := map[string]float64{"counter": 3}
+	if values := expvarValues(t, "test-registry"); !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+
+	// Sub-registries added after publication are exposed as well.
+	sub := NewRegistry()
+	sub.Gauge("gauge").Update(5)
+	r.MustAddWithLabels("sub.%s", sub, map[string]string{"store": "1"})
+	expected = map[string]float64{"counter": 3, `sub.gauge{store="1"}`: 5}
+	if values := expvarValues(t, "test-registry"); !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+
+	// Publishing the name again replaces the variable.
+	other := NewRegistry()
+	other.Counter("other").Inc(1)
+	PublishExpvar("test-registry", ExpvarFunc(func() *Registry { return other }))
+	expected = map[string]float64{"other": 1}
+	if values := expvarValues(t, "test-registry"); !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+}
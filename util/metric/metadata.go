@@ -0,0 +1,95 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metric
+
+// Unit is the unit of the values of a metric.
+type Unit string
+
+// The units of metrics.
+const (
+	UnitCount       Unit = "count"
+	UnitBytes       Unit = "bytes"
+	UnitNanoseconds Unit = "nanoseconds"
+	UnitSeconds     Unit = "seconds"
+	UnitPerSecond   Unit = "per_second"
+	UnitTimestamp   Unit = "timestamp_ns"
+)
+
+// Metadata describes a metric, so that its values can be rendered without
+// knowing about the metric in advance.
+type Metadata struct {
+	// Help is a description of the metric.
+	Help string `json:"help,omitempty"`
+	// Unit is the unit of the values of the metric.
+	Unit Unit `json:"unit"`
+	// Cumulative is true if the metric only increases over time, in which
+	// case its rate of change is usually of more interest than its value.
+	// Metrics which are not cumulative are instantaneous measurements.
+	Cumulative bool `json:"cumulative"`
+}
+
+// defaultMetadata returns the metadata of metrics which were not described,
+// based on their type.
+func defaultMetadata(v interface{}) Metadata {
+	switch v.(type) {
+	case *Counter:
+		return Metadata{Unit: UnitCount, Cumulative: true}
+	case float64:
+		// Rates pass their current value rather than themselves.
+		return Metadata{Unit: UnitPerSecond}
+	}
+	return Metadata{Unit: UnitCount}
+}
+
+// SetMetadata describes the metric registered with the given name. The
+// metadata of a metric can be set before or after the metric is registered.
+func (r *Registry) SetMetadata(name string, metadata Metadata) {
+	r.Lock()
+	defer r.Unlock()
+	r.metadata[name] = metadata
+}
+
+// Metadata returns the metadata of all the metrics in the registry, keyed by
+// their names. Metrics which were not described get default metadata based
+// on their type. Metrics which only differ in their labels share metadata.
+func (r *Registry) Metadata() map[string]Metadata {
+	m := make(map[string]Metadata)
+	r.eachMetadata(func(name string, metadata Metadata) {
+		m[name] = metadata
+	})
+	return m
+}
+
+func (r *Registry) eachMetadata(f func(name string, metadata Metadata)) {
+	r.Lock()
+	defer r.Unlock()
+	for _, t := range r.tracked {
+		format := t.format
+		if sub, ok := t.item.(*Registry); ok {
+			sub.eachMetadata(func(name string, metadata Metadata) {
+				f(formatName(format, name), metadata)
+			})
+			continue
+		}
+		t.item.Each(func(name string, v interface{}) {
+			name = formatName(format, name)
+			metadata, ok := r.metadata[name]
+			if !ok {
+				metadata = defaultMetadata(v)
+			}
+			f(name, metadata)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metric
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRegistryMetadata(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("counter")
+	r.Gauge("gauge")
+	r.Rate("rate", time.Minute)
+	r.Latency("latency")
+
+	sub := NewRegistry()
+	sub.SetMetadata("bytes", Metadata{Help: "Some bytes", Unit: UnitBytes})
+	sub.Gauge("bytes")
+	r.MustAddWithLabels("sub.%s", sub, map[string]string{"store": "1"})
+
+	expected := map[string]Metadata{
+		"counter":            {Unit: UnitCount, Cumulative: true},
+		"gauge":              {Unit: UnitCount},
+		"rate":               {Unit: UnitPerSecond},
+		"latency-1m":         {Unit: UnitNanoseconds},
+		"latency-10m":        {Unit: UnitNanoseconds},
+		"latency-1h":         {Unit: UnitNanoseconds},
+		"latency-rate-count": {Unit: UnitCount, Cumulative: true},
+		"latency-rate-1m":    {Unit: UnitPerSecond},
+		"latency-rate-10m":   {Unit: UnitPerSecond},
+		"latency-rate-1h":    {Unit: UnitPerSecond},
+		"sub.bytes":          {Help: "Some bytes", Unit: UnitBytes},
+	}
+	if metadata := r.Metadata(); !reflect.DeepEqual(metadata, expected) {
+		t.Errorf("expected %+v, got %+v", expected, metadata)
+	}
+}
@@ -44,9 +44,15 @@ var (
 	// Scale1M is a 1 minute window for windowed stats (e.g. Rates and Histograms).
 	Scale1M = TimeScale{"1m", 1 * time.Minute}
 
+	// Scale5M is a 5 minute window for windowed stats (e.g. Rates and Histograms).
+	Scale5M = TimeScale{"5m", 5 * time.Minute}
+
 	// Scale10M is a 10 minute window for windowed stats (e.g. Rates and Histograms).
 	Scale10M = TimeScale{"10m", 10 * time.Minute}
 
+	// Scale15M is a 15 minute window for windowed stats (e.g. Rates and Histograms).
+	Scale15M = TimeScale{"15m", 15 * time.Minute}
+
 	// Scale1H is a 1 hour window for windowed stats (e.g. Rates and Histograms).
 	Scale1H = TimeScale{"1h", time.Hour}
 )
@@ -68,6 +74,24 @@ var _ json.Marshaler = &Histogram{}
 var _ json.Marshaler = &Rate{}
 var _ json.Marshaler = &Registry{}
 
+// Metric is a single metric which can be tracked by a Registry: a *Counter,
+// *Gauge, *Rate or *Histogram.
+type Metric interface {
+	Iterable
+	json.Marshaler
+	isMetric()
+}
+
+var _ Metric = &Gauge{}
+var _ Metric = &Counter{}
+var _ Metric = &Histogram{}
+var _ Metric = &Rate{}
+
+func (*Gauge) isMetric()     {}
+func (*Counter) isMetric()   {}
+func (*Histogram) isMetric() {}
+func (*Rate) isMetric()      {}
+
 type periodic interface {
 	nextTick() time.Time
 	tick()
@@ -169,6 +193,21 @@ func (hs Histograms) RecordValue(v int64) {
 	}
 }
 
+// Latency is a composite metric which records durations in histograms at
+// several time scales and counts them in rates, so that the latency and the
+// throughput of an operation are always tracked together.
+type Latency struct {
+	Histograms Histograms
+	Rates      Rates
+}
+
+// RecordValue records the given duration in each histogram and counts it in
+// the rates.
+func (l Latency) RecordValue(d time.Duration) {
+	l.Histograms.RecordValue(d.Nanoseconds())
+	l.Rates.Add(1)
+}
+
 // A Counter holds a single mutable atomic value.
 type Counter struct {
 	metrics.Counter
@@ -187,6 +226,16 @@ func (c *Counter) MarshalJSON() ([]byte, error) {
 	return json.Marshal(c.Counter.Count())
 }
 
+// SnapshotAndReset returns the current value of the counter and subtracts it
+// from the counter, so that the next call returns the increments made in the
+// meantime. Concurrent increments are never lost: each is either included in
+// the returned value or left in the counter for the next call.
+func (c *Counter) SnapshotAndReset() int64 {
+	v := c.Counter.Count()
+	c.Counter.Dec(v)
+	return v
+}
+
 // A Gauge atomically stores a single value.
 type Gauge struct {
 	metrics.Gauge
@@ -198,6 +247,12 @@ func NewGauge() *Gauge {
 	return g
 }
 
+// NewGaugeFunc creates a Gauge whose value is computed by calling fn each
+// time it is read. Such a Gauge must not be updated.
+func NewGaugeFunc(fn func() int64) *Gauge {
+	return &Gauge{funcGauge(fn)}
+}
+
 // Each calls the given closure with the empty string and itself.
 func (g *Gauge) Each(f func(string, interface{})) { f("", g) }
 
@@ -206,6 +261,18 @@ func (g *Gauge) MarshalJSON() ([]byte, error) {
 	return json.Marshal(g.Gauge.Value())
 }
 
+// funcGauge is a metrics.Gauge whose value is computed by a closure.
+type funcGauge func() int64
+
+// Snapshot returns a read-only copy of the gauge.
+func (g funcGauge) Snapshot() metrics.Gauge { return metrics.GaugeSnapshot(g()) }
+
+// Update panics: the value of a funcGauge is computed by its closure.
+func (funcGauge) Update(int64) { panic("Update called on a GaugeFunc") }
+
+// Value returns the value computed by the closure.
+func (g funcGauge) Value() int64 { return g() }
+
 // A Rate is a exponential weighted moving average.
 type Rate struct {
 	mu       sync.Mutex // protects fields below
@@ -41,6 +41,23 @@ func TestGauge(t *testing.T) {
 
 }
 
+func TestGaugeFunc(t *testing.T) {
+	var v int64 = 5
+	g := NewGaugeFunc(func() int64 { return v })
+	if actual := g.Value(); actual != 5 {
+		t.Fatalf("unexpected value: %d", actual)
+	}
+	// The value is computed each time the gauge is read.
+	v = 7
+	if actual := g.Value(); actual != 7 {
+		t.Fatalf("unexpected value: %d", actual)
+	}
+	if actual := g.Snapshot().Value(); actual != 7 {
+		t.Fatalf("unexpected snapshot value: %d", actual)
+	}
+	testMarshal(t, g, "7")
+}
+
 func TestCounter(t *testing.T) {
 	c := NewCounter()
 	c.Inc(100)
@@ -52,6 +69,21 @@ func TestCounter(t *testing.T) {
 	testMarshal(t, c, "90")
 }
 
+func TestCounterSnapshotAndReset(t *testing.T) {
+	c := NewCounter()
+	c.Inc(5)
+	if v := c.SnapshotAndReset(); v != 5 {
+		t.Fatalf("unexpected snapshot: %d", v)
+	}
+	c.Inc(3)
+	if v := c.SnapshotAndReset(); v != 3 {
+		t.Fatalf("unexpected snapshot: %d", v)
+	}
+	if v := c.Count(); v != 0 {
+		t.Fatalf("unexpected value after reset: %d", v)
+	}
+}
+
 func setNow(d time.Duration) {
 	now = func() time.Time {
 		return time.Time{}.Add(d)
@@ -93,6 +125,43 @@ func TestHistogramJSON(t *testing.T) {
 	testMarshal(t, h, `[{"Quantile":0,"Count":1,"ValueAt":1},{"Quantile":100,"Count":1,"ValueAt":1}]`)
 }
 
+func TestRatesWithScales(t *testing.T) {
+	setNow(0)
+	rs := NewRegistry().RatesWithScales("load", LoadAverageTimeScales...)
+	for _, r := range rs.Rates {
+		// Skip the warmup phase of the wrapped EWMA for this test.
+		for i := 0; i < 100; i++ {
+			r.wrapped.Add(0)
+		}
+	}
+
+	// valuesDecrease returns whether the rates over the given time scales
+	// have decreasing values.
+	valuesDecrease := func(scales ...TimeScale) bool {
+		for i := 1; i < len(scales); i++ {
+			if rs.Rates[scales[i-1]].Value() <= rs.Rates[scales[i]].Value() {
+				return false
+			}
+		}
+		return true
+	}
+
+	// A spike shows most on the shortest time scale...
+	rs.Add(600)
+	setNow(time.Second)
+	if !valuesDecrease(Scale1M, Scale5M, Scale15M) {
+		t.Errorf("expected the spike to show most in the shortest window")
+	}
+	// ...and is forgotten there first.
+	setNow(2 * time.Minute)
+	if !valuesDecrease(Scale15M, Scale5M, Scale1M) {
+		t.Errorf("expected the spike to be forgotten first in the shortest window")
+	}
+	if c := rs.Count(); c != 600 {
+		t.Errorf("expected count 600, got %d", c)
+	}
+}
+
 func TestRateRotate(t *testing.T) {
 	setNow(0)
 	const interval = 10 * time.Second
@@ -0,0 +1,188 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metric
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// PrometheusContentType is the content type of the Prometheus text
+// exposition format written by PrintAsPrometheus.
+const PrometheusContentType = "text/plain; version=0.0.4"
+
+// prometheusNameRE matches the characters which are not allowed in a
+// Prometheus metric name.
+var prometheusNameRE = regexp.MustCompile("[^a-zA-Z0-9_:]")
+
+// exportedName returns the Prometheus metric name for the given metric name.
+func exportedName(name string) string {
+	return prometheusNameRE.ReplaceAllString(name, "_")
+}
+
+// prometheusMetric is a metric to be written by PrintAsPrometheus.
+type prometheusMetric struct {
+	name   string
+	labels string
+	value  interface{}
+	// orig is the name of the metric in the registry.
+	orig string
+}
+
+// prometheusMetrics sorts metrics by name and labels, and metrics whose
+// exported names collide by their names in the registry.
+type prometheusMetrics []prometheusMetric
+
+func (m prometheusMetrics) Len() int      { return len(m) }
+func (m prometheusMetrics) Swap(i, j int) { m[i], m[j] = m[j], m[i] }
+func (m prometheusMetrics) Less(i, j int) bool {
+	if m[i].name != m[j].name {
+		return m[i].name < m[j].name
+	}
+	if m[i].labels != m[j].labels {
+		return m[i].labels < m[j].labels
+	}
+	return m[i].orig < m[j].orig
+}
+
+// withLabels returns the labels of a sample, enclosed in braces, formed by
+// the given labels and the extra label if it is not empty.
+func withLabels(labels, extra string) string {
+	switch {
+	case labels == "" && extra == "":
+		return ""
+	case labels == "":
+		return "{" + extra + "}"
+	case extra == "":
+		return "{" + labels + "}"
+	}
+	return "{" + labels + "," + extra + "}"
+}
+
+// prometheusBuckets returns the upper bounds of the buckets of a histogram
+// of values up to maxVal exported to Prometheus: the powers of two up to the
+// first one covering maxVal. They are fixed so that all scrapes export the
+// same buckets, as required to aggregate them.
+func prometheusBuckets(maxVal int64) []int64 {
+	var bounds []int64
+	for b := int64(1); ; b *= 2 {
+		bounds = append(bounds, b)
+		if b >= maxVal {
+			return bounds
+		}
+	}
+}
+
+// PrintAsPrometheus writes all the metrics in the registry to w in the
+// Prometheus text exposition format. Counters are exported as counters,
+// Gauges and Rates as gauges and Histograms as histograms of the current
+// window, with cumulative buckets bounded by the powers of two up to their
+// maximum value. The labels of the metrics are exported as Prometheus
+// labels. Metrics are written in the order of their names and labels.
+//
+// Metrics whose exported names and labels collide, or whose exported names
+// collide with those of metrics of another type, can't be told apart by
+// Prometheus. Only the first of them is written, and an error naming them is
+// returned once the other metrics are written.
+func (r *Registry) PrintAsPrometheus(w io.Writer) error {
+	var metrics prometheusMetrics
+	r.EachWithLabels(func(name string, labels map[string]string, v interface{}) {
+		metrics = append(metrics, prometheusMetric{
+			name:   exportedName(name),
+			labels: formatLabels(labels),
+			value:  v,
+			orig:   name,
+		})
+	})
+	sort.Sort(metrics)
+
+	bw := bufio.NewWriter(w)
+	var lastName, lastLabels, lastType string
+	var collisions []string
+	for _, m := range metrics {
+		var typ string
+		switch m.value.(type) {
+		case *Counter:
+			typ = "counter"
+		case *Gauge, float64:
+			typ = "gauge"
+		case *Histogram:
+			typ = "histogram"
+		default:
+			continue
+		}
+		// Metrics which only differ in their labels share a TYPE line.
+		if m.name == lastName {
+			if m.labels == lastLabels || typ != lastType {
+				collisions = append(collisions, m.orig+withLabels(m.labels, ""))
+				continue
+			}
+		} else {
+			fmt.Fprintf(bw, "# TYPE %s %s\n", m.name, typ)
+			lastName, lastType = m.name, typ
+		}
+		lastLabels = m.labels
+		labels := withLabels(m.labels, "")
+		switch v := m.value.(type) {
+		case *Counter:
+			fmt.Fprintf(bw, "%s%s %d\n", m.name, labels, v.Count())
+		case *Gauge:
+			fmt.Fprintf(bw, "%s%s %d\n", m.name, labels, v.Value())
+		case float64:
+			// Rates pass their current value rather than themselves.
+			fmt.Fprintf(bw, "%s%s %g\n", m.name, labels, v)
+		case *Histogram:
+			h := v.Current()
+			bounds := prometheusBuckets(v.maxVal)
+			counts := make([]int64, len(bounds))
+			var count int64
+			for _, bar := range h.Distribution() {
+				if bar.Count == 0 {
+					continue
+				}
+				count += bar.Count
+				// Values above the last bound are only counted by the +Inf
+				// bucket.
+				if i := sort.Search(len(bounds), func(i int) bool {
+					return bounds[i] >= bar.To
+				}); i < len(bounds) {
+					counts[i] += bar.Count
+				}
+			}
+			var cumulative int64
+			for i, b := range bounds {
+				cumulative += counts[i]
+				fmt.Fprintf(bw, "%s_bucket%s %d\n", m.name, withLabels(m.labels, fmt.Sprintf("le=\"%d\"", b)), cumulative)
+			}
+			fmt.Fprintf(bw, "%s_bucket%s %d\n", m.name, withLabels(m.labels, `le="+Inf"`), count)
+			// The sum is approximated from the mean, which the histogram tracks
+			// with the same precision as its values.
+			fmt.Fprintf(bw, "%s_sum%s %g\n", m.name, labels, h.Mean()*float64(count))
+			fmt.Fprintf(bw, "%s_count%s %d\n", m.name, labels, count)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	if len(collisions) > 0 {
+		return fmt.Errorf("colliding Prometheus metrics not exported: %s",
+			strings.Join(collisions, ", "))
+	}
+	return nil
+}
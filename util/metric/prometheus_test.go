@@ -0,0 +1,100 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metric
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrintAsPrometheus(t *testing.T) {
+	r := NewRegistry()
+	sub := NewRegistry()
+	r.MustAdd("sub.%s", sub)
+
+	r.Counter("top.counter").Inc(3)
+	r.CounterWithLabels("labeled.counter", map[string]string{"store": "2"}).Inc(2)
+	r.CounterWithLabels("labeled.counter", map[string]string{"store": "1"}).Inc(1)
+	sub.Gauge("gauge").Update(-5)
+	r.Rate("top.rate", time.Minute)
+	h := r.Histogram("top.hist", time.Minute, 1000, 3)
+	for _, v := range []int64{1, 10, 10, 100} {
+		h.RecordValue(v)
+	}
+
+	var buf bytes.Buffer
+	if err := r.PrintAsPrometheus(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"# TYPE labeled_counter counter",
+		`labeled_counter{store="1"} 1`,
+		`labeled_counter{store="2"} 2`,
+		"# TYPE sub_gauge gauge",
+		"sub_gauge -5",
+		"# TYPE top_counter counter",
+		"top_counter 3",
+		"# TYPE top_hist histogram",
+		`top_hist_bucket{le="1"} 1`,
+		`top_hist_bucket{le="2"} 1`,
+		`top_hist_bucket{le="4"} 1`,
+		`top_hist_bucket{le="8"} 1`,
+		`top_hist_bucket{le="16"} 3`,
+		`top_hist_bucket{le="32"} 3`,
+		`top_hist_bucket{le="64"} 3`,
+		`top_hist_bucket{le="128"} 4`,
+		`top_hist_bucket{le="256"} 4`,
+		`top_hist_bucket{le="512"} 4`,
+		`top_hist_bucket{le="1024"} 4`,
+		`top_hist_bucket{le="+Inf"} 4`,
+		"top_hist_sum 121",
+		"top_hist_count 4",
+		"# TYPE top_rate gauge",
+		"top_rate 0",
+	}
+	if actual := strings.Split(strings.TrimSpace(buf.String()), "\n"); strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), buf.String())
+	}
+}
+
+func TestPrintAsPrometheusCollisions(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("a.b").Inc(1)
+	r.Counter("a_b").Inc(2)
+	r.GaugeWithLabels("c", map[string]string{"store": "1"}).Update(3)
+	r.CounterWithLabels("c", map[string]string{"store": "2"}).Inc(4)
+	r.Counter("d").Inc(5)
+
+	var buf bytes.Buffer
+	err := r.PrintAsPrometheus(&buf)
+	if err == nil || !strings.Contains(err.Error(), "a_b") ||
+		!strings.Contains(err.Error(), `c{store="2"}`) {
+		t.Errorf("expected collisions of a_b and c to be reported, got %v", err)
+	}
+	// Only the first of the colliding metrics is written.
+	expected := []string{
+		"# TYPE a_b counter",
+		"a_b 1",
+		"# TYPE c gauge",
+		`c{store="1"} 3`,
+		"# TYPE d counter",
+		"d 5",
+	}
+	if actual := strings.Split(strings.TrimSpace(buf.String()), "\n"); strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), buf.String())
+	}
+}
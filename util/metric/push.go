@@ -0,0 +1,380 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metric
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/stop"
+)
+
+// pushDialTimeout bounds the time spent connecting to the endpoint of a Sink.
+const pushDialTimeout = 5 * time.Second
+
+// pushWriteTimeout bounds the time spent writing the metrics to the
+// connection of a Graphite Sink, so that an endpoint which stops reading
+// doesn't block the Exporter forever.
+const pushWriteTimeout = 10 * time.Second
+
+// statsDMaxPacketSize is the maximum size of the UDP packets sent to a StatsD
+// endpoint, chosen so that packets are not fragmented on common networks.
+const statsDMaxPacketSize = 1432
+
+// openTSDBMaxPoints is the maximum number of data points sent to an
+// OpenTSDB endpoint in a single request, as recommended by its documentation.
+const openTSDBMaxPoints = 50
+
+// pushNameRE matches the characters which are not allowed in the path of a
+// metric pushed to Graphite or StatsD.
+var pushNameRE = regexp.MustCompile("[^a-zA-Z0-9_.-]")
+
+// openTSDBNameRE matches the characters which are not allowed in the names
+// and tags of metrics pushed to OpenTSDB.
+var openTSDBNameRE = regexp.MustCompile("[^a-zA-Z0-9_./-]")
+
+// A Point is the value of a metric at the time it was pushed.
+type Point struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+// path returns the dot-separated path of the point, made of the prefix, the
+// labels sorted by name as name.value segments and the name of the point.
+func (p Point) path(prefix string) string {
+	var buf bytes.Buffer
+	if prefix != "" {
+		buf.WriteString(prefix)
+		buf.WriteByte('.')
+	}
+	names := make([]string, 0, len(p.Labels))
+	for name := range p.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		buf.WriteString(pushNameRE.ReplaceAllString(name, "_"))
+		buf.WriteByte('.')
+		buf.WriteString(pushNameRE.ReplaceAllString(p.Labels[name], "_"))
+		buf.WriteByte('.')
+	}
+	buf.WriteString(pushNameRE.ReplaceAllString(p.Name, "_"))
+	return buf.String()
+}
+
+// histogramPoints are the quantiles of Histograms which are pushed, along
+// with the suffixes of their names.
+var histogramPoints = []struct {
+	suffix   string
+	quantile float64
+}{
+	{"-max", 100},
+	{"-p99", 99},
+	{"-p90", 90},
+	{"-p50", 50},
+}
+
+// Points returns the current values of all the metrics in the registry.
+// Counters, Gauges and Rates have a single value, Histograms one value per
+// pushed quantile of their current window.
+func (r *Registry) Points() []Point {
+	var points []Point
+	r.Visit(MetricVisitor{
+		Counter: func(name string, labels map[string]string, c *Counter) {
+			points = append(points, Point{Name: name, Labels: labels, Value: float64(c.Count())})
+		},
+		Gauge: func(name string, labels map[string]string, g *Gauge) {
+			points = append(points, Point{Name: name, Labels: labels, Value: float64(g.Value())})
+		},
+		Rate: func(name string, labels map[string]string, rate *Rate) {
+			points = append(points, Point{Name: name, Labels: labels, Value: rate.Value()})
+		},
+		Histogram: func(name string, labels map[string]string, h *Histogram) {
+			current := h.Current()
+			for _, hp := range histogramPoints {
+				points = append(points, Point{
+					Name:   name + hp.suffix,
+					Labels: labels,
+					Value:  float64(current.ValueAtQuantile(hp.quantile)),
+				})
+			}
+		},
+	})
+	return points
+}
+
+// A Sink is an external monitoring system to which an Exporter pushes the
+// values of metrics.
+type Sink interface {
+	// Push sends the values of the metrics, taken at the given time.
+	Push(now time.Time, points []Point) error
+}
+
+// graphiteSink pushes metrics to Graphite using its plaintext protocol.
+type graphiteSink struct {
+	addr         string
+	prefix       string
+	writeTimeout time.Duration
+}
+
+// NewGraphiteSink returns a Sink which pushes metrics to the Graphite
+// endpoint at addr. The paths of the metrics start with the prefix.
+func NewGraphiteSink(addr, prefix string) Sink {
+	return graphiteSink{addr: addr, prefix: prefix, writeTimeout: pushWriteTimeout}
+}
+
+// Push implements the Sink interface. A new connection is established for
+// every push, so that a restart of the endpoint does not interrupt pushes
+// for longer than one interval.
+func (s graphiteSink) Push(now time.Time, points []Point) error {
+	conn, err := net.DialTimeout("tcp", s.addr, pushDialTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetWriteDeadline(time.Now().Add(s.writeTimeout)); err != nil {
+		return err
+	}
+	w := bufio.NewWriter(conn)
+	for _, p := range points {
+		if _, err := fmt.Fprintf(w, "%s %g %d\n", p.path(s.prefix), p.Value, now.Unix()); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// statsDSink pushes metrics to StatsD as gauges.
+type statsDSink struct {
+	addr   string
+	prefix string
+}
+
+// NewStatsDSink returns a Sink which pushes metrics to the StatsD endpoint
+// at addr. All metrics are pushed as gauges, since the values of Counters are
+// totals rather than increments. The names of the metrics start with the
+// prefix.
+func NewStatsDSink(addr, prefix string) Sink {
+	return statsDSink{addr: addr, prefix: prefix}
+}
+
+// Push implements the Sink interface. The metrics are batched into as few
+// packets as possible.
+func (s statsDSink) Push(_ time.Time, points []Point) error {
+	conn, err := net.DialTimeout("udp", s.addr, pushDialTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	var buf bytes.Buffer
+	for _, p := range points {
+		line := fmt.Sprintf("%s:%g|g\n", p.path(s.prefix), p.Value)
+		if buf.Len() > 0 && buf.Len()+len(line) > statsDMaxPacketSize {
+			if _, err := conn.Write(buf.Bytes()); err != nil {
+				return err
+			}
+			buf.Reset()
+		}
+		buf.WriteString(line)
+	}
+	if buf.Len() > 0 {
+		if _, err := conn.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openTSDBPoint is a data point in the JSON format of the HTTP API of
+// OpenTSDB.
+type openTSDBPoint struct {
+	Metric    string            `json:"metric"`
+	Timestamp int64             `json:"timestamp"`
+	Value     float64           `json:"value"`
+	Tags      map[string]string `json:"tags"`
+}
+
+// openTSDBPoints converts the points to OpenTSDB data points taken at the
+// given time. The names of the metrics start with the prefix and their labels
+// become tags. Points without a finite value, which cannot be encoded in
+// JSON, are skipped.
+func openTSDBPoints(now time.Time, prefix string, points []Point) []openTSDBPoint {
+	result := make([]openTSDBPoint, 0, len(points))
+	for _, p := range points {
+		if math.IsNaN(p.Value) || math.IsInf(p.Value, 0) {
+			continue
+		}
+		name := openTSDBNameRE.ReplaceAllString(p.Name, "_")
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		tags := make(map[string]string, len(p.Labels))
+		for k, v := range p.Labels {
+			tags[openTSDBNameRE.ReplaceAllString(k, "_")] = openTSDBNameRE.ReplaceAllString(v, "_")
+		}
+		result = append(result, openTSDBPoint{
+			Metric:    name,
+			Timestamp: now.Unix(),
+			Value:     p.Value,
+			Tags:      tags,
+		})
+	}
+	return result
+}
+
+// WriteOpenTSDB writes the points, taken at the given time, as a JSON array of
+// data points in the format accepted by the /api/put endpoint of OpenTSDB.
+// The names of the metrics start with the prefix and their labels become
+// tags. Note that OpenTSDB rejects data points without any tags.
+func WriteOpenTSDB(w io.Writer, now time.Time, prefix string, points []Point) error {
+	return json.NewEncoder(w).Encode(openTSDBPoints(now, prefix, points))
+}
+
+// PrintAsOpenTSDB writes the current values of the metrics in the registry
+// in the JSON format of OpenTSDB. See WriteOpenTSDB.
+func (r *Registry) PrintAsOpenTSDB(w io.Writer, now time.Time, prefix string) error {
+	return WriteOpenTSDB(w, now, prefix, r.Points())
+}
+
+// openTSDBSink pushes metrics to the HTTP API of OpenTSDB.
+type openTSDBSink struct {
+	addr   string
+	prefix string
+	client *http.Client
+}
+
+// NewOpenTSDBSink returns a Sink which pushes metrics to the OpenTSDB
+// endpoint at addr. The names of the metrics start with the prefix.
+func NewOpenTSDBSink(addr, prefix string) Sink {
+	return openTSDBSink{
+		addr:   addr,
+		prefix: prefix,
+		client: &http.Client{Timeout: pushDialTimeout},
+	}
+}
+
+// Push implements the Sink interface. The data points are sent in batches of
+// at most openTSDBMaxPoints.
+func (s openTSDBSink) Push(now time.Time, points []Point) error {
+	dataPoints := openTSDBPoints(now, s.prefix, points)
+	for len(dataPoints) > 0 {
+		n := len(dataPoints)
+		if n > openTSDBMaxPoints {
+			n = openTSDBMaxPoints
+		}
+		if err := s.post(dataPoints[:n]); err != nil {
+			return err
+		}
+		dataPoints = dataPoints[n:]
+	}
+	return nil
+}
+
+func (s openTSDBSink) post(dataPoints []openTSDBPoint) error {
+	body, err := json.Marshal(dataPoints)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Post("http://"+s.addr+"/api/put", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return util.Errorf("OpenTSDB endpoint %s returned %s: %s", s.addr, resp.Status, msg)
+	}
+	return nil
+}
+
+// NewSinkFromURL returns the Sink described by the URL, whose scheme is the
+// kind of the endpoint and whose host is its address, e.g.
+// opentsdb://localhost:4242, graphite://localhost:2003 or
+// statsd://localhost:8125. The names of the metrics start with the prefix.
+func NewSinkFromURL(sinkURL, prefix string) (Sink, error) {
+	u, err := url.Parse(sinkURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" {
+		return nil, util.Errorf("metrics sink %q does not specify an address", sinkURL)
+	}
+	switch u.Scheme {
+	case "opentsdb":
+		return NewOpenTSDBSink(u.Host, prefix), nil
+	case "graphite":
+		return NewGraphiteSink(u.Host, prefix), nil
+	case "statsd":
+		return NewStatsDSink(u.Host, prefix), nil
+	default:
+		return nil, util.Errorf("unsupported metrics sink %q", sinkURL)
+	}
+}
+
+// An Exporter periodically pushes the values of the metrics of a Registry
+// to a Sink.
+type Exporter struct {
+	registry *Registry
+	sink     Sink
+	interval time.Duration
+}
+
+// NewExporter creates an Exporter which pushes the metrics of the registry
+// to the sink at the given interval.
+func NewExporter(registry *Registry, sink Sink, interval time.Duration) *Exporter {
+	return &Exporter{
+		registry: registry,
+		sink:     sink,
+		interval: interval,
+	}
+}
+
+// Export pushes the current values of the metrics to the sink.
+func (e *Exporter) Export(now time.Time) error {
+	return e.sink.Push(now, e.registry.Points())
+}
+
+// Start starts a worker which pushes the metrics at every interval until the
+// stopper stops. Failed pushes are logged and retried at the next interval.
+func (e *Exporter) Start(stopper *stop.Stopper) {
+	stopper.RunWorker(func() {
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				if err := e.Export(now); err != nil {
+					log.Warningf("failed to push metrics: %s", err)
+				}
+			case <-stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,222 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metric
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newPushTestRegistry() *Registry {
+	r := NewRegistry()
+	r.Counter("top.counter").Inc(3)
+	sub := NewRegistry()
+	sub.Gauge("gauge").Update(-5)
+	r.MustAddWithLabels("sub.%s", sub, map[string]string{"store": "1"})
+	return r
+}
+
+func TestGraphiteSink(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			received <- err.Error()
+			return
+		}
+		defer conn.Close()
+		b, err := ioutil.ReadAll(conn)
+		if err != nil {
+			received <- err.Error()
+			return
+		}
+		received <- string(b)
+	}()
+
+	e := NewExporter(newPushTestRegistry(), NewGraphiteSink(ln.Addr().String(), "cr"), time.Minute)
+	if err := e.Export(time.Unix(1000, 0)); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(<-received), "\n")
+	sort.Strings(lines)
+	expected := []string{
+		"cr.store.1.sub.gauge -5 1000",
+		"cr.top.counter 3 1000",
+	}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("expected %q, got %q", expected, lines)
+	}
+}
+
+func TestStatsDSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	e := NewExporter(newPushTestRegistry(), NewStatsDSink(conn.LocalAddr().String(), ""), time.Minute)
+	if err := e.Export(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, statsDMaxPacketSize)
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(buf[:n])), "\n")
+	sort.Strings(lines)
+	expected := []string{
+		"store.1.sub.gauge:-5|g",
+		"top.counter:3|g",
+	}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("expected %q, got %q", expected, lines)
+	}
+}
+
+func TestOpenTSDBSink(t *testing.T) {
+	received := make(chan []openTSDBPoint, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/put" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		var dataPoints []openTSDBPoint
+		if err := json.NewDecoder(r.Body).Decode(&dataPoints); err != nil {
+			t.Error(err)
+		}
+		received <- dataPoints
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	sink, err := NewSinkFromURL("opentsdb://"+ts.Listener.Addr().String(), "cr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := NewExporter(newPushTestRegistry(), sink, time.Minute)
+	if err := e.Export(time.Unix(1000, 0)); err != nil {
+		t.Fatal(err)
+	}
+	dataPoints := make(map[string]openTSDBPoint)
+	for _, p := range <-received {
+		dataPoints[p.Metric] = p
+	}
+	expected := map[string]openTSDBPoint{
+		"cr.sub.gauge":   {Metric: "cr.sub.gauge", Timestamp: 1000, Value: -5, Tags: map[string]string{"store": "1"}},
+		"cr.top.counter": {Metric: "cr.top.counter", Timestamp: 1000, Value: 3, Tags: map[string]string{}},
+	}
+	if !reflect.DeepEqual(dataPoints, expected) {
+		t.Errorf("expected %+v, got %+v", expected, dataPoints)
+	}
+}
+
+// TestGraphiteSinkWriteTimeout verifies that pushing to a Graphite endpoint
+// which doesn't read the metrics times out.
+func TestGraphiteSinkWriteTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			close(accepted)
+			return
+		}
+		accepted <- conn
+	}()
+	defer func() {
+		if conn, ok := <-accepted; ok {
+			conn.Close()
+		}
+	}()
+
+	// Push more than fits into the socket buffers.
+	name := strings.Repeat("x", 1<<10)
+	points := make([]Point, 1<<16)
+	for i := range points {
+		points[i] = Point{Name: name}
+	}
+	sink := graphiteSink{addr: ln.Addr().String(), writeTimeout: 50 * time.Millisecond}
+	err = sink.Push(time.Unix(1000, 0), points)
+	if netErr, ok := err.(net.Error); !ok || !netErr.Timeout() {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+}
+
+func TestNewSinkFromURL(t *testing.T) {
+	testCases := []struct {
+		url      string
+		expected Sink
+	}{
+		{"opentsdb://localhost:4242", NewOpenTSDBSink("localhost:4242", "p")},
+		{"graphite://localhost:2003", NewGraphiteSink("localhost:2003", "p")},
+		{"statsd://localhost:8125", NewStatsDSink("localhost:8125", "p")},
+		{"opentsdb://", nil},
+		{"influx://localhost:8086", nil},
+	}
+	for _, tc := range testCases {
+		sink, err := NewSinkFromURL(tc.url, "p")
+		if tc.expected == nil {
+			if err == nil {
+				t.Errorf("%s: expected an error", tc.url)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tc.url, err)
+			continue
+		}
+		if reflect.TypeOf(sink) != reflect.TypeOf(tc.expected) {
+			t.Errorf("%s: expected %T, got %T", tc.url, tc.expected, sink)
+		}
+	}
+}
+
+func TestRegistryPointsHistogram(t *testing.T) {
+	r := NewRegistry()
+	h := r.Histogram("hist", time.Minute, 1000, 3)
+	h.RecordValue(10)
+	var names []string
+	for _, p := range r.Points() {
+		names = append(names, p.Name)
+		if p.Value != 10 {
+			t.Errorf("expected %s to be 10, got %g", p.Name, p.Value)
+		}
+	}
+	expected := []string{"hist-max", "hist-p99", "hist-p90", "hist-p50"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}
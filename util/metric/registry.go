@@ -17,9 +17,11 @@
 package metric
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 )
@@ -30,21 +32,80 @@ const sep = "-"
 // metrics in bulk (such as Latency or Rates).
 var DefaultTimeScales = []TimeScale{Scale1M, Scale10M, Scale1H}
 
+// LoadAverageTimeScales are the durations of the windows of the classic load
+// average, for rates which should show both short-term spikes and longer
+// trends.
+var LoadAverageTimeScales = []TimeScale{Scale1M, Scale5M, Scale15M}
+
 // A Registry bundles up various iterables (i.e. typically metrics or other
 // registries) to provide a single point of access to them.
 //
 // A Registry can be added to another Registry through the Add/MustAdd methods. This allows a
 // hierarchy of Registry instances to be created.
+//
+// Items can also be added with a set of labels, which carry dimensions such as
+// the store a metric belongs to without encoding them in the metric's name.
+// The labels of a Registry apply to all the items it contains.
 type Registry struct {
 	sync.Mutex
-	tracked map[string]Iterable
+	// tracked is keyed by the format and labels of the items.
+	tracked map[string]trackedItem
+	// metadata is keyed by the names of the metrics it describes.
+	metadata map[string]Metadata
+	// aggregations is keyed by the names of the metrics they apply to.
+	aggregations map[string]Aggregation
+}
+
+// trackedItem is an Iterable tracked by a Registry, along with the format and
+// labels it was added with.
+type trackedItem struct {
+	format string
+	labels map[string]string
+	item   Iterable
 }
 
 // NewRegistry creates a new Registry.
 func NewRegistry() *Registry {
 	return &Registry{
-		tracked: map[string]Iterable{},
+		tracked:      map[string]trackedItem{},
+		metadata:     map[string]Metadata{},
+		aggregations: map[string]Aggregation{},
+	}
+}
+
+// trackedKey returns the key of an item with the given format and labels.
+func trackedKey(format string, labels map[string]string) string {
+	if len(labels) == 0 {
+		return format
+	}
+	return format + "{" + formatLabels(labels) + "}"
+}
+
+// formatLabels formats the labels as a comma-separated list of name="value"
+// pairs sorted by name.
+func formatLabels(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buf bytes.Buffer
+	for i, name := range names {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		fmt.Fprintf(&buf, "%s=%q", name, labels[name])
+	}
+	return buf.String()
+}
+
+// formatName formats the name of an item contained in an item tracked with
+// the given format.
+func formatName(format, name string) string {
+	if name == "" {
+		return format
 	}
+	return fmt.Sprintf(format, name)
 }
 
 // Add links the given Iterable into this registry using the given format
@@ -55,37 +116,243 @@ func NewRegistry() *Registry {
 // and registered in a single step. Add is called manually only when adding
 // a registry to another, or when integrating metrics defined elsewhere.
 func (r *Registry) Add(format string, item Iterable) error {
+	return r.AddWithLabels(format, item, nil)
+}
+
+// AddWithLabels is like Add, but attaches the given labels to the item. The
+// same format string can be used by several items as long as their labels
+// differ.
+func (r *Registry) AddWithLabels(format string, item Iterable, labels map[string]string) error {
 	r.Lock()
 	defer r.Unlock()
-	if _, ok := r.tracked[format]; ok {
+	key := trackedKey(format, labels)
+	if _, ok := r.tracked[key]; ok {
 		return errors.New("format string already in use")
 	}
-	r.tracked[format] = item
+	r.tracked[key] = trackedItem{format: format, labels: labels, item: item}
 	return nil
 }
 
-// MustAdd calls Add and panics on error.
+// MustAdd calls Add and panics on error. As an exception, a Registry added
+// with a format string already used by another Registry replaces it, so that
+// recreated components (such as restarted stores) don't leave their stale
+// metrics behind.
 func (r *Registry) MustAdd(format string, item Iterable) {
-	if err := r.Add(format, item); err != nil {
-		panic(fmt.Sprintf("error adding %s: %s", format, err))
+	r.MustAddWithLabels(format, item, nil)
+}
+
+// MustAddWithLabels calls AddWithLabels and panics on error. Like MustAdd, it
+// replaces a Registry previously added with the same format and labels.
+func (r *Registry) MustAddWithLabels(format string, item Iterable, labels map[string]string) {
+	if sub, ok := item.(*Registry); ok && r.replaceSub(format, sub, labels) {
+		return
+	}
+	if err := r.AddWithLabels(format, item, labels); err != nil {
+		panic(fmt.Sprintf("error adding %s: %s", trackedKey(format, labels), err))
 	}
 }
 
-// Each calls the given closure for all metrics.
+// replaceSub replaces the Registry tracked with the given format and labels
+// by sub, returning false if no Registry is tracked with them.
+func (r *Registry) replaceSub(format string, sub *Registry, labels map[string]string) bool {
+	r.Lock()
+	defer r.Unlock()
+	key := trackedKey(format, labels)
+	t, ok := r.tracked[key]
+	if !ok {
+		return false
+	}
+	if _, ok := t.item.(*Registry); !ok {
+		return false
+	}
+	r.tracked[key] = trackedItem{format: format, labels: labels, item: sub}
+	return true
+}
+
+// Remove unregisters the items added with the given name, regardless of their
+// labels, along with the metadata of the name. It returns whether any item
+// was removed. Sub-registries are removed with RemoveSub.
+func (r *Registry) Remove(name string) bool {
+	r.Lock()
+	defer r.Unlock()
+	delete(r.metadata, name)
+	return r.removeLocked(name, func(item Iterable) bool {
+		_, ok := item.(*Registry)
+		return !ok
+	})
+}
+
+// RemoveSub unregisters the sub-registries added with the given prefix format,
+// regardless of their labels. It returns whether any sub-registry was
+// removed.
+func (r *Registry) RemoveSub(prefix string) bool {
+	r.Lock()
+	defer r.Unlock()
+	return r.removeLocked(prefix, func(item Iterable) bool {
+		_, ok := item.(*Registry)
+		return ok
+	})
+}
+
+// removeLocked removes the items tracked with the given format for which
+// match returns true. r must be locked.
+func (r *Registry) removeLocked(format string, match func(Iterable) bool) bool {
+	removed := false
+	for key, t := range r.tracked {
+		if t.format == format && match(t.item) {
+			delete(r.tracked, key)
+			removed = true
+		}
+	}
+	return removed
+}
+
+// Each calls the given closure for all metrics. Labels are ignored; use
+// EachWithLabels to retrieve them.
 func (r *Registry) Each(f func(name string, val interface{})) {
 	r.Lock()
 	defer r.Unlock()
-	for format, registry := range r.tracked {
-		registry.Each(func(name string, v interface{}) {
-			if name == "" {
-				f(format, v)
-			} else {
-				f(fmt.Sprintf(format, name), v)
-			}
+	for _, t := range r.tracked {
+		format := t.format
+		t.item.Each(func(name string, v interface{}) {
+			f(formatName(format, name), v)
 		})
 	}
 }
 
+// EachWithLabels calls the given closure for all metrics along with their
+// labels, which include the labels of the registries they are contained in.
+// The closure must not modify the labels.
+func (r *Registry) EachWithLabels(f func(name string, labels map[string]string, val interface{})) {
+	r.eachWithLabels(nil, f)
+}
+
+func (r *Registry) eachWithLabels(
+	parentLabels map[string]string, f func(name string, labels map[string]string, val interface{})) {
+	r.Lock()
+	defer r.Unlock()
+	for _, t := range r.tracked {
+		format := t.format
+		labels := mergeLabels(parentLabels, t.labels)
+		if sub, ok := t.item.(*Registry); ok {
+			sub.eachWithLabels(labels, func(name string, labels map[string]string, v interface{}) {
+				f(formatName(format, name), labels, v)
+			})
+			continue
+		}
+		t.item.Each(func(name string, v interface{}) {
+			f(formatName(format, name), labels, v)
+		})
+	}
+}
+
+// mergeLabels returns the labels of an item with the given labels contained
+// in a registry with the parent labels.
+func mergeLabels(parentLabels, labels map[string]string) map[string]string {
+	if len(labels) == 0 {
+		return parentLabels
+	}
+	merged := make(map[string]string, len(parentLabels)+len(labels))
+	for k, v := range parentLabels {
+		merged[k] = v
+	}
+	for k, v := range labels {
+		merged[k] = v
+	}
+	return merged
+}
+
+// EachMetric calls the given closure for all metrics, including those of
+// nested registries. Unlike Each, it passes Rates themselves rather than
+// their current value, so that all metrics are passed as a Metric.
+func (r *Registry) EachMetric(f func(name string, m Metric)) {
+	r.eachMetric(nil, func(name string, _ map[string]string, m Metric) {
+		f(name, m)
+	})
+}
+
+// A MetricVisitor receives the metrics of a Registry according to their
+// type when passed to Visit. Nil functions are skipped. The functions must
+// not modify the labels.
+type MetricVisitor struct {
+	Counter   func(name string, labels map[string]string, c *Counter)
+	Gauge     func(name string, labels map[string]string, g *Gauge)
+	Rate      func(name string, labels map[string]string, r *Rate)
+	Histogram func(name string, labels map[string]string, h *Histogram)
+}
+
+// Visit calls the function of the visitor matching the type of each metric
+// in the registry, including those of nested registries, along with the
+// labels of the metric.
+func (r *Registry) Visit(v MetricVisitor) {
+	r.eachMetric(nil, func(name string, labels map[string]string, m Metric) {
+		switch mtr := m.(type) {
+		case *Counter:
+			if v.Counter != nil {
+				v.Counter(name, labels, mtr)
+			}
+		case *Gauge:
+			if v.Gauge != nil {
+				v.Gauge(name, labels, mtr)
+			}
+		case *Rate:
+			if v.Rate != nil {
+				v.Rate(name, labels, mtr)
+			}
+		case *Histogram:
+			if v.Histogram != nil {
+				v.Histogram(name, labels, mtr)
+			}
+		}
+	})
+}
+
+func (r *Registry) eachMetric(
+	parentLabels map[string]string, f func(name string, labels map[string]string, m Metric)) {
+	r.Lock()
+	defer r.Unlock()
+	for _, t := range r.tracked {
+		format := t.format
+		labels := mergeLabels(parentLabels, t.labels)
+		switch item := t.item.(type) {
+		case *Registry:
+			item.eachMetric(labels, func(name string, labels map[string]string, m Metric) {
+				f(formatName(format, name), labels, m)
+			})
+		case Metric:
+			f(formatName(format, ""), labels, item)
+		default:
+			item.Each(func(name string, v interface{}) {
+				if m, ok := v.(Metric); ok {
+					f(formatName(format, name), labels, m)
+				}
+			})
+		}
+	}
+}
+
+// Deltas resets all the counters of the registry, including those of its
+// sub-registries, and returns the values they had, i.e. the increments since
+// the previous call, for exporters which push increments rather than totals.
+// The values are keyed by the names of the counters, followed by their labels
+// in braces if they have any.
+//
+// Resetting a counter affects all of its readers. In particular, the time
+// series recorder of the server (status.MetricsRecorder) records counters as
+// totals, so the time series of the counters of a registry it records would
+// drop to zero after every call. Deltas should thus only be called on
+// registries which are not recorded, such as one dedicated to a delta-based
+// exporter.
+func (r *Registry) Deltas() map[string]int64 {
+	deltas := make(map[string]int64)
+	r.Visit(MetricVisitor{
+		Counter: func(name string, labels map[string]string, c *Counter) {
+			deltas[trackedKey(name, labels)] = c.SnapshotAndReset()
+		},
+	})
+	return deltas
+}
+
 // MarshalJSON marshals to JSON.
 func (r *Registry) MarshalJSON() ([]byte, error) {
 	m := make(map[string]interface{})
@@ -104,27 +371,53 @@ func (r *Registry) Histogram(name string, duration time.Duration, maxVal int64,
 	return h
 }
 
-// Latency is a convenience function which registers histograms with
-// suitable defaults for latency tracking. Values are expressed in ns,
-// are truncated into the interval [0, time.Minute] and are recorded
-// with two digits of precision (i.e. errors of <1ms at 100ms, <.6s at 1m).
-// The generated names of the metric will begin with the given prefix.
+// GetHistogram returns the Histogram in this registry with the given name. If
+// a Histogram with this name is not present (including if a non-Histogram
+// Iterable is registered with the name), nil is returned.
+func (r *Registry) GetHistogram(name string) *Histogram {
+	r.Lock()
+	defer r.Unlock()
+	t, ok := r.tracked[name]
+	if !ok {
+		return nil
+	}
+	histogram, ok := t.item.(*Histogram)
+	if !ok {
+		return nil
+	}
+	return histogram
+}
+
+// Latency is a convenience function which registers a Latency, whose
+// histograms have suitable defaults for latency tracking. Values are
+// expressed in ns, are truncated into the interval [0, time.Minute] and are
+// recorded with two digits of precision (i.e. errors of <1ms at 100ms, <.6s
+// at 1m). The generated names of the histograms will begin with the given
+// prefix, and those of the rates with the prefix followed by "rate".
 //
 // TODO(mrtracy,tschottdorf): need to discuss roll-ups and generally how (and
 // which) information flows between metrics and time series.
-func (r *Registry) Latency(prefix string) Histograms {
+func (r *Registry) Latency(prefix string) Latency {
 	windows := DefaultTimeScales
 	hs := make(Histograms)
 	for _, w := range windows {
-		hs[w] = r.Histogram(prefix+sep+w.name, w.d, int64(time.Minute), 2)
+		name := prefix + sep + w.name
+		hs[w] = r.Histogram(name, w.d, int64(time.Minute), 2)
+		r.SetMetadata(name, Metadata{Unit: UnitNanoseconds})
 	}
-	return hs
+	return Latency{Histograms: hs, Rates: r.Rates(prefix + sep + "rate")}
 }
 
 // Counter registers new counter to the registry.
 func (r *Registry) Counter(name string) *Counter {
+	return r.CounterWithLabels(name, nil)
+}
+
+// CounterWithLabels registers a new counter with the given labels to the
+// registry.
+func (r *Registry) CounterWithLabels(name string, labels map[string]string) *Counter {
 	c := NewCounter()
-	r.MustAdd(name, c)
+	r.MustAddWithLabels(name, c, labels)
 	return c
 }
 
@@ -134,11 +427,11 @@ func (r *Registry) Counter(name string) *Counter {
 func (r *Registry) GetCounter(name string) *Counter {
 	r.Lock()
 	defer r.Unlock()
-	iterable, ok := r.tracked[name]
+	t, ok := r.tracked[name]
 	if !ok {
 		return nil
 	}
-	counter, ok := iterable.(*Counter)
+	counter, ok := t.item.(*Counter)
 	if !ok {
 		return nil
 	}
@@ -147,7 +440,22 @@ func (r *Registry) GetCounter(name string) *Counter {
 
 // Gauge registers a new Gauge with the given name.
 func (r *Registry) Gauge(name string) *Gauge {
+	return r.GaugeWithLabels(name, nil)
+}
+
+// GaugeWithLabels registers a new Gauge with the given name and labels.
+func (r *Registry) GaugeWithLabels(name string, labels map[string]string) *Gauge {
 	g := NewGauge()
+	r.MustAddWithLabels(name, g, labels)
+	return g
+}
+
+// GaugeFunc registers a new Gauge with the given name whose value is computed
+// by calling fn whenever the Gauge is read, e.g. when the metrics are
+// recorded or exported. This avoids having to copy values which are already
+// tracked elsewhere into a Gauge. fn must be safe for concurrent use.
+func (r *Registry) GaugeFunc(name string, fn func() int64) *Gauge {
+	g := NewGaugeFunc(fn)
 	r.MustAdd(name, g)
 	return g
 }
@@ -158,11 +466,11 @@ func (r *Registry) Gauge(name string) *Gauge {
 func (r *Registry) GetGauge(name string) *Gauge {
 	r.Lock()
 	defer r.Unlock()
-	iterable, ok := r.tracked[name]
+	t, ok := r.tracked[name]
 	if !ok {
 		return nil
 	}
-	gauge, ok := iterable.(*Gauge)
+	gauge, ok := t.item.(*Gauge)
 	if !ok {
 		return nil
 	}
@@ -183,11 +491,11 @@ func (r *Registry) Rate(name string, timescale time.Duration) *Rate {
 func (r *Registry) GetRate(name string) *Rate {
 	r.Lock()
 	defer r.Unlock()
-	iterable, ok := r.tracked[name]
+	t, ok := r.tracked[name]
 	if !ok {
 		return nil
 	}
-	rate, ok := iterable.(*Rate)
+	rate, ok := t.item.(*Rate)
 	if !ok {
 		return nil
 	}
@@ -197,7 +505,15 @@ func (r *Registry) GetRate(name string) *Rate {
 // Rates registers and returns a new Rates instance, which contains a set of EWMA-based rates
 // with generally useful time scales and a cumulative counter.
 func (r *Registry) Rates(prefix string) Rates {
-	scales := DefaultTimeScales
+	return r.RatesWithScales(prefix, DefaultTimeScales...)
+}
+
+// RatesWithScales registers and returns a new Rates instance, which contains
+// an EWMA-based rate for each of the given time scales and a cumulative
+// counter. The names of the rates are the prefix followed by the names of
+// their time scales, and the name of the counter is the prefix followed by
+// "count".
+func (r *Registry) RatesWithScales(prefix string, scales ...TimeScale) Rates {
 	es := make(map[TimeScale]*Rate)
 	for _, scale := range scales {
 		es[scale] = r.Rate(prefix+sep+scale.name, scale.d)
@@ -17,6 +17,8 @@
 package metric
 
 import (
+	"reflect"
+	"sort"
 	"testing"
 	"time"
 )
@@ -29,6 +31,7 @@ func TestRegistry(t *testing.T) {
 	topCounter := r.Counter("top.counter")
 	topRate := r.Rate("top.rate", time.Minute)
 	_ = r.Rates("top.rates")
+	_ = r.RatesWithScales("top.load", LoadAverageTimeScales...)
 	_ = r.Histogram("top.hist", time.Minute, 1000, 3)
 	_ = r.Latency("top.latency")
 
@@ -40,22 +43,30 @@ func TestRegistry(t *testing.T) {
 	_ = sub.Rates("rates")
 
 	expNames := map[string]struct{}{
-		"top.rate":             {},
-		"top.rates-count":      {},
-		"top.rates-1m":         {},
-		"top.rates-10m":        {},
-		"top.rates-1h":         {},
-		"top.hist":             {},
-		"top.latency-1m":       {},
-		"top.latency-10m":      {},
-		"top.latency-1h":       {},
-		"top.gauge":            {},
-		"top.counter":          {},
-		"bottom.gauge#1":       {},
-		"bottom.rates-count#1": {},
-		"bottom.rates-1m#1":    {},
-		"bottom.rates-10m#1":   {},
-		"bottom.rates-1h#1":    {},
+		"top.rate":               {},
+		"top.rates-count":        {},
+		"top.rates-1m":           {},
+		"top.rates-10m":          {},
+		"top.rates-1h":           {},
+		"top.load-count":         {},
+		"top.load-1m":            {},
+		"top.load-5m":            {},
+		"top.load-15m":           {},
+		"top.hist":               {},
+		"top.latency-1m":         {},
+		"top.latency-10m":        {},
+		"top.latency-1h":         {},
+		"top.latency-rate-count": {},
+		"top.latency-rate-1m":    {},
+		"top.latency-rate-10m":   {},
+		"top.latency-rate-1h":    {},
+		"top.gauge":              {},
+		"top.counter":            {},
+		"bottom.gauge#1":         {},
+		"bottom.rates-count#1":   {},
+		"bottom.rates-1m#1":      {},
+		"bottom.rates-10m#1":     {},
+		"bottom.rates-1h#1":      {},
 	}
 
 	r.Each(func(name string, _ interface{}) {
@@ -99,3 +110,160 @@ func TestRegistry(t *testing.T) {
 		t.Errorf("GetRate returned non-nil %v of type %T when requesting non-rate, expected nil", r, r)
 	}
 }
+
+func TestRegistryLabels(t *testing.T) {
+	r := NewRegistry()
+	r.CounterWithLabels("ranges.count", map[string]string{"store": "1"})
+	r.CounterWithLabels("ranges.count", map[string]string{"store": "2"})
+	if err := r.AddWithLabels("ranges.count", NewCounter(), map[string]string{"store": "1"}); err == nil {
+		t.Fatalf("expected failure on double-add")
+	}
+	r.Gauge("gauge")
+
+	sub := NewRegistry()
+	sub.GaugeWithLabels("gauge", map[string]string{"range": "3"})
+	r.MustAddWithLabels("sub.%s", sub, map[string]string{"store": "3"})
+
+	expLabels := map[string]struct{}{
+		`ranges.count{store="1"}`:        {},
+		`ranges.count{store="2"}`:        {},
+		`gauge`:                          {},
+		`sub.gauge{range="3",store="3"}`: {},
+	}
+	r.EachWithLabels(func(name string, labels map[string]string, _ interface{}) {
+		key := trackedKey(name, labels)
+		if _, exist := expLabels[key]; !exist {
+			t.Errorf("unexpected metric: %s", key)
+		}
+		delete(expLabels, key)
+	})
+	if len(expLabels) > 0 {
+		t.Fatalf("missed metrics: %v", expLabels)
+	}
+}
+
+func TestRegistryVisit(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("counter").Inc(2)
+	r.Gauge("gauge").Update(3)
+	r.Rate("rate", time.Minute)
+	h := r.Histogram("hist", time.Minute, 1000, 3)
+	sub := NewRegistry()
+	sub.Counter("counter")
+	r.MustAddWithLabels("sub.%s", sub, map[string]string{"store": "1"})
+
+	metrics := map[string]Metric{}
+	r.EachMetric(func(name string, m Metric) {
+		metrics[name] = m
+	})
+	for name, expected := range map[string]Metric{
+		"counter":     r.GetCounter("counter"),
+		"gauge":       r.GetGauge("gauge"),
+		"rate":        r.GetRate("rate"),
+		"hist":        h,
+		"sub.counter": sub.GetCounter("counter"),
+	} {
+		if metrics[name] != expected {
+			t.Errorf("expected %s to be %v, got %v", name, expected, metrics[name])
+		}
+	}
+	if len(metrics) != 5 {
+		t.Errorf("expected 5 metrics, got %v", metrics)
+	}
+	if r.GetHistogram("hist") != h || r.GetHistogram("counter") != nil {
+		t.Error("unexpected histograms returned by GetHistogram")
+	}
+
+	var counters []string
+	var gaugeValue int64
+	r.Visit(MetricVisitor{
+		Counter: func(name string, labels map[string]string, c *Counter) {
+			counters = append(counters, name+formatLabels(labels))
+		},
+		Gauge: func(_ string, _ map[string]string, g *Gauge) {
+			gaugeValue = g.Value()
+		},
+	})
+	sort.Strings(counters)
+	if expected := []string{"counter", `sub.counterstore="1"`}; !reflect.DeepEqual(counters, expected) {
+		t.Errorf("expected counters %v, got %v", expected, counters)
+	}
+	if gaugeValue != 3 {
+		t.Errorf("expected gauge value 3, got %d", gaugeValue)
+	}
+}
+
+func TestRegistryDeltas(t *testing.T) {
+	r := NewRegistry()
+	c := r.Counter("counter")
+	r.Gauge("gauge").Update(3)
+	sub := NewRegistry()
+	subC := sub.Counter("counter")
+	r.MustAddWithLabels("sub.%s", sub, map[string]string{"store": "1"})
+
+	c.Inc(2)
+	subC.Inc(5)
+	expected := map[string]int64{"counter": 2, `sub.counter{store="1"}`: 5}
+	if deltas := r.Deltas(); !reflect.DeepEqual(deltas, expected) {
+		t.Errorf("expected deltas %v, got %v", expected, deltas)
+	}
+
+	c.Inc(1)
+	expected = map[string]int64{"counter": 1, `sub.counter{store="1"}`: 0}
+	if deltas := r.Deltas(); !reflect.DeepEqual(deltas, expected) {
+		t.Errorf("expected deltas %v, got %v", expected, deltas)
+	}
+}
+
+func TestRegistryRemove(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("counter")
+	r.CounterWithLabels("labeled", map[string]string{"store": "1"})
+	r.CounterWithLabels("labeled", map[string]string{"store": "2"})
+	r.SetMetadata("counter", Metadata{Unit: UnitBytes})
+
+	oldSub := NewRegistry()
+	oldSub.Gauge("old")
+	r.MustAdd("sub.%s", oldSub)
+	newSub := NewRegistry()
+	newSub.Gauge("new")
+	// Re-adding a sub-registry replaces it.
+	r.MustAdd("sub.%s", newSub)
+
+	names := func() map[string]struct{} {
+		m := map[string]struct{}{}
+		r.Each(func(name string, _ interface{}) {
+			m[name] = struct{}{}
+		})
+		return m
+	}
+	if expected, actual := map[string]struct{}{
+		"counter": {}, "labeled": {}, "sub.new": {},
+	}, names(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	if r.RemoveSub("counter") {
+		t.Error("unexpectedly removed a metric with RemoveSub")
+	}
+	if !r.Remove("counter") || !r.Remove("labeled") {
+		t.Error("failed to remove metrics")
+	}
+	if r.Remove("sub.%s") {
+		t.Error("unexpectedly removed a sub-registry with Remove")
+	}
+	if _, ok := r.metadata["counter"]; ok {
+		t.Error("metadata of removed metric was not removed")
+	}
+	if !r.RemoveSub("sub.%s") {
+		t.Error("failed to remove sub-registry")
+	}
+	if actual := names(); len(actual) != 0 {
+		t.Errorf("expected no metrics, got %v", actual)
+	}
+	if r.Remove("counter") {
+		t.Error("unexpectedly removed a metric twice")
+	}
+	// A name can be reused after removal.
+	r.Counter("counter")
+}
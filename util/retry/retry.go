@@ -20,6 +20,24 @@ import (
 	"math"
 	"math/rand"
 	"time"
+
+	"golang.org/x/net/context"
+)
+
+// Jitter determines how the backoff intervals of a retry loop are
+// randomized.
+type Jitter int
+
+const (
+	// JitterProportional randomizes each backoff interval by up to
+	// RandomizationFactor of it in either direction. It is the default.
+	JitterProportional Jitter = iota
+	// JitterFull picks each backoff interval uniformly between zero and the
+	// exponential backoff, which spreads out the retries of clients which
+	// failed at the same time the most.
+	JitterFull
+	// JitterNone doesn't randomize backoff intervals.
+	JitterNone
 )
 
 // Options provides reusable configuration of Retry objects.
@@ -28,21 +46,36 @@ type Options struct {
 	MaxBackoff          time.Duration   // Maximum retry backoff interval
 	Multiplier          float64         // Default backoff constant
 	MaxRetries          int             // Maximum number of attempts (0 for infinite)
-	RandomizationFactor float64         // Randomize the backoff interval by constant
+	MaxDuration         time.Duration   // Maximum duration of all attempts (0 for infinite)
+	RandomizationFactor float64         // Randomize the backoff interval by constant, in (0, 1]
+	Jitter              Jitter          // How the backoff interval is randomized
 	Closer              <-chan struct{} // Optionally end retry loop channel close.
+	// OnRetry, if set, is called with the number of each retry and the
+	// backoff interval preceding it, before backing off. It can be used to
+	// log or count retries.
+	OnRetry func(attempt int, backoff time.Duration)
 }
 
 // Retry implements the public methods necessary to control an exponential-
 // backoff retry loop.
 type Retry struct {
 	opts           Options
+	ctx            context.Context
+	deadline       time.Time
 	currentAttempt int
 	isReset        bool
+	started        bool
 }
 
 // Start returns a new Retry initialized to some default values. The Retry can
 // then be used in an exponential-backoff retry loop.
 func Start(opts Options) Retry {
+	return StartWithCtx(context.Background(), opts)
+}
+
+// StartWithCtx is like Start, but the retry loop also ends once the context
+// is done.
+func StartWithCtx(ctx context.Context, opts Options) Retry {
 	if opts.InitialBackoff == 0 {
 		opts.InitialBackoff = 50 * time.Millisecond
 	}
@@ -51,12 +84,18 @@ func Start(opts Options) Retry {
 	}
 	if opts.RandomizationFactor == 0 {
 		opts.RandomizationFactor = 0.15
+	} else if opts.RandomizationFactor > 1 {
+		// Larger factors could make the backoff interval negative.
+		opts.RandomizationFactor = 1
 	}
 	if opts.Multiplier == 0 {
 		opts.Multiplier = 2
 	}
 
-	r := Retry{opts: opts}
+	r := Retry{opts: opts, ctx: ctx}
+	if opts.MaxDuration > 0 {
+		r.deadline = time.Now().Add(opts.MaxDuration)
+	}
 	r.Reset()
 	return r
 }
@@ -82,6 +121,13 @@ func (r Retry) retryIn() time.Duration {
 		backoff = maxBackoff
 	}
 
+	switch r.opts.Jitter {
+	case JitterNone:
+		return time.Duration(backoff)
+	case JitterFull:
+		return time.Duration(rand.Float64() * backoff)
+	}
+
 	var delta = r.opts.RandomizationFactor * backoff
 	// Get a random value from the range [backoff - delta, backoff + delta].
 	// The formula used below has a +1 because time.Duration is an int64, and the
@@ -90,24 +136,44 @@ func (r Retry) retryIn() time.Duration {
 }
 
 // Next returns whether the retry loop should continue, and blocks for the
-// appropriate length of time before yielding back to the caller. If a stopper
-// is present, Next will eagerly return false when the stopper is stopped.
+// appropriate length of time before yielding back to the caller. The first
+// call always returns true. Later calls eagerly return false when the closer
+// is closed, the context is done, or the next attempt would start after
+// MaxDuration has elapsed.
 func (r *Retry) Next() bool {
-	if r.isReset {
+	if !r.started {
+		r.started = true
 		r.isReset = false
 		return true
 	}
+	if r.ctx.Err() != nil {
+		return false
+	}
+	if r.isReset {
+		r.isReset = false
+		return r.deadline.IsZero() || time.Now().Before(r.deadline)
+	}
 
 	if r.opts.MaxRetries > 0 && r.currentAttempt == r.opts.MaxRetries {
 		return false
 	}
 
+	backoff := r.retryIn()
+	if !r.deadline.IsZero() && time.Now().Add(backoff).After(r.deadline) {
+		return false
+	}
+	if r.opts.OnRetry != nil {
+		r.opts.OnRetry(r.currentAttempt+1, backoff)
+	}
+
 	// Wait before retry.
 	select {
-	case <-time.After(r.retryIn()):
+	case <-time.After(backoff):
 		r.currentAttempt++
 		return true
 	case <-r.opts.Closer:
 		return false
+	case <-r.ctx.Done():
+		return false
 	}
 }
@@ -17,8 +17,11 @@
 package retry
 
 import (
+	"reflect"
 	"testing"
 	"time"
+
+	"golang.org/x/net/context"
 )
 
 func TestRetryExceedsMaxBackoff(t *testing.T) {
@@ -113,3 +116,91 @@ func TestRetryStop(t *testing.T) {
 		t.Errorf("expected %d attempts, got %d", expAttempts, attempts)
 	}
 }
+
+func TestRetryMaxDuration(t *testing.T) {
+	opts := Options{
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+		Jitter:         JitterNone,
+		MaxDuration:    35 * time.Millisecond,
+	}
+
+	attempts := 0
+	for r := Start(opts); r.Next(); attempts++ {
+	}
+
+	// The fifth attempt would start after the budget ran out.
+	if expAttempts := 4; attempts != expAttempts {
+		t.Errorf("expected %d attempts, got %d attempts", expAttempts, attempts)
+	}
+}
+
+func TestRetryCtxCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	opts := Options{
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Second,
+	}
+
+	var attempts int
+	for r := StartWithCtx(ctx, opts); r.Next(); attempts++ {
+		go cancel()
+		<-ctx.Done()
+		// Immediate retries stop as well.
+		r.Reset()
+	}
+
+	if expAttempts := 1; attempts != expAttempts {
+		t.Errorf("expected %d attempts, got %d attempts", expAttempts, attempts)
+	}
+}
+
+func TestRetryOnRetry(t *testing.T) {
+	var retries []int
+	opts := Options{
+		InitialBackoff: time.Microsecond,
+		MaxBackoff:     time.Microsecond,
+		MaxRetries:     3,
+		OnRetry: func(attempt int, backoff time.Duration) {
+			if backoff <= 0 {
+				t.Errorf("expected a positive backoff, got %s", backoff)
+			}
+			retries = append(retries, attempt)
+		},
+	}
+
+	for r := Start(opts); r.Next(); {
+	}
+
+	if exp := []int{1, 2, 3}; !reflect.DeepEqual(retries, exp) {
+		t.Errorf("expected retries %v, got %v", exp, retries)
+	}
+}
+
+func TestRetryJitter(t *testing.T) {
+	testCases := []struct {
+		jitter   Jitter
+		factor   float64
+		min, max time.Duration
+	}{
+		{JitterProportional, 0, 85 * time.Millisecond, 115 * time.Millisecond},
+		{JitterProportional, 0.5, 50 * time.Millisecond, 150 * time.Millisecond},
+		// Factors above one are capped so that backoffs aren't negative.
+		{JitterProportional, 5, 0, 200 * time.Millisecond},
+		{JitterFull, 0, 0, 100 * time.Millisecond},
+		{JitterNone, 0, 100 * time.Millisecond, 100 * time.Millisecond},
+	}
+	for i, tc := range testCases {
+		r := Start(Options{
+			InitialBackoff:      100 * time.Millisecond,
+			MaxBackoff:          time.Second,
+			RandomizationFactor: tc.factor,
+			Jitter:              tc.jitter,
+		})
+		for j := 0; j < 100; j++ {
+			if backoff := r.retryIn(); backoff < tc.min || backoff > tc.max {
+				t.Errorf("%d: expected backoff between %s and %s, got %s", i, tc.min, tc.max, backoff)
+			}
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package settings provides a registry of runtime settings, which are
+// shared by all nodes of a cluster and can be changed without restarting
+// them.
+package settings
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/cockroachdb/cockroach/util/log"
+)
+
+// A Setting describes a runtime setting. Values of settings are strings;
+// settings which need a different type parse them in their validation and
+// change functions.
+type Setting struct {
+	Name        string
+	Description string
+	Default     string
+
+	validate func(string) error
+	onChange func(string)
+}
+
+// A Value is the current value of a setting.
+type Value struct {
+	Setting
+	Value string
+}
+
+// A Registry holds the registered settings and their current values.
+type Registry struct {
+	mu       sync.Mutex
+	settings map[string]*Setting
+	values   map[string]string // Values which were changed from the default
+}
+
+// NewRegistry creates a new registry without settings.
+func NewRegistry() *Registry {
+	return &Registry{
+		settings: map[string]*Setting{},
+		values:   map[string]string{},
+	}
+}
+
+// Register registers a setting with the given name, description and
+// default value. validate, if not nil, returns an error for invalid values.
+// onChange, if not nil, is called with the new value whenever the value of
+// the setting changes. Register panics if the name is already taken or the
+// default value is invalid.
+func (r *Registry) Register(name, description, defaultValue string,
+	validate func(string) error, onChange func(string)) {
+	if validate != nil {
+		if err := validate(defaultValue); err != nil {
+			panic(fmt.Sprintf("invalid default value %q for setting %s: %s", defaultValue, name, err))
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.settings[name]; ok {
+		panic(fmt.Sprintf("setting %s already registered", name))
+	}
+	r.settings[name] = &Setting{
+		Name:        name,
+		Description: description,
+		Default:     defaultValue,
+		validate:    validate,
+		onChange:    onChange,
+	}
+}
+
+// Validate returns an error if no setting with the given name is registered
+// or if the value is invalid for it.
+func (r *Registry) Validate(name, value string) error {
+	r.mu.Lock()
+	s, ok := r.settings[name]
+	r.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown setting %q", name)
+	}
+	if s.validate != nil {
+		if err := s.validate(value); err != nil {
+			return fmt.Errorf("invalid value %q for setting %s: %s", value, name, err)
+		}
+	}
+	return nil
+}
+
+// Get returns the current value of the setting with the given name and
+// whether such a setting is registered.
+func (r *Registry) Get(name string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.settings[name]
+	if !ok {
+		return "", false
+	}
+	if v, ok := r.values[name]; ok {
+		return v, true
+	}
+	return s.Default, true
+}
+
+// Update replaces the values of the settings by the given ones, which are
+// keyed by setting name. Settings missing from values revert to their
+// defaults. Values of unknown settings are ignored, and so are invalid
+// values, which also revert their settings to the defaults. The change
+// functions of all settings whose value changed are called.
+func (r *Registry) Update(values map[string]string) {
+	type change struct {
+		onChange func(string)
+		value    string
+	}
+	var changes []change
+
+	r.mu.Lock()
+	for name, s := range r.settings {
+		old, ok := r.values[name]
+		if !ok {
+			old = s.Default
+		}
+		v, ok := values[name]
+		if ok && s.validate != nil {
+			if err := s.validate(v); err != nil {
+				log.Warningf("ignoring invalid value %q for setting %s: %s", v, name, err)
+				ok = false
+			}
+		}
+		if ok {
+			r.values[name] = v
+		} else {
+			delete(r.values, name)
+			v = s.Default
+		}
+		if v != old && s.onChange != nil {
+			changes = append(changes, change{onChange: s.onChange, value: v})
+		}
+	}
+	r.mu.Unlock()
+
+	for _, c := range changes {
+		c.onChange(c.value)
+	}
+}
+
+type valuesByName []Value
+
+func (v valuesByName) Len() int           { return len(v) }
+func (v valuesByName) Swap(i, j int)      { v[i], v[j] = v[j], v[i] }
+func (v valuesByName) Less(i, j int) bool { return v[i].Name < v[j].Name }
+
+// Values returns the current values of all registered settings, sorted by
+// name.
+func (r *Registry) Values() []Value {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	values := make([]Value, 0, len(r.settings))
+	for name, s := range r.settings {
+		v, ok := r.values[name]
+		if !ok {
+			v = s.Default
+		}
+		values = append(values, Value{Setting: *s, Value: v})
+	}
+	sort.Sort(valuesByName(values))
+	return values
+}
@@ -0,0 +1,72 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package settings
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	var changes []string
+	r.Register("b.int", "an integer", "1", func(v string) error {
+		_, err := strconv.Atoi(v)
+		return err
+	}, func(v string) {
+		changes = append(changes, v)
+	})
+	r.Register("a.string", "a string", "x", nil, nil)
+
+	if err := r.Validate("b.int", "2"); err != nil {
+		t.Error(err)
+	}
+	if err := r.Validate("b.int", "two"); err == nil {
+		t.Error("expected error for invalid value")
+	}
+	if err := r.Validate("c", "2"); err == nil {
+		t.Error("expected error for unknown setting")
+	}
+
+	expect := func(name, expValue string) {
+		if v, ok := r.Get(name); !ok || v != expValue {
+			t.Errorf("expected %s=%q, got %q (registered=%t)", name, expValue, v, ok)
+		}
+	}
+	expect("b.int", "1")
+
+	r.Update(map[string]string{"b.int": "3", "c": "4"})
+	expect("b.int", "3")
+	expect("a.string", "x")
+	// Unchanged values don't trigger the change functions.
+	r.Update(map[string]string{"b.int": "3", "a.string": "y"})
+	expect("a.string", "y")
+	// Invalid values revert to the default.
+	r.Update(map[string]string{"b.int": "three"})
+	expect("b.int", "1")
+	expect("a.string", "x")
+	if e := []string{"3", "1"}; !reflect.DeepEqual(changes, e) {
+		t.Errorf("expected changes %v, got %v", e, changes)
+	}
+
+	values := r.Values()
+	if len(values) != 2 || values[0].Name != "a.string" || values[1].Name != "b.int" {
+		t.Fatalf("unexpected values %+v", values)
+	}
+	if v := values[1]; v.Value != "1" || v.Default != "1" || v.Description != "an integer" {
+		t.Errorf("unexpected value %+v", v)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/cockroachdb/cockroach/util/caller"
 )
@@ -40,21 +41,91 @@ func (f CloserFn) Close() {
 	f()
 }
 
+// callerName returns the call site which added a worker or closer. It must
+// be called directly by the Stopper method it was added with.
+func callerName() string {
+	file, line, _ := caller.Lookup(2)
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+// taskKey identifies a task by its name or, for unnamed tasks, by the call
+// site which ran it. Running tasks is a hot path, so the call site is only
+// formatted when the tasks are listed.
 type taskKey struct {
 	file string
 	line int
+	name string
+}
+
+// callerKey returns the key of an unnamed task. It must be called directly
+// by the Stopper method the task was run with.
+func callerKey() taskKey {
+	file, line, _ := caller.Lookup(2)
+	return taskKey{file: file, line: line}
 }
 
 func (k taskKey) String() string {
+	if k.file == "" {
+		return k.name
+	}
 	return fmt.Sprintf("%s:%d", k.file, k.line)
 }
 
+// runningTask is a task in Stopper.running.
+type runningTask struct {
+	key       taskKey
+	startedAt time.Time
+}
+
+// A Phase is a phase of stopping a Stopper, in the order in which they are
+// carried out.
+type Phase int
+
+const (
+	// PhaseDrain waits for the running tasks to complete. New tasks are
+	// refused from its start on.
+	PhaseDrain Phase = iota
+	// PhaseStop waits for the workers to exit.
+	PhaseStop
+	// PhaseClose closes the closers added with AddCloser.
+	PhaseClose
+	// PhaseCloseLast closes the closers added with AddCloserLast.
+	PhaseCloseLast
+	numPhases
+)
+
+var phaseNames = [numPhases]string{"drain", "stop", "close", "close-last"}
+
+func (p Phase) String() string {
+	return phaseNames[p]
+}
+
+// DefaultPhaseTimeout is the duration after which the tasks, workers or
+// closers a phase of stopping waits for are logged as laggards, unless
+// changed through SetPhaseTimeout.
+const DefaultPhaseTimeout = 10 * time.Second
+
+// namedCloser is a Closer along with the call site which added it.
+type namedCloser struct {
+	Closer
+	name string
+}
+
+// A TaskInfo describes a running task.
+type TaskInfo struct {
+	// Name is the name the task was run with or, for unnamed tasks, the call
+	// site which ran it.
+	Name string `json:"name"`
+	// StartedAt is the time at which the task started.
+	StartedAt time.Time `json:"started_at"`
+}
+
 // A Stopper provides a channel-based mechanism to stop an arbitrary
 // array of workers. Each worker is registered with the stopper via
 // the RunWorker() method. The system further allows execution of functions
 // through RunTask() and RunAsyncTask().
 //
-// Stopping occurs in two phases: the first is the request to stop, which moves
+// Stopping occurs in phases: the first is the request to stop, which moves
 // the stopper into a draining phase. While draining, calls to RunTask() &
 // RunAsyncTask() don't execute the function passed in and return false.
 // When all outstanding tasks have been completed, the stopper
@@ -63,18 +134,28 @@ func (k taskKey) String() string {
 //
 // An arbitrary list of objects implementing the Closer interface may
 // be added to the stopper via AddCloser(), to be closed after the
-// stopper has stopped.
+// stopper has stopped. Objects which the others may still use while closing,
+// such as storage engines, are added via AddCloserLast() instead, to be
+// closed after all others. If a phase takes longer than its timeout, the
+// tasks, workers or closer it is waiting for are logged as laggards, so that
+// a hung shutdown can be attributed.
 type Stopper struct {
-	drainer  chan struct{}  // Closed when draining
-	stopper  chan struct{}  // Closed when stopping
-	stopped  chan struct{}  // Closed when stopped completely
-	stop     sync.WaitGroup // Incremented for outstanding workers
-	mu       sync.Mutex     // Protects the fields below
-	drain    *sync.Cond     // Conditional variable to wait for outstanding tasks
-	draining bool           // true when Stop() has been called
-	numTasks int            // number of outstanding tasks
-	tasks    map[taskKey]int
-	closers  []Closer
+	drainer  chan struct{}   // Closed when draining
+	stopper  chan struct{}   // Closed when stopping
+	stopped  chan struct{}   // Closed when stopped completely
+	stop     sync.WaitGroup  // Incremented for outstanding workers
+	mu       sync.Mutex      // Protects the fields below
+	drain    *sync.Cond      // Conditional variable to wait for outstanding tasks
+	draining bool            // true when Stop() has been called
+	numTasks int             // number of outstanding tasks
+	tasks    map[taskKey]int // number of outstanding tasks by key
+	running  map[int64]runningTask
+	nextID   int64          // ID of the next task in running
+	workers  map[string]int // number of live workers by call site
+	closers  []namedCloser
+	// lastClosers are closed after closers.
+	lastClosers []namedCloser
+	timeouts    [numPhases]time.Duration
 }
 
 // NewStopper returns an instance of Stopper.
@@ -84,26 +165,61 @@ func NewStopper() *Stopper {
 		stopper: make(chan struct{}),
 		stopped: make(chan struct{}),
 		tasks:   map[taskKey]int{},
+		running: map[int64]runningTask{},
+		workers: map[string]int{},
+	}
+	for i := range s.timeouts {
+		s.timeouts[i] = DefaultPhaseTimeout
 	}
 	s.drain = sync.NewCond(&s.mu)
 	return s
 }
 
+// SetPhaseTimeout sets the duration after which the tasks, workers or
+// closers the given phase of stopping waits for are logged as laggards. They
+// are logged again each time the duration elapses. A zero duration disables
+// the logging.
+func (s *Stopper) SetPhaseTimeout(phase Phase, timeout time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.timeouts[phase] = timeout
+}
+
 // RunWorker runs the supplied function as a "worker" to be stopped
 // by the stopper. The function <f> is run in a goroutine.
 func (s *Stopper) RunWorker(f func()) {
+	name := callerName()
+	s.mu.Lock()
+	s.workers[name]++
+	s.mu.Unlock()
 	s.stop.Add(1)
 	go func() {
-		defer s.stop.Done()
+		defer func() {
+			s.mu.Lock()
+			s.workers[name]--
+			s.mu.Unlock()
+			s.stop.Done()
+		}()
 		f()
 	}()
 }
 
 // AddCloser adds an object to close after the stopper has been stopped.
+// Closers are closed in the order in which they were added.
 func (s *Stopper) AddCloser(c Closer) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.closers = append(s.closers, c)
+	s.closers = append(s.closers, namedCloser{Closer: c, name: callerName()})
+}
+
+// AddCloserLast adds an object to close after the stopper has been stopped
+// and the closers added with AddCloser have been closed. It is meant for
+// objects which the workers or other closers may use until they are done,
+// such as storage engines.
+func (s *Stopper) AddCloserLast(c Closer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastClosers = append(s.lastClosers, namedCloser{Closer: c, name: callerName()})
 }
 
 // RunTask adds one to the count of tasks left to drain in the system. Any
@@ -115,13 +231,22 @@ func (s *Stopper) AddCloser(c Closer) {
 // Returns false to indicate that the system is currently draining and
 // function f was not called.
 func (s *Stopper) RunTask(f func()) bool {
-	file, line, _ := caller.Lookup(1)
-	key := taskKey{file, line}
-	if !s.runPrelude(key) {
+	return s.runTask(callerKey(), f)
+}
+
+// RunNamedTask is like RunTask, but lists the task under the given name
+// rather than its call site in RunningTasks and Tasks.
+func (s *Stopper) RunNamedTask(name string, f func()) bool {
+	return s.runTask(taskKey{name: name}, f)
+}
+
+func (s *Stopper) runTask(key taskKey, f func()) bool {
+	id, ok := s.runPrelude(key)
+	if !ok {
 		return false
 	}
 	// Call f.
-	defer s.runPostlude(key)
+	defer s.runPostlude(id, key)
 	f()
 	return true
 }
@@ -129,35 +254,48 @@ func (s *Stopper) RunTask(f func()) bool {
 // RunAsyncTask runs function f in a goroutine. It returns false when the
 // Stopper is draining and the function is not executed.
 func (s *Stopper) RunAsyncTask(f func()) bool {
-	file, line, _ := caller.Lookup(1)
-	key := taskKey{file, line}
-	if !s.runPrelude(key) {
+	return s.runAsyncTask(callerKey(), f)
+}
+
+// RunNamedAsyncTask is like RunAsyncTask, but lists the task under the given
+// name rather than its call site in RunningTasks and Tasks.
+func (s *Stopper) RunNamedAsyncTask(name string, f func()) bool {
+	return s.runAsyncTask(taskKey{name: name}, f)
+}
+
+func (s *Stopper) runAsyncTask(key taskKey, f func()) bool {
+	id, ok := s.runPrelude(key)
+	if !ok {
 		return false
 	}
 	// Call f.
 	go func() {
-		defer s.runPostlude(key)
+		defer s.runPostlude(id, key)
 		f()
 	}()
 	return true
 }
 
-func (s *Stopper) runPrelude(key taskKey) bool {
+func (s *Stopper) runPrelude(key taskKey) (int64, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if s.draining {
-		return false
+		return 0, false
 	}
 	s.numTasks++
 	s.tasks[key]++
-	return true
+	id := s.nextID
+	s.nextID++
+	s.running[id] = runningTask{key: key, startedAt: time.Now()}
+	return id, true
 }
 
-func (s *Stopper) runPostlude(key taskKey) {
+func (s *Stopper) runPostlude(id int64, key taskKey) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.numTasks--
 	s.tasks[key]--
+	delete(s.running, id)
 	s.drain.Broadcast()
 }
 
@@ -196,11 +334,32 @@ func (s *Stopper) runningTasksLocked() TaskMap {
 		if s.tasks[k] == 0 {
 			continue
 		}
-		m[k.String()] = s.tasks[k]
+		m[k.String()] += s.tasks[k]
 	}
 	return m
 }
 
+// tasksByStart sorts tasks by their start times.
+type tasksByStart []TaskInfo
+
+func (t tasksByStart) Len() int           { return len(t) }
+func (t tasksByStart) Less(i, j int) bool { return t[i].StartedAt.Before(t[j].StartedAt) }
+func (t tasksByStart) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+
+// Tasks returns the running tasks, longest running first. Unlike
+// RunningTasks, it lists each task individually along with its start time,
+// so that a task which never completes stands out.
+func (s *Stopper) Tasks() []TaskInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tasks := make([]TaskInfo, 0, len(s.running))
+	for _, t := range s.running {
+		tasks = append(tasks, TaskInfo{Name: t.key.String(), StartedAt: t.startedAt})
+	}
+	sort.Sort(tasksByStart(tasks))
+	return tasks
+}
+
 // Stop signals all live workers to stop and then waits for each to
 // confirm it has stopped.
 func (s *Stopper) Stop() {
@@ -215,20 +374,86 @@ func (s *Stopper) Stop() {
 		for _, c := range s.closers {
 			go c.Close()
 		}
+		for _, c := range s.lastClosers {
+			go c.Close()
+		}
 		panic(r)
 	}
 
 	s.Quiesce()
 	close(s.stopper)
-	s.stop.Wait()
+	s.waitForWorkers()
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	for _, c := range s.closers {
-		c.Close()
+		closeWatched(c, PhaseClose, s.timeouts[PhaseClose])
+	}
+	for _, c := range s.lastClosers {
+		closeWatched(c, PhaseCloseLast, s.timeouts[PhaseCloseLast])
 	}
 	close(s.stopped)
 }
 
+// waitForWorkers waits for all workers to exit.
+func (s *Stopper) waitForWorkers() {
+	s.mu.Lock()
+	timeout := s.timeouts[PhaseStop]
+	s.mu.Unlock()
+	done := make(chan struct{})
+	defer close(done)
+	watchLaggards(PhaseStop, timeout, done, func() string {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return countsString(s.workers)
+	})
+	s.stop.Wait()
+}
+
+// closeWatched closes the closer, logging it as a laggard if closing takes
+// longer than the timeout.
+func closeWatched(c namedCloser, phase Phase, timeout time.Duration) {
+	done := make(chan struct{})
+	defer close(done)
+	watchLaggards(phase, timeout, done, func() string {
+		return fmt.Sprintf("%T added at %s", c.Closer, c.name)
+	})
+	c.Close()
+}
+
+// watchLaggards logs the laggards of the given phase of stopping each time
+// the timeout elapses until done is closed.
+func watchLaggards(phase Phase, timeout time.Duration, done <-chan struct{}, laggards func() string) {
+	if timeout <= 0 {
+		return
+	}
+	start := time.Now()
+	go func() {
+		ticker := time.NewTicker(timeout)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				// Use stdlib "log" instead of "cockroach/util/log" due to import cycles.
+				log.Printf("%s phase of stopping has taken %s; still waiting for:\n%s",
+					phase, time.Since(start), laggards())
+			case <-done:
+				return
+			}
+		}
+	}()
+}
+
+// countsString returns a sorted multi-line listing of the non-zero counts.
+func countsString(counts map[string]int) string {
+	m := TaskMap{}
+	for name, n := range counts {
+		if n != 0 {
+			m[name] = n
+		}
+	}
+	return m.String()
+}
+
 // ShouldDrain returns a channel which will be closed when Stop() has been
 // invoked and outstanding tasks should begin to drain.
 func (s *Stopper) ShouldDrain() <-chan struct{} {
@@ -268,6 +493,15 @@ func (s *Stopper) Quiesce() {
 		s.draining = true
 		close(s.drainer)
 	}
+	done := make(chan struct{})
+	defer close(done)
+	watchLaggards(PhaseDrain, s.timeouts[PhaseDrain], done, func() string {
+		var lines []string
+		for _, t := range s.Tasks() {
+			lines = append(lines, fmt.Sprintf("%s (running for %s)", t.Name, time.Since(t.StartedAt)))
+		}
+		return strings.Join(lines, "\n")
+	})
 	for s.numTasks > 0 {
 		// Use stdlib "log" instead of "cockroach/util/log" due to import cycles.
 		log.Print("draining; tasks left:\n", s.runningTasksLocked())
@@ -17,7 +17,13 @@
 package stop_test
 
 import (
+	"bytes"
 	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -245,6 +251,56 @@ func TestStopperClosers(t *testing.T) {
 	}
 }
 
+func TestStopperCloserOrder(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := stop.NewStopper()
+	var closed []string
+	s.AddCloserLast(stop.CloserFn(func() { closed = append(closed, "last") }))
+	s.AddCloser(stop.CloserFn(func() { closed = append(closed, "first") }))
+	s.AddCloser(stop.CloserFn(func() { closed = append(closed, "second") }))
+	s.Stop()
+	if expected := []string{"first", "second", "last"}; !reflect.DeepEqual(closed, expected) {
+		t.Errorf("expected closers to be closed in order %v, got %v", expected, closed)
+	}
+}
+
+// syncBuffer is a bytes.Buffer which is safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestStopperPhaseTimeout(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	var buf syncBuffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	s := stop.NewStopper()
+	s.SetPhaseTimeout(stop.PhaseStop, time.Millisecond)
+	s.RunWorker(func() {
+		<-s.ShouldStop()
+		time.Sleep(50 * time.Millisecond)
+	})
+	s.Stop()
+	if out := buf.String(); !strings.Contains(out, "stop phase of stopping") ||
+		!strings.Contains(out, "stopper_test.go") {
+		t.Errorf("expected the worker to be logged as a laggard, got %q", out)
+	}
+}
+
 func TestStopperNumTasks(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	s := stop.NewStopper()
@@ -297,6 +353,42 @@ func TestStopperNumTasks(t *testing.T) {
 	s.Stop()
 }
 
+func TestStopperTasks(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := stop.NewStopper()
+	first, second := make(chan struct{}), make(chan struct{})
+	if !s.RunNamedAsyncTask("first", func() { <-first }) {
+		t.Fatal("task was not run")
+	}
+	if !s.RunAsyncTask(func() { <-second }) {
+		t.Fatal("task was not run")
+	}
+
+	tasks := s.Tasks()
+	if len(tasks) != 2 {
+		t.Fatalf("expected 2 running tasks, got %+v", tasks)
+	}
+	if tasks[0].Name != "first" {
+		t.Errorf("expected the named task to be listed first, got %+v", tasks)
+	}
+	if tasks[1].StartedAt.Before(tasks[0].StartedAt) {
+		t.Errorf("expected tasks to be sorted by start time, got %+v", tasks)
+	}
+	if _, ok := s.RunningTasks()["first"]; !ok {
+		t.Errorf("expected the named task in the task map, got %+v", s.RunningTasks())
+	}
+
+	close(first)
+	close(second)
+	util.SucceedsSoon(t, func() error {
+		if tasks := s.Tasks(); len(tasks) != 0 {
+			return util.Errorf("expected no running tasks, got %+v", tasks)
+		}
+		return nil
+	})
+	s.Stop()
+}
+
 // TestStopperRunTaskPanic ensures that tasks are not leaked when they panic.
 // RunAsyncTask has a similar bit of logic, but it is not testable because
 // we cannot insert a recover() call in the right place.
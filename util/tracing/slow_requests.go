@@ -0,0 +1,199 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	basictracer "github.com/opentracing/basictracer-go"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+// A SlowRequestEvent is an event logged to the trace of a slow request.
+type SlowRequestEvent struct {
+	Time      time.Time `json:"time"`
+	Operation string    `json:"operation"`
+	Event     string    `json:"event"`
+}
+
+// A SlowRequest is the trace of a request which took longer than the
+// threshold of SlowRequests.
+type SlowRequest struct {
+	Operation string             `json:"operation"`
+	Start     time.Time          `json:"start"`
+	Duration  time.Duration      `json:"duration"`
+	Events    []SlowRequestEvent `json:"events"`
+}
+
+// SlowRequests retains the traces of the slowest requests served by a node,
+// for debugging them after the fact. Requests are traced in full while they
+// execute, regardless of sampling, and their traces are kept if they took
+// longer than the threshold. The spans are received from the tracer of the
+// node, which must pass them to RecordSpan in addition to recording them as
+// usual; see NewTeeTracer. A nil *SlowRequests traces nothing.
+type SlowRequests struct {
+	threshold time.Duration
+	capacity  int
+
+	mu       sync.Mutex
+	active   map[int64][]*slowTrace // Traces being recorded, by trace ID.
+	requests []SlowRequest          // Sorted by decreasing duration.
+}
+
+// A slowTrace collects the spans of a request being traced.
+type slowTrace struct {
+	traceID int64
+	start   time.Time
+	spans   []basictracer.RawSpan
+}
+
+// NewSlowRequests returns a SlowRequests which retains the traces of the
+// capacity slowest requests which took longer than threshold. A threshold
+// of zero disables tracing.
+func NewSlowRequests(threshold time.Duration, capacity int) *SlowRequests {
+	return &SlowRequests{
+		threshold: threshold,
+		capacity:  capacity,
+		active:    map[int64][]*slowTrace{},
+	}
+}
+
+func (s *SlowRequests) enabled() bool {
+	return s != nil && s.threshold > 0 && s.capacity > 0
+}
+
+// RecordSpan implements basictracer.SpanRecorder. It collects the spans
+// which belong to the trace of a request being traced and started after it.
+func (s *SlowRequests) RecordSpan(rawSpan basictracer.RawSpan) {
+	if !s.enabled() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.active[rawSpan.Context.TraceID] {
+		if !rawSpan.Start.Before(t.start) {
+			t.spans = append(t.spans, rawSpan)
+		}
+	}
+}
+
+// Trace starts recording the trace of a request and returns a context which
+// carries its span. The span is started from tr as a child of the span of ctx
+// if there is one, so that its events remain part of the caller's trace; tr
+// must pass the spans it records to s. The returned func must be called once
+// the request completes; the trace is retained if the request was slow.
+// Requests which are already snowball traced are left alone, since their
+// traces are returned to the client.
+func (s *SlowRequests) Trace(ctx context.Context, tr opentracing.Tracer, opName string) (context.Context, func()) {
+	if !s.enabled() {
+		return ctx, func() {}
+	}
+	var carrier *Span
+	if parent := opentracing.SpanFromContext(ctx); parent != nil {
+		if parent.BaggageItem(Snowball) != "" {
+			return ctx, func() {}
+		}
+		carrier = &Span{}
+		if err := tr.Inject(parent, basictracer.Delegator, carrier); err != nil {
+			carrier = nil
+		}
+	}
+
+	t := &slowTrace{start: time.Now()}
+	sp, err := JoinOrNew(tr, carrier, opName)
+	if err != nil {
+		return ctx, func() {}
+	}
+	// Unsampled spans drop their events, so the span must be sampled.
+	ext.SamplingPriority.Set(sp, 1)
+	var state Span
+	if err := tr.Inject(sp, basictracer.Delegator, &state); err != nil {
+		return opentracing.ContextWithSpan(ctx, sp), sp.Finish
+	}
+	t.traceID = state.TraceID
+	s.mu.Lock()
+	s.active[t.traceID] = append(s.active[t.traceID], t)
+	s.mu.Unlock()
+
+	return opentracing.ContextWithSpan(ctx, sp), func() {
+		sp.Finish()
+		duration := time.Since(t.start)
+		s.mu.Lock()
+		traces := s.active[t.traceID]
+		for i := range traces {
+			if traces[i] == t {
+				traces = append(traces[:i], traces[i+1:]...)
+				break
+			}
+		}
+		if len(traces) == 0 {
+			delete(s.active, t.traceID)
+		} else {
+			s.active[t.traceID] = traces
+		}
+		s.mu.Unlock()
+		if duration < s.threshold {
+			return
+		}
+		req := SlowRequest{
+			Operation: opName,
+			Start:     t.start,
+			Duration:  duration,
+		}
+		for _, rawSpan := range t.spans {
+			for _, entry := range rawSpan.Logs {
+				req.Events = append(req.Events, SlowRequestEvent{
+					Time:      entry.Timestamp,
+					Operation: rawSpan.Operation,
+					Event:     entry.Event,
+				})
+			}
+		}
+		s.record(req)
+	}
+}
+
+// record retains the trace of a slow request, evicting the trace of the
+// fastest request retained so far if there are too many.
+func (s *SlowRequests) record(req SlowRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := sort.Search(len(s.requests), func(i int) bool {
+		return s.requests[i].Duration < req.Duration
+	})
+	if i >= s.capacity {
+		return
+	}
+	if len(s.requests) < s.capacity {
+		s.requests = append(s.requests, SlowRequest{})
+	}
+	copy(s.requests[i+1:], s.requests[i:])
+	s.requests[i] = req
+}
+
+// Requests returns the retained traces of slow requests, slowest first.
+func (s *SlowRequests) Requests() []SlowRequest {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SlowRequest(nil), s.requests...)
+}
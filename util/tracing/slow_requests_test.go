@@ -0,0 +1,122 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	basictracer "github.com/opentracing/basictracer-go"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+func TestSlowRequestsTrace(t *testing.T) {
+	s := NewSlowRequests(10*time.Millisecond, 10)
+	// Record the spans of the caller's trace as well.
+	var mu sync.Mutex
+	var callerSpans []basictracer.RawSpan
+	tr := NewTeeTracer(CallbackRecorder(func(rawSpan basictracer.RawSpan) {
+		mu.Lock()
+		callerSpans = append(callerSpans, rawSpan)
+		mu.Unlock()
+		s.RecordSpan(rawSpan)
+	}))
+
+	// A fast request is not retained.
+	_, finish := s.Trace(context.Background(), tr, "fast")
+	finish()
+	if reqs := s.Requests(); len(reqs) != 0 {
+		t.Fatalf("expected no slow requests, got %+v", reqs)
+	}
+
+	// A slow request is retained along with the events logged to the span
+	// of its context, even though the parent span is not sampled.
+	parent := tr.StartSpan("parent")
+	defer parent.Finish()
+	ctx, finish := s.Trace(opentracing.ContextWithSpan(context.Background(), parent), tr, "slow")
+	sp, cleanupSp := SpanFromContext("unused", tr, ctx)
+	sp.LogEvent("sleeping")
+	time.Sleep(20 * time.Millisecond)
+	cleanupSp()
+	finish()
+	reqs := s.Requests()
+	if len(reqs) != 1 {
+		t.Fatalf("expected one slow request, got %+v", reqs)
+	}
+	if req := reqs[0]; req.Operation != "slow" || req.Duration < 20*time.Millisecond {
+		t.Errorf("unexpected slow request %+v", req)
+	}
+	var found bool
+	for _, e := range reqs[0].Events {
+		found = found || e.Event == "sleeping"
+	}
+	if !found {
+		t.Errorf("expected the logged event to be retained, got %+v", reqs[0].Events)
+	}
+
+	// The events remain part of the caller's trace.
+	var parentState Span
+	if err := tr.Inject(parent, basictracer.Delegator, &parentState); err != nil {
+		t.Fatal(err)
+	}
+	found = false
+	mu.Lock()
+	for _, rawSpan := range callerSpans {
+		if rawSpan.Context.TraceID != parentState.TraceID {
+			continue
+		}
+		for _, entry := range rawSpan.Logs {
+			found = found || entry.Event == "sleeping"
+		}
+	}
+	mu.Unlock()
+	if !found {
+		t.Errorf("expected the logged event in the caller's trace, got %+v", callerSpans)
+	}
+
+	// Snowball traced requests are left alone.
+	snowball, err := JoinOrNewSnowball("snowball", nil, func(_ basictracer.RawSpan) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer snowball.Finish()
+	snowballCtx := opentracing.ContextWithSpan(context.Background(), snowball)
+	if ctx, _ := s.Trace(snowballCtx, tr, "snowball"); ctx != snowballCtx {
+		t.Error("expected the snowball trace to be left alone")
+	}
+}
+
+func TestSlowRequestsRecord(t *testing.T) {
+	s := NewSlowRequests(time.Millisecond, 3)
+	for _, d := range []time.Duration{5, 1, 4, 2, 3, 6} {
+		s.record(SlowRequest{Duration: d})
+	}
+	var durations []time.Duration
+	for _, req := range s.Requests() {
+		durations = append(durations, req.Duration)
+	}
+	expected := []time.Duration{6, 5, 4}
+	if len(durations) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, durations)
+	}
+	for i := range expected {
+		if durations[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, durations)
+		}
+	}
+}
@@ -91,6 +91,13 @@ func NewTracer() opentracing.Tracer {
 	return newTracer()
 }
 
+// NewTeeTracer creates a Tracer which records to the net/trace endpoint,
+// like NewTracer, and also passes the spans it records to the given
+// recorder.
+func NewTeeTracer(recorder basictracer.SpanRecorder) opentracing.Tracer {
+	return basictracer.NewWithOptions(defaultOptions(recorder.RecordSpan))
+}
+
 // SpanFromContext returns the Span obtained from the context or, if none is
 // found, a new one started through the tracer. Callers should call (or defer)
 // the returned cleanup func as well to ensure that the span is Finish()ed, but